@@ -599,3 +599,68 @@ func DomainPruningPointProofToMsgPruningPointProof(pruningPointProof *externalap
 		Headers: headers,
 	}
 }
+
+// DomainAcceptanceDataToBlockAcceptanceData converts domain AcceptanceData
+// to BlockAcceptanceData
+func DomainAcceptanceDataToBlockAcceptanceData(acceptanceData externalapi.AcceptanceData) []*BlockAcceptanceData {
+	blockAcceptanceData := make([]*BlockAcceptanceData, len(acceptanceData))
+	for i, domainBlockAcceptanceData := range acceptanceData {
+		transactionAcceptanceData := make([]*TransactionAcceptanceData,
+			len(domainBlockAcceptanceData.TransactionAcceptanceData))
+		for j, domainTransactionAcceptanceData := range domainBlockAcceptanceData.TransactionAcceptanceData {
+			utxoEntries := make([]*UTXOEntry, len(domainTransactionAcceptanceData.TransactionInputUTXOEntries))
+			for k, utxoEntry := range domainTransactionAcceptanceData.TransactionInputUTXOEntries {
+				utxoEntries[k] = &UTXOEntry{
+					Amount:          utxoEntry.Amount(),
+					ScriptPublicKey: utxoEntry.ScriptPublicKey(),
+					IsCoinbase:      utxoEntry.IsCoinbase(),
+					BlockDAAScore:   utxoEntry.BlockDAAScore(),
+				}
+			}
+			transactionAcceptanceData[j] = &TransactionAcceptanceData{
+				Transaction:                 DomainTransactionToMsgTx(domainTransactionAcceptanceData.Transaction),
+				Fee:                         domainTransactionAcceptanceData.Fee,
+				IsAccepted:                  domainTransactionAcceptanceData.IsAccepted,
+				TransactionInputUTXOEntries: utxoEntries,
+			}
+		}
+		blockAcceptanceData[i] = &BlockAcceptanceData{
+			BlockHash:                 domainBlockAcceptanceData.BlockHash,
+			TransactionAcceptanceData: transactionAcceptanceData,
+		}
+	}
+	return blockAcceptanceData
+}
+
+// BlockAcceptanceDataToDomainAcceptanceData converts BlockAcceptanceData
+// to domain AcceptanceData
+func BlockAcceptanceDataToDomainAcceptanceData(blockAcceptanceData []*BlockAcceptanceData) externalapi.AcceptanceData {
+	acceptanceData := make(externalapi.AcceptanceData, len(blockAcceptanceData))
+	for i, singleBlockAcceptanceData := range blockAcceptanceData {
+		transactionAcceptanceData := make([]*externalapi.TransactionAcceptanceData,
+			len(singleBlockAcceptanceData.TransactionAcceptanceData))
+		for j, singleTransactionAcceptanceData := range singleBlockAcceptanceData.TransactionAcceptanceData {
+			var utxoEntries []externalapi.UTXOEntry
+			if len(singleTransactionAcceptanceData.TransactionInputUTXOEntries) > 0 {
+				utxoEntries = make([]externalapi.UTXOEntry, len(singleTransactionAcceptanceData.TransactionInputUTXOEntries))
+				for k, utxoEntry := range singleTransactionAcceptanceData.TransactionInputUTXOEntries {
+					utxoEntries[k] = utxo.NewUTXOEntry(utxoEntry.Amount, utxoEntry.ScriptPublicKey,
+						utxoEntry.IsCoinbase, utxoEntry.BlockDAAScore)
+				}
+			}
+			transaction := MsgTxToDomainTransaction(singleTransactionAcceptanceData.Transaction)
+			transaction.Fee = singleTransactionAcceptanceData.Fee
+			transactionAcceptanceData[j] = &externalapi.TransactionAcceptanceData{
+				Transaction:                 transaction,
+				Fee:                         singleTransactionAcceptanceData.Fee,
+				IsAccepted:                  singleTransactionAcceptanceData.IsAccepted,
+				TransactionInputUTXOEntries: utxoEntries,
+			}
+		}
+		acceptanceData[i] = &externalapi.BlockAcceptanceData{
+			BlockHash:                 singleBlockAcceptanceData.BlockHash,
+			TransactionAcceptanceData: transactionAcceptanceData,
+		}
+	}
+	return acceptanceData
+}
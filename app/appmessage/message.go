@@ -73,6 +73,12 @@ const (
 	CmdRequestIBDChainBlockLocator
 	CmdIBDChainBlockLocator
 	CmdRequestAnticone
+	CmdCompactBlock
+	CmdRequestBlockTransactions
+	CmdBlockTransactions
+	CmdStemTransaction
+	CmdRequestHistoricalBlocks
+	CmdHistoricalBlocks
 
 	// rpc
 	CmdGetCurrentNetworkRequestMessage
@@ -163,6 +169,16 @@ const (
 	CmdGetMempoolEntriesByAddressesResponseMessage
 	CmdGetCoinSupplyRequestMessage
 	CmdGetCoinSupplyResponseMessage
+	CmdGetUTXOsByAddressesAtBlockRequestMessage
+	CmdGetUTXOsByAddressesAtBlockResponseMessage
+	CmdGetBalancesByAddressesAtBlockRequestMessage
+	CmdGetBalancesByAddressesAtBlockResponseMessage
+	CmdGetNetTotalsRequestMessage
+	CmdGetNetTotalsResponseMessage
+	CmdVerifyMessageRequestMessage
+	CmdVerifyMessageResponseMessage
+	CmdGetDAGRequestMessage
+	CmdGetDAGResponseMessage
 )
 
 // ProtocolMessageCommandToString maps all MessageCommands to their string representation
@@ -210,6 +226,12 @@ var ProtocolMessageCommandToString = map[MessageCommand]string{
 	CmdRequestIBDChainBlockLocator:                 "RequestIBDChainBlockLocator",
 	CmdIBDChainBlockLocator:                        "IBDChainBlockLocator",
 	CmdRequestAnticone:                             "RequestAnticone",
+	CmdCompactBlock:                                "CompactBlock",
+	CmdRequestBlockTransactions:                    "RequestBlockTransactions",
+	CmdBlockTransactions:                           "BlockTransactions",
+	CmdStemTransaction:                             "StemTransaction",
+	CmdRequestHistoricalBlocks:                     "RequestHistoricalBlocks",
+	CmdHistoricalBlocks:                            "HistoricalBlocks",
 }
 
 // RPCMessageCommandToString maps all MessageCommands to their string representation
@@ -300,6 +322,16 @@ var RPCMessageCommandToString = map[MessageCommand]string{
 	CmdGetMempoolEntriesByAddressesResponseMessage:                "GetMempoolEntriesByAddressesResponse",
 	CmdGetCoinSupplyRequestMessage:                                "GetCoinSupplyRequest",
 	CmdGetCoinSupplyResponseMessage:                               "GetCoinSupplyResponse",
+	CmdGetUTXOsByAddressesAtBlockRequestMessage:                   "GetUTXOsByAddressesAtBlockRequest",
+	CmdGetUTXOsByAddressesAtBlockResponseMessage:                  "GetUTXOsByAddressesAtBlockResponse",
+	CmdGetBalancesByAddressesAtBlockRequestMessage:                "GetBalancesByAddressesAtBlockRequest",
+	CmdGetBalancesByAddressesAtBlockResponseMessage:               "GetBalancesByAddressesAtBlockResponse",
+	CmdGetNetTotalsRequestMessage:                                 "GetNetTotalsRequest",
+	CmdGetNetTotalsResponseMessage:                                "GetNetTotalsResponse",
+	CmdVerifyMessageRequestMessage:                                "VerifyMessageRequest",
+	CmdVerifyMessageResponseMessage:                               "VerifyMessageResponse",
+	CmdGetDAGRequestMessage:                                       "GetDAGRequest",
+	CmdGetDAGResponseMessage:                                      "GetDAGResponse",
 }
 
 // Message is an interface that describes a sedra message. A type that
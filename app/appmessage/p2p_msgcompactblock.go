@@ -0,0 +1,50 @@
+package appmessage
+
+// MaxShortIDsPerCompactBlock is the maximum number of short transaction IDs
+// that can be in a single CompactBlock message.
+const MaxShortIDsPerCompactBlock = MaxInvPerMsg
+
+// PrefilledTransaction is a transaction that is sent in full inside a
+// MsgCompactBlock, alongside its index within the block.
+type PrefilledTransaction struct {
+	Index       uint32
+	Transaction *MsgTx
+}
+
+// MsgCompactBlock implements the Message interface and represents a sedra
+// CompactBlock message. It is used to relay a block to peers that support
+// compact block relay by sending its header, short IDs of its transactions,
+// and the transactions which the receiver is not expected to have (such as
+// the coinbase) in full.
+type MsgCompactBlock struct {
+	baseMessage
+	Header                MsgBlockHeader
+	ShortIDNonce          uint64
+	ShortIDs              []uint64
+	PrefilledTransactions []*PrefilledTransaction
+}
+
+// Command returns the protocol command string for the message. This is part
+// of the Message interface implementation.
+func (msg *MsgCompactBlock) Command() MessageCommand {
+	return CmdCompactBlock
+}
+
+// TransactionCount returns the total amount of transactions in the block
+// described by this message.
+func (msg *MsgCompactBlock) TransactionCount() int {
+	return len(msg.ShortIDs) + len(msg.PrefilledTransactions)
+}
+
+// NewMsgCompactBlock returns a new sedra CompactBlock message that conforms to
+// the Message interface. See MsgCompactBlock for details.
+func NewMsgCompactBlock(header *MsgBlockHeader, shortIDNonce uint64, shortIDs []uint64,
+	prefilledTransactions []*PrefilledTransaction) *MsgCompactBlock {
+
+	return &MsgCompactBlock{
+		Header:                *header,
+		ShortIDNonce:          shortIDNonce,
+		ShortIDs:              shortIDs,
+		PrefilledTransactions: prefilledTransactions,
+	}
+}
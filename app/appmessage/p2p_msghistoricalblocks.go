@@ -0,0 +1,53 @@
+package appmessage
+
+import (
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+)
+
+// MsgHistoricalBlocks implements the Message interface and represents a sedra
+// HistoricalBlocks message. It is sent in response to a MsgRequestHistoricalBlocks
+// and contains an entry for every requested hash, in the order they were requested.
+type MsgHistoricalBlocks struct {
+	baseMessage
+	Blocks []*HistoricalBlock
+}
+
+// HistoricalBlock is a single block sent in a MsgHistoricalBlocks message.
+// Block is nil if the responding node does not have the requested block.
+// AcceptanceData is empty if it was not requested, or if the responding
+// node does not have it.
+type HistoricalBlock struct {
+	Hash           *externalapi.DomainHash
+	Block          *MsgBlock
+	AcceptanceData []*BlockAcceptanceData
+}
+
+// BlockAcceptanceData holds the acceptance data of all the transactions
+// of a single block in the merge set of a historical block
+type BlockAcceptanceData struct {
+	BlockHash                 *externalapi.DomainHash
+	TransactionAcceptanceData []*TransactionAcceptanceData
+}
+
+// TransactionAcceptanceData holds whether a single transaction was accepted,
+// along with its fee and the UTXO entries it spent
+type TransactionAcceptanceData struct {
+	Transaction                 *MsgTx
+	Fee                         uint64
+	IsAccepted                  bool
+	TransactionInputUTXOEntries []*UTXOEntry
+}
+
+// Command returns the protocol command string for the message. This is part
+// of the Message interface implementation.
+func (msg *MsgHistoricalBlocks) Command() MessageCommand {
+	return CmdHistoricalBlocks
+}
+
+// NewMsgHistoricalBlocks returns a new sedra HistoricalBlocks message that conforms to
+// the Message interface. See MsgHistoricalBlocks for details.
+func NewMsgHistoricalBlocks(blocks []*HistoricalBlock) *MsgHistoricalBlocks {
+	return &MsgHistoricalBlocks{
+		Blocks: blocks,
+	}
+}
@@ -0,0 +1,30 @@
+package appmessage
+
+import (
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+)
+
+// MsgRequestBlockTransactions implements the Message interface and represents a sedra
+// RequestBlockTransactions message. It is used to request the transactions of a
+// compact block that the requesting peer failed to find in its mempool, by their
+// indexes within the block.
+type MsgRequestBlockTransactions struct {
+	baseMessage
+	BlockHash *externalapi.DomainHash
+	Indexes   []uint32
+}
+
+// Command returns the protocol command string for the message. This is part
+// of the Message interface implementation.
+func (msg *MsgRequestBlockTransactions) Command() MessageCommand {
+	return CmdRequestBlockTransactions
+}
+
+// NewMsgRequestBlockTransactions returns a new sedra RequestBlockTransactions message that conforms to
+// the Message interface. See MsgRequestBlockTransactions for details.
+func NewMsgRequestBlockTransactions(blockHash *externalapi.DomainHash, indexes []uint32) *MsgRequestBlockTransactions {
+	return &MsgRequestBlockTransactions{
+		BlockHash: blockHash,
+		Indexes:   indexes,
+	}
+}
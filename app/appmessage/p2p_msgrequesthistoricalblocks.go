@@ -0,0 +1,36 @@
+package appmessage
+
+import (
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+)
+
+// MaxRequestHistoricalBlocksHashes is the maximum number of hashes that can
+// be in a single RequestHistoricalBlocks message.
+const MaxRequestHistoricalBlocksHashes = 100
+
+// MsgRequestHistoricalBlocks implements the Message interface and represents a sedra
+// RequestHistoricalBlocks message. It is used by pruned nodes to request blocks,
+// and optionally their acceptance data, that they had already pruned from archival
+// nodes.
+type MsgRequestHistoricalBlocks struct {
+	baseMessage
+	Hashes                []*externalapi.DomainHash
+	IncludeAcceptanceData bool
+}
+
+// Command returns the protocol command string for the message. This is part
+// of the Message interface implementation.
+func (msg *MsgRequestHistoricalBlocks) Command() MessageCommand {
+	return CmdRequestHistoricalBlocks
+}
+
+// NewMsgRequestHistoricalBlocks returns a new sedra RequestHistoricalBlocks message that conforms to
+// the Message interface. See MsgRequestHistoricalBlocks for details.
+func NewMsgRequestHistoricalBlocks(hashes []*externalapi.DomainHash,
+	includeAcceptanceData bool) *MsgRequestHistoricalBlocks {
+
+	return &MsgRequestHistoricalBlocks{
+		Hashes:                hashes,
+		IncludeAcceptanceData: includeAcceptanceData,
+	}
+}
@@ -0,0 +1,24 @@
+package appmessage
+
+// MsgStemTransaction implements the Message interface and represents a sedra
+// StemTransaction message. It is used to pass a transaction along the stem
+// phase of Dandelion++ relay, during which the transaction is forwarded to a
+// single peer instead of being announced to the whole network.
+type MsgStemTransaction struct {
+	baseMessage
+	Transaction *MsgTx
+}
+
+// Command returns the protocol command string for the message. This is part
+// of the Message interface implementation.
+func (msg *MsgStemTransaction) Command() MessageCommand {
+	return CmdStemTransaction
+}
+
+// NewMsgStemTransaction returns a new sedra StemTransaction message that conforms to
+// the Message interface. See MsgStemTransaction for details.
+func NewMsgStemTransaction(transaction *MsgTx) *MsgStemTransaction {
+	return &MsgStemTransaction{
+		Transaction: transaction,
+	}
+}
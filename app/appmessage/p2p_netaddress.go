@@ -16,6 +16,10 @@ type NetAddress struct {
 	// Last time the address was seen.
 	Timestamp mstime.Time
 
+	// Services the peer advertised the last time it was connected to.
+	// It is 0 if the services of the peer are unknown.
+	Services ServiceFlag
+
 	// IP address of the peer.
 	IP net.IP
 
@@ -59,6 +63,12 @@ func NewNetAddress(addr *net.TCPAddr) *NetAddress {
 	return NewNetAddressIPPort(addr.IP, uint16(addr.Port))
 }
 
+// HasServices returns whether the peer at this address is known to
+// support all the given services
+func (na *NetAddress) HasServices(services ServiceFlag) bool {
+	return na.Services&services == services
+}
+
 func (na NetAddress) String() string {
 	return na.TCPAddress().String()
 }
@@ -10,12 +10,6 @@ import (
 	"strings"
 )
 
-const (
-	// DefaultServices describes the default services that are supported by
-	// the server.
-	DefaultServices = SFNodeNetwork | SFNodeBloom | SFNodeCF
-)
-
 // ServiceFlag identifies services supported by a sedra peer.
 type ServiceFlag uint64
 
@@ -23,34 +17,57 @@ const (
 	// SFNodeNetwork is a flag used to indicate a peer is a full node.
 	SFNodeNetwork ServiceFlag = 1 << iota
 
-	// SFNodeGetUTXO is a flag used to indicate a peer supports the
-	// getutxos and utxos commands (BIP0064).
+	// SFNodeGetUTXO is a legacy flag inherited from btcd. It is never
+	// advertised by sedrad, and is kept so that its bit is not reused.
 	SFNodeGetUTXO
 
-	// SFNodeBloom is a flag used to indicate a peer supports bloom
-	// filtering.
+	// SFNodeBloom is a legacy flag inherited from btcd. It used to be
+	// advertised by older versions of sedrad even though bloom filtering
+	// was never supported, so its bit must not be reused.
 	SFNodeBloom
 
-	// SFNodeXthin is a flag used to indicate a peer supports xthin blocks.
+	// SFNodeXthin is a legacy flag inherited from btcd. It is never
+	// advertised by sedrad, and is kept so that its bit is not reused.
 	SFNodeXthin
 
-	// SFNodeBit5 is a flag used to indicate a peer supports a service
-	// defined by bit 5.
+	// SFNodeBit5 is a legacy flag inherited from btcd. It is never
+	// advertised by sedrad, and is kept so that its bit is not reused.
 	SFNodeBit5
 
-	// SFNodeCF is a flag used to indicate a peer supports committed
-	// filters (CFs).
+	// SFNodeCF is a legacy flag inherited from btcd. It used to be
+	// advertised by older versions of sedrad even though committed filters
+	// were never supported, so its bit must not be reused.
 	SFNodeCF
+
+	// SFNodeArchival is a flag used to indicate a peer keeps all block
+	// data, including the data below its pruning point.
+	SFNodeArchival
+
+	// SFNodeUTXOIndex is a flag used to indicate a peer maintains a UTXO
+	// index.
+	SFNodeUTXOIndex
+
+	// SFNodePruningUTXOSet is a flag used to indicate a peer serves its
+	// pruning point UTXO set to peers that sync from it with a headers proof.
+	SFNodePruningUTXOSet
+
+	// SFNodeBlocksOnly is a flag used to indicate a peer does not accept
+	// transactions from its peers.
+	SFNodeBlocksOnly
 )
 
 // Map of service flags back to their constant names for pretty printing.
 var sfStrings = map[ServiceFlag]string{
-	SFNodeNetwork: "SFNodeNetwork",
-	SFNodeGetUTXO: "SFNodeGetUTXO",
-	SFNodeBloom:   "SFNodeBloom",
-	SFNodeXthin:   "SFNodeXthin",
-	SFNodeBit5:    "SFNodeBit5",
-	SFNodeCF:      "SFNodeCF",
+	SFNodeNetwork:        "SFNodeNetwork",
+	SFNodeGetUTXO:        "SFNodeGetUTXO",
+	SFNodeBloom:          "SFNodeBloom",
+	SFNodeXthin:          "SFNodeXthin",
+	SFNodeBit5:           "SFNodeBit5",
+	SFNodeCF:             "SFNodeCF",
+	SFNodeArchival:       "SFNodeArchival",
+	SFNodeUTXOIndex:      "SFNodeUTXOIndex",
+	SFNodePruningUTXOSet: "SFNodePruningUTXOSet",
+	SFNodeBlocksOnly:     "SFNodeBlocksOnly",
 }
 
 // orderedSFStrings is an ordered list of service flags from highest to
@@ -62,6 +79,10 @@ var orderedSFStrings = []ServiceFlag{
 	SFNodeXthin,
 	SFNodeBit5,
 	SFNodeCF,
+	SFNodeArchival,
+	SFNodeUTXOIndex,
+	SFNodePruningUTXOSet,
+	SFNodeBlocksOnly,
 }
 
 // String returns the ServiceFlag in human-readable form.
@@ -19,7 +19,12 @@ func TestServiceFlagStringer(t *testing.T) {
 		{SFNodeXthin, "SFNodeXthin"},
 		{SFNodeBit5, "SFNodeBit5"},
 		{SFNodeCF, "SFNodeCF"},
-		{0xffffffff, "SFNodeNetwork|SFNodeGetUTXO|SFNodeBloom|SFNodeXthin|SFNodeBit5|SFNodeCF|0xffffffc0"},
+		{SFNodeArchival, "SFNodeArchival"},
+		{SFNodeUTXOIndex, "SFNodeUTXOIndex"},
+		{SFNodePruningUTXOSet, "SFNodePruningUTXOSet"},
+		{SFNodeBlocksOnly, "SFNodeBlocksOnly"},
+		{0xffffffff, "SFNodeNetwork|SFNodeGetUTXO|SFNodeBloom|SFNodeXthin|SFNodeBit5|SFNodeCF|" +
+			"SFNodeArchival|SFNodeUTXOIndex|SFNodePruningUTXOSet|SFNodeBlocksOnly|0xfffffc00"},
 	}
 
 	t.Logf("Running %d tests", len(tests))
@@ -0,0 +1,55 @@
+package appmessage
+
+// GetBalancesByAddressesAtBlockRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GetBalancesByAddressesAtBlockRequestMessage struct {
+	baseMessage
+	Addresses []string
+	BlockHash string
+	DAAScore  *uint64
+	Timestamp *int64
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetBalancesByAddressesAtBlockRequestMessage) Command() MessageCommand {
+	return CmdGetBalancesByAddressesAtBlockRequestMessage
+}
+
+// NewGetBalancesByAddressesAtBlockRequest returns a instance of the message
+func NewGetBalancesByAddressesAtBlockRequest(addresses []string, blockHash string,
+	daaScore *uint64, timestamp *int64) *GetBalancesByAddressesAtBlockRequestMessage {
+
+	return &GetBalancesByAddressesAtBlockRequestMessage{
+		Addresses: addresses,
+		BlockHash: blockHash,
+		DAAScore:  daaScore,
+		Timestamp: timestamp,
+	}
+}
+
+// GetBalancesByAddressesAtBlockResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GetBalancesByAddressesAtBlockResponseMessage struct {
+	baseMessage
+	BlockHash string
+	DAAScore  uint64
+	Entries   []*BalancesByAddressesEntry
+
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetBalancesByAddressesAtBlockResponseMessage) Command() MessageCommand {
+	return CmdGetBalancesByAddressesAtBlockResponseMessage
+}
+
+// NewGetBalancesByAddressesAtBlockResponse returns an instance of the message
+func NewGetBalancesByAddressesAtBlockResponse(blockHash string, daaScore uint64,
+	entries []*BalancesByAddressesEntry) *GetBalancesByAddressesAtBlockResponseMessage {
+
+	return &GetBalancesByAddressesAtBlockResponseMessage{
+		BlockHash: blockHash,
+		DAAScore:  daaScore,
+		Entries:   entries,
+	}
+}
@@ -4,8 +4,10 @@ package appmessage
 // its respective RPC message
 type GetBlockRequestMessage struct {
 	baseMessage
-	Hash                string
-	IncludeTransactions bool
+	Hash                          string
+	IncludeTransactions           bool
+	FetchFromArchivalPeers        bool
+	IncludeAcceptedTransactionIDs bool
 }
 
 // Command returns the protocol command string for the message
@@ -25,7 +27,8 @@ func NewGetBlockRequestMessage(hash string, includeTransactions bool) *GetBlockR
 // its respective RPC message
 type GetBlockResponseMessage struct {
 	baseMessage
-	Block *RPCBlock
+	Block                  *RPCBlock
+	AcceptedTransactionIDs []string
 
 	Error *RPCError
 }
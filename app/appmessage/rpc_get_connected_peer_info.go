@@ -47,4 +47,15 @@ type GetConnectedPeerInfoMessage struct {
 	AdvertisedProtocolVersion uint32
 	TimeConnected             int64
 	IsIBDPeer                 bool
+	MisbehaviorScore          float64
+	RecentViolations          []*PeerViolation
+	BytesSent                 uint64
+	BytesReceived             uint64
+}
+
+// PeerViolation holds information about a single violation of a connected peer
+type PeerViolation struct {
+	Misbehavior string
+	Reason      string
+	Timestamp   int64
 }
@@ -0,0 +1,71 @@
+package appmessage
+
+// GetDAGRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GetDAGRequestMessage struct {
+	baseMessage
+	LowHash           string
+	HighHash          string
+	LastDAABlockCount uint64
+	IncludeDOT        bool
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetDAGRequestMessage) Command() MessageCommand {
+	return CmdGetDAGRequestMessage
+}
+
+// NewGetDAGRequestMessage returns a instance of the message
+func NewGetDAGRequestMessage(lowHash string, highHash string, lastDAABlockCount uint64,
+	includeDOT bool) *GetDAGRequestMessage {
+
+	return &GetDAGRequestMessage{
+		LowHash:           lowHash,
+		HighHash:          highHash,
+		LastDAABlockCount: lastDAABlockCount,
+		IncludeDOT:        includeDOT,
+	}
+}
+
+// GetDAGResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GetDAGResponseMessage struct {
+	baseMessage
+	Blocks []*RPCDAGBlock
+	DOT    string
+
+	Error *RPCError
+}
+
+// RPCDAGBlock is a block of a sub-DAG, along with its GHOSTDAG data
+type RPCDAGBlock struct {
+	Hash                string
+	ParentHashes        []string
+	SelectedParentHash  string
+	BlueScore           uint64
+	DAAScore            uint64
+	Timestamp           int64
+	IsChainBlock        bool
+	Color               string
+	MergeSetBluesHashes []string
+	MergeSetRedsHashes  []string
+}
+
+// The colors of an RPCDAGBlock
+const (
+	RPCDAGBlockColorBlue = "blue"
+	RPCDAGBlockColorRed  = "red"
+)
+
+// Command returns the protocol command string for the message
+func (msg *GetDAGResponseMessage) Command() MessageCommand {
+	return CmdGetDAGResponseMessage
+}
+
+// NewGetDAGResponseMessage returns a instance of the message
+func NewGetDAGResponseMessage(blocks []*RPCDAGBlock, dot string) *GetDAGResponseMessage {
+	return &GetDAGResponseMessage{
+		Blocks: blocks,
+		DOT:    dot,
+	}
+}
@@ -26,6 +26,8 @@ type GetInfoResponseMessage struct {
 	IsUtxoIndexed bool
 	IsSynced      bool
 
+	MinimumRelayTransactionFee uint64
+
 	Error *RPCError
 }
 
@@ -35,12 +37,15 @@ func (msg *GetInfoResponseMessage) Command() MessageCommand {
 }
 
 // NewGetInfoResponseMessage returns a instance of the message
-func NewGetInfoResponseMessage(p2pID string, mempoolSize uint64, serverVersion string, isUtxoIndexed bool, isSynced bool) *GetInfoResponseMessage {
+func NewGetInfoResponseMessage(p2pID string, mempoolSize uint64, serverVersion string, isUtxoIndexed bool, isSynced bool,
+	minimumRelayTransactionFee uint64) *GetInfoResponseMessage {
+
 	return &GetInfoResponseMessage{
-		P2PID:         p2pID,
-		MempoolSize:   mempoolSize,
-		ServerVersion: serverVersion,
-		IsUtxoIndexed: isUtxoIndexed,
-		IsSynced:      isSynced,
+		P2PID:                      p2pID,
+		MempoolSize:                mempoolSize,
+		ServerVersion:              serverVersion,
+		IsUtxoIndexed:              isUtxoIndexed,
+		IsSynced:                   isSynced,
+		MinimumRelayTransactionFee: minimumRelayTransactionFee,
 	}
 }
@@ -0,0 +1,55 @@
+package appmessage
+
+// GetNetTotalsRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GetNetTotalsRequestMessage struct {
+	baseMessage
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetNetTotalsRequestMessage) Command() MessageCommand {
+	return CmdGetNetTotalsRequestMessage
+}
+
+// NewGetNetTotalsRequestMessage returns a instance of the message
+func NewGetNetTotalsRequestMessage() *GetNetTotalsRequestMessage {
+	return &GetNetTotalsRequestMessage{}
+}
+
+// GetNetTotalsResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GetNetTotalsResponseMessage struct {
+	baseMessage
+	TotalBytesSent     uint64
+	TotalBytesReceived uint64
+	TimeStarted        int64
+	MessageTypeTotals  []*NetMessageTypeTotals
+
+	Error *RPCError
+}
+
+// NetMessageTypeTotals holds the p2p traffic of a single message type
+type NetMessageTypeTotals struct {
+	MessageType      string
+	BytesSent        uint64
+	BytesReceived    uint64
+	MessagesSent     uint64
+	MessagesReceived uint64
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetNetTotalsResponseMessage) Command() MessageCommand {
+	return CmdGetNetTotalsResponseMessage
+}
+
+// NewGetNetTotalsResponseMessage returns a instance of the message
+func NewGetNetTotalsResponseMessage(totalBytesSent uint64, totalBytesReceived uint64, timeStarted int64,
+	messageTypeTotals []*NetMessageTypeTotals) *GetNetTotalsResponseMessage {
+
+	return &GetNetTotalsResponseMessage{
+		TotalBytesSent:     totalBytesSent,
+		TotalBytesReceived: totalBytesReceived,
+		TimeStarted:        timeStarted,
+		MessageTypeTotals:  messageTypeTotals,
+	}
+}
@@ -0,0 +1,55 @@
+package appmessage
+
+// GetUTXOsByAddressesAtBlockRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GetUTXOsByAddressesAtBlockRequestMessage struct {
+	baseMessage
+	Addresses []string
+	BlockHash string
+	DAAScore  *uint64
+	Timestamp *int64
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetUTXOsByAddressesAtBlockRequestMessage) Command() MessageCommand {
+	return CmdGetUTXOsByAddressesAtBlockRequestMessage
+}
+
+// NewGetUTXOsByAddressesAtBlockRequestMessage returns a instance of the message
+func NewGetUTXOsByAddressesAtBlockRequestMessage(addresses []string, blockHash string,
+	daaScore *uint64, timestamp *int64) *GetUTXOsByAddressesAtBlockRequestMessage {
+
+	return &GetUTXOsByAddressesAtBlockRequestMessage{
+		Addresses: addresses,
+		BlockHash: blockHash,
+		DAAScore:  daaScore,
+		Timestamp: timestamp,
+	}
+}
+
+// GetUTXOsByAddressesAtBlockResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GetUTXOsByAddressesAtBlockResponseMessage struct {
+	baseMessage
+	BlockHash string
+	DAAScore  uint64
+	Entries   []*UTXOsByAddressesEntry
+
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetUTXOsByAddressesAtBlockResponseMessage) Command() MessageCommand {
+	return CmdGetUTXOsByAddressesAtBlockResponseMessage
+}
+
+// NewGetUTXOsByAddressesAtBlockResponseMessage returns a instance of the message
+func NewGetUTXOsByAddressesAtBlockResponseMessage(blockHash string, daaScore uint64,
+	entries []*UTXOsByAddressesEntry) *GetUTXOsByAddressesAtBlockResponseMessage {
+
+	return &GetUTXOsByAddressesAtBlockResponseMessage{
+		BlockHash: blockHash,
+		DAAScore:  daaScore,
+		Entries:   entries,
+	}
+}
@@ -0,0 +1,47 @@
+package appmessage
+
+// VerifyMessageRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type VerifyMessageRequestMessage struct {
+	baseMessage
+	Address   string
+	Message   string
+	Signature string
+}
+
+// Command returns the protocol command string for the message
+func (msg *VerifyMessageRequestMessage) Command() MessageCommand {
+	return CmdVerifyMessageRequestMessage
+}
+
+// NewVerifyMessageRequestMessage returns a instance of the message
+func NewVerifyMessageRequestMessage(address string, message string, signature string) *VerifyMessageRequestMessage {
+	return &VerifyMessageRequestMessage{
+		Address:   address,
+		Message:   message,
+		Signature: signature,
+	}
+}
+
+// VerifyMessageResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type VerifyMessageResponseMessage struct {
+	baseMessage
+	IsValid       bool
+	InvalidReason string
+
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *VerifyMessageResponseMessage) Command() MessageCommand {
+	return CmdVerifyMessageResponseMessage
+}
+
+// NewVerifyMessageResponseMessage returns a instance of the message
+func NewVerifyMessageResponseMessage(isValid bool, invalidReason string) *VerifyMessageResponseMessage {
+	return &VerifyMessageResponseMessage{
+		IsValid:       isValid,
+		InvalidReason: invalidReason,
+	}
+}
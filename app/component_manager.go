@@ -10,6 +10,7 @@ import (
 
 	"github.com/sedracoin/sedrad/app/protocol"
 	"github.com/sedracoin/sedrad/app/rpc"
+	"github.com/sedracoin/sedrad/app/rpc/dagviewer"
 	"github.com/sedracoin/sedrad/domain"
 	"github.com/sedracoin/sedrad/domain/consensus"
 	"github.com/sedracoin/sedrad/domain/utxoindex"
@@ -30,6 +31,7 @@ type ComponentManager struct {
 	rpcManager        *rpc.Manager
 	connectionManager *connmanager.ConnectionManager
 	netAdapter        *netadapter.NetAdapter
+	dagViewer         *dagviewer.Server
 
 	started, shutdown int32
 }
@@ -49,6 +51,13 @@ func (a *ComponentManager) Start() {
 	}
 
 	a.connectionManager.Start()
+
+	if a.dagViewer != nil {
+		err := a.dagViewer.Start()
+		if err != nil {
+			panics.Exit(log, fmt.Sprintf("Error starting the DAG viewer: %+v", err))
+		}
+	}
 }
 
 // Stop gracefully shuts down all the Sedrad Services.
@@ -61,6 +70,13 @@ func (a *ComponentManager) Stop() {
 
 	log.Warnf("sedrad shutting down")
 
+	if a.dagViewer != nil {
+		err := a.dagViewer.Stop()
+		if err != nil {
+			log.Errorf("Error stopping the DAG viewer: %+v", err)
+		}
+	}
+
 	a.connectionManager.Stop()
 
 	err := a.netAdapter.Stop()
@@ -79,6 +95,19 @@ func (a *ComponentManager) Stop() {
 func NewComponentManager(cfg *config.Config, db infrastructuredatabase.Database, interrupt chan<- struct{}) (
 	*ComponentManager, error) {
 
+	netAdapter, err := netadapter.NewNetAdapter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewComponentManagerWithNetAdapter(cfg, db, netAdapter, interrupt)
+}
+
+// NewComponentManagerWithNetAdapter returns a new ComponentManager instance
+// that uses the given NetAdapter, e.g. one that runs over a simulated network.
+// Use Start() to begin all services within this ComponentManager
+func NewComponentManagerWithNetAdapter(cfg *config.Config, db infrastructuredatabase.Database,
+	netAdapter *netadapter.NetAdapter, interrupt chan<- struct{}) (*ComponentManager, error) {
+
 	consensusConfig := consensus.Config{
 		Params:                          *cfg.ActiveNetParams,
 		IsArchival:                      cfg.IsArchivalNode,
@@ -93,11 +122,6 @@ func NewComponentManager(cfg *config.Config, db infrastructuredatabase.Database,
 		return nil, err
 	}
 
-	netAdapter, err := netadapter.NewNetAdapter(cfg)
-	if err != nil {
-		return nil, err
-	}
-
 	addressManager, err := addressmanager.New(addressmanager.NewConfig(cfg), db)
 	if err != nil {
 		return nil, err
@@ -123,6 +147,14 @@ func NewComponentManager(cfg *config.Config, db infrastructuredatabase.Database,
 	}
 	rpcManager := setupRPC(cfg, domain, netAdapter, protocolManager, connectionManager, addressManager, utxoIndex, domain.ConsensusEventsChannel(), interrupt)
 
+	var dagViewer *dagviewer.Server
+	if cfg.DAGViewerListen != "" {
+		dagViewer, err = dagviewer.New(rpcManager.Context(), cfg.DAGViewerListen)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &ComponentManager{
 		cfg:               cfg,
 		protocolManager:   protocolManager,
@@ -130,6 +162,7 @@ func NewComponentManager(cfg *config.Config, db infrastructuredatabase.Database,
 		connectionManager: connectionManager,
 		netAdapter:        netAdapter,
 		addressManager:    addressManager,
+		dagViewer:         dagViewer,
 	}, nil
 
 }
@@ -172,3 +205,8 @@ func (a *ComponentManager) P2PNodeID() *id.ID {
 func (a *ComponentManager) AddressManager() *addressmanager.AddressManager {
 	return a.addressManager
 }
+
+// ProtocolManager returns the protocol.Manager associated with this ComponentManager
+func (a *ComponentManager) ProtocolManager() *protocol.Manager {
+	return a.protocolManager
+}
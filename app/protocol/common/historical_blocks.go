@@ -0,0 +1,22 @@
+package common
+
+import (
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+)
+
+// HistoricalBlocksRequest is a request for blocks that were already pruned
+// by this node, sent to an archival peer
+type HistoricalBlocksRequest struct {
+	Hashes                []*externalapi.DomainHash
+	IncludeAcceptanceData bool
+}
+
+// HistoricalBlock is a block that was fetched from an archival peer and
+// verified against the header this node holds for it. Block is nil if the
+// peer does not have the block, and AcceptanceData is nil if it was not
+// requested or the peer does not have it.
+type HistoricalBlock struct {
+	Hash           *externalapi.DomainHash
+	Block          *externalapi.DomainBlock
+	AcceptanceData externalapi.AcceptanceData
+}
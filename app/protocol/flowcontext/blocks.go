@@ -80,7 +80,8 @@ func (f *FlowContext) broadcastTransactionsAfterBlockAdded(
 		if err != nil {
 			return err
 		}
-		txIDsToRebroadcast = consensushashing.TransactionIDs(txsToRebroadcast)
+		// Transactions in their stem phase are fluffed once their embargo expires
+		txIDsToRebroadcast = f.withoutEmbargoedTransactions(consensushashing.TransactionIDs(txsToRebroadcast))
 		f.lastRebroadcastTime = time.Now()
 	}
 
@@ -0,0 +1,263 @@
+package flowcontext
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	peerpkg "github.com/sedracoin/sedrad/app/protocol/peer"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter"
+)
+
+const (
+	// dandelionEpochDuration is the duration of a Dandelion++ epoch. At the start of
+	// every epoch the node decides anew whether it stems or fluffs transactions, and
+	// selects the peer it stems them to.
+	dandelionEpochDuration = 10 * time.Minute
+
+	// dandelionFluffProbability is the probability of the node to fluff, rather
+	// than stem, all the transactions it relays during an epoch.
+	dandelionFluffProbability = 0.1
+
+	// dandelionEmbargoTimeout is the minimal time a stemmed transaction is waited for
+	// to be fluffed by some other node, before it is fluffed by this node.
+	dandelionEmbargoTimeout = 30 * time.Second
+
+	// dandelionEmbargoMaxJitter is the maximal random time that is added to
+	// dandelionEmbargoTimeout, so that the first node to fluff a transaction whose
+	// stem was cut does not reveal its position along the stem.
+	dandelionEmbargoMaxJitter = 15 * time.Second
+
+	// StemTransactionsProtocolVersion is the first protocol version in which
+	// peers understand appmessage.MsgStemTransaction
+	StemTransactionsProtocolVersion = 6
+)
+
+// dandelionState holds the Dandelion++ state of the current epoch, and the
+// embargo timers of all the transactions that are in their stem phase.
+type dandelionState struct {
+	lock           sync.Mutex
+	epochStartTime time.Time
+	isFluffEpoch   bool
+	stemPeer       *peerpkg.Peer
+	embargoes      map[externalapi.DomainTransactionID]embargoTimer
+
+	// now, random and afterFunc are the clock, the source of randomness and the
+	// timers of the state. They're replaced in tests to make them deterministic.
+	// random isn't safe for concurrent use, so it's used with the lock held.
+	now       func() time.Time
+	random    *rand.Rand
+	afterFunc func(duration time.Duration, onExpired func()) embargoTimer
+}
+
+// embargoTimer is the timer that fluffs a transaction once its embargo expires
+type embargoTimer interface {
+	Stop() bool
+}
+
+func newDandelionState() *dandelionState {
+	return &dandelionState{
+		embargoes: make(map[externalapi.DomainTransactionID]embargoTimer),
+		now:       time.Now,
+		random:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		afterFunc: func(duration time.Duration, onExpired func()) embargoTimer {
+			return time.AfterFunc(duration, onExpired)
+		},
+	}
+}
+
+// StemTransactions relays the given transactions, which were either submitted
+// locally or received in their stem phase from sourcePeer. sourcePeer is nil for
+// local transactions. If Dandelion++ is enabled and this node stems transactions
+// in the current epoch, they are sent to the epoch's stem peer alone, and are
+// fluffed by this node only if their embargo expires before they are announced by
+// any other peer. Otherwise, they are broadcast to all peers.
+func (f *FlowContext) StemTransactions(transactions []*externalapi.DomainTransaction, sourcePeer *peerpkg.Peer) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	var stemPeer *peerpkg.Peer
+	if f.Config().Dandelion {
+		stemPeer = f.dandelionStemPeer(sourcePeer)
+	}
+	if stemPeer == nil {
+		return f.EnqueueTransactionIDsForPropagation(consensushashing.TransactionIDs(transactions))
+	}
+
+	log.Debugf("Stemming %d transactions to %s", len(transactions), stemPeer)
+	for _, transaction := range transactions {
+		f.embargoTransaction(consensushashing.TransactionID(transaction))
+
+		msgStemTransaction := appmessage.NewMsgStemTransaction(appmessage.DomainTransactionToMsgTx(transaction))
+		err := f.netAdapter.P2PBroadcast([]*netadapter.NetConnection{stemPeer.Connection()}, msgStemTransaction)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsTransactionEmbargoed returns whether the given transaction is in its stem
+// phase, and should thus not be revealed to peers that ask for it.
+func (f *FlowContext) IsTransactionEmbargoed(transactionID *externalapi.DomainTransactionID) bool {
+	f.dandelion.lock.Lock()
+	defer f.dandelion.lock.Unlock()
+
+	_, ok := f.dandelion.embargoes[*transactionID]
+	return ok
+}
+
+// ClearTransactionEmbargoes cancels the embargoes of the given transactions. It is
+// called once they are announced by some peer, which means they have already been
+// fluffed and there's no need to fluff them again.
+func (f *FlowContext) ClearTransactionEmbargoes(transactionIDs []*externalapi.DomainTransactionID) {
+	f.dandelion.lock.Lock()
+	defer f.dandelion.lock.Unlock()
+
+	for _, transactionID := range transactionIDs {
+		f.clearEmbargo(transactionID)
+	}
+}
+
+// clearEmbargo removes the embargo of the given transaction, and returns whether
+// such an embargo existed. It must be called with the dandelion lock held.
+func (f *FlowContext) clearEmbargo(transactionID *externalapi.DomainTransactionID) bool {
+	timer, ok := f.dandelion.embargoes[*transactionID]
+	if !ok {
+		return false
+	}
+	timer.Stop()
+	delete(f.dandelion.embargoes, *transactionID)
+	return true
+}
+
+func (f *FlowContext) embargoTransaction(transactionID *externalapi.DomainTransactionID) {
+	f.dandelion.lock.Lock()
+	defer f.dandelion.lock.Unlock()
+
+	if _, ok := f.dandelion.embargoes[*transactionID]; ok {
+		return
+	}
+
+	embargoTimeout := dandelionEmbargoTimeout + time.Duration(f.dandelion.random.Int63n(int64(dandelionEmbargoMaxJitter)))
+	f.dandelion.embargoes[*transactionID] = f.dandelion.afterFunc(embargoTimeout, func() {
+		f.onEmbargoExpired(transactionID)
+	})
+}
+
+func (f *FlowContext) onEmbargoExpired(transactionID *externalapi.DomainTransactionID) {
+	f.dandelion.lock.Lock()
+	wasEmbargoed := f.clearEmbargo(transactionID)
+	f.dandelion.lock.Unlock()
+	if !wasEmbargoed {
+		return
+	}
+
+	// The transaction might have been meanwhile included in a block or evicted
+	if _, _, ok := f.Domain().MiningManager().GetTransaction(transactionID, true, false); !ok {
+		return
+	}
+
+	log.Debugf("Embargo of transaction %s has expired. Fluffing it", transactionID)
+	err := f.EnqueueTransactionIDsForPropagation([]*externalapi.DomainTransactionID{transactionID})
+	if err != nil {
+		log.Warnf("Failed to fluff transaction %s: %s", transactionID, err)
+	}
+}
+
+// withoutEmbargoedTransactions returns the given transaction IDs excluding the ones
+// that are currently in their stem phase
+func (f *FlowContext) withoutEmbargoedTransactions(
+	transactionIDs []*externalapi.DomainTransactionID) []*externalapi.DomainTransactionID {
+
+	f.dandelion.lock.Lock()
+	defer f.dandelion.lock.Unlock()
+
+	if len(f.dandelion.embargoes) == 0 {
+		return transactionIDs
+	}
+	filtered := make([]*externalapi.DomainTransactionID, 0, len(transactionIDs))
+	for _, transactionID := range transactionIDs {
+		if _, ok := f.dandelion.embargoes[*transactionID]; !ok {
+			filtered = append(filtered, transactionID)
+		}
+	}
+	return filtered
+}
+
+// dandelionStemPeer returns the peer that transactions from sourcePeer are stemmed
+// to, which is the stem peer of the current epoch unless it's sourcePeer itself.
+// It starts a new epoch if the current one is over, and returns nil if
+// transactions should be fluffed.
+func (f *FlowContext) dandelionStemPeer(sourcePeer *peerpkg.Peer) *peerpkg.Peer {
+	f.dandelion.lock.Lock()
+	defer f.dandelion.lock.Unlock()
+
+	now := f.dandelion.now()
+	if now.Sub(f.dandelion.epochStartTime) > dandelionEpochDuration {
+		f.dandelion.epochStartTime = now
+		f.dandelion.isFluffEpoch = f.dandelion.random.Float64() < dandelionFluffProbability
+		f.dandelion.stemPeer = nil
+		log.Debugf("Started a new Dandelion++ epoch. Fluff epoch: %t", f.dandelion.isFluffEpoch)
+	}
+
+	if f.dandelion.isFluffEpoch {
+		return nil
+	}
+	if f.dandelion.stemPeer == nil || !f.isReadyPeer(f.dandelion.stemPeer) {
+		f.selectStemPeer()
+	}
+
+	// Transactions are never stemmed back to the peer they came from, since
+	// that would both loop them and reveal the stem peer to that peer
+	if sourcePeer != nil && f.dandelion.stemPeer == sourcePeer {
+		return f.randomStemPeerCandidate(sourcePeer)
+	}
+	return f.dandelion.stemPeer
+}
+
+// selectStemPeer must be called with the dandelion lock held
+func (f *FlowContext) selectStemPeer() {
+	f.dandelion.stemPeer = f.randomStemPeerCandidate(nil)
+	if f.dandelion.stemPeer == nil {
+		log.Debugf("No peer is eligible for being a stem peer. Transactions will be fluffed")
+		return
+	}
+	log.Debugf("Selected %s as the stem peer of the current Dandelion++ epoch", f.dandelion.stemPeer)
+}
+
+// randomStemPeerCandidate returns a random peer, other than excludedPeer, that is
+// eligible for being a stem peer, or nil if there's no such peer. It must be
+// called with the dandelion lock held.
+func (f *FlowContext) randomStemPeerCandidate(excludedPeer *peerpkg.Peer) *peerpkg.Peer {
+	// Only outbound peers are eligible for being stem peers, since
+	// inbound connections are much cheaper for an attacker to obtain
+	var candidates []*peerpkg.Peer
+	for _, peer := range f.Peers() {
+		if peer != excludedPeer && peer.IsOutbound() && peer.ProtocolVersion() >= StemTransactionsProtocolVersion {
+			candidates = append(candidates, peer)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	// The candidates are sorted so that the selection depends on the random source alone
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].ID().String() < candidates[j].ID().String()
+	})
+	return candidates[f.dandelion.random.Intn(len(candidates))]
+}
+
+func (f *FlowContext) isReadyPeer(peer *peerpkg.Peer) bool {
+	f.peersMutex.RLock()
+	defer f.peersMutex.RUnlock()
+
+	readyPeer, ok := f.peers[*peer.ID()]
+	return ok && readyPeer == peer
+}
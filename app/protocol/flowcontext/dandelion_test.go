@@ -0,0 +1,387 @@
+package flowcontext
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	peerpkg "github.com/sedracoin/sedrad/app/protocol/peer"
+	"github.com/sedracoin/sedrad/domain"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/constants"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/subnetworks"
+	"github.com/sedracoin/sedrad/domain/miningmanager"
+	"github.com/sedracoin/sedrad/infrastructure/config"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/id"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/router"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/server"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/server/simulatedserver"
+)
+
+// dandelionTestPeer describes a peer of the node in a Dandelion++ test
+type dandelionTestPeer struct {
+	isOutbound      bool
+	protocolVersion uint32
+}
+
+// fakeClock is a clock that only advances when it's told to
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+// fakeEmbargoTimer is an embargo timer that expires only when the test expires it
+type fakeEmbargoTimer struct {
+	duration  time.Duration
+	onExpired func()
+	isStopped bool
+}
+
+func (t *fakeEmbargoTimer) Stop() bool {
+	wasStopped := t.isStopped
+	t.isStopped = true
+	return !wasStopped
+}
+
+// fixedSource is a random source whose Float64 is always the given value
+type fixedSource struct {
+	float64Value float64
+}
+
+func (s *fixedSource) Int63() int64 {
+	return int64(s.float64Value * (1 << 63))
+}
+
+func (s *fixedSource) Seed(int64) {}
+
+type fakeDomain struct {
+	domain.Domain
+	miningManager *fakeMiningManager
+}
+
+func (d *fakeDomain) MiningManager() miningmanager.MiningManager {
+	return d.miningManager
+}
+
+// fakeMiningManager is a mining manager whose mempool consists of the given transactions
+type fakeMiningManager struct {
+	miningmanager.MiningManager
+	transactions map[externalapi.DomainTransactionID]*externalapi.DomainTransaction
+}
+
+func (m *fakeMiningManager) GetTransaction(transactionID *externalapi.DomainTransactionID,
+	includeTransactionPool bool, includeOrphanPool bool) (*externalapi.DomainTransaction, bool, bool) {
+
+	transaction, ok := m.transactions[*transactionID]
+	return transaction, false, ok
+}
+
+// newDandelionTestContext returns a FlowContext with Dandelion++ enabled, whose peers are connected
+// over a simulated network. Its clock, random source and embargo timers are the given ones.
+func newDandelionTestContext(t *testing.T, peers []dandelionTestPeer, clock *fakeClock, random *rand.Rand,
+	timers *[]*fakeEmbargoTimer) (*FlowContext, []*peerpkg.Peer) {
+
+	const localAddress = "10.0.0.1:16111"
+	network, err := simulatedserver.NewNetwork(simulatedserver.LinkConfig{}, 0)
+	if err != nil {
+		t.Fatalf("NewNetwork: %+v", err)
+	}
+	p2pServer, err := network.NewP2PServer(localAddress)
+	if err != nil {
+		t.Fatalf("NewP2PServer: %+v", err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.Dandelion = true
+	netAdapter, err := netadapter.NewNetAdapterWithP2PServer(cfg, p2pServer)
+	if err != nil {
+		t.Fatalf("NewNetAdapterWithP2PServer: %+v", err)
+	}
+	netConnections := make(chan *netadapter.NetConnection, 1)
+	netAdapter.SetP2PRouterInitializer(func(_ *router.Router, netConnection *netadapter.NetConnection) {
+		netConnections <- netConnection
+	})
+	err = p2pServer.Start()
+	if err != nil {
+		t.Fatalf("Start: %+v", err)
+	}
+
+	flowContext := New(cfg, &fakeDomain{miningManager: &fakeMiningManager{
+		transactions: make(map[externalapi.DomainTransactionID]*externalapi.DomainTransaction),
+	}}, nil, netAdapter, nil)
+	flowContext.dandelion.now = clock.Now
+	flowContext.dandelion.random = random
+	flowContext.dandelion.afterFunc = func(duration time.Duration, onExpired func()) embargoTimer {
+		timer := &fakeEmbargoTimer{duration: duration, onExpired: onExpired}
+		*timers = append(*timers, timer)
+		return timer
+	}
+
+	readyPeers := make([]*peerpkg.Peer, len(peers))
+	for i, testPeer := range peers {
+		peerAddress := fmt.Sprintf("10.0.0.%d:16111", i+2)
+		peerServer, err := network.NewP2PServer(peerAddress)
+		if err != nil {
+			t.Fatalf("NewP2PServer: %+v", err)
+		}
+		peerServer.SetOnConnectedHandler(func(connection server.Connection) error {
+			connectionRouter := router.NewRouter(peerAddress)
+			connection.SetOnDisconnectedHandler(connectionRouter.Close)
+			connection.Start(connectionRouter)
+			return nil
+		})
+		err = peerServer.Start()
+		if err != nil {
+			t.Fatalf("Start: %+v", err)
+		}
+		if testPeer.isOutbound {
+			err = netAdapter.P2PConnect(peerAddress)
+		} else {
+			_, err = peerServer.Connect(localAddress)
+		}
+		if err != nil {
+			t.Fatalf("Connect: %+v", err)
+		}
+		netConnection := <-netConnections
+		if netConnection.IsOutbound() != testPeer.isOutbound {
+			t.Fatalf("Expected the connection to %s to be outbound: %t", peerAddress, testPeer.isOutbound)
+		}
+
+		peerID, err := id.GenerateID()
+		if err != nil {
+			t.Fatalf("GenerateID: %+v", err)
+		}
+		netConnection.SetID(peerID)
+		peer := peerpkg.New(netConnection)
+		msgVersion := appmessage.NewMsgVersion(nil, peerID, cfg.ActiveNetParams.Name, nil, testPeer.protocolVersion)
+		peer.UpdateFieldsFromMsgVersion(msgVersion, testPeer.protocolVersion)
+		err = flowContext.AddToPeers(peer)
+		if err != nil {
+			t.Fatalf("AddToPeers: %+v", err)
+		}
+		readyPeers[i] = peer
+	}
+	return flowContext, readyPeers
+}
+
+func TestDandelionStemPeerSelection(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_000_000, 0)}
+	var timers []*fakeEmbargoTimer
+	flowContext, peers := newDandelionTestContext(t, []dandelionTestPeer{
+		{isOutbound: true, protocolVersion: 6},
+		{isOutbound: true, protocolVersion: 6},
+		{isOutbound: true, protocolVersion: 5},
+		{isOutbound: false, protocolVersion: 6},
+		{isOutbound: true, protocolVersion: 6},
+	}, clock, rand.New(rand.NewSource(1)), &timers)
+	isEligible := map[*peerpkg.Peer]bool{peers[0]: true, peers[1]: true, peers[4]: true}
+
+	selectionCounts := make(map[*peerpkg.Peer]int)
+	fluffEpochCount := 0
+	const epochCount = 100
+	for i := 0; i < epochCount; i++ {
+		clock.now = clock.now.Add(dandelionEpochDuration + time.Second)
+		stemPeer := flowContext.dandelionStemPeer(nil)
+		if stemPeer == nil {
+			fluffEpochCount++
+			continue
+		}
+		if !isEligible[stemPeer] {
+			t.Fatalf("Selected %s, which is inbound or doesn't support stem transactions, as a stem peer", stemPeer)
+		}
+		selectionCounts[stemPeer]++
+
+		// The stem peer stays the same throughout the epoch
+		clock.now = clock.now.Add(dandelionEpochDuration - time.Second)
+		if flowContext.dandelionStemPeer(nil) != stemPeer {
+			t.Fatalf("Expected the stem peer to stay the same within an epoch")
+		}
+	}
+	if fluffEpochCount == 0 || fluffEpochCount == epochCount {
+		t.Fatalf("Expected some epochs but not all of them to be fluff epochs, but got %d", fluffEpochCount)
+	}
+	if len(selectionCounts) != len(isEligible) {
+		t.Fatalf("Expected every eligible peer to be selected in some epoch, but only %d were",
+			len(selectionCounts))
+	}
+
+	// A new stem peer is selected once the stem peer of the epoch disconnects
+	for flowContext.dandelionStemPeer(nil) == nil {
+		clock.now = clock.now.Add(dandelionEpochDuration + time.Second)
+	}
+	stemPeer := flowContext.dandelionStemPeer(nil)
+	flowContext.RemoveFromPeers(stemPeer)
+	newStemPeer := flowContext.dandelionStemPeer(nil)
+	if newStemPeer == nil || newStemPeer == stemPeer || !isEligible[newStemPeer] {
+		t.Fatalf("Expected another eligible peer to be selected once the stem peer disconnected")
+	}
+
+	// Transactions are fluffed when no peer is eligible
+	for _, peer := range peers {
+		if isEligible[peer] {
+			flowContext.RemoveFromPeers(peer)
+		}
+	}
+	if flowContext.dandelionStemPeer(nil) != nil {
+		t.Fatalf("Expected no stem peer to be selected when no peer is eligible")
+	}
+}
+
+func TestDandelionStemPeerExcludesSourcePeer(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_000_000, 0)}
+	var timers []*fakeEmbargoTimer
+	// The random source is such that the first epoch isn't a fluff epoch
+	flowContext, peers := newDandelionTestContext(t, []dandelionTestPeer{
+		{isOutbound: true, protocolVersion: 6},
+		{isOutbound: true, protocolVersion: 6},
+	}, clock, rand.New(&fixedSource{float64Value: 0.5}), &timers)
+
+	stemPeer := flowContext.dandelionStemPeer(nil)
+	if stemPeer == nil {
+		t.Fatalf("Expected a stem peer to be selected")
+	}
+	otherPeer := peers[0]
+	if otherPeer == stemPeer {
+		otherPeer = peers[1]
+	}
+
+	// Transactions from the stem peer are stemmed to the other peer,
+	// without changing the stem peer of the epoch
+	if flowContext.dandelionStemPeer(stemPeer) != otherPeer {
+		t.Fatalf("Expected transactions from the stem peer to be stemmed to the other peer")
+	}
+	if flowContext.dandelionStemPeer(otherPeer) != stemPeer || flowContext.dandelionStemPeer(nil) != stemPeer {
+		t.Fatalf("Expected the stem peer of the epoch to stay the same")
+	}
+
+	// Transactions from the stem peer are fluffed when no other peer is eligible
+	flowContext.RemoveFromPeers(otherPeer)
+	if flowContext.dandelionStemPeer(stemPeer) != nil {
+		t.Fatalf("Expected no peer to be selected for transactions from the only eligible peer")
+	}
+	transaction := &externalapi.DomainTransaction{
+		Version:      constants.MaxTransactionVersion,
+		SubnetworkID: subnetworks.SubnetworkIDNative,
+	}
+	err := flowContext.StemTransactions([]*externalapi.DomainTransaction{transaction}, stemPeer)
+	if err != nil {
+		t.Fatalf("StemTransactions: %+v", err)
+	}
+	if len(timers) != 0 {
+		t.Fatalf("Expected a transaction that isn't stemmed not to be embargoed")
+	}
+	if len(flowContext.transactionIDsToPropagate) != 1 ||
+		*flowContext.transactionIDsToPropagate[0] != *consensushashing.TransactionID(transaction) {
+
+		t.Fatalf("Expected a transaction from the only eligible peer to be fluffed")
+	}
+}
+
+func TestDandelionFluffProbability(t *testing.T) {
+	tests := []struct {
+		random       float64
+		isFluffEpoch bool
+	}{
+		{random: 0, isFluffEpoch: true},
+		{random: dandelionFluffProbability - 0.001, isFluffEpoch: true},
+		{random: dandelionFluffProbability + 0.001, isFluffEpoch: false},
+		{random: 0.999, isFluffEpoch: false},
+	}
+	for _, test := range tests {
+		clock := &fakeClock{now: time.Unix(1_000_000, 0)}
+		var timers []*fakeEmbargoTimer
+		flowContext, _ := newDandelionTestContext(t, nil, clock,
+			rand.New(&fixedSource{float64Value: test.random}), &timers)
+		flowContext.dandelionStemPeer(nil)
+		if flowContext.dandelion.isFluffEpoch != test.isFluffEpoch {
+			t.Fatalf("Expected a random value of %f to start a fluff epoch: %t", test.random, test.isFluffEpoch)
+		}
+	}
+
+	clock := &fakeClock{now: time.Unix(1_000_000, 0)}
+	var timers []*fakeEmbargoTimer
+	flowContext, _ := newDandelionTestContext(t, nil, clock, rand.New(rand.NewSource(1)), &timers)
+	fluffEpochCount := 0
+	const epochCount = 10_000
+	for i := 0; i < epochCount; i++ {
+		clock.now = clock.now.Add(dandelionEpochDuration + time.Second)
+		flowContext.dandelionStemPeer(nil)
+		if flowContext.dandelion.isFluffEpoch {
+			fluffEpochCount++
+		}
+	}
+	fluffRate := float64(fluffEpochCount) / epochCount
+	if fluffRate < dandelionFluffProbability*0.8 || fluffRate > dandelionFluffProbability*1.2 {
+		t.Fatalf("Expected about %f of the epochs to be fluff epochs, but got %f", dandelionFluffProbability, fluffRate)
+	}
+}
+
+func TestDandelionEmbargoExpiry(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_000_000, 0)}
+	var timers []*fakeEmbargoTimer
+	// The random source is such that the first epoch isn't a fluff epoch
+	flowContext, _ := newDandelionTestContext(t, []dandelionTestPeer{{isOutbound: true, protocolVersion: 6}},
+		clock, rand.New(&fixedSource{float64Value: 0.5}), &timers)
+	mempoolTransactions := flowContext.Domain().MiningManager().(*fakeMiningManager).transactions
+
+	transactions := make([]*externalapi.DomainTransaction, 3)
+	transactionIDs := make([]*externalapi.DomainTransactionID, len(transactions))
+	for i := range transactions {
+		transactions[i] = &externalapi.DomainTransaction{
+			Version:      constants.MaxTransactionVersion,
+			SubnetworkID: subnetworks.SubnetworkIDNative,
+			Payload:      []byte{byte(i)},
+		}
+		transactionIDs[i] = consensushashing.TransactionID(transactions[i])
+		mempoolTransactions[*transactionIDs[i]] = transactions[i]
+	}
+
+	err := flowContext.StemTransactions(transactions, nil)
+	if err != nil {
+		t.Fatalf("StemTransactions: %+v", err)
+	}
+	if len(timers) != len(transactions) {
+		t.Fatalf("Expected %d embargoes, but got %d", len(transactions), len(timers))
+	}
+	for i, timer := range timers {
+		if timer.duration < dandelionEmbargoTimeout || timer.duration >= dandelionEmbargoTimeout+dandelionEmbargoMaxJitter {
+			t.Fatalf("Embargo duration %s is out of range", timer.duration)
+		}
+		if !flowContext.IsTransactionEmbargoed(transactionIDs[i]) {
+			t.Fatalf("Expected transaction %d to be embargoed", i)
+		}
+	}
+	if len(flowContext.withoutEmbargoedTransactions(transactionIDs)) != 0 {
+		t.Fatalf("Expected the embargoed transactions not to be announced")
+	}
+	if len(flowContext.transactionIDsToPropagate) != 0 {
+		t.Fatalf("Expected the stemmed transactions not to be fluffed")
+	}
+
+	// The first transaction is announced by another peer before its embargo expires,
+	// the second is removed from the mempool and the third isn't seen again
+	flowContext.ClearTransactionEmbargoes(transactionIDs[:1])
+	if !timers[0].isStopped {
+		t.Fatalf("Expected the embargo timer of a transaction that was announced to be stopped")
+	}
+	delete(mempoolTransactions, *transactionIDs[1])
+	for _, timer := range timers {
+		timer.onExpired()
+	}
+
+	for i, transactionID := range transactionIDs {
+		if flowContext.IsTransactionEmbargoed(transactionID) {
+			t.Fatalf("Expected the embargo of transaction %d to be over", i)
+		}
+	}
+	if len(flowContext.transactionIDsToPropagate) != 1 || *flowContext.transactionIDsToPropagate[0] != *transactionIDs[2] {
+		t.Fatalf("Expected only the transaction whose embargo expired while in the mempool to be fluffed, "+
+			"but %d transactions were", len(flowContext.transactionIDsToPropagate))
+	}
+}
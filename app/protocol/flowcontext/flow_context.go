@@ -11,6 +11,7 @@ import (
 	"github.com/sedracoin/sedrad/domain"
 
 	peerpkg "github.com/sedracoin/sedrad/app/protocol/peer"
+	"github.com/sedracoin/sedrad/app/protocol/peerscore"
 	"github.com/sedracoin/sedrad/infrastructure/config"
 	"github.com/sedracoin/sedrad/infrastructure/network/addressmanager"
 	"github.com/sedracoin/sedrad/infrastructure/network/connmanager"
@@ -62,6 +63,12 @@ type FlowContext struct {
 	lastTransactionIDPropagationTime time.Time
 	transactionIDPropagationLock     sync.Mutex
 
+	dandelion *dandelionState
+
+	historicalBlocks *historicalBlocksState
+
+	peerScores *peerscore.Tracker
+
 	shutdownChan chan struct{}
 }
 
@@ -82,6 +89,9 @@ func New(cfg *config.Config, domain domain.Domain, addressManager *addressmanage
 		timeStarted:                      mstime.Now().UnixMilliseconds(),
 		transactionIDsToPropagate:        []*externalapi.DomainTransactionID{},
 		lastTransactionIDPropagationTime: time.Now(),
+		dandelion:                        newDandelionState(),
+		historicalBlocks:                 newHistoricalBlocksState(),
+		peerScores:                       peerscore.New(),
 		shutdownChan:                     make(chan struct{}),
 	}
 }
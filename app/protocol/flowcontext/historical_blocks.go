@@ -0,0 +1,373 @@
+package flowcontext
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/app/protocol/common"
+	peerpkg "github.com/sedracoin/sedrad/app/protocol/peer"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/lrucache"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/id"
+	"github.com/pkg/errors"
+)
+
+const (
+	// historicalBlocksProtocolVersion is the first protocol version in which
+	// peers understand appmessage.MsgRequestHistoricalBlocks
+	historicalBlocksProtocolVersion = 6
+
+	// historicalBlocksCacheSize is the maximal number of historical blocks
+	// that are kept in memory after being fetched from archival peers
+	historicalBlocksCacheSize = 100
+
+	// historicalBlocksRequestTimeout is the duration to wait for an archival
+	// peer to respond to a historical blocks request before trying another one
+	historicalBlocksRequestTimeout = 30 * time.Second
+
+	// maxExpiredHistoricalBlocksRequests is the maximal number of timed out historical
+	// blocks requests that are remembered per peer, so that their late responses are
+	// told apart from unrequested ones
+	maxExpiredHistoricalBlocksRequests = 10
+)
+
+var (
+	// ErrNoArchivalPeers indicates that a historical block could not be fetched
+	// because this node is not connected to any archival peer.
+	ErrNoArchivalPeers = errors.New("not connected to any archival peer")
+
+	// ErrHistoricalBlockNotFound indicates that none of the archival peers
+	// had the requested historical block.
+	ErrHistoricalBlockNotFound = errors.New("historical block was not found in any archival peer")
+)
+
+type historicalBlocksResponse struct {
+	blocks []*common.HistoricalBlock
+	err    error
+}
+
+type pendingHistoricalBlocksRequest struct {
+	request      *common.HistoricalBlocksRequest
+	responseChan chan *historicalBlocksResponse
+}
+
+// peerHistoricalBlocksRequests are the historical blocks requests sent to a peer.
+// Responses are matched to requests by their hashes.
+type peerHistoricalBlocksRequests struct {
+	pending []*pendingHistoricalBlocksRequest
+
+	// expired are the last requests that timed out. An archival peer that is
+	// slow but honest still responds to them, and these responses are dropped.
+	expired []*common.HistoricalBlocksRequest
+}
+
+// historicalBlockFetch serializes the fetches of a historical block, so that
+// concurrent requests for the same block are sent to the network only once
+type historicalBlockFetch struct {
+	lock     sync.Mutex
+	refCount int
+}
+
+// historicalBlocksState holds the historical blocks requests that are pending
+// a response from archival peers, and the cache of already fetched blocks.
+type historicalBlocksState struct {
+	lock     sync.Mutex
+	requests map[id.ID]*peerHistoricalBlocksRequests
+	fetches  map[externalapi.DomainHash]*historicalBlockFetch
+	cache    *lrucache.LRUCache
+}
+
+func newHistoricalBlocksState() *historicalBlocksState {
+	return &historicalBlocksState{
+		requests: make(map[id.ID]*peerHistoricalBlocksRequests),
+		fetches:  make(map[externalapi.DomainHash]*historicalBlockFetch),
+		cache:    lrucache.New(historicalBlocksCacheSize, true),
+	}
+}
+
+// FetchHistoricalBlock fetches a block that was already pruned by this node, and
+// optionally its acceptance data, from archival peers. The block is verified
+// against the header this node holds for it, so blocks whose header is not held
+// cannot be fetched. Fetched blocks are cached, so repeated requests for the same
+// block do not reach the network.
+func (f *FlowContext) FetchHistoricalBlock(hash *externalapi.DomainHash,
+	includeAcceptanceData bool) (*common.HistoricalBlock, error) {
+
+	block, ok := f.cachedHistoricalBlock(hash, includeAcceptanceData)
+	if ok {
+		return block, nil
+	}
+
+	unlock := f.historicalBlocks.lockFetch(hash)
+	defer unlock()
+
+	// The block might have been fetched while waiting for a concurrent fetch of it
+	block, ok = f.cachedHistoricalBlock(hash, includeAcceptanceData)
+	if ok {
+		return block, nil
+	}
+
+	blockInfo, err := f.Domain().Consensus().GetBlockInfo(hash)
+	if err != nil {
+		return nil, err
+	}
+	if !blockInfo.Exists {
+		return nil, errors.Errorf("the header of block %s is not held by this node, "+
+			"so it cannot be verified", hash)
+	}
+
+	archivalPeers := f.archivalPeers()
+	if len(archivalPeers) == 0 {
+		return nil, ErrNoArchivalPeers
+	}
+
+	request := &common.HistoricalBlocksRequest{
+		Hashes:                []*externalapi.DomainHash{hash},
+		IncludeAcceptanceData: includeAcceptanceData,
+	}
+	for _, peer := range archivalPeers {
+		blocks, err := f.requestHistoricalBlocks(peer, request)
+		if err != nil {
+			log.Debugf("Failed fetching historical block %s from %s: %s", hash, peer, err)
+			continue
+		}
+		block := blocks[0]
+		if block.Block == nil {
+			log.Debugf("Archival peer %s does not have historical block %s", peer, hash)
+			continue
+		}
+
+		f.cacheHistoricalBlock(block)
+		return block, nil
+	}
+	return nil, errors.Wrapf(ErrHistoricalBlockNotFound, "block %s", hash)
+}
+
+// HistoricalBlocksRequest returns the historical blocks request for the given hashes
+// that is pending a response from the given peer, if there's any.
+func (f *FlowContext) HistoricalBlocksRequest(peer *peerpkg.Peer,
+	hashes []*externalapi.DomainHash) (*common.HistoricalBlocksRequest, bool) {
+
+	f.historicalBlocks.lock.Lock()
+	defer f.historicalBlocks.lock.Unlock()
+
+	pending, ok := f.historicalBlocks.pendingRequest(*peer.ID(), hashes)
+	if !ok {
+		return nil, false
+	}
+	return pending.request, true
+}
+
+// IsExpiredHistoricalBlocksRequest returns whether a historical blocks request for the given
+// hashes was recently sent to the given peer and timed out. Late responses to such requests
+// are expected from slow peers, and are not a sign of misbehavior.
+func (f *FlowContext) IsExpiredHistoricalBlocksRequest(peer *peerpkg.Peer, hashes []*externalapi.DomainHash) bool {
+	f.historicalBlocks.lock.Lock()
+	defer f.historicalBlocks.lock.Unlock()
+
+	return f.historicalBlocks.isExpired(*peer.ID(), hashes)
+}
+
+// HandleHistoricalBlocksResponse passes the verified response of the given peer, or the
+// error that was encountered while verifying it, to the given pending historical blocks request.
+func (f *FlowContext) HandleHistoricalBlocksResponse(peer *peerpkg.Peer, request *common.HistoricalBlocksRequest,
+	blocks []*common.HistoricalBlock, err error) {
+
+	f.historicalBlocks.lock.Lock()
+	defer f.historicalBlocks.lock.Unlock()
+
+	pending, ok := f.historicalBlocks.removePending(*peer.ID(), request)
+	if !ok {
+		return
+	}
+	pending.responseChan <- &historicalBlocksResponse{blocks: blocks, err: err}
+}
+
+// requestHistoricalBlocks sends the given request to the given peer and waits
+// for its verified response
+func (f *FlowContext) requestHistoricalBlocks(peer *peerpkg.Peer,
+	request *common.HistoricalBlocksRequest) ([]*common.HistoricalBlock, error) {
+
+	pending := &pendingHistoricalBlocksRequest{
+		request:      request,
+		responseChan: make(chan *historicalBlocksResponse, 1),
+	}
+	f.historicalBlocks.lock.Lock()
+	f.historicalBlocks.addPending(*peer.ID(), pending)
+	f.historicalBlocks.lock.Unlock()
+
+	isExpired := false
+	defer func() {
+		f.historicalBlocks.lock.Lock()
+		defer f.historicalBlocks.lock.Unlock()
+		_, ok := f.historicalBlocks.removePending(*peer.ID(), request)
+		if ok && isExpired {
+			f.historicalBlocks.addExpired(*peer.ID(), request)
+		}
+	}()
+
+	log.Debugf("Requesting %d historical blocks from %s", len(request.Hashes), peer)
+	msgRequestHistoricalBlocks := appmessage.NewMsgRequestHistoricalBlocks(request.Hashes, request.IncludeAcceptanceData)
+	err := f.netAdapter.P2PBroadcast([]*netadapter.NetConnection{peer.Connection()}, msgRequestHistoricalBlocks)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case response := <-pending.responseChan:
+		return response.blocks, response.err
+	case <-time.After(historicalBlocksRequestTimeout):
+		isExpired = true
+		return nil, errors.Errorf("timed out waiting for historical blocks from %s", peer)
+	case <-f.shutdownChan:
+		return nil, errors.New("shutting down")
+	}
+}
+
+// forgetHistoricalBlocksRequests forgets the historical blocks requests that
+// were sent to the given peer, once it's disconnected
+func (f *FlowContext) forgetHistoricalBlocksRequests(peer *peerpkg.Peer) {
+	f.historicalBlocks.lock.Lock()
+	defer f.historicalBlocks.lock.Unlock()
+
+	delete(f.historicalBlocks.requests, *peer.ID())
+}
+
+// lockFetch locks the fetch of the block with the given hash, and returns the function that unlocks it
+func (s *historicalBlocksState) lockFetch(hash *externalapi.DomainHash) (unlock func()) {
+	s.lock.Lock()
+	fetch, ok := s.fetches[*hash]
+	if !ok {
+		fetch = &historicalBlockFetch{}
+		s.fetches[*hash] = fetch
+	}
+	fetch.refCount++
+	s.lock.Unlock()
+
+	fetch.lock.Lock()
+	return func() {
+		fetch.lock.Unlock()
+
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		fetch.refCount--
+		if fetch.refCount == 0 {
+			delete(s.fetches, *hash)
+		}
+	}
+}
+
+func (s *historicalBlocksState) peerRequests(peerID id.ID) *peerHistoricalBlocksRequests {
+	requests, ok := s.requests[peerID]
+	if !ok {
+		requests = &peerHistoricalBlocksRequests{}
+		s.requests[peerID] = requests
+	}
+	return requests
+}
+
+func (s *historicalBlocksState) addPending(peerID id.ID, pending *pendingHistoricalBlocksRequest) {
+	requests := s.peerRequests(peerID)
+	requests.pending = append(requests.pending, pending)
+}
+
+// pendingRequest returns the first request pending a response from the given peer for the
+// given hashes. A peer handles requests in order, so the first one is the one it responds to.
+func (s *historicalBlocksState) pendingRequest(peerID id.ID,
+	hashes []*externalapi.DomainHash) (*pendingHistoricalBlocksRequest, bool) {
+
+	requests, ok := s.requests[peerID]
+	if !ok {
+		return nil, false
+	}
+	for _, pending := range requests.pending {
+		if externalapi.HashesEqual(pending.request.Hashes, hashes) {
+			return pending, true
+		}
+	}
+	return nil, false
+}
+
+func (s *historicalBlocksState) removePending(peerID id.ID,
+	request *common.HistoricalBlocksRequest) (*pendingHistoricalBlocksRequest, bool) {
+
+	requests, ok := s.requests[peerID]
+	if !ok {
+		return nil, false
+	}
+	for i, pending := range requests.pending {
+		if pending.request == request {
+			requests.pending = append(requests.pending[:i], requests.pending[i+1:]...)
+			return pending, true
+		}
+	}
+	return nil, false
+}
+
+func (s *historicalBlocksState) addExpired(peerID id.ID, request *common.HistoricalBlocksRequest) {
+	requests := s.peerRequests(peerID)
+	requests.expired = append(requests.expired, request)
+	if len(requests.expired) > maxExpiredHistoricalBlocksRequests {
+		requests.expired = requests.expired[1:]
+	}
+}
+
+// isExpired returns whether a request for the given hashes to the given peer timed out,
+// and forgets it, since a peer responds to a request only once
+func (s *historicalBlocksState) isExpired(peerID id.ID, hashes []*externalapi.DomainHash) bool {
+	requests, ok := s.requests[peerID]
+	if !ok {
+		return false
+	}
+	for i, request := range requests.expired {
+		if externalapi.HashesEqual(request.Hashes, hashes) {
+			requests.expired = append(requests.expired[:i], requests.expired[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// archivalPeers returns all the ready archival peers that understand historical
+// blocks requests, in random order
+func (f *FlowContext) archivalPeers() []*peerpkg.Peer {
+	var archivalPeers []*peerpkg.Peer
+	for _, peer := range f.Peers() {
+		if peer.ProtocolVersion() >= historicalBlocksProtocolVersion &&
+			peer.HasServices(appmessage.SFNodeArchival) {
+
+			archivalPeers = append(archivalPeers, peer)
+		}
+	}
+	rand.Shuffle(len(archivalPeers), func(i, j int) {
+		archivalPeers[i], archivalPeers[j] = archivalPeers[j], archivalPeers[i]
+	})
+	return archivalPeers
+}
+
+func (f *FlowContext) cachedHistoricalBlock(hash *externalapi.DomainHash,
+	includeAcceptanceData bool) (*common.HistoricalBlock, bool) {
+
+	f.historicalBlocks.lock.Lock()
+	defer f.historicalBlocks.lock.Unlock()
+
+	cached, ok := f.historicalBlocks.cache.Get(hash)
+	if !ok {
+		return nil, false
+	}
+	block := cached.(*common.HistoricalBlock)
+	if includeAcceptanceData && block.AcceptanceData == nil {
+		return nil, false
+	}
+	return block, true
+}
+
+func (f *FlowContext) cacheHistoricalBlock(block *common.HistoricalBlock) {
+	f.historicalBlocks.lock.Lock()
+	defer f.historicalBlocks.lock.Unlock()
+
+	f.historicalBlocks.cache.Add(block.Hash, block)
+}
@@ -0,0 +1,103 @@
+package flowcontext
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sedracoin/sedrad/app/protocol/common"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/id"
+)
+
+func TestHistoricalBlocksRequestMatching(t *testing.T) {
+	state := newHistoricalBlocksState()
+	peerID, err := id.GenerateID()
+	if err != nil {
+		t.Fatalf("GenerateID: %+v", err)
+	}
+	otherPeerID, err := id.GenerateID()
+	if err != nil {
+		t.Fatalf("GenerateID: %+v", err)
+	}
+	hash := func(b byte) []*externalapi.DomainHash {
+		return []*externalapi.DomainHash{externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{b})}
+	}
+
+	oldRequest := &common.HistoricalBlocksRequest{Hashes: hash(1)}
+	newRequest := &common.HistoricalBlocksRequest{Hashes: hash(2)}
+	state.addPending(*peerID, &pendingHistoricalBlocksRequest{request: oldRequest})
+	state.addPending(*peerID, &pendingHistoricalBlocksRequest{request: newRequest})
+
+	pending, ok := state.pendingRequest(*peerID, hash(2))
+	if !ok || pending.request != newRequest {
+		t.Fatalf("Expected the response for hash 2 to match the request for it")
+	}
+	if _, ok := state.pendingRequest(*otherPeerID, hash(2)); ok {
+		t.Fatalf("Expected a request to one peer not to match the response of another")
+	}
+	if _, ok := state.pendingRequest(*peerID, hash(3)); ok {
+		t.Fatalf("Expected a response for unrequested hashes not to match any request")
+	}
+
+	// The old request times out while the new one is still pending
+	if _, ok := state.removePending(*peerID, oldRequest); !ok {
+		t.Fatalf("Expected the old request to be pending")
+	}
+	state.addExpired(*peerID, oldRequest)
+	if _, ok := state.pendingRequest(*peerID, hash(1)); ok {
+		t.Fatalf("Expected the late response to the old request not to match the new request")
+	}
+	if !state.isExpired(*peerID, hash(1)) {
+		t.Fatalf("Expected the late response to the old request to be recognized")
+	}
+	if state.isExpired(*peerID, hash(1)) {
+		t.Fatalf("Expected a second response to the old request not to be recognized")
+	}
+
+	for i := 0; i < maxExpiredHistoricalBlocksRequests+1; i++ {
+		state.addExpired(*peerID, &common.HistoricalBlocksRequest{Hashes: hash(byte(10 + i))})
+	}
+	if state.isExpired(*peerID, hash(10)) {
+		t.Fatalf("Expected the oldest expired request to be forgotten")
+	}
+	if !state.isExpired(*peerID, hash(byte(10+maxExpiredHistoricalBlocksRequests))) {
+		t.Fatalf("Expected the newest expired request to be remembered")
+	}
+}
+
+func TestHistoricalBlocksLockFetch(t *testing.T) {
+	state := newHistoricalBlocksState()
+	hash := externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{1})
+	otherHash := externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{2})
+
+	unlock := state.lockFetch(hash)
+
+	// A fetch of another block isn't blocked
+	unlockOther := state.lockFetch(otherHash)
+	unlockOther()
+
+	locked := make(chan struct{})
+	go func() {
+		unlockSecond := state.lockFetch(hash)
+		close(locked)
+		unlockSecond()
+	}()
+	select {
+	case <-locked:
+		t.Fatalf("Expected a second fetch of the same block to wait for the first one")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case <-locked:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the second fetch to proceed once the first one is done")
+	}
+
+	state.lock.Lock()
+	defer state.lock.Unlock()
+	if len(state.fetches) != 0 {
+		t.Fatalf("Expected no fetches to be left, but got %d", len(state.fetches))
+	}
+}
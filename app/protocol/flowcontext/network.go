@@ -4,6 +4,7 @@ import (
 	"github.com/sedracoin/sedrad/app/appmessage"
 	"github.com/sedracoin/sedrad/app/protocol/common"
 	peerpkg "github.com/sedracoin/sedrad/app/protocol/peer"
+	"github.com/sedracoin/sedrad/app/protocol/peerscore"
 	"github.com/sedracoin/sedrad/infrastructure/network/connmanager"
 	"github.com/sedracoin/sedrad/infrastructure/network/netadapter"
 	"github.com/pkg/errors"
@@ -19,6 +20,11 @@ func (f *FlowContext) ConnectionManager() *connmanager.ConnectionManager {
 	return f.connectionManager
 }
 
+// PeerScores returns the misbehavior score tracker of all peers
+func (f *FlowContext) PeerScores() *peerscore.Tracker {
+	return f.peerScores
+}
+
 // AddToPeers marks this peer as ready and adds it to the ready peers list.
 func (f *FlowContext) AddToPeers(peer *peerpkg.Peer) error {
 	f.peersMutex.Lock()
@@ -39,6 +45,7 @@ func (f *FlowContext) RemoveFromPeers(peer *peerpkg.Peer) {
 	defer f.peersMutex.Unlock()
 
 	delete(f.peers, *peer.ID())
+	f.forgetHistoricalBlocksRequests(peer)
 }
 
 // readyPeerConnections returns the NetConnections of all the ready peers.
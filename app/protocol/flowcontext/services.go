@@ -0,0 +1,23 @@
+package flowcontext
+
+import "github.com/sedracoin/sedrad/app/appmessage"
+
+// Services returns the services this node currently provides to its peers
+func (f *FlowContext) Services() appmessage.ServiceFlag {
+	services := appmessage.SFNodeNetwork
+	if f.cfg.IsArchivalNode {
+		services |= appmessage.SFNodeArchival
+	}
+	if f.cfg.UTXOIndex {
+		services |= appmessage.SFNodeUTXOIndex
+	}
+	if f.cfg.BlocksOnly {
+		services |= appmessage.SFNodeBlocksOnly
+	}
+	// While this node is syncing, its pruning point UTXO set is about to be
+	// replaced, so peers that need one should rather get it elsewhere
+	if !f.IsIBDRunning() {
+		services |= appmessage.SFNodePruningUTXOSet
+	}
+	return services
+}
@@ -5,7 +5,6 @@ import (
 
 	"github.com/sedracoin/sedrad/app/appmessage"
 	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
-	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
 )
 
 // TransactionIDPropagationInterval is the interval between transaction IDs propagations
@@ -18,8 +17,7 @@ func (f *FlowContext) AddTransaction(tx *externalapi.DomainTransaction, allowOrp
 		return err
 	}
 
-	acceptedTransactionIDs := consensushashing.TransactionIDs(acceptedTransactions)
-	return f.EnqueueTransactionIDsForPropagation(acceptedTransactionIDs)
+	return f.StemTransactions(acceptedTransactions, nil)
 }
 
 func (f *FlowContext) shouldRebroadcastTransactions() bool {
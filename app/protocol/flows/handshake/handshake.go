@@ -24,6 +24,7 @@ type HandleHandshakeContext interface {
 	NetAdapter() *netadapter.NetAdapter
 	Domain() domain.Domain
 	AddressManager() *addressmanager.AddressManager
+	Services() appmessage.ServiceFlag
 	AddToPeers(peer *peerpkg.Peer) error
 	HandleError(err error, flowName string, isStopping *uint32, errChan chan<- error)
 }
@@ -93,6 +94,19 @@ func HandleHandshake(context HandleHandshakeContext, netConnection *netadapter.N
 		if err != nil {
 			return nil, err
 		}
+		// The peer has advertised its services itself, so they replace any
+		// services that were gossiped for its address
+		err = context.AddressManager().UpdateServices(peerAddress, peerAddress.Services)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if peer.IsOutbound() {
+		// The address we've dialed might differ from the one the peer advertises
+		err := context.AddressManager().UpdateServices(netConnection.NetAddress(), peer.Services())
+		if err != nil {
+			return nil, err
+		}
 	}
 	return peer, nil
 }
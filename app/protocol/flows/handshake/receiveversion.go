@@ -20,7 +20,7 @@ var (
 	// connected peer may support.
 	minAcceptableProtocolVersion = uint32(5)
 
-	maxAcceptableProtocolVersion = uint32(5)
+	maxAcceptableProtocolVersion = uint32(6)
 )
 
 type receiveVersionFlow struct {
@@ -113,5 +113,11 @@ func (flow *receiveVersionFlow) start() (*appmessage.NetAddress, error) {
 
 	flow.peer.Connection().SetID(msgVersion.ID)
 
-	return msgVersion.Address, nil
+	peerAddress := msgVersion.Address
+	if peerAddress != nil {
+		peerAddressWithServices := *peerAddress
+		peerAddressWithServices.Services = flow.peer.Services()
+		peerAddress = &peerAddressWithServices
+	}
+	return peerAddress, nil
 }
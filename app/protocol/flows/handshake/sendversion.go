@@ -19,10 +19,6 @@ var (
 	// identify ourselves to other sedra peers.
 	userAgentVersion = version.Version()
 
-	// defaultServices describes the default services that are supported by
-	// the server.
-	defaultServices = appmessage.DefaultServices
-
 	// defaultRequiredServices describes the default services that are
 	// required to be supported by outbound peers.
 	defaultRequiredServices = appmessage.SFNodeNetwork
@@ -65,7 +61,7 @@ func (flow *sendVersionFlow) start() error {
 	msg.AddUserAgent(userAgentName, userAgentVersion, flow.Config().UserAgentComments...)
 
 	// Advertise the services flag
-	msg.Services = defaultServices
+	msg.Services = flow.Services()
 
 	// Advertise our max supported protocol version.
 	msg.ProtocolVersion = flow.Config().ProtocolVersion
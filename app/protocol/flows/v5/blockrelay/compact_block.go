@@ -0,0 +1,187 @@
+package blockrelay
+
+import (
+	"encoding/binary"
+	"hash"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/app/protocol/protocolerrors"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/util/random"
+	"golang.org/x/crypto/blake2b"
+)
+
+// compactBlocksProtocolVersion is the first protocol version in which
+// relay blocks are sent as compact blocks
+const compactBlocksProtocolVersion = 6
+
+// shortIDLength is the amount of bytes of a transaction's keyed hash
+// that are used as its short ID
+const shortIDLength = 6
+
+// shortIDHasher computes the short IDs of transactions within a single compact block.
+// The hash is keyed with both the block hash and a random nonce chosen by the sender,
+// so that short ID collisions cannot be precomputed by an attacker.
+type shortIDHasher struct {
+	hasher hash.Hash
+}
+
+func newShortIDHasher(blockHash *externalapi.DomainHash, nonce uint64) *shortIDHasher {
+	key := make([]byte, externalapi.DomainHashSize+8)
+	copy(key, blockHash.ByteSlice())
+	binary.LittleEndian.PutUint64(key[externalapi.DomainHashSize:], nonce)
+
+	hasher, err := blake2b.New256(key)
+	if err != nil {
+		// Can only happen if the key is longer than 64 bytes
+		panic(err)
+	}
+	return &shortIDHasher{hasher: hasher}
+}
+
+func (s *shortIDHasher) shortID(transactionID *externalapi.DomainTransactionID) uint64 {
+	s.hasher.Reset()
+	s.hasher.Write(transactionID.ByteSlice())
+	digest := s.hasher.Sum(nil)
+
+	var shortIDBytes [8]byte
+	copy(shortIDBytes[:shortIDLength], digest[:shortIDLength])
+	return binary.LittleEndian.Uint64(shortIDBytes[:])
+}
+
+// domainBlockToMsgCompactBlock converts the given block to a compact block. The coinbase
+// transaction is always sent in full, since the receiver can never have it in its mempool.
+func domainBlockToMsgCompactBlock(block *externalapi.DomainBlock) (*appmessage.MsgCompactBlock, error) {
+	nonce, err := random.Uint64()
+	if err != nil {
+		return nil, err
+	}
+
+	hasher := newShortIDHasher(consensushashing.BlockHash(block), nonce)
+	var prefilledTransactions []*appmessage.PrefilledTransaction
+	shortIDs := make([]uint64, 0, len(block.Transactions))
+	for i, transaction := range block.Transactions {
+		if i == 0 {
+			prefilledTransactions = append(prefilledTransactions, &appmessage.PrefilledTransaction{
+				Index:       uint32(i),
+				Transaction: appmessage.DomainTransactionToMsgTx(transaction),
+			})
+			continue
+		}
+		shortIDs = append(shortIDs, hasher.shortID(consensushashing.TransactionID(transaction)))
+	}
+
+	header := appmessage.DomainBlockHeaderToBlockHeader(block.Header)
+	return appmessage.NewMsgCompactBlock(header, nonce, shortIDs, prefilledTransactions), nil
+}
+
+// partialBlock is a block that's being reconstructed out of a compact block.
+// Transactions that have not been resolved yet are nil.
+type partialBlock struct {
+	hash         *externalapi.DomainHash
+	header       externalapi.BlockHeader
+	transactions []*externalapi.DomainTransaction
+}
+
+// newPartialBlock fills the transactions of the given compact block out of its prefilled
+// transactions and the given mempool transactions. Short IDs that match none or more than
+// one of the mempool transactions are left unresolved.
+func newPartialBlock(msgCompactBlock *appmessage.MsgCompactBlock,
+	mempoolTransactions []*externalapi.DomainTransaction) (*partialBlock, error) {
+
+	header := appmessage.BlockHeaderToDomainBlockHeader(&msgCompactBlock.Header)
+	blockHash := consensushashing.HeaderHash(header)
+	transactionCount := msgCompactBlock.TransactionCount()
+	transactions := make([]*externalapi.DomainTransaction, transactionCount)
+
+	isPrefilled := make([]bool, transactionCount)
+	for _, prefilledTransaction := range msgCompactBlock.PrefilledTransactions {
+		index := prefilledTransaction.Index
+		if int(index) >= transactionCount {
+			return nil, protocolerrors.Errorf(true, "compact block %s has a prefilled transaction "+
+				"with index %d while it has only %d transactions", blockHash, index, transactionCount)
+		}
+		if isPrefilled[index] {
+			return nil, protocolerrors.Errorf(true, "compact block %s has more than one prefilled "+
+				"transaction with index %d", blockHash, index)
+		}
+		isPrefilled[index] = true
+		transactions[index] = appmessage.MsgTxToDomainTransaction(prefilledTransaction.Transaction)
+	}
+
+	hasher := newShortIDHasher(blockHash, msgCompactBlock.ShortIDNonce)
+	mempoolTransactionsByShortID := make(map[uint64]*externalapi.DomainTransaction, len(mempoolTransactions))
+	for _, transaction := range mempoolTransactions {
+		shortID := hasher.shortID(consensushashing.TransactionID(transaction))
+		if _, ok := mempoolTransactionsByShortID[shortID]; ok {
+			// Ambiguous short IDs are left for the sender to resolve
+			mempoolTransactionsByShortID[shortID] = nil
+			continue
+		}
+		mempoolTransactionsByShortID[shortID] = transaction
+	}
+
+	shortIDIndex := 0
+	for i := range transactions {
+		if isPrefilled[i] {
+			continue
+		}
+		transaction := mempoolTransactionsByShortID[msgCompactBlock.ShortIDs[shortIDIndex]]
+		shortIDIndex++
+		if transaction != nil {
+			// Consensus populates the inputs of block transactions, so the mempool
+			// transaction must not be shared
+			transactions[i] = transaction.Clone()
+		}
+	}
+
+	return &partialBlock{
+		hash:         blockHash,
+		header:       header,
+		transactions: transactions,
+	}, nil
+}
+
+// missingIndexes returns the indexes of all the transactions that have not been resolved yet
+func (pb *partialBlock) missingIndexes() []uint32 {
+	var missingIndexes []uint32
+	for i, transaction := range pb.transactions {
+		if transaction == nil {
+			missingIndexes = append(missingIndexes, uint32(i))
+		}
+	}
+	return missingIndexes
+}
+
+// allIndexes returns the indexes of all the transactions in the block
+func (pb *partialBlock) allIndexes() []uint32 {
+	indexes := make([]uint32, len(pb.transactions))
+	for i := range indexes {
+		indexes[i] = uint32(i)
+	}
+	return indexes
+}
+
+// fill sets the transactions at the given indexes out of the given MsgBlockTransactions
+func (pb *partialBlock) fill(indexes []uint32, msgBlockTransactions *appmessage.MsgBlockTransactions) error {
+	if !msgBlockTransactions.BlockHash.Equal(pb.hash) {
+		return protocolerrors.Errorf(true, "got transactions of block %s while expecting "+
+			"transactions of block %s", msgBlockTransactions.BlockHash, pb.hash)
+	}
+	if len(msgBlockTransactions.Transactions) != len(indexes) {
+		return protocolerrors.Errorf(true, "got %d transactions of block %s while %d were requested",
+			len(msgBlockTransactions.Transactions), pb.hash, len(indexes))
+	}
+	for i, index := range indexes {
+		pb.transactions[index] = appmessage.MsgTxToDomainTransaction(msgBlockTransactions.Transactions[i])
+	}
+	return nil
+}
+
+func (pb *partialBlock) toDomainBlock() *externalapi.DomainBlock {
+	return &externalapi.DomainBlock{
+		Header:       pb.header,
+		Transactions: pb.transactions,
+	}
+}
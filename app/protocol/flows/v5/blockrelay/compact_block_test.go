@@ -0,0 +1,102 @@
+package blockrelay
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/blockheader"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/merkle"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/subnetworks"
+)
+
+func TestCompactBlockReconstruction(t *testing.T) {
+	transactions := make([]*externalapi.DomainTransaction, 5)
+	for i := range transactions {
+		subnetworkID := subnetworks.SubnetworkIDNative
+		if i == 0 {
+			subnetworkID = subnetworks.SubnetworkIDCoinbase
+		}
+		transactions[i] = &externalapi.DomainTransaction{
+			Inputs:       []*externalapi.DomainTransactionInput{},
+			Outputs:      []*externalapi.DomainTransactionOutput{},
+			SubnetworkID: subnetworkID,
+			Payload:      []byte{byte(i)},
+		}
+	}
+	header := blockheader.NewImmutableBlockHeader(0, []externalapi.BlockLevelParents{},
+		merkle.CalculateHashMerkleRoot(transactions), &externalapi.DomainHash{}, &externalapi.DomainHash{},
+		0, 0, 0, 0, 0, big.NewInt(0), &externalapi.DomainHash{})
+	block := &externalapi.DomainBlock{Header: header, Transactions: transactions}
+	blockHash := consensushashing.BlockHash(block)
+
+	msgCompactBlock, err := domainBlockToMsgCompactBlock(block)
+	if err != nil {
+		t.Fatalf("domainBlockToMsgCompactBlock: %+v", err)
+	}
+	if msgCompactBlock.TransactionCount() != len(transactions) {
+		t.Fatalf("expected %d transactions in the compact block but got %d",
+			len(transactions), msgCompactBlock.TransactionCount())
+	}
+	if len(msgCompactBlock.PrefilledTransactions) != 1 || msgCompactBlock.PrefilledTransactions[0].Index != 0 {
+		t.Fatalf("expected only the coinbase transaction to be prefilled")
+	}
+
+	// The mempool holds all the transactions except for the one at index 3,
+	// along with an unrelated transaction
+	unrelatedTransaction := &externalapi.DomainTransaction{
+		Inputs:       []*externalapi.DomainTransactionInput{},
+		Outputs:      []*externalapi.DomainTransactionOutput{},
+		SubnetworkID: subnetworks.SubnetworkIDNative,
+		Payload:      []byte{100},
+	}
+	mempoolTransactions := []*externalapi.DomainTransaction{
+		transactions[1], transactions[2], transactions[4], unrelatedTransaction,
+	}
+	partial, err := newPartialBlock(msgCompactBlock, mempoolTransactions)
+	if err != nil {
+		t.Fatalf("newPartialBlock: %+v", err)
+	}
+	if !partial.hash.Equal(blockHash) {
+		t.Fatalf("expected partial block hash %s but got %s", blockHash, partial.hash)
+	}
+
+	missingIndexes := partial.missingIndexes()
+	if len(missingIndexes) != 1 || missingIndexes[0] != 3 {
+		t.Fatalf("expected only index 3 to be missing but got %v", missingIndexes)
+	}
+
+	msgBlockTransactions := appmessage.NewMsgBlockTransactions(blockHash,
+		[]*appmessage.MsgTx{appmessage.DomainTransactionToMsgTx(transactions[3])})
+	err = partial.fill(missingIndexes, msgBlockTransactions)
+	if err != nil {
+		t.Fatalf("fill: %+v", err)
+	}
+
+	reconstructedBlock := partial.toDomainBlock()
+	if !consensushashing.BlockHash(reconstructedBlock).Equal(blockHash) {
+		t.Fatalf("reconstructed block has an unexpected hash")
+	}
+	if !merkle.CalculateHashMerkleRoot(reconstructedBlock.Transactions).Equal(header.HashMerkleRoot()) {
+		t.Fatalf("reconstructed block has unexpected transactions")
+	}
+
+	// Ambiguous short IDs must be left unresolved
+	ambiguousMempoolTransactions := []*externalapi.DomainTransaction{transactions[1], transactions[1]}
+	partial, err = newPartialBlock(msgCompactBlock, ambiguousMempoolTransactions)
+	if err != nil {
+		t.Fatalf("newPartialBlock: %+v", err)
+	}
+	missingIndexes = partial.missingIndexes()
+	if len(missingIndexes) != 4 {
+		t.Fatalf("expected all non-prefilled transactions to be missing but got %v", missingIndexes)
+	}
+
+	// A response with the wrong amount of transactions is a protocol error
+	err = partial.fill(missingIndexes, msgBlockTransactions)
+	if err == nil {
+		t.Fatalf("expected fill to fail on a mismatching amount of transactions")
+	}
+}
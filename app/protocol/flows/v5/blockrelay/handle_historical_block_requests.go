@@ -0,0 +1,65 @@
+package blockrelay
+
+import (
+	"github.com/sedracoin/sedrad/app/appmessage"
+	peerpkg "github.com/sedracoin/sedrad/app/protocol/peer"
+	"github.com/sedracoin/sedrad/domain"
+	"github.com/sedracoin/sedrad/infrastructure/db/database"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/router"
+	"github.com/pkg/errors"
+)
+
+// HistoricalBlockRequestsContext is the interface for the context needed for the HandleHistoricalBlockRequests flow.
+type HistoricalBlockRequestsContext interface {
+	Domain() domain.Domain
+}
+
+// HandleHistoricalBlockRequests listens to appmessage.MsgRequestHistoricalBlocks messages and
+// sends the requested blocks, and optionally their acceptance data, to the requesting peer.
+// Blocks this node does not have are sent without a body, so that the requesting peer can
+// ask another peer for them.
+func HandleHistoricalBlockRequests(context HistoricalBlockRequestsContext, incomingRoute *router.Route,
+	outgoingRoute *router.Route, peer *peerpkg.Peer) error {
+
+	for {
+		message, err := incomingRoute.Dequeue()
+		if err != nil {
+			return err
+		}
+		msgRequestHistoricalBlocks := message.(*appmessage.MsgRequestHistoricalBlocks)
+
+		log.Debugf("Got request for %d historical blocks from %s",
+			len(msgRequestHistoricalBlocks.Hashes), peer)
+
+		blocks := make([]*appmessage.HistoricalBlock, len(msgRequestHistoricalBlocks.Hashes))
+		for i, hash := range msgRequestHistoricalBlocks.Hashes {
+			blocks[i] = &appmessage.HistoricalBlock{Hash: hash}
+
+			block, found, err := context.Domain().Consensus().GetBlock(hash)
+			if err != nil {
+				return errors.Wrapf(err, "unable to fetch requested historical block hash %s", hash)
+			}
+			if !found {
+				continue
+			}
+			blocks[i].Block = appmessage.DomainBlockToMsgBlock(block)
+
+			if !msgRequestHistoricalBlocks.IncludeAcceptanceData {
+				continue
+			}
+			acceptanceData, err := context.Domain().Consensus().GetBlockAcceptanceData(hash)
+			if err != nil {
+				if database.IsNotFoundError(err) {
+					continue
+				}
+				return errors.Wrapf(err, "unable to fetch acceptance data of historical block hash %s", hash)
+			}
+			blocks[i].AcceptanceData = appmessage.DomainAcceptanceDataToBlockAcceptanceData(acceptanceData)
+		}
+
+		err = outgoingRoute.Enqueue(appmessage.NewMsgHistoricalBlocks(blocks))
+		if err != nil {
+			return err
+		}
+	}
+}
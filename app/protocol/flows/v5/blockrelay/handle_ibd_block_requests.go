@@ -4,6 +4,7 @@ import (
 	"github.com/sedracoin/sedrad/app/appmessage"
 	"github.com/sedracoin/sedrad/app/protocol/protocolerrors"
 	"github.com/sedracoin/sedrad/domain"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter"
 	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/router"
 	"github.com/pkg/errors"
 )
@@ -11,6 +12,7 @@ import (
 // HandleIBDBlockRequestsContext is the interface for the context needed for the HandleIBDBlockRequests flow.
 type HandleIBDBlockRequestsContext interface {
 	Domain() domain.Domain
+	NetAdapter() *netadapter.NetAdapter
 }
 
 // HandleIBDBlockRequests listens to appmessage.MsgRequestRelayBlocks messages and sends
@@ -25,6 +27,7 @@ func HandleIBDBlockRequests(context HandleIBDBlockRequestsContext, incomingRoute
 		}
 		msgRequestIBDBlocks := message.(*appmessage.MsgRequestIBDBlocks)
 		log.Debugf("Got request for %d ibd blocks", len(msgRequestIBDBlocks.Hashes))
+		ibdUploadLimiter := context.NetAdapter().IBDUploadLimiter()
 		for i, hash := range msgRequestIBDBlocks.Hashes {
 			// Fetch the block from the database.
 			block, found, err := context.Domain().Consensus().GetBlock(hash)
@@ -40,6 +43,18 @@ func HandleIBDBlockRequests(context HandleIBDBlockRequestsContext, incomingRoute
 
 			blockMessage := appmessage.DomainBlockToMsgBlock(block)
 			ibdBlockMessage := appmessage.NewMsgIBDBlock(blockMessage)
+
+			// Wait until the block is within the IBD upload limit before it's
+			// enqueued, so that serving blocks to syncing peers cannot saturate
+			// the uplink
+			if ibdUploadLimiter != nil {
+				byteCount, err := netadapter.P2PMessageSize(ibdBlockMessage)
+				if err != nil {
+					return err
+				}
+				ibdUploadLimiter.Wait(byteCount)
+			}
+
 			err = outgoingRoute.Enqueue(ibdBlockMessage)
 			if err != nil {
 				return err
@@ -15,7 +15,9 @@ type RelayBlockRequestsContext interface {
 }
 
 // HandleRelayBlockRequests listens to appmessage.MsgRequestRelayBlocks messages and sends
-// their corresponding blocks to the requesting peer.
+// their corresponding blocks to the requesting peer. Peers that support compact blocks
+// get compact blocks, and may then request their missing transactions via
+// appmessage.MsgRequestBlockTransactions.
 func HandleRelayBlockRequests(context RelayBlockRequestsContext, incomingRoute *router.Route,
 	outgoingRoute *router.Route, peer *peerpkg.Peer) error {
 
@@ -24,26 +26,89 @@ func HandleRelayBlockRequests(context RelayBlockRequestsContext, incomingRoute *
 		if err != nil {
 			return err
 		}
-		getRelayBlocksMessage := message.(*appmessage.MsgRequestRelayBlocks)
-		log.Debugf("Got request for relay blocks with hashes %s", getRelayBlocksMessage.Hashes)
-		for _, hash := range getRelayBlocksMessage.Hashes {
-			// Fetch the block from the database.
-			block, found, err := context.Domain().Consensus().GetBlock(hash)
-			if err != nil {
-				return errors.Wrapf(err, "unable to fetch requested block hash %s", hash)
-			}
 
-			if !found {
-				return protocolerrors.Errorf(false, "Relay block %s not found", hash)
-			}
+		switch message := message.(type) {
+		case *appmessage.MsgRequestRelayBlocks:
+			err = sendRelayBlocks(context, outgoingRoute, peer, message)
+		case *appmessage.MsgRequestBlockTransactions:
+			err = sendBlockTransactions(context, outgoingRoute, peer, message)
+		default:
+			return protocolerrors.Errorf(true, "unexpected %s message in the block relay requests flow",
+				message.Command())
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
 
-			// TODO (Partial nodes): Convert block to partial block if needed
+func sendRelayBlocks(context RelayBlockRequestsContext, outgoingRoute *router.Route, peer *peerpkg.Peer,
+	getRelayBlocksMessage *appmessage.MsgRequestRelayBlocks) error {
 
-			err = outgoingRoute.Enqueue(appmessage.DomainBlockToMsgBlock(block))
+	log.Debugf("Got request for relay blocks with hashes %s", getRelayBlocksMessage.Hashes)
+	for _, hash := range getRelayBlocksMessage.Hashes {
+		// Fetch the block from the database.
+		block, found, err := context.Domain().Consensus().GetBlock(hash)
+		if err != nil {
+			return errors.Wrapf(err, "unable to fetch requested block hash %s", hash)
+		}
+
+		if !found {
+			return protocolerrors.Errorf(false, "Relay block %s not found", hash)
+		}
+
+		// TODO (Partial nodes): Convert block to partial block if needed
+
+		if peer.ProtocolVersion() >= compactBlocksProtocolVersion {
+			msgCompactBlock, err := domainBlockToMsgCompactBlock(block)
+			if err != nil {
+				return err
+			}
+			err = outgoingRoute.Enqueue(msgCompactBlock)
 			if err != nil {
 				return err
 			}
-			log.Debugf("Relayed block with hash %s", hash)
+			log.Debugf("Relayed compact block with hash %s", hash)
+			continue
+		}
+
+		err = outgoingRoute.Enqueue(appmessage.DomainBlockToMsgBlock(block))
+		if err != nil {
+			return err
 		}
+		log.Debugf("Relayed block with hash %s", hash)
+	}
+	return nil
+}
+
+func sendBlockTransactions(context RelayBlockRequestsContext, outgoingRoute *router.Route, peer *peerpkg.Peer,
+	requestBlockTransactionsMessage *appmessage.MsgRequestBlockTransactions) error {
+
+	if peer.ProtocolVersion() < compactBlocksProtocolVersion {
+		return protocolerrors.Errorf(true, "peer with protocol version %d requested compact block "+
+			"transactions", peer.ProtocolVersion())
 	}
+
+	blockHash := requestBlockTransactionsMessage.BlockHash
+	log.Debugf("Got request for %d transactions of block %s",
+		len(requestBlockTransactionsMessage.Indexes), blockHash)
+	block, found, err := context.Domain().Consensus().GetBlock(blockHash)
+	if err != nil {
+		return errors.Wrapf(err, "unable to fetch requested block hash %s", blockHash)
+	}
+
+	if !found {
+		return protocolerrors.Errorf(false, "Relay block %s not found", blockHash)
+	}
+
+	transactions := make([]*appmessage.MsgTx, len(requestBlockTransactionsMessage.Indexes))
+	for i, index := range requestBlockTransactionsMessage.Indexes {
+		if int(index) >= len(block.Transactions) {
+			return protocolerrors.Errorf(true, "requested transaction %d of block %s which "+
+				"has only %d transactions", index, blockHash, len(block.Transactions))
+		}
+		transactions[i] = appmessage.DomainTransactionToMsgTx(block.Transactions[index])
+	}
+
+	return outgoingRoute.Enqueue(appmessage.NewMsgBlockTransactions(blockHash, transactions))
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/sedracoin/sedrad/domain/consensus/ruleerrors"
 	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
 	"github.com/sedracoin/sedrad/domain/consensus/utils/hashset"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/merkle"
 	"github.com/sedracoin/sedrad/infrastructure/config"
 	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/router"
 	"github.com/pkg/errors"
@@ -85,7 +86,7 @@ func (flow *handleRelayInvsFlow) start() error {
 		}
 		if blockInfo.Exists && blockInfo.BlockStatus != externalapi.StatusHeaderOnly {
 			if blockInfo.BlockStatus == externalapi.StatusInvalid {
-				return protocolerrors.Errorf(true, "sent inv of an invalid block %s",
+				return protocolerrors.MisbehaviorErrorf(protocolerrors.MisbehaviorInvalidBlock, "sent inv of an invalid block %s",
 					inv.Hash)
 			}
 			log.Debugf("Block %s already exists. continuing...", inv.Hash)
@@ -288,24 +289,113 @@ func (flow *handleRelayInvsFlow) requestBlock(requestHash *externalapi.DomainHas
 		return nil, false, err
 	}
 
-	msgBlock, err := flow.readMsgBlock()
+	message, err := flow.readRelayBlockMessage()
 	if err != nil {
 		return nil, false, err
 	}
 
-	block := appmessage.MsgBlockToDomainBlock(msgBlock)
+	var block *externalapi.DomainBlock
+	switch message := message.(type) {
+	case *appmessage.MsgBlock:
+		block = appmessage.MsgBlockToDomainBlock(message)
+	case *appmessage.MsgCompactBlock:
+		block, err = flow.reconstructCompactBlock(requestHash, message)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
 	blockHash := consensushashing.BlockHash(block)
 	if !blockHash.Equal(requestHash) {
-		return nil, false, protocolerrors.Errorf(true, "got unrequested block %s", blockHash)
+		return nil, false, protocolerrors.MisbehaviorErrorf(protocolerrors.MisbehaviorUnrequestedMessage, "got unrequested block %s", blockHash)
 	}
 
 	return block, false, nil
 }
 
-// readMsgBlock returns the next msgBlock in msgChan, and populates invsQueue with any inv messages that meanwhile arrive.
+// reconstructCompactBlock rebuilds the block described by the given compact block out of
+// the mempool, and requests from the peer any transactions that could not be found in it.
+// If the reconstructed block does not match its header's merkle root (for example, due to a
+// short ID collision), all of its transactions are requested from the peer instead.
+func (flow *handleRelayInvsFlow) reconstructCompactBlock(requestHash *externalapi.DomainHash,
+	msgCompactBlock *appmessage.MsgCompactBlock) (*externalapi.DomainBlock, error) {
+
+	if flow.peer.ProtocolVersion() < compactBlocksProtocolVersion {
+		return nil, protocolerrors.Errorf(true, "peer with protocol version %d sent a compact block",
+			flow.peer.ProtocolVersion())
+	}
+
+	mempoolTransactions, _ := flow.Domain().MiningManager().AllTransactions(true, false)
+	block, err := newPartialBlock(msgCompactBlock, mempoolTransactions)
+	if err != nil {
+		return nil, err
+	}
+	if !block.hash.Equal(requestHash) {
+		return nil, protocolerrors.MisbehaviorErrorf(protocolerrors.MisbehaviorUnrequestedMessage, "got unrequested block %s", block.hash)
+	}
+
+	missingIndexes := block.missingIndexes()
+	if len(missingIndexes) > 0 {
+		log.Debugf("Requesting %d out of %d transactions of compact block %s",
+			len(missingIndexes), len(block.transactions), block.hash)
+		err := flow.requestBlockTransactions(block, missingIndexes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	hashMerkleRoot := merkle.CalculateHashMerkleRoot(block.transactions)
+	if !hashMerkleRoot.Equal(block.header.HashMerkleRoot()) {
+		log.Debugf("Compact block %s could not be reconstructed. Requesting all of its transactions",
+			block.hash)
+		err := flow.requestBlockTransactions(block, block.allIndexes())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return block.toDomainBlock(), nil
+}
+
+func (flow *handleRelayInvsFlow) requestBlockTransactions(block *partialBlock, indexes []uint32) error {
+	err := flow.outgoingRoute.Enqueue(appmessage.NewMsgRequestBlockTransactions(block.hash, indexes))
+	if err != nil {
+		return err
+	}
+
+	msgBlockTransactions, err := flow.readMsgBlockTransactions()
+	if err != nil {
+		return err
+	}
+	return block.fill(indexes, msgBlockTransactions)
+}
+
+// readRelayBlockMessage returns the next msgBlock or msgCompactBlock in msgChan, and populates
+// invsQueue with any inv messages that meanwhile arrive.
 //
-// Note: this function assumes msgChan can contain only appmessage.MsgInvRelayBlock and appmessage.MsgBlock messages.
-func (flow *handleRelayInvsFlow) readMsgBlock() (msgBlock *appmessage.MsgBlock, err error) {
+// Note: this function assumes msgChan can contain only appmessage.MsgInvRelayBlock, appmessage.MsgBlock
+// and appmessage.MsgCompactBlock messages.
+func (flow *handleRelayInvsFlow) readRelayBlockMessage() (appmessage.Message, error) {
+	for {
+		message, err := flow.incomingRoute.DequeueWithTimeout(common.DefaultTimeout)
+		if err != nil {
+			return nil, err
+		}
+
+		switch message := message.(type) {
+		case *appmessage.MsgInvRelayBlock:
+			flow.invsQueue = append(flow.invsQueue, invRelayBlock{Hash: message.Hash, IsOrphanRoot: false})
+		case *appmessage.MsgBlock, *appmessage.MsgCompactBlock:
+			return message, nil
+		default:
+			return nil, errors.Errorf("unexpected message %s", message.Command())
+		}
+	}
+}
+
+// readMsgBlockTransactions returns the next msgBlockTransactions in msgChan, and populates invsQueue
+// with any inv messages that meanwhile arrive.
+func (flow *handleRelayInvsFlow) readMsgBlockTransactions() (*appmessage.MsgBlockTransactions, error) {
 	for {
 		message, err := flow.incomingRoute.DequeueWithTimeout(common.DefaultTimeout)
 		if err != nil {
@@ -315,7 +405,7 @@ func (flow *handleRelayInvsFlow) readMsgBlock() (msgBlock *appmessage.MsgBlock,
 		switch message := message.(type) {
 		case *appmessage.MsgInvRelayBlock:
 			flow.invsQueue = append(flow.invsQueue, invRelayBlock{Hash: message.Hash, IsOrphanRoot: false})
-		case *appmessage.MsgBlock:
+		case *appmessage.MsgBlockTransactions:
 			return message, nil
 		default:
 			return nil, errors.Errorf("unexpected message %s", message.Command())
@@ -339,7 +429,7 @@ func (flow *handleRelayInvsFlow) processBlock(block *externalapi.DomainBlock) ([
 		if !errors.Is(err, ruleerrors.ErrDuplicateBlock) {
 			log.Warnf("Rejected block %s from %s: %s", blockHash, flow.peer, err)
 		}
-		return nil, protocolerrors.Wrapf(true, err, "got invalid block %s from relay", blockHash)
+		return nil, protocolerrors.WrapMisbehaviorf(protocolerrors.MisbehaviorInvalidBlock, err, "got invalid block %s from relay", blockHash)
 	}
 	return nil, nil
 }
@@ -128,7 +128,11 @@ func receiveRequestHeaders(incomingRoute *router.Route) (lowHash *externalapi.Do
 	if err != nil {
 		return nil, nil, err
 	}
-	msgRequestIBDBlocks := message.(*appmessage.MsgRequestHeaders)
+	msgRequestHeaders, ok := message.(*appmessage.MsgRequestHeaders)
+	if !ok {
+		return nil, nil, protocolerrors.Errorf(true, "received unexpected message type. "+
+			"expected: %s, got: %s", appmessage.CmdRequestHeaders, message.Command())
+	}
 
-	return msgRequestIBDBlocks.LowHash, msgRequestIBDBlocks.HighHash, nil
+	return msgRequestHeaders.LowHash, msgRequestHeaders.HighHash, nil
 }
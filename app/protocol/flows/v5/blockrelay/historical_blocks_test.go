@@ -0,0 +1,177 @@
+package blockrelay
+
+import (
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/app/protocol/common"
+	peerpkg "github.com/sedracoin/sedrad/app/protocol/peer"
+	"github.com/sedracoin/sedrad/app/protocol/protocolerrors"
+	"github.com/sedracoin/sedrad/domain"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/blockheader"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/merkle"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/subnetworks"
+	"github.com/pkg/errors"
+)
+
+type fakeReceiveHistoricalBlocksContext struct{}
+
+func (*fakeReceiveHistoricalBlocksContext) Domain() domain.Domain {
+	return nil
+}
+
+func (*fakeReceiveHistoricalBlocksContext) HistoricalBlocksRequest(*peerpkg.Peer,
+	[]*externalapi.DomainHash) (*common.HistoricalBlocksRequest, bool) {
+
+	return nil, false
+}
+
+func (*fakeReceiveHistoricalBlocksContext) IsExpiredHistoricalBlocksRequest(*peerpkg.Peer, []*externalapi.DomainHash) bool {
+	return false
+}
+
+func (*fakeReceiveHistoricalBlocksContext) HandleHistoricalBlocksResponse(*peerpkg.Peer,
+	*common.HistoricalBlocksRequest, []*common.HistoricalBlock, error) {
+}
+
+func historicalBlocksTestTransactions() []*externalapi.DomainTransaction {
+	transactions := make([]*externalapi.DomainTransaction, 4)
+	for i := range transactions {
+		subnetworkID := subnetworks.SubnetworkIDNative
+		if i == 0 {
+			subnetworkID = subnetworks.SubnetworkIDCoinbase
+		}
+		transactions[i] = &externalapi.DomainTransaction{
+			Inputs:       []*externalapi.DomainTransactionInput{},
+			Outputs:      []*externalapi.DomainTransactionOutput{},
+			SubnetworkID: subnetworkID,
+			Payload:      []byte{byte(i)},
+		}
+	}
+	return transactions
+}
+
+func historicalBlocksTestBlock(transactions []*externalapi.DomainTransaction,
+	acceptedIDMerkleRoot *externalapi.DomainHash) *externalapi.DomainBlock {
+
+	header := blockheader.NewImmutableBlockHeader(0, []externalapi.BlockLevelParents{},
+		merkle.CalculateHashMerkleRoot(transactions), acceptedIDMerkleRoot, &externalapi.DomainHash{},
+		0, 0, 0, 0, 0, big.NewInt(0), &externalapi.DomainHash{})
+	return &externalapi.DomainBlock{Header: header, Transactions: transactions}
+}
+
+func TestVerifyHistoricalBlocks(t *testing.T) {
+	block := historicalBlocksTestBlock(historicalBlocksTestTransactions(), &externalapi.DomainHash{})
+	blockHash := consensushashing.BlockHash(block)
+	missingHash := externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{1})
+	request := &common.HistoricalBlocksRequest{Hashes: []*externalapi.DomainHash{blockHash, missingHash}}
+
+	tamperedMsgBlock := appmessage.DomainBlockToMsgBlock(block)
+	tamperedMsgBlock.Transactions = tamperedMsgBlock.Transactions[:len(tamperedMsgBlock.Transactions)-1]
+
+	tests := []struct {
+		name          string
+		blocks        []*appmessage.HistoricalBlock
+		expectedError bool
+	}{
+		{
+			name: "valid",
+			blocks: []*appmessage.HistoricalBlock{
+				{Hash: blockHash, Block: appmessage.DomainBlockToMsgBlock(block)},
+				{Hash: missingHash},
+			},
+		},
+		{
+			name:          "missing entry",
+			blocks:        []*appmessage.HistoricalBlock{{Hash: blockHash, Block: appmessage.DomainBlockToMsgBlock(block)}},
+			expectedError: true,
+		},
+		{
+			name: "wrong order",
+			blocks: []*appmessage.HistoricalBlock{
+				{Hash: missingHash},
+				{Hash: blockHash, Block: appmessage.DomainBlockToMsgBlock(block)},
+			},
+			expectedError: true,
+		},
+		{
+			name: "block of another hash",
+			blocks: []*appmessage.HistoricalBlock{
+				{Hash: blockHash, Block: appmessage.DomainBlockToMsgBlock(block)},
+				{Hash: missingHash, Block: appmessage.DomainBlockToMsgBlock(block)},
+			},
+			expectedError: true,
+		},
+		{
+			name: "transactions do not match the header",
+			blocks: []*appmessage.HistoricalBlock{
+				{Hash: blockHash, Block: tamperedMsgBlock},
+				{Hash: missingHash},
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		blocks, err := verifyHistoricalBlocks(&fakeReceiveHistoricalBlocksContext{}, request,
+			appmessage.NewMsgHistoricalBlocks(test.blocks))
+		if test.expectedError {
+			if !errors.As(err, &protocolerrors.ProtocolError{}) {
+				t.Fatalf("%s: expected a protocol error but got: %v", test.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: verifyHistoricalBlocks: %+v", test.name, err)
+		}
+		if len(blocks) != 2 {
+			t.Fatalf("%s: expected 2 blocks but got %d", test.name, len(blocks))
+		}
+		if !consensushashing.BlockHash(blocks[0].Block).Equal(blockHash) {
+			t.Fatalf("%s: got an unexpected block", test.name)
+		}
+		if blocks[1].Block != nil {
+			t.Fatalf("%s: expected the missing block to have no body", test.name)
+		}
+	}
+}
+
+func TestVerifyAcceptedIDMerkleRoot(t *testing.T) {
+	transactions := historicalBlocksTestTransactions()
+	acceptanceData := externalapi.AcceptanceData{{
+		BlockHash:                 &externalapi.DomainHash{},
+		TransactionAcceptanceData: make([]*externalapi.TransactionAcceptanceData, len(transactions)),
+	}}
+	var acceptedTransactions []*externalapi.DomainTransaction
+	for i, transaction := range transactions {
+		isAccepted := i != 2
+		acceptanceData[0].TransactionAcceptanceData[i] = &externalapi.TransactionAcceptanceData{
+			Transaction: transaction,
+			IsAccepted:  isAccepted,
+		}
+		if isAccepted {
+			acceptedTransactions = append(acceptedTransactions, transaction)
+		}
+	}
+	sort.Slice(acceptedTransactions, func(i, j int) bool {
+		return consensushashing.TransactionID(acceptedTransactions[i]).Less(
+			consensushashing.TransactionID(acceptedTransactions[j]))
+	})
+
+	block := historicalBlocksTestBlock(transactions, merkle.CalculateIDMerkleRoot(acceptedTransactions))
+	blockHash := consensushashing.BlockHash(block)
+	err := verifyAcceptedIDMerkleRoot(block.Header, blockHash, acceptanceData)
+	if err != nil {
+		t.Fatalf("verifyAcceptedIDMerkleRoot: %+v", err)
+	}
+
+	acceptanceData[0].TransactionAcceptanceData[2].IsAccepted = true
+	err = verifyAcceptedIDMerkleRoot(block.Header, blockHash, acceptanceData)
+	if !errors.As(err, &protocolerrors.ProtocolError{}) {
+		t.Fatalf("expected a protocol error when the acceptance data was tampered with, but got: %v", err)
+	}
+}
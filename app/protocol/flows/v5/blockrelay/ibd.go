@@ -28,6 +28,7 @@ type IBDContext interface {
 	TrySetIBDRunning(ibdPeer *peerpkg.Peer) bool
 	UnsetIBDRunning()
 	IsRecoverableError(err error) bool
+	Peers() []*peerpkg.Peer
 }
 
 type handleIBDFlow struct {
@@ -98,6 +99,12 @@ func (flow *handleIBDFlow) runIBDIfNotRunning(block *externalapi.DomainBlock) er
 		return nil
 	}
 
+	if shouldDownloadHeadersProof && flow.hasBetterPeerForHeadersProof() {
+		log.Infof("Not syncing from %s since it does not serve its pruning point UTXO set. "+
+			"Waiting for IBD to be triggered by a peer that does", flow.peer)
+		return nil
+	}
+
 	if shouldDownloadHeadersProof {
 		log.Infof("Starting IBD with headers proof")
 		err = flow.ibdWithHeadersProof(syncerHeaderSelectedTipHash, relayBlockHash, block.Header.DAAScore())
@@ -189,7 +196,7 @@ func (flow *handleIBDFlow) negotiateMissingSyncerChainSegment() (*externalapi.Do
 			}
 			if info.Exists {
 				if info.BlockStatus == externalapi.StatusInvalid {
-					return nil, nil, protocolerrors.Errorf(true, "Sent invalid chain block %s", syncerChainHash)
+					return nil, nil, protocolerrors.MisbehaviorErrorf(protocolerrors.MisbehaviorInvalidBlock, "Sent invalid chain block %s", syncerChainHash)
 				}
 
 				isPruningPointOnSyncerChain, err := flow.Domain().Consensus().IsInSelectedParentChainOf(pruningPoint, syncerChainHash)
@@ -300,6 +307,20 @@ func (flow *handleIBDFlow) isGenesisVirtualSelectedParent() (bool, error) {
 	return virtualSelectedParent.Equal(flow.Config().NetParams().GenesisHash), nil
 }
 
+// hasBetterPeerForHeadersProof returns whether the current peer does not serve its
+// pruning point UTXO set while some other connected peer does
+func (flow *handleIBDFlow) hasBetterPeerForHeadersProof() bool {
+	if flow.peer.HasServices(appmessage.SFNodePruningUTXOSet) {
+		return false
+	}
+	for _, peer := range flow.Peers() {
+		if peer != flow.peer && peer.HasServices(appmessage.SFNodePruningUTXOSet) {
+			return true
+		}
+	}
+	return false
+}
+
 func (flow *handleIBDFlow) logIBDFinished(isFinishedSuccessfully bool, err error) {
 	successString := "successfully"
 	if !isFinishedSuccessfully {
@@ -365,7 +386,11 @@ func (flow *handleIBDFlow) syncPruningPointFutureHeaders(consensus externalapi.C
 	// never a moment when the node is not validating and inserting
 	// headers
 	blockHeadersMessageChan := make(chan *appmessage.BlockHeadersMessage, 2)
-	errChan := make(chan error)
+	errChan := make(chan error, 1)
+	// doneChan is closed once the headers stop being processed, so that the goroutine
+	// that receives them doesn't block forever when they're rejected
+	doneChan := make(chan struct{})
+	defer close(doneChan)
 	spawn("handleRelayInvsFlow-syncPruningPointFutureHeaders", func() {
 		for {
 			blockHeadersMessage, doneIBD, err := flow.receiveHeaders()
@@ -383,7 +408,11 @@ func (flow *handleIBDFlow) syncPruningPointFutureHeaders(consensus externalapi.C
 				return
 			}
 
-			blockHeadersMessageChan <- blockHeadersMessage
+			select {
+			case blockHeadersMessageChan <- blockHeadersMessage:
+			case <-doneChan:
+				return
+			}
 
 			err = flow.outgoingRoute.Enqueue(appmessage.NewMsgRequestNextHeaders())
 			if err != nil {
@@ -526,7 +555,7 @@ func (flow *handleIBDFlow) processHeader(consensus externalapi.Consensus, msgBlo
 			log.Debugf("Skipping block header %s as it is a duplicate", blockHash)
 		} else {
 			log.Infof("Rejected block header %s from %s during IBD: %s", blockHash, flow.peer, err)
-			return protocolerrors.Wrapf(true, err, "got invalid block header %s during IBD", blockHash)
+			return protocolerrors.WrapMisbehaviorf(protocolerrors.MisbehaviorInvalidBlock, err, "got invalid block header %s during IBD", blockHash)
 		}
 	}
 
@@ -680,7 +709,7 @@ func (flow *handleIBDFlow) syncMissingBlockBodies(highHash *externalapi.DomainHa
 			block := appmessage.MsgBlockToDomainBlock(msgIBDBlock.MsgBlock)
 			blockHash := consensushashing.BlockHash(block)
 			if !expectedHash.Equal(blockHash) {
-				return protocolerrors.Errorf(true, "expected block %s but got %s", expectedHash, blockHash)
+				return protocolerrors.MisbehaviorErrorf(protocolerrors.MisbehaviorUnrequestedMessage, "expected block %s but got %s", expectedHash, blockHash)
 			}
 
 			err = flow.banIfBlockIsHeaderOnly(block)
@@ -133,7 +133,7 @@ func (flow *handleIBDFlow) syncAndValidatePruningPointProof() (*externalapi.Doma
 	err = flow.Domain().Consensus().ValidatePruningPointProof(pruningPointProof)
 	if err != nil {
 		if errors.As(err, &ruleerrors.RuleError{}) {
-			return nil, protocolerrors.Wrapf(true, err, "pruning point proof validation failed")
+			return nil, protocolerrors.WrapMisbehaviorf(protocolerrors.MisbehaviorInvalidBlock, err, "pruning point proof validation failed")
 		}
 		return nil, err
 	}
@@ -293,7 +293,7 @@ func (flow *handleIBDFlow) processBlockWithTrustedData(
 	err := consensus.ValidateAndInsertBlockWithTrustedData(blockWithTrustedData, false)
 	if err != nil {
 		if errors.As(err, &ruleerrors.RuleError{}) {
-			return protocolerrors.Wrapf(true, err, "failed validating block with trusted data")
+			return protocolerrors.WrapMisbehaviorf(protocolerrors.MisbehaviorInvalidBlock, err, "failed validating block with trusted data")
 		}
 		return err
 	}
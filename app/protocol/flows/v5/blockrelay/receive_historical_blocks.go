@@ -0,0 +1,168 @@
+package blockrelay
+
+import (
+	"sort"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/app/protocol/common"
+	peerpkg "github.com/sedracoin/sedrad/app/protocol/peer"
+	"github.com/sedracoin/sedrad/app/protocol/protocolerrors"
+	"github.com/sedracoin/sedrad/domain"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/merkle"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/router"
+)
+
+// ReceiveHistoricalBlocksContext is the interface for the context needed for the ReceiveHistoricalBlocks flow.
+type ReceiveHistoricalBlocksContext interface {
+	Domain() domain.Domain
+	HistoricalBlocksRequest(peer *peerpkg.Peer, hashes []*externalapi.DomainHash) (*common.HistoricalBlocksRequest, bool)
+	IsExpiredHistoricalBlocksRequest(peer *peerpkg.Peer, hashes []*externalapi.DomainHash) bool
+	HandleHistoricalBlocksResponse(peer *peerpkg.Peer, request *common.HistoricalBlocksRequest,
+		blocks []*common.HistoricalBlock, err error)
+}
+
+// ReceiveHistoricalBlocks listens to appmessage.MsgHistoricalBlocks messages sent in
+// response to a historical blocks request to this peer, verifies them, and passes
+// them on to the flow context. Late responses to requests that timed out are dropped.
+//
+// A historical block is verified by making sure its hash is the requested hash, whose
+// header this node holds and had verified against the pruning point proof chain, and
+// that its transactions match the header's hash merkle root. Its acceptance data is
+// verified against the header's accepted ID merkle root, and the transactions of every
+// merged block whose header this node holds are verified against that header.
+func ReceiveHistoricalBlocks(context ReceiveHistoricalBlocksContext, incomingRoute *router.Route,
+	peer *peerpkg.Peer) error {
+
+	for {
+		message, err := incomingRoute.Dequeue()
+		if err != nil {
+			return err
+		}
+		msgHistoricalBlocks := message.(*appmessage.MsgHistoricalBlocks)
+
+		// Responses are matched to requests by their hashes
+		hashes := make([]*externalapi.DomainHash, len(msgHistoricalBlocks.Blocks))
+		for i, historicalBlock := range msgHistoricalBlocks.Blocks {
+			hashes[i] = historicalBlock.Hash
+		}
+		request, ok := context.HistoricalBlocksRequest(peer, hashes)
+		if !ok {
+			if context.IsExpiredHistoricalBlocksRequest(peer, hashes) {
+				log.Debugf("Dropping late historical blocks from %s", peer)
+				continue
+			}
+			return protocolerrors.MisbehaviorErrorf(protocolerrors.MisbehaviorUnrequestedMessage,
+				"got unrequested historical blocks")
+		}
+
+		blocks, err := verifyHistoricalBlocks(context, request, msgHistoricalBlocks)
+		context.HandleHistoricalBlocksResponse(peer, request, blocks, err)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func verifyHistoricalBlocks(context ReceiveHistoricalBlocksContext, request *common.HistoricalBlocksRequest,
+	msgHistoricalBlocks *appmessage.MsgHistoricalBlocks) ([]*common.HistoricalBlock, error) {
+
+	if len(msgHistoricalBlocks.Blocks) != len(request.Hashes) {
+		return nil, protocolerrors.MisbehaviorErrorf(protocolerrors.MisbehaviorProtocolViolation,
+			"requested %d historical blocks but got %d", len(request.Hashes), len(msgHistoricalBlocks.Blocks))
+	}
+
+	blocks := make([]*common.HistoricalBlock, len(request.Hashes))
+	for i, requestedHash := range request.Hashes {
+		historicalBlock := msgHistoricalBlocks.Blocks[i]
+		if !historicalBlock.Hash.Equal(requestedHash) {
+			return nil, protocolerrors.MisbehaviorErrorf(protocolerrors.MisbehaviorUnrequestedMessage,
+				"expected historical block %s but got %s", requestedHash, historicalBlock.Hash)
+		}
+		blocks[i] = &common.HistoricalBlock{Hash: requestedHash}
+		if historicalBlock.Block == nil {
+			continue
+		}
+
+		block := appmessage.MsgBlockToDomainBlock(historicalBlock.Block)
+		blockHash := consensushashing.BlockHash(block)
+		if !blockHash.Equal(requestedHash) {
+			return nil, protocolerrors.MisbehaviorErrorf(protocolerrors.MisbehaviorUnrequestedMessage,
+				"expected historical block %s but got %s", requestedHash, blockHash)
+		}
+		hashMerkleRoot := merkle.CalculateHashMerkleRoot(block.Transactions)
+		if !block.Header.HashMerkleRoot().Equal(hashMerkleRoot) {
+			return nil, protocolerrors.MisbehaviorErrorf(protocolerrors.MisbehaviorInvalidBlock,
+				"historical block %s has transactions that do not match its hash merkle root", blockHash)
+		}
+		blocks[i].Block = block
+
+		if !request.IncludeAcceptanceData || len(historicalBlock.AcceptanceData) == 0 {
+			continue
+		}
+		acceptanceData := appmessage.BlockAcceptanceDataToDomainAcceptanceData(historicalBlock.AcceptanceData)
+		err := verifyHistoricalAcceptanceData(context, block, blockHash, acceptanceData)
+		if err != nil {
+			return nil, err
+		}
+		blocks[i].AcceptanceData = acceptanceData
+	}
+	return blocks, nil
+}
+
+func verifyHistoricalAcceptanceData(context ReceiveHistoricalBlocksContext, block *externalapi.DomainBlock,
+	blockHash *externalapi.DomainHash, acceptanceData externalapi.AcceptanceData) error {
+
+	err := verifyAcceptedIDMerkleRoot(block.Header, blockHash, acceptanceData)
+	if err != nil {
+		return err
+	}
+
+	for _, blockAcceptanceData := range acceptanceData {
+		blockInfo, err := context.Domain().Consensus().GetBlockInfo(blockAcceptanceData.BlockHash)
+		if err != nil {
+			return err
+		}
+		if !blockInfo.Exists {
+			continue
+		}
+		mergedBlockHeader, err := context.Domain().Consensus().GetBlockHeader(blockAcceptanceData.BlockHash)
+		if err != nil {
+			return err
+		}
+		transactions := make([]*externalapi.DomainTransaction, len(blockAcceptanceData.TransactionAcceptanceData))
+		for i, transactionAcceptanceData := range blockAcceptanceData.TransactionAcceptanceData {
+			transactions[i] = transactionAcceptanceData.Transaction
+		}
+		if !mergedBlockHeader.HashMerkleRoot().Equal(merkle.CalculateHashMerkleRoot(transactions)) {
+			return protocolerrors.MisbehaviorErrorf(protocolerrors.MisbehaviorInvalidBlock,
+				"acceptance data of historical block %s has transactions of merged block %s "+
+					"that do not match its hash merkle root", blockHash, blockAcceptanceData.BlockHash)
+		}
+	}
+	return nil
+}
+
+func verifyAcceptedIDMerkleRoot(header externalapi.BlockHeader, blockHash *externalapi.DomainHash,
+	acceptanceData externalapi.AcceptanceData) error {
+
+	var acceptedTransactions []*externalapi.DomainTransaction
+	for _, blockAcceptanceData := range acceptanceData {
+		for _, transactionAcceptanceData := range blockAcceptanceData.TransactionAcceptanceData {
+			if transactionAcceptanceData.IsAccepted {
+				acceptedTransactions = append(acceptedTransactions, transactionAcceptanceData.Transaction)
+			}
+		}
+	}
+	sort.Slice(acceptedTransactions, func(i, j int) bool {
+		return consensushashing.TransactionID(acceptedTransactions[i]).Less(
+			consensushashing.TransactionID(acceptedTransactions[j]))
+	})
+	acceptedIDMerkleRoot := merkle.CalculateIDMerkleRoot(acceptedTransactions)
+	if !header.AcceptedIDMerkleRoot().Equal(acceptedIDMerkleRoot) {
+		return protocolerrors.MisbehaviorErrorf(protocolerrors.MisbehaviorInvalidBlock,
+			"acceptance data of historical block %s does not match its accepted ID merkle root", blockHash)
+	}
+	return nil
+}
@@ -64,6 +64,7 @@ func registerBlockRelayFlows(m protocolManager, router *routerpkg.Router, isStop
 
 		m.RegisterFlow("HandleRelayInvs", router, []appmessage.MessageCommand{
 			appmessage.CmdInvRelayBlock, appmessage.CmdBlock, appmessage.CmdBlockLocator,
+			appmessage.CmdCompactBlock, appmessage.CmdBlockTransactions,
 		},
 			isStopping, errChan, func(incomingRoute *routerpkg.Route, peer *peerpkg.Peer) error {
 				return blockrelay.HandleRelayInvs(m.Context(), incomingRoute,
@@ -86,7 +87,9 @@ func registerBlockRelayFlows(m protocolManager, router *routerpkg.Router, isStop
 			},
 		),
 
-		m.RegisterFlow("HandleRelayBlockRequests", router, []appmessage.MessageCommand{appmessage.CmdRequestRelayBlocks}, isStopping, errChan,
+		m.RegisterFlow("HandleRelayBlockRequests", router, []appmessage.MessageCommand{
+			appmessage.CmdRequestRelayBlocks, appmessage.CmdRequestBlockTransactions,
+		}, isStopping, errChan,
 			func(incomingRoute *routerpkg.Route, peer *peerpkg.Peer) error {
 				return blockrelay.HandleRelayBlockRequests(m.Context(), incomingRoute, outgoingRoute, peer)
 			},
@@ -155,6 +158,20 @@ func registerBlockRelayFlows(m protocolManager, router *routerpkg.Router, isStop
 				return blockrelay.HandlePruningPointProofRequests(m.Context(), incomingRoute, outgoingRoute, peer)
 			},
 		),
+
+		m.RegisterFlow("HandleHistoricalBlockRequests", router,
+			[]appmessage.MessageCommand{appmessage.CmdRequestHistoricalBlocks}, isStopping, errChan,
+			func(incomingRoute *routerpkg.Route, peer *peerpkg.Peer) error {
+				return blockrelay.HandleHistoricalBlockRequests(m.Context(), incomingRoute, outgoingRoute, peer)
+			},
+		),
+
+		m.RegisterFlow("ReceiveHistoricalBlocks", router,
+			[]appmessage.MessageCommand{appmessage.CmdHistoricalBlocks}, isStopping, errChan,
+			func(incomingRoute *routerpkg.Route, peer *peerpkg.Peer) error {
+				return blockrelay.ReceiveHistoricalBlocks(m.Context(), incomingRoute, peer)
+			},
+		),
 	}
 }
 
@@ -192,6 +209,12 @@ func registerTransactionRelayFlow(m protocolManager, router *routerpkg.Router, i
 				return transactionrelay.HandleRequestedTransactions(m.Context(), incomingRoute, outgoingRoute)
 			},
 		),
+		m.RegisterFlowWithCapacity("HandleStemTransactions", 10_000, router,
+			[]appmessage.MessageCommand{appmessage.CmdStemTransaction}, isStopping, errChan,
+			func(incomingRoute *routerpkg.Route, peer *peerpkg.Peer) error {
+				return transactionrelay.HandleStemTransactions(m.Context(), incomingRoute, peer)
+			},
+		),
 	}
 }
 
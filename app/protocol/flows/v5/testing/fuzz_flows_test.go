@@ -0,0 +1,158 @@
+package testing
+
+import (
+	"testing"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	v5 "github.com/sedracoin/sedrad/app/protocol/flows/v5"
+	peerpkg "github.com/sedracoin/sedrad/app/protocol/peer"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/dagconfig"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/router"
+)
+
+// fuzzedProtocolVersion is the protocol version of the peer that sends the fuzzed messages.
+// It's the latest one, whose flows are a superset of the previous one's.
+const fuzzedProtocolVersion = 6
+
+// fuzzFlow fuzzes the v5 flow with the given name. The flow runs with all the other v5 flows
+// registered on its router, so that the messages that belong to them are routed like they
+// would be with a real peer. If triggerFlow isn't nil, it's called before the flow starts,
+// to trigger the flow the way other flows do.
+//
+// Plain `go test` only runs the seed inputs. To fuzz a flow, run e.g.:
+//
+//	go test -run ^$ -fuzz ^FuzzHandleRelayInvs$ ./app/protocol/flows/v5/testing
+//
+// SendVirtualSelectedParentInv and SendPings aren't fuzzed, since they don't read any message
+// before they send one on their own schedule.
+func fuzzFlow(f *testing.F, flowName string, triggerFlow func(peer *peerpkg.Peer)) {
+	addSeeds(f, sampleFlowMessages())
+	harness := newFuzzHarness(f)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		flowRouter := router.NewRouter("fuzz")
+		manager := &fuzzProtocolManager{context: harness.context, flows: make(map[string]*registeredFlow)}
+		isStopping := uint32(0)
+		v5.Register(manager, flowRouter, make(chan error), &isStopping)
+		flow, ok := manager.flows[flowName]
+		if !ok {
+			t.Fatalf("There's no v5 flow named %s", flowName)
+		}
+
+		peer := harness.newPeer(fuzzedProtocolVersion)
+		harness.run(t, data, flowRouter, []*router.Route{flow.route}, func() error {
+			if triggerFlow != nil {
+				triggerFlow(peer)
+			}
+			return flow.initializeFunc(flow.route, peer)
+		})
+	})
+}
+
+func FuzzSendAddresses(f *testing.F) {
+	fuzzFlow(f, "SendAddresses", nil)
+}
+
+func FuzzReceiveAddresses(f *testing.F) {
+	fuzzFlow(f, "ReceiveAddresses", nil)
+}
+
+func FuzzHandleRelayInvs(f *testing.F) {
+	fuzzFlow(f, "HandleRelayInvs", nil)
+}
+
+func FuzzHandleIBD(f *testing.F) {
+	relayBlock := sampleRelayBlock()
+	relayBlockHash := consensushashing.BlockHash(relayBlock)
+	genesisHash := dagconfig.SimnetParams.GenesisHash
+
+	// Negotiate the genesis as the highest shared chain block, and send the relay block header
+	syncerChain := []*externalapi.DomainHash{relayBlockHash, genesisHash}
+	relayBlockHeader := appmessage.DomainBlockHeaderToBlockHeader(relayBlock.Header)
+	f.Add(encodeMessages(f,
+		appmessage.NewMsgIBDChainBlockLocator(syncerChain),
+		appmessage.NewMsgIBDChainBlockLocator(syncerChain),
+		appmessage.NewBlockHeadersMessage([]*appmessage.MsgBlockHeader{relayBlockHeader}),
+	))
+
+	fuzzFlow(f, "HandleIBD", func(peer *peerpkg.Peer) {
+		// HandleIBD waits for HandleRelayInvs to request IBD before it reads any message,
+		// and for another request after IBD ends. Request IBD once, and let the flow return
+		// once it ends.
+		ibdRequestChannel := peer.IBDRequestChannel()
+		go func() {
+			ibdRequestChannel <- relayBlock
+			close(ibdRequestChannel)
+		}()
+	})
+}
+
+func FuzzHandleRelayBlockRequests(f *testing.F) {
+	fuzzFlow(f, "HandleRelayBlockRequests", nil)
+}
+
+func FuzzHandleRequestBlockLocator(f *testing.F) {
+	fuzzFlow(f, "HandleRequestBlockLocator", nil)
+}
+
+func FuzzHandleRequestHeaders(f *testing.F) {
+	fuzzFlow(f, "HandleRequestHeaders", nil)
+}
+
+func FuzzHandleIBDBlockRequests(f *testing.F) {
+	fuzzFlow(f, "HandleIBDBlockRequests", nil)
+}
+
+func FuzzHandleRequestPruningPointUTXOSet(f *testing.F) {
+	fuzzFlow(f, "HandleRequestPruningPointUTXOSet", nil)
+}
+
+func FuzzHandlePruningPointAndItsAnticoneRequests(f *testing.F) {
+	fuzzFlow(f, "HandlePruningPointAndItsAnticoneRequests", nil)
+}
+
+func FuzzHandleIBDBlockLocator(f *testing.F) {
+	fuzzFlow(f, "HandleIBDBlockLocator", nil)
+}
+
+func FuzzHandleRequestIBDChainBlockLocator(f *testing.F) {
+	fuzzFlow(f, "HandleRequestIBDChainBlockLocator", nil)
+}
+
+func FuzzHandleRequestAnticone(f *testing.F) {
+	fuzzFlow(f, "HandleRequestAnticone", nil)
+}
+
+func FuzzHandlePruningPointProofRequests(f *testing.F) {
+	fuzzFlow(f, "HandlePruningPointProofRequests", nil)
+}
+
+func FuzzHandleHistoricalBlockRequests(f *testing.F) {
+	fuzzFlow(f, "HandleHistoricalBlockRequests", nil)
+}
+
+func FuzzReceiveHistoricalBlocks(f *testing.F) {
+	fuzzFlow(f, "ReceiveHistoricalBlocks", nil)
+}
+
+func FuzzReceivePings(f *testing.F) {
+	fuzzFlow(f, "ReceivePings", nil)
+}
+
+func FuzzHandleRelayedTransactions(f *testing.F) {
+	fuzzFlow(f, "HandleRelayedTransactions", nil)
+}
+
+func FuzzHandleRequestTransactions(f *testing.F) {
+	fuzzFlow(f, "HandleRequestTransactions", nil)
+}
+
+func FuzzHandleStemTransactions(f *testing.F) {
+	fuzzFlow(f, "HandleStemTransactions", nil)
+}
+
+func FuzzHandleRejects(f *testing.F) {
+	fuzzFlow(f, "HandleRejects", nil)
+}
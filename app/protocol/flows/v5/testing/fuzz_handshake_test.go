@@ -0,0 +1,45 @@
+package testing
+
+import (
+	"testing"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/app/protocol/flows/handshake"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/router"
+)
+
+// FuzzHandshake fuzzes the handshake with the routes the protocol manager registers
+// for it, before a peer has any other flows
+func FuzzHandshake(f *testing.F) {
+	addSeeds(f, sampleHandshakeMessages(f))
+	harness := newFuzzHarness(f)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		flowRouter := router.NewRouter("fuzz")
+		receiveVersionRoute, err := flowRouter.AddIncomingRoute("recieveVersion - incoming",
+			[]appmessage.MessageCommand{appmessage.CmdVersion})
+		if err != nil {
+			t.Fatalf("AddIncomingRoute: %+v", err)
+		}
+		sendVersionRoute, err := flowRouter.AddIncomingRoute("sendVersion - incoming",
+			[]appmessage.MessageCommand{appmessage.CmdVerAck})
+		if err != nil {
+			t.Fatalf("AddIncomingRoute: %+v", err)
+		}
+		receiveReadyRoute, err := flowRouter.AddIncomingRoute("recieveReady - incoming",
+			[]appmessage.MessageCommand{appmessage.CmdReady})
+		if err != nil {
+			t.Fatalf("AddIncomingRoute: %+v", err)
+		}
+
+		incomingRoutes := []*router.Route{receiveVersionRoute, sendVersionRoute, receiveReadyRoute}
+		harness.run(t, data, flowRouter, incomingRoutes, func() error {
+			peer, err := handshake.HandleHandshake(harness.context, harness.netConnection, receiveVersionRoute,
+				sendVersionRoute, flowRouter.OutgoingRoute())
+			if peer != nil {
+				harness.context.RemoveFromPeers(peer)
+			}
+			return err
+		})
+	})
+}
@@ -0,0 +1,461 @@
+package testing
+
+import (
+	"encoding/binary"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/app/protocol/common"
+	"github.com/sedracoin/sedrad/app/protocol/flowcontext"
+	peerpkg "github.com/sedracoin/sedrad/app/protocol/peer"
+	"github.com/sedracoin/sedrad/domain"
+	"github.com/sedracoin/sedrad/domain/consensus"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/dagconfig"
+	"github.com/sedracoin/sedrad/domain/miningmanager/mempool"
+	"github.com/sedracoin/sedrad/infrastructure/config"
+	"github.com/sedracoin/sedrad/infrastructure/network/addressmanager"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/id"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/router"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/server"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/server/simulatedserver"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// maxFuzzedMessages is the maximum number of messages a fuzz input is decoded into.
+	// It's below the capacity of the routes, so that the flows never fall behind enough
+	// to get disconnected.
+	maxFuzzedMessages = 100
+
+	// fuzzedFlowTimeout is how long a fuzzed flow may take to return after its
+	// incoming routes are closed before it's considered deadlocked
+	fuzzedFlowTimeout = 10 * time.Second
+
+	// maxFuzzedHeapGrowth is how much the heap may grow over a fuzzing session
+	// before it's considered to grow without bound
+	maxFuzzedHeapGrowth = 512 * 1024 * 1024
+
+	// heapCheckInterval is the number of fuzz inputs between heap checks, since
+	// garbage collecting after every input would slow fuzzing down too much
+	heapCheckInterval = 100
+)
+
+// fuzzHarness runs flows against a peer that sends them fuzzed messages. All the
+// inputs of a fuzzing session share its context, so the flows run against the state
+// left behind by earlier inputs, like they would with a real node.
+type fuzzHarness struct {
+	context       *flowcontext.FlowContext
+	netConnection *netadapter.NetConnection
+
+	inputCount   int
+	baselineHeap uint64
+}
+
+// newFuzzHarness creates a harness with a simnet node that skips proof of work
+// validation, so that fuzzed blocks reach the rest of the validation rules
+func newFuzzHarness(f *testing.F) *fuzzHarness {
+	consensusConfig := &consensus.Config{Params: dagconfig.SimnetParams}
+	consensusConfig.SkipProofOfWork = true
+	tc, teardown, err := consensus.NewFactory().NewTestConsensus(consensusConfig, f.Name())
+	if err != nil {
+		f.Fatalf("Error setting up test consensus: %+v", err)
+	}
+	f.Cleanup(func() { teardown(false) })
+
+	domainInstance, err := domain.New(consensusConfig, mempool.DefaultConfig(&consensusConfig.Params), tc.Database())
+	if err != nil {
+		f.Fatalf("Error setting up domain: %+v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.ActiveNetParams = &consensusConfig.Params
+	cfg.Listeners = nil
+	cfg.RPCListeners = nil
+	addressManager, err := addressmanager.New(addressmanager.NewConfig(cfg), tc.Database())
+	if err != nil {
+		f.Fatalf("Error setting up address manager: %+v", err)
+	}
+
+	netAdapter, netConnection := newFuzzedNetConnection(f, cfg)
+	// The flows run after the handshake, which sets the ID of the connection
+	peerID, err := id.GenerateID()
+	if err != nil {
+		f.Fatalf("GenerateID: %+v", err)
+	}
+	netConnection.SetID(peerID)
+	harness := &fuzzHarness{
+		context:       flowcontext.New(cfg, domainInstance, addressManager, netAdapter, nil),
+		netConnection: netConnection,
+	}
+	harness.baselineHeap = heapAlloc()
+	return harness
+}
+
+// newFuzzedNetConnection connects a NetAdapter to a peer over a simulated network, and
+// returns the NetAdapter along with its connection to the peer. The flows get their
+// messages from the fuzzer rather than from the connection, but they use it to identify
+// the peer.
+func newFuzzedNetConnection(f *testing.F, cfg *config.Config) (*netadapter.NetAdapter, *netadapter.NetConnection) {
+	const localAddress, peerAddress = "10.0.0.1:16111", "10.0.0.2:16111"
+
+	network, err := simulatedserver.NewNetwork(simulatedserver.LinkConfig{}, 0)
+	if err != nil {
+		f.Fatalf("NewNetwork: %+v", err)
+	}
+	peerServer, err := network.NewP2PServer(peerAddress)
+	if err != nil {
+		f.Fatalf("NewP2PServer: %+v", err)
+	}
+	peerServer.SetOnConnectedHandler(func(connection server.Connection) error {
+		connectionRouter := router.NewRouter(peerAddress)
+		connection.SetOnDisconnectedHandler(connectionRouter.Close)
+		connection.Start(connectionRouter)
+		return nil
+	})
+	err = peerServer.Start()
+	if err != nil {
+		f.Fatalf("Start: %+v", err)
+	}
+
+	p2pServer, err := network.NewP2PServer(localAddress)
+	if err != nil {
+		f.Fatalf("NewP2PServer: %+v", err)
+	}
+	netAdapter, err := netadapter.NewNetAdapterWithP2PServer(cfg, p2pServer)
+	if err != nil {
+		f.Fatalf("NewNetAdapterWithP2PServer: %+v", err)
+	}
+	netConnections := make(chan *netadapter.NetConnection, 1)
+	netAdapter.SetP2PRouterInitializer(func(_ *router.Router, netConnection *netadapter.NetConnection) {
+		netConnections <- netConnection
+	})
+	err = p2pServer.Start()
+	if err != nil {
+		f.Fatalf("Start: %+v", err)
+	}
+	err = netAdapter.P2PConnect(peerAddress)
+	if err != nil {
+		f.Fatalf("P2PConnect: %+v", err)
+	}
+	return netAdapter, <-netConnections
+}
+
+// newPeer returns a peer that completed the handshake with the given protocol version
+func (h *fuzzHarness) newPeer(protocolVersion uint32) *peerpkg.Peer {
+	peer := peerpkg.New(h.netConnection)
+	msgVersion := appmessage.NewMsgVersion(nil, nil, h.context.Config().ActiveNetParams.Name, nil, protocolVersion)
+	msgVersion.Services = appmessage.SFNodeNetwork
+	peer.UpdateFieldsFromMsgVersion(msgVersion, protocolVersion)
+	return peer
+}
+
+// run runs a flow whose incoming routes belong to the given router, feeds it the messages
+// the given data decodes into, and closes the routes the flow reads from. It fails the
+// test if the flow returns an error that isn't a protocol error, doesn't return in time,
+// sends a message that isn't triggered by the input, leaks goroutines, or if the heap
+// keeps growing over the fuzzing session. A panic in the flow crashes the fuzzer itself.
+func (h *fuzzHarness) run(t *testing.T, data []byte, flowRouter *router.Router, incomingRoutes []*router.Route,
+	flow func() error) {
+
+	goroutineCount := runtime.NumGoroutine()
+
+	outgoingMessageCountChan := make(chan int)
+	go func() {
+		outgoingMessageCount := 0
+		for {
+			_, err := flowRouter.OutgoingRoute().Dequeue()
+			if err != nil {
+				outgoingMessageCountChan <- outgoingMessageCount
+				return
+			}
+			outgoingMessageCount++
+		}
+	}()
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- flow()
+	}()
+
+	for _, message := range decodeMessages(data) {
+		// The router disconnects peers that send messages it can't route
+		err := flowRouter.EnqueueIncomingMessage(message)
+		if err != nil {
+			break
+		}
+	}
+	for _, route := range incomingRoutes {
+		route.Close()
+	}
+
+	select {
+	case err := <-errChan:
+		if !h.context.IsRecoverableError(err) {
+			t.Fatalf("The flow returned an error that isn't a protocol error: %+v", err)
+		}
+	case <-time.After(fuzzedFlowTimeout):
+		t.Fatalf("The flow didn't return %s after its incoming routes were closed:\n%s",
+			fuzzedFlowTimeout, goroutineStacks())
+	}
+
+	flowRouter.OutgoingRoute().Close()
+	outgoingMessageCount := <-outgoingMessageCountChan
+	// Every message the flow sends should be a response to some part of the input, so a flow
+	// that sends more messages than there are bytes in the input sends them regardless of it
+	const maxUnsolicitedMessages = 10
+	if outgoingMessageCount > len(data)+maxUnsolicitedMessages {
+		t.Fatalf("The flow sent %d messages in response to %d bytes", outgoingMessageCount, len(data))
+	}
+
+	h.checkGoroutines(t, goroutineCount)
+	h.inputCount++
+	if h.inputCount%heapCheckInterval == 0 {
+		h.checkHeap(t)
+	}
+}
+
+// checkGoroutines fails the test if the number of goroutines doesn't go back to the
+// given count, which is what it was before the flow started
+func (h *fuzzHarness) checkGoroutines(t *testing.T, goroutineCount int) {
+	const timeout = 5 * time.Second
+	for start := time.Now(); time.Since(start) < timeout; time.Sleep(time.Millisecond) {
+		if runtime.NumGoroutine() <= goroutineCount {
+			return
+		}
+	}
+	t.Fatalf("The flow leaked %d goroutines:\n%s", runtime.NumGoroutine()-goroutineCount, goroutineStacks())
+}
+
+func (h *fuzzHarness) checkHeap(t *testing.T) {
+	heap := heapAlloc()
+	if heap > h.baselineHeap+maxFuzzedHeapGrowth {
+		t.Fatalf("The heap grew from %d to %d bytes over %d inputs", h.baselineHeap, heap, h.inputCount)
+	}
+}
+
+func heapAlloc() uint64 {
+	runtime.GC()
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	return memStats.HeapAlloc
+}
+
+func goroutineStacks() string {
+	stacks := &strings.Builder{}
+	err := pprof.Lookup("goroutine").WriteTo(stacks, 1)
+	if err != nil {
+		return err.Error()
+	}
+	return stacks.String()
+}
+
+// decodeMessages decodes the given data into the messages a peer sends, like the
+// connection does with the data it receives. Every message is encoded as its length,
+// as a uvarint, followed by its protowire encoding. Decoding stops at the first message
+// that isn't valid, since the connection disconnects from the peer at that point.
+func decodeMessages(data []byte) []appmessage.Message {
+	var messages []appmessage.Message
+	for len(data) > 0 && len(messages) < maxFuzzedMessages {
+		length, lengthSize := binary.Uvarint(data)
+		if lengthSize <= 0 || length > uint64(len(data)-lengthSize) {
+			break
+		}
+		data = data[lengthSize:]
+		protoMessage := &protowire.SedradMessage{}
+		err := proto.Unmarshal(data[:length], protoMessage)
+		if err != nil {
+			break
+		}
+		data = data[length:]
+		message, err := protoMessage.ToAppMessage()
+		if err != nil {
+			break
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+// encodeMessages is the inverse of decodeMessages
+func encodeMessages(f *testing.F, messages ...appmessage.Message) []byte {
+	var data []byte
+	for _, message := range messages {
+		protoMessage, err := protowire.FromAppMessage(message)
+		if err != nil {
+			f.Fatalf("FromAppMessage: %+v", err)
+		}
+		messageData, err := proto.Marshal(protoMessage)
+		if err != nil {
+			f.Fatalf("Marshal: %+v", err)
+		}
+		data = binary.AppendUvarint(data, uint64(len(messageData)))
+		data = append(data, messageData...)
+	}
+	return data
+}
+
+// addSeeds adds seed inputs made of the given messages: no messages at all, every one
+// of them alone, and all of them in a row
+func addSeeds(f *testing.F, messages []appmessage.Message) {
+	f.Add([]byte{})
+	for _, message := range messages {
+		f.Add(encodeMessages(f, message))
+	}
+	f.Add(encodeMessages(f, messages...))
+}
+
+// sampleRelayBlock returns a block on top of the simnet genesis that isn't in the DAG
+func sampleRelayBlock() *externalapi.DomainBlock {
+	params := &dagconfig.SimnetParams
+	msgBlock := appmessage.DomainBlockToMsgBlock(params.GenesisBlock)
+	msgBlock.Header.Parents = []externalapi.BlockLevelParents{{params.GenesisHash}}
+	msgBlock.Header.Timestamp = msgBlock.Header.Timestamp.Add(params.TargetTimePerBlock)
+	msgBlock.Header.DAAScore++
+	msgBlock.Header.BlueScore++
+	return appmessage.MsgBlockToDomainBlock(msgBlock)
+}
+
+// sampleHandshakeMessages returns a valid message of every type the handshake receives
+func sampleHandshakeMessages(f *testing.F) []appmessage.Message {
+	peerID, err := id.GenerateID()
+	if err != nil {
+		f.Fatalf("GenerateID: %+v", err)
+	}
+	msgVersion := appmessage.NewMsgVersion(sampleNetAddress(), peerID, dagconfig.SimnetParams.Name, nil,
+		fuzzedProtocolVersion)
+	msgVersion.Services = appmessage.SFNodeNetwork
+
+	return []appmessage.Message{
+		msgVersion,
+		appmessage.NewMsgVerAck(),
+		appmessage.NewMsgReady(),
+	}
+}
+
+func sampleNetAddress() *appmessage.NetAddress {
+	return appmessage.NewNetAddressIPPort([]byte{10, 0, 0, 3}, 16111)
+}
+
+// sampleFlowMessages returns a valid message of every type the v5 flows receive
+func sampleFlowMessages() []appmessage.Message {
+	params := &dagconfig.SimnetParams
+	genesisHash := params.GenesisHash
+	genesisBlock := appmessage.DomainBlockToMsgBlock(params.GenesisBlock)
+	relayBlock := sampleRelayBlock()
+	relayBlockHash := consensushashing.BlockHash(relayBlock)
+	msgRelayBlock := appmessage.DomainBlockToMsgBlock(relayBlock)
+	msgTx := appmessage.DomainTransactionToMsgTx(relayBlock.Transactions[0])
+	transactionID := consensushashing.TransactionID(relayBlock.Transactions[0])
+	hashes := []*externalapi.DomainHash{relayBlockHash, genesisHash}
+
+	msgBlockWithTrustedData := appmessage.NewMsgBlockWithTrustedDataV4()
+	msgBlockWithTrustedData.Block = genesisBlock
+
+	return []appmessage.Message{
+		appmessage.NewMsgRequestAddresses(false, nil),
+		appmessage.NewMsgAddresses([]*appmessage.NetAddress{sampleNetAddress()}),
+		appmessage.NewMsgPing(1),
+		appmessage.NewMsgPong(1),
+		appmessage.NewMsgReject("reason"),
+		appmessage.NewMsgInvBlock(relayBlockHash),
+		msgRelayBlock,
+		appmessage.NewMsgBlockLocator(hashes),
+		appmessage.NewMsgCompactBlock(&msgRelayBlock.Header, 1, []uint64{1}, nil),
+		appmessage.NewMsgBlockTransactions(relayBlockHash, []*appmessage.MsgTx{msgTx}),
+		appmessage.NewMsgDoneHeaders(),
+		appmessage.NewMsgUnexpectedPruningPoint(),
+		appmessage.NewMsgPruningPointUTXOSetChunk(nil),
+		appmessage.NewBlockHeadersMessage([]*appmessage.MsgBlockHeader{&msgRelayBlock.Header}),
+		appmessage.NewMsgIBDBlockLocatorHighestHash(genesisHash),
+		msgBlockWithTrustedData,
+		appmessage.NewMsgDoneBlocksWithTrustedData(),
+		appmessage.NewMsgIBDBlockLocatorHighestHashNotFound(),
+		appmessage.NewMsgDonePruningPointUTXOSetChunks(),
+		appmessage.NewMsgIBDBlock(msgRelayBlock),
+		appmessage.NewMsgPruningPoints([]*appmessage.MsgBlockHeader{&genesisBlock.Header}),
+		appmessage.NewMsgPruningPointProof([][]*appmessage.MsgBlockHeader{{&genesisBlock.Header}}),
+		appmessage.NewMsgTrustedData(),
+		appmessage.NewMsgIBDChainBlockLocator(hashes),
+		appmessage.NewMsgRequestRelayBlocks(hashes),
+		appmessage.NewMsgRequestBlockTransactions(genesisHash, []uint32{0}),
+		appmessage.NewMsgRequestBlockLocator(genesisHash, 10),
+		appmessage.NewMsgRequstHeaders(genesisHash, genesisHash),
+		appmessage.NewMsgRequestNextHeaders(),
+		appmessage.NewMsgRequestIBDBlocks(hashes),
+		appmessage.NewMsgRequestPruningPointUTXOSet(genesisHash),
+		appmessage.NewMsgRequestNextPruningPointUTXOSetChunk(),
+		appmessage.NewMsgRequestPruningPointAndItsAnticone(),
+		appmessage.NewMsgRequestNextPruningPointAndItsAnticoneBlocks(),
+		appmessage.NewMsgIBDBlockLocator(genesisHash, hashes),
+		appmessage.NewMsgIBDRequestChainBlockLocator(genesisHash, nil),
+		appmessage.NewMsgRequestAnticone(genesisHash, genesisHash),
+		appmessage.NewMsgRequestPruningPointProof(),
+		appmessage.NewMsgRequestHistoricalBlocks(hashes, true),
+		appmessage.NewMsgHistoricalBlocks([]*appmessage.HistoricalBlock{{Hash: genesisHash, Block: genesisBlock}}),
+		appmessage.NewMsgInvTransaction([]*externalapi.DomainTransactionID{transactionID}),
+		msgTx,
+		appmessage.NewMsgTransactionNotFound(transactionID),
+		appmessage.NewMsgRequestTransactions([]*externalapi.DomainTransactionID{transactionID}),
+		appmessage.NewMsgStemTransaction(msgTx),
+	}
+}
+
+// fuzzProtocolManager registers the v5 flows of a peer on its router like the protocol
+// manager does, but keeps them for the harness to run instead of running them
+type fuzzProtocolManager struct {
+	context *flowcontext.FlowContext
+	flows   map[string]*registeredFlow
+}
+
+// registeredFlow is a flow that was registered by fuzzProtocolManager
+type registeredFlow struct {
+	route          *router.Route
+	initializeFunc common.FlowInitializeFunc
+}
+
+func (m *fuzzProtocolManager) RegisterFlow(name string, router *router.Router, messageTypes []appmessage.MessageCommand,
+	isStopping *uint32, errChan chan error, initializeFunc common.FlowInitializeFunc) *common.Flow {
+
+	route, err := router.AddIncomingRoute(name, messageTypes)
+	if err != nil {
+		panic(err)
+	}
+	return m.registerFlowForRoute(name, route, initializeFunc)
+}
+
+func (m *fuzzProtocolManager) RegisterOneTimeFlow(name string, router *router.Router,
+	messageTypes []appmessage.MessageCommand, isStopping *uint32, stopChan chan error,
+	initializeFunc common.FlowInitializeFunc) *common.Flow {
+
+	return m.RegisterFlow(name, router, messageTypes, isStopping, stopChan, initializeFunc)
+}
+
+func (m *fuzzProtocolManager) RegisterFlowWithCapacity(name string, capacity int, router *router.Router,
+	messageTypes []appmessage.MessageCommand, isStopping *uint32, errChan chan error,
+	initializeFunc common.FlowInitializeFunc) *common.Flow {
+
+	route, err := router.AddIncomingRouteWithCapacity(name, capacity, messageTypes)
+	if err != nil {
+		panic(err)
+	}
+	return m.registerFlowForRoute(name, route, initializeFunc)
+}
+
+func (m *fuzzProtocolManager) registerFlowForRoute(name string, route *router.Route,
+	initializeFunc common.FlowInitializeFunc) *common.Flow {
+
+	m.flows[name] = &registeredFlow{route: route, initializeFunc: initializeFunc}
+	return &common.Flow{Name: name}
+}
+
+func (m *fuzzProtocolManager) Context() *flowcontext.FlowContext {
+	return m.context
+}
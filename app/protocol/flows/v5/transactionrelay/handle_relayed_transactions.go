@@ -4,6 +4,7 @@ import (
 	"github.com/sedracoin/sedrad/app/appmessage"
 	"github.com/sedracoin/sedrad/app/protocol/common"
 	"github.com/sedracoin/sedrad/app/protocol/flowcontext"
+	peerpkg "github.com/sedracoin/sedrad/app/protocol/peer"
 	"github.com/sedracoin/sedrad/app/protocol/protocolerrors"
 	"github.com/sedracoin/sedrad/domain"
 	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
@@ -22,6 +23,9 @@ type TransactionsRelayContext interface {
 	SharedRequestedTransactions() *flowcontext.SharedRequestedTransactions
 	OnTransactionAddedToMempool()
 	EnqueueTransactionIDsForPropagation(transactionIDs []*externalapi.DomainTransactionID) error
+	StemTransactions(transactions []*externalapi.DomainTransaction, sourcePeer *peerpkg.Peer) error
+	IsTransactionEmbargoed(transactionID *externalapi.DomainTransactionID) bool
+	ClearTransactionEmbargoes(transactionIDs []*externalapi.DomainTransactionID)
 	IsNearlySynced() (bool, error)
 }
 
@@ -50,6 +54,10 @@ func (flow *handleRelayedTransactionsFlow) start() error {
 			return err
 		}
 
+		// Announced transactions have already been fluffed, so there's
+		// no need to fluff them once their embargo expires
+		flow.ClearTransactionEmbargoes(inv.TxIDs)
+
 		isNearlySynced, err := flow.IsNearlySynced()
 		if err != nil {
 			return err
@@ -170,7 +178,7 @@ func (flow *handleRelayedTransactionsFlow) receiveTransactions(requestedTransact
 		}
 		if msgTxNotFound != nil {
 			if !msgTxNotFound.ID.Equal(expectedID) {
-				return protocolerrors.Errorf(true, "expected transaction %s, but got %s",
+				return protocolerrors.MisbehaviorErrorf(protocolerrors.MisbehaviorUnrequestedMessage, "expected transaction %s, but got %s",
 					expectedID, msgTxNotFound.ID)
 			}
 
@@ -179,30 +187,18 @@ func (flow *handleRelayedTransactionsFlow) receiveTransactions(requestedTransact
 		tx := appmessage.MsgTxToDomainTransaction(msgTx)
 		txID := consensushashing.TransactionID(tx)
 		if !txID.Equal(expectedID) {
-			return protocolerrors.Errorf(true, "expected transaction %s, but got %s",
+			return protocolerrors.MisbehaviorErrorf(protocolerrors.MisbehaviorUnrequestedMessage, "expected transaction %s, but got %s",
 				expectedID, txID)
 		}
 
 		acceptedTransactions, err :=
 			flow.Domain().MiningManager().ValidateAndInsertTransaction(tx, false, true)
 		if err != nil {
-			ruleErr := &mempool.RuleError{}
-			if !errors.As(err, ruleErr) {
-				return errors.Wrapf(err, "failed to process transaction %s", txID)
-			}
-
-			shouldBan := false
-			if txRuleErr := (&mempool.TxRuleError{}); errors.As(ruleErr.Err, txRuleErr) {
-				if txRuleErr.RejectCode == mempool.RejectInvalid {
-					shouldBan = true
-				}
-			}
-
-			if !shouldBan {
-				continue
+			err = handleRejectedTransaction(txID, err)
+			if err != nil {
+				return err
 			}
-
-			return protocolerrors.Errorf(true, "rejected transaction %s: %s", txID, ruleErr)
+			continue
 		}
 		err = flow.broadcastAcceptedTransactions(consensushashing.TransactionIDs(acceptedTransactions))
 		if err != nil {
@@ -212,3 +208,26 @@ func (flow *handleRelayedTransactionsFlow) receiveTransactions(requestedTransact
 	}
 	return nil
 }
+
+// handleRejectedTransaction returns an error if the given mempool error, returned when
+// processing a transaction received from a peer, justifies dropping or banning that peer.
+// Otherwise, the transaction is simply ignored and nil is returned.
+func handleRejectedTransaction(txID *externalapi.DomainTransactionID, err error) error {
+	ruleErr := &mempool.RuleError{}
+	if !errors.As(err, ruleErr) {
+		return errors.Wrapf(err, "failed to process transaction %s", txID)
+	}
+
+	shouldBan := false
+	if txRuleErr := (&mempool.TxRuleError{}); errors.As(ruleErr.Err, txRuleErr) {
+		if txRuleErr.RejectCode == mempool.RejectInvalid {
+			shouldBan = true
+		}
+	}
+
+	if !shouldBan {
+		return nil
+	}
+
+	return protocolerrors.MisbehaviorErrorf(protocolerrors.MisbehaviorInvalidTransaction, "rejected transaction %s: %s", txID, ruleErr)
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	peerpkg "github.com/sedracoin/sedrad/app/protocol/peer"
 	"github.com/sedracoin/sedrad/app/protocol/protocolerrors"
 	"github.com/sedracoin/sedrad/domain"
 	"github.com/sedracoin/sedrad/domain/consensus"
@@ -44,6 +45,17 @@ func (m *mocTransactionsRelayContext) EnqueueTransactionIDsForPropagation(transa
 	return nil
 }
 
+func (m *mocTransactionsRelayContext) StemTransactions(transactions []*externalapi.DomainTransaction, _ *peerpkg.Peer) error {
+	return nil
+}
+
+func (m *mocTransactionsRelayContext) IsTransactionEmbargoed(transactionID *externalapi.DomainTransactionID) bool {
+	return false
+}
+
+func (m *mocTransactionsRelayContext) ClearTransactionEmbargoes(transactionIDs []*externalapi.DomainTransactionID) {
+}
+
 func (m *mocTransactionsRelayContext) OnTransactionAddedToMempool() {
 }
 
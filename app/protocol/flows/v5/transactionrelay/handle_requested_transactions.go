@@ -32,7 +32,9 @@ func (flow *handleRequestedTransactionsFlow) start() error {
 		for _, transactionID := range msgRequestTransactions.IDs {
 			tx, _, ok := flow.Domain().MiningManager().GetTransaction(transactionID, true, false)
 
-			if !ok {
+			// Transactions in their stem phase are not revealed, so that
+			// peers can't probe which nodes are along their stem
+			if !ok || flow.IsTransactionEmbargoed(transactionID) {
 				msgTransactionNotFound := appmessage.NewMsgTransactionNotFound(transactionID)
 				err := flow.outgoingRoute.Enqueue(msgTransactionNotFound)
 				if err != nil {
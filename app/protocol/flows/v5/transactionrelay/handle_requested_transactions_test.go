@@ -9,7 +9,11 @@ import (
 	"github.com/sedracoin/sedrad/domain"
 	"github.com/sedracoin/sedrad/domain/consensus"
 	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/constants"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/subnetworks"
 	"github.com/sedracoin/sedrad/domain/consensus/utils/testutils"
+	"github.com/sedracoin/sedrad/domain/miningmanager"
 	"github.com/sedracoin/sedrad/domain/miningmanager/mempool"
 	"github.com/sedracoin/sedrad/infrastructure/config"
 	"github.com/sedracoin/sedrad/infrastructure/logger"
@@ -89,3 +93,88 @@ func TestHandleRequestedTransactionsNotFound(t *testing.T) {
 		}
 	})
 }
+
+type mocEmbargoTransactionsRelayContext struct {
+	mocTransactionsRelayContext
+	embargoedTransactions map[externalapi.DomainTransactionID]struct{}
+}
+
+func (m *mocEmbargoTransactionsRelayContext) IsTransactionEmbargoed(transactionID *externalapi.DomainTransactionID) bool {
+	_, ok := m.embargoedTransactions[*transactionID]
+	return ok
+}
+
+type mocMempoolDomain struct {
+	domain.Domain
+	miningManager *mocMempoolMiningManager
+}
+
+func (d *mocMempoolDomain) MiningManager() miningmanager.MiningManager {
+	return d.miningManager
+}
+
+// mocMempoolMiningManager is a mining manager whose mempool consists of the given transactions
+type mocMempoolMiningManager struct {
+	miningmanager.MiningManager
+	transactions map[externalapi.DomainTransactionID]*externalapi.DomainTransaction
+}
+
+func (m *mocMempoolMiningManager) GetTransaction(transactionID *externalapi.DomainTransactionID,
+	includeTransactionPool bool, includeOrphanPool bool) (*externalapi.DomainTransaction, bool, bool) {
+
+	transaction, ok := m.transactions[*transactionID]
+	return transaction, false, ok
+}
+
+// TestHandleRequestedTransactionsEmbargoed tests that HandleRequestedTransactions doesn't
+// reveal transactions that are in their stem phase, even though they're in the mempool.
+func TestHandleRequestedTransactionsEmbargoed(t *testing.T) {
+	transactions := make([]*externalapi.DomainTransaction, 2)
+	transactionIDs := make([]*externalapi.DomainTransactionID, len(transactions))
+	mempoolTransactions := make(map[externalapi.DomainTransactionID]*externalapi.DomainTransaction)
+	for i := range transactions {
+		transactions[i] = &externalapi.DomainTransaction{
+			Version:      constants.MaxTransactionVersion,
+			SubnetworkID: subnetworks.SubnetworkIDNative,
+			Payload:      []byte{byte(i)},
+		}
+		transactionIDs[i] = consensushashing.TransactionID(transactions[i])
+		mempoolTransactions[*transactionIDs[i]] = transactions[i]
+	}
+	context := &mocEmbargoTransactionsRelayContext{
+		mocTransactionsRelayContext: mocTransactionsRelayContext{
+			domain: &mocMempoolDomain{miningManager: &mocMempoolMiningManager{transactions: mempoolTransactions}},
+		},
+		embargoedTransactions: map[externalapi.DomainTransactionID]struct{}{*transactionIDs[0]: {}},
+	}
+
+	incomingRoute := router.NewRoute("incoming")
+	outgoingRoute := router.NewRoute("outgoing")
+	err := incomingRoute.Enqueue(appmessage.NewMsgRequestTransactions(transactionIDs))
+	if err != nil {
+		t.Fatalf("Unexpected error from incomingRoute.Enqueue: %v", err)
+	}
+	incomingRoute.Close()
+
+	err = transactionrelay.HandleRequestedTransactions(context, incomingRoute, outgoingRoute)
+	if err == nil || !errors.Is(err, router.ErrRouteClosed) {
+		t.Fatalf("Unexpected error: expected: %v, got : %v", router.ErrRouteClosed, err)
+	}
+
+	message, err := outgoingRoute.Dequeue()
+	if err != nil {
+		t.Fatalf("Dequeue: %s", err)
+	}
+	msgTransactionNotFound, ok := message.(*appmessage.MsgTransactionNotFound)
+	if !ok || *msgTransactionNotFound.ID != *transactionIDs[0] {
+		t.Fatalf("Expected the embargoed transaction to be reported as not found, but got %s", message.Command())
+	}
+	message, err = outgoingRoute.Dequeue()
+	if err != nil {
+		t.Fatalf("Dequeue: %s", err)
+	}
+	msgTx, ok := message.(*appmessage.MsgTx)
+	if !ok || *consensushashing.TransactionID(appmessage.MsgTxToDomainTransaction(msgTx)) != *transactionIDs[1] {
+		t.Fatalf("Expected the transaction that isn't embargoed to be sent, but got %s", message.Command())
+	}
+}
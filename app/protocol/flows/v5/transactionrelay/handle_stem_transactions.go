@@ -0,0 +1,58 @@
+package transactionrelay
+
+import (
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/app/protocol/flowcontext"
+	peerpkg "github.com/sedracoin/sedrad/app/protocol/peer"
+	"github.com/sedracoin/sedrad/app/protocol/protocolerrors"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/router"
+)
+
+// HandleStemTransactions listens to appmessage.MsgStemTransaction messages, adds their
+// transactions to the mempool and passes them on along their stem, or fluffs them,
+// according to the Dandelion++ state of the current epoch.
+func HandleStemTransactions(context TransactionsRelayContext, incomingRoute *router.Route, peer *peerpkg.Peer) error {
+	for {
+		message, err := incomingRoute.Dequeue()
+		if err != nil {
+			return err
+		}
+		msgStemTransaction := message.(*appmessage.MsgStemTransaction)
+
+		if peer.ProtocolVersion() < flowcontext.StemTransactionsProtocolVersion {
+			return protocolerrors.Errorf(true, "peer with protocol version %d sent a stem transaction",
+				peer.ProtocolVersion())
+		}
+
+		isNearlySynced, err := context.IsNearlySynced()
+		if err != nil {
+			return err
+		}
+		// Transaction relay is disabled if the node is out of sync and thus not mining
+		if !isNearlySynced {
+			continue
+		}
+
+		tx := appmessage.MsgTxToDomainTransaction(msgStemTransaction.Transaction)
+		txID := consensushashing.TransactionID(tx)
+		log.Debugf("Got stem transaction %s", txID)
+
+		// Stem transactions may not be orphans, since requesting their
+		// missing parents would reveal them to other peers
+		acceptedTransactions, err := context.Domain().MiningManager().ValidateAndInsertTransaction(tx, false, false)
+		if err != nil {
+			err = handleRejectedTransaction(txID, err)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		err = context.StemTransactions(acceptedTransactions, peer)
+		if err != nil {
+			return err
+		}
+		context.OnTransactionAddedToMempool()
+	}
+}
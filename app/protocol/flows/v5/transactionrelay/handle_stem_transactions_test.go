@@ -0,0 +1,141 @@
+package transactionrelay_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/app/protocol/flowcontext"
+	"github.com/sedracoin/sedrad/app/protocol/flows/v5/transactionrelay"
+	peerpkg "github.com/sedracoin/sedrad/app/protocol/peer"
+	"github.com/sedracoin/sedrad/app/protocol/protocolerrors"
+	"github.com/sedracoin/sedrad/domain"
+	"github.com/sedracoin/sedrad/domain/consensus"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/constants"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/subnetworks"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/testutils"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
+	"github.com/sedracoin/sedrad/domain/miningmanager/mempool"
+	"github.com/sedracoin/sedrad/infrastructure/config"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/router"
+	"github.com/sedracoin/sedrad/util"
+)
+
+type mocStemTransactionsRelayContext struct {
+	mocTransactionsRelayContext
+	stemmedTransactions []*externalapi.DomainTransaction
+}
+
+func (m *mocStemTransactionsRelayContext) StemTransactions(transactions []*externalapi.DomainTransaction, _ *peerpkg.Peer) error {
+	m.stemmedTransactions = append(m.stemmedTransactions, transactions...)
+	return nil
+}
+
+// TestHandleStemTransactionsOrphan tests that HandleStemTransactions drops stem
+// transactions whose parents are missing instead of keeping them as orphans.
+func TestHandleStemTransactionsOrphan(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestHandleStemTransactionsOrphan")
+		if err != nil {
+			t.Fatalf("Error setting up test consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		adapter, err := netadapter.NewNetAdapter(config.DefaultConfig())
+		if err != nil {
+			t.Fatalf("Failed to create a NetAdapter: %v", err)
+		}
+		domainInstance, err := domain.New(consensusConfig, mempool.DefaultConfig(&consensusConfig.Params), tc.Database())
+		if err != nil {
+			t.Fatalf("Failed to set up a domain instance: %v", err)
+		}
+		context := &mocStemTransactionsRelayContext{
+			mocTransactionsRelayContext: mocTransactionsRelayContext{
+				netAdapter:                  adapter,
+				domain:                      domainInstance,
+				sharedRequestedTransactions: flowcontext.NewSharedRequestedTransactions(),
+			},
+		}
+
+		address, err := util.NewAddressPublicKey(make([]byte, 32), consensusConfig.Prefix)
+		if err != nil {
+			t.Fatalf("NewAddressPublicKey: %v", err)
+		}
+		scriptPublicKey, err := txscript.PayToAddrScript(address)
+		if err != nil {
+			t.Fatalf("PayToAddrScript: %v", err)
+		}
+		orphanTransaction := &externalapi.DomainTransaction{
+			Version: constants.MaxTransactionVersion,
+			Inputs: []*externalapi.DomainTransactionInput{{
+				PreviousOutpoint: externalapi.DomainOutpoint{
+					TransactionID: *externalapi.NewDomainTransactionIDFromByteArray(&[externalapi.DomainHashSize]byte{0x01}),
+					Index:         0,
+				},
+				SignatureScript: []byte{},
+				Sequence:        constants.MaxTxInSequenceNum,
+				SigOpCount:      1,
+			}},
+			Outputs: []*externalapi.DomainTransactionOutput{{
+				Value:           constants.SeepPerSedra,
+				ScriptPublicKey: scriptPublicKey,
+			}},
+			SubnetworkID: subnetworks.SubnetworkIDNative,
+			Payload:      []byte{},
+		}
+
+		incomingRoute := router.NewRoute("incoming")
+		err = incomingRoute.Enqueue(appmessage.NewMsgStemTransaction(appmessage.DomainTransactionToMsgTx(orphanTransaction)))
+		if err != nil {
+			t.Fatalf("Unexpected error from incomingRoute.Enqueue: %v", err)
+		}
+		// Close the incomingRoute to stop the infinite loop once the message is handled
+		incomingRoute.Close()
+
+		err = transactionrelay.HandleStemTransactions(context, incomingRoute, newTestPeer(6))
+		if err == nil || !errors.Is(err, router.ErrRouteClosed) {
+			t.Fatalf("Unexpected error: expected: %v, got : %v", router.ErrRouteClosed, err)
+		}
+		if len(context.stemmedTransactions) != 0 {
+			t.Fatalf("Expected the orphan stem transaction not to be relayed, but %d transactions were",
+				len(context.stemmedTransactions))
+		}
+		_, _, found := domainInstance.MiningManager().GetTransaction(consensushashing.TransactionID(orphanTransaction), true, true)
+		if found {
+			t.Fatalf("Expected the orphan stem transaction not to be added to the mempool")
+		}
+	})
+}
+
+// TestHandleStemTransactionsProtocolVersion tests that HandleStemTransactions rejects
+// stem transactions from peers whose protocol version doesn't support them
+func TestHandleStemTransactionsProtocolVersion(t *testing.T) {
+	context := &mocStemTransactionsRelayContext{}
+	incomingRoute := router.NewRoute("incoming")
+	err := incomingRoute.Enqueue(appmessage.NewMsgStemTransaction(&appmessage.MsgTx{}))
+	if err != nil {
+		t.Fatalf("Unexpected error from incomingRoute.Enqueue: %v", err)
+	}
+	incomingRoute.Close()
+
+	err = transactionrelay.HandleStemTransactions(context, incomingRoute, newTestPeer(5))
+	protocolErr := protocolerrors.ProtocolError{}
+	if !errors.As(err, &protocolErr) || !protocolErr.ShouldBan {
+		t.Fatalf("Expected a banning protocol error, but got: %v", err)
+	}
+	if len(context.stemmedTransactions) != 0 {
+		t.Fatalf("Expected the stem transaction not to be relayed")
+	}
+}
+
+// newTestPeer returns a peer that negotiated the given protocol version
+func newTestPeer(protocolVersion uint32) *peerpkg.Peer {
+	peer := peerpkg.New(nil)
+	msgVersion := appmessage.NewMsgVersion(nil, nil, "", nil, protocolVersion)
+	peer.UpdateFieldsFromMsgVersion(msgVersion, protocolVersion)
+	return peer
+}
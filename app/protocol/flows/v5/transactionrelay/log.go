@@ -0,0 +1,7 @@
+package transactionrelay
+
+import (
+	"github.com/sedracoin/sedrad/infrastructure/logger"
+)
+
+var log = logger.RegisterSubSystem("PROT")
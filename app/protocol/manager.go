@@ -15,6 +15,7 @@ import (
 
 	"github.com/sedracoin/sedrad/app/protocol/flowcontext"
 	peerpkg "github.com/sedracoin/sedrad/app/protocol/peer"
+	"github.com/sedracoin/sedrad/app/protocol/peerscore"
 	"github.com/sedracoin/sedrad/infrastructure/config"
 	"github.com/sedracoin/sedrad/infrastructure/network/addressmanager"
 	"github.com/sedracoin/sedrad/infrastructure/network/connmanager"
@@ -63,6 +64,13 @@ func (m *Manager) IBDPeer() *peerpkg.Peer {
 	return m.context.IBDPeer()
 }
 
+// PeerMisbehavior returns the current misbehavior score of the given peer,
+// along with its most recent violations
+func (m *Manager) PeerMisbehavior(peer *peerpkg.Peer) (score float64, recentViolations []*peerscore.Violation) {
+	ip := peer.Connection().NetAddress().IP
+	return m.context.PeerScores().Score(ip), m.context.PeerScores().RecentViolations(ip)
+}
+
 // AddTransaction adds transaction to the mempool and propagates it.
 func (m *Manager) AddTransaction(tx *externalapi.DomainTransaction, allowOrphan bool) error {
 	return m.context.AddTransaction(tx, allowOrphan)
@@ -73,6 +81,14 @@ func (m *Manager) AddBlock(block *externalapi.DomainBlock) error {
 	return m.context.AddBlock(block)
 }
 
+// FetchHistoricalBlock fetches a block that was already pruned by this node,
+// and optionally its acceptance data, from archival peers.
+func (m *Manager) FetchHistoricalBlock(hash *externalapi.DomainHash,
+	includeAcceptanceData bool) (*common.HistoricalBlock, error) {
+
+	return m.context.FetchHistoricalBlock(hash, includeAcceptanceData)
+}
+
 // Context returns the manager's flow context
 func (m *Manager) Context() *flowcontext.FlowContext {
 	return m.context
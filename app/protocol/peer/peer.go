@@ -13,6 +13,15 @@ import (
 	"github.com/sedracoin/sedrad/util/mstime"
 )
 
+// serviceFlagsProtocolVersion is the first protocol version in which
+// peers advertise the services they actually provide
+const serviceFlagsProtocolVersion = 6
+
+// legacyServices are the services that are assumed to be provided by peers
+// that advertise an older protocol version, since they were provided by
+// every such node regardless of what it advertised.
+const legacyServices = appmessage.SFNodeNetwork | appmessage.SFNodePruningUTXOSet
+
 // Peer holds data about a peer.
 type Peer struct {
 	connection *netadapter.NetConnection
@@ -75,6 +84,19 @@ func (p *Peer) AdvertisedProtocolVersion() uint32 {
 	return p.advertisedProtocolVerion
 }
 
+// Services returns the services the peer provides
+func (p *Peer) Services() appmessage.ServiceFlag {
+	if p.advertisedProtocolVerion < serviceFlagsProtocolVersion {
+		return legacyServices
+	}
+	return p.services
+}
+
+// HasServices returns whether the peer provides all the given services
+func (p *Peer) HasServices(services appmessage.ServiceFlag) bool {
+	return p.Services()&services == services
+}
+
 // ProtocolVersion returns the protocol version which is used when communicating with the peer.
 func (p *Peer) ProtocolVersion() uint32 {
 	return p.protocolVersion
@@ -0,0 +1,138 @@
+package peerscore
+
+import (
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/sedracoin/sedrad/app/protocol/protocolerrors"
+)
+
+const (
+	// scoreHalfLife is the time it takes a misbehavior score to decay to half of its value
+	scoreHalfLife = time.Hour
+
+	// minTrackedScore is the score below which a peer's misbehavior record is forgotten
+	minTrackedScore = 1
+
+	// MaxRecentViolations is the number of most recent violations that are kept for each peer
+	MaxRecentViolations = 10
+)
+
+// Violation is a single misbehavior of a peer
+type Violation struct {
+	Misbehavior protocolerrors.Misbehavior
+	Reason      string
+	Time        time.Time
+}
+
+type record struct {
+	score            float64
+	lastUpdateTime   time.Time
+	recentViolations []*Violation
+}
+
+// Tracker keeps track of the misbehavior scores of peers. Scores are kept per
+// IP address, so that they survive reconnections, and decay exponentially over time.
+type Tracker struct {
+	records map[string]*record
+	lock    sync.Mutex
+	now     func() time.Time
+}
+
+// New returns a new, empty, Tracker
+func New() *Tracker {
+	return &Tracker{
+		records: make(map[string]*record),
+		now:     time.Now,
+	}
+}
+
+// AddViolation records the given misbehavior of the peer with the given IP,
+// and returns the resulting misbehavior score of that peer
+func (t *Tracker) AddViolation(ip net.IP, misbehavior protocolerrors.Misbehavior, reason string) float64 {
+	return t.addViolation(ip, misbehavior, reason, math.Inf(1))
+}
+
+// AddCappedViolation records the given misbehavior of the peer with the given IP
+// like AddViolation, except that the misbehavior doesn't raise the score of the
+// peer above maxScore. A score that is already above maxScore is kept as is.
+func (t *Tracker) AddCappedViolation(ip net.IP, misbehavior protocolerrors.Misbehavior, reason string,
+	maxScore float64) float64 {
+
+	return t.addViolation(ip, misbehavior, reason, maxScore)
+}
+
+func (t *Tracker) addViolation(ip net.IP, misbehavior protocolerrors.Misbehavior, reason string,
+	maxScore float64) float64 {
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.now()
+	t.decayAndPrune(now)
+
+	key := ip.String()
+	peerRecord, ok := t.records[key]
+	if !ok {
+		peerRecord = &record{lastUpdateTime: now}
+		t.records[key] = peerRecord
+	}
+
+	if peerRecord.score < maxScore {
+		peerRecord.score = math.Min(peerRecord.score+float64(misbehavior.Score()), maxScore)
+	}
+	peerRecord.recentViolations = append(peerRecord.recentViolations, &Violation{
+		Misbehavior: misbehavior,
+		Reason:      reason,
+		Time:        now,
+	})
+	if len(peerRecord.recentViolations) > MaxRecentViolations {
+		peerRecord.recentViolations = peerRecord.recentViolations[len(peerRecord.recentViolations)-MaxRecentViolations:]
+	}
+	return peerRecord.score
+}
+
+// Score returns the current misbehavior score of the peer with the given IP
+func (t *Tracker) Score(ip net.IP) float64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.decayAndPrune(t.now())
+	peerRecord, ok := t.records[ip.String()]
+	if !ok {
+		return 0
+	}
+	return peerRecord.score
+}
+
+// RecentViolations returns the most recent violations of the peer
+// with the given IP, ordered from the oldest to the newest
+func (t *Tracker) RecentViolations(ip net.IP) []*Violation {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.decayAndPrune(t.now())
+	peerRecord, ok := t.records[ip.String()]
+	if !ok {
+		return nil
+	}
+	recentViolations := make([]*Violation, len(peerRecord.recentViolations))
+	copy(recentViolations, peerRecord.recentViolations)
+	return recentViolations
+}
+
+// decayAndPrune must be called with the tracker lock held
+func (t *Tracker) decayAndPrune(now time.Time) {
+	for key, peerRecord := range t.records {
+		elapsed := now.Sub(peerRecord.lastUpdateTime)
+		if elapsed > 0 {
+			peerRecord.score *= math.Pow(0.5, float64(elapsed)/float64(scoreHalfLife))
+			peerRecord.lastUpdateTime = now
+		}
+		if peerRecord.score < minTrackedScore {
+			delete(t.records, key)
+		}
+	}
+}
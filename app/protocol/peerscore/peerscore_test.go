@@ -0,0 +1,97 @@
+package peerscore
+
+import (
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sedracoin/sedrad/app/protocol/protocolerrors"
+)
+
+func TestTracker(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	tracker := New()
+	tracker.now = func() time.Time { return now }
+
+	ip := net.ParseIP("10.0.0.1")
+	otherIP := net.ParseIP("10.0.0.2")
+
+	score := tracker.AddViolation(ip, protocolerrors.MisbehaviorSlowResponse, "timeout")
+	if score != float64(protocolerrors.MisbehaviorSlowResponse.Score()) {
+		t.Fatalf("unexpected score after a single violation: %f", score)
+	}
+	score = tracker.AddViolation(ip, protocolerrors.MisbehaviorUnrequestedMessage, "unrequested block")
+	expectedScore := float64(protocolerrors.MisbehaviorSlowResponse.Score() + protocolerrors.MisbehaviorUnrequestedMessage.Score())
+	if score != expectedScore {
+		t.Fatalf("expected score %f but got %f", expectedScore, score)
+	}
+	if tracker.Score(otherIP) != 0 {
+		t.Fatalf("violations of one peer affected the score of another")
+	}
+
+	// The score should halve after a half-life
+	now = now.Add(scoreHalfLife)
+	if score := tracker.Score(ip); math.Abs(score-expectedScore/2) > 0.0001 {
+		t.Fatalf("expected score %f after a half-life but got %f", expectedScore/2, score)
+	}
+
+	for i := 0; i < MaxRecentViolations+5; i++ {
+		tracker.AddViolation(otherIP, protocolerrors.MisbehaviorNone, "benign")
+	}
+	recentViolations := tracker.RecentViolations(otherIP)
+	if len(recentViolations) != 0 {
+		t.Fatalf("violations that don't add to the score should be forgotten, but got %d", len(recentViolations))
+	}
+
+	for i := 0; i < MaxRecentViolations+5; i++ {
+		tracker.AddViolation(otherIP, protocolerrors.MisbehaviorSlowResponse, "timeout")
+	}
+	recentViolations = tracker.RecentViolations(otherIP)
+	if len(recentViolations) != MaxRecentViolations {
+		t.Fatalf("expected %d recent violations but got %d", MaxRecentViolations, len(recentViolations))
+	}
+
+	// After enough time passes, the peer is forgotten
+	now = now.Add(20 * scoreHalfLife)
+	if score := tracker.Score(ip); score != 0 {
+		t.Fatalf("expected the score to decay to 0 but got %f", score)
+	}
+	if len(tracker.RecentViolations(ip)) != 0 {
+		t.Fatalf("expected the violations of a forgotten peer to be removed")
+	}
+}
+
+func TestTrackerCappedViolations(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	tracker := New()
+	tracker.now = func() time.Time { return now }
+
+	ip := net.ParseIP("10.0.0.1")
+	const maxScore = 50
+
+	for i := 0; i < 20; i++ {
+		score := tracker.AddCappedViolation(ip, protocolerrors.MisbehaviorSlowResponse, "timeout", maxScore)
+		if score > maxScore {
+			t.Fatalf("capped violations raised the score to %f, above %d", score, maxScore)
+		}
+	}
+	if score := tracker.Score(ip); score != maxScore {
+		t.Fatalf("expected the score to reach the cap of %d but got %f", maxScore, score)
+	}
+	if len(tracker.RecentViolations(ip)) != MaxRecentViolations {
+		t.Fatalf("capped violations should still be recorded")
+	}
+
+	// Uncapped violations aren't affected by the cap, and capped violations
+	// don't lower a score that is already above their cap
+	expectedScore := float64(maxScore + protocolerrors.MisbehaviorUnrequestedMessage.Score())
+	score := tracker.AddViolation(ip, protocolerrors.MisbehaviorUnrequestedMessage, "unrequested block")
+	if score != expectedScore {
+		t.Fatalf("expected score %f but got %f", expectedScore, score)
+	}
+	score = tracker.AddCappedViolation(ip, protocolerrors.MisbehaviorSlowResponse, "timeout", maxScore)
+	if score != expectedScore {
+		t.Fatalf("expected a capped violation to keep the score at %f but got %f", expectedScore, score)
+	}
+}
@@ -49,7 +49,7 @@ func (m *Manager) routerInitializer(router *routerpkg.Router, netConnection *net
 
 		netConnection.SetOnInvalidMessageHandler(func(err error) {
 			if atomic.AddUint32(&isStopping, 1) == 1 {
-				errChan <- protocolerrors.Wrap(true, err, "received bad message")
+				errChan <- protocolerrors.WrapMisbehaviorf(protocolerrors.MisbehaviorMalformedMessage, err, "received bad message")
 			}
 		})
 
@@ -76,7 +76,10 @@ func (m *Manager) routerInitializer(router *routerpkg.Router, netConnection *net
 		var flows []*common.Flow
 		log.Infof("Registering p2p flows for peer %s for protocol version %d", peer, peer.ProtocolVersion())
 		switch peer.ProtocolVersion() {
-		case 5:
+		case 5, 6:
+			// Protocol version 6 only adds compact block relay and stem transactions
+			// on top of version 5, so both are served by the same flows, which check
+			// the negotiated version where the two differ.
 			flows = v5.Register(m, router, errChan, &isStopping)
 		default:
 			panic(errors.Errorf("no way to handle protocol version %d", peer.ProtocolVersion()))
@@ -105,26 +108,18 @@ func (m *Manager) routerInitializer(router *routerpkg.Router, netConnection *net
 }
 
 func (m *Manager) handleError(err error, netConnection *netadapter.NetConnection, outgoingRoute *routerpkg.Route) {
-	if protocolErr := (protocolerrors.ProtocolError{}); errors.As(err, &protocolErr) {
-		if m.context.Config().EnableBanning && protocolErr.ShouldBan {
-			log.Warnf("Banning %s (reason: %s)", netConnection, protocolErr.Cause)
-
-			err := m.context.ConnectionManager().Ban(netConnection)
-			if err != nil && !errors.Is(err, connmanager.ErrCannotBanPermanent) {
-				panic(err)
-			}
-
-			err = outgoingRoute.Enqueue(appmessage.NewMsgReject(protocolErr.Error()))
-			if err != nil && !errors.Is(err, routerpkg.ErrRouteClosed) {
-				panic(err)
-			}
-		}
-		log.Infof("Disconnecting from %s (reason: %s)", netConnection, protocolErr.Cause)
+	// ErrTimeout is a ProtocolError itself, so it's checked first
+	if errors.Is(err, routerpkg.ErrTimeout) {
+		log.Warnf("Got timeout from %s. Disconnecting...", netConnection)
+		m.addMisbehavior(netConnection, outgoingRoute, protocolerrors.MisbehaviorSlowResponse, err)
 		netConnection.Disconnect()
 		return
 	}
-	if errors.Is(err, routerpkg.ErrTimeout) {
-		log.Warnf("Got timeout from %s. Disconnecting...", netConnection)
+	if protocolErr := (protocolerrors.ProtocolError{}); errors.As(err, &protocolErr) {
+		if protocolErr.ShouldBan {
+			m.addMisbehavior(netConnection, outgoingRoute, protocolErr.Misbehavior, protocolErr.Cause)
+		}
+		log.Infof("Disconnecting from %s (reason: %s)", netConnection, protocolErr.Cause)
 		netConnection.Disconnect()
 		return
 	}
@@ -134,6 +129,44 @@ func (m *Manager) handleError(err error, netConnection *netadapter.NetConnection
 	panic(err)
 }
 
+// slowResponseScoreCapRatio is the part of the ban threshold that slow responses can
+// raise the misbehavior score of a peer to. Slow but honest peers time out every now
+// and then, so timeouts alone never get a peer banned, but they still bring a peer
+// that also misbehaves otherwise closer to a ban.
+const slowResponseScoreCapRatio = 0.5
+
+// addMisbehavior adds the given misbehavior to the misbehavior score of the given peer,
+// and bans the peer if its score has reached the ban threshold
+func (m *Manager) addMisbehavior(netConnection *netadapter.NetConnection, outgoingRoute *routerpkg.Route,
+	misbehavior protocolerrors.Misbehavior, reason error) {
+
+	ip := netConnection.NetAddress().IP
+	var score float64
+	if misbehavior == protocolerrors.MisbehaviorSlowResponse {
+		maxScore := float64(m.context.Config().BanThreshold) * slowResponseScoreCapRatio
+		score = m.context.PeerScores().AddCappedViolation(ip, misbehavior, reason.Error(), maxScore)
+	} else {
+		score = m.context.PeerScores().AddViolation(ip, misbehavior, reason.Error())
+	}
+	log.Debugf("Peer %s misbehaved (%s), and its misbehavior score is now %.2f",
+		netConnection, misbehavior, score)
+
+	if !m.context.Config().EnableBanning || score < float64(m.context.Config().BanThreshold) {
+		return
+	}
+
+	log.Warnf("Banning %s (score: %.2f, reason: %s)", netConnection, score, reason)
+	err := m.context.ConnectionManager().Ban(netConnection)
+	if err != nil && !errors.Is(err, connmanager.ErrCannotBanPermanent) {
+		panic(err)
+	}
+
+	err = outgoingRoute.Enqueue(appmessage.NewMsgReject(reason.Error()))
+	if err != nil && !errors.Is(err, routerpkg.ErrRouteClosed) {
+		panic(err)
+	}
+}
+
 // RegisterFlow registers a flow to the given router.
 func (m *Manager) RegisterFlow(name string, router *routerpkg.Router, messageTypes []appmessage.MessageCommand, isStopping *uint32,
 	errChan chan error, initializeFunc common.FlowInitializeFunc) *common.Flow {
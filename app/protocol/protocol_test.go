@@ -0,0 +1,139 @@
+package protocol
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/app/protocol/flowcontext"
+	"github.com/sedracoin/sedrad/app/protocol/protocolerrors"
+	"github.com/sedracoin/sedrad/infrastructure/config"
+	"github.com/sedracoin/sedrad/infrastructure/db/database/ldb"
+	"github.com/sedracoin/sedrad/infrastructure/network/addressmanager"
+	"github.com/sedracoin/sedrad/infrastructure/network/connmanager"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter"
+	routerpkg "github.com/sedracoin/sedrad/infrastructure/network/netadapter/router"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/server"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/server/simulatedserver"
+	"github.com/pkg/errors"
+)
+
+func TestHandleErrorBans(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Listeners = nil
+	cfg.RPCListeners = nil
+	cfg.EnableBanning = true
+	// An unrequested message alone stays below the ban threshold, but together with
+	// the capped score of the timeouts it exceeds it
+	cfg.BanThreshold = 90
+
+	database, err := ldb.NewLevelDB(t.TempDir(), 8)
+	if err != nil {
+		t.Fatalf("NewLevelDB: %+v", err)
+	}
+	defer database.Close()
+
+	addressManagerConfig := addressmanager.NewConfig(cfg)
+	// The simulated network uses private addresses
+	addressManagerConfig.AcceptUnroutable = true
+	addressManager, err := addressmanager.New(addressManagerConfig, database)
+	if err != nil {
+		t.Fatalf("addressmanager.New: %+v", err)
+	}
+	netAdapter, netConnection := newTestNetConnection(t, cfg)
+	connectionManager, err := connmanager.New(cfg, netAdapter, addressManager)
+	if err != nil {
+		t.Fatalf("connmanager.New: %+v", err)
+	}
+	// The address of an outbound peer is known to the address manager
+	err = addressManager.AddAddress(netConnection.NetAddress())
+	if err != nil {
+		t.Fatalf("AddAddress: %+v", err)
+	}
+	manager := &Manager{
+		context: flowcontext.New(cfg, nil, addressManager, netAdapter, connectionManager),
+	}
+	outgoingRoute := routerpkg.NewRoute("outgoing")
+
+	isBanned := func() bool {
+		isBanned, err := connectionManager.IsBanned(netConnection)
+		if err != nil {
+			t.Fatalf("IsBanned: %+v", err)
+		}
+		return isBanned
+	}
+
+	// A slow but honest peer isn't banned no matter how many times it times out
+	for i := 0; i < 3*int(cfg.BanThreshold); i++ {
+		manager.handleError(errors.Wrap(routerpkg.ErrTimeout, "got timeout"), netConnection, outgoingRoute)
+	}
+	if isBanned() {
+		t.Fatalf("A peer was banned for timing out")
+	}
+	score := manager.context.PeerScores().Score(netConnection.NetAddress().IP)
+	if score >= float64(cfg.BanThreshold) {
+		t.Fatalf("Timeouts raised the misbehavior score to %f, while the ban threshold is %d",
+			score, cfg.BanThreshold)
+	}
+
+	// The timeouts still count towards the ban of a peer that also misbehaves otherwise
+	manager.handleError(protocolerrors.MisbehaviorErrorf(protocolerrors.MisbehaviorUnrequestedMessage,
+		"unrequested block"), netConnection, outgoingRoute)
+	if !isBanned() {
+		t.Fatalf("A peer that timed out and sent an unrequested block wasn't banned")
+	}
+	message, err := outgoingRoute.DequeueWithTimeout(time.Second)
+	if err != nil {
+		t.Fatalf("Expected a reject message to be sent to the banned peer: %+v", err)
+	}
+	if message.Command() != appmessage.CmdReject {
+		t.Fatalf("Expected a reject message to be sent to the banned peer but got %s", message.Command())
+	}
+}
+
+// newTestNetConnection connects a NetAdapter to a peer over a simulated network,
+// and returns the NetAdapter along with its connection to the peer
+func newTestNetConnection(t *testing.T, cfg *config.Config) (*netadapter.NetAdapter, *netadapter.NetConnection) {
+	const localAddress, peerAddress = "10.0.0.1:16111", "10.0.0.2:16111"
+
+	network, err := simulatedserver.NewNetwork(simulatedserver.LinkConfig{}, 0)
+	if err != nil {
+		t.Fatalf("NewNetwork: %+v", err)
+	}
+	peerServer, err := network.NewP2PServer(peerAddress)
+	if err != nil {
+		t.Fatalf("NewP2PServer: %+v", err)
+	}
+	peerServer.SetOnConnectedHandler(func(connection server.Connection) error {
+		connectionRouter := routerpkg.NewRouter(peerAddress)
+		connection.SetOnDisconnectedHandler(connectionRouter.Close)
+		connection.Start(connectionRouter)
+		return nil
+	})
+	err = peerServer.Start()
+	if err != nil {
+		t.Fatalf("Start: %+v", err)
+	}
+
+	p2pServer, err := network.NewP2PServer(localAddress)
+	if err != nil {
+		t.Fatalf("NewP2PServer: %+v", err)
+	}
+	netAdapter, err := netadapter.NewNetAdapterWithP2PServer(cfg, p2pServer)
+	if err != nil {
+		t.Fatalf("NewNetAdapterWithP2PServer: %+v", err)
+	}
+	netConnections := make(chan *netadapter.NetConnection, 1)
+	netAdapter.SetP2PRouterInitializer(func(_ *routerpkg.Router, netConnection *netadapter.NetConnection) {
+		netConnections <- netConnection
+	})
+	err = p2pServer.Start()
+	if err != nil {
+		t.Fatalf("Start: %+v", err)
+	}
+	err = netAdapter.P2PConnect(peerAddress)
+	if err != nil {
+		t.Fatalf("P2PConnect: %+v", err)
+	}
+	return netAdapter, <-netConnections
+}
@@ -0,0 +1,68 @@
+package protocolerrors
+
+import "fmt"
+
+// Misbehavior is the type of a protocol violation. Every misbehavior adds its
+// score to the misbehavior score of the violating peer, and the peer is banned
+// once its score reaches the configured ban threshold.
+type Misbehavior uint8
+
+// Misbehavior types
+const (
+	// MisbehaviorNone is a protocol error that does not count as misbehavior,
+	// and only causes a disconnection from the peer.
+	MisbehaviorNone Misbehavior = iota
+
+	// MisbehaviorProtocolViolation is any protocol violation that does not have
+	// a more specific misbehavior type.
+	MisbehaviorProtocolViolation
+
+	// MisbehaviorInvalidBlock is sending a block, header or any other consensus
+	// data that breaks the consensus rules.
+	MisbehaviorInvalidBlock
+
+	// MisbehaviorInvalidTransaction is sending a transaction that can never be valid.
+	MisbehaviorInvalidTransaction
+
+	// MisbehaviorUnrequestedMessage is sending data that was not requested.
+	MisbehaviorUnrequestedMessage
+
+	// MisbehaviorMalformedMessage is sending a message that cannot be decoded.
+	MisbehaviorMalformedMessage
+
+	// MisbehaviorSlowResponse is not responding to a request in time.
+	MisbehaviorSlowResponse
+)
+
+var misbehaviorScores = map[Misbehavior]uint32{
+	MisbehaviorNone:               0,
+	MisbehaviorProtocolViolation:  100,
+	MisbehaviorInvalidBlock:       100,
+	MisbehaviorInvalidTransaction: 100,
+	MisbehaviorUnrequestedMessage: 50,
+	MisbehaviorMalformedMessage:   100,
+	MisbehaviorSlowResponse:       10,
+}
+
+var misbehaviorStrings = map[Misbehavior]string{
+	MisbehaviorNone:               "None",
+	MisbehaviorProtocolViolation:  "ProtocolViolation",
+	MisbehaviorInvalidBlock:       "InvalidBlock",
+	MisbehaviorInvalidTransaction: "InvalidTransaction",
+	MisbehaviorUnrequestedMessage: "UnrequestedMessage",
+	MisbehaviorMalformedMessage:   "MalformedMessage",
+	MisbehaviorSlowResponse:       "SlowResponse",
+}
+
+// Score returns the amount by which this misbehavior increases the
+// misbehavior score of the violating peer.
+func (m Misbehavior) Score() uint32 {
+	return misbehaviorScores[m]
+}
+
+func (m Misbehavior) String() string {
+	if misbehaviorString, ok := misbehaviorStrings[m]; ok {
+		return misbehaviorString
+	}
+	return fmt.Sprintf("<unknown misbehavior %d>", m)
+}
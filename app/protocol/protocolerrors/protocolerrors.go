@@ -8,8 +8,18 @@ import (
 // ProtocolError is an error that signifies a violation
 // of the peer-to-peer protocol
 type ProtocolError struct {
-	ShouldBan bool
-	Cause     error
+	// ShouldBan is true if the violation counts as misbehavior, and
+	// thus adds to the misbehavior score of the peer
+	ShouldBan   bool
+	Misbehavior Misbehavior
+	Cause       error
+}
+
+func misbehaviorFromShouldBan(shouldBan bool) Misbehavior {
+	if shouldBan {
+		return MisbehaviorProtocolViolation
+	}
+	return MisbehaviorNone
 }
 
 func (e ProtocolError) Error() string {
@@ -25,8 +35,9 @@ func (e ProtocolError) Unwrap() error {
 // as a ProtocolError.
 func Errorf(shouldBan bool, format string, args ...interface{}) error {
 	return ProtocolError{
-		ShouldBan: shouldBan,
-		Cause:     errors.Errorf(format, args...),
+		ShouldBan:   shouldBan,
+		Misbehavior: misbehaviorFromShouldBan(shouldBan),
+		Cause:       errors.Errorf(format, args...),
 	}
 }
 
@@ -34,24 +45,47 @@ func Errorf(shouldBan bool, format string, args ...interface{}) error {
 // New also records the stack trace at the point it was called.
 func New(shouldBan bool, message string) error {
 	return ProtocolError{
-		ShouldBan: shouldBan,
-		Cause:     errors.New(message),
+		ShouldBan:   shouldBan,
+		Misbehavior: misbehaviorFromShouldBan(shouldBan),
+		Cause:       errors.New(message),
 	}
 }
 
 // Wrap wraps the given error and returns it as a ProtocolError.
 func Wrap(shouldBan bool, err error, message string) error {
 	return ProtocolError{
-		ShouldBan: shouldBan,
-		Cause:     errors.Wrap(err, message),
+		ShouldBan:   shouldBan,
+		Misbehavior: misbehaviorFromShouldBan(shouldBan),
+		Cause:       errors.Wrap(err, message),
 	}
 }
 
 // Wrapf wraps the given error with the given format and returns it as a ProtocolError.
 func Wrapf(shouldBan bool, err error, format string, args ...interface{}) error {
 	return ProtocolError{
-		ShouldBan: shouldBan,
-		Cause:     errors.Wrapf(err, format, args...),
+		ShouldBan:   shouldBan,
+		Misbehavior: misbehaviorFromShouldBan(shouldBan),
+		Cause:       errors.Wrapf(err, format, args...),
+	}
+}
+
+// MisbehaviorErrorf formats according to a format specifier and returns the string
+// as a ProtocolError of the given misbehavior type.
+func MisbehaviorErrorf(misbehavior Misbehavior, format string, args ...interface{}) error {
+	return ProtocolError{
+		ShouldBan:   misbehavior != MisbehaviorNone,
+		Misbehavior: misbehavior,
+		Cause:       errors.Errorf(format, args...),
+	}
+}
+
+// WrapMisbehaviorf wraps the given error with the given format and returns it as
+// a ProtocolError of the given misbehavior type.
+func WrapMisbehaviorf(misbehavior Misbehavior, err error, format string, args ...interface{}) error {
+	return ProtocolError{
+		ShouldBan:   misbehavior != MisbehaviorNone,
+		Misbehavior: misbehavior,
+		Cause:       errors.Wrapf(err, format, args...),
 	}
 }
 
@@ -63,5 +97,5 @@ func ConvertToBanningProtocolErrorIfRuleError(err error, format string, args ...
 		return err
 	}
 
-	return Wrapf(true, err, format, args...)
+	return WrapMisbehaviorf(MisbehaviorInvalidBlock, err, format, args...)
 }
@@ -0,0 +1,254 @@
+// Package dagviewer serves a web page that renders the recent DAG of the node live.
+//
+// The page loads the last blocks of the DAG from /dag, and then adds every block
+// it gets from /events, which streams the block-added notifications of the node
+// as server-sent events.
+package dagviewer
+
+import (
+	// _ "embed" is necessary for the go:embed feature.
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/app/rpc/rpccontext"
+	routerpkg "github.com/sedracoin/sedrad/infrastructure/network/netadapter/router"
+	"github.com/pkg/errors"
+)
+
+//go:embed index.html
+var indexHTML []byte
+
+const (
+	// defaultBlockCount is the number of DAA scores the page shows by default
+	defaultBlockCount = 200
+
+	// maxBlockCount is the maximal number of DAA scores the page can show
+	maxBlockCount = 2000
+
+	// maxSubscribers is the maximal number of pages that can be open at the same time
+	maxSubscribers = 16
+
+	// subscriberBufferSize is the number of blocks buffered for a page before
+	// new blocks are dropped for it
+	subscriberBufferSize = 100
+)
+
+// Server is an HTTP server that serves the DAG viewer page
+type Server struct {
+	context    *rpccontext.Context
+	listener   net.Listener
+	httpServer *http.Server
+	router     *routerpkg.Router
+
+	subscribersLock sync.Mutex
+	subscribers     map[chan []byte]struct{}
+}
+
+// New creates a DAG viewer server that listens on the given address.
+// Use Start() to start serving.
+func New(context *rpccontext.Context, listenAddress string) (*Server, error) {
+	listener, err := net.Listen("tcp", listenAddress)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to listen on %s", listenAddress)
+	}
+
+	server := &Server{
+		context:     context,
+		listener:    listener,
+		router:      routerpkg.NewRouter("DAG viewer"),
+		subscribers: make(map[chan []byte]struct{}),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", server.handleIndex)
+	mux.HandleFunc("/dag", server.handleDAG)
+	mux.HandleFunc("/events", server.handleEvents)
+	server.httpServer = &http.Server{Handler: mux}
+
+	return server, nil
+}
+
+// Start starts listening to block-added notifications and serving the page
+func (s *Server) Start() error {
+	s.context.NotificationManager.AddListener(s.router)
+	listener, err := s.context.NotificationManager.Listener(s.router)
+	if err != nil {
+		return err
+	}
+	listener.PropagateBlockAddedNotifications()
+
+	spawn("dagviewer.Server.broadcastLoop", s.broadcastLoop)
+	spawn("dagviewer.Server.serve", func() {
+		err := s.httpServer.Serve(s.listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("DAG viewer server stopped: %s", err)
+		}
+	})
+
+	log.Infof("DAG viewer listening on %s", s.listener.Addr())
+	return nil
+}
+
+// Stop stops serving the page and closes all the open event streams
+func (s *Server) Stop() error {
+	s.context.NotificationManager.RemoveListener(s.router)
+	s.router.Close()
+	return s.httpServer.Close()
+}
+
+func (s *Server) broadcastLoop() {
+	for {
+		message, err := s.router.OutgoingRoute().Dequeue()
+		if err != nil {
+			if errors.Is(err, routerpkg.ErrRouteClosed) {
+				return
+			}
+			panic(err)
+		}
+		notification, ok := message.(*appmessage.BlockAddedNotificationMessage)
+		if !ok {
+			continue
+		}
+
+		blockJSON, err := json.Marshal(rpcBlockToRPCDAGBlock(notification.Block))
+		if err != nil {
+			panic(err)
+		}
+		s.broadcast(blockJSON)
+	}
+}
+
+func (s *Server) broadcast(blockJSON []byte) {
+	s.subscribersLock.Lock()
+	defer s.subscribersLock.Unlock()
+
+	for subscriber := range s.subscribers {
+		select {
+		case subscriber <- blockJSON:
+		default:
+			// The page doesn't keep up, so the block is dropped for it. It
+			// will show up once the page reloads the DAG.
+		}
+	}
+}
+
+func (s *Server) subscribe() (chan []byte, bool) {
+	s.subscribersLock.Lock()
+	defer s.subscribersLock.Unlock()
+
+	if len(s.subscribers) >= maxSubscribers {
+		return nil, false
+	}
+	subscriber := make(chan []byte, subscriberBufferSize)
+	s.subscribers[subscriber] = struct{}{}
+	return subscriber, true
+}
+
+func (s *Server) unsubscribe(subscriber chan []byte) {
+	s.subscribersLock.Lock()
+	defer s.subscribersLock.Unlock()
+
+	delete(s.subscribers, subscriber)
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(indexHTML)
+}
+
+func (s *Server) handleDAG(w http.ResponseWriter, r *http.Request) {
+	blockCount := uint64(defaultBlockCount)
+	if blockCountString := r.URL.Query().Get("blocks"); blockCountString != "" {
+		var err error
+		blockCount, err = strconv.ParseUint(blockCountString, 10, 64)
+		if err != nil || blockCount == 0 || blockCount > maxBlockCount {
+			http.Error(w, fmt.Sprintf("blocks must be a number between 1 and %d", maxBlockCount),
+				http.StatusBadRequest)
+			return
+		}
+	}
+
+	blocks, err := s.context.DAGBlocks("", "", blockCount)
+	if err != nil {
+		rpcError := &appmessage.RPCError{}
+		if errors.As(err, &rpcError) {
+			http.Error(w, rpcError.Message, http.StatusBadRequest)
+			return
+		}
+		log.Errorf("Failed to get the DAG: %+v", err)
+		http.Error(w, "failed to get the DAG", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(blocks)
+	if err != nil {
+		log.Debugf("Failed to write the DAG: %s", err)
+	}
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	subscriber, ok := s.subscribe()
+	if !ok {
+		http.Error(w, "too many open DAG viewer pages", http.StatusServiceUnavailable)
+		return
+	}
+	defer s.unsubscribe(subscriber)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case blockJSON := <-subscriber:
+			_, err := fmt.Fprintf(w, "data: %s\n\n", blockJSON)
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// rpcBlockToRPCDAGBlock converts the block of a block-added notification to an
+// RPCDAGBlock. The block is colored only if it's a chain block, since the blocks
+// that merge it don't exist yet.
+func rpcBlockToRPCDAGBlock(block *appmessage.RPCBlock) *appmessage.RPCDAGBlock {
+	dagBlock := &appmessage.RPCDAGBlock{
+		DAAScore:  block.Header.DAAScore,
+		Timestamp: block.Header.Timestamp,
+	}
+	if len(block.Header.Parents) > 0 {
+		dagBlock.ParentHashes = block.Header.Parents[0].ParentHashes
+	}
+	if block.VerboseData != nil {
+		dagBlock.Hash = block.VerboseData.Hash
+		dagBlock.SelectedParentHash = block.VerboseData.SelectedParentHash
+		dagBlock.BlueScore = block.VerboseData.BlueScore
+		dagBlock.IsChainBlock = block.VerboseData.IsChainBlock
+		dagBlock.MergeSetBluesHashes = block.VerboseData.MergeSetBluesHashes
+		dagBlock.MergeSetRedsHashes = block.VerboseData.MergeSetRedsHashes
+	}
+	if dagBlock.IsChainBlock {
+		dagBlock.Color = appmessage.RPCDAGBlockColorBlue
+	}
+	return dagBlock
+}
@@ -0,0 +1,90 @@
+package dagviewer
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/app/rpc/rpccontext"
+	"github.com/sedracoin/sedrad/domain/dagconfig"
+)
+
+func TestEvents(t *testing.T) {
+	context := &rpccontext.Context{NotificationManager: rpccontext.NewNotificationManager(&dagconfig.SimnetParams)}
+	server, err := New(context, "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("New: %+v", err)
+	}
+	err = server.Start()
+	if err != nil {
+		t.Fatalf("Start: %+v", err)
+	}
+	defer func() {
+		err := server.Stop()
+		if err != nil {
+			t.Fatalf("Stop: %+v", err)
+		}
+	}()
+
+	response, err := http.Get("http://" + server.listener.Addr().String() + "/events")
+	if err != nil {
+		t.Fatalf("Get: %+v", err)
+	}
+	defer response.Body.Close()
+	if response.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Unexpected content type %s", response.Header.Get("Content-Type"))
+	}
+
+	// The page is subscribed once the headers are flushed
+	for {
+		server.subscribersLock.Lock()
+		subscriberCount := len(server.subscribers)
+		server.subscribersLock.Unlock()
+		if subscriberCount == 1 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	notification := appmessage.NewBlockAddedNotificationMessage(&appmessage.RPCBlock{
+		Header: &appmessage.RPCBlockHeader{
+			Parents:  []*appmessage.RPCBlockLevelParents{{ParentHashes: []string{"parent1", "parent2"}}},
+			DAAScore: 7,
+		},
+		VerboseData: &appmessage.RPCBlockVerboseData{
+			Hash:                "block",
+			SelectedParentHash:  "parent1",
+			BlueScore:           5,
+			MergeSetBluesHashes: []string{"parent1"},
+			MergeSetRedsHashes:  []string{"parent2"},
+			IsChainBlock:        true,
+		},
+	})
+	err = context.NotificationManager.NotifyBlockAdded(notification)
+	if err != nil {
+		t.Fatalf("NotifyBlockAdded: %+v", err)
+	}
+
+	reader := bufio.NewReader(response.Body)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		t.Fatalf("ReadString: %+v", err)
+	}
+	if !strings.HasPrefix(line, "data: ") {
+		t.Fatalf("Unexpected event line %q", line)
+	}
+	block := &appmessage.RPCDAGBlock{}
+	err = json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), block)
+	if err != nil {
+		t.Fatalf("Unmarshal: %+v", err)
+	}
+	if block.Hash != "block" || block.SelectedParentHash != "parent1" || len(block.ParentHashes) != 2 ||
+		block.BlueScore != 5 || block.DAAScore != 7 || !block.IsChainBlock ||
+		block.Color != appmessage.RPCDAGBlockColorBlue || len(block.MergeSetRedsHashes) != 1 {
+		t.Fatalf("Unexpected block %+v", block)
+	}
+}
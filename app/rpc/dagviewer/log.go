@@ -0,0 +1,9 @@
+package dagviewer
+
+import (
+	"github.com/sedracoin/sedrad/infrastructure/logger"
+	"github.com/sedracoin/sedrad/util/panics"
+)
+
+var log = logger.RegisterSubSystem("DAGV")
+var spawn = panics.GoroutineWrapperFunc(log)
@@ -51,6 +51,11 @@ func NewManager(
 	return &manager
 }
 
+// Context returns the context the RPC handlers are run with
+func (m *Manager) Context() *rpccontext.Context {
+	return m.context
+}
+
 func (m *Manager) initConsensusEventsHandler(consensusEventsChan chan externalapi.ConsensusEvent) {
 	spawn("consensusEventsHandler", func() {
 		for {
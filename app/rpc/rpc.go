@@ -51,6 +51,11 @@ var handlers = map[appmessage.MessageCommand]handler{
 	appmessage.CmdNotifyNewBlockTemplateRequestMessage:                      rpchandlers.HandleNotifyNewBlockTemplate,
 	appmessage.CmdGetCoinSupplyRequestMessage:                               rpchandlers.HandleGetCoinSupply,
 	appmessage.CmdGetMempoolEntriesByAddressesRequestMessage:                rpchandlers.HandleGetMempoolEntriesByAddresses,
+	appmessage.CmdGetUTXOsByAddressesAtBlockRequestMessage:                  rpchandlers.HandleGetUTXOsByAddressesAtBlock,
+	appmessage.CmdGetBalancesByAddressesAtBlockRequestMessage:               rpchandlers.HandleGetBalancesByAddressesAtBlock,
+	appmessage.CmdGetNetTotalsRequestMessage:                                rpchandlers.HandleGetNetTotals,
+	appmessage.CmdVerifyMessageRequestMessage:                               rpchandlers.HandleVerifyMessage,
+	appmessage.CmdGetDAGRequestMessage:                                      rpchandlers.HandleGetDAG,
 }
 
 func (m *Manager) routerInitializer(router *router.Router, netConnection *netadapter.NetConnection) {
@@ -0,0 +1,340 @@
+package rpccontext
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/domain/consensus/model"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/hashes"
+)
+
+// MaxDAGBlocks is the number of blocks after which a sub-DAG returned by DAGBlocks is cut off
+const MaxDAGBlocks = 10_000
+
+// DAGBlocks returns the sub-DAG that's selected either by lowHash and highHash, or by
+// lastDAABlockCount, sorted topologically. See GetDagRequestMessage for the exact semantics.
+func (ctx *Context) DAGBlocks(lowHashString string, highHashString string, lastDAABlockCount uint64) (
+	[]*appmessage.RPCDAGBlock, error) {
+
+	if lastDAABlockCount != 0 {
+		if lowHashString != "" || highHashString != "" {
+			return nil, appmessage.RPCErrorf("lastDaaBlockCount can't be used together with lowHash or highHash")
+		}
+		return ctx.lastDAABlocks(lastDAABlockCount)
+	}
+
+	if lowHashString == "" {
+		return nil, appmessage.RPCErrorf("Either lowHash or lastDaaBlockCount must be set")
+	}
+	lowHash, err := ctx.parseExistingBlockHash("lowHash", lowHashString)
+	if err != nil {
+		return nil, err
+	}
+	var highHash *externalapi.DomainHash
+	if highHashString != "" {
+		highHash, err = ctx.parseExistingBlockHash("highHash", highHashString)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	blockHashes, err := ctx.dagBlockHashesBetween(lowHash, highHash)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.buildRPCDAGBlocks(blockHashes)
+}
+
+func (ctx *Context) parseExistingBlockHash(name string, blockHashString string) (*externalapi.DomainHash, error) {
+	blockHash, err := externalapi.NewDomainHashFromString(blockHashString)
+	if err != nil {
+		return nil, appmessage.RPCErrorf("Could not decode %s %s: %s", name, blockHashString, err)
+	}
+	blockInfo, err := ctx.Domain.Consensus().GetBlockInfo(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if !blockInfo.HasHeader() {
+		return nil, appmessage.RPCErrorf("Could not find %s %s", name, blockHashString)
+	}
+	return blockHash, nil
+}
+
+func (ctx *Context) lastDAABlocks(count uint64) ([]*appmessage.RPCDAGBlock, error) {
+	if count > MaxDAGBlocks {
+		return nil, appmessage.RPCErrorf("lastDaaBlockCount must be at most %d", MaxDAGBlocks)
+	}
+
+	virtualDAAScore, err := ctx.Domain.Consensus().GetVirtualDAAScore()
+	if err != nil {
+		return nil, err
+	}
+	minDAAScore := uint64(0)
+	if virtualDAAScore > count {
+		minDAAScore = virtualDAAScore - count
+	}
+	lowHash, err := ctx.Domain.Consensus().GetChainBlockByDAAScore(minDAAScore)
+	if err != nil {
+		return nil, appmessage.RPCErrorf("Could not find a chain block for DAA score %d: %s", minDAAScore, err)
+	}
+
+	blockHashes, err := ctx.dagBlockHashesBetween(lowHash, nil)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := ctx.buildRPCDAGBlocks(blockHashes)
+	if err != nil {
+		return nil, err
+	}
+
+	// The anti-past of the chain block contains blocks from before it as well
+	lastBlocks := make([]*appmessage.RPCDAGBlock, 0, len(blocks))
+	for _, block := range blocks {
+		if block.DAAScore >= minDAAScore {
+			lastBlocks = append(lastBlocks, block)
+		}
+	}
+	return lastBlocks, nil
+}
+
+// dagBlockHashesBetween returns lowHash and the blocks in its anti-past that are in the past
+// of highHash, including highHash. If highHash is nil, the virtual selected parent and its
+// anticone are used instead.
+func (ctx *Context) dagBlockHashesBetween(lowHash *externalapi.DomainHash, highHash *externalapi.DomainHash) (
+	[]*externalapi.DomainHash, error) {
+
+	consensus := ctx.Domain.Consensus()
+	isHighHashVirtualSelectedParent := highHash == nil
+	if isHighHashVirtualSelectedParent {
+		var err error
+		highHash, err = consensus.GetVirtualSelectedParent()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	lowBlockInfo, err := consensus.GetBlockInfo(lowHash)
+	if err != nil {
+		return nil, err
+	}
+	highBlockInfo, err := consensus.GetBlockInfo(highHash)
+	if err != nil {
+		return nil, err
+	}
+	if lowBlockInfo.BlueScore > highBlockInfo.BlueScore {
+		return nil, appmessage.RPCErrorf("The blue score of lowHash %s is higher than that of highHash %s",
+			lowHash, highHash)
+	}
+
+	// maxBlocks MUST be >= MergeSetSizeLimit + 1
+	maxBlocks := uint64(MaxDAGBlocks)
+	if maxBlocks < ctx.Config.NetParams().MergeSetSizeLimit+1 {
+		maxBlocks = ctx.Config.NetParams().MergeSetSizeLimit + 1
+	}
+	blockHashes, actualHighHash, err := consensus.GetHashesBetween(lowHash, highHash, maxBlocks)
+	if err != nil {
+		return nil, err
+	}
+	blockHashes = append([]*externalapi.DomainHash{lowHash}, blockHashes...)
+
+	// The anticone is added only if GetHashesBetween didn't cut the sub-DAG off, so that
+	// the sub-DAG doesn't have holes
+	if isHighHashVirtualSelectedParent && actualHighHash.Equal(highHash) {
+		virtualSelectedParentAnticone, err := consensus.Anticone(highHash)
+		if err != nil {
+			return nil, err
+		}
+		blockHashes = append(blockHashes, virtualSelectedParentAnticone...)
+	}
+	return blockHashes, nil
+}
+
+// buildRPCDAGBlocks builds an RPCDAGBlock for every one of the given blocks. The blocks
+// are colored according to the merge sets of the virtual selected parent chain, which is
+// walked from the chain block below the lowest of them.
+func (ctx *Context) buildRPCDAGBlocks(blockHashes []*externalapi.DomainHash) ([]*appmessage.RPCDAGBlock, error) {
+	consensus := ctx.Domain.Consensus()
+
+	blocks := make([]*appmessage.RPCDAGBlock, len(blockHashes))
+	blocksByHash := make(map[externalapi.DomainHash]*appmessage.RPCDAGBlock, len(blockHashes))
+	minDAAScore := uint64(0)
+	for i, blockHash := range blockHashes {
+		blockHeader, err := consensus.GetBlockHeader(blockHash)
+		if err != nil {
+			return nil, err
+		}
+		blockInfo, err := consensus.GetBlockInfo(blockHash)
+		if err != nil {
+			return nil, err
+		}
+		isChainBlock, err := consensus.IsChainBlock(blockHash)
+		if err != nil {
+			return nil, err
+		}
+
+		block := &appmessage.RPCDAGBlock{
+			Hash:                blockHash.String(),
+			ParentHashes:        hashes.ToStrings(blockHeader.DirectParents()),
+			BlueScore:           blockInfo.BlueScore,
+			DAAScore:            blockHeader.DAAScore(),
+			Timestamp:           blockHeader.TimeInMilliseconds(),
+			IsChainBlock:        isChainBlock,
+			MergeSetBluesHashes: hashes.ToStrings(blockInfo.MergeSetBlues),
+			MergeSetRedsHashes:  hashes.ToStrings(blockInfo.MergeSetReds),
+		}
+		if blockInfo.SelectedParent != nil {
+			block.SelectedParentHash = blockInfo.SelectedParent.String()
+		}
+		if isChainBlock {
+			block.Color = appmessage.RPCDAGBlockColorBlue
+		}
+		blocks[i] = block
+		blocksByHash[*blockHash] = block
+
+		if i == 0 || block.DAAScore < minDAAScore {
+			minDAAScore = block.DAAScore
+		}
+	}
+	if len(blocks) == 0 {
+		return blocks, nil
+	}
+
+	err := ctx.colorRPCDAGBlocks(blocksByHash, minDAAScore)
+	if err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// colorRPCDAGBlocks colors the given blocks by walking up the virtual selected parent chain
+// from the chain block with the given DAA score, until all of them are colored. A block that
+// isn't merged within the merge depth is never merged, so the walk stops there if some blocks
+// are still uncolored.
+func (ctx *Context) colorRPCDAGBlocks(blocksByHash map[externalapi.DomainHash]*appmessage.RPCDAGBlock,
+	minDAAScore uint64) error {
+
+	consensus := ctx.Domain.Consensus()
+
+	uncoloredBlockCount := 0
+	for _, block := range blocksByHash {
+		if block.Color == "" {
+			uncoloredBlockCount++
+		}
+	}
+	colorBlocks := func(blockHashes []*externalapi.DomainHash, color string) {
+		for _, blockHash := range blockHashes {
+			block, ok := blocksByHash[*blockHash]
+			if ok && block.Color == "" {
+				block.Color = color
+				uncoloredBlockCount--
+			}
+		}
+	}
+
+	chainBlock, err := consensus.GetChainBlockByDAAScore(minDAAScore)
+	if err != nil {
+		return err
+	}
+	mergeDepth := ctx.Config.NetParams().MergeDepth
+	chainBlocksPastSubDAG := uint64(0)
+	for uncoloredBlockCount > 0 && chainBlocksPastSubDAG <= mergeDepth {
+		blockInfo, err := consensus.GetBlockInfo(chainBlock)
+		if err != nil {
+			return err
+		}
+		colorBlocks(blockInfo.MergeSetBlues, appmessage.RPCDAGBlockColorBlue)
+		colorBlocks(blockInfo.MergeSetReds, appmessage.RPCDAGBlockColorRed)
+		if _, ok := blocksByHash[*chainBlock]; !ok {
+			chainBlocksPastSubDAG++
+		}
+
+		selectedChild, err := ctx.selectedChild(chainBlock)
+		if err != nil {
+			return err
+		}
+		if selectedChild == nil {
+			break
+		}
+		chainBlock = selectedChild
+	}
+	return nil
+}
+
+// selectedChild returns the child of the given chain block that's in the virtual selected
+// parent chain, or nil if it's the virtual selected parent
+func (ctx *Context) selectedChild(chainBlock *externalapi.DomainHash) (*externalapi.DomainHash, error) {
+	_, children, err := ctx.Domain.Consensus().GetBlockRelations(chainBlock)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		// The virtual is a child of the tips
+		if child.Equal(model.VirtualBlockHash) {
+			continue
+		}
+		isChainBlock, err := ctx.Domain.Consensus().IsChainBlock(child)
+		if err != nil {
+			return nil, err
+		}
+		if isChainBlock {
+			return child, nil
+		}
+	}
+	return nil, nil
+}
+
+// RenderDAGToDOT renders the given sub-DAG in the DOT language of Graphviz. Blocks are
+// filled with their color, chain blocks have a bold border, and the edge of every block
+// to its selected parent is bold.
+func RenderDAGToDOT(blocks []*appmessage.RPCDAGBlock) string {
+	var dotBuilder strings.Builder
+	dotBuilder.WriteString("digraph {\n\trankdir = RL;\n\tnode [style = filled, fontname = monospace];\n\n")
+
+	for _, block := range blocks {
+		fillColor := "lightgray"
+		switch block.Color {
+		case appmessage.RPCDAGBlockColorBlue:
+			fillColor = "lightblue"
+		case appmessage.RPCDAGBlockColorRed:
+			fillColor = "lightpink"
+		}
+		penWidth := 1
+		if block.IsChainBlock {
+			penWidth = 3
+		}
+		fmt.Fprintf(&dotBuilder, "\t\"%s\" [label = \"%s\\nblue score: %d\\nDAA score: %d\", fillcolor = %s, penwidth = %d];\n",
+			block.Hash, shortHash(block.Hash), block.BlueScore, block.DAAScore, fillColor, penWidth)
+	}
+	dotBuilder.WriteString("\n")
+
+	blockHashes := make(map[string]struct{}, len(blocks))
+	for _, block := range blocks {
+		blockHashes[block.Hash] = struct{}{}
+	}
+	for _, block := range blocks {
+		for _, parentHash := range block.ParentHashes {
+			// Parents outside of the sub-DAG are left out
+			if _, ok := blockHashes[parentHash]; !ok {
+				continue
+			}
+			style := "solid"
+			if parentHash == block.SelectedParentHash {
+				style = "bold"
+			}
+			fmt.Fprintf(&dotBuilder, "\t\"%s\" -> \"%s\" [style = %s];\n", block.Hash, parentHash, style)
+		}
+	}
+	dotBuilder.WriteString("}\n")
+
+	return dotBuilder.String()
+}
+
+func shortHash(hash string) string {
+	const shortHashLength = 8
+	if len(hash) <= shortHashLength {
+		return hash
+	}
+	return hash[:shortHashLength]
+}
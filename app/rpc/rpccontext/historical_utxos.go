@@ -0,0 +1,93 @@
+package rpccontext
+
+import (
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/ruleerrors"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
+	"github.com/sedracoin/sedrad/domain/utxoindex"
+	"github.com/sedracoin/sedrad/util"
+	"github.com/pkg/errors"
+)
+
+// ResolveHistoricalChainBlock returns the chain block that is referred to by exactly one of
+// the given blockHash, daaScore and timestamp, alongside its DAA score.
+// A nil daaScore or timestamp is unset, while a zero one is set, so that the genesis can be selected
+func (ctx *Context) ResolveHistoricalChainBlock(blockHashString string, daaScore *uint64, timestamp *int64) (
+	*externalapi.DomainHash, uint64, error) {
+
+	numberOfSetSelectors := 0
+	for _, isSet := range []bool{blockHashString != "", daaScore != nil, timestamp != nil} {
+		if isSet {
+			numberOfSetSelectors++
+		}
+	}
+	if numberOfSetSelectors != 1 {
+		return nil, 0, appmessage.RPCErrorf("Exactly one of blockHash, daaScore and timestamp must be set")
+	}
+
+	var blockHash *externalapi.DomainHash
+	var err error
+	switch {
+	case blockHashString != "":
+		blockHash, err = externalapi.NewDomainHashFromString(blockHashString)
+		if err != nil {
+			return nil, 0, appmessage.RPCErrorf("Could not parse blockHash '%s': %s", blockHashString, err)
+		}
+	case daaScore != nil:
+		blockHash, err = ctx.Domain.Consensus().GetChainBlockByDAAScore(*daaScore)
+		if err != nil {
+			return nil, 0, appmessage.RPCErrorf("Could not find a chain block for DAA score %d: %s", *daaScore, err)
+		}
+	default:
+		blockHash, err = ctx.Domain.Consensus().GetChainBlockByTimestamp(*timestamp)
+		if err != nil {
+			return nil, 0, appmessage.RPCErrorf("Could not find a chain block for timestamp %d: %s", *timestamp, err)
+		}
+	}
+
+	blockHeader, err := ctx.Domain.Consensus().GetBlockHeader(blockHash)
+	if err != nil {
+		return nil, 0, appmessage.RPCErrorf("Block %s not found", blockHash)
+	}
+	isChainBlock, err := ctx.Domain.Consensus().IsChainBlock(blockHash)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !isChainBlock {
+		return nil, 0, appmessage.RPCErrorf("Block %s is not in the virtual selected parent chain", blockHash)
+	}
+	return blockHash, blockHeader.DAAScore(), nil
+}
+
+// UTXOsByAddressesAtChainBlock returns the UTXOs of each of the given addresses as they were
+// right after the given chain block had been accepted
+func (ctx *Context) UTXOsByAddressesAtChainBlock(blockHash *externalapi.DomainHash, addressStrings []string) (
+	[]utxoindex.UTXOOutpointEntryPairs, error) {
+
+	scriptPublicKeys := make([]*externalapi.ScriptPublicKey, len(addressStrings))
+	for i, addressString := range addressStrings {
+		address, err := util.DecodeAddress(addressString, ctx.Config.ActiveNetParams.Prefix)
+		if err != nil {
+			return nil, appmessage.RPCErrorf("Could not decode address '%s': %s", addressString, err)
+		}
+		scriptPublicKeys[i], err = txscript.PayToAddrScript(address)
+		if err != nil {
+			return nil, appmessage.RPCErrorf("Could not create a scriptPublicKey for address '%s': %s", addressString, err)
+		}
+	}
+
+	utxoOutpointEntryPairs, err := ctx.UTXOIndex.UTXOsAtChainBlock(blockHash, scriptPublicKeys)
+	if err != nil {
+		if errors.Is(err, ruleerrors.ErrPrunedBlockUTXOSet) {
+			return nil, appmessage.RPCErrorf("The UTXO set of block %s is unavailable: it is below the pruning "+
+				"point and this node is not running with --archival", blockHash)
+		}
+		ruleError := ruleerrors.RuleError{}
+		if errors.As(err, &ruleError) {
+			return nil, appmessage.RPCErrorf("Could not restore the UTXO set of block %s: %s", blockHash, err)
+		}
+		return nil, err
+	}
+	return utxoOutpointEntryPairs, nil
+}
@@ -81,7 +81,11 @@ func (ctx *Context) PopulateBlockWithVerboseData(block *appmessage.RPCBlock, dom
 		block.VerboseData.SelectedParentHash = blockInfo.SelectedParent.String()
 	}
 
-	if blockInfo.BlockStatus == externalapi.StatusHeaderOnly {
+	// Header-only blocks have no transaction verbose data, unless their
+	// body was fetched from an archival peer
+	if blockInfo.BlockStatus == externalapi.StatusHeaderOnly &&
+		(domainBlock == nil || len(domainBlock.Transactions) == 0) {
+
 		return nil
 	}
 
@@ -0,0 +1,56 @@
+package rpchandlers
+
+import (
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/app/rpc/rpccontext"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/router"
+	"github.com/pkg/errors"
+)
+
+// HandleGetBalancesByAddressesAtBlock handles the respectively named RPC command
+func HandleGetBalancesByAddressesAtBlock(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	if !context.Config.UTXOIndex {
+		errorMessage := &appmessage.GetBalancesByAddressesAtBlockResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Method unavailable when sedrad is run without --utxoindex")
+		return errorMessage, nil
+	}
+
+	getBalancesByAddressesAtBlockRequest := request.(*appmessage.GetBalancesByAddressesAtBlockRequestMessage)
+
+	blockHash, daaScore, err := context.ResolveHistoricalChainBlock(getBalancesByAddressesAtBlockRequest.BlockHash,
+		getBalancesByAddressesAtBlockRequest.DAAScore, getBalancesByAddressesAtBlockRequest.Timestamp)
+	if err != nil {
+		return handleGetBalancesByAddressesAtBlockError(err)
+	}
+
+	utxoOutpointEntryPairsByAddress, err := context.UTXOsByAddressesAtChainBlock(blockHash,
+		getBalancesByAddressesAtBlockRequest.Addresses)
+	if err != nil {
+		return handleGetBalancesByAddressesAtBlockError(err)
+	}
+
+	allEntries := make([]*appmessage.BalancesByAddressesEntry, len(getBalancesByAddressesAtBlockRequest.Addresses))
+	for i, address := range getBalancesByAddressesAtBlockRequest.Addresses {
+		balance := uint64(0)
+		for _, utxoEntry := range utxoOutpointEntryPairsByAddress[i] {
+			balance += utxoEntry.Amount()
+		}
+		allEntries[i] = &appmessage.BalancesByAddressesEntry{
+			Address: address,
+			Balance: balance,
+		}
+	}
+
+	response := appmessage.NewGetBalancesByAddressesAtBlockResponse(blockHash.String(), daaScore, allEntries)
+	return response, nil
+}
+
+func handleGetBalancesByAddressesAtBlockError(err error) (appmessage.Message, error) {
+	rpcError := &appmessage.RPCError{}
+	if !errors.As(err, &rpcError) {
+		return nil, err
+	}
+	errorMessage := &appmessage.GetBalancesByAddressesAtBlockResponseMessage{}
+	errorMessage.Error = rpcError
+	return errorMessage, nil
+}
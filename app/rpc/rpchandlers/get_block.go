@@ -4,6 +4,8 @@ import (
 	"github.com/sedracoin/sedrad/app/appmessage"
 	"github.com/sedracoin/sedrad/app/rpc/rpccontext"
 	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/infrastructure/db/database"
 	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/router"
 	"github.com/pkg/errors"
 )
@@ -27,6 +29,27 @@ func HandleGetBlock(context *rpccontext.Context, _ *router.Router, request appme
 		return errorMessage, nil
 	}
 
+	// Every block body contains at least a coinbase transaction, so a block
+	// without transactions is one whose body is not held by this node
+	var acceptanceData externalapi.AcceptanceData
+	if len(block.Transactions) == 0 && getBlockRequest.FetchFromArchivalPeers {
+		historicalBlock, err := context.ProtocolManager.FetchHistoricalBlock(hash,
+			getBlockRequest.IncludeAcceptedTransactionIDs)
+		if err != nil {
+			errorMessage := &appmessage.GetBlockResponseMessage{}
+			errorMessage.Error = appmessage.RPCErrorf("Block %s could not be fetched from archival peers: %s",
+				hash, err)
+			return errorMessage, nil
+		}
+		block = historicalBlock.Block
+		acceptanceData = historicalBlock.AcceptanceData
+	} else if len(block.Transactions) > 0 && getBlockRequest.IncludeAcceptedTransactionIDs {
+		acceptanceData, err = context.Domain.Consensus().GetBlockAcceptanceData(hash)
+		if err != nil && !database.IsNotFoundError(err) {
+			return nil, err
+		}
+	}
+
 	response := appmessage.NewGetBlockResponseMessage()
 
 	if getBlockRequest.IncludeTransactions {
@@ -45,5 +68,16 @@ func HandleGetBlock(context *rpccontext.Context, _ *router.Router, request appme
 		return nil, err
 	}
 
+	if getBlockRequest.IncludeAcceptedTransactionIDs {
+		for _, blockAcceptanceData := range acceptanceData {
+			for _, transactionAcceptanceData := range blockAcceptanceData.TransactionAcceptanceData {
+				if transactionAcceptanceData.IsAccepted {
+					response.AcceptedTransactionIDs = append(response.AcceptedTransactionIDs,
+						consensushashing.TransactionID(transactionAcceptanceData.Transaction).String())
+				}
+			}
+		}
+	}
+
 	return response, nil
 }
@@ -12,6 +12,16 @@ func HandleGetConnectedPeerInfo(context *rpccontext.Context, _ *router.Router, _
 	ibdPeer := context.ProtocolManager.IBDPeer()
 	infos := make([]*appmessage.GetConnectedPeerInfoMessage, 0, len(peers))
 	for _, peer := range peers {
+		misbehaviorScore, recentViolations := context.ProtocolManager.PeerMisbehavior(peer)
+		rpcRecentViolations := make([]*appmessage.PeerViolation, len(recentViolations))
+		for i, violation := range recentViolations {
+			rpcRecentViolations[i] = &appmessage.PeerViolation{
+				Misbehavior: violation.Misbehavior.String(),
+				Reason:      violation.Reason,
+				Timestamp:   violation.Time.UnixMilli(),
+			}
+		}
+		traffic, _ := peer.Connection().TrafficStats()
 		info := &appmessage.GetConnectedPeerInfoMessage{
 			ID:                        peer.ID().String(),
 			Address:                   peer.Address(),
@@ -22,6 +32,10 @@ func HandleGetConnectedPeerInfo(context *rpccontext.Context, _ *router.Router, _
 			AdvertisedProtocolVersion: peer.AdvertisedProtocolVersion(),
 			TimeConnected:             peer.TimeConnected().Milliseconds(),
 			IsIBDPeer:                 peer == ibdPeer,
+			MisbehaviorScore:          misbehaviorScore,
+			RecentViolations:          rpcRecentViolations,
+			BytesSent:                 traffic.BytesSent,
+			BytesReceived:             traffic.BytesReceived,
 		}
 		infos = append(infos, info)
 	}
@@ -0,0 +1,30 @@
+package rpchandlers
+
+import (
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/app/rpc/rpccontext"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/router"
+	"github.com/pkg/errors"
+)
+
+// HandleGetDAG handles the respectively named RPC command
+func HandleGetDAG(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	getDAGRequest := request.(*appmessage.GetDAGRequestMessage)
+
+	blocks, err := context.DAGBlocks(getDAGRequest.LowHash, getDAGRequest.HighHash, getDAGRequest.LastDAABlockCount)
+	if err != nil {
+		rpcError := &appmessage.RPCError{}
+		if !errors.As(err, &rpcError) {
+			return nil, err
+		}
+		errorMessage := &appmessage.GetDAGResponseMessage{}
+		errorMessage.Error = rpcError
+		return errorMessage, nil
+	}
+
+	dot := ""
+	if getDAGRequest.IncludeDOT {
+		dot = rpccontext.RenderDAGToDOT(blocks)
+	}
+	return appmessage.NewGetDAGResponseMessage(blocks, dot), nil
+}
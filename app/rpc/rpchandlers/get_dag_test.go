@@ -0,0 +1,150 @@
+package rpchandlers_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/app/rpc/rpccontext"
+	"github.com/sedracoin/sedrad/app/rpc/rpchandlers"
+	"github.com/sedracoin/sedrad/domain/consensus"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/testutils"
+	"github.com/sedracoin/sedrad/infrastructure/config"
+)
+
+func TestHandleGetDAG(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		// With K=0, every block in the anticone of a selected parent is red
+		consensusConfig.K = 0
+
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestHandleGetDAG")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		fakeContext := rpccontext.Context{
+			Config: &config.Config{Flags: &config.Flags{NetworkFlags: config.NetworkFlags{ActiveNetParams: &consensusConfig.Params}}},
+			Domain: fakeDomain{tc},
+		}
+
+		getDAG := func(request *appmessage.GetDAGRequestMessage) *appmessage.GetDAGResponseMessage {
+			response, err := rpchandlers.HandleGetDAG(&fakeContext, nil, request)
+			if err != nil {
+				t.Fatalf("HandleGetDAG: %+v", err)
+			}
+			return response.(*appmessage.GetDAGResponseMessage)
+		}
+
+		// Create a DAG with the following structure:
+		//            merging block
+		//            /           \
+		//  blockA <- blockB    blockC
+		//       \              /
+		//           genesis
+		blockA, _, err := tc.AddBlock([]*externalapi.DomainHash{consensusConfig.GenesisHash}, nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+		blockB, _, err := tc.AddBlock([]*externalapi.DomainHash{blockA}, nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+		blockC, _, err := tc.AddBlock([]*externalapi.DomainHash{consensusConfig.GenesisHash}, nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+		mergingBlock, _, err := tc.AddBlock([]*externalapi.DomainHash{blockB, blockC}, nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+
+		response := getDAG(appmessage.NewGetDAGRequestMessage(consensusConfig.GenesisHash.String(), "", 0, true))
+		if response.Error != nil {
+			t.Fatalf("Unexpected error: %s", response.Error.Message)
+		}
+
+		type expectedBlock struct {
+			hash           *externalapi.DomainHash
+			color          string
+			isChainBlock   bool
+			selectedParent *externalapi.DomainHash
+		}
+		expectedBlocks := []expectedBlock{
+			{consensusConfig.GenesisHash, appmessage.RPCDAGBlockColorBlue, true, nil},
+			{blockA, appmessage.RPCDAGBlockColorBlue, true, consensusConfig.GenesisHash},
+			{blockB, appmessage.RPCDAGBlockColorBlue, true, blockA},
+			{blockC, appmessage.RPCDAGBlockColorRed, false, consensusConfig.GenesisHash},
+			{mergingBlock, appmessage.RPCDAGBlockColorBlue, true, blockB},
+		}
+		if len(response.Blocks) != len(expectedBlocks) {
+			t.Fatalf("Expected %d blocks but got %d", len(expectedBlocks), len(response.Blocks))
+		}
+		blocksByHash := make(map[string]*appmessage.RPCDAGBlock)
+		for _, block := range response.Blocks {
+			blocksByHash[block.Hash] = block
+		}
+		for _, expected := range expectedBlocks {
+			block, ok := blocksByHash[expected.hash.String()]
+			if !ok {
+				t.Fatalf("Block %s is missing", expected.hash)
+			}
+			if block.Color != expected.color {
+				t.Errorf("Expected block %s to be %s but it's %s", expected.hash, expected.color, block.Color)
+			}
+			if block.IsChainBlock != expected.isChainBlock {
+				t.Errorf("Expected IsChainBlock of block %s to be %t", expected.hash, expected.isChainBlock)
+			}
+			if expected.selectedParent != nil && block.SelectedParentHash != expected.selectedParent.String() {
+				t.Errorf("Expected the selected parent of block %s to be %s but got %s",
+					expected.hash, expected.selectedParent, block.SelectedParentHash)
+			}
+		}
+		mergingDAGBlock := blocksByHash[mergingBlock.String()]
+		if len(mergingDAGBlock.ParentHashes) != 2 || len(mergingDAGBlock.MergeSetRedsHashes) != 1 ||
+			mergingDAGBlock.MergeSetRedsHashes[0] != blockC.String() {
+			t.Errorf("Unexpected parents or merge set of the merging block: %+v", mergingDAGBlock)
+		}
+
+		if !strings.HasPrefix(response.DOT, "digraph {") ||
+			!strings.Contains(response.DOT, "\""+mergingBlock.String()+"\" -> \""+blockB.String()+"\" [style = bold]") ||
+			!strings.Contains(response.DOT, "\""+mergingBlock.String()+"\" -> \""+blockC.String()+"\" [style = solid]") {
+			t.Errorf("Unexpected DOT graph:\n%s", response.DOT)
+		}
+
+		// highHash cuts the sub-DAG off
+		response = getDAG(appmessage.NewGetDAGRequestMessage(blockA.String(), blockB.String(), 0, false))
+		if response.Error != nil {
+			t.Fatalf("Unexpected error: %s", response.Error.Message)
+		}
+		if len(response.Blocks) != 2 || response.Blocks[0].Hash != blockA.String() ||
+			response.Blocks[1].Hash != blockB.String() || response.DOT != "" {
+			t.Errorf("Unexpected sub-DAG between blockA and blockB: %+v", response)
+		}
+
+		// The DAA score of the virtual is higher by 1 than that of the merging block, which is
+		// higher by 2 than that of blockB, since the merging block merges blockC
+		response = getDAG(appmessage.NewGetDAGRequestMessage("", "", 2, false))
+		if response.Error != nil {
+			t.Fatalf("Unexpected error: %s", response.Error.Message)
+		}
+		if len(response.Blocks) != 1 || response.Blocks[0].Hash != mergingBlock.String() {
+			t.Errorf("Expected the last 2 DAA blocks to be only the merging block, but got %+v", response.Blocks)
+		}
+
+		for _, request := range []*appmessage.GetDAGRequestMessage{
+			appmessage.NewGetDAGRequestMessage("", "", 0, false),
+			appmessage.NewGetDAGRequestMessage(blockA.String(), "", 2, false),
+			appmessage.NewGetDAGRequestMessage("not a hash", "", 0, false),
+			appmessage.NewGetDAGRequestMessage(blockB.String(), blockA.String(), 0, false),
+			appmessage.NewGetDAGRequestMessage("", "", rpccontext.MaxDAGBlocks+1, false),
+		} {
+			response := getDAG(request)
+			if response.Error == nil {
+				t.Errorf("Expected an error for request %+v", request)
+			}
+		}
+	})
+}
@@ -0,0 +1,32 @@
+package rpchandlers
+
+import (
+	"sort"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/app/rpc/rpccontext"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/router"
+)
+
+// HandleGetNetTotals handles the respectively named RPC command
+func HandleGetNetTotals(context *rpccontext.Context, _ *router.Router, _ appmessage.Message) (appmessage.Message, error) {
+	total, byCommand := context.NetAdapter.P2PTrafficStats()
+
+	messageTypeTotals := make([]*appmessage.NetMessageTypeTotals, 0, len(byCommand))
+	for command, commandStats := range byCommand {
+		messageTypeTotals = append(messageTypeTotals, &appmessage.NetMessageTypeTotals{
+			MessageType:      command.String(),
+			BytesSent:        commandStats.BytesSent,
+			BytesReceived:    commandStats.BytesReceived,
+			MessagesSent:     commandStats.MessagesSent,
+			MessagesReceived: commandStats.MessagesReceived,
+		})
+	}
+	sort.Slice(messageTypeTotals, func(i, j int) bool {
+		return messageTypeTotals[i].MessageType < messageTypeTotals[j].MessageType
+	})
+
+	response := appmessage.NewGetNetTotalsResponseMessage(total.BytesSent, total.BytesReceived,
+		context.NetAdapter.StartTime().UnixMilli(), messageTypeTotals)
+	return response, nil
+}
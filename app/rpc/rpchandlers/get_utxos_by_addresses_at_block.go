@@ -0,0 +1,51 @@
+package rpchandlers
+
+import (
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/app/rpc/rpccontext"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/router"
+	"github.com/pkg/errors"
+)
+
+// HandleGetUTXOsByAddressesAtBlock handles the respectively named RPC command
+func HandleGetUTXOsByAddressesAtBlock(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	if !context.Config.UTXOIndex {
+		errorMessage := &appmessage.GetUTXOsByAddressesAtBlockResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Method unavailable when sedrad is run without --utxoindex")
+		return errorMessage, nil
+	}
+
+	getUTXOsByAddressesAtBlockRequest := request.(*appmessage.GetUTXOsByAddressesAtBlockRequestMessage)
+
+	blockHash, daaScore, err := context.ResolveHistoricalChainBlock(getUTXOsByAddressesAtBlockRequest.BlockHash,
+		getUTXOsByAddressesAtBlockRequest.DAAScore, getUTXOsByAddressesAtBlockRequest.Timestamp)
+	if err != nil {
+		return handleGetUTXOsByAddressesAtBlockError(err)
+	}
+
+	utxoOutpointEntryPairsByAddress, err := context.UTXOsByAddressesAtChainBlock(blockHash,
+		getUTXOsByAddressesAtBlockRequest.Addresses)
+	if err != nil {
+		return handleGetUTXOsByAddressesAtBlockError(err)
+	}
+
+	allEntries := make([]*appmessage.UTXOsByAddressesEntry, 0)
+	for i, addressString := range getUTXOsByAddressesAtBlockRequest.Addresses {
+		entries := rpccontext.ConvertUTXOOutpointEntryPairsToUTXOsByAddressesEntries(addressString,
+			utxoOutpointEntryPairsByAddress[i])
+		allEntries = append(allEntries, entries...)
+	}
+
+	response := appmessage.NewGetUTXOsByAddressesAtBlockResponseMessage(blockHash.String(), daaScore, allEntries)
+	return response, nil
+}
+
+func handleGetUTXOsByAddressesAtBlockError(err error) (appmessage.Message, error) {
+	rpcError := &appmessage.RPCError{}
+	if !errors.As(err, &rpcError) {
+		return nil, err
+	}
+	errorMessage := &appmessage.GetUTXOsByAddressesAtBlockResponseMessage{}
+	errorMessage.Error = rpcError
+	return errorMessage, nil
+}
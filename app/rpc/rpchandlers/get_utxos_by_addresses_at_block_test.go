@@ -0,0 +1,149 @@
+package rpchandlers_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/app/rpc/rpccontext"
+	"github.com/sedracoin/sedrad/app/rpc/rpchandlers"
+	"github.com/sedracoin/sedrad/domain/consensus"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/testutils"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
+	"github.com/sedracoin/sedrad/domain/utxoindex"
+	"github.com/sedracoin/sedrad/infrastructure/config"
+	"github.com/sedracoin/sedrad/infrastructure/db/database/ldb"
+)
+
+func TestHandleGetUTXOsByAddressesAtBlock(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		// This is done to reduce the pruning depth to 6 blocks and to have no
+		// blocks kept below the pruning point
+		consensusConfig.FinalityDuration = 2 * consensusConfig.TargetTimePerBlock
+		consensusConfig.K = 0
+		consensusConfig.DifficultyAdjustmentWindowSize = 0
+
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestHandleGetUTXOsByAddressesAtBlock")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		db, err := ldb.NewLevelDB(t.TempDir(), 8)
+		if err != nil {
+			t.Fatalf("NewLevelDB: %+v", err)
+		}
+		defer db.Close()
+
+		utxoIndex, err := utxoindex.New(fakeDomain{tc}, db)
+		if err != nil {
+			t.Fatalf("utxoindex.New: %+v", err)
+		}
+
+		fakeContext := rpccontext.Context{
+			Config: &config.Config{Flags: &config.Flags{
+				NetworkFlags: config.NetworkFlags{ActiveNetParams: &consensusConfig.Params},
+				UTXOIndex:    true,
+			}},
+			Domain:    fakeDomain{tc},
+			UTXOIndex: utxoIndex,
+		}
+
+		scriptPublicKey, _ := testutils.OpTrueScript()
+		_, address, err := txscript.ExtractScriptPubKeyAddress(scriptPublicKey, &consensusConfig.Params)
+		if err != nil {
+			t.Fatalf("ExtractScriptPubKeyAddress: %+v", err)
+		}
+		coinbaseData := &externalapi.DomainCoinbaseData{ScriptPublicKey: scriptPublicKey, ExtraData: []byte{}}
+		chain := []*externalapi.DomainHash{consensusConfig.GenesisHash}
+		addBlock := func() {
+			blockHash, virtualChangeSet, err := tc.AddBlock([]*externalapi.DomainHash{chain[len(chain)-1]}, coinbaseData, nil)
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+			_, err = utxoIndex.Update(virtualChangeSet)
+			if err != nil {
+				t.Fatalf("Update: %+v", err)
+			}
+			chain = append(chain, blockHash)
+		}
+
+		getUTXOsAtBlock := func(blockHash string, daaScore *uint64, timestamp *int64) *appmessage.GetUTXOsByAddressesAtBlockResponseMessage {
+			request := appmessage.NewGetUTXOsByAddressesAtBlockRequestMessage(
+				[]string{address.String()}, blockHash, daaScore, timestamp)
+			response, err := rpchandlers.HandleGetUTXOsByAddressesAtBlock(&fakeContext, nil, request)
+			if err != nil {
+				t.Fatalf("HandleGetUTXOsByAddressesAtBlock: %+v", err)
+			}
+			return response.(*appmessage.GetUTXOsByAddressesAtBlockResponseMessage)
+		}
+
+		for i := 0; i < 3; i++ {
+			addBlock()
+		}
+
+		// Zero selectors are set selectors, so the genesis can be selected
+		zeroDAAScore := uint64(0)
+		response := getUTXOsAtBlock("", &zeroDAAScore, nil)
+		if response.Error != nil {
+			t.Fatalf("Unexpected error for DAA score 0: %s", response.Error.Message)
+		}
+		if response.DAAScore != 0 {
+			t.Fatalf("Expected a block with DAA score 0 but got %d", response.DAAScore)
+		}
+		genesisHeader, err := tc.GetBlockHeader(consensusConfig.GenesisHash)
+		if err != nil {
+			t.Fatalf("GetBlockHeader: %+v", err)
+		}
+		genesisTimestamp := genesisHeader.TimeInMilliseconds()
+		response = getUTXOsAtBlock("", nil, &genesisTimestamp)
+		if response.Error != nil {
+			t.Fatalf("Unexpected error for the genesis timestamp: %s", response.Error.Message)
+		}
+		if response.BlockHash != consensusConfig.GenesisHash.String() {
+			t.Fatalf("Expected the genesis %s to be selected but got %s", consensusConfig.GenesisHash, response.BlockHash)
+		}
+
+		tipHash := chain[len(chain)-1]
+		response = getUTXOsAtBlock(tipHash.String(), nil, nil)
+		if response.Error != nil {
+			t.Fatalf("Unexpected error for block %s: %s", tipHash, response.Error.Message)
+		}
+		if response.BlockHash != tipHash.String() {
+			t.Fatalf("Expected block %s to be selected but got %s", tipHash, response.BlockHash)
+		}
+
+		for _, selectors := range []struct {
+			blockHash string
+			daaScore  *uint64
+			timestamp *int64
+		}{
+			{},
+			{blockHash: tipHash.String(), daaScore: &zeroDAAScore},
+			{daaScore: &zeroDAAScore, timestamp: &genesisTimestamp},
+		} {
+			response = getUTXOsAtBlock(selectors.blockHash, selectors.daaScore, selectors.timestamp)
+			if response.Error == nil || !strings.Contains(response.Error.Message, "Exactly one") {
+				t.Fatalf("Expected the request with selectors %+v to be rejected", selectors)
+			}
+		}
+
+		// Add blocks until the UTXO data of the first block above the genesis is pruned
+		for {
+			addBlock()
+			pruningPoint, err := tc.PruningPoint()
+			if err != nil {
+				t.Fatalf("PruningPoint: %+v", err)
+			}
+			if !pruningPoint.Equal(consensusConfig.GenesisHash) && !pruningPoint.Equal(chain[1]) {
+				break
+			}
+		}
+		response = getUTXOsAtBlock(chain[1].String(), nil, nil)
+		if response.Error == nil || !strings.Contains(response.Error.Message, "--archival") {
+			t.Fatalf("Expected the request for the pruned block %s to be rejected", chain[1])
+		}
+	})
+}
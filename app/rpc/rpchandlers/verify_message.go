@@ -0,0 +1,36 @@
+package rpchandlers
+
+import (
+	"encoding/hex"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/app/rpc/rpccontext"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/router"
+	"github.com/sedracoin/sedrad/util"
+	"github.com/sedracoin/sedrad/util/messagesigning"
+)
+
+// HandleVerifyMessage handles the respectively named RPC command
+func HandleVerifyMessage(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	verifyMessageRequest := request.(*appmessage.VerifyMessageRequestMessage)
+
+	address, err := util.DecodeAddress(verifyMessageRequest.Address, context.Config.ActiveNetParams.Prefix)
+	if err != nil {
+		errorMessage := &appmessage.VerifyMessageResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Couldn't decode address '%s': %s", verifyMessageRequest.Address, err)
+		return errorMessage, nil
+	}
+
+	signature, err := hex.DecodeString(verifyMessageRequest.Signature)
+	if err != nil {
+		errorMessage := &appmessage.VerifyMessageResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Couldn't decode signature: %s", err)
+		return errorMessage, nil
+	}
+
+	err = messagesigning.Verify(address, []byte(verifyMessageRequest.Message), signature)
+	if err != nil {
+		return appmessage.NewVerifyMessageResponseMessage(false, err.Error()), nil
+	}
+	return appmessage.NewVerifyMessageResponseMessage(true, ""), nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/pkg/errors"
+	"github.com/sedracoin/sedrad/version"
+)
+
+const defaultThreshold = 10.0
+
+type configFlags struct {
+	ShowVersion bool    `short:"V" long:"version" description:"Display version information and exit"`
+	Threshold   float64 `short:"t" long:"threshold" description:"Percentage by which a metric has to grow to be flagged as a regression"`
+	Positional  struct {
+		Old string `positional-arg-name:"old" description:"Results file of the baseline"`
+		New string `positional-arg-name:"new" description:"Results file to compare with the baseline"`
+	} `positional-args:"yes"`
+}
+
+func parseConfig() (*configFlags, error) {
+	cfg := &configFlags{
+		Threshold: defaultThreshold,
+	}
+	parser := flags.NewParser(cfg, flags.PrintErrors|flags.HelpFlag)
+	parser.Usage = "[OPTIONS] old new"
+	_, err := parser.Parse()
+
+	// Show the version and exit if the version flag was specified.
+	if cfg.ShowVersion {
+		appName := filepath.Base(os.Args[0])
+		appName = strings.TrimSuffix(appName, filepath.Ext(appName))
+		fmt.Println(appName, "version", version.Version())
+		os.Exit(0)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Positional.Old == "" || cfg.Positional.New == "" {
+		return nil, errors.New("Both the old and the new results files must be given")
+	}
+	if cfg.Threshold <= 0 {
+		return nil, errors.New("--threshold must be positive")
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+)
+
+func main() {
+	cfg, err := parseConfig()
+	if err != nil {
+		printErrorAndExit(errors.Errorf("Error parsing command-line arguments: %s", err))
+	}
+
+	oldResults, err := readResults(cfg.Positional.Old)
+	if err != nil {
+		printErrorAndExit(err)
+	}
+	newResults, err := readResults(cfg.Positional.New)
+	if err != nil {
+		printErrorAndExit(err)
+	}
+
+	regressions := compare(os.Stdout, oldResults, newResults, cfg.Threshold)
+	if len(regressions) > 0 {
+		fmt.Fprintf(os.Stderr, "\n%d regressions above %.1f%%:\n", len(regressions), cfg.Threshold)
+		for _, regression := range regressions {
+			fmt.Fprintf(os.Stderr, "  %s\n", regression)
+		}
+		os.Exit(1)
+	}
+}
+
+// compare writes a table of the metrics of the benchmarks that appear in both results, and
+// returns descriptions of the metrics that grew by more than threshold percent
+func compare(writer io.Writer, oldResults, newResults *results, threshold float64) []string {
+	var regressions []string
+
+	tabWriter := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tabWriter, "benchmark\tmetric\told\tnew\tdelta\t")
+	for _, name := range newResults.names {
+		oldResult, ok := oldResults.byName[name]
+		if !ok {
+			continue
+		}
+		newResult := newResults.byName[name]
+		for _, metric := range metrics {
+			oldValue, oldOK := oldResult.values[metric]
+			newValue, newOK := newResult.values[metric]
+			if !oldOK || !newOK {
+				continue
+			}
+			delta := percentageDelta(oldValue, newValue)
+			mark := ""
+			if delta > threshold {
+				mark = " !"
+				regressions = append(regressions, fmt.Sprintf("%s %s: %s -> %s (%+.1f%%)",
+					name, metric, formatValue(oldValue), formatValue(newValue), delta))
+			}
+			fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\t%+.1f%%%s\t\n",
+				name, metric, formatValue(oldValue), formatValue(newValue), delta, mark)
+		}
+	}
+	tabWriter.Flush()
+
+	for _, name := range oldResults.names {
+		if _, ok := newResults.byName[name]; !ok {
+			fmt.Fprintf(writer, "%s is missing from the new results\n", name)
+		}
+	}
+	for _, name := range newResults.names {
+		if _, ok := oldResults.byName[name]; !ok {
+			fmt.Fprintf(writer, "%s is missing from the old results\n", name)
+		}
+	}
+
+	return regressions
+}
+
+// percentageDelta returns by how many percent newValue is bigger than oldValue
+func percentageDelta(oldValue, newValue float64) float64 {
+	if oldValue == 0 {
+		if newValue == 0 {
+			return 0
+		}
+		return 100
+	}
+	return (newValue - oldValue) / oldValue * 100
+}
+
+// formatValue formats a metric, which is an average, with the precision that matters for its magnitude
+func formatValue(value float64) string {
+	if value >= 100 {
+		return fmt.Sprintf("%.0f", value)
+	}
+	return fmt.Sprintf("%.2f", value)
+}
+
+func printErrorAndExit(err error) {
+	fmt.Fprintf(os.Stderr, "%+v\n", err)
+	os.Exit(1)
+}
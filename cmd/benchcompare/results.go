@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// metrics are the metrics benchcompare compares, in the order they're printed
+var metrics = []string{"ns/op", "B/op", "allocs/op"}
+
+// result is the result of one benchmark, averaged over all the times it ran
+type result struct {
+	name   string
+	values map[string]float64
+}
+
+// results are the results of a benchmark run, in the order the benchmarks first appear
+type results struct {
+	names  []string
+	byName map[string]*result
+}
+
+// readResults reads the results in the `go test -bench` output file at the given path
+func readResults(path string) (*results, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	parsed, err := parseResults(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing %s", path)
+	}
+	return parsed, nil
+}
+
+// parseResults parses `go test -bench` output. Lines that aren't benchmark results are
+// skipped, and the results of a benchmark that ran several times, e.g. with -count, are
+// averaged.
+func parseResults(reader io.Reader) (*results, error) {
+	sums := make(map[string]map[string]float64)
+	counts := make(map[string]map[string]int)
+	var names []string
+
+	scanner := bufio.NewScanner(reader)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		fields := strings.Fields(scanner.Text())
+		// A result line is the name, the number of iterations and pairs of a value and its unit
+		if len(fields) < 4 || len(fields)%2 != 0 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+		if _, err := strconv.ParseUint(fields[1], 10, 64); err != nil {
+			continue
+		}
+
+		name := trimProcs(fields[0])
+		if _, ok := sums[name]; !ok {
+			names = append(names, name)
+			sums[name] = make(map[string]float64)
+			counts[name] = make(map[string]int)
+		}
+		for i := 2; i < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid value %s in line %d", fields[i], lineNumber)
+			}
+			unit := fields[i+1]
+			sums[name][unit] += value
+			counts[name][unit]++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	parsed := &results{names: names, byName: make(map[string]*result, len(names))}
+	for _, name := range names {
+		values := make(map[string]float64, len(sums[name]))
+		for unit, sum := range sums[name] {
+			values[unit] = sum / float64(counts[name][unit])
+		}
+		parsed.byName[name] = &result{name: name, values: values}
+	}
+	return parsed, nil
+}
+
+// trimProcs trims the -GOMAXPROCS suffix `go test` appends to benchmark names, so that
+// results of machines with a different number of CPUs can be compared
+func trimProcs(name string) string {
+	dashIndex := strings.LastIndexByte(name, '-')
+	if dashIndex == -1 {
+		return name
+	}
+	if _, err := strconv.Atoi(name[dashIndex+1:]); err != nil {
+		return name
+	}
+	return name[:dashIndex]
+}
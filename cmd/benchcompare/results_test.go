@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const oldOutput = `goos: linux
+goarch: amd64
+pkg: github.com/sedracoin/sedrad/domain/consensus/benchmarks
+BenchmarkGHOSTDAG/Deep-8         	  268915	      4000 ns/op	    1000 B/op	      10 allocs/op
+BenchmarkGHOSTDAG/Deep-8         	  268915	      6000 ns/op	    1000 B/op	      10 allocs/op
+BenchmarkHeavyHash-8             	   95521	     12562 ns/op	    1760 B/op	      23 allocs/op
+BenchmarkRemoved-8               	     100	       100 ns/op
+PASS
+ok  	github.com/sedracoin/sedrad/domain/consensus/benchmarks	2.036s
+`
+
+const newOutput = `BenchmarkGHOSTDAG/Deep-4         	  268915	      5050 ns/op	    1200 B/op	      10 allocs/op
+BenchmarkHeavyHash-4             	   95521	     12000 ns/op	    1760 B/op	      23 allocs/op
+BenchmarkAdded-4                 	     100	       100 ns/op
+`
+
+func TestParseResults(t *testing.T) {
+	parsed, err := parseResults(strings.NewReader(oldOutput))
+	if err != nil {
+		t.Fatalf("parseResults: %+v", err)
+	}
+	expectedNames := []string{"BenchmarkGHOSTDAG/Deep", "BenchmarkHeavyHash", "BenchmarkRemoved"}
+	if strings.Join(parsed.names, ",") != strings.Join(expectedNames, ",") {
+		t.Fatalf("Expected names %v, but got %v", expectedNames, parsed.names)
+	}
+	ghostdag := parsed.byName["BenchmarkGHOSTDAG/Deep"].values
+	if ghostdag["ns/op"] != 5000 || ghostdag["B/op"] != 1000 || ghostdag["allocs/op"] != 10 {
+		t.Fatalf("Expected the runs of BenchmarkGHOSTDAG/Deep to be averaged, but got %v", ghostdag)
+	}
+	if _, ok := parsed.byName["BenchmarkRemoved"].values["B/op"]; ok {
+		t.Fatalf("Expected BenchmarkRemoved not to have B/op")
+	}
+
+	_, err = parseResults(strings.NewReader("BenchmarkBroken-8 100 abc ns/op\n"))
+	if err == nil {
+		t.Fatalf("Expected an error for an invalid value")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	oldResults, err := parseResults(strings.NewReader(oldOutput))
+	if err != nil {
+		t.Fatalf("parseResults: %+v", err)
+	}
+	newResults, err := parseResults(strings.NewReader(newOutput))
+	if err != nil {
+		t.Fatalf("parseResults: %+v", err)
+	}
+
+	output := &bytes.Buffer{}
+	regressions := compare(output, oldResults, newResults, 10)
+	// ns/op of BenchmarkGHOSTDAG/Deep grew by 1%, which is below the threshold
+	if len(regressions) != 1 || !strings.HasPrefix(regressions[0], "BenchmarkGHOSTDAG/Deep B/op") {
+		t.Fatalf("Expected only B/op of BenchmarkGHOSTDAG/Deep to regress, but got %v", regressions)
+	}
+	if !strings.Contains(output.String(), "BenchmarkRemoved is missing from the new results") ||
+		!strings.Contains(output.String(), "BenchmarkAdded is missing from the old results") {
+		t.Fatalf("Expected the output to report the missing benchmarks, but got:\n%s", output)
+	}
+}
@@ -11,6 +11,7 @@ import (
 var commandTypes = []reflect.Type{
 	reflect.TypeOf(protowire.SedradMessage_AddPeerRequest{}),
 	reflect.TypeOf(protowire.SedradMessage_GetConnectedPeerInfoRequest{}),
+	reflect.TypeOf(protowire.SedradMessage_GetNetTotalsRequest{}),
 	reflect.TypeOf(protowire.SedradMessage_GetPeerAddressesRequest{}),
 	reflect.TypeOf(protowire.SedradMessage_GetCurrentNetworkRequest{}),
 	reflect.TypeOf(protowire.SedradMessage_GetInfoRequest{}),
@@ -25,6 +26,7 @@ var commandTypes = []reflect.Type{
 	reflect.TypeOf(protowire.SedradMessage_GetVirtualSelectedParentChainFromBlockRequest{}),
 	reflect.TypeOf(protowire.SedradMessage_ResolveFinalityConflictRequest{}),
 	reflect.TypeOf(protowire.SedradMessage_EstimateNetworkHashesPerSecondRequest{}),
+	reflect.TypeOf(protowire.SedradMessage_GetDagRequest{}),
 
 	reflect.TypeOf(protowire.SedradMessage_GetBlockTemplateRequest{}),
 	reflect.TypeOf(protowire.SedradMessage_SubmitBlockRequest{}),
@@ -37,7 +39,10 @@ var commandTypes = []reflect.Type{
 
 	reflect.TypeOf(protowire.SedradMessage_GetUtxosByAddressesRequest{}),
 	reflect.TypeOf(protowire.SedradMessage_GetBalanceByAddressRequest{}),
+	reflect.TypeOf(protowire.SedradMessage_GetUtxosByAddressesAtBlockRequest{}),
+	reflect.TypeOf(protowire.SedradMessage_GetBalancesByAddressesAtBlockRequest{}),
 	reflect.TypeOf(protowire.SedradMessage_GetCoinSupplyRequest{}),
+	reflect.TypeOf(protowire.SedradMessage_VerifyMessageRequest{}),
 
 	reflect.TypeOf(protowire.SedradMessage_BanRequest{}),
 	reflect.TypeOf(protowire.SedradMessage_UnbanRequest{}),
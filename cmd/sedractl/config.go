@@ -17,6 +17,7 @@ type configFlags struct {
 	RequestJSON                        string `short:"j" long:"json" description:"The request in JSON format"`
 	ListCommands                       bool   `short:"l" long:"list-commands" description:"List all commands and exit"`
 	AllowConnectionToDifferentVersions bool   `short:"a" long:"allow-connection-to-different-versions" description:"Allow connections to versions different than sedractl's version'"`
+	PrintDOT                           bool   `long:"dot" description:"Print the DOT graph of a GetDag response instead of the whole response"`
 	CommandAndParameters               []string
 	config.NetworkFlags
 }
@@ -59,6 +59,10 @@ func main() {
 	timeout := time.Duration(cfg.Timeout) * time.Second
 	select {
 	case responseString := <-responseChan:
+		if cfg.PrintDOT {
+			fmt.Print(dotFromResponse(responseString))
+			return
+		}
 		prettyResponseString := prettifyResponse(responseString)
 		fmt.Println(prettyResponseString)
 	case <-time.After(timeout):
@@ -79,6 +83,14 @@ func postCommand(cfg *configFlags, client *grpcclient.GRPCClient, responseChan c
 		printErrorAndExit(fmt.Sprintf("error parsing command: %s", err))
 	}
 
+	if cfg.PrintDOT {
+		getDAGRequest := message.GetGetDagRequest()
+		if getDAGRequest == nil {
+			printErrorAndExit("--dot can only be used with GetDag")
+		}
+		getDAGRequest.IncludeDot = true
+	}
+
 	response, err := client.Post(message)
 	if err != nil {
 		printErrorAndExit(fmt.Sprintf("error posting the request to the RPC server: %s", err))
@@ -112,6 +124,26 @@ func prettifyResponse(response string) string {
 	return marshalOptions.Format(sedradMessage)
 }
 
+func dotFromResponse(response string) string {
+	sedradMessage := &protowire.SedradMessage{}
+	err := protojson.Unmarshal([]byte(response), sedradMessage)
+	if err != nil {
+		printErrorAndExit(fmt.Sprintf("error parsing the response from the RPC server: %s", err))
+	}
+
+	getDAGResponse := sedradMessage.GetGetDagResponse()
+	if getDAGResponse == nil {
+		printErrorAndExit("--dot can only be used with GetDag")
+	}
+	if getDAGResponse.Error != nil {
+		printErrorAndExit(fmt.Sprintf("error from the RPC server: %s", getDAGResponse.Error.Message))
+	}
+	if getDAGResponse.Dot == "" {
+		printErrorAndExit("the response doesn't contain a DOT graph. Set includeDot in the request")
+	}
+	return getDAGResponse.Dot
+}
+
 func printErrorAndExit(message string) {
 	fmt.Fprintf(os.Stderr, fmt.Sprintf("%s\n", message))
 	os.Exit(1)
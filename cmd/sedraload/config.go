@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
+	"github.com/sedracoin/sedrad/infrastructure/config"
+
+	"github.com/sedracoin/sedrad/util"
+	"github.com/pkg/errors"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/sedracoin/sedrad/version"
+)
+
+const (
+	defaultLogFilename    = "sedraload.log"
+	defaultErrLogFilename = "sedraload_err.log"
+	defaultTPS            = 10
+	defaultUTXOs          = 1000
+	defaultInputs         = 1
+	defaultOutputs        = 2
+	defaultReportInterval = 10 * time.Second
+)
+
+var (
+	// Default configuration options
+	defaultAppDir     = util.AppDir("sedraload", false)
+	defaultLogFile    = filepath.Join(defaultAppDir, defaultLogFilename)
+	defaultErrLogFile = filepath.Join(defaultAppDir, defaultErrLogFilename)
+	defaultRPCServer  = "localhost"
+)
+
+type configFlags struct {
+	ShowVersion     bool          `short:"V" long:"version" description:"Display version information and exit"`
+	RPCServer       string        `short:"s" long:"rpcserver" description:"RPC server to connect to. The node must run with --utxoindex"`
+	PrivateKey      string        `long:"private-key" description:"Hex encoded Schnorr private key of the funding address. If omitted, a new key is generated and printed"`
+	TPS             float64       `long:"tps" description:"Number of transactions to submit per second"`
+	UTXOs           int           `long:"utxos" description:"Number of UTXOs to fan the funds out to before submitting load"`
+	Inputs          int           `long:"inputs" description:"Number of inputs of every transaction"`
+	Outputs         int           `long:"outputs" description:"Number of outputs of every transaction"`
+	Multisig        string        `long:"multisig" description:"Spend and create m-of-n multisig outputs instead of pay-to-pubkey ones, e.g. 2-of-3"`
+	ChainedChildren int           `long:"chained-children" description:"Number of transactions chained after every transaction, each spending the outputs of the previous one while it's still in the mempool"`
+	FeeRate         uint64        `long:"fee-rate" description:"Fee rate in seep per 1000 grams of mass. If omitted, the minimum relay fee rate of the node is used"`
+	Duration        time.Duration `long:"duration" description:"How long to submit transactions, e.g. 10m. If omitted, transactions are submitted until the process is interrupted"`
+	ReportInterval  time.Duration `long:"report-interval" description:"How often to report statistics"`
+	Profile         string        `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
+	config.NetworkFlags
+
+	minimumSignatures int
+	publicKeyCount    int
+}
+
+func parseConfig() (*configFlags, error) {
+	cfg := &configFlags{
+		RPCServer:      defaultRPCServer,
+		TPS:            defaultTPS,
+		UTXOs:          defaultUTXOs,
+		Inputs:         defaultInputs,
+		Outputs:        defaultOutputs,
+		ReportInterval: defaultReportInterval,
+	}
+	parser := flags.NewParser(cfg, flags.PrintErrors|flags.HelpFlag)
+	_, err := parser.Parse()
+
+	// Show the version and exit if the version flag was specified.
+	if cfg.ShowVersion {
+		appName := filepath.Base(os.Args[0])
+		appName = strings.TrimSuffix(appName, filepath.Ext(appName))
+		fmt.Println(appName, "version", version.Version())
+		os.Exit(0)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = cfg.ResolveNetwork(parser)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Profile != "" {
+		profilePort, err := strconv.Atoi(cfg.Profile)
+		if err != nil || profilePort < 1024 || profilePort > 65535 {
+			return nil, errors.New("The profile port must be between 1024 and 65535")
+		}
+	}
+
+	if cfg.TPS <= 0 {
+		return nil, errors.New("--tps must be positive")
+	}
+	if cfg.UTXOs < 1 {
+		return nil, errors.New("--utxos must be at least 1")
+	}
+	if cfg.Inputs < 1 || cfg.Outputs < 1 {
+		return nil, errors.New("--inputs and --outputs must be at least 1")
+	}
+	if cfg.ChainedChildren < 0 {
+		return nil, errors.New("--chained-children can't be negative")
+	}
+	if cfg.ReportInterval <= 0 {
+		return nil, errors.New("--report-interval must be positive")
+	}
+
+	if cfg.Multisig != "" {
+		cfg.minimumSignatures, cfg.publicKeyCount, err = parseMultisig(cfg.Multisig)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	initLog(defaultLogFile, defaultErrLogFile)
+
+	return cfg, nil
+}
+
+// parseMultisig parses a multisig shape in the form of m-of-n
+func parseMultisig(multisig string) (minimumSignatures int, publicKeyCount int, err error) {
+	parts := strings.Split(multisig, "-of-")
+	if len(parts) != 2 {
+		return 0, 0, errors.Errorf("--multisig must be in the form of m-of-n, e.g. 2-of-3, but got %s", multisig)
+	}
+	minimumSignatures, err = strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "invalid minimum signatures in --multisig %s", multisig)
+	}
+	publicKeyCount, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "invalid number of public keys in --multisig %s", multisig)
+	}
+	if minimumSignatures < 1 || minimumSignatures > publicKeyCount || publicKeyCount > txscript.MaxPubKeysPerMultiSig {
+		return 0, 0, errors.Errorf("--multisig must satisfy 1 <= m <= n <= %d, but got %s", txscript.MaxPubKeysPerMultiSig, multisig)
+	}
+	return minimumSignatures, publicKeyCount, nil
+}
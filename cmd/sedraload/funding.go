@@ -0,0 +1,197 @@
+package main
+
+import (
+	"sort"
+	"time"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxFanOutOutputs is the maximum number of outputs of a fan-out transaction
+	maxFanOutOutputs = 100
+
+	// fundingPollInterval is how often the funding address is checked for spendable UTXOs
+	fundingPollInterval = 10 * time.Second
+
+	// pendingPollInterval is how often the fan-out transactions are checked for acceptance
+	pendingPollInterval = 100 * time.Millisecond
+)
+
+// fund waits for spendable UTXOs at the funding address, and then fans them out to the
+// configured number of UTXOs, which are moved to the pool. It returns early without an
+// error if quit is closed.
+func (g *generator) fund(quit <-chan struct{}) error {
+	log.Infof("Funding address: %s", g.keys.fundingAddress)
+	for {
+		outputs, err := g.spendableOutputs()
+		if err != nil {
+			return err
+		}
+		if len(outputs) > 0 {
+			return g.fanOut(outputs, quit)
+		}
+
+		log.Infof("Waiting for spendable UTXOs at %s. Mine to it with `sedraminer --miningaddr %s`",
+			g.keys.fundingAddress, g.keys.fundingAddress)
+		select {
+		case <-quit:
+			return nil
+		case <-time.After(fundingPollInterval):
+		}
+	}
+}
+
+// spendableOutputs returns the UTXOs of the funding and load addresses, excluding immature coinbase outputs
+func (g *generator) spendableOutputs() ([]*spendableOutput, error) {
+	addresses := []string{g.keys.fundingAddress.String()}
+	if g.keys.loadAddress.String() != g.keys.fundingAddress.String() {
+		addresses = append(addresses, g.keys.loadAddress.String())
+	}
+	getUTXOsByAddressesResponse, err := g.client.GetUTXOsByAddresses(addresses)
+	if err != nil {
+		return nil, err
+	}
+	getBlockDAGInfoResponse, err := g.client.GetBlockDAGInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	coinbaseMaturity := g.cfg.NetParams().BlockCoinbaseMaturity
+	outputs := make([]*spendableOutput, 0, len(getUTXOsByAddressesResponse.Entries))
+	for _, entry := range getUTXOsByAddressesResponse.Entries {
+		outpoint, err := appmessage.RPCOutpointToDomainOutpoint(entry.Outpoint)
+		if err != nil {
+			return nil, err
+		}
+		utxoEntry, err := appmessage.RPCUTXOEntryToUTXOEntry(entry.UTXOEntry)
+		if err != nil {
+			return nil, err
+		}
+		if utxoEntry.IsCoinbase() && utxoEntry.BlockDAAScore()+coinbaseMaturity > getBlockDAGInfoResponse.VirtualDAAScore {
+			continue
+		}
+		outputs = append(outputs, &spendableOutput{outpoint: *outpoint, entry: utxoEntry})
+	}
+	return outputs, nil
+}
+
+// fanOut splits the given outputs in rounds until there are enough outputs that pay to the
+// load address. Every round waits for its transactions to be accepted before the next one
+// splits their outputs further.
+func (g *generator) fanOut(outputs []*spendableOutput, quit <-chan struct{}) error {
+	for round := 1; ; round++ {
+		loadCount := 0
+		for _, output := range outputs {
+			if output.entry.ScriptPublicKey().Equal(g.keys.loadScriptPublicKey) {
+				loadCount++
+			}
+		}
+
+		// Split the largest outputs first, so that the UTXOs have similar values
+		sort.Slice(outputs, func(i, j int) bool { return outputs[i].entry.Amount() > outputs[j].entry.Amount() })
+		var loadOutputs []*spendableOutput
+		submittedCount := 0
+		for _, output := range outputs {
+			isLoadOutput := output.entry.ScriptPublicKey().Equal(g.keys.loadScriptPublicKey)
+			if loadCount >= g.cfg.UTXOs {
+				if isLoadOutput {
+					loadOutputs = append(loadOutputs, output)
+				}
+				continue
+			}
+
+			// An output of the funding address is split into at least one load output, while
+			// an output of the load address is only worth splitting into more than one
+			outputCount := g.cfg.UTXOs - loadCount
+			minimumOutputCount := 1
+			if isLoadOutput {
+				outputCount++
+				minimumOutputCount = 2
+			}
+			if outputCount > maxFanOutOutputs {
+				outputCount = maxFanOutOutputs
+			}
+			created, err := g.submitFanOutTransaction(output, outputCount, minimumOutputCount)
+			if err != nil {
+				return err
+			}
+			if created == 0 {
+				if isLoadOutput {
+					loadOutputs = append(loadOutputs, output)
+				}
+				continue
+			}
+			loadCount += created
+			if isLoadOutput {
+				loadCount--
+			}
+			submittedCount++
+		}
+
+		if submittedCount == 0 {
+			if loadCount < g.cfg.UTXOs {
+				log.Warnf("The funds are only enough for %d out of %d UTXOs", loadCount, g.cfg.UTXOs)
+			}
+			g.lock.Lock()
+			g.pool = append(g.pool, loadOutputs...)
+			g.lock.Unlock()
+			log.Infof("Funded %d UTXOs at %s", len(g.pool), g.keys.loadAddress)
+			return nil
+		}
+
+		log.Infof("Fan-out round %d: waiting for %d transactions to be accepted", round, submittedCount)
+		if !g.waitForPendingTransactions(quit) {
+			return nil
+		}
+		g.lock.Lock()
+		outputs = append(loadOutputs, g.pool...)
+		g.pool = nil
+		g.lock.Unlock()
+	}
+}
+
+// submitFanOutTransaction splits the given output into up to outputCount outputs of the
+// load address, as many as its value allows. It returns the number of created outputs,
+// which is 0 if the output is too small to be split into minimumOutputCount outputs.
+func (g *generator) submitFanOutTransaction(output *spendableOutput, outputCount int, minimumOutputCount int) (int, error) {
+	for ; outputCount >= minimumOutputCount; outputCount /= 2 {
+		transaction, outputs, err := g.builder.build([]*spendableOutput{output}, outputCount, g.keys.loadScriptPublicKey)
+		if err != nil {
+			if errors.Is(err, errInsufficientFunds) {
+				continue
+			}
+			return 0, err
+		}
+		err = g.submit(transaction, outputs, false)
+		if err != nil {
+			return 0, errors.Wrapf(err, "error submitting a fan-out transaction")
+		}
+		return outputCount, nil
+	}
+	return 0, nil
+}
+
+// waitForPendingTransactions waits until all the pending transactions are accepted. It
+// returns false if quit is closed first.
+func (g *generator) waitForPendingTransactions(quit <-chan struct{}) bool {
+	ticker := time.NewTicker(pendingPollInterval)
+	defer ticker.Stop()
+	lastLogTime := time.Now()
+	for {
+		pendingCount := g.pendingCount()
+		if pendingCount == 0 {
+			return true
+		}
+		if time.Since(lastLogTime) > fundingPollInterval {
+			log.Infof("Still waiting for %d transactions to be accepted. Make sure blocks are being mined", pendingCount)
+			lastLogTime = time.Now()
+		}
+		select {
+		case <-quit:
+			return false
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/transactionid"
+	"github.com/sedracoin/sedrad/infrastructure/network/rpcclient"
+	"github.com/sedracoin/sedrad/util/txfee"
+	"github.com/sedracoin/sedrad/util/txmass"
+	"github.com/pkg/errors"
+)
+
+// submitTickInterval is how often due transactions are submitted
+const submitTickInterval = 10 * time.Millisecond
+
+// pendingTransaction is a submitted transaction that wasn't accepted yet
+type pendingTransaction struct {
+	submitTime time.Time
+	outputs    []*spendableOutput
+
+	// isLoad is false for the fan-out transactions, which aren't counted in the statistics
+	isLoad bool
+}
+
+// generator submits transactions at the configured rate and shape, and moves their outputs
+// to the pool of spendable outputs once they're accepted
+type generator struct {
+	cfg     *configFlags
+	client  *rpcclient.RPCClient
+	keys    *keys
+	builder *transactionBuilder
+	stats   *stats
+
+	lock    sync.Mutex
+	pool    []*spendableOutput
+	pending map[externalapi.DomainTransactionID]*pendingTransaction
+}
+
+func newGenerator(cfg *configFlags, client *rpcclient.RPCClient, keys *keys) (*generator, error) {
+	getInfoResponse, err := client.GetInfo()
+	if err != nil {
+		return nil, err
+	}
+	if !getInfoResponse.IsUtxoIndexed {
+		return nil, errors.New("the node must run with --utxoindex")
+	}
+
+	feeRate := cfg.FeeRate
+	if feeRate == 0 {
+		feeRate = getInfoResponse.MinimumRelayTransactionFee
+	}
+	if feeRate == 0 {
+		feeRate = txfee.DefaultMinimumRelayFeeRate
+	}
+	log.Infof("Using a fee rate of %d seep per 1000 grams", feeRate)
+
+	params := cfg.NetParams()
+	g := &generator{
+		cfg:    cfg,
+		client: client,
+		keys:   keys,
+		builder: &transactionBuilder{
+			keys:           keys,
+			massCalculator: txmass.NewCalculator(params.MassPerTxByte, params.MassPerScriptPubKeyByte, params.MassPerSigOp),
+			feeRate:        feeRate,
+		},
+		pending: make(map[externalapi.DomainTransactionID]*pendingTransaction),
+	}
+
+	err = client.RegisterForVirtualSelectedParentChainChangedNotifications(true, g.handleVirtualSelectedParentChainChanged)
+	if err != nil {
+		return nil, errors.Wrap(err, "error requesting virtual-selected-parent-chain-changed notifications")
+	}
+	return g, nil
+}
+
+// run funds the generator and then submits transactions until the configured duration
+// passes or quit is closed
+func (g *generator) run(quit <-chan struct{}) error {
+	err := g.fund(quit)
+	if err != nil {
+		return err
+	}
+	select {
+	case <-quit:
+		return nil
+	default:
+	}
+
+	mempoolSize, err := g.mempoolSize()
+	if err != nil {
+		return err
+	}
+	g.lock.Lock()
+	g.stats = newStats(mempoolSize)
+	g.lock.Unlock()
+	defer g.summarize()
+
+	var deadline <-chan time.Time
+	if g.cfg.Duration > 0 {
+		deadline = time.After(g.cfg.Duration)
+	}
+	reportTicker := time.NewTicker(g.cfg.ReportInterval)
+	defer reportTicker.Stop()
+	submitTicker := time.NewTicker(submitTickInterval)
+	defer submitTicker.Stop()
+
+	log.Infof("Submitting %.1f transactions per second with %d inputs, %d outputs and %d chained children",
+		g.cfg.TPS, g.cfg.Inputs, g.cfg.Outputs, g.cfg.ChainedChildren)
+	startTime := time.Now()
+	submittedCount := 0
+	for {
+		select {
+		case <-quit:
+			return nil
+		case <-deadline:
+			return nil
+		case <-reportTicker.C:
+			err := g.report()
+			if err != nil {
+				return err
+			}
+		case now := <-submitTicker.C:
+			dueCount := int(now.Sub(startTime).Seconds()*g.cfg.TPS) - submittedCount
+
+			// Don't try to catch up on more than a second of transactions if the node
+			// can't keep up, so that the load doesn't come in bursts
+			maxDueCount := int(g.cfg.TPS) + 1
+			if dueCount > maxDueCount {
+				log.Debugf("Falling behind by %d transactions", dueCount)
+				submittedCount += dueCount - maxDueCount
+				dueCount = maxDueCount
+			}
+
+			for dueCount > 0 {
+				count, err := g.submitLoadTransactions()
+				if err != nil {
+					return err
+				}
+				if count == 0 {
+					g.stats.recordStarved(dueCount)
+					submittedCount += dueCount
+					break
+				}
+				submittedCount += count
+				dueCount -= count
+			}
+		}
+	}
+}
+
+// submitLoadTransactions submits a transaction of the configured shape, followed by its
+// chained children. It returns the number of transactions it tried to submit, which is 0
+// if there aren't enough spendable outputs.
+func (g *generator) submitLoadTransactions() (int, error) {
+	var transaction *externalapi.DomainTransaction
+	var outputs []*spendableOutput
+	for transaction == nil {
+		inputs := g.takeFromPool(g.cfg.Inputs)
+		if inputs == nil {
+			return 0, nil
+		}
+		var err error
+		transaction, outputs, err = g.builder.build(inputs, g.cfg.Outputs, g.keys.loadScriptPublicKey)
+		if err != nil {
+			if !errors.Is(err, errInsufficientFunds) {
+				return 0, err
+			}
+			g.stats.recordDroppedUTXOs(len(inputs))
+		}
+	}
+	err := g.submitLoadTransaction(transaction, outputs)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 1
+	parentID := consensushashing.TransactionID(transaction)
+	parentOutputs := outputs
+	for i := 0; i < g.cfg.ChainedChildren; i++ {
+		child, childOutputs, err := g.builder.build(parentOutputs, g.cfg.Outputs, g.keys.loadScriptPublicKey)
+		if err != nil {
+			if errors.Is(err, errInsufficientFunds) {
+				break
+			}
+			return 0, err
+		}
+		// The parent might've been accepted in the meantime, in which case its outputs are
+		// already in the pool
+		if !g.claimPendingOutputs(parentID, parentOutputs) {
+			break
+		}
+		err = g.submitLoadTransaction(child, childOutputs)
+		if err != nil {
+			return 0, err
+		}
+		count++
+		parentID = consensushashing.TransactionID(child)
+		parentOutputs = childOutputs
+	}
+	return count, nil
+}
+
+// submitLoadTransaction submits a load transaction and records it in the statistics. A
+// rejection isn't an error, and the inputs of the rejected transaction are dropped.
+func (g *generator) submitLoadTransaction(transaction *externalapi.DomainTransaction, outputs []*spendableOutput) error {
+	g.stats.recordSubmitted()
+	err := g.submit(transaction, outputs, true)
+	if err != nil {
+		if !errors.Is(err, rpcclient.ErrRPC) {
+			return err
+		}
+		g.stats.recordRejected()
+		log.Debugf("Transaction %s was rejected: %s", consensushashing.TransactionID(transaction), err)
+	}
+	return nil
+}
+
+// submit submits the given transaction and marks it as pending until it's accepted
+func (g *generator) submit(transaction *externalapi.DomainTransaction, outputs []*spendableOutput, isLoad bool) error {
+	transactionID := consensushashing.TransactionID(transaction)
+	g.lock.Lock()
+	g.pending[*transactionID] = &pendingTransaction{
+		submitTime: time.Now(),
+		outputs:    outputs,
+		isLoad:     isLoad,
+	}
+	g.lock.Unlock()
+
+	_, err := g.client.SubmitTransaction(appmessage.DomainTransactionToRPCTransaction(transaction), false)
+	if err != nil {
+		g.lock.Lock()
+		delete(g.pending, *transactionID)
+		g.lock.Unlock()
+		return err
+	}
+	return nil
+}
+
+// handleVirtualSelectedParentChainChanged moves the outputs of the accepted pending
+// transactions to the pool and records their acceptance latency
+func (g *generator) handleVirtualSelectedParentChainChanged(
+	notification *appmessage.VirtualSelectedParentChainChangedNotificationMessage) {
+
+	now := time.Now()
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	for _, acceptedTransactionIDs := range notification.AcceptedTransactionIDs {
+		for _, acceptedTransactionID := range acceptedTransactionIDs.AcceptedTransactionIDs {
+			transactionID, err := transactionid.FromString(acceptedTransactionID)
+			if err != nil {
+				log.Warnf("Couldn't parse accepted transaction ID %s: %s", acceptedTransactionID, err)
+				continue
+			}
+			pending, ok := g.pending[*transactionID]
+			if !ok {
+				continue
+			}
+			delete(g.pending, *transactionID)
+
+			for _, output := range pending.outputs {
+				if !output.isSpent {
+					g.pool = append(g.pool, output)
+				}
+			}
+			if pending.isLoad && g.stats != nil {
+				g.stats.recordAccepted(now.Sub(pending.submitTime))
+			}
+		}
+	}
+}
+
+// takeFromPool removes the given number of outputs from the pool and returns them, or
+// returns nil if the pool doesn't have enough outputs
+func (g *generator) takeFromPool(count int) []*spendableOutput {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if len(g.pool) < count {
+		return nil
+	}
+	outputs := g.pool[:count:count]
+	g.pool = g.pool[count:]
+	for _, output := range outputs {
+		output.isSpent = true
+	}
+	return outputs
+}
+
+// claimPendingOutputs marks the given outputs of a pending transaction as spent, so that
+// they won't move to the pool once it's accepted. It returns false if the transaction is
+// no longer pending.
+func (g *generator) claimPendingOutputs(transactionID *externalapi.DomainTransactionID, outputs []*spendableOutput) bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if _, ok := g.pending[*transactionID]; !ok {
+		return false
+	}
+	for _, output := range outputs {
+		output.isSpent = true
+	}
+	return true
+}
+
+func (g *generator) pendingCount() int {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	return len(g.pending)
+}
+
+func (g *generator) mempoolSize() (uint64, error) {
+	getInfoResponse, err := g.client.GetInfo()
+	if err != nil {
+		return 0, err
+	}
+	return getInfoResponse.MempoolSize, nil
+}
+
+func (g *generator) report() error {
+	mempoolSize, err := g.mempoolSize()
+	if err != nil {
+		return err
+	}
+	g.stats.report(g.pendingCount(), mempoolSize)
+	return nil
+}
+
+func (g *generator) summarize() {
+	mempoolSize, err := g.mempoolSize()
+	if err != nil {
+		log.Warnf("Couldn't get the mempool size: %s", err)
+	}
+	g.stats.summarize(g.pendingCount(), mempoolSize)
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/sedracoin/go-secp256k1"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
+	"github.com/sedracoin/sedrad/domain/dagconfig"
+	"github.com/sedracoin/sedrad/util"
+	"github.com/pkg/errors"
+)
+
+// schnorrSignatureSize is the size of a Schnorr signature with its appended sighash type
+const schnorrSignatureSize = 65
+
+// keys holds the keys sedraload signs its transactions with.
+//
+// The funds are received at the pay-to-pubkey funding address. Unless multisig is used, the load
+// transactions spend and create outputs of the funding address as well. With multisig, they spend
+// and create outputs of a pay-to-script-hash address, whose keys are derived from the funding key
+// so that its leftover UTXOs can be spent by later runs.
+type keys struct {
+	fundingKeyPair         *secp256k1.SchnorrKeyPair
+	fundingAddress         util.Address
+	fundingScriptPublicKey *externalapi.ScriptPublicKey
+
+	loadAddress         util.Address
+	loadScriptPublicKey *externalapi.ScriptPublicKey
+
+	multisigKeyPairs  []*secp256k1.SchnorrKeyPair
+	minimumSignatures int
+	redeemScript      []byte
+}
+
+// newKeys creates the keys of the given hex encoded private key. If privateKeyHex is empty, a new
+// private key is generated.
+func newKeys(privateKeyHex string, minimumSignatures int, publicKeyCount int, params *dagconfig.Params) (*keys, error) {
+	var fundingKeyPair *secp256k1.SchnorrKeyPair
+	if privateKeyHex == "" {
+		var err error
+		fundingKeyPair, err = secp256k1.GenerateSchnorrKeyPair()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		privateKeyBytes, err := hex.DecodeString(privateKeyHex)
+		if err != nil {
+			return nil, errors.Wrap(err, "error decoding the private key")
+		}
+		fundingKeyPair, err = secp256k1.DeserializeSchnorrPrivateKeyFromSlice(privateKeyBytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "error deserializing the private key")
+		}
+	}
+
+	fundingPublicKey, err := serializedPublicKey(fundingKeyPair)
+	if err != nil {
+		return nil, err
+	}
+	fundingAddress, err := util.NewAddressPublicKey(fundingPublicKey, params.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	fundingScriptPublicKey, err := txscript.PayToAddrScript(fundingAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &keys{
+		fundingKeyPair:         fundingKeyPair,
+		fundingAddress:         fundingAddress,
+		fundingScriptPublicKey: fundingScriptPublicKey,
+		loadAddress:            fundingAddress,
+		loadScriptPublicKey:    fundingScriptPublicKey,
+	}
+	if publicKeyCount == 0 {
+		return k, nil
+	}
+
+	k.minimumSignatures = minimumSignatures
+	k.multisigKeyPairs = make([]*secp256k1.SchnorrKeyPair, publicKeyCount)
+	scriptBuilder := txscript.NewScriptBuilder()
+	scriptBuilder.AddInt64(int64(minimumSignatures))
+	for i := range k.multisigKeyPairs {
+		k.multisigKeyPairs[i], err = deriveMultisigKeyPair(fundingKeyPair, i)
+		if err != nil {
+			return nil, err
+		}
+		publicKey, err := serializedPublicKey(k.multisigKeyPairs[i])
+		if err != nil {
+			return nil, err
+		}
+		scriptBuilder.AddData(publicKey)
+	}
+	scriptBuilder.AddInt64(int64(publicKeyCount))
+	scriptBuilder.AddOp(txscript.OpCheckMultiSig)
+	k.redeemScript, err = scriptBuilder.Script()
+	if err != nil {
+		return nil, err
+	}
+
+	k.loadAddress, err = util.NewAddressScriptHash(k.redeemScript, params.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	k.loadScriptPublicKey, err = txscript.PayToAddrScript(k.loadAddress)
+	if err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// deriveMultisigKeyPair deterministically derives the multisig key with the given index from the
+// funding key
+func deriveMultisigKeyPair(fundingKeyPair *secp256k1.SchnorrKeyPair, index int) (*secp256k1.SchnorrKeyPair, error) {
+	seed := append(fundingKeyPair.SerializePrivateKey()[:], byte(index))
+	privateKey := sha256.Sum256(seed)
+	return secp256k1.DeserializeSchnorrPrivateKeyFromSlice(privateKey[:])
+}
+
+func serializedPublicKey(keyPair *secp256k1.SchnorrKeyPair) ([]byte, error) {
+	publicKey, err := keyPair.SchnorrPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	serialized, err := publicKey.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	return serialized[:], nil
+}
+
+// isMultisig returns whether outputs with the given script are spent by multisig signatures
+func (k *keys) isMultisig(scriptPublicKey *externalapi.ScriptPublicKey) bool {
+	return k.redeemScript != nil && scriptPublicKey.Equal(k.loadScriptPublicKey)
+}
+
+// sigOpCount returns the number of signature operations needed to spend an output with the given script
+func (k *keys) sigOpCount(scriptPublicKey *externalapi.ScriptPublicKey) byte {
+	if k.isMultisig(scriptPublicKey) {
+		return byte(len(k.multisigKeyPairs))
+	}
+	return 1
+}
+
+// placeholderSignatureScript returns a signature script with the same size as the one that
+// spends an output with the given script, so that the mass of a transaction can be calculated
+// before it's signed
+func (k *keys) placeholderSignatureScript(scriptPublicKey *externalapi.ScriptPublicKey) ([]byte, error) {
+	placeholderSignature := make([]byte, schnorrSignatureSize)
+	if !k.isMultisig(scriptPublicKey) {
+		return txscript.NewScriptBuilder().AddData(placeholderSignature).Script()
+	}
+	scriptBuilder := txscript.NewScriptBuilder()
+	for i := 0; i < k.minimumSignatures; i++ {
+		scriptBuilder.AddData(placeholderSignature)
+	}
+	scriptBuilder.AddData(k.redeemScript)
+	return scriptBuilder.Script()
+}
+
+// sign signs all the inputs of the given transaction. The UTXO entries and signature operation
+// counts of the inputs must already be set.
+func (k *keys) sign(transaction *externalapi.DomainTransaction) error {
+	sighashReusedValues := &consensushashing.SighashReusedValues{}
+	for i, input := range transaction.Inputs {
+		scriptPublicKey := input.UTXOEntry.ScriptPublicKey()
+		if !k.isMultisig(scriptPublicKey) {
+			signatureScript, err := txscript.SignatureScript(transaction, i, consensushashing.SigHashAll,
+				k.fundingKeyPair, sighashReusedValues)
+			if err != nil {
+				return err
+			}
+			input.SignatureScript = signatureScript
+			continue
+		}
+
+		// The signatures must be in the same order as their public keys in the redeem script
+		scriptBuilder := txscript.NewScriptBuilder()
+		for _, keyPair := range k.multisigKeyPairs[:k.minimumSignatures] {
+			signature, err := txscript.RawTxInSignature(transaction, i, consensushashing.SigHashAll,
+				keyPair, sighashReusedValues)
+			if err != nil {
+				return err
+			}
+			scriptBuilder.AddData(signature)
+		}
+		scriptBuilder.AddData(k.redeemScript)
+		signatureScript, err := scriptBuilder.Script()
+		if err != nil {
+			return err
+		}
+		input.SignatureScript = signatureScript
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/sedracoin/sedrad/infrastructure/logger"
+	"github.com/sedracoin/sedrad/infrastructure/network/rpcclient"
+	"github.com/sedracoin/sedrad/version"
+
+	"github.com/pkg/errors"
+
+	_ "net/http/pprof"
+
+	"github.com/sedracoin/sedrad/infrastructure/os/signal"
+	"github.com/sedracoin/sedrad/util/panics"
+	"github.com/sedracoin/sedrad/util/profiling"
+)
+
+func main() {
+	defer panics.HandlePanic(log, "MAIN", nil)
+	interrupt := signal.InterruptListener()
+
+	cfg, err := parseConfig()
+	if err != nil {
+		printErrorAndExit(errors.Errorf("Error parsing command-line arguments: %s", err))
+	}
+	defer backendLog.Close()
+
+	// Show version at startup.
+	log.Infof("Version %s", version.Version())
+
+	// Enable http profiling server if requested.
+	if cfg.Profile != "" {
+		profiling.Start(cfg.Profile, log)
+	}
+
+	keys, err := newKeys(cfg.PrivateKey, cfg.minimumSignatures, cfg.publicKeyCount, cfg.NetParams())
+	if err != nil {
+		printErrorAndExit(errors.Wrap(err, "error creating the keys"))
+	}
+	if cfg.PrivateKey == "" {
+		log.Infof("Generated the private key %s. Pass it with --private-key to reuse its funds in later runs",
+			hex.EncodeToString(keys.fundingKeyPair.SerializePrivateKey()[:]))
+	}
+
+	rpcAddress, err := cfg.NetParams().NormalizeRPCServerAddress(cfg.RPCServer)
+	if err != nil {
+		printErrorAndExit(errors.Wrap(err, "error parsing the RPC server address"))
+	}
+	client, err := rpcclient.NewRPCClient(rpcAddress)
+	if err != nil {
+		printErrorAndExit(errors.Wrap(err, "error connecting to the RPC server"))
+	}
+	defer client.Disconnect()
+	client.SetLogger(backendLog, logger.LevelTrace)
+	log.Infof("Connected to %s", rpcAddress)
+
+	generator, err := newGenerator(cfg, client, keys)
+	if err != nil {
+		printErrorAndExit(err)
+	}
+
+	quit := make(chan struct{})
+	doneChan := make(chan struct{})
+	spawn("generator.run", func() {
+		err := generator.run(quit)
+		if err != nil {
+			panic(errors.Wrap(err, "error generating load"))
+		}
+		close(doneChan)
+	})
+
+	select {
+	case <-doneChan:
+	case <-interrupt:
+		close(quit)
+		<-doneChan
+	}
+}
+
+func printErrorAndExit(err error) {
+	fmt.Fprintf(os.Stderr, "%+v\n", err)
+	os.Exit(1)
+}
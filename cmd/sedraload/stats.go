@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// periodStats are the statistics of the load transactions over some period
+type periodStats struct {
+	startTime time.Time
+	submitted int
+	accepted  int
+	rejected  int
+	starved   int
+	latencies []time.Duration
+}
+
+func newPeriodStats(startTime time.Time) *periodStats {
+	return &periodStats{startTime: startTime}
+}
+
+// String returns a one line summary of the period that ends at the time it's called
+func (ps *periodStats) String() string {
+	elapsed := time.Since(ps.startTime)
+	str := fmt.Sprintf("submitted %d (%.1f TPS), accepted %d (%.1f TPS), rejected %d, starved %d",
+		ps.submitted, float64(ps.submitted)/elapsed.Seconds(), ps.accepted, float64(ps.accepted)/elapsed.Seconds(),
+		ps.rejected, ps.starved)
+	if len(ps.latencies) == 0 {
+		return str
+	}
+
+	latencies := make([]time.Duration, len(ps.latencies))
+	copy(latencies, ps.latencies)
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	return fmt.Sprintf("%s, acceptance latency p50 %s p90 %s p99 %s max %s", str,
+		percentile(latencies, 50), percentile(latencies, 90), percentile(latencies, 99), percentile(latencies, 100))
+}
+
+// percentile returns the nearest-rank percentile of the given sorted latencies
+func percentile(sortedLatencies []time.Duration, percent int) time.Duration {
+	rank := (percent*len(sortedLatencies) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sortedLatencies[rank-1].Round(time.Millisecond)
+}
+
+// stats collects the statistics of the load transactions, both since the last report and in total
+type stats struct {
+	lock     sync.Mutex
+	interval *periodStats
+	total    *periodStats
+
+	initialMempoolSize uint64
+	lastMempoolSize    uint64
+	droppedUTXOs       int
+}
+
+func newStats(initialMempoolSize uint64) *stats {
+	now := time.Now()
+	return &stats{
+		interval:           newPeriodStats(now),
+		total:              newPeriodStats(now),
+		initialMempoolSize: initialMempoolSize,
+		lastMempoolSize:    initialMempoolSize,
+	}
+}
+
+func (s *stats) recordSubmitted() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.interval.submitted++
+	s.total.submitted++
+}
+
+func (s *stats) recordRejected() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.interval.rejected++
+	s.total.rejected++
+}
+
+func (s *stats) recordStarved(count int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.interval.starved += count
+	s.total.starved += count
+}
+
+func (s *stats) recordAccepted(latency time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.interval.accepted++
+	s.interval.latencies = append(s.interval.latencies, latency)
+	s.total.accepted++
+	s.total.latencies = append(s.total.latencies, latency)
+}
+
+func (s *stats) recordDroppedUTXOs(count int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.droppedUTXOs += count
+}
+
+// report logs the statistics since the last report and starts a new interval
+func (s *stats) report(pendingCount int, mempoolSize uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	log.Infof("Last %s: %s. Pending %d, mempool size %d (%+d)",
+		time.Since(s.interval.startTime).Round(time.Second), s.interval, pendingCount, mempoolSize,
+		int64(mempoolSize)-int64(s.lastMempoolSize))
+	s.interval = newPeriodStats(time.Now())
+	s.lastMempoolSize = mempoolSize
+}
+
+// summarize logs the statistics since the load started
+func (s *stats) summarize(pendingCount int, mempoolSize uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	log.Infof("Total in %s: %s", time.Since(s.total.startTime).Round(time.Second), s.total)
+	log.Infof("Pending %d, mempool size %d (%+d since the load started)", pendingCount, mempoolSize,
+		int64(mempoolSize)-int64(s.initialMempoolSize))
+	if s.droppedUTXOs > 0 {
+		log.Warnf("%d UTXOs were too small to pay for the configured transaction shape and were dropped",
+			s.droppedUTXOs)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/constants"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/subnetworks"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/utxo"
+	"github.com/sedracoin/sedrad/util/txfee"
+	"github.com/sedracoin/sedrad/util/txmass"
+	"github.com/pkg/errors"
+)
+
+// errInsufficientFunds is returned when the inputs of a transaction can't pay for its fee
+// and non-dust outputs
+var errInsufficientFunds = errors.New("insufficient funds")
+
+// spendableOutput is an output that sedraload can spend
+type spendableOutput struct {
+	outpoint externalapi.DomainOutpoint
+	entry    externalapi.UTXOEntry
+
+	// isSpent is set once a transaction that spends the output is submitted, which
+	// may happen before the transaction that created it is accepted
+	isSpent bool
+}
+
+// transactionBuilder builds and signs sedraload's transactions
+type transactionBuilder struct {
+	keys           *keys
+	massCalculator *txmass.Calculator
+	feeRate        uint64
+}
+
+// build builds a signed transaction that spends the given outputs into outputCount outputs
+// with the given script, which split the value of the inputs minus the fee equally. It returns
+// the outputs of the transaction, or errInsufficientFunds if they would be dust.
+func (b *transactionBuilder) build(inputs []*spendableOutput, outputCount int,
+	scriptPublicKey *externalapi.ScriptPublicKey) (*externalapi.DomainTransaction, []*spendableOutput, error) {
+
+	transaction := &externalapi.DomainTransaction{
+		Version:      constants.MaxTransactionVersion,
+		Inputs:       make([]*externalapi.DomainTransactionInput, len(inputs)),
+		Outputs:      make([]*externalapi.DomainTransactionOutput, outputCount),
+		SubnetworkID: subnetworks.SubnetworkIDNative,
+	}
+	inputsValue := uint64(0)
+	for i, input := range inputs {
+		signatureScript, err := b.keys.placeholderSignatureScript(input.entry.ScriptPublicKey())
+		if err != nil {
+			return nil, nil, err
+		}
+		transaction.Inputs[i] = &externalapi.DomainTransactionInput{
+			PreviousOutpoint: input.outpoint,
+			SignatureScript:  signatureScript,
+			SigOpCount:       b.keys.sigOpCount(input.entry.ScriptPublicKey()),
+			UTXOEntry:        input.entry,
+		}
+		inputsValue += input.entry.Amount()
+	}
+	for i := range transaction.Outputs {
+		transaction.Outputs[i] = &externalapi.DomainTransactionOutput{ScriptPublicKey: scriptPublicKey}
+	}
+
+	// The placeholder signature scripts have the same size as the real ones, and the size of
+	// an output doesn't depend on its value, so the mass is already final
+	fee := txfee.MinimumRelayFee(b.massCalculator.CalculateTransactionMass(transaction), b.feeRate)
+	if inputsValue <= fee {
+		return nil, nil, errInsufficientFunds
+	}
+	outputValue := (inputsValue - fee) / uint64(outputCount)
+	for _, output := range transaction.Outputs {
+		output.Value = outputValue
+		if isDust(output, b.feeRate) {
+			return nil, nil, errInsufficientFunds
+		}
+	}
+	transaction.Outputs[0].Value += (inputsValue - fee) % uint64(outputCount)
+
+	err := b.keys.sign(transaction)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	transactionID := consensushashing.TransactionID(transaction)
+	outputs := make([]*spendableOutput, outputCount)
+	for i, output := range transaction.Outputs {
+		outputs[i] = &spendableOutput{
+			outpoint: externalapi.DomainOutpoint{TransactionID: *transactionID, Index: uint32(i)},
+			entry:    utxo.NewUTXOEntry(output.Value, output.ScriptPublicKey, false, constants.UnacceptedDAAScore),
+		}
+	}
+	return transaction, outputs, nil
+}
+
+// isDust returns whether the mempool considers the given output dust. It mirrors
+// the mempool's IsTransactionOutputDust, assuming its minimum relay fee is feeRate.
+func isDust(output *externalapi.DomainTransactionOutput, feeRate uint64) bool {
+	totalSerializedSize := txmass.TransactionOutputEstimatedSerializedSize(output) + 148
+	return output.Value*1000/(3*totalSerializedSize) < feeRate
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/utxo"
+	"github.com/sedracoin/sedrad/domain/dagconfig"
+	"github.com/sedracoin/sedrad/util/txfee"
+	"github.com/sedracoin/sedrad/util/txmass"
+	"github.com/pkg/errors"
+)
+
+func TestBuild(t *testing.T) {
+	params := &dagconfig.SimnetParams
+	const privateKey = "05d8f681e954a550395ee2297fc1a14f6e801f554c0b9d48cd7165a7ea72ff77"
+	tests := []struct {
+		name              string
+		minimumSignatures int
+		publicKeyCount    int
+	}{
+		{name: "pay-to-pubkey"},
+		{name: "2-of-3 multisig", minimumSignatures: 2, publicKeyCount: 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			keys, err := newKeys(privateKey, test.minimumSignatures, test.publicKeyCount, params)
+			if err != nil {
+				t.Fatalf("newKeys: %+v", err)
+			}
+			builder := &transactionBuilder{
+				keys:           keys,
+				massCalculator: txmass.NewCalculator(params.MassPerTxByte, params.MassPerScriptPubKeyByte, params.MassPerSigOp),
+				feeRate:        txfee.DefaultMinimumRelayFeeRate,
+			}
+
+			// Spend a funding output and one of the resulting load outputs, like the fan-out does
+			inputs := []*spendableOutput{{
+				outpoint: externalapi.DomainOutpoint{Index: 0},
+				entry:    utxo.NewUTXOEntry(1_000_000, keys.fundingScriptPublicKey, true, 0),
+			}}
+			var transaction *externalapi.DomainTransaction
+			for i := 0; i < 2; i++ {
+				var outputs []*spendableOutput
+				transaction, outputs, err = builder.build(inputs, 3, keys.loadScriptPublicKey)
+				if err != nil {
+					t.Fatalf("build: %+v", err)
+				}
+				verifyTransaction(t, builder, transaction)
+				if len(outputs) != 3 || outputs[2].outpoint.TransactionID != *consensushashing.TransactionID(transaction) ||
+					outputs[2].outpoint.Index != 2 || outputs[2].entry.Amount() != transaction.Outputs[2].Value {
+					t.Fatalf("Unexpected outputs %+v", outputs)
+				}
+				inputs = outputs[:1]
+			}
+
+			// The outputs of a load transaction are split equally, except for the remainder
+			if transaction.Outputs[0].Value < transaction.Outputs[1].Value ||
+				transaction.Outputs[1].Value != transaction.Outputs[2].Value {
+				t.Fatalf("Unexpected output values %d, %d and %d",
+					transaction.Outputs[0].Value, transaction.Outputs[1].Value, transaction.Outputs[2].Value)
+			}
+
+			_, _, err = builder.build(inputs, 1000, keys.loadScriptPublicKey)
+			if !errors.Is(err, errInsufficientFunds) {
+				t.Fatalf("Expected errInsufficientFunds for dust outputs, but got %v", err)
+			}
+		})
+	}
+}
+
+// verifyTransaction checks that the given transaction passes script validation, that the
+// signature operation counts of its inputs are correct and that it pays the minimum fee
+func verifyTransaction(t *testing.T, builder *transactionBuilder, transaction *externalapi.DomainTransaction) {
+	inputsValue := uint64(0)
+	for i, input := range transaction.Inputs {
+		scriptPublicKey := input.UTXOEntry.ScriptPublicKey()
+		engine, err := txscript.NewEngine(scriptPublicKey, transaction, i, txscript.ScriptNoFlags, nil, nil,
+			&consensushashing.SighashReusedValues{})
+		if err != nil {
+			t.Fatalf("NewEngine: %+v", err)
+		}
+		err = engine.Execute()
+		if err != nil {
+			t.Fatalf("Input %d failed script validation: %+v", i, err)
+		}
+
+		sigOpCount := txscript.GetPreciseSigOpCount(input.SignatureScript, scriptPublicKey, txscript.IsPayToScriptHash(scriptPublicKey))
+		if int(input.SigOpCount) != sigOpCount {
+			t.Fatalf("Expected input %d to have %d signature operations, but it has %d", i, sigOpCount, input.SigOpCount)
+		}
+		inputsValue += input.UTXOEntry.Amount()
+	}
+
+	outputsValue := uint64(0)
+	for _, output := range transaction.Outputs {
+		outputsValue += output.Value
+	}
+	expectedFee := txfee.MinimumRelayFee(builder.massCalculator.CalculateTransactionMass(transaction), builder.feeRate)
+	if inputsValue-outputsValue != expectedFee {
+		t.Fatalf("Expected a fee of %d, but got %d", expectedFee, inputsValue-outputsValue)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	latencies := make([]time.Duration, 200)
+	for i := range latencies {
+		latencies[i] = time.Duration(i+1) * time.Second
+	}
+	tests := []struct {
+		percent  int
+		expected time.Duration
+	}{
+		{percent: 0, expected: 1 * time.Second},
+		{percent: 50, expected: 100 * time.Second},
+		{percent: 99, expected: 198 * time.Second},
+		{percent: 100, expected: 200 * time.Second},
+	}
+	for _, test := range tests {
+		result := percentile(latencies, test.percent)
+		if result != test.expected {
+			t.Errorf("Expected p%d to be %s, but got %s", test.percent, test.expected, result)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"github.com/jessevdk/go-flags"
+	"github.com/sedracoin/sedrad/infrastructure/config"
+)
+
+type configFlags struct {
+	Transaction string   `long:"transaction" short:"t" description:"The hex transaction, as created or signed by sedrawallet" required:"true"`
+	InputIndex  uint32   `long:"input" short:"i" description:"The index of the input to replay"`
+	UTXOs       []string `long:"utxo" short:"u" description:"A UTXO entry referenced by the transaction, as <transaction ID>:<index>:<amount>:<hex script public key>[:<script version>]. Use multiple times for several inputs"`
+	RPCServer   string   `long:"rpcserver" short:"s" description:"Fetch the UTXO entries that weren't given with --utxo from this RPC server"`
+	Addresses   []string `long:"address" short:"a" description:"An address whose UTXOs are searched when fetching UTXO entries from the RPC server. Use multiple times for several addresses"`
+	ECDSA       bool     `long:"ecdsa" description:"The transaction was created by an ECDSA wallet"`
+	Verbose     bool     `long:"verbose" short:"v" description:"Print the stacks of every step, and not only of the failing one"`
+	config.NetworkFlags
+}
+
+func parseConfig() (*configFlags, error) {
+	cfg := &configFlags{}
+	parser := flags.NewParser(cfg, flags.PrintErrors|flags.HelpFlag)
+	_, err := parser.Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	err = cfg.ResolveNetwork(parser)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet/serialization"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/constants"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
+	"github.com/pkg/errors"
+)
+
+func main() {
+	cfg, err := parseConfig()
+	if err != nil {
+		os.Exit(1)
+	}
+
+	err = replay(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+}
+
+func replay(cfg *configFlags) error {
+	tx, partiallySignedTransaction, err := parseTransaction(cfg)
+	if err != nil {
+		return err
+	}
+	if int(cfg.InputIndex) >= len(tx.Inputs) {
+		return errors.Errorf("the transaction has only %d inputs", len(tx.Inputs))
+	}
+
+	err = populateUTXOEntries(cfg, tx, partiallySignedTransaction)
+	if err != nil {
+		return err
+	}
+
+	input := tx.Inputs[cfg.InputIndex]
+	fmt.Printf("Replaying input %d, which spends %s\n", cfg.InputIndex, input.PreviousOutpoint)
+	fmt.Printf("Signature script: %s\n", disassemble(constants.MaxScriptPublicKeyVersion, input.SignatureScript))
+	fmt.Printf("Script public key: %s\n\n", disassemble(input.UTXOEntry.ScriptPublicKey().Version,
+		input.UTXOEntry.ScriptPublicKey().Script))
+
+	recorder, executionErr := txscript.TraceTransactionInput(tx, int(cfg.InputIndex))
+	for _, step := range recorder.Steps {
+		if cfg.Verbose {
+			fmt.Print(step)
+			continue
+		}
+		notExecuted := ""
+		if !step.IsBranchExecuting {
+			notExecuted = " [not executed]"
+		}
+		fmt.Printf("%02x:%04x: %s (%s)%s\n", step.ScriptIndex, step.ScriptOffset, step.Opcode, step.ScriptName(),
+			notExecuted)
+	}
+	fmt.Println()
+
+	if executionErr == nil {
+		fmt.Println("The input's scripts were executed successfully")
+		return nil
+	}
+
+	failingStep := recorder.FailingStep()
+	if failingStep != nil {
+		fmt.Printf("Failing opcode:\n%s", failingStep)
+	} else if len(recorder.Steps) > 0 {
+		fmt.Printf("All opcodes were executed, and the last step was:\n%s", recorder.Steps[len(recorder.Steps)-1])
+	}
+	return errors.Wrap(executionErr, "script execution failed")
+}
+
+func parseTransaction(cfg *configFlags) (*externalapi.DomainTransaction, *serialization.PartiallySignedTransaction, error) {
+	transactionBytes, err := hex.DecodeString(cfg.Transaction)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "the transaction is not valid hex")
+	}
+	partiallySignedTransaction, err := serialization.DeserializePartiallySignedTransaction(transactionBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// ExtractTransactionDeserialized modifies the transaction it's given, so
+	// it's given a copy in order to keep the original intact in case the
+	// transaction isn't fully signed
+	tx, err := libsedrawallet.ExtractTransactionDeserialized(partiallySignedTransaction.Clone(), cfg.ECDSA)
+	if err != nil {
+		fmt.Printf("The transaction is not fully signed (%s), so it is replayed with the signature "+
+			"scripts it has\n", err)
+		tx = partiallySignedTransaction.Clone().Tx
+	}
+	return tx, partiallySignedTransaction, nil
+}
+
+func disassemble(version uint16, script []byte) string {
+	disassembly, err := txscript.DisasmString(version, script)
+	if err != nil {
+		return fmt.Sprintf("%x (%s)", script, err)
+	}
+	if disassembly == "" {
+		return "<empty>"
+	}
+	return disassembly
+}
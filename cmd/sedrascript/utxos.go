@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet/serialization"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/utxo"
+	"github.com/sedracoin/sedrad/infrastructure/network/rpcclient"
+	"github.com/sedracoin/sedrad/util"
+	"github.com/pkg/errors"
+)
+
+// populateUTXOEntries sets the UTXO entries of all the inputs of tx. Every
+// entry is taken from the --utxo flags if given there, otherwise from the RPC
+// server if one is given, and otherwise from the previous outputs recorded by
+// sedrawallet in the transaction.
+func populateUTXOEntries(cfg *configFlags, tx *externalapi.DomainTransaction,
+	partiallySignedTransaction *serialization.PartiallySignedTransaction) error {
+
+	utxoEntries, err := parseUTXOFlags(cfg.UTXOs)
+	if err != nil {
+		return err
+	}
+
+	if cfg.RPCServer != "" {
+		err := fetchUTXOEntries(cfg, tx, partiallySignedTransaction, utxoEntries)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, input := range tx.Inputs {
+		utxoEntry, ok := utxoEntries[input.PreviousOutpoint]
+		if ok {
+			input.UTXOEntry = utxoEntry
+			continue
+		}
+
+		prevOutput := partiallySignedTransaction.PartiallySignedInputs[i].PrevOutput
+		if cfg.RPCServer != "" {
+			fmt.Printf("The UTXO entry of input %d was not found by the RPC server, so the previous output "+
+				"recorded in the transaction is used\n", i)
+		}
+		input.UTXOEntry = utxo.NewUTXOEntry(prevOutput.Value, prevOutput.ScriptPublicKey, false, 0)
+	}
+	return nil
+}
+
+func parseUTXOFlags(utxoFlags []string) (map[externalapi.DomainOutpoint]externalapi.UTXOEntry, error) {
+	utxoEntries := make(map[externalapi.DomainOutpoint]externalapi.UTXOEntry, len(utxoFlags))
+	for _, utxoFlag := range utxoFlags {
+		parts := strings.Split(utxoFlag, ":")
+		if len(parts) != 4 && len(parts) != 5 {
+			return nil, errors.Errorf("UTXO %s is not of the form "+
+				"<transaction ID>:<index>:<amount>:<hex script public key>[:<script version>]", utxoFlag)
+		}
+
+		transactionID, err := externalapi.NewDomainTransactionIDFromString(parts[0])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid transaction ID in UTXO %s", utxoFlag)
+		}
+		index, err := strconv.ParseUint(parts[1], 10, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid index in UTXO %s", utxoFlag)
+		}
+		amount, err := strconv.ParseUint(parts[2], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid amount in UTXO %s", utxoFlag)
+		}
+		script, err := hex.DecodeString(parts[3])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid script public key in UTXO %s", utxoFlag)
+		}
+		version := uint64(0)
+		if len(parts) == 5 {
+			version, err = strconv.ParseUint(parts[4], 10, 16)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid script version in UTXO %s", utxoFlag)
+			}
+		}
+
+		outpoint := externalapi.DomainOutpoint{TransactionID: *transactionID, Index: uint32(index)}
+		scriptPublicKey := &externalapi.ScriptPublicKey{Script: script, Version: uint16(version)}
+		utxoEntries[outpoint] = utxo.NewUTXOEntry(amount, scriptPublicKey, false, 0)
+	}
+	return utxoEntries, nil
+}
+
+// fetchUTXOEntries adds to utxoEntries the entries of the inputs of tx that are
+// missing from it. Entries are looked up as outputs of mempool transactions, and
+// then in the UTXO set by the addresses given with --address, the addresses of
+// the previous outputs recorded in the transaction and the pay-to-script-hash
+// addresses of the redeem scripts in the inputs' signature scripts.
+func fetchUTXOEntries(cfg *configFlags, tx *externalapi.DomainTransaction,
+	partiallySignedTransaction *serialization.PartiallySignedTransaction,
+	utxoEntries map[externalapi.DomainOutpoint]externalapi.UTXOEntry) error {
+
+	rpcAddress, err := cfg.NetParams().NormalizeRPCServerAddress(cfg.RPCServer)
+	if err != nil {
+		return err
+	}
+	rpcClient, err := rpcclient.NewRPCClient(rpcAddress)
+	if err != nil {
+		return err
+	}
+	defer rpcClient.Close()
+
+	addresses := append([]string(nil), cfg.Addresses...)
+	for i, input := range tx.Inputs {
+		if _, ok := utxoEntries[input.PreviousOutpoint]; ok {
+			continue
+		}
+
+		response, err := rpcClient.GetMempoolEntry(input.PreviousOutpoint.TransactionID.String(), true, false)
+		if err == nil && int(input.PreviousOutpoint.Index) < len(response.Entry.Transaction.Outputs) {
+			output := response.Entry.Transaction.Outputs[input.PreviousOutpoint.Index]
+			script, err := hex.DecodeString(output.ScriptPublicKey.Script)
+			if err != nil {
+				return err
+			}
+			scriptPublicKey := &externalapi.ScriptPublicKey{Script: script, Version: output.ScriptPublicKey.Version}
+			utxoEntries[input.PreviousOutpoint] = utxo.NewUTXOEntry(output.Amount, scriptPublicKey, false, 0)
+			continue
+		}
+
+		prevOutput := partiallySignedTransaction.PartiallySignedInputs[i].PrevOutput
+		_, address, err := txscript.ExtractScriptPubKeyAddress(prevOutput.ScriptPublicKey, cfg.NetParams())
+		if err == nil && address != nil {
+			addresses = append(addresses, address.String())
+		}
+		redeemScriptAddress, ok := redeemScriptAddress(cfg, input.SignatureScript)
+		if ok {
+			addresses = append(addresses, redeemScriptAddress)
+		}
+	}
+	if len(addresses) == 0 {
+		return nil
+	}
+
+	response, err := rpcClient.GetUTXOsByAddresses(addresses)
+	if err != nil {
+		return err
+	}
+	for _, entry := range response.Entries {
+		outpoint, err := appmessage.RPCOutpointToDomainOutpoint(entry.Outpoint)
+		if err != nil {
+			return err
+		}
+		if _, ok := utxoEntries[*outpoint]; ok {
+			continue
+		}
+		utxoEntry, err := appmessage.RPCUTXOEntryToUTXOEntry(entry.UTXOEntry)
+		if err != nil {
+			return err
+		}
+		utxoEntries[*outpoint] = utxoEntry
+	}
+	return nil
+}
+
+// redeemScriptAddress returns the pay-to-script-hash address of the last data
+// push of the given signature script, which is the redeem script in case the
+// input spends a pay-to-script-hash output.
+func redeemScriptAddress(cfg *configFlags, signatureScript []byte) (string, bool) {
+	pushes, err := txscript.PushedData(signatureScript)
+	if err != nil || len(pushes) == 0 {
+		return "", false
+	}
+	address, err := util.NewAddressScriptHash(pushes[len(pushes)-1], cfg.NetParams().Prefix)
+	if err != nil {
+		return "", false
+	}
+	return address.String(), true
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/keys"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet/bip32"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/utils"
+	"github.com/pkg/errors"
+)
+
+func addAccount(conf *addAccountConfig) error {
+	if conf.Account > libsedrawallet.MaxAccount {
+		return errors.Errorf("account %d is higher than the maximum of %d", conf.Account, libsedrawallet.MaxAccount)
+	}
+
+	keysFile, err := keys.ReadKeysFile(conf.NetParams(), conf.KeysFile)
+	if err != nil {
+		return err
+	}
+
+	_, err = keysFile.AccountExtendedPublicKeys(conf.Account)
+	if err == nil {
+		return errors.Errorf("account %d already exists in %s", conf.Account, keysFile.Path())
+	}
+
+	var mnemonics []*libsedrawallet.Mnemonic
+	if len(keysFile.EncryptedMnemonics) > 0 {
+		if len(conf.Password) == 0 {
+			conf.Password = keys.GetPassword("Password:")
+		}
+		mnemonics, err = keysFile.DecryptMnemonics(conf.Password)
+		if err != nil {
+			if strings.Contains(err.Error(), "message authentication failed") {
+				fmt.Fprintf(os.Stderr, "Password decryption failed. Sometimes this is a result of not "+
+					"specifying the same keys file used by the wallet daemon process.\n")
+			}
+			return err
+		}
+	}
+
+	// The public keys of the new account are kept in the same order as the
+	// ones of the first account, so each of them belongs to the same cosigner
+	isMultisig := len(keysFile.ExtendedPublicKeys) > 1
+	extendedPublicKeys := make([]string, len(keysFile.ExtendedPublicKeys))
+	for i, mnemonic := range mnemonics {
+		firstAccountPublicKey, err := libsedrawallet.AccountPublicKeyFromMnemonic(conf.NetParams(), mnemonic, 0,
+			isMultisig)
+		if err != nil {
+			return err
+		}
+		accountPublicKey, err := libsedrawallet.AccountPublicKeyFromMnemonic(conf.NetParams(), mnemonic,
+			conf.Account, isMultisig)
+		if err != nil {
+			return err
+		}
+
+		found := false
+		for j, extendedPublicKey := range keysFile.ExtendedPublicKeys {
+			if extendedPublicKey == firstAccountPublicKey {
+				extendedPublicKeys[j] = accountPublicKey
+				found = true
+			}
+		}
+		if !found {
+			return errors.Errorf("mnemonic #%d doesn't match any of the public keys of the wallet", i+1)
+		}
+
+		fmt.Printf("Extended public key of mnemonic #%d for account %d:\n%s\n\n", i+1, conf.Account, accountPublicKey)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for i, firstAccountPublicKey := range keysFile.ExtendedPublicKeys {
+		if extendedPublicKeys[i] != "" {
+			continue
+		}
+
+		fmt.Printf("Enter the public key for account %d of the cosigner whose first account public key is %s here:\n",
+			conf.Account, firstAccountPublicKey)
+		extendedPublicKey, err := utils.ReadLine(reader)
+		if err != nil {
+			return err
+		}
+
+		_, err = bip32.DeserializeExtendedKey(string(extendedPublicKey))
+		if err != nil {
+			return errors.Wrapf(err, "%s is invalid extended public key", string(extendedPublicKey))
+		}
+
+		fmt.Println()
+
+		extendedPublicKeys[i] = string(extendedPublicKey)
+	}
+
+	err = keysFile.TryLock()
+	if err != nil {
+		return err
+	}
+
+	err = keysFile.AddAccount(conf.Account, extendedPublicKeys)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Added account %d to %s. Restart the wallet daemon in order to use it\n", conf.Account, keysFile.Path())
+	return nil
+}
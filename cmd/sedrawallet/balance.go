@@ -18,7 +18,7 @@ func balance(conf *balanceConfig) error {
 
 	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
 	defer cancel()
-	response, err := daemonClient.GetBalance(ctx, &pb.GetBalanceRequest{})
+	response, err := daemonClient.GetBalance(ctx, &pb.GetBalanceRequest{Account: conf.Account})
 	if err != nil {
 		return err
 	}
@@ -37,12 +37,17 @@ func broadcast(conf *broadcastConfig) error {
 		transactionsHex = strings.TrimSpace(string(transactionHexBytes))
 	}
 
-	transactions, err := decodeTransactionsFromHex(transactionsHex)
+	var transactions [][]byte
+	if conf.Finalized {
+		transactions, err = decodeTransactionsFromHex(transactionsHex)
+	} else {
+		transactions, err = decodePartiallySignedTransactions(transactionsHex)
+	}
 	if err != nil {
 		return err
 	}
 
-	response, err := daemonClient.Broadcast(ctx, &pb.BroadcastRequest{Transactions: transactions})
+	response, err := daemonClient.Broadcast(ctx, &pb.BroadcastRequest{IsDomain: conf.Finalized, Transactions: transactions})
 	if err != nil {
 		return err
 	}
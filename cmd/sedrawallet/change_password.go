@@ -0,0 +1,60 @@
+package main
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/keys"
+	"github.com/pkg/errors"
+)
+
+func changePassword(conf *changePasswordConfig) error {
+	kdfParams := &keys.KDFParams{
+		Time:      conf.KDFTime,
+		MemoryKiB: conf.KDFMemory * 1024,
+		Threads:   conf.KDFThreads,
+	}
+	err := kdfParams.Validate()
+	if err != nil {
+		return err
+	}
+
+	keysFile, err := keys.ReadKeysFile(conf.NetParams(), conf.KeysFile)
+	if err != nil {
+		return err
+	}
+
+	err = keysFile.TryLock()
+	if err != nil {
+		return err
+	}
+
+	if len(conf.Password) == 0 {
+		conf.Password = keys.GetPassword("Current password:")
+	}
+
+	if len(conf.NewPassword) == 0 {
+		newPassword := []byte(keys.GetPassword("New password:"))
+		confirmNewPassword := []byte(keys.GetPassword("Confirm new password:"))
+		if subtle.ConstantTimeCompare(newPassword, confirmNewPassword) != 1 {
+			return errors.New("Passwords are not identical")
+		}
+		conf.NewPassword = string(newPassword)
+	}
+
+	backupPath, err := keysFile.ChangePassword(conf.Password, conf.NewPassword, kdfParams)
+	if err != nil {
+		if strings.Contains(err.Error(), "message authentication failed") {
+			fmt.Fprintf(os.Stderr, "Password decryption failed. Sometimes this is a result of not "+
+				"specifying the same keys file used by the wallet daemon process.\n")
+		}
+		return err
+	}
+
+	fmt.Printf("Changed the password of %s\n", keysFile.Path())
+	fmt.Printf("The previous keys file was backed up to %s. It can still be decrypted with the previous "+
+		"password, so delete it once you have made sure the new password works\n", backupPath)
+	return nil
+}
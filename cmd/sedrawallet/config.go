@@ -2,6 +2,7 @@ package main
 
 import (
 	"os"
+	"time"
 
 	"github.com/sedracoin/sedrad/infrastructure/config"
 	"github.com/pkg/errors"
@@ -22,6 +23,25 @@ const (
 	newAddressSubCmd                = "new-address"
 	dumpUnencryptedDataSubCmd       = "dump-unencrypted-data"
 	startDaemonSubCmd               = "start-daemon"
+	newVaultSubCmd                  = "new-vault"
+	newHTLCSubCmd                   = "new-htlc"
+	importContractSubCmd            = "import-contract"
+	showContractsSubCmd             = "show-contracts"
+	spendContractSubCmd             = "spend-contract"
+	signMessageSubCmd               = "sign-message"
+	verifyMessageSubCmd             = "verify-message"
+	addAccountSubCmd                = "add-account"
+	rescanSubCmd                    = "rescan"
+	changePasswordSubCmd            = "change-password"
+	combineSubCmd                   = "combine"
+	finalizeSubCmd                  = "finalize"
+	inspectSubCmd                   = "inspect"
+	proposeSubCmd                   = "propose"
+	showProposalsSubCmd             = "show-proposals"
+	signProposalSubCmd              = "sign-proposal"
+	cancelProposalSubCmd            = "cancel-proposal"
+	createInvoiceSubCmd             = "create-invoice"
+	showInvoicesSubCmd              = "show-invoices"
 )
 
 const (
@@ -42,12 +62,14 @@ type createConfig struct {
 	NumPublicKeys     uint32 `long:"num-public-keys" short:"n" description:"Total number of keys" default:"1"`
 	ECDSA             bool   `long:"ecdsa" description:"Create an ECDSA wallet"`
 	Import            bool   `long:"import" short:"i" description:"Import private keys (as opposed to generating them)"`
+	BIP39Passphrase   bool   `long:"bip39-passphrase" description:"Ask for an optional bip-39 passphrase for each of the private keys"`
 	config.NetworkFlags
 }
 
 type balanceConfig struct {
 	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
 	Verbose       bool   `long:"verbose" short:"v" description:"Verbose: show addresses with balance"`
+	Account       uint32 `long:"account" description:"The account of the wallet to use" default:"0"`
 	config.NetworkFlags
 }
 
@@ -61,12 +83,14 @@ type sendConfig struct {
 	IsSendAll                bool     `long:"send-all" description:"Send all the sedra in the wallet (mutually exclusive with --send-amount)"`
 	UseExistingChangeAddress bool     `long:"use-existing-change-address" short:"u" description:"Will use an existing change address (in case no change address was ever used, it will use a new one)"`
 	Verbose                  bool     `long:"show-serialized" short:"s" description:"Show a list of hex encoded sent transactions"`
+	Account                  uint32   `long:"account" description:"The account of the wallet to use" default:"0"`
 	config.NetworkFlags
 }
 
 type sweepConfig struct {
 	PrivateKey    string `long:"private-key" short:"k" description:"Private key in hex format"`
 	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	Account       uint32 `long:"account" description:"The account of the wallet to sweep the funds to" default:"0"`
 	config.NetworkFlags
 }
 
@@ -77,6 +101,7 @@ type createUnsignedTransactionConfig struct {
 	SendAmount               string   `long:"send-amount" short:"v" description:"An amount to send in sedra (e.g. 1234.12345678)"`
 	IsSendAll                bool     `long:"send-all" description:"Send all the sedra in the wallet (mutually exclusive with --send-amount)"`
 	UseExistingChangeAddress bool     `long:"use-existing-change-address" short:"u" description:"Will use an existing change address (in case no change address was ever used, it will use a new one)"`
+	Account                  uint32   `long:"account" description:"The account of the wallet to use" default:"0"`
 	config.NetworkFlags
 }
 
@@ -85,6 +110,7 @@ type signConfig struct {
 	Password        string `long:"password" short:"p" description:"Wallet password"`
 	Transaction     string `long:"transaction" short:"t" description:"The unsigned transaction(s) to sign on (encoded in hex)"`
 	TransactionFile string `long:"transaction-file" short:"F" description:"The file containing the unsigned transaction(s) to sign on (encoded in hex)"`
+	Account         uint32 `long:"account" description:"The account of the wallet the transaction(s) spend from" default:"0"`
 	config.NetworkFlags
 }
 
@@ -92,6 +118,7 @@ type broadcastConfig struct {
 	DaemonAddress    string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
 	Transactions     string `long:"transaction" short:"t" description:"The signed transaction to broadcast (encoded in hex)"`
 	TransactionsFile string `long:"transaction-file" short:"F" description:"The file containing the unsigned transaction to sign on (encoded in hex)"`
+	Finalized        bool   `long:"finalized" description:"The transactions are finalized transactions created by the finalize command"`
 	config.NetworkFlags
 }
 
@@ -102,13 +129,161 @@ type parseConfig struct {
 	config.NetworkFlags
 }
 
+type combineConfig struct {
+	Transactions     []string `long:"transaction" short:"t" description:"A partially signed transaction (or several, of the same multisig spend) to combine, in hex or as a JSON PSST. Pass once per cosigner"`
+	TransactionFiles []string `long:"transaction-file" short:"F" description:"A file containing a partially signed transaction to combine. Pass once per cosigner"`
+	JSON             bool     `long:"json" description:"Output the combined transaction(s) as a JSON PSST instead of hex"`
+	config.NetworkFlags
+}
+
+type finalizeConfig struct {
+	Transaction     string `long:"transaction" short:"t" description:"The fully signed transaction(s) to finalize, in hex or as a JSON PSST"`
+	TransactionFile string `long:"transaction-file" short:"F" description:"The file containing the fully signed transaction(s) to finalize"`
+	config.NetworkFlags
+}
+
+type inspectConfig struct {
+	Transaction     string `long:"transaction" short:"t" description:"The partially signed transaction(s) to inspect, in hex or as a JSON PSST"`
+	TransactionFile string `long:"transaction-file" short:"F" description:"The file containing the partially signed transaction(s) to inspect"`
+	JSON            bool   `long:"json" description:"Print the transaction(s) as a JSON PSST"`
+	config.NetworkFlags
+}
+
 type showAddressesConfig struct {
 	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	Account       uint32 `long:"account" description:"The account of the wallet to use" default:"0"`
 	config.NetworkFlags
 }
 
 type newAddressConfig struct {
 	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	Account       uint32 `long:"account" description:"The account of the wallet to use" default:"0"`
+	config.NetworkFlags
+}
+
+type newVaultConfig struct {
+	DaemonAddress      string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	RecoveryAddress    string `long:"recovery-address" short:"r" description:"The public key address that can spend the vault at any time" required:"true"`
+	LockTime           uint64 `long:"lock-time" short:"l" description:"The DAA score (or, if at least 500000000000, the UNIX time in milliseconds) after which the wallet can spend the vault" required:"true"`
+	IsRelativeLockTime bool   `long:"relative" description:"Interpret --lock-time as the number of DAA scores that have to pass after each deposit"`
+	config.NetworkFlags
+}
+
+type newHTLCConfig struct {
+	DaemonAddress      string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	RecipientAddress   string `long:"recipient-address" short:"r" description:"The public key address that can claim the contract with the secret" required:"true"`
+	SecretHash         string `long:"secret-hash" short:"s" description:"Hex SHA256 of the secret (if omitted, a new secret is generated and printed)"`
+	LockTime           uint64 `long:"lock-time" short:"l" description:"The DAA score (or, if at least 500000000000, the UNIX time in milliseconds) after which the wallet can refund the contract" required:"true"`
+	IsRelativeLockTime bool   `long:"relative" description:"Interpret --lock-time as the number of DAA scores that have to pass after each deposit"`
+	config.NetworkFlags
+}
+
+type importContractConfig struct {
+	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	RedeemScript  string `long:"redeem-script" short:"s" description:"The hex redeem script of the contract" required:"true"`
+	config.NetworkFlags
+}
+
+type showContractsConfig struct {
+	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	Verbose       bool   `long:"verbose" short:"v" description:"Verbose: show the redeem scripts of the contracts"`
+	config.NetworkFlags
+}
+
+type spendContractConfig struct {
+	KeysFile        string `long:"keys-file" short:"f" description:"Keys file location (default: ~/.sedrawallet/keys.json (*nix), %USERPROFILE%\\AppData\\Local\\Sedrawallet\\key.json (Windows))"`
+	Password        string `long:"password" short:"p" description:"Wallet password"`
+	DaemonAddress   string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	ContractAddress string `long:"address" short:"a" description:"The address of the contract to spend" required:"true"`
+	ToAddress       string `long:"to-address" short:"t" description:"The public address to send the funds to (default: a new address of the current wallet)"`
+	Secret          string `long:"secret" short:"s" description:"The hex secret of a hash-timelocked contract, in order to claim it"`
+	Recover         bool   `long:"recover" description:"Spend a vault through its recovery key"`
+	config.NetworkFlags
+}
+
+type signMessageConfig struct {
+	KeysFile           string   `long:"keys-file" short:"f" description:"Keys file location (default: ~/.sedrawallet/keys.json (*nix), %USERPROFILE%\\AppData\\Local\\Sedrawallet\\key.json (Windows))"`
+	Password           string   `long:"password" short:"p" description:"Wallet password"`
+	Address            string   `long:"address" short:"a" description:"The address of the current wallet to sign the message with" required:"true"`
+	Message            string   `long:"message" short:"m" description:"The message to sign" required:"true"`
+	DerivationPath     string   `long:"derivation-path" description:"The derivation path of the address (default: searched in the wallet)"`
+	CosignerSignatures []string `long:"cosigner-signature" short:"c" description:"A partial signature of the same message by another cosigner of a multisig wallet. Use multiple times to combine several signatures"`
+	Account            uint32   `long:"account" description:"The account of the wallet the address belongs to" default:"0"`
+	config.NetworkFlags
+}
+
+type addAccountConfig struct {
+	KeysFile string `long:"keys-file" short:"f" description:"Keys file location (default: ~/.sedrawallet/keys.json (*nix), %USERPROFILE%\\AppData\\Local\\Sedrawallet\\key.json (Windows))"`
+	Password string `long:"password" short:"p" description:"Wallet password"`
+	Account  uint32 `long:"account" description:"The index of the account to add" required:"true"`
+	config.NetworkFlags
+}
+
+type changePasswordConfig struct {
+	KeysFile    string `long:"keys-file" short:"f" description:"Keys file location (default: ~/.sedrawallet/keys.json (*nix), %USERPROFILE%\\AppData\\Local\\Sedrawallet\\key.json (Windows))"`
+	Password    string `long:"password" short:"p" description:"Current wallet password"`
+	NewPassword string `long:"new-password" description:"New wallet password"`
+	KDFTime     uint32 `long:"kdf-time" description:"Number of Argon2 passes over the memory" default:"1"`
+	KDFMemory   uint32 `long:"kdf-memory" description:"Argon2 memory size in MiB" default:"64"`
+	KDFThreads  uint8  `long:"kdf-threads" description:"Number of Argon2 threads" default:"8"`
+	config.NetworkFlags
+}
+
+type rescanConfig struct {
+	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	FromDAAScore  uint64 `long:"from-daa-score" description:"Also scan the blocks from this DAA score on for payments to addresses beyond the gap limit"`
+	config.NetworkFlags
+}
+
+type proposeConfig struct {
+	DaemonAddress   string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	Transaction     string `long:"transaction" short:"t" description:"The unsigned or partially signed transaction(s) to propose, in hex or as a JSON PSST"`
+	TransactionFile string `long:"transaction-file" short:"F" description:"The file containing the transaction(s) to propose"`
+	Description     string `long:"description" description:"A description of the proposal for the other cosigners"`
+	config.NetworkFlags
+}
+
+type showProposalsConfig struct {
+	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	ID            string `long:"id" description:"Show only the proposal with this ID"`
+	config.NetworkFlags
+}
+
+type signProposalConfig struct {
+	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	KeysFile      string `long:"keys-file" short:"f" description:"Keys file location (default: ~/.sedrawallet/keys.json (*nix), %USERPROFILE%\\AppData\\Local\\Sedrawallet\\key.json (Windows))"`
+	Password      string `long:"password" short:"p" description:"Wallet password"`
+	ID            string `long:"id" description:"The ID of the proposal to sign" required:"true"`
+	Account       uint32 `long:"account" description:"The account of the wallet the proposal spends from" default:"0"`
+	config.NetworkFlags
+}
+
+type cancelProposalConfig struct {
+	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	ID            string `long:"id" description:"The ID of the proposal to cancel" required:"true"`
+	config.NetworkFlags
+}
+
+type createInvoiceConfig struct {
+	DaemonAddress string        `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	Amount        string        `long:"amount" short:"v" description:"The requested amount in sedra (e.g. 1234.12345678). If omitted, the payer chooses the amount"`
+	Label         string        `long:"label" short:"l" description:"A label for the payee, e.g. the name of the shop"`
+	Message       string        `long:"message" short:"m" description:"A message that describes the payment to the payer"`
+	ExpiresIn     time.Duration `long:"expires-in" short:"e" description:"The time until the invoice expires (e.g. 30m or 24h). If omitted, the invoice doesn't expire"`
+	Account       uint32        `long:"account" description:"The account of the wallet to receive the payment to" default:"0"`
+	config.NetworkFlags
+}
+
+type showInvoicesConfig struct {
+	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	Address       string `long:"address" short:"a" description:"Show only the invoice of this address"`
+	config.NetworkFlags
+}
+
+type verifyMessageConfig struct {
+	Address   string `long:"address" short:"a" description:"The address that signed the message" required:"true"`
+	Message   string `long:"message" short:"m" description:"The signed message" required:"true"`
+	Signature string `long:"signature" short:"s" description:"The hex signature of the message" required:"true"`
 	config.NetworkFlags
 }
 
@@ -119,6 +294,7 @@ type startDaemonConfig struct {
 	Listen    string `long:"listen" short:"l" description:"Address to listen on (default: 0.0.0.0:8082)"`
 	Timeout   uint32 `long:"wait-timeout" short:"w" description:"Waiting timeout for RPC calls, seconds (default: 30 s)"`
 	Profile   string `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
+	GapLimit  uint32 `long:"gap-limit" description:"Number of unused addresses after the last used one to scan for payments" default:"1000"`
 	config.NetworkFlags
 }
 
@@ -184,6 +360,102 @@ func parseCommandLine() (subCommand string, config interface{}) {
 		RPCServer: defaultRPCServer,
 		Listen:    defaultListen,
 	}
+	newVaultConf := &newVaultConfig{DaemonAddress: defaultListen}
+	parser.AddCommand(newVaultSubCmd, "Creates a timelocked vault",
+		"Creates a vault that the current wallet can spend once its lock time has passed, and that the "+
+			"recovery address can spend at any time", newVaultConf)
+
+	newHTLCConf := &newHTLCConfig{DaemonAddress: defaultListen}
+	parser.AddCommand(newHTLCSubCmd, "Creates a hash-timelocked contract",
+		"Creates a contract that the recipient address can claim with a secret, and that the current "+
+			"wallet can refund once its lock time has passed", newHTLCConf)
+
+	importContractConf := &importContractConfig{DaemonAddress: defaultListen}
+	parser.AddCommand(importContractSubCmd, "Imports a contract created by another wallet",
+		"Imports a vault or hash-timelocked contract in which one of the keys of the current wallet can spend",
+		importContractConf)
+
+	showContractsConf := &showContractsConfig{DaemonAddress: defaultListen}
+	parser.AddCommand(showContractsSubCmd, "Shows the contracts tracked by the current wallet",
+		"Shows the contracts tracked by the current wallet", showContractsConf)
+
+	spendContractConf := &spendContractConfig{DaemonAddress: defaultListen}
+	parser.AddCommand(spendContractSubCmd, "Spends the funds of a contract",
+		"Claims, refunds or recovers the funds of a contract tracked by the current wallet", spendContractConf)
+
+	signMessageConf := &signMessageConfig{}
+	parser.AddCommand(signMessageSubCmd, "Signs a message with the key of an address",
+		"Signs a message with the key of an address of the current wallet, in order to prove its control "+
+			"over the address. Cosigners of a multisig wallet sign in turns, each passing the partial signatures "+
+			"of the previous ones with --cosigner-signature", signMessageConf)
+
+	verifyMessageConf := &verifyMessageConfig{}
+	parser.AddCommand(verifyMessageSubCmd, "Verifies a message signature",
+		"Verifies that a message was signed by the key, or enough of the multisig keys, of an address",
+		verifyMessageConf)
+
+	addAccountConf := &addAccountConfig{}
+	parser.AddCommand(addAccountSubCmd, "Adds an account to the wallet",
+		"Derives the public keys of another account of the wallet and adds it to the keys file. "+
+			"The wallet daemon has to be restarted in order to use the new account", addAccountConf)
+
+	changePasswordConf := &changePasswordConfig{}
+	parser.AddCommand(changePasswordSubCmd, "Changes the wallet password",
+		"Re-encrypts the private keys of the wallet with a new password and, optionally, stronger Argon2 "+
+			"parameters. The previous keys file is kept as a backup. This also upgrades keys files of older "+
+			"formats, for which the new password may be the same as the current one", changePasswordConf)
+
+	combineConf := &combineConfig{}
+	parser.AddCommand(combineSubCmd, "Combines the signatures of partially signed transactions",
+		"Combines copies of the same partially signed transaction, each signed by different cosigners, "+
+			"into a single PSST", combineConf)
+
+	finalizeConf := &finalizeConfig{}
+	parser.AddCommand(finalizeSubCmd, "Finalizes fully signed transactions",
+		"Builds and verifies the signature scripts of fully signed transactions, and prints the transactions "+
+			"in the form that `broadcast --finalized` expects", finalizeConf)
+
+	inspectConf := &inspectConfig{}
+	parser.AddCommand(inspectSubCmd, "Shows the details of partially signed transactions",
+		"Shows the inputs, outputs, fee and signatures of partially signed transactions, or prints them "+
+			"as JSON PSSTs", inspectConf)
+
+	rescanConf := &rescanConfig{DaemonAddress: defaultListen}
+	parser.AddCommand(rescanSubCmd, "Rescans the addresses of the wallet",
+		"Makes the wallet daemon forget its sync state and rediscover the used addresses of the wallet. "+
+			"With --from-daa-score it also scans the blocks from the given DAA score on, in order to find "+
+			"payments to addresses beyond the gap limit", rescanConf)
+
+	proposeConf := &proposeConfig{DaemonAddress: defaultListen}
+	parser.AddCommand(proposeSubCmd, "Proposes a multisig transaction to the other cosigners",
+		"Hosts an unsigned, or partially signed, transaction of a multisig wallet on the wallet daemon, "+
+			"where the other cosigners can sign it with sign-proposal. The daemon broadcasts it once it "+
+			"has enough signatures", proposeConf)
+
+	showProposalsConf := &showProposalsConfig{DaemonAddress: defaultListen}
+	parser.AddCommand(showProposalsSubCmd, "Shows the multisig proposals of the wallet daemon",
+		"Shows the multisig proposals hosted by the wallet daemon, and which cosigners signed them",
+		showProposalsConf)
+
+	signProposalConf := &signProposalConfig{DaemonAddress: defaultListen}
+	parser.AddCommand(signProposalSubCmd, "Signs a multisig proposal",
+		"Fetches a multisig proposal from the wallet daemon, signs it with the local keys file, and sends "+
+			"the signatures back to the daemon", signProposalConf)
+
+	cancelProposalConf := &cancelProposalConfig{DaemonAddress: defaultListen}
+	parser.AddCommand(cancelProposalSubCmd, "Cancels a multisig proposal",
+		"Removes a multisig proposal from the wallet daemon", cancelProposalConf)
+
+	createInvoiceConf := &createInvoiceConfig{DaemonAddress: defaultListen}
+	parser.AddCommand(createInvoiceSubCmd, "Creates an invoice with a payment URI",
+		"Allocates a new receive address for a payment request, and prints its payment URI, which can be "+
+			"handed to the payer as-is or as a QR code. The wallet daemon marks the invoice as paid once the "+
+			"address receives the requested amount", createInvoiceConf)
+
+	showInvoicesConf := &showInvoicesConfig{DaemonAddress: defaultListen}
+	parser.AddCommand(showInvoicesSubCmd, "Shows the invoices of the wallet daemon",
+		"Shows the invoices created by the wallet daemon, and whether they were paid", showInvoicesConf)
+
 	parser.AddCommand(startDaemonSubCmd, "Start the wallet daemon", "Start the wallet daemon", startDaemonConf)
 
 	_, err := parser.Parse()
@@ -290,6 +562,139 @@ func parseCommandLine() (subCommand string, config interface{}) {
 			printErrorAndExit(err)
 		}
 		config = startDaemonConf
+	case newVaultSubCmd:
+		combineNetworkFlags(&newVaultConf.NetworkFlags, &cfg.NetworkFlags)
+		err := newVaultConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = newVaultConf
+	case newHTLCSubCmd:
+		combineNetworkFlags(&newHTLCConf.NetworkFlags, &cfg.NetworkFlags)
+		err := newHTLCConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = newHTLCConf
+	case importContractSubCmd:
+		combineNetworkFlags(&importContractConf.NetworkFlags, &cfg.NetworkFlags)
+		err := importContractConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = importContractConf
+	case showContractsSubCmd:
+		combineNetworkFlags(&showContractsConf.NetworkFlags, &cfg.NetworkFlags)
+		err := showContractsConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = showContractsConf
+	case spendContractSubCmd:
+		combineNetworkFlags(&spendContractConf.NetworkFlags, &cfg.NetworkFlags)
+		err := spendContractConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = spendContractConf
+	case signMessageSubCmd:
+		combineNetworkFlags(&signMessageConf.NetworkFlags, &cfg.NetworkFlags)
+		err := signMessageConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = signMessageConf
+	case verifyMessageSubCmd:
+		combineNetworkFlags(&verifyMessageConf.NetworkFlags, &cfg.NetworkFlags)
+		err := verifyMessageConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = verifyMessageConf
+	case addAccountSubCmd:
+		combineNetworkFlags(&addAccountConf.NetworkFlags, &cfg.NetworkFlags)
+		err := addAccountConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = addAccountConf
+	case changePasswordSubCmd:
+		combineNetworkFlags(&changePasswordConf.NetworkFlags, &cfg.NetworkFlags)
+		err := changePasswordConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = changePasswordConf
+	case combineSubCmd:
+		combineNetworkFlags(&combineConf.NetworkFlags, &cfg.NetworkFlags)
+		err := combineConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = combineConf
+	case finalizeSubCmd:
+		combineNetworkFlags(&finalizeConf.NetworkFlags, &cfg.NetworkFlags)
+		err := finalizeConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = finalizeConf
+	case inspectSubCmd:
+		combineNetworkFlags(&inspectConf.NetworkFlags, &cfg.NetworkFlags)
+		err := inspectConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = inspectConf
+	case rescanSubCmd:
+		combineNetworkFlags(&rescanConf.NetworkFlags, &cfg.NetworkFlags)
+		err := rescanConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = rescanConf
+	case proposeSubCmd:
+		combineNetworkFlags(&proposeConf.NetworkFlags, &cfg.NetworkFlags)
+		err := proposeConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = proposeConf
+	case showProposalsSubCmd:
+		combineNetworkFlags(&showProposalsConf.NetworkFlags, &cfg.NetworkFlags)
+		err := showProposalsConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = showProposalsConf
+	case signProposalSubCmd:
+		combineNetworkFlags(&signProposalConf.NetworkFlags, &cfg.NetworkFlags)
+		err := signProposalConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = signProposalConf
+	case cancelProposalSubCmd:
+		combineNetworkFlags(&cancelProposalConf.NetworkFlags, &cfg.NetworkFlags)
+		err := cancelProposalConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = cancelProposalConf
+	case createInvoiceSubCmd:
+		combineNetworkFlags(&createInvoiceConf.NetworkFlags, &cfg.NetworkFlags)
+		err := createInvoiceConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = createInvoiceConf
+	case showInvoicesSubCmd:
+		combineNetworkFlags(&showInvoicesConf.NetworkFlags, &cfg.NetworkFlags)
+		err := showInvoicesConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = showInvoicesConf
 	}
 
 	return parser.Command.Active.Name, config
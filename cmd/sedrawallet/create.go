@@ -19,9 +19,11 @@ func create(conf *createConfig) error {
 	var err error
 	isMultisig := conf.NumPublicKeys > 1
 	if !conf.Import {
-		encryptedMnemonics, signerExtendedPublicKeys, err = keys.CreateMnemonics(conf.NetParams(), conf.NumPrivateKeys, conf.Password, isMultisig)
+		encryptedMnemonics, signerExtendedPublicKeys, err = keys.CreateMnemonics(conf.NetParams(), conf.NumPrivateKeys, conf.Password,
+			isMultisig, conf.BIP39Passphrase)
 	} else {
-		encryptedMnemonics, signerExtendedPublicKeys, err = keys.ImportMnemonics(conf.NetParams(), conf.NumPrivateKeys, conf.Password, isMultisig)
+		encryptedMnemonics, signerExtendedPublicKeys, err = keys.ImportMnemonics(conf.NetParams(), conf.NumPrivateKeys, conf.Password,
+			isMultisig, conf.BIP39Passphrase)
 	}
 	if err != nil {
 		return err
@@ -66,6 +68,7 @@ func create(conf *createConfig) error {
 
 	file := keys.File{
 		Version:            keys.LastVersion,
+		KDFParams:          keys.DefaultKDFParams(),
 		EncryptedMnemonics: encryptedMnemonics,
 		ExtendedPublicKeys: extendedPublicKeys,
 		MinimumSignatures:  conf.MinimumSignatures,
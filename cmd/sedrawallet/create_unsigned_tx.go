@@ -32,6 +32,7 @@ func createUnsignedTransaction(conf *createUnsignedTransactionConfig) error {
 		Amount:                   sendAmountSeep,
 		IsSendAll:                conf.IsSendAll,
 		UseExistingChangeAddress: conf.UseExistingChangeAddress,
+		Account:                  conf.Account,
 	})
 	if err != nil {
 		return err
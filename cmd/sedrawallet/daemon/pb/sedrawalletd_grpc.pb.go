@@ -29,6 +29,19 @@ type SedrawalletdClient interface {
 	Send(ctx context.Context, in *SendRequest, opts ...grpc.CallOption) (*SendResponse, error)
 	// Since SignRequest contains a password - this command should only be used on a trusted or secure connection
 	Sign(ctx context.Context, in *SignRequest, opts ...grpc.CallOption) (*SignResponse, error)
+	NewTimeLockedVault(ctx context.Context, in *NewTimeLockedVaultRequest, opts ...grpc.CallOption) (*NewContractResponse, error)
+	NewHashTimeLockedContract(ctx context.Context, in *NewHashTimeLockedContractRequest, opts ...grpc.CallOption) (*NewContractResponse, error)
+	ImportContract(ctx context.Context, in *ImportContractRequest, opts ...grpc.CallOption) (*ImportContractResponse, error)
+	ShowContracts(ctx context.Context, in *ShowContractsRequest, opts ...grpc.CallOption) (*ShowContractsResponse, error)
+	CreateUnsignedContractSpend(ctx context.Context, in *CreateUnsignedContractSpendRequest, opts ...grpc.CallOption) (*CreateUnsignedTransactionsResponse, error)
+	Rescan(ctx context.Context, in *RescanRequest, opts ...grpc.CallOption) (*RescanResponse, error)
+	CreateMultisigProposal(ctx context.Context, in *CreateMultisigProposalRequest, opts ...grpc.CallOption) (*MultisigProposalResponse, error)
+	GetMultisigProposals(ctx context.Context, in *GetMultisigProposalsRequest, opts ...grpc.CallOption) (*GetMultisigProposalsResponse, error)
+	// If SignMultisigProposalRequest contains a password - this command should only be used on a trusted or secure connection
+	SignMultisigProposal(ctx context.Context, in *SignMultisigProposalRequest, opts ...grpc.CallOption) (*MultisigProposalResponse, error)
+	CancelMultisigProposal(ctx context.Context, in *CancelMultisigProposalRequest, opts ...grpc.CallOption) (*CancelMultisigProposalResponse, error)
+	CreateInvoice(ctx context.Context, in *CreateInvoiceRequest, opts ...grpc.CallOption) (*InvoiceResponse, error)
+	GetInvoices(ctx context.Context, in *GetInvoicesRequest, opts ...grpc.CallOption) (*GetInvoicesResponse, error)
 }
 
 type sedrawalletdClient struct {
@@ -120,6 +133,114 @@ func (c *sedrawalletdClient) Sign(ctx context.Context, in *SignRequest, opts ...
 	return out, nil
 }
 
+func (c *sedrawalletdClient) NewTimeLockedVault(ctx context.Context, in *NewTimeLockedVaultRequest, opts ...grpc.CallOption) (*NewContractResponse, error) {
+	out := new(NewContractResponse)
+	err := c.cc.Invoke(ctx, "/sedrawalletd.sedrawalletd/NewTimeLockedVault", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sedrawalletdClient) NewHashTimeLockedContract(ctx context.Context, in *NewHashTimeLockedContractRequest, opts ...grpc.CallOption) (*NewContractResponse, error) {
+	out := new(NewContractResponse)
+	err := c.cc.Invoke(ctx, "/sedrawalletd.sedrawalletd/NewHashTimeLockedContract", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sedrawalletdClient) ImportContract(ctx context.Context, in *ImportContractRequest, opts ...grpc.CallOption) (*ImportContractResponse, error) {
+	out := new(ImportContractResponse)
+	err := c.cc.Invoke(ctx, "/sedrawalletd.sedrawalletd/ImportContract", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sedrawalletdClient) ShowContracts(ctx context.Context, in *ShowContractsRequest, opts ...grpc.CallOption) (*ShowContractsResponse, error) {
+	out := new(ShowContractsResponse)
+	err := c.cc.Invoke(ctx, "/sedrawalletd.sedrawalletd/ShowContracts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sedrawalletdClient) CreateUnsignedContractSpend(ctx context.Context, in *CreateUnsignedContractSpendRequest, opts ...grpc.CallOption) (*CreateUnsignedTransactionsResponse, error) {
+	out := new(CreateUnsignedTransactionsResponse)
+	err := c.cc.Invoke(ctx, "/sedrawalletd.sedrawalletd/CreateUnsignedContractSpend", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sedrawalletdClient) Rescan(ctx context.Context, in *RescanRequest, opts ...grpc.CallOption) (*RescanResponse, error) {
+	out := new(RescanResponse)
+	err := c.cc.Invoke(ctx, "/sedrawalletd.sedrawalletd/Rescan", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sedrawalletdClient) CreateMultisigProposal(ctx context.Context, in *CreateMultisigProposalRequest, opts ...grpc.CallOption) (*MultisigProposalResponse, error) {
+	out := new(MultisigProposalResponse)
+	err := c.cc.Invoke(ctx, "/sedrawalletd.sedrawalletd/CreateMultisigProposal", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sedrawalletdClient) GetMultisigProposals(ctx context.Context, in *GetMultisigProposalsRequest, opts ...grpc.CallOption) (*GetMultisigProposalsResponse, error) {
+	out := new(GetMultisigProposalsResponse)
+	err := c.cc.Invoke(ctx, "/sedrawalletd.sedrawalletd/GetMultisigProposals", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sedrawalletdClient) SignMultisigProposal(ctx context.Context, in *SignMultisigProposalRequest, opts ...grpc.CallOption) (*MultisigProposalResponse, error) {
+	out := new(MultisigProposalResponse)
+	err := c.cc.Invoke(ctx, "/sedrawalletd.sedrawalletd/SignMultisigProposal", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sedrawalletdClient) CancelMultisigProposal(ctx context.Context, in *CancelMultisigProposalRequest, opts ...grpc.CallOption) (*CancelMultisigProposalResponse, error) {
+	out := new(CancelMultisigProposalResponse)
+	err := c.cc.Invoke(ctx, "/sedrawalletd.sedrawalletd/CancelMultisigProposal", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sedrawalletdClient) CreateInvoice(ctx context.Context, in *CreateInvoiceRequest, opts ...grpc.CallOption) (*InvoiceResponse, error) {
+	out := new(InvoiceResponse)
+	err := c.cc.Invoke(ctx, "/sedrawalletd.sedrawalletd/CreateInvoice", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sedrawalletdClient) GetInvoices(ctx context.Context, in *GetInvoicesRequest, opts ...grpc.CallOption) (*GetInvoicesResponse, error) {
+	out := new(GetInvoicesResponse)
+	err := c.cc.Invoke(ctx, "/sedrawalletd.sedrawalletd/GetInvoices", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SedrawalletdServer is the server API for Sedrawalletd service.
 // All implementations must embed UnimplementedSedrawalletdServer
 // for forward compatibility
@@ -135,6 +256,19 @@ type SedrawalletdServer interface {
 	Send(context.Context, *SendRequest) (*SendResponse, error)
 	// Since SignRequest contains a password - this command should only be used on a trusted or secure connection
 	Sign(context.Context, *SignRequest) (*SignResponse, error)
+	NewTimeLockedVault(context.Context, *NewTimeLockedVaultRequest) (*NewContractResponse, error)
+	NewHashTimeLockedContract(context.Context, *NewHashTimeLockedContractRequest) (*NewContractResponse, error)
+	ImportContract(context.Context, *ImportContractRequest) (*ImportContractResponse, error)
+	ShowContracts(context.Context, *ShowContractsRequest) (*ShowContractsResponse, error)
+	CreateUnsignedContractSpend(context.Context, *CreateUnsignedContractSpendRequest) (*CreateUnsignedTransactionsResponse, error)
+	Rescan(context.Context, *RescanRequest) (*RescanResponse, error)
+	CreateMultisigProposal(context.Context, *CreateMultisigProposalRequest) (*MultisigProposalResponse, error)
+	GetMultisigProposals(context.Context, *GetMultisigProposalsRequest) (*GetMultisigProposalsResponse, error)
+	// If SignMultisigProposalRequest contains a password - this command should only be used on a trusted or secure connection
+	SignMultisigProposal(context.Context, *SignMultisigProposalRequest) (*MultisigProposalResponse, error)
+	CancelMultisigProposal(context.Context, *CancelMultisigProposalRequest) (*CancelMultisigProposalResponse, error)
+	CreateInvoice(context.Context, *CreateInvoiceRequest) (*InvoiceResponse, error)
+	GetInvoices(context.Context, *GetInvoicesRequest) (*GetInvoicesResponse, error)
 	mustEmbedUnimplementedSedrawalletdServer()
 }
 
@@ -169,6 +303,42 @@ func (UnimplementedSedrawalletdServer) Send(context.Context, *SendRequest) (*Sen
 func (UnimplementedSedrawalletdServer) Sign(context.Context, *SignRequest) (*SignResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Sign not implemented")
 }
+func (UnimplementedSedrawalletdServer) NewTimeLockedVault(context.Context, *NewTimeLockedVaultRequest) (*NewContractResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NewTimeLockedVault not implemented")
+}
+func (UnimplementedSedrawalletdServer) NewHashTimeLockedContract(context.Context, *NewHashTimeLockedContractRequest) (*NewContractResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NewHashTimeLockedContract not implemented")
+}
+func (UnimplementedSedrawalletdServer) ImportContract(context.Context, *ImportContractRequest) (*ImportContractResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportContract not implemented")
+}
+func (UnimplementedSedrawalletdServer) ShowContracts(context.Context, *ShowContractsRequest) (*ShowContractsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ShowContracts not implemented")
+}
+func (UnimplementedSedrawalletdServer) CreateUnsignedContractSpend(context.Context, *CreateUnsignedContractSpendRequest) (*CreateUnsignedTransactionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateUnsignedContractSpend not implemented")
+}
+func (UnimplementedSedrawalletdServer) Rescan(context.Context, *RescanRequest) (*RescanResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Rescan not implemented")
+}
+func (UnimplementedSedrawalletdServer) CreateMultisigProposal(context.Context, *CreateMultisigProposalRequest) (*MultisigProposalResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateMultisigProposal not implemented")
+}
+func (UnimplementedSedrawalletdServer) GetMultisigProposals(context.Context, *GetMultisigProposalsRequest) (*GetMultisigProposalsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMultisigProposals not implemented")
+}
+func (UnimplementedSedrawalletdServer) SignMultisigProposal(context.Context, *SignMultisigProposalRequest) (*MultisigProposalResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SignMultisigProposal not implemented")
+}
+func (UnimplementedSedrawalletdServer) CancelMultisigProposal(context.Context, *CancelMultisigProposalRequest) (*CancelMultisigProposalResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelMultisigProposal not implemented")
+}
+func (UnimplementedSedrawalletdServer) CreateInvoice(context.Context, *CreateInvoiceRequest) (*InvoiceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateInvoice not implemented")
+}
+func (UnimplementedSedrawalletdServer) GetInvoices(context.Context, *GetInvoicesRequest) (*GetInvoicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInvoices not implemented")
+}
 func (UnimplementedSedrawalletdServer) mustEmbedUnimplementedSedrawalletdServer() {}
 
 // UnsafeSedrawalletdServer may be embedded to opt out of forward compatibility for this service.
@@ -344,6 +514,222 @@ func _Sedrawalletd_Sign_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Sedrawalletd_NewTimeLockedVault_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NewTimeLockedVaultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SedrawalletdServer).NewTimeLockedVault(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sedrawalletd.sedrawalletd/NewTimeLockedVault",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SedrawalletdServer).NewTimeLockedVault(ctx, req.(*NewTimeLockedVaultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sedrawalletd_NewHashTimeLockedContract_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NewHashTimeLockedContractRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SedrawalletdServer).NewHashTimeLockedContract(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sedrawalletd.sedrawalletd/NewHashTimeLockedContract",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SedrawalletdServer).NewHashTimeLockedContract(ctx, req.(*NewHashTimeLockedContractRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sedrawalletd_ImportContract_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportContractRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SedrawalletdServer).ImportContract(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sedrawalletd.sedrawalletd/ImportContract",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SedrawalletdServer).ImportContract(ctx, req.(*ImportContractRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sedrawalletd_ShowContracts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShowContractsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SedrawalletdServer).ShowContracts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sedrawalletd.sedrawalletd/ShowContracts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SedrawalletdServer).ShowContracts(ctx, req.(*ShowContractsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sedrawalletd_CreateUnsignedContractSpend_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateUnsignedContractSpendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SedrawalletdServer).CreateUnsignedContractSpend(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sedrawalletd.sedrawalletd/CreateUnsignedContractSpend",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SedrawalletdServer).CreateUnsignedContractSpend(ctx, req.(*CreateUnsignedContractSpendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sedrawalletd_Rescan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RescanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SedrawalletdServer).Rescan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sedrawalletd.sedrawalletd/Rescan",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SedrawalletdServer).Rescan(ctx, req.(*RescanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sedrawalletd_CreateMultisigProposal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateMultisigProposalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SedrawalletdServer).CreateMultisigProposal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sedrawalletd.sedrawalletd/CreateMultisigProposal",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SedrawalletdServer).CreateMultisigProposal(ctx, req.(*CreateMultisigProposalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sedrawalletd_GetMultisigProposals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMultisigProposalsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SedrawalletdServer).GetMultisigProposals(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sedrawalletd.sedrawalletd/GetMultisigProposals",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SedrawalletdServer).GetMultisigProposals(ctx, req.(*GetMultisigProposalsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sedrawalletd_SignMultisigProposal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignMultisigProposalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SedrawalletdServer).SignMultisigProposal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sedrawalletd.sedrawalletd/SignMultisigProposal",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SedrawalletdServer).SignMultisigProposal(ctx, req.(*SignMultisigProposalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sedrawalletd_CancelMultisigProposal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelMultisigProposalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SedrawalletdServer).CancelMultisigProposal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sedrawalletd.sedrawalletd/CancelMultisigProposal",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SedrawalletdServer).CancelMultisigProposal(ctx, req.(*CancelMultisigProposalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sedrawalletd_CreateInvoice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateInvoiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SedrawalletdServer).CreateInvoice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sedrawalletd.sedrawalletd/CreateInvoice",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SedrawalletdServer).CreateInvoice(ctx, req.(*CreateInvoiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sedrawalletd_GetInvoices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInvoicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SedrawalletdServer).GetInvoices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sedrawalletd.sedrawalletd/GetInvoices",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SedrawalletdServer).GetInvoices(ctx, req.(*GetInvoicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Sedrawalletd_ServiceDesc is the grpc.ServiceDesc for Sedrawalletd service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -387,6 +773,54 @@ var Sedrawalletd_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Sign",
 			Handler:    _Sedrawalletd_Sign_Handler,
 		},
+		{
+			MethodName: "NewTimeLockedVault",
+			Handler:    _Sedrawalletd_NewTimeLockedVault_Handler,
+		},
+		{
+			MethodName: "NewHashTimeLockedContract",
+			Handler:    _Sedrawalletd_NewHashTimeLockedContract_Handler,
+		},
+		{
+			MethodName: "ImportContract",
+			Handler:    _Sedrawalletd_ImportContract_Handler,
+		},
+		{
+			MethodName: "ShowContracts",
+			Handler:    _Sedrawalletd_ShowContracts_Handler,
+		},
+		{
+			MethodName: "CreateUnsignedContractSpend",
+			Handler:    _Sedrawalletd_CreateUnsignedContractSpend_Handler,
+		},
+		{
+			MethodName: "Rescan",
+			Handler:    _Sedrawalletd_Rescan_Handler,
+		},
+		{
+			MethodName: "CreateMultisigProposal",
+			Handler:    _Sedrawalletd_CreateMultisigProposal_Handler,
+		},
+		{
+			MethodName: "GetMultisigProposals",
+			Handler:    _Sedrawalletd_GetMultisigProposals_Handler,
+		},
+		{
+			MethodName: "SignMultisigProposal",
+			Handler:    _Sedrawalletd_SignMultisigProposal_Handler,
+		},
+		{
+			MethodName: "CancelMultisigProposal",
+			Handler:    _Sedrawalletd_CancelMultisigProposal_Handler,
+		},
+		{
+			MethodName: "CreateInvoice",
+			Handler:    _Sedrawalletd_CreateInvoice_Handler,
+		},
+		{
+			MethodName: "GetInvoices",
+			Handler:    _Sedrawalletd_GetInvoices_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "sedrawalletd.proto",
@@ -10,14 +10,16 @@ import (
 	"github.com/pkg/errors"
 )
 
-func (s *server) changeAddress(useExisting bool, fromAddresses []*walletAddress) (util.Address, *walletAddress, error) {
+func (s *server) changeAddress(account uint32, useExisting bool, fromAddresses []*walletAddress) (util.Address,
+	*walletAddress, error) {
+
 	var walletAddr *walletAddress
 	if len(fromAddresses) != 0 && useExisting {
 		walletAddr = fromAddresses[0]
 	} else {
 		internalIndex := uint32(0)
 		if !useExisting {
-			err := s.keysFile.SetLastUsedInternalIndex(s.keysFile.LastUsedInternalIndex() + 1)
+			err := s.keysFile.SetLastUsedInternalIndex(account, s.keysFile.LastUsedInternalIndex(account)+1)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -27,18 +29,18 @@ func (s *server) changeAddress(useExisting bool, fromAddresses []*walletAddress)
 				return nil, nil, err
 			}
 
-			internalIndex = s.keysFile.LastUsedInternalIndex()
+			internalIndex = s.keysFile.LastUsedInternalIndex(account)
 		}
 
 		walletAddr = &walletAddress{
+			account:       account,
 			index:         internalIndex,
 			cosignerIndex: s.keysFile.CosignerIndex,
 			keyChain:      libsedrawallet.InternalKeychain,
 		}
 	}
 
-	path := s.walletAddressPath(walletAddr)
-	address, err := libsedrawallet.Address(s.params, s.keysFile.ExtendedPublicKeys, s.keysFile.MinimumSignatures, path, s.keysFile.ECDSA)
+	address, err := s.walletAddressAddress(walletAddr)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -53,19 +55,25 @@ func (s *server) ShowAddresses(_ context.Context, request *pb.ShowAddressesReque
 		return nil, errors.Errorf("wallet daemon is not synced yet, %s", s.formatSyncStateReport())
 	}
 
-	addresses := make([]string, s.keysFile.LastUsedExternalIndex())
-	for i := uint32(1); i <= s.keysFile.LastUsedExternalIndex(); i++ {
+	err := s.checkAccount(request.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	lastUsedExternalIndex := s.keysFile.LastUsedExternalIndex(request.Account)
+	addresses := make([]string, lastUsedExternalIndex)
+	for i := uint32(1); i <= lastUsedExternalIndex; i++ {
 		walletAddr := &walletAddress{
+			account:       request.Account,
 			index:         i,
 			cosignerIndex: s.keysFile.CosignerIndex,
 			keyChain:      libsedrawallet.ExternalKeychain,
 		}
-		path := s.walletAddressPath(walletAddr)
-		address, err := libsedrawallet.Address(s.params, s.keysFile.ExtendedPublicKeys, s.keysFile.MinimumSignatures, path, s.keysFile.ECDSA)
+		address, err := s.walletAddressString(walletAddr)
 		if err != nil {
 			return nil, err
 		}
-		addresses[i-1] = address.String()
+		addresses[i-1] = address
 	}
 
 	return &pb.ShowAddressesResponse{Address: addresses}, nil
@@ -79,7 +87,12 @@ func (s *server) NewAddress(_ context.Context, request *pb.NewAddressRequest) (*
 		return nil, errors.Errorf("wallet daemon is not synced yet, %s", s.formatSyncStateReport())
 	}
 
-	err := s.keysFile.SetLastUsedExternalIndex(s.keysFile.LastUsedExternalIndex() + 1)
+	err := s.checkAccount(request.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.keysFile.SetLastUsedExternalIndex(request.Account, s.keysFile.LastUsedExternalIndex(request.Account)+1)
 	if err != nil {
 		return nil, err
 	}
@@ -90,22 +103,21 @@ func (s *server) NewAddress(_ context.Context, request *pb.NewAddressRequest) (*
 	}
 
 	walletAddr := &walletAddress{
-		index:         s.keysFile.LastUsedExternalIndex(),
+		account:       request.Account,
+		index:         s.keysFile.LastUsedExternalIndex(request.Account),
 		cosignerIndex: s.keysFile.CosignerIndex,
 		keyChain:      libsedrawallet.ExternalKeychain,
 	}
-	path := s.walletAddressPath(walletAddr)
-	address, err := libsedrawallet.Address(s.params, s.keysFile.ExtendedPublicKeys, s.keysFile.MinimumSignatures, path, s.keysFile.ECDSA)
+	address, err := s.walletAddressString(walletAddr)
 	if err != nil {
 		return nil, err
 	}
 
-	return &pb.NewAddressResponse{Address: address.String()}, nil
+	return &pb.NewAddressResponse{Address: address}, nil
 }
 
 func (s *server) walletAddressString(wAddr *walletAddress) (string, error) {
-	path := s.walletAddressPath(wAddr)
-	addr, err := libsedrawallet.Address(s.params, s.keysFile.ExtendedPublicKeys, s.keysFile.MinimumSignatures, path, s.keysFile.ECDSA)
+	addr, err := s.walletAddressAddress(wAddr)
 	if err != nil {
 		return "", err
 	}
@@ -113,6 +125,16 @@ func (s *server) walletAddressString(wAddr *walletAddress) (string, error) {
 	return addr.String(), nil
 }
 
+func (s *server) walletAddressAddress(wAddr *walletAddress) (util.Address, error) {
+	extendedPublicKeys, err := s.keysFile.AccountExtendedPublicKeys(wAddr.account)
+	if err != nil {
+		return nil, err
+	}
+
+	path := s.walletAddressPath(wAddr)
+	return libsedrawallet.Address(s.params, extendedPublicKeys, s.keysFile.MinimumSignatures, path, s.keysFile.ECDSA)
+}
+
 func (s *server) walletAddressPath(wAddr *walletAddress) string {
 	if s.isMultisig() {
 		return fmt.Sprintf("m/%d/%d/%d", wAddr.cosignerIndex, wAddr.keyChain, wAddr.index)
@@ -123,3 +145,13 @@ func (s *server) walletAddressPath(wAddr *walletAddress) string {
 func (s *server) isMultisig() bool {
 	return len(s.keysFile.ExtendedPublicKeys) > 1
 }
+
+// checkAccount returns an error if the given account doesn't exist in the keys file
+func (s *server) checkAccount(account uint32) error {
+	_, err := s.keysFile.AccountExtendedPublicKeys(account)
+	if err != nil {
+		return errors.Errorf("account %d doesn't exist in the keys file. Add it with "+
+			"`sedrawallet add-account` and restart the daemon", account)
+	}
+	return nil
+}
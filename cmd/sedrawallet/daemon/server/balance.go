@@ -4,16 +4,20 @@ import (
 	"context"
 
 	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/pb"
-	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
 )
 
 type balancesType struct{ available, pending uint64 }
 type balancesMapType map[*walletAddress]*balancesType
 
-func (s *server) GetBalance(_ context.Context, _ *pb.GetBalanceRequest) (*pb.GetBalanceResponse, error) {
+func (s *server) GetBalance(_ context.Context, request *pb.GetBalanceRequest) (*pb.GetBalanceResponse, error) {
 	s.lock.RLock()
 	defer s.lock.RUnlock()
 
+	err := s.checkAccount(request.Account)
+	if err != nil {
+		return nil, err
+	}
+
 	dagInfo, err := s.rpcClient.GetBlockDAGInfo()
 	if err != nil {
 		return nil, err
@@ -23,6 +27,9 @@ func (s *server) GetBalance(_ context.Context, _ *pb.GetBalanceRequest) (*pb.Get
 
 	balancesMap := make(balancesMapType, 0)
 	for _, entry := range s.utxosSortedByAmount {
+		if entry.address.account != request.Account {
+			continue
+		}
 		amount := entry.UTXOEntry.Amount()
 		address := entry.address
 		balances, ok := balancesMap[address]
@@ -41,12 +48,12 @@ func (s *server) GetBalance(_ context.Context, _ *pb.GetBalanceRequest) (*pb.Get
 	i := 0
 	var available, pending uint64
 	for walletAddress, balances := range balancesMap {
-		address, err := libsedrawallet.Address(s.params, s.keysFile.ExtendedPublicKeys, s.keysFile.MinimumSignatures, s.walletAddressPath(walletAddress), s.keysFile.ECDSA)
+		address, err := s.walletAddressString(walletAddress)
 		if err != nil {
 			return nil, err
 		}
 		addressBalances[i] = &pb.AddressBalances{
-			Address:   address.String(),
+			Address:   address,
 			Available: balances.available,
 			Pending:   balances.pending,
 		}
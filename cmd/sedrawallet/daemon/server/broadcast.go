@@ -7,7 +7,6 @@ import (
 	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
 	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet/serialization"
 	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
-	"github.com/sedracoin/sedrad/infrastructure/network/rpcclient"
 	"github.com/pkg/errors"
 	"time"
 )
@@ -62,7 +61,7 @@ func (s *server) broadcast(transactions [][]byte, isDomain bool) ([]string, erro
 	return txIDs, nil
 }
 
-func sendTransaction(client *rpcclient.RPCClient, tx *externalapi.DomainTransaction) (string, error) {
+func sendTransaction(client daemonRPCClient, tx *externalapi.DomainTransaction) (string, error) {
 	submitTransactionResponse, err := client.SubmitTransaction(appmessage.DomainTransactionToRPCTransaction(tx), false)
 	if err != nil {
 		return "", errors.Wrapf(err, "error submitting transaction")
@@ -9,6 +9,7 @@ type walletUTXO struct {
 }
 
 type walletAddress struct {
+	account       uint32
 	index         uint32
 	cosignerIndex uint32
 	keyChain      uint8
@@ -0,0 +1,486 @@
+package server
+
+import (
+	"context"
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/pb"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/keys"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet/serialization"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/constants"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
+	"github.com/sedracoin/sedrad/domain/miningmanager/mempool"
+	"github.com/sedracoin/sedrad/util"
+	"github.com/sedracoin/sedrad/util/txfee"
+)
+
+func (s *server) NewTimeLockedVault(_ context.Context, request *pb.NewTimeLockedVaultRequest) (*pb.NewContractResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	recoveryAddress, err := util.DecodeAddress(request.RecoveryAddress, s.params.Prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	timeLock := txscript.TimeLock{Value: request.LockTime, IsRelative: request.IsRelativeLockTime}
+	return s.newContract(func(path string) (*libsedrawallet.Contract, error) {
+		return libsedrawallet.NewTimeLockedVault(s.keysFile.ExtendedPublicKeys[0], path, recoveryAddress, timeLock,
+			s.keysFile.ECDSA)
+	})
+}
+
+func (s *server) NewHashTimeLockedContract(_ context.Context, request *pb.NewHashTimeLockedContractRequest) (
+	*pb.NewContractResponse, error) {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	recipientAddress, err := util.DecodeAddress(request.RecipientAddress, s.params.Prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	secretHash, err := hex.DecodeString(request.SecretHash)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode the secret hash")
+	}
+
+	timeLock := txscript.TimeLock{Value: request.LockTime, IsRelative: request.IsRelativeLockTime}
+	return s.newContract(func(path string) (*libsedrawallet.Contract, error) {
+		return libsedrawallet.NewHashTimeLockedContract(s.keysFile.ExtendedPublicKeys[0], path, recipientAddress,
+			secretHash, timeLock, s.keysFile.ECDSA)
+	})
+}
+
+// newContract creates a contract with the key of a new external address of the
+// wallet, and starts tracking it. Contracts always use the keys of the first
+// account of the wallet.
+func (s *server) newContract(createContract func(path string) (*libsedrawallet.Contract, error)) (
+	*pb.NewContractResponse, error) {
+
+	err := s.checkContractsSupported()
+	if err != nil {
+		return nil, err
+	}
+
+	walletAddr := &walletAddress{
+		index:         s.keysFile.LastUsedExternalIndex(0) + 1,
+		cosignerIndex: s.keysFile.CosignerIndex,
+		keyChain:      libsedrawallet.ExternalKeychain,
+	}
+	path := s.walletAddressPath(walletAddr)
+	contract, err := createContract(path)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.keysFile.SetLastUsedExternalIndex(0, walletAddr.index)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := s.trackContract(contract, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.NewContractResponse{
+		Address:      address.String(),
+		RedeemScript: hex.EncodeToString(contract.RedeemScript),
+	}, nil
+}
+
+func (s *server) ImportContract(_ context.Context, request *pb.ImportContractRequest) (*pb.ImportContractResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	err := s.checkContractsSupported()
+	if err != nil {
+		return nil, err
+	}
+
+	redeemScript, err := hex.DecodeString(request.RedeemScript)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode the redeem script")
+	}
+
+	contract, err := libsedrawallet.ParseContract(redeemScript)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := s.findContractKeyPath(contract)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := s.trackContract(contract, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ImportContractResponse{Address: address.String()}, nil
+}
+
+func (s *server) trackContract(contract *libsedrawallet.Contract, path string) (util.Address, error) {
+	address, err := contract.Address(s.params)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.keysFile.AddContract(&keys.Contract{
+		RedeemScript:   contract.RedeemScript,
+		DerivationPath: path,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Tracking %s contract %s", contract.Type(), address)
+	return address, nil
+}
+
+// findContractKeyPath returns the derivation path of the wallet key that takes
+// part in the given contract, out of the keys of the wallet's used addresses
+func (s *server) findContractKeyPath(contract *libsedrawallet.Contract) (string, error) {
+	lastUsedIndexes := map[uint8]uint32{
+		libsedrawallet.ExternalKeychain: s.keysFile.LastUsedExternalIndex(0),
+		libsedrawallet.InternalKeychain: s.keysFile.LastUsedInternalIndex(0),
+	}
+	for _, keyChain := range keyChains {
+		for index := uint32(0); index <= lastUsedIndexes[keyChain]; index++ {
+			path := s.walletAddressPath(&walletAddress{
+				index:         index,
+				cosignerIndex: s.keysFile.CosignerIndex,
+				keyChain:      keyChain,
+			})
+			for _, branch := range []libsedrawallet.ContractBranch{
+				libsedrawallet.ContractBranchImmediate, libsedrawallet.ContractBranchTimeLocked} {
+
+				isKeyInBranch, err := contract.IsKeyInBranch(s.keysFile.ExtendedPublicKeys[0], path, branch,
+					s.keysFile.ECDSA)
+				if err != nil {
+					return "", err
+				}
+				if isKeyInBranch {
+					return path, nil
+				}
+			}
+		}
+	}
+
+	return "", errors.Errorf("none of the keys of the wallet's addresses take part in the contract")
+}
+
+func (s *server) checkContractsSupported() error {
+	if s.isMultisig() {
+		return errors.Errorf("contracts are only supported by single signer wallets")
+	}
+	return nil
+}
+
+// trackedContract is a contract tracked by the wallet, along with its address
+// and the path of the wallet key that takes part in it
+type trackedContract struct {
+	*libsedrawallet.Contract
+	address util.Address
+	path    string
+}
+
+func (s *server) trackedContracts() ([]*trackedContract, error) {
+	contracts := make([]*trackedContract, len(s.keysFile.Contracts()))
+	for i, keysFileContract := range s.keysFile.Contracts() {
+		contract, err := libsedrawallet.ParseContract(keysFileContract.RedeemScript)
+		if err != nil {
+			return nil, err
+		}
+
+		address, err := contract.Address(s.params)
+		if err != nil {
+			return nil, err
+		}
+
+		contracts[i] = &trackedContract{
+			Contract: contract,
+			address:  address,
+			path:     keysFileContract.DerivationPath,
+		}
+	}
+	return contracts, nil
+}
+
+func (s *server) ShowContracts(_ context.Context, _ *pb.ShowContractsRequest) (*pb.ShowContractsResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	contracts, err := s.trackedContracts()
+	if err != nil {
+		return nil, err
+	}
+	if len(contracts) == 0 {
+		return &pb.ShowContractsResponse{}, nil
+	}
+
+	addresses := make([]string, len(contracts))
+	for i, contract := range contracts {
+		addresses[i] = contract.address.String()
+	}
+	getBalancesByAddressesResponse, err := s.rpcClient.GetBalancesByAddresses(addresses)
+	if err != nil {
+		return nil, err
+	}
+	balances := make(map[string]uint64, len(getBalancesByAddressesResponse.Entries))
+	for _, entry := range getBalancesByAddressesResponse.Entries {
+		balances[entry.Address] = entry.Balance
+	}
+
+	contractInfos := make([]*pb.ContractInfo, len(contracts))
+	for i, contract := range contracts {
+		canSpendImmediately, err := contract.IsKeyInBranch(s.keysFile.ExtendedPublicKeys[0], contract.path,
+			libsedrawallet.ContractBranchImmediate, s.keysFile.ECDSA)
+		if err != nil {
+			return nil, err
+		}
+		canSpendAfterLockTime, err := contract.IsKeyInBranch(s.keysFile.ExtendedPublicKeys[0], contract.path,
+			libsedrawallet.ContractBranchTimeLocked, s.keysFile.ECDSA)
+		if err != nil {
+			return nil, err
+		}
+
+		contractInfos[i] = &pb.ContractInfo{
+			Address:               contract.address.String(),
+			Type:                  contract.Type(),
+			RedeemScript:          hex.EncodeToString(contract.RedeemScript),
+			LockTime:              contract.TimeLock().Value,
+			IsRelativeLockTime:    contract.TimeLock().IsRelative,
+			CanSpendImmediately:   canSpendImmediately,
+			CanSpendAfterLockTime: canSpendAfterLockTime,
+			Balance:               balances[contract.address.String()],
+		}
+		if contract.HashTimeLockedContract != nil {
+			contractInfos[i].SecretHash = hex.EncodeToString(contract.HashTimeLockedContract.SecretHash[:])
+		}
+	}
+
+	return &pb.ShowContractsResponse{Contracts: contractInfos}, nil
+}
+
+func (s *server) CreateUnsignedContractSpend(_ context.Context, request *pb.CreateUnsignedContractSpendRequest) (
+	*pb.CreateUnsignedTransactionsResponse, error) {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	contract, err := s.trackedContract(request.ContractAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	var secret []byte
+	if request.Secret != "" {
+		secret, err = hex.DecodeString(request.Secret)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not decode the secret")
+		}
+	}
+
+	branch := libsedrawallet.ContractBranchTimeLocked
+	if contract.Vault != nil && request.Recover {
+		branch = libsedrawallet.ContractBranchImmediate
+	}
+	if contract.HashTimeLockedContract != nil {
+		if request.Recover {
+			return nil, errors.Errorf("only vaults can be recovered")
+		}
+		if secret != nil {
+			branch = libsedrawallet.ContractBranchImmediate
+		}
+	}
+
+	var toAddress util.Address
+	if request.ToAddress != "" {
+		toAddress, err = util.DecodeAddress(request.ToAddress, s.params.Prefix)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	selectedUTXOs, totalValue, err := s.spendableContractUTXOs(contract, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	if toAddress == nil {
+		err := s.keysFile.SetLastUsedExternalIndex(0, s.keysFile.LastUsedExternalIndex(0)+1)
+		if err != nil {
+			return nil, err
+		}
+		walletAddr := &walletAddress{
+			index:         s.keysFile.LastUsedExternalIndex(0),
+			cosignerIndex: s.keysFile.CosignerIndex,
+			keyChain:      libsedrawallet.ExternalKeychain,
+		}
+		extendedPublicKeys, err := s.keysFile.AccountExtendedPublicKeys(0)
+		if err != nil {
+			return nil, err
+		}
+		toAddress, err = libsedrawallet.Address(s.params, extendedPublicKeys, s.keysFile.MinimumSignatures,
+			s.walletAddressPath(walletAddr), s.keysFile.ECDSA)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	feeRate, err := s.minimumRelayFeeRate()
+	if err != nil {
+		return nil, err
+	}
+
+	// The fee only affects the value of the single output, so it doesn't change the
+	// mass, and a single re-estimation is enough
+	fee := uint64(0)
+	var unsignedTransaction []byte
+	for i := 0; i < 2; i++ {
+		if totalValue <= fee {
+			return nil, errors.Errorf("Insufficient funds in the contract: %f required for the fee, while only %f available",
+				float64(fee)/constants.SeepPerSedra, float64(totalValue)/constants.SeepPerSedra)
+		}
+
+		payments := []*libsedrawallet.Payment{{
+			Address: toAddress,
+			Amount:  totalValue - fee,
+		}}
+		unsignedTransaction, err = libsedrawallet.CreateUnsignedContractTransaction(s.keysFile.ExtendedPublicKeys[0],
+			contract.Contract, branch, secret, payments, selectedUTXOs)
+		if err != nil {
+			return nil, err
+		}
+
+		transaction, err := serialization.DeserializePartiallySignedTransaction(unsignedTransaction)
+		if err != nil {
+			return nil, err
+		}
+		mass, err := s.estimateMassAfterSignatures(transaction)
+		if err != nil {
+			return nil, err
+		}
+		if mass > mempool.MaximumStandardTransactionMass {
+			return nil, errors.Errorf("the contract has too many UTXOs to spend in a single transaction")
+		}
+		fee = txfee.MinimumRelayFee(mass, feeRate)
+	}
+
+	return &pb.CreateUnsignedTransactionsResponse{UnsignedTransactions: [][]byte{unsignedTransaction}}, nil
+}
+
+func (s *server) trackedContract(address string) (*trackedContract, error) {
+	contracts, err := s.trackedContracts()
+	if err != nil {
+		return nil, err
+	}
+	for _, contract := range contracts {
+		if contract.address.String() == address {
+			return contract, nil
+		}
+	}
+	return nil, errors.Errorf("contract %s is not tracked by the wallet", address)
+}
+
+// spendableContractUTXOs returns the UTXOs of the given contract that can be spent
+// through the given branch, which excludes UTXOs whose lock hasn't passed yet
+func (s *server) spendableContractUTXOs(contract *trackedContract, branch libsedrawallet.ContractBranch) (
+	[]*libsedrawallet.UTXO, uint64, error) {
+
+	address := contract.address.String()
+	mempoolEntriesByAddresses, err := s.rpcClient.GetMempoolEntriesByAddresses([]string{address}, true, true)
+	if err != nil {
+		return nil, 0, err
+	}
+	exclude := make(map[appmessage.RPCOutpoint]struct{})
+	for _, entriesByAddress := range mempoolEntriesByAddresses.Entries {
+		for _, entry := range entriesByAddress.Sending {
+			for _, input := range entry.Transaction.Inputs {
+				exclude[*input.PreviousOutpoint] = struct{}{}
+			}
+		}
+	}
+
+	getUTXOsByAddressesResponse, err := s.rpcClient.GetUTXOsByAddresses([]string{address})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	dagInfo, err := s.rpcClient.GetBlockDAGInfo()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	timeLock := contract.TimeLock()
+	if branch == libsedrawallet.ContractBranchTimeLocked && !timeLock.IsRelative {
+		if timeLock.Value < constants.LockTimeThreshold {
+			if timeLock.Value >= dagInfo.VirtualDAAScore {
+				return nil, 0, errors.Errorf("the contract is locked until DAA score %d, while the current "+
+					"DAA score is %d", timeLock.Value, dagInfo.VirtualDAAScore)
+			}
+		} else if int64(timeLock.Value) >= dagInfo.PastMedianTime {
+			return nil, 0, errors.Errorf("the contract is locked until %s",
+				time.UnixMilli(int64(timeLock.Value)).UTC())
+		}
+	}
+
+	var utxos []*libsedrawallet.UTXO
+	totalValue := uint64(0)
+	lockedValue := uint64(0)
+	for _, entry := range getUTXOsByAddressesResponse.Entries {
+		if _, ok := exclude[*entry.Outpoint]; ok {
+			continue
+		}
+
+		outpoint, err := appmessage.RPCOutpointToDomainOutpoint(entry.Outpoint)
+		if err != nil {
+			return nil, 0, err
+		}
+		if broadcastTime, ok := s.usedOutpoints[*outpoint]; ok && time.Since(broadcastTime) <= time.Minute {
+			continue
+		}
+
+		utxoEntry, err := appmessage.RPCUTXOEntryToUTXOEntry(entry.UTXOEntry)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !isUTXOSpendable(&walletUTXO{UTXOEntry: utxoEntry}, dagInfo.VirtualDAAScore, s.params.BlockCoinbaseMaturity) {
+			continue
+		}
+
+		// The relative lock of an output passes once a DAA score greater than the
+		// output's DAA score plus the lock minus one is reached
+		if branch == libsedrawallet.ContractBranchTimeLocked && timeLock.IsRelative &&
+			utxoEntry.BlockDAAScore()+timeLock.Value > dagInfo.VirtualDAAScore {
+
+			lockedValue += utxoEntry.Amount()
+			continue
+		}
+
+		utxos = append(utxos, &libsedrawallet.UTXO{
+			Outpoint:       outpoint,
+			UTXOEntry:      utxoEntry,
+			DerivationPath: contract.path,
+		})
+		totalValue += utxoEntry.Amount()
+	}
+
+	if len(utxos) == 0 {
+		if lockedValue > 0 {
+			return nil, 0, errors.Errorf("all the funds of the contract (%f) are still locked",
+				float64(lockedValue)/constants.SeepPerSedra)
+		}
+		return nil, 0, errors.Errorf("couldn't find funds to spend in contract %s", address)
+	}
+
+	return utxos, totalValue, nil
+}
@@ -7,23 +7,21 @@ import (
 
 	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/pb"
 	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet/serialization"
 	"github.com/sedracoin/sedrad/domain/consensus/utils/constants"
 	"github.com/sedracoin/sedrad/util"
 	"github.com/pkg/errors"
 	"golang.org/x/exp/slices"
 )
 
-// TODO: Implement a better fee estimation mechanism
-const feePerInput = 10000
-
 func (s *server) CreateUnsignedTransactions(_ context.Context, request *pb.CreateUnsignedTransactionsRequest) (
 	*pb.CreateUnsignedTransactionsResponse, error,
 ) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	unsignedTransactions, err := s.createUnsignedTransactions(request.Address, request.Amount, request.IsSendAll,
-		request.From, request.UseExistingChangeAddress)
+	unsignedTransactions, err := s.createUnsignedTransactions(request.Account, request.Address, request.Amount,
+		request.IsSendAll, request.From, request.UseExistingChangeAddress)
 	if err != nil {
 		return nil, err
 	}
@@ -31,11 +29,22 @@ func (s *server) CreateUnsignedTransactions(_ context.Context, request *pb.Creat
 	return &pb.CreateUnsignedTransactionsResponse{UnsignedTransactions: unsignedTransactions}, nil
 }
 
-func (s *server) createUnsignedTransactions(address string, amount uint64, isSendAll bool, fromAddressesString []string, useExistingChangeAddress bool) ([][]byte, error) {
+func (s *server) createUnsignedTransactions(account uint32, address string, amount uint64, isSendAll bool,
+	fromAddressesString []string, useExistingChangeAddress bool) ([][]byte, error) {
+
 	if !s.isSynced() {
 		return nil, errors.Errorf("wallet daemon is not synced yet, %s", s.formatSyncStateReport())
 	}
 
+	err := s.checkAccount(account)
+	if err != nil {
+		return nil, err
+	}
+	extendedPublicKeys, err := s.keysFile.AccountExtendedPublicKeys(account)
+	if err != nil {
+		return nil, err
+	}
+
 	// make sure address string is correct before proceeding to a
 	// potentially long UTXO refreshment operation
 	toAddress, err := util.DecodeAddress(address, s.params.Prefix)
@@ -54,48 +63,89 @@ func (s *server) createUnsignedTransactions(address string, amount uint64, isSen
 		if !exists {
 			return nil, fmt.Errorf("Specified from address %s does not exists", from)
 		}
+		if fromAddress.account != account {
+			return nil, errors.Errorf("Specified from address %s belongs to account %d", from, fromAddress.account)
+		}
 		fromAddresses = append(fromAddresses, fromAddress)
 	}
 
-	selectedUTXOs, spendValue, changeSeep, err := s.selectUTXOs(amount, isSendAll, feePerInput, fromAddresses)
+	feeRate, err := s.minimumRelayFeeRate()
 	if err != nil {
 		return nil, err
 	}
 
-	if len(selectedUTXOs) == 0 {
-		return nil, errors.Errorf("couldn't find funds to spend")
-	}
+	// Adding inputs in order to pay the fee increases the mass, and thus the fee,
+	// so UTXOs are re-selected until the selected inputs are enough to pay the
+	// fee of the transaction they make up
+	var changeAddress util.Address
+	var changeWalletAddress *walletAddress
+	var unsignedTransaction []byte
+	fee := uint64(0)
+	for i := 0; ; i++ {
+		if i == maxFeeEstimationIterations {
+			return nil, errors.Errorf("transaction fee did not converge after %d iterations",
+				maxFeeEstimationIterations)
+		}
 
-	changeAddress, changeWalletAddress, err := s.changeAddress(useExistingChangeAddress, fromAddresses)
-	if err != nil {
-		return nil, err
-	}
+		selectedUTXOs, spendValue, changeSeep, err := s.selectUTXOs(account, amount, isSendAll, fee, fromAddresses)
+		if err != nil {
+			return nil, err
+		}
 
-	payments := []*libsedrawallet.Payment{{
-		Address: toAddress,
-		Amount:  spendValue,
-	}}
-	if changeSeep > 0 {
-		payments = append(payments, &libsedrawallet.Payment{
-			Address: changeAddress,
-			Amount:  changeSeep,
-		})
-	}
-	unsignedTransaction, err := libsedrawallet.CreateUnsignedTransaction(s.keysFile.ExtendedPublicKeys,
-		s.keysFile.MinimumSignatures,
-		payments, selectedUTXOs)
-	if err != nil {
-		return nil, err
+		if len(selectedUTXOs) == 0 {
+			return nil, errors.Errorf("couldn't find funds to spend")
+		}
+
+		if changeAddress == nil {
+			changeAddress, changeWalletAddress, err = s.changeAddress(account, useExistingChangeAddress, fromAddresses)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		payments := []*libsedrawallet.Payment{{
+			Address: toAddress,
+			Amount:  spendValue,
+		}}
+		if changeSeep > 0 {
+			payments = append(payments, &libsedrawallet.Payment{
+				Address: changeAddress,
+				Amount:  changeSeep,
+			})
+		}
+		unsignedTransaction, err = libsedrawallet.CreateUnsignedTransaction(extendedPublicKeys,
+			s.keysFile.MinimumSignatures,
+			payments, selectedUTXOs)
+		if err != nil {
+			return nil, err
+		}
+
+		transaction, err := serialization.DeserializePartiallySignedTransaction(unsignedTransaction)
+		if err != nil {
+			return nil, err
+		}
+		requiredFee, err := s.estimateFee(transaction, feeRate)
+		if err != nil {
+			return nil, err
+		}
+		if requiredFee <= fee {
+			break
+		}
+		fee = requiredFee
 	}
 
-	unsignedTransactions, err := s.maybeAutoCompoundTransaction(unsignedTransaction, toAddress, changeAddress, changeWalletAddress)
+	unsignedTransactions, err := s.maybeAutoCompoundTransaction(unsignedTransaction, toAddress, changeAddress,
+		changeWalletAddress, feeRate)
 	if err != nil {
 		return nil, err
 	}
 	return unsignedTransactions, nil
 }
 
-func (s *server) selectUTXOs(spendAmount uint64, isSendAll bool, feePerInput uint64, fromAddresses []*walletAddress) (
+// selectUTXOs selects UTXOs of the given account whose total value covers spendAmount along
+// with the given fee. If isSendAll is true, all the spendable UTXOs are selected and the fee
+// is deducted from the sent amount.
+func (s *server) selectUTXOs(account uint32, spendAmount uint64, isSendAll bool, fee uint64, fromAddresses []*walletAddress) (
 	selectedUTXOs []*libsedrawallet.UTXO, totalReceived uint64, changeSeep uint64, err error) {
 
 	selectedUTXOs = []*libsedrawallet.UTXO{}
@@ -112,6 +162,9 @@ func (s *server) selectUTXOs(spendAmount uint64, isSendAll bool, feePerInput uin
 	}
 
 	for _, utxo := range s.utxosSortedByAmount {
+		if utxo.address.account != account {
+			continue
+		}
 		if (fromAddresses != nil && !slices.Contains(fromAddresses, utxo.address)) ||
 			!isUTXOSpendable(utxo, dagInfo.VirtualDAAScore, coinbaseMaturity) {
 			continue
@@ -133,16 +186,18 @@ func (s *server) selectUTXOs(spendAmount uint64, isSendAll bool, feePerInput uin
 
 		totalValue += utxo.UTXOEntry.Amount()
 
-		fee := feePerInput * uint64(len(selectedUTXOs))
 		totalSpend := spendAmount + fee
 		if !isSendAll && totalValue >= totalSpend {
 			break
 		}
 	}
 
-	fee := feePerInput * uint64(len(selectedUTXOs))
 	var totalSpend uint64
 	if isSendAll {
+		if totalValue < fee {
+			return nil, 0, 0, errors.Errorf("Insufficient funds for send: %f required for the fee, while only %f available",
+				float64(fee)/constants.SeepPerSedra, float64(totalValue)/constants.SeepPerSedra)
+		}
 		totalSpend = totalValue
 		totalReceived = totalValue - fee
 	} else {
@@ -0,0 +1,239 @@
+package server
+
+import (
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/keys"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet/serialization"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
+	"github.com/sedracoin/sedrad/domain/dagconfig"
+	"github.com/sedracoin/sedrad/util/txfee"
+	"github.com/sedracoin/sedrad/util/txmass"
+)
+
+// feeTestAddress is the address of the wallet that holds all the UTXOs of the
+// fee tests.
+var feeTestAddress = &walletAddress{account: 0, index: 1, cosignerIndex: 0, keyChain: libsedrawallet.ExternalKeychain}
+
+// newFeeTestServer returns a synced server of a new wallet, whose node requires
+// the given fee rate, along with the mnemonic of the wallet.
+func newFeeTestServer(t *testing.T, params *dagconfig.Params, feeRate uint64) (*server, *stubRPCClient, string) {
+	mnemonic, err := libsedrawallet.CreateMnemonic()
+	if err != nil {
+		t.Fatalf("CreateMnemonic: %+v", err)
+	}
+	keysFile, err := keys.NewFileFromMnemonic(params, mnemonic, "password")
+	if err != nil {
+		t.Fatalf("NewFileFromMnemonic: %+v", err)
+	}
+	err = keysFile.SetPath(params, filepath.Join(t.TempDir(), "keys.json"), true)
+	if err != nil {
+		t.Fatalf("SetPath: %+v", err)
+	}
+
+	stub := &stubRPCClient{usedAddresses: make(map[string]struct{}), minimumRelayFeeRate: feeRate}
+	serverInstance := &server{
+		rpcClient:        stub,
+		params:           params,
+		keysFile:         keysFile,
+		addressSet:       make(walletAddressSet),
+		txMassCalculator: txmass.NewCalculator(params.MassPerTxByte, params.MassPerScriptPubKeyByte, params.MassPerSigOp),
+		usedOutpoints:    map[externalapi.DomainOutpoint]time.Time{},
+		gapLimit:         20,
+	}
+
+	addressString, err := serverInstance.walletAddressString(feeTestAddress)
+	if err != nil {
+		t.Fatalf("walletAddressString: %+v", err)
+	}
+	stub.usedAddresses[addressString] = struct{}{}
+	err = serverInstance.collectNewAddresses()
+	if err != nil {
+		t.Fatalf("collectNewAddresses: %+v", err)
+	}
+
+	return serverInstance, stub, mnemonic
+}
+
+// addFeeTestUTXOs adds UTXOs with the given amounts to feeTestAddress. They're
+// picked up by the next refresh of the server's UTXOs.
+func addFeeTestUTXOs(t *testing.T, serverInstance *server, stub *stubRPCClient, amounts ...uint64) {
+	address, err := serverInstance.walletAddressAddress(feeTestAddress)
+	if err != nil {
+		t.Fatalf("walletAddressAddress: %+v", err)
+	}
+	scriptPublicKey, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		t.Fatalf("PayToAddrScript: %+v", err)
+	}
+
+	for _, amount := range amounts {
+		utxoIndex := len(stub.utxos)
+		transactionID := externalapi.NewDomainTransactionIDFromByteArray(
+			&[externalapi.DomainHashSize]byte{byte(utxoIndex), byte(utxoIndex >> 8)})
+		stub.utxos = append(stub.utxos, &appmessage.UTXOsByAddressesEntry{
+			Address:  address.String(),
+			Outpoint: &appmessage.RPCOutpoint{TransactionID: transactionID.String(), Index: 0},
+			UTXOEntry: &appmessage.RPCUTXOEntry{
+				Amount: amount,
+				ScriptPublicKey: &appmessage.RPCScriptPublicKey{
+					Version: scriptPublicKey.Version,
+					Script:  hex.EncodeToString(scriptPublicKey.Script),
+				},
+			},
+		})
+	}
+}
+
+// checkFeeTestTransactionFee signs the given transaction, and checks that it
+// pays at least the minimum fee for the mass of the signed transaction. It
+// returns the fee the transaction pays.
+func checkFeeTestTransactionFee(t *testing.T, serverInstance *server, mnemonic string,
+	transaction *serialization.PartiallySignedTransaction, feeRate uint64) uint64 {
+
+	transactionBytes, err := serialization.SerializePartiallySignedTransaction(transaction)
+	if err != nil {
+		t.Fatalf("SerializePartiallySignedTransaction: %+v", err)
+	}
+	signedTransactionBytes, err := libsedrawallet.Sign(serverInstance.params, []string{mnemonic}, transactionBytes, false)
+	if err != nil {
+		t.Fatalf("Sign: %+v", err)
+	}
+	signedTransaction, err := libsedrawallet.ExtractTransaction(signedTransactionBytes, false)
+	if err != nil {
+		t.Fatalf("ExtractTransaction: %+v", err)
+	}
+
+	inputsValue := uint64(0)
+	for _, input := range transaction.PartiallySignedInputs {
+		inputsValue += input.PrevOutput.Value
+	}
+	outputsValue := uint64(0)
+	for _, output := range transaction.Tx.Outputs {
+		outputsValue += output.Value
+	}
+	if outputsValue > inputsValue {
+		t.Fatalf("The transaction spends %d seep while its inputs are worth %d seep", outputsValue, inputsValue)
+	}
+
+	fee := inputsValue - outputsValue
+	mass := serverInstance.txMassCalculator.CalculateTransactionMass(signedTransaction)
+	if fee < txfee.MinimumRelayFee(mass, feeRate) {
+		t.Fatalf("The transaction pays a fee of %d seep, while %d seep are required for its mass of %d",
+			fee, txfee.MinimumRelayFee(mass, feeRate), mass)
+	}
+	return fee
+}
+
+func TestCreateUnsignedTransactionsFee(t *testing.T) {
+	params := &dagconfig.SimnetParams
+	const feeRate = 1000
+	serverInstance, stub, mnemonic := newFeeTestServer(t, params, feeRate)
+
+	const utxoAmount = 10_000
+	amounts := make([]uint64, 30)
+	for i := range amounts {
+		amounts[i] = utxoAmount
+	}
+	addFeeTestUTXOs(t, serverInstance, stub, amounts...)
+
+	toAddress, err := serverInstance.walletAddressString(
+		&walletAddress{account: 0, index: 5, cosignerIndex: 0, keyChain: libsedrawallet.ExternalKeychain})
+	if err != nil {
+		t.Fatalf("walletAddressString: %+v", err)
+	}
+
+	const sentAmount = 10 * utxoAmount
+	unsignedTransactions, err := serverInstance.createUnsignedTransactions(0, toAddress, sentAmount, false, nil, false)
+	if err != nil {
+		t.Fatalf("createUnsignedTransactions: %+v", err)
+	}
+	if len(unsignedTransactions) != 1 {
+		t.Fatalf("Expected a single transaction but got %d", len(unsignedTransactions))
+	}
+
+	transaction, err := serialization.DeserializePartiallySignedTransaction(unsignedTransactions[0])
+	if err != nil {
+		t.Fatalf("DeserializePartiallySignedTransaction: %+v", err)
+	}
+	if transaction.Tx.Outputs[0].Value != sentAmount {
+		t.Fatalf("Expected %d seep to be sent but got %d", sentAmount, transaction.Tx.Outputs[0].Value)
+	}
+
+	// The sent amount by itself is covered by exactly sentAmount/utxoAmount
+	// UTXOs, so the fee is only paid if more UTXOs were selected for it
+	if len(transaction.Tx.Inputs) <= sentAmount/utxoAmount {
+		t.Fatalf("Expected more than %d inputs to be selected in order to pay the fee, but got %d",
+			sentAmount/utxoAmount, len(transaction.Tx.Inputs))
+	}
+	checkFeeTestTransactionFee(t, serverInstance, mnemonic, transaction, feeRate)
+}
+
+func TestCreateUnsignedTransactionsFeeDoesNotConverge(t *testing.T) {
+	params := &dagconfig.SimnetParams
+	const feeRate = 1000
+	serverInstance, stub, _ := newFeeTestServer(t, params, feeRate)
+
+	// Measure the fee an additional input adds to a transaction, by comparing
+	// transactions that spend one and two UTXOs
+	addFeeTestUTXOs(t, serverInstance, stub, 1, 1)
+	err := serverInstance.refreshUTXOs()
+	if err != nil {
+		t.Fatalf("refreshUTXOs: %+v", err)
+	}
+	extendedPublicKeys, err := serverInstance.keysFile.AccountExtendedPublicKeys(0)
+	if err != nil {
+		t.Fatalf("AccountExtendedPublicKeys: %+v", err)
+	}
+	address, err := serverInstance.walletAddressAddress(feeTestAddress)
+	if err != nil {
+		t.Fatalf("walletAddressAddress: %+v", err)
+	}
+	feeForInputs := func(numInputs int) uint64 {
+		utxos := make([]*libsedrawallet.UTXO, numInputs)
+		for i := range utxos {
+			utxos[i] = &libsedrawallet.UTXO{
+				Outpoint:       serverInstance.utxosSortedByAmount[i].Outpoint,
+				UTXOEntry:      serverInstance.utxosSortedByAmount[i].UTXOEntry,
+				DerivationPath: serverInstance.walletAddressPath(feeTestAddress),
+			}
+		}
+		transactionBytes, err := libsedrawallet.CreateUnsignedTransaction(extendedPublicKeys,
+			serverInstance.keysFile.MinimumSignatures, []*libsedrawallet.Payment{{Address: address, Amount: 1}}, utxos)
+		if err != nil {
+			t.Fatalf("CreateUnsignedTransaction: %+v", err)
+		}
+		transaction, err := serialization.DeserializePartiallySignedTransaction(transactionBytes)
+		if err != nil {
+			t.Fatalf("DeserializePartiallySignedTransaction: %+v", err)
+		}
+		fee, err := serverInstance.estimateFee(transaction, feeRate)
+		if err != nil {
+			t.Fatalf("estimateFee: %+v", err)
+		}
+		return fee
+	}
+	feePerInput := feeForInputs(2) - feeForInputs(1)
+
+	// Every UTXO is worth a bit less than the fee for spending it, so selecting
+	// more UTXOs to pay the fee always raises the fee further
+	stub.utxos = nil
+	utxoAmount := feePerInput * 20 / 21
+	amounts := make([]uint64, 300)
+	for i := range amounts {
+		amounts[i] = utxoAmount
+	}
+	addFeeTestUTXOs(t, serverInstance, stub, amounts...)
+
+	_, err = serverInstance.createUnsignedTransactions(0, address.String(), utxoAmount, false, nil, false)
+	if err == nil || !strings.Contains(err.Error(), "did not converge") {
+		t.Fatalf("Expected the transaction fee not to converge, but got: %v", err)
+	}
+}
@@ -52,10 +52,14 @@ func (s *server) selectExternalSpendableUTXOs(externalUTXOs *appmessage.GetUTXOs
 	return selectedExternalUtxos, nil
 }
 
+// minimumSpendableCoinbaseAmount is the amount at or below which external coinbase
+// UTXOs are considered not worth spending
+const minimumSpendableCoinbaseAmount = 10000
+
 func isExternalUTXOSpendable(entry *appmessage.UTXOsByAddressesEntry, virtualDAAScore uint64, coinbaseMaturity uint64) bool {
 	if !entry.UTXOEntry.IsCoinbase {
 		return true
-	} else if entry.UTXOEntry.Amount <= feePerInput {
+	} else if entry.UTXOEntry.Amount <= minimumSpendableCoinbaseAmount {
 		return false
 	}
 	return entry.UTXOEntry.BlockDAAScore+coinbaseMaturity < virtualDAAScore
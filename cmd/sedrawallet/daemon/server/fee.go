@@ -0,0 +1,33 @@
+package server
+
+import (
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet/serialization"
+	"github.com/sedracoin/sedrad/util/txfee"
+)
+
+// maxFeeEstimationIterations is the maximum number of times UTXOs are re-selected
+// while waiting for the fee and the selected inputs to converge
+const maxFeeEstimationIterations = 20
+
+// minimumRelayFeeRate returns the minimum fee rate, in seep per 1000 grams of mass,
+// the connected node requires in order to accept transactions to its mempool
+func (s *server) minimumRelayFeeRate() (uint64, error) {
+	getInfoResponse, err := s.rpcClient.GetInfo()
+	if err != nil {
+		return 0, err
+	}
+	if getInfoResponse.MinimumRelayTransactionFee == 0 {
+		return txfee.DefaultMinimumRelayFeeRate, nil
+	}
+	return getInfoResponse.MinimumRelayTransactionFee, nil
+}
+
+// estimateFee returns the minimum fee the given transaction has to pay in order
+// to be relayed, according to its mass once it is fully signed
+func (s *server) estimateFee(transaction *serialization.PartiallySignedTransaction, feeRate uint64) (uint64, error) {
+	mass, err := s.estimateMassAfterSignatures(transaction)
+	if err != nil {
+		return 0, err
+	}
+	return txfee.MinimumRelayFee(mass, feeRate), nil
+}
@@ -0,0 +1,232 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/pb"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
+	"github.com/pkg/errors"
+)
+
+const (
+	invoiceStatusPending = "pending"
+	invoiceStatusPaid    = "paid"
+	invoiceStatusExpired = "expired"
+)
+
+// invoice is a payment request for a receive address that was allocated
+// specifically for it, so that any payment to the address pays the invoice.
+// It's identified by its address.
+type invoice struct {
+	paymentRequest   *libsedrawallet.PaymentRequest
+	walletAddress    *walletAddress
+	createdAt        time.Time
+	status           string
+	receivedAmount   uint64
+	paidAt           time.Time
+	paymentOutpoints []string
+}
+
+func (s *server) CreateInvoice(_ context.Context, request *pb.CreateInvoiceRequest) (*pb.InvoiceResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.isSynced() {
+		return nil, errors.Errorf("wallet daemon is not synced yet, %s", s.formatSyncStateReport())
+	}
+
+	err := s.checkAccount(request.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.keysFile.SetLastUsedExternalIndex(request.Account, s.keysFile.LastUsedExternalIndex(request.Account)+1)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.keysFile.Save()
+	if err != nil {
+		return nil, err
+	}
+
+	walletAddr := &walletAddress{
+		account:       request.Account,
+		index:         s.keysFile.LastUsedExternalIndex(request.Account),
+		cosignerIndex: s.keysFile.CosignerIndex,
+		keyChain:      libsedrawallet.ExternalKeychain,
+	}
+	address, err := s.walletAddressAddress(walletAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt := time.Now()
+	paymentRequest := &libsedrawallet.PaymentRequest{
+		Address: address,
+		Amount:  request.Amount,
+		Label:   request.Label,
+		Message: request.Message,
+	}
+	if request.ExpiresIn != 0 {
+		paymentRequest.Expiry = createdAt.Add(time.Duration(request.ExpiresIn) * time.Second)
+	}
+
+	newInvoice := &invoice{
+		paymentRequest: paymentRequest,
+		walletAddress:  walletAddr,
+		createdAt:      createdAt,
+		status:         invoiceStatusPending,
+	}
+	s.invoices[address.String()] = newInvoice
+	s.trackInvoiceAddress(newInvoice)
+
+	err = s.saveInvoices()
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Created invoice for %s", address)
+
+	return &pb.InvoiceResponse{Invoice: invoiceToProto(newInvoice)}, nil
+}
+
+func (s *server) GetInvoices(_ context.Context, request *pb.GetInvoicesRequest) (*pb.GetInvoicesResponse, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var invoices []*invoice
+	if request.Address != "" {
+		addressInvoice, ok := s.invoices[request.Address]
+		if !ok {
+			return nil, errors.Errorf("there's no invoice for address %s", request.Address)
+		}
+		invoices = []*invoice{addressInvoice}
+	} else {
+		invoices = s.sortedInvoices()
+	}
+
+	pbInvoices := make([]*pb.Invoice, len(invoices))
+	for i, invoice := range invoices {
+		pbInvoices[i] = invoiceToProto(invoice)
+	}
+	return &pb.GetInvoicesResponse{Invoices: pbInvoices}, nil
+}
+
+// trackInvoiceAddress adds the address of the invoice to the addresses
+// whose UTXOs are fetched, so that a payment is found even before the
+// address is found by the address scan.
+func (s *server) trackInvoiceAddress(invoice *invoice) {
+	address := invoice.paymentRequest.Address.String()
+	if _, ok := s.addressSet[address]; !ok {
+		s.addressSet[address] = invoice.walletAddress
+		s.isSyncStateDirty = true
+	}
+}
+
+func (s *server) updateInvoicesWithLock() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.updateInvoices(time.Now())
+}
+
+// updateInvoices marks the pending invoices whose addresses received
+// enough funds as paid, and the ones that expired before that as expired.
+// It should be called right after the UTXO set is refreshed.
+func (s *server) updateInvoices(now time.Time) error {
+	pendingInvoices := make(map[walletAddress]*invoice)
+	for _, invoice := range s.invoices {
+		if invoice.status != invoiceStatusPending {
+			continue
+		}
+		pendingInvoices[*invoice.walletAddress] = invoice
+
+		// The address scan might have dropped the address, e.g. by a rescan,
+		// while it's still unused
+		s.trackInvoiceAddress(invoice)
+	}
+	if len(pendingInvoices) == 0 {
+		return nil
+	}
+
+	receivedAmounts := make(map[*invoice]uint64)
+	paymentOutpoints := make(map[*invoice][]string)
+	for _, utxo := range s.utxosSortedByAmount {
+		invoice, ok := pendingInvoices[*utxo.address]
+		if !ok {
+			continue
+		}
+		receivedAmounts[invoice] += utxo.UTXOEntry.Amount()
+		paymentOutpoints[invoice] = append(paymentOutpoints[invoice],
+			fmt.Sprintf("%s:%d", utxo.Outpoint.TransactionID, utxo.Outpoint.Index))
+	}
+
+	isChanged := false
+	for _, invoice := range pendingInvoices {
+		address := invoice.paymentRequest.Address
+		if receivedAmounts[invoice] != invoice.receivedAmount {
+			invoice.receivedAmount = receivedAmounts[invoice]
+			sort.Strings(paymentOutpoints[invoice])
+			invoice.paymentOutpoints = paymentOutpoints[invoice]
+			isChanged = true
+		}
+
+		if invoice.receivedAmount > 0 && invoice.receivedAmount >= invoice.paymentRequest.Amount {
+			invoice.status = invoiceStatusPaid
+			invoice.paidAt = now
+			isChanged = true
+			log.Infof("Invoice for %s was paid with %d seep", address, invoice.receivedAmount)
+			continue
+		}
+
+		if invoice.paymentRequest.IsExpired(now) {
+			invoice.status = invoiceStatusExpired
+			isChanged = true
+			log.Infof("Invoice for %s expired", address)
+		}
+	}
+
+	if !isChanged {
+		return nil
+	}
+	return s.saveInvoices()
+}
+
+func (s *server) sortedInvoices() []*invoice {
+	invoices := make([]*invoice, 0, len(s.invoices))
+	for _, invoice := range s.invoices {
+		invoices = append(invoices, invoice)
+	}
+	sort.Slice(invoices, func(i, j int) bool {
+		if !invoices[i].createdAt.Equal(invoices[j].createdAt) {
+			return invoices[i].createdAt.Before(invoices[j].createdAt)
+		}
+		return invoices[i].paymentRequest.Address.String() < invoices[j].paymentRequest.Address.String()
+	})
+	return invoices
+}
+
+func invoiceToProto(invoice *invoice) *pb.Invoice {
+	pbInvoice := &pb.Invoice{
+		Address:          invoice.paymentRequest.Address.String(),
+		Uri:              invoice.paymentRequest.URI(),
+		Amount:           invoice.paymentRequest.Amount,
+		Label:            invoice.paymentRequest.Label,
+		Message:          invoice.paymentRequest.Message,
+		CreatedAt:        invoice.createdAt.UnixMilli(),
+		Status:           invoice.status,
+		ReceivedAmount:   invoice.receivedAmount,
+		PaymentOutpoints: invoice.paymentOutpoints,
+		Account:          invoice.walletAddress.account,
+	}
+	if !invoice.paymentRequest.Expiry.IsZero() {
+		pbInvoice.ExpiresAt = invoice.paymentRequest.Expiry.UnixMilli()
+	}
+	if !invoice.paidAt.IsZero() {
+		pbInvoice.PaidAt = invoice.paidAt.UnixMilli()
+	}
+	return pbInvoice
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/utils"
+	"github.com/pkg/errors"
+)
+
+const invoicesVersion = 1
+
+// invoicesJSON holds the invoices created by the daemon. It's kept next to
+// the keys file, so that invoices that weren't paid yet are still tracked
+// after a restart of the daemon.
+type invoicesJSON struct {
+	Version  uint32         `json:"version"`
+	Invoices []*invoiceJSON `json:"invoices"`
+}
+
+type invoiceJSON struct {
+	URI              string   `json:"uri"`
+	Account          uint32   `json:"account"`
+	Index            uint32   `json:"index"`
+	CreatedAt        int64    `json:"createdAt"`
+	Status           string   `json:"status"`
+	ReceivedAmount   uint64   `json:"receivedAmount,omitempty"`
+	PaidAt           int64    `json:"paidAt,omitempty"`
+	PaymentOutpoints []string `json:"paymentOutpoints,omitempty"`
+}
+
+func (s *server) invoicesPath() string {
+	return s.keysFile.Path() + ".invoices"
+}
+
+// loadInvoices loads the invoices saved by a previous run of the daemon, if
+// there are any. A file that can't be read fails the daemon, since payments
+// to the invoices in it would go unnoticed.
+func (s *server) loadInvoices() error {
+	file, err := os.Open(s.invoicesPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	savedInvoices := &invoicesJSON{}
+	err = json.NewDecoder(file).Decode(savedInvoices)
+	if err != nil {
+		return errors.Wrapf(err, "could not read the invoices in %s", s.invoicesPath())
+	}
+	if savedInvoices.Version != invoicesVersion {
+		return errors.Errorf("unknown version %d of the invoices in %s", savedInvoices.Version, s.invoicesPath())
+	}
+
+	for _, savedInvoice := range savedInvoices.Invoices {
+		paymentRequest, err := libsedrawallet.ParsePaymentURI(savedInvoice.URI, s.params)
+		if err != nil {
+			return errors.Wrapf(err, "invalid invoice in %s", s.invoicesPath())
+		}
+
+		loadedInvoice := &invoice{
+			paymentRequest: paymentRequest,
+			walletAddress: &walletAddress{
+				account:       savedInvoice.Account,
+				index:         savedInvoice.Index,
+				cosignerIndex: s.keysFile.CosignerIndex,
+				keyChain:      libsedrawallet.ExternalKeychain,
+			},
+			createdAt:        time.UnixMilli(savedInvoice.CreatedAt),
+			status:           savedInvoice.Status,
+			receivedAmount:   savedInvoice.ReceivedAmount,
+			paymentOutpoints: savedInvoice.PaymentOutpoints,
+		}
+		if savedInvoice.PaidAt != 0 {
+			loadedInvoice.paidAt = time.UnixMilli(savedInvoice.PaidAt)
+		}
+
+		address, err := s.walletAddressString(loadedInvoice.walletAddress)
+		if err != nil {
+			return err
+		}
+		if address != paymentRequest.Address.String() {
+			return errors.Errorf("the invoice for %s in %s doesn't belong to the wallet",
+				paymentRequest.Address, s.invoicesPath())
+		}
+
+		s.invoices[address] = loadedInvoice
+		if loadedInvoice.status == invoiceStatusPending {
+			s.trackInvoiceAddress(loadedInvoice)
+		}
+	}
+
+	log.Infof("Loaded %d invoices from %s", len(s.invoices), s.invoicesPath())
+	return nil
+}
+
+// saveInvoices saves all the invoices, replacing the previous file
+// atomically.
+func (s *server) saveInvoices() error {
+	savedInvoices := &invoicesJSON{
+		Version:  invoicesVersion,
+		Invoices: make([]*invoiceJSON, 0, len(s.invoices)),
+	}
+	for _, invoice := range s.sortedInvoices() {
+		savedInvoice := &invoiceJSON{
+			URI:              invoice.paymentRequest.URI(),
+			Account:          invoice.walletAddress.account,
+			Index:            invoice.walletAddress.index,
+			CreatedAt:        invoice.createdAt.UnixMilli(),
+			Status:           invoice.status,
+			ReceivedAmount:   invoice.receivedAmount,
+			PaymentOutpoints: invoice.paymentOutpoints,
+		}
+		if !invoice.paidAt.IsZero() {
+			savedInvoice.PaidAt = invoice.paidAt.UnixMilli()
+		}
+		savedInvoices.Invoices = append(savedInvoices.Invoices, savedInvoice)
+	}
+
+	return utils.WriteJSONFileAtomically(s.invoicesPath(), savedInvoices)
+}
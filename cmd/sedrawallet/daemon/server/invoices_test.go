@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/pb"
+	"github.com/sedracoin/sedrad/domain/consensus"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/testutils"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/utxo"
+)
+
+func TestInvoices(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		params := &consensusConfig.Params
+		keysFilePath := filepath.Join(t.TempDir(), "keys.json")
+
+		serverInstance := newSyncStateTestServer(t, params, keysFilePath)
+		serverInstance.invoices = map[string]*invoice{}
+		serverInstance.gapLimit = 20
+
+		// Invoices are only created by a synced wallet
+		serverInstance.rpcClient = &stubRPCClient{}
+		err := serverInstance.collectNewAddresses()
+		if err != nil {
+			t.Fatalf("collectNewAddresses: %+v", err)
+		}
+
+		paidResponse, err := serverInstance.CreateInvoice(context.Background(), &pb.CreateInvoiceRequest{
+			Amount:    100,
+			Label:     "Shop",
+			Message:   "Order 42",
+			ExpiresIn: 3600,
+		})
+		if err != nil {
+			t.Fatalf("CreateInvoice: %+v", err)
+		}
+		expiredResponse, err := serverInstance.CreateInvoice(context.Background(), &pb.CreateInvoiceRequest{
+			ExpiresIn: 1,
+		})
+		if err != nil {
+			t.Fatalf("CreateInvoice: %+v", err)
+		}
+		paidAddress := paidResponse.Invoice.Address
+		expiredAddress := expiredResponse.Invoice.Address
+		if paidAddress == expiredAddress {
+			t.Fatalf("Two invoices got the same address %s", paidAddress)
+		}
+		for _, address := range []string{paidAddress, expiredAddress} {
+			if _, ok := serverInstance.addressSet[address]; !ok {
+				t.Fatalf("The address %s of a new invoice isn't tracked", address)
+			}
+		}
+		if !serverInstance.isSyncStateDirty {
+			t.Fatalf("The addresses of new invoices aren't saved with the sync state")
+		}
+
+		addPayment := func(address string, amount uint64, transactionIDByte byte) {
+			serverInstance.utxosSortedByAmount = append(serverInstance.utxosSortedByAmount, &walletUTXO{
+				Outpoint: &externalapi.DomainOutpoint{
+					TransactionID: *externalapi.NewDomainTransactionIDFromByteArray(
+						&[externalapi.DomainHashSize]byte{transactionIDByte}),
+				},
+				UTXOEntry: utxo.NewUTXOEntry(amount, &externalapi.ScriptPublicKey{}, false, 0),
+				address:   serverInstance.addressSet[address],
+			})
+		}
+
+		now := time.Now()
+		addPayment(paidAddress, 60, 1)
+		err = serverInstance.updateInvoices(now)
+		if err != nil {
+			t.Fatalf("updateInvoices: %+v", err)
+		}
+		paidInvoice := serverInstance.invoices[paidAddress]
+		if paidInvoice.status != invoiceStatusPending || paidInvoice.receivedAmount != 60 {
+			t.Fatalf("Expected a partially paid invoice to be pending with 60 seep, but it's %s with %d seep",
+				paidInvoice.status, paidInvoice.receivedAmount)
+		}
+
+		addPayment(paidAddress, 50, 2)
+		err = serverInstance.updateInvoices(now.Add(2 * time.Second))
+		if err != nil {
+			t.Fatalf("updateInvoices: %+v", err)
+		}
+		if paidInvoice.status != invoiceStatusPaid || paidInvoice.receivedAmount != 110 ||
+			len(paidInvoice.paymentOutpoints) != 2 {
+
+			t.Fatalf("Expected the invoice to be paid with 110 seep in 2 outpoints, but it's %s with %d seep "+
+				"in %d outpoints", paidInvoice.status, paidInvoice.receivedAmount, len(paidInvoice.paymentOutpoints))
+		}
+		expiredInvoice := serverInstance.invoices[expiredAddress]
+		if expiredInvoice.status != invoiceStatusExpired {
+			t.Fatalf("Expected the unpaid invoice to expire, but it's %s", expiredInvoice.status)
+		}
+
+		// A payment after the invoice was paid or expired doesn't change it
+		addPayment(expiredAddress, 10, 3)
+		err = serverInstance.updateInvoices(now.Add(3 * time.Second))
+		if err != nil {
+			t.Fatalf("updateInvoices: %+v", err)
+		}
+		if expiredInvoice.status != invoiceStatusExpired || expiredInvoice.receivedAmount != 0 {
+			t.Fatalf("A payment changed an expired invoice")
+		}
+
+		loadedServerInstance := &server{
+			params:     params,
+			keysFile:   serverInstance.keysFile,
+			addressSet: make(walletAddressSet),
+			invoices:   map[string]*invoice{},
+		}
+		err = loadedServerInstance.loadInvoices()
+		if err != nil {
+			t.Fatalf("loadInvoices: %+v", err)
+		}
+		if len(loadedServerInstance.invoices) != 2 {
+			t.Fatalf("Expected 2 loaded invoices but got %d", len(loadedServerInstance.invoices))
+		}
+		getInvoicesResponse, err := loadedServerInstance.GetInvoices(context.Background(),
+			&pb.GetInvoicesRequest{Address: paidAddress})
+		if err != nil {
+			t.Fatalf("GetInvoices: %+v", err)
+		}
+		loadedPaidInvoice := getInvoicesResponse.Invoices[0]
+		if loadedPaidInvoice.Uri != paidResponse.Invoice.Uri || loadedPaidInvoice.Status != invoiceStatusPaid ||
+			loadedPaidInvoice.ReceivedAmount != 110 || loadedPaidInvoice.PaidAt != now.Add(2*time.Second).UnixMilli() {
+
+			t.Fatalf("The loaded invoice %+v is different than the saved one", loadedPaidInvoice)
+		}
+		if loadedServerInstance.invoices[expiredAddress].status != invoiceStatusExpired {
+			t.Fatalf("Expected the loaded unpaid invoice to be expired, but it's %s",
+				loadedServerInstance.invoices[expiredAddress].status)
+		}
+	})
+}
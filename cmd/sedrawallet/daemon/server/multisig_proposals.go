@@ -0,0 +1,421 @@
+package server
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/pb"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet/bip32"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet/serialization"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/pkg/errors"
+)
+
+const (
+	multisigProposalStatusPending   = "pending"
+	multisigProposalStatusBroadcast = "broadcast"
+)
+
+// multisigProposal is a set of transactions of a multisig wallet that the
+// daemon hosts until its cosigners sign them. It's identified by the ID of
+// its first transaction.
+type multisigProposal struct {
+	id             string
+	description    string
+	createdAt      time.Time
+	status         string
+	transactions   []*serialization.PartiallySignedTransaction
+	txIDs          []string
+	broadcastError string
+}
+
+func (s *server) CreateMultisigProposal(_ context.Context, request *pb.CreateMultisigProposalRequest) (
+	*pb.MultisigProposalResponse, error) {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.keysFile.ExtendedPublicKeys) < 2 {
+		return nil, errors.New("multisig proposals are only supported by multisig wallets")
+	}
+	if len(request.Transactions) == 0 {
+		return nil, errors.New("a proposal must contain at least one transaction")
+	}
+
+	transactions := make([]*serialization.PartiallySignedTransaction, len(request.Transactions))
+	for i, serializedTransaction := range request.Transactions {
+		transaction, err := s.deserializeMultisigProposalTransaction(serializedTransaction)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid transaction #%d", i+1)
+		}
+		transactions[i] = transaction
+	}
+
+	id := consensushashing.TransactionID(transactions[0].Tx).String()
+	if _, ok := s.multisigProposals[id]; ok {
+		return nil, errors.Errorf("a proposal for transaction %s already exists", id)
+	}
+
+	proposal := &multisigProposal{
+		id:           id,
+		description:  request.Description,
+		createdAt:    time.Now(),
+		status:       multisigProposalStatusPending,
+		transactions: transactions,
+	}
+	s.multisigProposals[id] = proposal
+	log.Infof("Created multisig proposal %s", id)
+
+	return s.updateMultisigProposal(proposal)
+}
+
+func (s *server) GetMultisigProposals(_ context.Context, request *pb.GetMultisigProposalsRequest) (
+	*pb.GetMultisigProposalsResponse, error) {
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var proposals []*multisigProposal
+	if request.Id != "" {
+		proposal, err := s.multisigProposal(request.Id)
+		if err != nil {
+			return nil, err
+		}
+		proposals = []*multisigProposal{proposal}
+	} else {
+		proposals = s.sortedMultisigProposals()
+	}
+
+	pbProposals := make([]*pb.MultisigProposal, len(proposals))
+	for i, proposal := range proposals {
+		var err error
+		pbProposals[i], err = s.multisigProposalToProto(proposal)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &pb.GetMultisigProposalsResponse{Proposals: pbProposals}, nil
+}
+
+func (s *server) SignMultisigProposal(_ context.Context, request *pb.SignMultisigProposalRequest) (
+	*pb.MultisigProposalResponse, error) {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	proposal, err := s.multisigProposal(request.Id)
+	if err != nil {
+		return nil, err
+	}
+	if proposal.status != multisigProposalStatusPending {
+		return nil, errors.Errorf("proposal %s was already broadcast", proposal.id)
+	}
+
+	signedTransactions := request.SignedTransactions
+	if request.Password != "" {
+		if len(signedTransactions) > 0 {
+			return nil, errors.New("either signed transactions or a password may be given, not both")
+		}
+
+		proposalTransactions, err := serializeMultisigProposalTransactions(proposal)
+		if err != nil {
+			return nil, err
+		}
+		signedTransactions, err = s.signTransactions(request.Account, proposalTransactions, request.Password)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(signedTransactions) > 0 {
+		err = s.addMultisigProposalSignatures(proposal, signedTransactions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.updateMultisigProposal(proposal)
+}
+
+func (s *server) CancelMultisigProposal(_ context.Context, request *pb.CancelMultisigProposalRequest) (
+	*pb.CancelMultisigProposalResponse, error) {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, err := s.multisigProposal(request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(s.multisigProposals, request.Id)
+	err = s.saveMultisigProposals()
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Cancelled multisig proposal %s", request.Id)
+
+	return &pb.CancelMultisigProposalResponse{}, nil
+}
+
+func (s *server) multisigProposal(id string) (*multisigProposal, error) {
+	proposal, ok := s.multisigProposals[id]
+	if !ok {
+		return nil, errors.Errorf("proposal %s doesn't exist", id)
+	}
+	return proposal, nil
+}
+
+func (s *server) sortedMultisigProposals() []*multisigProposal {
+	proposals := make([]*multisigProposal, 0, len(s.multisigProposals))
+	for _, proposal := range s.multisigProposals {
+		proposals = append(proposals, proposal)
+	}
+	sort.Slice(proposals, func(i, j int) bool {
+		if !proposals[i].createdAt.Equal(proposals[j].createdAt) {
+			return proposals[i].createdAt.Before(proposals[j].createdAt)
+		}
+		return proposals[i].id < proposals[j].id
+	})
+	return proposals
+}
+
+// deserializeMultisigProposalTransaction deserializes a transaction of a
+// proposal, and makes sure that all of its inputs are spent by the cosigners
+// of the wallet and that the signatures it already has are valid.
+func (s *server) deserializeMultisigProposalTransaction(serializedTransaction []byte) (
+	*serialization.PartiallySignedTransaction, error) {
+
+	transaction, err := serialization.DeserializePartiallySignedTransaction(serializedTransaction)
+	if err != nil {
+		return nil, err
+	}
+
+	// A transaction that isn't in the PSST format isn't validated when it's deserialized,
+	// and signing fields are populated by indexing its inputs with the partially signed ones
+	if len(transaction.PartiallySignedInputs) != len(transaction.Tx.Inputs) {
+		return nil, errors.Errorf("the transaction has %d partially signed inputs but %d inputs",
+			len(transaction.PartiallySignedInputs), len(transaction.Tx.Inputs))
+	}
+	for i, input := range transaction.PartiallySignedInputs {
+		if input.PrevOutput == nil {
+			return nil, errors.Errorf("the previous output of input %d is missing", i)
+		}
+	}
+
+	for i, input := range transaction.PartiallySignedInputs {
+		_, err := s.multisigInputCosignerPairs(input)
+		if err != nil {
+			return nil, errors.Wrapf(err, "input %d isn't spent by the cosigners of the wallet", i)
+		}
+	}
+
+	err = libsedrawallet.VerifyPartialSignatures(transaction, s.keysFile.ECDSA)
+	if err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+func (s *server) addMultisigProposalSignatures(proposal *multisigProposal, signedTransactions [][]byte) error {
+	if len(signedTransactions) != len(proposal.transactions) {
+		return errors.Errorf("proposal %s has %d transactions but %d signed transactions were given",
+			proposal.id, len(proposal.transactions), len(signedTransactions))
+	}
+
+	combinedTransactions := make([]*serialization.PartiallySignedTransaction, len(signedTransactions))
+	for i, serializedTransaction := range signedTransactions {
+		signedTransaction, err := s.deserializeMultisigProposalTransaction(serializedTransaction)
+		if err != nil {
+			return errors.Wrapf(err, "invalid signed transaction #%d", i+1)
+		}
+
+		combinedTransactions[i], err = libsedrawallet.CombinePartiallySignedTransactions(
+			[]*serialization.PartiallySignedTransaction{proposal.transactions[i], signedTransaction})
+		if err != nil {
+			return errors.Wrapf(err, "signed transaction #%d doesn't match the proposal", i+1)
+		}
+	}
+
+	proposal.transactions = combinedTransactions
+	return nil
+}
+
+// updateMultisigProposal broadcasts the proposal if it's fully signed, and
+// saves it. A failure to broadcast is kept in the proposal rather than
+// returned, so that the signatures that completed it aren't lost.
+func (s *server) updateMultisigProposal(proposal *multisigProposal) (*pb.MultisigProposalResponse, error) {
+	err := s.broadcastMultisigProposalIfFullySigned(proposal)
+	if err != nil {
+		proposal.broadcastError = err.Error()
+		log.Warnf("Could not broadcast multisig proposal %s: %s", proposal.id, err)
+	}
+
+	err = s.saveMultisigProposals()
+	if err != nil {
+		return nil, err
+	}
+
+	pbProposal, err := s.multisigProposalToProto(proposal)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.MultisigProposalResponse{Proposal: pbProposal}, nil
+}
+
+func (s *server) broadcastMultisigProposalIfFullySigned(proposal *multisigProposal) error {
+	for _, transaction := range proposal.transactions {
+		if !libsedrawallet.IsPartiallySignedTransactionFullySigned(transaction) {
+			return nil
+		}
+	}
+
+	finalizedTransactions := make([][]byte, len(proposal.transactions))
+	for i, transaction := range proposal.transactions {
+		tx, err := libsedrawallet.FinalizeTransaction(transaction)
+		if err != nil {
+			return err
+		}
+		finalizedTransactions[i], err = serialization.SerializeDomainTransaction(tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	txIDs, err := s.broadcast(finalizedTransactions, true)
+	if err != nil {
+		return err
+	}
+
+	proposal.status = multisigProposalStatusBroadcast
+	proposal.txIDs = txIDs
+	proposal.broadcastError = ""
+	log.Infof("Broadcast multisig proposal %s", proposal.id)
+	return nil
+}
+
+func (s *server) multisigProposalToProto(proposal *multisigProposal) (*pb.MultisigProposal, error) {
+	transactions, err := serializeMultisigProposalTransactions(proposal)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.MultisigProposal{
+		Id:                proposal.id,
+		Description:       proposal.description,
+		CreatedAt:         proposal.createdAt.UnixMilli(),
+		Status:            proposal.status,
+		Transactions:      transactions,
+		Cosigners:         s.multisigCosignerStatuses(proposal),
+		MinimumSignatures: s.keysFile.MinimumSignatures,
+		TxIDs:             proposal.txIDs,
+		BroadcastError:    proposal.broadcastError,
+	}, nil
+}
+
+// multisigCosignerStatuses counts the inputs of the proposal that every
+// cosigner signed
+func (s *server) multisigCosignerStatuses(proposal *multisigProposal) []*pb.MultisigCosignerStatus {
+	statuses := make([]*pb.MultisigCosignerStatus, len(s.keysFile.ExtendedPublicKeys))
+	for i := range statuses {
+		statuses[i] = &pb.MultisigCosignerStatus{CosignerIndex: uint32(i)}
+	}
+
+	for _, transaction := range proposal.transactions {
+		for _, input := range transaction.PartiallySignedInputs {
+			// The inputs were verified when the proposal was created
+			cosignerPairs, err := s.multisigInputCosignerPairs(input)
+			if err != nil {
+				log.Warnf("Could not match the public keys of an input of multisig proposal %s "+
+					"to cosigners: %s", proposal.id, err)
+				continue
+			}
+			for i, pair := range cosignerPairs {
+				statuses[i].TotalInputs++
+				if pair.Signature != nil {
+					statuses[i].SignedInputs++
+				}
+			}
+		}
+	}
+	return statuses
+}
+
+// multisigInputCosignerPairs returns the public key and signature pairs of the given input,
+// indexed by the cosigner index of their owners. It fails if the public keys of the input
+// aren't the ones of the cosigners of any account of the wallet at its derivation path.
+//
+// The pairs of an input are sorted by the extended public keys of its account rather than by
+// cosigner, so they're matched to the cosigners by deriving the public key of every cosigner.
+func (s *server) multisigInputCosignerPairs(input *serialization.PartiallySignedInput) (
+	[]*serialization.PubKeySignaturePair, error) {
+
+	if input.RedeemScript != nil || len(input.PubKeySignaturePairs) != len(s.keysFile.ExtendedPublicKeys) {
+		return nil, errors.Errorf("it has %d public keys, but the wallet has %d cosigners",
+			len(input.PubKeySignaturePairs), len(s.keysFile.ExtendedPublicKeys))
+	}
+	pairsByPublicKey := make(map[string]*serialization.PubKeySignaturePair, len(input.PubKeySignaturePairs))
+	for _, pair := range input.PubKeySignaturePairs {
+		pairsByPublicKey[pair.ExtendedPublicKey] = pair
+	}
+
+	// The index of a cosigner is the index of its public key of the first account
+	// among the sorted public keys of the first account
+	sortedFirstAccountPublicKeys := append([]string(nil), s.keysFile.ExtendedPublicKeys...)
+	sort.Strings(sortedFirstAccountPublicKeys)
+
+	for _, account := range s.keysFile.Accounts() {
+		extendedPublicKeys, err := s.keysFile.AccountExtendedPublicKeys(account)
+		if err != nil {
+			return nil, err
+		}
+
+		cosignerPairs := make([]*serialization.PubKeySignaturePair, len(extendedPublicKeys))
+		isAccountOfInput := true
+		for i, extendedPublicKey := range extendedPublicKeys {
+			publicKey, err := derivePublicKey(extendedPublicKey, input.DerivationPath)
+			if err != nil {
+				return nil, err
+			}
+			pair, ok := pairsByPublicKey[publicKey]
+			if !ok {
+				isAccountOfInput = false
+				break
+			}
+			cosignerIndex := sort.SearchStrings(sortedFirstAccountPublicKeys, s.keysFile.ExtendedPublicKeys[i])
+			cosignerPairs[cosignerIndex] = pair
+		}
+		if isAccountOfInput {
+			return cosignerPairs, nil
+		}
+	}
+	return nil, errors.Errorf("its public keys don't belong to the cosigners of any account "+
+		"of the wallet at %s", input.DerivationPath)
+}
+
+// derivePublicKey derives the public key at the given path from the given extended public key
+func derivePublicKey(extendedPublicKey string, path string) (string, error) {
+	extendedKey, err := bip32.DeserializeExtendedKey(extendedPublicKey)
+	if err != nil {
+		return "", err
+	}
+	derivedKey, err := extendedKey.DeriveFromPath(path)
+	if err != nil {
+		return "", err
+	}
+	return derivedKey.String(), nil
+}
+
+func serializeMultisigProposalTransactions(proposal *multisigProposal) ([][]byte, error) {
+	serializedTransactions := make([][]byte, len(proposal.transactions))
+	for i, transaction := range proposal.transactions {
+		var err error
+		serializedTransactions[i], err = serialization.SerializePSST(transaction)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return serializedTransactions, nil
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet/serialization"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/utils"
+	"github.com/pkg/errors"
+)
+
+const multisigProposalsVersion = 1
+
+// multisigProposalsJSON holds the multisig proposals hosted by the daemon. It's
+// kept next to the keys file, so that signatures that were already collected
+// survive a restart of the daemon.
+type multisigProposalsJSON struct {
+	Version   uint32                  `json:"version"`
+	Proposals []*multisigProposalJSON `json:"proposals"`
+}
+
+type multisigProposalJSON struct {
+	ID             string   `json:"id"`
+	Description    string   `json:"description"`
+	CreatedAt      int64    `json:"createdAt"`
+	Status         string   `json:"status"`
+	Transactions   []string `json:"transactions"`
+	TxIDs          []string `json:"txIDs,omitempty"`
+	BroadcastError string   `json:"broadcastError,omitempty"`
+}
+
+func (s *server) multisigProposalsPath() string {
+	return s.keysFile.Path() + ".proposals"
+}
+
+// loadMultisigProposals loads the multisig proposals saved by a previous run
+// of the daemon, if there are any. Unlike the sync state, the proposals can't
+// be recreated, so a file that can't be read fails the daemon.
+func (s *server) loadMultisigProposals() error {
+	file, err := os.Open(s.multisigProposalsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	proposalsJSON := &multisigProposalsJSON{}
+	err = json.NewDecoder(file).Decode(proposalsJSON)
+	if err != nil {
+		return errors.Wrapf(err, "could not read the multisig proposals in %s", s.multisigProposalsPath())
+	}
+	if proposalsJSON.Version != multisigProposalsVersion {
+		return errors.Errorf("unknown version %d of the multisig proposals in %s",
+			proposalsJSON.Version, s.multisigProposalsPath())
+	}
+
+	for _, proposalJSON := range proposalsJSON.Proposals {
+		proposal := &multisigProposal{
+			id:             proposalJSON.ID,
+			description:    proposalJSON.Description,
+			createdAt:      time.UnixMilli(proposalJSON.CreatedAt),
+			status:         proposalJSON.Status,
+			transactions:   make([]*serialization.PartiallySignedTransaction, len(proposalJSON.Transactions)),
+			txIDs:          proposalJSON.TxIDs,
+			broadcastError: proposalJSON.BroadcastError,
+		}
+		for i, transactionHex := range proposalJSON.Transactions {
+			serializedTransaction, err := hex.DecodeString(transactionHex)
+			if err != nil {
+				return errors.Wrapf(err, "invalid transaction in multisig proposal %s", proposal.id)
+			}
+			proposal.transactions[i], err = s.deserializeMultisigProposalTransaction(serializedTransaction)
+			if err != nil {
+				return errors.Wrapf(err, "invalid transaction in multisig proposal %s", proposal.id)
+			}
+		}
+		s.multisigProposals[proposal.id] = proposal
+	}
+
+	log.Infof("Loaded %d multisig proposals from %s", len(s.multisigProposals), s.multisigProposalsPath())
+	return nil
+}
+
+// saveMultisigProposals saves all the multisig proposals, replacing the
+// previous file atomically.
+func (s *server) saveMultisigProposals() error {
+	proposalsJSON := &multisigProposalsJSON{
+		Version:   multisigProposalsVersion,
+		Proposals: make([]*multisigProposalJSON, 0, len(s.multisigProposals)),
+	}
+	for _, proposal := range s.sortedMultisigProposals() {
+		transactions, err := serializeMultisigProposalTransactions(proposal)
+		if err != nil {
+			return err
+		}
+		transactionsHex := make([]string, len(transactions))
+		for i, transaction := range transactions {
+			transactionsHex[i] = hex.EncodeToString(transaction)
+		}
+
+		proposalsJSON.Proposals = append(proposalsJSON.Proposals, &multisigProposalJSON{
+			ID:             proposal.id,
+			Description:    proposal.description,
+			CreatedAt:      proposal.createdAt.UnixMilli(),
+			Status:         proposal.status,
+			Transactions:   transactionsHex,
+			TxIDs:          proposal.txIDs,
+			BroadcastError: proposal.broadcastError,
+		})
+	}
+
+	return utils.WriteJSONFileAtomically(s.multisigProposalsPath(), proposalsJSON)
+}
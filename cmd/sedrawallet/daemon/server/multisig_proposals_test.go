@@ -0,0 +1,337 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/pb"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/keys"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet/serialization"
+	"github.com/sedracoin/sedrad/domain/consensus"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/testutils"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/utxo"
+	"github.com/sedracoin/sedrad/domain/dagconfig"
+)
+
+func TestMultisigProposals(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		params := &consensusConfig.Params
+
+		const numKeys = 3
+		const minimumSignatures = 2
+		mnemonics := make([]string, numKeys)
+		publicKeys := make([]string, numKeys)
+		for i := 0; i < numKeys; i++ {
+			var err error
+			mnemonics[i], err = libsedrawallet.CreateMnemonic()
+			if err != nil {
+				t.Fatalf("CreateMnemonic: %+v", err)
+			}
+
+			publicKeys[i], err = libsedrawallet.MasterPublicKeyFromMnemonic(params, mnemonics[i], true)
+			if err != nil {
+				t.Fatalf("MasterPublicKeyFromMnemonic: %+v", err)
+			}
+		}
+
+		// The public keys of a multisig wallet are sorted, and the index of a
+		// cosigner is the index of its public key
+		sort.Sort(&mnemonicsByPublicKey{mnemonics: mnemonics, publicKeys: publicKeys})
+
+		keysFile := &keys.File{
+			ExtendedPublicKeys: publicKeys,
+			MinimumSignatures:  minimumSignatures,
+		}
+		err := keysFile.SetPath(params, filepath.Join(t.TempDir(), "keys.json"), true)
+		if err != nil {
+			t.Fatalf("SetPath: %+v", err)
+		}
+		serverInstance := &server{
+			params:            params,
+			keysFile:          keysFile,
+			multisigProposals: map[string]*multisigProposal{},
+		}
+
+		const path = "m/0/1"
+		address, err := libsedrawallet.Address(params, publicKeys, minimumSignatures, path, false)
+		if err != nil {
+			t.Fatalf("Address: %+v", err)
+		}
+		scriptPublicKey, err := txscript.PayToAddrScript(address)
+		if err != nil {
+			t.Fatalf("PayToAddrScript: %+v", err)
+		}
+
+		unsignedTransaction, err := libsedrawallet.CreateUnsignedTransaction(publicKeys, minimumSignatures,
+			[]*libsedrawallet.Payment{{
+				Address: address,
+				Amount:  10,
+			}}, []*libsedrawallet.UTXO{{
+				Outpoint: &externalapi.DomainOutpoint{
+					TransactionID: *externalapi.NewDomainTransactionIDFromByteArray(&[externalapi.DomainHashSize]byte{1}),
+				},
+				UTXOEntry:      utxo.NewUTXOEntry(20, scriptPublicKey, false, 5),
+				DerivationPath: path,
+			}})
+		if err != nil {
+			t.Fatalf("CreateUnsignedTransaction: %+v", err)
+		}
+
+		createResponse, err := serverInstance.CreateMultisigProposal(context.Background(),
+			&pb.CreateMultisigProposalRequest{
+				Transactions: [][]byte{unsignedTransaction},
+				Description:  "test",
+			})
+		if err != nil {
+			t.Fatalf("CreateMultisigProposal: %+v", err)
+		}
+		id := createResponse.Proposal.Id
+
+		_, err = serverInstance.CreateMultisigProposal(context.Background(),
+			&pb.CreateMultisigProposalRequest{Transactions: [][]byte{unsignedTransaction}})
+		if err == nil {
+			t.Fatalf("CreateMultisigProposal unexpectedly created the same proposal twice")
+		}
+
+		signedTransaction, err := libsedrawallet.Sign(params, mnemonics[:1], unsignedTransaction, false)
+		if err != nil {
+			t.Fatalf("Sign: %+v", err)
+		}
+		signResponse, err := serverInstance.SignMultisigProposal(context.Background(),
+			&pb.SignMultisigProposalRequest{Id: id, SignedTransactions: [][]byte{signedTransaction}})
+		if err != nil {
+			t.Fatalf("SignMultisigProposal: %+v", err)
+		}
+		assertCosignersSigned(t, signResponse.Proposal, []bool{true, false, false})
+		if signResponse.Proposal.Status != multisigProposalStatusPending {
+			t.Fatalf("Expected the proposal to be pending, but it's %s", signResponse.Proposal.Status)
+		}
+
+		// A signature that doesn't match the public key it's attached to
+		// must not be accepted
+		otherSignedTransaction, err := libsedrawallet.Sign(params, mnemonics[1:2], unsignedTransaction, false)
+		if err != nil {
+			t.Fatalf("Sign: %+v", err)
+		}
+		tampered, err := serialization.DeserializePartiallySignedTransaction(otherSignedTransaction)
+		if err != nil {
+			t.Fatalf("DeserializePartiallySignedTransaction: %+v", err)
+		}
+		tampered.PartiallySignedInputs[0].PubKeySignaturePairs[1].Signature[0] ^= 1
+		serializedTampered, err := serialization.SerializePSST(tampered)
+		if err != nil {
+			t.Fatalf("SerializePSST: %+v", err)
+		}
+		_, err = serverInstance.SignMultisigProposal(context.Background(),
+			&pb.SignMultisigProposalRequest{Id: id, SignedTransactions: [][]byte{serializedTampered}})
+		if err == nil {
+			t.Fatalf("SignMultisigProposal unexpectedly accepted an invalid signature")
+		}
+
+		// A transaction with more partially signed inputs than inputs must be
+		// rejected rather than crash the daemon
+		mismatched, err := serialization.DeserializePartiallySignedTransaction(signedTransaction)
+		if err != nil {
+			t.Fatalf("DeserializePartiallySignedTransaction: %+v", err)
+		}
+		mismatched.PartiallySignedInputs = append(mismatched.PartiallySignedInputs, mismatched.PartiallySignedInputs[0])
+		serializedMismatched, err := serialization.SerializePartiallySignedTransaction(mismatched)
+		if err != nil {
+			t.Fatalf("SerializePartiallySignedTransaction: %+v", err)
+		}
+		_, err = serverInstance.CreateMultisigProposal(context.Background(),
+			&pb.CreateMultisigProposalRequest{Transactions: [][]byte{serializedMismatched}})
+		if err == nil {
+			t.Fatalf("CreateMultisigProposal unexpectedly accepted a transaction with mismatched inputs")
+		}
+		_, err = serverInstance.SignMultisigProposal(context.Background(),
+			&pb.SignMultisigProposalRequest{Id: id, SignedTransactions: [][]byte{serializedMismatched}})
+		if err == nil {
+			t.Fatalf("SignMultisigProposal unexpectedly accepted a transaction with mismatched inputs")
+		}
+
+		loadedServerInstance := &server{
+			params:            params,
+			keysFile:          keysFile,
+			multisigProposals: map[string]*multisigProposal{},
+		}
+		err = loadedServerInstance.loadMultisigProposals()
+		if err != nil {
+			t.Fatalf("loadMultisigProposals: %+v", err)
+		}
+		getResponse, err := loadedServerInstance.GetMultisigProposals(context.Background(),
+			&pb.GetMultisigProposalsRequest{})
+		if err != nil {
+			t.Fatalf("GetMultisigProposals: %+v", err)
+		}
+		if len(getResponse.Proposals) != 1 {
+			t.Fatalf("Expected 1 loaded proposal, but got %d", len(getResponse.Proposals))
+		}
+		if getResponse.Proposals[0].Id != id || getResponse.Proposals[0].Description != "test" {
+			t.Fatalf("Unexpected loaded proposal %+v", getResponse.Proposals[0])
+		}
+		assertCosignersSigned(t, getResponse.Proposals[0], []bool{true, false, false})
+
+		_, err = loadedServerInstance.CancelMultisigProposal(context.Background(),
+			&pb.CancelMultisigProposalRequest{Id: id})
+		if err != nil {
+			t.Fatalf("CancelMultisigProposal: %+v", err)
+		}
+		_, err = loadedServerInstance.GetMultisigProposals(context.Background(),
+			&pb.GetMultisigProposalsRequest{Id: id})
+		if err == nil {
+			t.Fatalf("GetMultisigProposals unexpectedly returned a cancelled proposal")
+		}
+	})
+}
+
+// TestMultisigProposalsOtherAccount makes sure that the cosigners of the inputs of another
+// account of an unsorted keys file are recognized, and that inputs that aren't spent by the
+// cosigners of the wallet are rejected
+func TestMultisigProposalsOtherAccount(t *testing.T) {
+	params := &dagconfig.MainnetParams
+	const numKeys = 3
+	const minimumSignatures = 2
+	const account = 1
+
+	newCosigners := func() (mnemonics []*libsedrawallet.Mnemonic, publicKeys []string, accountPublicKeys []string) {
+		mnemonics = make([]*libsedrawallet.Mnemonic, numKeys)
+		publicKeys = make([]string, numKeys)
+		accountPublicKeys = make([]string, numKeys)
+		for i := range mnemonics {
+			phrase, err := libsedrawallet.CreateMnemonic()
+			if err != nil {
+				t.Fatalf("CreateMnemonic: %+v", err)
+			}
+			mnemonics[i] = &libsedrawallet.Mnemonic{Phrase: phrase}
+			publicKeys[i], err = libsedrawallet.AccountPublicKeyFromMnemonic(params, mnemonics[i], 0, true)
+			if err != nil {
+				t.Fatalf("AccountPublicKeyFromMnemonic: %+v", err)
+			}
+			accountPublicKeys[i], err = libsedrawallet.AccountPublicKeyFromMnemonic(params, mnemonics[i], account, true)
+			if err != nil {
+				t.Fatalf("AccountPublicKeyFromMnemonic: %+v", err)
+			}
+		}
+		return mnemonics, publicKeys, accountPublicKeys
+	}
+
+	mnemonics, publicKeys, accountPublicKeys := newCosigners()
+
+	// The cosigners are ordered so that the public keys of the keys file aren't sorted,
+	// while the index of a cosigner is the index of its public key among the sorted ones
+	order := []int{0, 1, 2}
+	sort.Slice(order, func(i, j int) bool { return publicKeys[order[i]] > publicKeys[order[j]] })
+	publicKeys = []string{publicKeys[order[0]], publicKeys[order[1]], publicKeys[order[2]]}
+	accountPublicKeys = []string{accountPublicKeys[order[0]], accountPublicKeys[order[1]], accountPublicKeys[order[2]]}
+	secondCosignerMnemonic := mnemonics[order[1]]
+
+	keysFile := &keys.File{
+		Version:            keys.LastVersion,
+		ExtendedPublicKeys: publicKeys,
+		MinimumSignatures:  minimumSignatures,
+	}
+	err := keysFile.SetPath(params, filepath.Join(t.TempDir(), "keys.json"), true)
+	if err != nil {
+		t.Fatalf("SetPath: %+v", err)
+	}
+	err = keysFile.AddAccount(account, accountPublicKeys)
+	if err != nil {
+		t.Fatalf("AddAccount: %+v", err)
+	}
+	serverInstance := &server{
+		params:            params,
+		keysFile:          keysFile,
+		multisigProposals: map[string]*multisigProposal{},
+	}
+
+	createUnsignedTransaction := func(extendedPublicKeys []string) []byte {
+		const path = "m/0/1"
+		address, err := libsedrawallet.Address(params, extendedPublicKeys, minimumSignatures, path, false)
+		if err != nil {
+			t.Fatalf("Address: %+v", err)
+		}
+		scriptPublicKey, err := txscript.PayToAddrScript(address)
+		if err != nil {
+			t.Fatalf("PayToAddrScript: %+v", err)
+		}
+		unsignedTransaction, err := libsedrawallet.CreateUnsignedTransaction(extendedPublicKeys, minimumSignatures,
+			[]*libsedrawallet.Payment{{
+				Address: address,
+				Amount:  10,
+			}}, []*libsedrawallet.UTXO{{
+				Outpoint: &externalapi.DomainOutpoint{
+					TransactionID: *externalapi.NewDomainTransactionIDFromByteArray(&[externalapi.DomainHashSize]byte{1}),
+				},
+				UTXOEntry:      utxo.NewUTXOEntry(20, scriptPublicKey, false, 5),
+				DerivationPath: path,
+			}})
+		if err != nil {
+			t.Fatalf("CreateUnsignedTransaction: %+v", err)
+		}
+		return unsignedTransaction
+	}
+
+	unsignedTransaction := createUnsignedTransaction(accountPublicKeys)
+	createResponse, err := serverInstance.CreateMultisigProposal(context.Background(),
+		&pb.CreateMultisigProposalRequest{Transactions: [][]byte{unsignedTransaction}})
+	if err != nil {
+		t.Fatalf("CreateMultisigProposal: %+v", err)
+	}
+	signedTransaction, err := libsedrawallet.SignWithAccount(params,
+		[]*libsedrawallet.Mnemonic{secondCosignerMnemonic}, account, unsignedTransaction, false)
+	if err != nil {
+		t.Fatalf("SignWithAccount: %+v", err)
+	}
+	signResponse, err := serverInstance.SignMultisigProposal(context.Background(),
+		&pb.SignMultisigProposalRequest{Id: createResponse.Proposal.Id, SignedTransactions: [][]byte{signedTransaction}})
+	if err != nil {
+		t.Fatalf("SignMultisigProposal: %+v", err)
+	}
+	assertCosignersSigned(t, signResponse.Proposal, []bool{false, true, false})
+
+	// The inputs of a transaction of other cosigners, with as many public keys as the
+	// wallet has, must not be accepted
+	_, _, otherPublicKeys := newCosigners()
+	_, err = serverInstance.CreateMultisigProposal(context.Background(),
+		&pb.CreateMultisigProposalRequest{Transactions: [][]byte{createUnsignedTransaction(otherPublicKeys)}})
+	if err == nil {
+		t.Fatalf("CreateMultisigProposal unexpectedly accepted an input of other cosigners")
+	}
+}
+
+func assertCosignersSigned(t *testing.T, proposal *pb.MultisigProposal, expected []bool) {
+	if len(proposal.Cosigners) != len(expected) {
+		t.Fatalf("Expected %d cosigners, but got %d", len(expected), len(proposal.Cosigners))
+	}
+	for i, cosigner := range proposal.Cosigners {
+		signed := cosigner.SignedInputs == cosigner.TotalInputs
+		if signed != expected[i] {
+			t.Fatalf("Expected cosigner %d to have signed: %t, but it has signed %d of %d inputs",
+				i, expected[i], cosigner.SignedInputs, cosigner.TotalInputs)
+		}
+	}
+}
+
+type mnemonicsByPublicKey struct {
+	mnemonics  []string
+	publicKeys []string
+}
+
+func (m *mnemonicsByPublicKey) Len() int {
+	return len(m.publicKeys)
+}
+
+func (m *mnemonicsByPublicKey) Less(i, j int) bool {
+	return m.publicKeys[i] < m.publicKeys[j]
+}
+
+func (m *mnemonicsByPublicKey) Swap(i, j int) {
+	m.mnemonics[i], m.mnemonics[j] = m.mnemonics[j], m.mnemonics[i]
+	m.publicKeys[i], m.publicKeys[j] = m.publicKeys[j], m.publicKeys[i]
+}
@@ -0,0 +1,225 @@
+package server
+
+import (
+	"context"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/pb"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
+)
+
+// Rescan makes the daemon forget its sync state and rediscover the used
+// addresses of the wallet. The rescan itself is done by the sync loop, so
+// Rescan returns as soon as it's requested.
+func (s *server) Rescan(_ context.Context, request *pb.RescanRequest) (*pb.RescanResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	err := s.removeSyncState()
+	if err != nil {
+		return nil, err
+	}
+
+	s.addressSet = make(walletAddressSet)
+	s.utxosSortedByAmount = []*walletUTXO{}
+	s.nextSyncStartIndex = 0
+	s.nextRecentScanIndex = 0
+	s.isSyncStateDirty = true
+	s.isLogFinalProgressLineShown = false
+	s.maxUsedAddressesForLog = 0
+	s.maxProcessedAddressesForLog = 0
+
+	s.isRescanRequested = true
+	s.rescanFromDAAScore = request.FromDAAScore
+
+	log.Infof("Rescan requested, rediscovering the used addresses...")
+	return &pb.RescanResponse{}, nil
+}
+
+// scanBlocksIfRescanRequested scans the blocks from the DAA score given to
+// the last Rescan request, if any, for payments to addresses of the wallet.
+// This finds used addresses that are further than gapLimit from the previous
+// used address, which the address scan by itself would miss.
+func (s *server) scanBlocksIfRescanRequested() error {
+	s.lock.Lock()
+	if !s.isRescanRequested {
+		s.lock.Unlock()
+		return nil
+	}
+	s.isRescanRequested = false
+	fromDAAScore := s.rescanFromDAAScore
+	s.lock.Unlock()
+
+	if fromDAAScore == 0 {
+		return nil
+	}
+
+	log.Infof("Scanning the blocks from DAA score %d...", fromDAAScore)
+	lowHash, err := s.findBlockScanStart(fromDAAScore)
+	if err != nil {
+		return err
+	}
+
+	addresses := &blockScanAddresses{
+		addressSet: make(walletAddressSet),
+		end:        0,
+	}
+	numScannedBlocks := 0
+	err = s.forEachBlockPage(lowHash, true, func(blocks []*appmessage.RPCBlock) (bool, error) {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		for _, block := range blocks {
+			if block.Header.DAAScore < fromDAAScore {
+				continue
+			}
+			err := s.updateLastUsedIndexesFromBlock(block, addresses)
+			if err != nil {
+				return false, err
+			}
+			numScannedBlocks++
+		}
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Scanned %d blocks, highest used address index is %d", numScannedBlocks, s.maxUsedIndexWithLock())
+	return nil
+}
+
+// findBlockScanStart returns the hash of the highest chain block with a DAA
+// score lower than fromDAAScore, or the pruning point if there's no such
+// block above it.
+func (s *server) findBlockScanStart(fromDAAScore uint64) (string, error) {
+	getBlockDAGInfoResponse, err := s.rpcClient.GetBlockDAGInfo()
+	if err != nil {
+		return "", err
+	}
+
+	startHash := getBlockDAGInfoResponse.PruningPointHash
+	err = s.forEachBlockPage(startHash, false, func(blocks []*appmessage.RPCBlock) (bool, error) {
+		for _, block := range blocks {
+			if !block.VerboseData.IsChainBlock {
+				continue
+			}
+			if block.Header.DAAScore >= fromDAAScore {
+				return false, nil
+			}
+			startHash = block.VerboseData.Hash
+		}
+		return true, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return startHash, nil
+}
+
+// forEachBlockPage calls handlePage with the blocks that follow lowHash, a page
+// of GetBlocks at a time, until handlePage returns false or there are no more
+// blocks. Every block is passed to handlePage once.
+func (s *server) forEachBlockPage(lowHash string, includeTransactions bool,
+	handlePage func(blocks []*appmessage.RPCBlock) (bool, error)) error {
+
+	previousPageHashes := make(map[string]struct{})
+	for {
+		getBlocksResponse, err := s.rpcClient.GetBlocks(lowHash, true, includeTransactions)
+		if err != nil {
+			return err
+		}
+
+		nextLowHash := lowHash
+		highestBlueScore := uint64(0)
+		pageHashes := make(map[string]struct{}, len(getBlocksResponse.Blocks))
+		blocks := make([]*appmessage.RPCBlock, 0, len(getBlocksResponse.Blocks))
+		for _, block := range getBlocksResponse.Blocks {
+			pageHashes[block.VerboseData.Hash] = struct{}{}
+			if block.VerboseData.IsChainBlock && block.Header.BlueScore > highestBlueScore {
+				nextLowHash = block.VerboseData.Hash
+				highestBlueScore = block.Header.BlueScore
+			}
+			if _, ok := previousPageHashes[block.VerboseData.Hash]; ok {
+				continue
+			}
+			blocks = append(blocks, block)
+		}
+
+		shouldContinue, err := handlePage(blocks)
+		if err != nil {
+			return err
+		}
+		if !shouldContinue || nextLowHash == lowHash {
+			return nil
+		}
+
+		lowHash = nextLowHash
+		previousPageHashes = pageHashes
+	}
+}
+
+// blockScanAddresses holds the addresses of the wallet that a block scan looks
+// for: all the addresses with an index lower than end.
+type blockScanAddresses struct {
+	addressSet walletAddressSet
+	end        uint32
+}
+
+// extend adds the addresses up to gapLimit indexes after the highest used
+// index, if they're not there yet.
+func (addresses *blockScanAddresses) extend(s *server) error {
+	end := s.syncEndIndex()
+	if end <= addresses.end {
+		return nil
+	}
+
+	newAddressSet, err := s.addressesToQuery(addresses.end, end)
+	if err != nil {
+		return err
+	}
+	for addressString, address := range newAddressSet {
+		addresses.addressSet[addressString] = address
+	}
+	addresses.end = end
+	return nil
+}
+
+// updateLastUsedIndexesFromBlock raises the last used indexes of the wallet's
+// accounts to the indexes of the addresses paid by the outputs of block.
+func (s *server) updateLastUsedIndexesFromBlock(block *appmessage.RPCBlock, addresses *blockScanAddresses) error {
+	for _, transaction := range block.Transactions {
+		for _, output := range transaction.Outputs {
+			if output.VerboseData == nil {
+				continue
+			}
+
+			// The addresses to look for are extended with every used address
+			err := addresses.extend(s)
+			if err != nil {
+				return err
+			}
+
+			walletAddress, ok := addresses.addressSet[output.VerboseData.ScriptPublicKeyAddress]
+			if !ok {
+				continue
+			}
+
+			if walletAddress.keyChain == libsedrawallet.ExternalKeychain {
+				if walletAddress.index > s.keysFile.LastUsedExternalIndex(walletAddress.account) {
+					err = s.keysFile.SetLastUsedExternalIndex(walletAddress.account, walletAddress.index)
+				}
+			} else {
+				if walletAddress.index > s.keysFile.LastUsedInternalIndex(walletAddress.account) {
+					err = s.keysFile.SetLastUsedInternalIndex(walletAddress.account, walletAddress.index)
+				}
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
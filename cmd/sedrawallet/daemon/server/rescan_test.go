@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/pb"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
+	"github.com/sedracoin/sedrad/domain/dagconfig"
+)
+
+// newRescanTestChain returns a chain of numBlocks blocks, where the DAA and
+// blue scores of every block are its index, and every other block is a
+// non-chain block merged by the following one.
+func newRescanTestChain(numBlocks int) []*appmessage.RPCBlock {
+	blocks := make([]*appmessage.RPCBlock, numBlocks)
+	for i := range blocks {
+		blocks[i] = &appmessage.RPCBlock{
+			Header: &appmessage.RPCBlockHeader{DAAScore: uint64(i), BlueScore: uint64(i)},
+			VerboseData: &appmessage.RPCBlockVerboseData{
+				Hash:         fmt.Sprintf("block-%d", i),
+				IsChainBlock: i%2 == 0,
+			},
+		}
+	}
+	return blocks
+}
+
+func addRescanTestPayment(t *testing.T, serverInstance *server, block *appmessage.RPCBlock, address *walletAddress) {
+	addressString, err := serverInstance.walletAddressString(address)
+	if err != nil {
+		t.Fatalf("walletAddressString: %+v", err)
+	}
+	block.Transactions = append(block.Transactions, &appmessage.RPCTransaction{
+		Outputs: []*appmessage.RPCTransactionOutput{{
+			Amount:      1,
+			VerboseData: &appmessage.RPCTransactionOutputVerboseData{ScriptPublicKeyAddress: addressString},
+		}},
+	})
+}
+
+func TestForEachBlockPage(t *testing.T) {
+	blocks := newRescanTestChain(25)
+	serverInstance := &server{rpcClient: &stubRPCClient{blocks: blocks, blocksPageSize: 4}}
+
+	timesPassed := make(map[string]int)
+	err := serverInstance.forEachBlockPage(blocks[0].VerboseData.Hash, false, func(page []*appmessage.RPCBlock) (bool, error) {
+		for _, block := range page {
+			timesPassed[block.VerboseData.Hash]++
+		}
+		return true, nil
+	})
+	if err != nil {
+		t.Fatalf("forEachBlockPage: %+v", err)
+	}
+
+	for _, block := range blocks {
+		if timesPassed[block.VerboseData.Hash] != 1 {
+			t.Fatalf("Expected block %s to be passed once but it was passed %d times",
+				block.VerboseData.Hash, timesPassed[block.VerboseData.Hash])
+		}
+	}
+
+	numPages := 0
+	err = serverInstance.forEachBlockPage(blocks[0].VerboseData.Hash, false, func(page []*appmessage.RPCBlock) (bool, error) {
+		numPages++
+		return false, nil
+	})
+	if err != nil {
+		t.Fatalf("forEachBlockPage: %+v", err)
+	}
+	if numPages != 1 {
+		t.Fatalf("Expected the pages to stop after the first one but got %d pages", numPages)
+	}
+}
+
+func TestRescanFromDAAScore(t *testing.T) {
+	params := &dagconfig.SimnetParams
+	serverInstance := newSyncStateTestServer(t, params, filepath.Join(t.TempDir(), "keys.json"))
+	serverInstance.gapLimit = 20
+
+	blocks := newRescanTestChain(40)
+	const fromDAAScore = 8
+
+	// The payment before fromDAAScore is within the gap of the other payments,
+	// but shouldn't be found. Every other payment is only within the gap of
+	// the ones before it.
+	addRescanTestPayment(t, serverInstance, blocks[3],
+		&walletAddress{account: 0, index: 48, cosignerIndex: 0, keyChain: libsedrawallet.ExternalKeychain})
+	addRescanTestPayment(t, serverInstance, blocks[10],
+		&walletAddress{account: 0, index: 12, cosignerIndex: 0, keyChain: libsedrawallet.ExternalKeychain})
+	addRescanTestPayment(t, serverInstance, blocks[15],
+		&walletAddress{account: 0, index: 30, cosignerIndex: 0, keyChain: libsedrawallet.ExternalKeychain})
+	addRescanTestPayment(t, serverInstance, blocks[27],
+		&walletAddress{account: 0, index: 45, cosignerIndex: 0, keyChain: libsedrawallet.InternalKeychain})
+
+	serverInstance.rpcClient = &stubRPCClient{
+		pruningPointHash: blocks[0].VerboseData.Hash,
+		blocks:           blocks,
+		blocksPageSize:   5,
+	}
+
+	scanStart, err := serverInstance.findBlockScanStart(fromDAAScore)
+	if err != nil {
+		t.Fatalf("findBlockScanStart: %+v", err)
+	}
+	if scanStart != blocks[6].VerboseData.Hash {
+		t.Fatalf("Expected the scan to start from %s but it starts from %s", blocks[6].VerboseData.Hash, scanStart)
+	}
+
+	_, err = serverInstance.Rescan(context.Background(), &pb.RescanRequest{FromDAAScore: fromDAAScore})
+	if err != nil {
+		t.Fatalf("Rescan: %+v", err)
+	}
+	err = serverInstance.scanBlocksIfRescanRequested()
+	if err != nil {
+		t.Fatalf("scanBlocksIfRescanRequested: %+v", err)
+	}
+	if serverInstance.isRescanRequested {
+		t.Fatalf("The rescan is still requested after the blocks were scanned")
+	}
+
+	if serverInstance.keysFile.LastUsedExternalIndex(0) != 30 {
+		t.Fatalf("Expected the last used external index to be 30 but got %d",
+			serverInstance.keysFile.LastUsedExternalIndex(0))
+	}
+	if serverInstance.keysFile.LastUsedInternalIndex(0) != 45 {
+		t.Fatalf("Expected the last used internal index to be 45 but got %d",
+			serverInstance.keysFile.LastUsedInternalIndex(0))
+	}
+}
@@ -3,10 +3,25 @@ package server
 import (
 	"time"
 
+	"github.com/sedracoin/sedrad/app/appmessage"
 	"github.com/sedracoin/sedrad/domain/dagconfig"
 	"github.com/sedracoin/sedrad/infrastructure/network/rpcclient"
 )
 
+// daemonRPCClient is the part of the RPC client that the daemon uses. The
+// daemon depends on it rather than on *rpcclient.RPCClient so its syncing can
+// be tested without a node.
+type daemonRPCClient interface {
+	GetInfo() (*appmessage.GetInfoResponseMessage, error)
+	GetBlockDAGInfo() (*appmessage.GetBlockDAGInfoResponseMessage, error)
+	GetBlocks(lowHash string, includeBlocks bool, includeTransactions bool) (*appmessage.GetBlocksResponseMessage, error)
+	GetBalancesByAddresses(addresses []string) (*appmessage.GetBalancesByAddressesResponseMessage, error)
+	GetUTXOsByAddresses(addresses []string) (*appmessage.GetUTXOsByAddressesResponseMessage, error)
+	GetMempoolEntriesByAddresses(addresses []string, includeOrphanPool bool,
+		filterTransactionPool bool) (*appmessage.GetMempoolEntriesByAddressesResponseMessage, error)
+	SubmitTransaction(transaction *appmessage.RPCTransaction, allowOrphan bool) (*appmessage.SubmitTransactionResponseMessage, error)
+}
+
 func connectToRPC(params *dagconfig.Params, rpcServer string, timeout uint32) (*rpcclient.RPCClient, error) {
 	rpcAddress, err := params.NormalizeRPCServerAddress(rpcServer)
 	if err != nil {
@@ -10,14 +10,14 @@ func (s *server) Send(_ context.Context, request *pb.SendRequest) (*pb.SendRespo
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	unsignedTransactions, err := s.createUnsignedTransactions(request.ToAddress, request.Amount, request.IsSendAll,
-		request.From, request.UseExistingChangeAddress)
+	unsignedTransactions, err := s.createUnsignedTransactions(request.Account, request.ToAddress, request.Amount,
+		request.IsSendAll, request.From, request.UseExistingChangeAddress)
 
 	if err != nil {
 		return nil, err
 	}
 
-	signedTransactions, err := s.signTransactions(unsignedTransactions, request.Password)
+	signedTransactions, err := s.signTransactions(request.Account, unsignedTransactions, request.Password)
 	if err != nil {
 		return nil, err
 	}
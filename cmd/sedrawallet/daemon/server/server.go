@@ -16,7 +16,6 @@ import (
 	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/pb"
 	"github.com/sedracoin/sedrad/cmd/sedrawallet/keys"
 	"github.com/sedracoin/sedrad/domain/dagconfig"
-	"github.com/sedracoin/sedrad/infrastructure/network/rpcclient"
 	"github.com/sedracoin/sedrad/infrastructure/os/signal"
 	"github.com/sedracoin/sedrad/util/panics"
 	"github.com/pkg/errors"
@@ -27,7 +26,7 @@ import (
 type server struct {
 	pb.UnimplementedSedrawalletdServer
 
-	rpcClient *rpcclient.RPCClient
+	rpcClient daemonRPCClient
 	params    *dagconfig.Params
 
 	lock                sync.RWMutex
@@ -38,6 +37,15 @@ type server struct {
 	addressSet          walletAddressSet
 	txMassCalculator    *txmass.Calculator
 	usedOutpoints       map[externalapi.DomainOutpoint]time.Time
+	gapLimit            uint32
+	nextRecentScanIndex uint32
+	isSyncStateDirty    bool
+
+	isRescanRequested  bool
+	rescanFromDAAScore uint64
+
+	multisigProposals map[string]*multisigProposal
+	invoices          map[string]*invoice
 
 	isLogFinalProgressLineShown bool
 	maxUsedAddressesForLog      uint32
@@ -49,7 +57,9 @@ type server struct {
 const MaxDaemonSendMsgSize = 100_000_000
 
 // Start starts the sedrawalletd server
-func Start(params *dagconfig.Params, listen, rpcServer string, keysFilePath string, profile string, timeout uint32) error {
+func Start(params *dagconfig.Params, listen, rpcServer string, keysFilePath string, profile string, timeout uint32,
+	gapLimit uint32) error {
+
 	initLog(defaultLogFile, defaultErrLogFile)
 
 	defer panics.HandlePanic(log, "MAIN", nil)
@@ -59,6 +69,10 @@ func Start(params *dagconfig.Params, listen, rpcServer string, keysFilePath stri
 		profiling.Start(profile, log)
 	}
 
+	if gapLimit == 0 {
+		return errors.New("the gap limit must be positive")
+	}
+
 	listener, err := net.Listen("tcp", listen)
 	if err != nil {
 		return (errors.Wrapf(err, "Error listening to TCP on %s", listen))
@@ -92,11 +106,33 @@ func Start(params *dagconfig.Params, listen, rpcServer string, keysFilePath stri
 		addressSet:                  make(walletAddressSet),
 		txMassCalculator:            txmass.NewCalculator(params.MassPerTxByte, params.MassPerScriptPubKeyByte, params.MassPerSigOp),
 		usedOutpoints:               map[externalapi.DomainOutpoint]time.Time{},
+		gapLimit:                    gapLimit,
+		nextRecentScanIndex:         0,
+		isSyncStateDirty:            false,
+		isRescanRequested:           false,
+		rescanFromDAAScore:          0,
+		multisigProposals:           map[string]*multisigProposal{},
+		invoices:                    map[string]*invoice{},
 		isLogFinalProgressLineShown: false,
 		maxUsedAddressesForLog:      0,
 		maxProcessedAddressesForLog: 0,
 	}
 
+	err = serverInstance.loadSyncState()
+	if err != nil {
+		return err
+	}
+
+	err = serverInstance.loadMultisigProposals()
+	if err != nil {
+		return err
+	}
+
+	err = serverInstance.loadInvoices()
+	if err != nil {
+		return err
+	}
+
 	log.Infof("Read, syncing the wallet...")
 	spawn("serverInstance.sync", func() {
 		err := serverInstance.sync()
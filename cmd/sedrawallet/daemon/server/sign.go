@@ -12,21 +12,27 @@ func (s *server) Sign(_ context.Context, request *pb.SignRequest) (*pb.SignRespo
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	signedTransactions, err := s.signTransactions(request.UnsignedTransactions, request.Password)
+	signedTransactions, err := s.signTransactions(request.Account, request.UnsignedTransactions, request.Password)
 	if err != nil {
 		return nil, err
 	}
 	return &pb.SignResponse{SignedTransactions: signedTransactions}, nil
 }
 
-func (s *server) signTransactions(unsignedTransactions [][]byte, password string) ([][]byte, error) {
+func (s *server) signTransactions(account uint32, unsignedTransactions [][]byte, password string) ([][]byte, error) {
+	err := s.checkAccount(account)
+	if err != nil {
+		return nil, err
+	}
+
 	mnemonics, err := s.keysFile.DecryptMnemonics(password)
 	if err != nil {
 		return nil, err
 	}
 	signedTransactions := make([][]byte, len(unsignedTransactions))
 	for i, unsignedTransaction := range unsignedTransactions {
-		signedTransaction, err := libsedrawallet.Sign(s.params, mnemonics, unsignedTransaction, s.keysFile.ECDSA)
+		signedTransaction, err := libsedrawallet.SignWithAccount(s.params, mnemonics, account, unsignedTransaction,
+			s.keysFile.ECDSA)
 		if err != nil {
 			return nil, err
 		}
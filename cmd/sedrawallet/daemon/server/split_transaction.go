@@ -21,13 +21,14 @@ import (
 // An additional `mergeTransaction` is generated - which merges the outputs of the above splits into a single output
 // paying to the original transaction's payee.
 func (s *server) maybeAutoCompoundTransaction(transactionBytes []byte, toAddress util.Address,
-	changeAddress util.Address, changeWalletAddress *walletAddress) ([][]byte, error) {
+	changeAddress util.Address, changeWalletAddress *walletAddress, feeRate uint64) ([][]byte, error) {
 	transaction, err := serialization.DeserializePartiallySignedTransaction(transactionBytes)
 	if err != nil {
 		return nil, err
 	}
 
-	splitTransactions, err := s.maybeSplitAndMergeTransaction(transaction, toAddress, changeAddress, changeWalletAddress)
+	splitTransactions, err := s.maybeSplitAndMergeTransaction(transaction, toAddress, changeAddress, changeWalletAddress,
+		feeRate)
 	if err != nil {
 		return nil, err
 	}
@@ -47,6 +48,7 @@ func (s *server) mergeTransaction(
 	toAddress util.Address,
 	changeAddress util.Address,
 	changeWalletAddress *walletAddress,
+	feeRate uint64,
 ) (*serialization.PartiallySignedTransaction, error) {
 	numOutputs := len(originalTransaction.Tx.Outputs)
 	if numOutputs > 2 || numOutputs == 0 {
@@ -57,6 +59,11 @@ func (s *server) mergeTransaction(
 			len(originalTransaction.Tx.Outputs))
 	}
 
+	extendedPublicKeys, err := s.keysFile.AccountExtendedPublicKeys(changeWalletAddress.account)
+	if err != nil {
+		return nil, err
+	}
+
 	totalValue := uint64(0)
 	sentValue := originalTransaction.Tx.Outputs[0].Value
 	utxos := make([]*libsedrawallet.UTXO, len(splitTransactions))
@@ -71,42 +78,63 @@ func (s *server) mergeTransaction(
 			DerivationPath: s.walletAddressPath(changeWalletAddress),
 		}
 		totalValue += output.Value
-		totalValue -= feePerInput
 	}
 
-	if totalValue < sentValue {
-		// sometimes the fees from compound transactions make the total output higher than what's available from selected
-		// utxos, in such cases - find one more UTXO and use it.
-		additionalUTXOs, totalValueAdded, err := s.moreUTXOsForMergeTransaction(utxos, sentValue-totalValue)
+	// The fee of the merge transaction depends on the number of its inputs, so
+	// more UTXOs are added until the merge transaction can pay for itself
+	fee := uint64(0)
+	for i := 0; ; i++ {
+		if i == maxFeeEstimationIterations {
+			return nil, errors.Errorf("merge transaction fee did not converge after %d iterations",
+				maxFeeEstimationIterations)
+		}
+
+		if totalValue < sentValue+fee {
+			// sometimes the fees from compound transactions make the total output higher than what's available from selected
+			// utxos, in such cases - find more UTXOs and use them.
+			additionalUTXOs, totalValueAdded, err := s.moreUTXOsForMergeTransaction(changeWalletAddress.account, utxos,
+				sentValue+fee-totalValue)
+			if err != nil {
+				return nil, err
+			}
+			utxos = append(utxos, additionalUTXOs...)
+			totalValue += totalValueAdded
+		}
+
+		payments := []*libsedrawallet.Payment{{
+			Address: toAddress,
+			Amount:  sentValue,
+		}}
+		if totalValue > sentValue+fee {
+			payments = append(payments, &libsedrawallet.Payment{
+				Address: changeAddress,
+				Amount:  totalValue - sentValue - fee,
+			})
+		}
+
+		mergeTransactionBytes, err := libsedrawallet.CreateUnsignedTransaction(extendedPublicKeys,
+			s.keysFile.MinimumSignatures, payments, utxos)
 		if err != nil {
 			return nil, err
 		}
-		utxos = append(utxos, additionalUTXOs...)
-		totalValue += totalValueAdded
-	}
 
-	payments := []*libsedrawallet.Payment{{
-		Address: toAddress,
-		Amount:  sentValue,
-	}}
-	if totalValue > sentValue {
-		payments = append(payments, &libsedrawallet.Payment{
-			Address: changeAddress,
-			Amount:  totalValue - sentValue,
-		})
-	}
-
-	mergeTransactionBytes, err := libsedrawallet.CreateUnsignedTransaction(s.keysFile.ExtendedPublicKeys,
-		s.keysFile.MinimumSignatures, payments, utxos)
-	if err != nil {
-		return nil, err
+		mergeTransaction, err := serialization.DeserializePartiallySignedTransaction(mergeTransactionBytes)
+		if err != nil {
+			return nil, err
+		}
+		requiredFee, err := s.estimateFee(mergeTransaction, feeRate)
+		if err != nil {
+			return nil, err
+		}
+		if requiredFee <= fee {
+			return mergeTransaction, nil
+		}
+		fee = requiredFee
 	}
-
-	return serialization.DeserializePartiallySignedTransaction(mergeTransactionBytes)
 }
 
 func (s *server) maybeSplitAndMergeTransaction(transaction *serialization.PartiallySignedTransaction, toAddress util.Address,
-	changeAddress util.Address, changeWalletAddress *walletAddress) ([]*serialization.PartiallySignedTransaction, error) {
+	changeAddress util.Address, changeWalletAddress *walletAddress, feeRate uint64) ([]*serialization.PartiallySignedTransaction, error) {
 
 	transactionMass, err := s.estimateMassAfterSignatures(transaction)
 	if err != nil {
@@ -117,7 +145,9 @@ func (s *server) maybeSplitAndMergeTransaction(transaction *serialization.Partia
 		return []*serialization.PartiallySignedTransaction{transaction}, nil
 	}
 
-	splitCount, inputCountPerSplit, err := s.splitAndInputPerSplitCounts(transaction, transactionMass, changeAddress)
+	account := changeWalletAddress.account
+	splitCount, inputCountPerSplit, err := s.splitAndInputPerSplitCounts(account, transaction, transactionMass,
+		changeAddress)
 	if err != nil {
 		return nil, err
 	}
@@ -127,19 +157,22 @@ func (s *server) maybeSplitAndMergeTransaction(transaction *serialization.Partia
 		startIndex := i * inputCountPerSplit
 		endIndex := startIndex + inputCountPerSplit
 		var err error
-		splitTransactions[i], err = s.createSplitTransaction(transaction, changeAddress, startIndex, endIndex)
+		splitTransactions[i], err = s.createSplitTransaction(account, transaction, changeAddress, startIndex, endIndex,
+			feeRate)
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	if len(splitTransactions) > 1 {
-		mergeTransaction, err := s.mergeTransaction(splitTransactions, transaction, toAddress, changeAddress, changeWalletAddress)
+		mergeTransaction, err := s.mergeTransaction(splitTransactions, transaction, toAddress, changeAddress,
+			changeWalletAddress, feeRate)
 		if err != nil {
 			return nil, err
 		}
 		// Recursion will be 2-3 iterations deep even in the rarest` cases, so considered safe..
-		splitMergeTransaction, err := s.maybeSplitAndMergeTransaction(mergeTransaction, toAddress, changeAddress, changeWalletAddress)
+		splitMergeTransaction, err := s.maybeSplitAndMergeTransaction(mergeTransaction, toAddress, changeAddress,
+			changeWalletAddress, feeRate)
 		if err != nil {
 			return nil, err
 		}
@@ -151,8 +184,8 @@ func (s *server) maybeSplitAndMergeTransaction(transaction *serialization.Partia
 }
 
 // splitAndInputPerSplitCounts calculates the number of splits to create, and the number of inputs to assign per split.
-func (s *server) splitAndInputPerSplitCounts(transaction *serialization.PartiallySignedTransaction, transactionMass uint64,
-	changeAddress util.Address) (splitCount, inputsPerSplitCount int, err error) {
+func (s *server) splitAndInputPerSplitCounts(account uint32, transaction *serialization.PartiallySignedTransaction,
+	transactionMass uint64, changeAddress util.Address) (splitCount, inputsPerSplitCount int, err error) {
 
 	// Create a dummy transaction which is a clone of the original transaction, but without inputs,
 	// to calculate how much mass do all the inputs have
@@ -172,7 +205,7 @@ func (s *server) splitAndInputPerSplitCounts(transaction *serialization.Partiall
 
 	// Create another dummy transaction, this time one similar to the split transactions we wish to generate,
 	// but with 0 inputs, to calculate how much mass for inputs do we have available in the split transactions
-	splitTransactionWithoutInputs, err := s.createSplitTransaction(transaction, changeAddress, 0, 0)
+	splitTransactionWithoutInputs, err := s.createSplitTransaction(account, transaction, changeAddress, 0, 0, 0)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -189,8 +222,8 @@ func (s *server) splitAndInputPerSplitCounts(transaction *serialization.Partiall
 	return splitCount, inputsPerSplitCount, nil
 }
 
-func (s *server) createSplitTransaction(transaction *serialization.PartiallySignedTransaction,
-	changeAddress util.Address, startIndex int, endIndex int) (*serialization.PartiallySignedTransaction, error) {
+func (s *server) createSplitTransaction(account uint32, transaction *serialization.PartiallySignedTransaction,
+	changeAddress util.Address, startIndex int, endIndex int, feeRate uint64) (*serialization.PartiallySignedTransaction, error) {
 
 	selectedUTXOs := make([]*libsedrawallet.UTXO, 0, endIndex-startIndex)
 	totalSeep := uint64(0)
@@ -206,13 +239,42 @@ func (s *server) createSplitTransaction(transaction *serialization.PartiallySign
 		})
 
 		totalSeep += selectedUTXOs[i-startIndex].UTXOEntry.Amount()
-		totalSeep -= feePerInput
 	}
-	unsignedTransactionBytes, err := libsedrawallet.CreateUnsignedTransaction(s.keysFile.ExtendedPublicKeys,
+
+	splitTransaction, err := s.createUnsignedSplitTransaction(account, selectedUTXOs, changeAddress, totalSeep)
+	if err != nil {
+		return nil, err
+	}
+	if len(selectedUTXOs) == 0 {
+		return splitTransaction, nil
+	}
+
+	// The output value does not affect the mass, so the fee of the split
+	// transaction can be deducted from its output after the fact
+	fee, err := s.estimateFee(splitTransaction, feeRate)
+	if err != nil {
+		return nil, err
+	}
+	if fee >= totalSeep {
+		return nil, errors.Errorf("Insufficient funds for split transaction: %d seep required for the fee, "+
+			"while only %d available", fee, totalSeep)
+	}
+	return s.createUnsignedSplitTransaction(account, selectedUTXOs, changeAddress, totalSeep-fee)
+}
+
+func (s *server) createUnsignedSplitTransaction(account uint32, selectedUTXOs []*libsedrawallet.UTXO,
+	changeAddress util.Address, amount uint64) (*serialization.PartiallySignedTransaction, error) {
+
+	extendedPublicKeys, err := s.keysFile.AccountExtendedPublicKeys(account)
+	if err != nil {
+		return nil, err
+	}
+
+	unsignedTransactionBytes, err := libsedrawallet.CreateUnsignedTransaction(extendedPublicKeys,
 		s.keysFile.MinimumSignatures,
 		[]*libsedrawallet.Payment{{
 			Address: changeAddress,
-			Amount:  totalSeep,
+			Amount:  amount,
 		}}, selectedUTXOs)
 	if err != nil {
 		return nil, err
@@ -237,7 +299,7 @@ func (s *server) estimateMassAfterSignatures(transaction *serialization.Partiall
 			}
 			pubKeyPair.Signature = make([]byte, signatureSize+1) // +1 for SigHashType
 		}
-		transaction.Tx.Inputs[i].SigOpCount = byte(len(input.PubKeySignaturePairs))
+		transaction.Tx.Inputs[i].SigOpCount = libsedrawallet.PartiallySignedInputSigOpCount(input)
 	}
 
 	transactionWithSignatures, err := libsedrawallet.ExtractTransactionDeserialized(transaction, s.keysFile.ECDSA)
@@ -248,8 +310,8 @@ func (s *server) estimateMassAfterSignatures(transaction *serialization.Partiall
 	return s.txMassCalculator.CalculateTransactionMass(transactionWithSignatures), nil
 }
 
-func (s *server) moreUTXOsForMergeTransaction(alreadySelectedUTXOs []*libsedrawallet.UTXO, requiredAmount uint64) (
-	additionalUTXOs []*libsedrawallet.UTXO, totalValueAdded uint64, err error) {
+func (s *server) moreUTXOsForMergeTransaction(account uint32, alreadySelectedUTXOs []*libsedrawallet.UTXO,
+	requiredAmount uint64) (additionalUTXOs []*libsedrawallet.UTXO, totalValueAdded uint64, err error) {
 
 	dagInfo, err := s.rpcClient.GetBlockDAGInfo()
 	if err != nil {
@@ -261,6 +323,9 @@ func (s *server) moreUTXOsForMergeTransaction(alreadySelectedUTXOs []*libsedrawa
 	}
 
 	for _, utxo := range s.utxosSortedByAmount {
+		if utxo.address.account != account {
+			continue
+		}
 		if _, ok := alreadySelectedUTXOsMap[*utxo.Outpoint]; ok {
 			continue
 		}
@@ -271,7 +336,7 @@ func (s *server) moreUTXOsForMergeTransaction(alreadySelectedUTXOs []*libsedrawa
 			Outpoint:       utxo.Outpoint,
 			UTXOEntry:      utxo.UTXOEntry,
 			DerivationPath: s.walletAddressPath(utxo.address)})
-		totalValueAdded += utxo.UTXOEntry.Amount() - feePerInput
+		totalValueAdded += utxo.UTXOEntry.Amount()
 		if totalValueAdded >= requiredAmount {
 			break
 		}
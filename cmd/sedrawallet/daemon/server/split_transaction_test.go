@@ -150,3 +150,78 @@ func testEstimateMassIncreaseForSignaturesSetUp(t *testing.T, consensusConfig *c
 
 	return unsignedTransaction, mnemonics, params, teardown
 }
+
+func TestSplitAndMergeTransactionFees(t *testing.T) {
+	params := &dagconfig.SimnetParams
+	const feeRate = 1000
+	serverInstance, stub, mnemonic := newFeeTestServer(t, params, feeRate)
+
+	// The first four UTXOs are spent by the original transaction, and the rest
+	// are left for the merge transaction to pay its fee with
+	addFeeTestUTXOs(t, serverInstance, stub, 100_000, 100_000, 100_000, 100_000, 50_000, 50_000, 50_000)
+	err := serverInstance.refreshUTXOs()
+	if err != nil {
+		t.Fatalf("refreshUTXOs: %+v", err)
+	}
+	extendedPublicKeys, err := serverInstance.keysFile.AccountExtendedPublicKeys(0)
+	if err != nil {
+		t.Fatalf("AccountExtendedPublicKeys: %+v", err)
+	}
+	toAddress, err := serverInstance.walletAddressAddress(
+		&walletAddress{account: 0, index: 5, cosignerIndex: 0, keyChain: libsedrawallet.ExternalKeychain})
+	if err != nil {
+		t.Fatalf("walletAddressAddress: %+v", err)
+	}
+
+	// The original transaction sends the whole value of its inputs, so the
+	// fees of the split transactions leave the merge transaction short
+	const sentAmount = 400_000
+	originalUTXOs := make([]*libsedrawallet.UTXO, 4)
+	for i := range originalUTXOs {
+		originalUTXOs[i] = &libsedrawallet.UTXO{
+			Outpoint:       serverInstance.utxosSortedByAmount[i].Outpoint,
+			UTXOEntry:      serverInstance.utxosSortedByAmount[i].UTXOEntry,
+			DerivationPath: serverInstance.walletAddressPath(feeTestAddress),
+		}
+	}
+	originalTransactionBytes, err := libsedrawallet.CreateUnsignedTransaction(extendedPublicKeys,
+		serverInstance.keysFile.MinimumSignatures,
+		[]*libsedrawallet.Payment{{Address: toAddress, Amount: sentAmount}}, originalUTXOs)
+	if err != nil {
+		t.Fatalf("CreateUnsignedTransaction: %+v", err)
+	}
+	originalTransaction, err := serialization.DeserializePartiallySignedTransaction(originalTransactionBytes)
+	if err != nil {
+		t.Fatalf("DeserializePartiallySignedTransaction: %+v", err)
+	}
+
+	changeAddress, changeWalletAddress, err := serverInstance.changeAddress(0, false, nil)
+	if err != nil {
+		t.Fatalf("changeAddress: %+v", err)
+	}
+
+	splitTransactions := make([]*serialization.PartiallySignedTransaction, 2)
+	for i := range splitTransactions {
+		splitTransactions[i], err = serverInstance.createSplitTransaction(0, originalTransaction, changeAddress,
+			i*2, i*2+2, feeRate)
+		if err != nil {
+			t.Fatalf("createSplitTransaction: %+v", err)
+		}
+		checkFeeTestTransactionFee(t, serverInstance, mnemonic, splitTransactions[i], feeRate)
+	}
+
+	mergeTransaction, err := serverInstance.mergeTransaction(splitTransactions, originalTransaction, toAddress,
+		changeAddress, changeWalletAddress, feeRate)
+	if err != nil {
+		t.Fatalf("mergeTransaction: %+v", err)
+	}
+	if len(mergeTransaction.Tx.Inputs) <= len(splitTransactions) {
+		t.Fatalf("Expected the merge transaction to spend more UTXOs than the outputs of the split transactions, "+
+			"but it has %d inputs", len(mergeTransaction.Tx.Inputs))
+	}
+	if mergeTransaction.Tx.Outputs[0].Value != sentAmount {
+		t.Fatalf("Expected the merge transaction to send %d seep but it sends %d",
+			sentAmount, mergeTransaction.Tx.Outputs[0].Value)
+	}
+	checkFeeTestTransactionFee(t, serverInstance, mnemonic, mergeTransaction, feeRate)
+}
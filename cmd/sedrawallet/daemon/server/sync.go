@@ -2,6 +2,7 @@ package server
 
 import (
 	"fmt"
+	"math"
 	"sort"
 	"time"
 
@@ -27,18 +28,18 @@ func (s *server) sync() error {
 	ticker := time.NewTicker(time.Second)
 	defer ticker.Stop()
 
-	err := s.collectRecentAddresses()
+	err := s.refreshExistingUTXOsWithLock()
 	if err != nil {
 		return err
 	}
 
-	err = s.refreshExistingUTXOsWithLock()
-	if err != nil {
-		return err
-	}
+	for {
+		err = s.scanBlocksIfRescanRequested()
+		if err != nil {
+			return err
+		}
 
-	for range ticker.C {
-		err = s.collectFarAddresses()
+		err = s.collectNewAddresses()
 		if err != nil {
 			return err
 		}
@@ -52,35 +53,45 @@ func (s *server) sync() error {
 		if err != nil {
 			return err
 		}
-	}
 
-	return nil
+		err = s.updateInvoicesWithLock()
+		if err != nil {
+			return err
+		}
+
+		err = s.saveSyncStateWithLock()
+		if err != nil {
+			return err
+		}
+
+		<-ticker.C
+	}
 }
 
-const (
-	numIndexesToQueryForFarAddresses    = 100
-	numIndexesToQueryForRecentAddresses = 1000
-)
+const numIndexesToQueryPerBatch = 1000
 
 // addressesToQuery scans the addresses in the given range. Because
 // each cosigner in a multisig has its own unique path for generating
 // addresses it goes over all the cosigners and add their addresses
-// for each key chain.
+// for each key chain. This is done for each of the wallet's accounts.
 func (s *server) addressesToQuery(start, end uint32) (walletAddressSet, error) {
 	addresses := make(walletAddressSet)
-	for index := start; index < end; index++ {
-		for cosignerIndex := uint32(0); cosignerIndex < uint32(len(s.keysFile.ExtendedPublicKeys)); cosignerIndex++ {
-			for _, keychain := range keyChains {
-				address := &walletAddress{
-					index:         index,
-					cosignerIndex: cosignerIndex,
-					keyChain:      keychain,
-				}
-				addressString, err := s.walletAddressString(address)
-				if err != nil {
-					return nil, err
+	for _, account := range s.keysFile.Accounts() {
+		for index := start; index < end; index++ {
+			for cosignerIndex := uint32(0); cosignerIndex < uint32(len(s.keysFile.ExtendedPublicKeys)); cosignerIndex++ {
+				for _, keychain := range keyChains {
+					address := &walletAddress{
+						account:       account,
+						index:         index,
+						cosignerIndex: cosignerIndex,
+						keyChain:      keychain,
+					}
+					addressString, err := s.walletAddressString(address)
+					if err != nil {
+						return nil, err
+					}
+					addresses[addressString] = address
 				}
-				addresses[addressString] = address
 			}
 		}
 	}
@@ -88,20 +99,6 @@ func (s *server) addressesToQuery(start, end uint32) (walletAddressSet, error) {
 	return addresses, nil
 }
 
-// collectFarAddresses collects numIndexesToQueryForFarAddresses addresses
-// from the last point it stopped in the previous call.
-func (s *server) collectFarAddresses() error {
-	s.lock.Lock()
-	defer s.lock.Unlock()
-	err := s.collectAddresses(s.nextSyncStartIndex, s.nextSyncStartIndex+numIndexesToQueryForFarAddresses)
-	if err != nil {
-		return err
-	}
-
-	s.nextSyncStartIndex += numIndexesToQueryForFarAddresses
-	return nil
-}
-
 func (s *server) maxUsedIndexWithLock() uint32 {
 	s.lock.RLock()
 	defer s.lock.RUnlock()
@@ -109,47 +106,94 @@ func (s *server) maxUsedIndexWithLock() uint32 {
 	return s.maxUsedIndex()
 }
 
+// maxUsedIndex returns the highest used index in any key chain of any
+// of the wallet's accounts
 func (s *server) maxUsedIndex() uint32 {
-	maxUsedIndex := s.keysFile.LastUsedExternalIndex()
-	if s.keysFile.LastUsedInternalIndex() > maxUsedIndex {
-		maxUsedIndex = s.keysFile.LastUsedInternalIndex()
+	maxUsedIndex := uint32(0)
+	for _, account := range s.keysFile.Accounts() {
+		if s.keysFile.LastUsedExternalIndex(account) > maxUsedIndex {
+			maxUsedIndex = s.keysFile.LastUsedExternalIndex(account)
+		}
+		if s.keysFile.LastUsedInternalIndex(account) > maxUsedIndex {
+			maxUsedIndex = s.keysFile.LastUsedInternalIndex(account)
+		}
 	}
 
 	return maxUsedIndex
 }
 
-// collectRecentAddresses collects addresses from used addresses until
-// the address with the index of the last used address + numIndexesToQueryForRecentAddresses.
-// collectRecentAddresses scans addresses in batches of numIndexesToQueryForRecentAddresses,
+// syncEndIndex returns the index up to which addresses should be scanned:
+// gapLimit indexes after the highest used index.
+func (s *server) syncEndIndex() uint32 {
+	maxUsedIndex := s.maxUsedIndex()
+	if maxUsedIndex > math.MaxUint32-s.gapLimit {
+		return math.MaxUint32
+	}
+	return maxUsedIndex + s.gapLimit
+}
+
+// collectNewAddresses collects the addresses that weren't scanned yet, from
+// nextSyncStartIndex until gapLimit indexes after the last used address.
+// collectNewAddresses scans addresses in batches of numIndexesToQueryPerBatch,
 // and releases the lock between scans.
-func (s *server) collectRecentAddresses() error {
-	index := uint32(0)
-	maxUsedIndex := uint32(0)
-	for ; index < maxUsedIndex+numIndexesToQueryForRecentAddresses; index += numIndexesToQueryForRecentAddresses {
-		err := s.collectAddressesWithLock(index, index+numIndexesToQueryForRecentAddresses)
+func (s *server) collectNewAddresses() error {
+	for {
+		s.lock.Lock()
+		start := s.nextSyncStartIndex
+		end := s.syncEndIndex()
+		if start >= end {
+			maxUsedIndex := s.maxUsedIndex()
+			s.lock.Unlock()
+
+			s.updateSyncingProgressLog(start, maxUsedIndex)
+			return nil
+		}
+		if end-start > numIndexesToQueryPerBatch {
+			end = start + numIndexesToQueryPerBatch
+		}
 
+		err := s.collectAddresses(start, end)
 		if err != nil {
+			s.lock.Unlock()
 			return err
 		}
-		maxUsedIndex = s.maxUsedIndexWithLock()
-
-		s.updateSyncingProgressLog(index, maxUsedIndex)
-	}
+		s.nextSyncStartIndex = end
+		s.isSyncStateDirty = true
+		maxUsedIndex := s.maxUsedIndex()
+		s.lock.Unlock()
 
-	s.lock.Lock()
-	if index > s.nextSyncStartIndex {
-		s.nextSyncStartIndex = index
+		s.updateSyncingProgressLog(end, maxUsedIndex)
 	}
-	s.lock.Unlock()
-
-	return nil
 }
 
-func (s *server) collectAddressesWithLock(start, end uint32) error {
+// collectRecentAddresses re-scans a batch of numIndexesToQueryPerBatch of the
+// already scanned indexes, so payments to addresses that were handed out but
+// weren't used at the time they were scanned are found as well. Each call
+// continues from where the previous one stopped, and wraps around once it
+// reaches nextSyncStartIndex.
+func (s *server) collectRecentAddresses() error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	return s.collectAddresses(start, end)
+	if s.nextRecentScanIndex >= s.nextSyncStartIndex {
+		s.nextRecentScanIndex = 0
+	}
+	start := s.nextRecentScanIndex
+	end := s.nextSyncStartIndex
+	if end-start > numIndexesToQueryPerBatch {
+		end = start + numIndexesToQueryPerBatch
+	}
+	if start >= end {
+		return nil
+	}
+
+	err := s.collectAddresses(start, end)
+	if err != nil {
+		return err
+	}
+
+	s.nextRecentScanIndex = end
+	return nil
 }
 
 func (s *server) collectAddresses(start, end uint32) error {
@@ -173,8 +217,12 @@ func (s *server) collectAddresses(start, end uint32) error {
 
 func (s *server) updateAddressesAndLastUsedIndexes(requestedAddressSet walletAddressSet,
 	getBalancesByAddressesResponse *appmessage.GetBalancesByAddressesResponseMessage) error {
-	lastUsedExternalIndex := s.keysFile.LastUsedExternalIndex()
-	lastUsedInternalIndex := s.keysFile.LastUsedInternalIndex()
+	lastUsedExternalIndexes := make(map[uint32]uint32)
+	lastUsedInternalIndexes := make(map[uint32]uint32)
+	for _, account := range s.keysFile.Accounts() {
+		lastUsedExternalIndexes[account] = s.keysFile.LastUsedExternalIndex(account)
+		lastUsedInternalIndexes[account] = s.keysFile.LastUsedInternalIndex(account)
+	}
 
 	for _, entry := range getBalancesByAddressesResponse.Entries {
 		walletAddress, ok := requestedAddressSet[entry.Address]
@@ -186,26 +234,37 @@ func (s *server) updateAddressesAndLastUsedIndexes(requestedAddressSet walletAdd
 			continue
 		}
 
-		s.addressSet[entry.Address] = walletAddress
+		if _, ok := s.addressSet[entry.Address]; !ok {
+			s.addressSet[entry.Address] = walletAddress
+			s.isSyncStateDirty = true
+		}
 
+		account := walletAddress.account
 		if walletAddress.keyChain == libsedrawallet.ExternalKeychain {
-			if walletAddress.index > lastUsedExternalIndex {
-				lastUsedExternalIndex = walletAddress.index
+			if walletAddress.index > lastUsedExternalIndexes[account] {
+				lastUsedExternalIndexes[account] = walletAddress.index
 			}
 			continue
 		}
 
-		if walletAddress.index > lastUsedInternalIndex {
-			lastUsedInternalIndex = walletAddress.index
+		if walletAddress.index > lastUsedInternalIndexes[account] {
+			lastUsedInternalIndexes[account] = walletAddress.index
 		}
 	}
 
-	err := s.keysFile.SetLastUsedExternalIndex(lastUsedExternalIndex)
-	if err != nil {
-		return err
+	for _, account := range s.keysFile.Accounts() {
+		err := s.keysFile.SetLastUsedExternalIndex(account, lastUsedExternalIndexes[account])
+		if err != nil {
+			return err
+		}
+
+		err = s.keysFile.SetLastUsedInternalIndex(account, lastUsedInternalIndexes[account])
+		if err != nil {
+			return err
+		}
 	}
 
-	return s.keysFile.SetLastUsedInternalIndex(lastUsedInternalIndex)
+	return nil
 }
 
 func (s *server) refreshExistingUTXOsWithLock() error {
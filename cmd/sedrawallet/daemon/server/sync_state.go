@@ -0,0 +1,165 @@
+package server
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/utils"
+	"github.com/pkg/errors"
+)
+
+const syncStateVersion = 1
+
+// syncStateJSON is the sync progress of the daemon, which is kept next to the
+// keys file so a restarted daemon doesn't have to rediscover the used
+// addresses from index 0.
+type syncStateJSON struct {
+	Version            uint32               `json:"version"`
+	ExtendedPublicKeys []string             `json:"publicKeys"`
+	NextSyncStartIndex uint32               `json:"nextSyncStartIndex"`
+	UsedAddresses      []*walletAddressJSON `json:"usedAddresses"`
+}
+
+type walletAddressJSON struct {
+	Account       uint32 `json:"account"`
+	Index         uint32 `json:"index"`
+	CosignerIndex uint32 `json:"cosignerIndex"`
+	KeyChain      uint8  `json:"keyChain"`
+}
+
+func (s *server) syncStatePath() string {
+	return s.keysFile.Path() + ".sync"
+}
+
+// syncStateExtendedPublicKeys returns the extended public keys of all the
+// accounts of the wallet. They are saved with the sync state, so a state
+// that was saved for a different wallet, or before accounts were added,
+// isn't used.
+func (s *server) syncStateExtendedPublicKeys() ([]string, error) {
+	var extendedPublicKeys []string
+	for _, account := range s.keysFile.Accounts() {
+		accountExtendedPublicKeys, err := s.keysFile.AccountExtendedPublicKeys(account)
+		if err != nil {
+			return nil, err
+		}
+		extendedPublicKeys = append(extendedPublicKeys, accountExtendedPublicKeys...)
+	}
+	return extendedPublicKeys, nil
+}
+
+// loadSyncState loads the sync state saved by a previous run of the daemon,
+// if there's one and it belongs to the current wallet.
+func (s *server) loadSyncState() error {
+	file, err := os.Open(s.syncStatePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	syncState := &syncStateJSON{}
+	err = json.NewDecoder(file).Decode(syncState)
+	if err != nil {
+		log.Warnf("Ignoring the sync state in %s: %s", s.syncStatePath(), err)
+		return nil
+	}
+
+	if syncState.Version != syncStateVersion {
+		log.Warnf("Ignoring the sync state in %s: unknown version %d", s.syncStatePath(), syncState.Version)
+		return nil
+	}
+
+	extendedPublicKeys, err := s.syncStateExtendedPublicKeys()
+	if err != nil {
+		return err
+	}
+	if !isSameStringSlice(syncState.ExtendedPublicKeys, extendedPublicKeys) {
+		log.Infof("Ignoring the sync state in %s: it belongs to different keys", s.syncStatePath())
+		return nil
+	}
+
+	for _, addressJSON := range syncState.UsedAddresses {
+		address := &walletAddress{
+			account:       addressJSON.Account,
+			index:         addressJSON.Index,
+			cosignerIndex: addressJSON.CosignerIndex,
+			keyChain:      addressJSON.KeyChain,
+		}
+		addressString, err := s.walletAddressString(address)
+		if err != nil {
+			return errors.Wrapf(err, "invalid address in the sync state in %s", s.syncStatePath())
+		}
+		s.addressSet[addressString] = address
+	}
+	s.nextSyncStartIndex = syncState.NextSyncStartIndex
+
+	log.Infof("Loaded the sync state from %s: %d used addresses, scanned up to index %d",
+		s.syncStatePath(), len(s.addressSet), s.nextSyncStartIndex)
+	return nil
+}
+
+func (s *server) saveSyncStateWithLock() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.saveSyncState()
+}
+
+// saveSyncState saves the sync state if it changed since it was last saved,
+// replacing the previous file atomically.
+func (s *server) saveSyncState() error {
+	if !s.isSyncStateDirty {
+		return nil
+	}
+
+	extendedPublicKeys, err := s.syncStateExtendedPublicKeys()
+	if err != nil {
+		return err
+	}
+
+	syncState := &syncStateJSON{
+		Version:            syncStateVersion,
+		ExtendedPublicKeys: extendedPublicKeys,
+		NextSyncStartIndex: s.nextSyncStartIndex,
+		UsedAddresses:      make([]*walletAddressJSON, 0, len(s.addressSet)),
+	}
+	for _, address := range s.addressSet {
+		syncState.UsedAddresses = append(syncState.UsedAddresses, &walletAddressJSON{
+			Account:       address.account,
+			Index:         address.index,
+			CosignerIndex: address.cosignerIndex,
+			KeyChain:      address.keyChain,
+		})
+	}
+
+	err = utils.WriteJSONFileAtomically(s.syncStatePath(), syncState)
+	if err != nil {
+		return err
+	}
+
+	s.isSyncStateDirty = false
+	return nil
+}
+
+// removeSyncState deletes the saved sync state, if there's one.
+func (s *server) removeSyncState() error {
+	err := os.Remove(s.syncStatePath())
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func isSameStringSlice(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/keys"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
+	"github.com/sedracoin/sedrad/domain/consensus"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/testutils"
+	"github.com/sedracoin/sedrad/domain/dagconfig"
+)
+
+func TestSyncState(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		params := &consensusConfig.Params
+		keysFilePath := filepath.Join(t.TempDir(), "keys.json")
+
+		serverInstance := newSyncStateTestServer(t, params, keysFilePath)
+		usedAddresses := []*walletAddress{
+			{account: 0, index: 0, cosignerIndex: 0, keyChain: libsedrawallet.ExternalKeychain},
+			{account: 0, index: 7, cosignerIndex: 0, keyChain: libsedrawallet.InternalKeychain},
+			{account: 0, index: 1500, cosignerIndex: 0, keyChain: libsedrawallet.ExternalKeychain},
+		}
+		for _, address := range usedAddresses {
+			addressString, err := serverInstance.walletAddressString(address)
+			if err != nil {
+				t.Fatalf("walletAddressString: %+v", err)
+			}
+			serverInstance.addressSet[addressString] = address
+		}
+		serverInstance.nextSyncStartIndex = 2500
+		serverInstance.isSyncStateDirty = true
+
+		err := serverInstance.saveSyncState()
+		if err != nil {
+			t.Fatalf("saveSyncState: %+v", err)
+		}
+		if serverInstance.isSyncStateDirty {
+			t.Fatalf("The sync state is still dirty after it was saved")
+		}
+		if _, err := os.Stat(serverInstance.syncStatePath() + ".tmp"); !os.IsNotExist(err) {
+			t.Fatalf("The temporary sync state file was left behind")
+		}
+
+		loadedServerInstance := &server{
+			params:     params,
+			keysFile:   serverInstance.keysFile,
+			addressSet: make(walletAddressSet),
+		}
+		err = loadedServerInstance.loadSyncState()
+		if err != nil {
+			t.Fatalf("loadSyncState: %+v", err)
+		}
+		if loadedServerInstance.nextSyncStartIndex != serverInstance.nextSyncStartIndex {
+			t.Fatalf("Expected nextSyncStartIndex %d but got %d",
+				serverInstance.nextSyncStartIndex, loadedServerInstance.nextSyncStartIndex)
+		}
+		if len(loadedServerInstance.addressSet) != len(serverInstance.addressSet) {
+			t.Fatalf("Expected %d used addresses but got %d",
+				len(serverInstance.addressSet), len(loadedServerInstance.addressSet))
+		}
+		for addressString, address := range serverInstance.addressSet {
+			loadedAddress, ok := loadedServerInstance.addressSet[addressString]
+			if !ok {
+				t.Fatalf("Address %s is missing from the loaded sync state", addressString)
+			}
+			if *loadedAddress != *address {
+				t.Fatalf("Expected address %s to be %+v but got %+v", addressString, address, loadedAddress)
+			}
+		}
+
+		// A sync state of a different wallet should be ignored
+		otherServerInstance := newSyncStateTestServer(t, params, keysFilePath)
+		err = otherServerInstance.loadSyncState()
+		if err != nil {
+			t.Fatalf("loadSyncState: %+v", err)
+		}
+		if otherServerInstance.nextSyncStartIndex != 0 || len(otherServerInstance.addressSet) != 0 {
+			t.Fatalf("The sync state of a different wallet was loaded")
+		}
+
+		err = serverInstance.removeSyncState()
+		if err != nil {
+			t.Fatalf("removeSyncState: %+v", err)
+		}
+		if _, err := os.Stat(serverInstance.syncStatePath()); !os.IsNotExist(err) {
+			t.Fatalf("The sync state file wasn't removed")
+		}
+		reloadedServerInstance := &server{
+			params:     params,
+			keysFile:   serverInstance.keysFile,
+			addressSet: make(walletAddressSet),
+		}
+		err = reloadedServerInstance.loadSyncState()
+		if err != nil {
+			t.Fatalf("loadSyncState: %+v", err)
+		}
+		if reloadedServerInstance.nextSyncStartIndex != 0 || len(reloadedServerInstance.addressSet) != 0 {
+			t.Fatalf("A sync state was loaded after it was removed")
+		}
+
+		// Removing a sync state that doesn't exist isn't an error
+		err = serverInstance.removeSyncState()
+		if err != nil {
+			t.Fatalf("removeSyncState: %+v", err)
+		}
+	})
+}
+
+func newSyncStateTestServer(t *testing.T, params *dagconfig.Params, keysFilePath string) *server {
+	mnemonic, err := libsedrawallet.CreateMnemonic()
+	if err != nil {
+		t.Fatalf("CreateMnemonic: %+v", err)
+	}
+
+	keysFile, err := keys.NewFileFromMnemonic(params, mnemonic, "password")
+	if err != nil {
+		t.Fatalf("NewFileFromMnemonic: %+v", err)
+	}
+	err = keysFile.SetPath(params, keysFilePath, true)
+	if err != nil {
+		t.Fatalf("SetPath: %+v", err)
+	}
+
+	return &server{
+		params:     params,
+		keysFile:   keysFile,
+		addressSet: make(walletAddressSet),
+	}
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
+	"github.com/sedracoin/sedrad/domain/dagconfig"
+	"github.com/pkg/errors"
+)
+
+// stubRPCClient answers the RPC requests of the sync from fixed data. Requests
+// it doesn't implement panic on the embedded nil daemonRPCClient.
+type stubRPCClient struct {
+	daemonRPCClient
+
+	usedAddresses           map[string]struct{}
+	requestedAddressBatches [][]string
+
+	utxos               []*appmessage.UTXOsByAddressesEntry
+	minimumRelayFeeRate uint64
+
+	pruningPointHash string
+	blocks           []*appmessage.RPCBlock
+	blocksPageSize   int
+}
+
+func (c *stubRPCClient) GetInfo() (*appmessage.GetInfoResponseMessage, error) {
+	return &appmessage.GetInfoResponseMessage{MinimumRelayTransactionFee: c.minimumRelayFeeRate}, nil
+}
+
+func (c *stubRPCClient) GetUTXOsByAddresses(addresses []string) (*appmessage.GetUTXOsByAddressesResponseMessage, error) {
+	requestedAddresses := make(map[string]struct{}, len(addresses))
+	for _, address := range addresses {
+		requestedAddresses[address] = struct{}{}
+	}
+
+	var entries []*appmessage.UTXOsByAddressesEntry
+	for _, entry := range c.utxos {
+		if _, ok := requestedAddresses[entry.Address]; ok {
+			entries = append(entries, entry)
+		}
+	}
+	return appmessage.NewGetUTXOsByAddressesResponseMessage(entries), nil
+}
+
+func (c *stubRPCClient) GetMempoolEntriesByAddresses(_ []string, _ bool, _ bool) (
+	*appmessage.GetMempoolEntriesByAddressesResponseMessage, error) {
+
+	return &appmessage.GetMempoolEntriesByAddressesResponseMessage{}, nil
+}
+
+func (c *stubRPCClient) GetBalancesByAddresses(addresses []string) (*appmessage.GetBalancesByAddressesResponseMessage, error) {
+	c.requestedAddressBatches = append(c.requestedAddressBatches, addresses)
+
+	entries := make([]*appmessage.BalancesByAddressesEntry, 0, len(addresses))
+	for _, address := range addresses {
+		balance := uint64(0)
+		if _, ok := c.usedAddresses[address]; ok {
+			balance = 1
+		}
+		entries = append(entries, &appmessage.BalancesByAddressesEntry{Address: address, Balance: balance})
+	}
+	return appmessage.NewGetBalancesByAddressesResponse(entries), nil
+}
+
+func (c *stubRPCClient) GetBlockDAGInfo() (*appmessage.GetBlockDAGInfoResponseMessage, error) {
+	return &appmessage.GetBlockDAGInfoResponseMessage{PruningPointHash: c.pruningPointHash}, nil
+}
+
+// GetBlocks returns a page of blocksPageSize blocks from lowHash. Like the
+// blocks returned by a node, consecutive pages overlap: every page starts with
+// lowHash, and also includes the block before it.
+func (c *stubRPCClient) GetBlocks(lowHash string, _ bool, _ bool) (*appmessage.GetBlocksResponseMessage, error) {
+	lowIndex := -1
+	for i, block := range c.blocks {
+		if block.VerboseData.Hash == lowHash {
+			lowIndex = i
+			break
+		}
+	}
+	if lowIndex == -1 {
+		return nil, errors.Errorf("block %s not found", lowHash)
+	}
+
+	start := lowIndex - 1
+	if start < 0 {
+		start = 0
+	}
+	end := lowIndex + c.blocksPageSize
+	if end > len(c.blocks) {
+		end = len(c.blocks)
+	}
+
+	blockHashes := make([]string, 0, end-start)
+	for _, block := range c.blocks[start:end] {
+		blockHashes = append(blockHashes, block.VerboseData.Hash)
+	}
+	return &appmessage.GetBlocksResponseMessage{BlockHashes: blockHashes, Blocks: c.blocks[start:end]}, nil
+}
+
+func TestCollectNewAddresses(t *testing.T) {
+	params := &dagconfig.SimnetParams
+	serverInstance := newSyncStateTestServer(t, params, filepath.Join(t.TempDir(), "keys.json"))
+	serverInstance.gapLimit = 1500
+
+	// Every used address is within gapLimit from the previous one, but not
+	// within the same batch of numIndexesToQueryPerBatch indexes, and the
+	// last one is beyond gapLimit from all of them
+	stub := &stubRPCClient{usedAddresses: make(map[string]struct{})}
+	for _, address := range []*walletAddress{
+		{account: 0, index: 1200, cosignerIndex: 0, keyChain: libsedrawallet.ExternalKeychain},
+		{account: 0, index: 2600, cosignerIndex: 0, keyChain: libsedrawallet.InternalKeychain},
+		{account: 0, index: 4200, cosignerIndex: 0, keyChain: libsedrawallet.ExternalKeychain},
+	} {
+		addressString, err := serverInstance.walletAddressString(address)
+		if err != nil {
+			t.Fatalf("walletAddressString: %+v", err)
+		}
+		stub.usedAddresses[addressString] = struct{}{}
+	}
+	serverInstance.rpcClient = stub
+
+	err := serverInstance.collectNewAddresses()
+	if err != nil {
+		t.Fatalf("collectNewAddresses: %+v", err)
+	}
+
+	if serverInstance.keysFile.LastUsedExternalIndex(0) != 1200 {
+		t.Fatalf("Expected the last used external index to be 1200 but got %d",
+			serverInstance.keysFile.LastUsedExternalIndex(0))
+	}
+	if serverInstance.keysFile.LastUsedInternalIndex(0) != 2600 {
+		t.Fatalf("Expected the last used internal index to be 2600 but got %d",
+			serverInstance.keysFile.LastUsedInternalIndex(0))
+	}
+	if len(serverInstance.addressSet) != 2 {
+		t.Fatalf("Expected 2 used addresses but got %d", len(serverInstance.addressSet))
+	}
+	expectedNextSyncStartIndex := uint32(2600 + 1500)
+	if serverInstance.nextSyncStartIndex != expectedNextSyncStartIndex {
+		t.Fatalf("Expected nextSyncStartIndex %d but got %d",
+			expectedNextSyncStartIndex, serverInstance.nextSyncStartIndex)
+	}
+	if !serverInstance.isSyncStateDirty {
+		t.Fatalf("The sync state isn't dirty after new addresses were scanned")
+	}
+
+	numQueriedAddresses := 0
+	for _, addresses := range stub.requestedAddressBatches {
+		if len(addresses) > numIndexesToQueryPerBatch*len(keyChains) {
+			t.Fatalf("Expected at most %d indexes per batch but %d addresses were requested",
+				numIndexesToQueryPerBatch, len(addresses))
+		}
+		numQueriedAddresses += len(addresses)
+	}
+	if numQueriedAddresses != int(expectedNextSyncStartIndex)*len(keyChains) {
+		t.Fatalf("Expected every address up to index %d to be queried once, but %d addresses were queried",
+			expectedNextSyncStartIndex, numQueriedAddresses)
+	}
+
+	// Once the addresses are scanned up to the gap, there's nothing left to collect
+	numBatches := len(stub.requestedAddressBatches)
+	err = serverInstance.collectNewAddresses()
+	if err != nil {
+		t.Fatalf("collectNewAddresses: %+v", err)
+	}
+	if len(stub.requestedAddressBatches) != numBatches {
+		t.Fatalf("Addresses were queried again after the wallet was synced")
+	}
+}
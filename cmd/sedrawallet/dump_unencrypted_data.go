@@ -35,8 +35,12 @@ func dumpUnencryptedData(conf *dumpUnencryptedDataConfig) error {
 
 	mnemonicPublicKeys := make(map[string]struct{})
 	for i, mnemonic := range mnemonics {
-		fmt.Printf("Mnemonic #%d:\n%s\n\n", i+1, mnemonic)
-		publicKey, err := libsedrawallet.MasterPublicKeyFromMnemonic(conf.NetParams(), mnemonic, len(keysFile.ExtendedPublicKeys) > 1)
+		fmt.Printf("Mnemonic #%d:\n%s\n\n", i+1, mnemonic.Phrase)
+		if mnemonic.Passphrase != "" {
+			fmt.Printf("BIP39 passphrase of mnemonic #%d:\n%s\n\n", i+1, mnemonic.Passphrase)
+		}
+		publicKey, err := libsedrawallet.AccountPublicKeyFromMnemonic(conf.NetParams(), mnemonic, 0,
+			len(keysFile.ExtendedPublicKeys) > 1)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/client"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/pb"
+)
+
+func importContract(conf *importContractConfig) error {
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	response, err := daemonClient.ImportContract(ctx, &pb.ImportContractRequest{RedeemScript: conf.RedeemScript})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported contract:\n%s\n", response.Address)
+	return nil
+}
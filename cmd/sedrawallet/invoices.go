@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/client"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/pb"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/utils"
+	"github.com/pkg/errors"
+)
+
+func createInvoice(conf *createInvoiceConfig) error {
+	var amount uint64
+	if conf.Amount != "" {
+		var err error
+		amount, err = utils.SdrToSeep(conf.Amount)
+		if err != nil {
+			return err
+		}
+		if amount == 0 {
+			return errors.New("the amount of an invoice must be positive")
+		}
+	}
+	if conf.ExpiresIn < 0 || (conf.ExpiresIn > 0 && conf.ExpiresIn < time.Second) {
+		return errors.New("an invoice must expire at least a second after it's created")
+	}
+
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	response, err := daemonClient.CreateInvoice(ctx, &pb.CreateInvoiceRequest{
+		Amount:    amount,
+		Label:     conf.Label,
+		Message:   conf.Message,
+		ExpiresIn: uint64(conf.ExpiresIn / time.Second),
+		Account:   conf.Account,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Payment URI:\n%s\n\n", response.Invoice.Uri)
+	printInvoice(response.Invoice)
+	return nil
+}
+
+func showInvoices(conf *showInvoicesConfig) error {
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	response, err := daemonClient.GetInvoices(ctx, &pb.GetInvoicesRequest{Address: conf.Address})
+	if err != nil {
+		return err
+	}
+
+	if len(response.Invoices) == 0 {
+		fmt.Println("There are no invoices")
+		return nil
+	}
+	for _, invoice := range response.Invoices {
+		printInvoice(invoice)
+	}
+	return nil
+}
+
+func printInvoice(invoice *pb.Invoice) {
+	fmt.Printf("Invoice %s\n", invoice.Address)
+	if invoice.Amount != 0 {
+		fmt.Printf("\tAmount: %s SDR\n", strings.TrimSpace(utils.FormatSdr(invoice.Amount)))
+	}
+	if invoice.Label != "" {
+		fmt.Printf("\tLabel: %s\n", invoice.Label)
+	}
+	if invoice.Message != "" {
+		fmt.Printf("\tMessage: %s\n", invoice.Message)
+	}
+	fmt.Printf("\tCreated at: %s\n", time.UnixMilli(invoice.CreatedAt).Format(time.RFC3339))
+	if invoice.ExpiresAt != 0 {
+		fmt.Printf("\tExpires at: %s\n", time.UnixMilli(invoice.ExpiresAt).Format(time.RFC3339))
+	}
+	fmt.Printf("\tStatus: %s\n", invoice.Status)
+	if invoice.ReceivedAmount != 0 {
+		fmt.Printf("\tReceived: %s SDR\n", strings.TrimSpace(utils.FormatSdr(invoice.ReceivedAmount)))
+	}
+	if invoice.PaidAt != 0 {
+		fmt.Printf("\tPaid at: %s\n", time.UnixMilli(invoice.PaidAt).Format(time.RFC3339))
+	}
+	for _, outpoint := range invoice.PaymentOutpoints {
+		fmt.Printf("\tPayment: %s\n", outpoint)
+	}
+	fmt.Println()
+}
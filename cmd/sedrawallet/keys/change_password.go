@@ -0,0 +1,99 @@
+package keys
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
+	"github.com/pkg/errors"
+)
+
+// ChangePassword re-encrypts the mnemonics of the file with newPassword, using
+// the given Argon2 parameters, and upgrades the file to the latest version.
+// Before the file is replaced, its previous contents are copied to a backup
+// file, whose path is returned. The backup can still be decrypted with the
+// old password.
+func (d *File) ChangePassword(oldPassword, newPassword string, kdfParams *KDFParams) (backupPath string, err error) {
+	err = kdfParams.Validate()
+	if err != nil {
+		return "", err
+	}
+
+	// The contents are read before decrypting, because decrypting a version 0
+	// file saves the detected number of threads into it
+	previousContents, err := os.ReadFile(d.path)
+	if err != nil {
+		return "", err
+	}
+
+	mnemonics, err := d.DecryptMnemonics(oldPassword)
+	if err != nil {
+		return "", err
+	}
+
+	encryptedMnemonics, err := encryptAndVerifyMnemonics(mnemonics, []byte(newPassword), kdfParams)
+	if err != nil {
+		return "", err
+	}
+
+	backupPath = fmt.Sprintf("%s.%s.bak", d.path, time.Now().Format("20060102150405"))
+	backupFile, err := os.OpenFile(backupPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", err
+	}
+	_, err = backupFile.Write(previousContents)
+	if err != nil {
+		backupFile.Close()
+		return "", err
+	}
+	err = backupFile.Close()
+	if err != nil {
+		return "", err
+	}
+
+	d.Version = LastVersion
+	d.KDFParams = kdfParams
+	d.EncryptedMnemonics = encryptedMnemonics
+	err = d.Save()
+	if err != nil {
+		return "", errors.Wrapf(err, "error saving the keys file. Its previous contents are kept in %s", backupPath)
+	}
+
+	return backupPath, nil
+}
+
+// encryptAndVerifyMnemonics encrypts mnemonics with password, and makes sure
+// that the result decrypts back to them before the previous ciphers are
+// thrown away.
+func encryptAndVerifyMnemonics(mnemonics []*libsedrawallet.Mnemonic, password []byte, kdfParams *KDFParams) (
+	[]*EncryptedMnemonic, error) {
+
+	encryptedMnemonics := make([]*EncryptedMnemonic, len(mnemonics))
+	for i, mnemonic := range mnemonics {
+		encryptedMnemonic, err := encryptMnemonic(mnemonic, password, kdfParams)
+		if err != nil {
+			return nil, err
+		}
+
+		phrase, err := decryptMnemonic(kdfParams, encryptedMnemonic, password)
+		if err != nil {
+			return nil, err
+		}
+		passphrase := ""
+		if len(encryptedMnemonic.passphraseCipher) > 0 {
+			passphrase, err = decrypt(kdfParams, encryptedMnemonic.passphraseCipher, encryptedMnemonic.passphraseSalt,
+				password)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if phrase != mnemonic.Phrase || passphrase != mnemonic.Passphrase {
+			return nil, errors.Errorf("mnemonic #%d didn't decrypt back to itself", i+1)
+		}
+
+		encryptedMnemonics[i] = encryptedMnemonic
+	}
+
+	return encryptedMnemonics, nil
+}
@@ -0,0 +1,117 @@
+package keys
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
+	"github.com/sedracoin/sedrad/domain/dagconfig"
+)
+
+func TestChangePassword(t *testing.T) {
+	params := &dagconfig.MainnetParams
+	path := filepath.Join(t.TempDir(), "keys.json")
+
+	phrase, err := libsedrawallet.CreateMnemonic()
+	if err != nil {
+		t.Fatalf("CreateMnemonic: %+v", err)
+	}
+	mnemonics := []*libsedrawallet.Mnemonic{
+		{Phrase: phrase},
+		{Phrase: phrase, Passphrase: "passphrase"},
+	}
+
+	// Create a version 1 file, whose KDF parameters aren't recorded in it
+	const oldPassword = "old password"
+	encryptedMnemonics := make([]*EncryptedMnemonic, len(mnemonics))
+	for i, mnemonic := range mnemonics {
+		encryptedMnemonics[i], err = encryptMnemonic(mnemonic, []byte(oldPassword), DefaultKDFParams())
+		if err != nil {
+			t.Fatalf("encryptMnemonic: %+v", err)
+		}
+	}
+	file := &File{
+		Version:            1,
+		EncryptedMnemonics: encryptedMnemonics,
+		MinimumSignatures:  1,
+	}
+	err = file.SetPath(params, path, true)
+	if err != nil {
+		t.Fatalf("SetPath: %+v", err)
+	}
+	err = file.Save()
+	if err != nil {
+		t.Fatalf("Save: %+v", err)
+	}
+
+	weakKDFParams := DefaultKDFParams()
+	weakKDFParams.MemoryKiB /= 2
+	_, err = file.ChangePassword(oldPassword, "new password", weakKDFParams)
+	if err == nil {
+		t.Fatalf("ChangePassword unexpectedly succeeded with weak KDF parameters")
+	}
+
+	_, err = file.ChangePassword("wrong password", "new password", DefaultKDFParams())
+	if err == nil {
+		t.Fatalf("ChangePassword unexpectedly succeeded with the wrong password")
+	}
+
+	const newPassword = "new password"
+	kdfParams := &KDFParams{
+		Time:      2,
+		MemoryKiB: 64 * 1024,
+		Threads:   4,
+	}
+	backupPath, err := file.ChangePassword(oldPassword, newPassword, kdfParams)
+	if err != nil {
+		t.Fatalf("ChangePassword: %+v", err)
+	}
+
+	backupFile, err := ReadKeysFile(params, backupPath)
+	if err != nil {
+		t.Fatalf("ReadKeysFile: %+v", err)
+	}
+	if backupFile.Version != 1 {
+		t.Fatalf("Expected the backup file to be of version 1 but got %d", backupFile.Version)
+	}
+	_, err = backupFile.DecryptMnemonics(oldPassword)
+	if err != nil {
+		t.Fatalf("DecryptMnemonics of the backup file: %+v", err)
+	}
+
+	changedFile, err := ReadKeysFile(params, path)
+	if err != nil {
+		t.Fatalf("ReadKeysFile: %+v", err)
+	}
+	if changedFile.Version != LastVersion {
+		t.Fatalf("Expected the file to be upgraded to version %d but got %d", LastVersion, changedFile.Version)
+	}
+	if changedFile.KDFParams == nil || *changedFile.KDFParams != *kdfParams {
+		t.Fatalf("Expected the KDF parameters %+v but got %+v", kdfParams, changedFile.KDFParams)
+	}
+
+	_, err = changedFile.DecryptMnemonics(oldPassword)
+	if err == nil {
+		t.Fatalf("DecryptMnemonics unexpectedly succeeded with the old password")
+	}
+	decryptedMnemonics, err := changedFile.DecryptMnemonics(newPassword)
+	if err != nil {
+		t.Fatalf("DecryptMnemonics: %+v", err)
+	}
+	for i, mnemonic := range mnemonics {
+		if *decryptedMnemonics[i] != *mnemonic {
+			t.Fatalf("Mnemonic #%d changed after changing the password", i+1)
+		}
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %+v", err)
+	}
+	err = json.Unmarshal(contents, &keysFileJSON{})
+	if err != nil {
+		t.Fatalf("The saved keys file is not valid JSON: %+v", err)
+	}
+}
@@ -14,12 +14,19 @@ import (
 	"github.com/tyler-smith/go-bip39"
 )
 
-// CreateMnemonics generates `numKeys` number of mnemonics.
-func CreateMnemonics(params *dagconfig.Params, numKeys uint32, cmdLinePassword string, isMultisig bool) (encryptedPrivateKeys []*EncryptedMnemonic, extendedPublicKeys []string, err error) {
-	mnemonics := make([]string, numKeys)
+// CreateMnemonics generates `numKeys` number of mnemonics. If askPassphrases is true,
+// the user is asked for an optional bip-39 passphrase for each of them.
+func CreateMnemonics(params *dagconfig.Params, numKeys uint32, cmdLinePassword string, isMultisig bool,
+	askPassphrases bool) (encryptedPrivateKeys []*EncryptedMnemonic, extendedPublicKeys []string, err error) {
+
+	mnemonics := make([]*libsedrawallet.Mnemonic, numKeys)
 	for i := uint32(0); i < numKeys; i++ {
-		var err error
-		mnemonics[i], err = libsedrawallet.CreateMnemonic()
+		phrase, err := libsedrawallet.CreateMnemonic()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		mnemonics[i], err = newMnemonic(phrase, i, askPassphrases)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -28,9 +35,12 @@ func CreateMnemonics(params *dagconfig.Params, numKeys uint32, cmdLinePassword s
 	return encryptedMnemonicExtendedPublicKeyPairs(params, mnemonics, cmdLinePassword, isMultisig)
 }
 
-// ImportMnemonics imports a `numKeys` of mnemonics.
-func ImportMnemonics(params *dagconfig.Params, numKeys uint32, cmdLinePassword string, isMultisig bool) (encryptedPrivateKeys []*EncryptedMnemonic, extendedPublicKeys []string, err error) {
-	mnemonics := make([]string, numKeys)
+// ImportMnemonics imports a `numKeys` of mnemonics. If askPassphrases is true,
+// the user is asked for the bip-39 passphrase of each of them.
+func ImportMnemonics(params *dagconfig.Params, numKeys uint32, cmdLinePassword string, isMultisig bool,
+	askPassphrases bool) (encryptedPrivateKeys []*EncryptedMnemonic, extendedPublicKeys []string, err error) {
+
+	mnemonics := make([]*libsedrawallet.Mnemonic, numKeys)
 	for i := uint32(0); i < numKeys; i++ {
 		fmt.Printf("Enter mnemonic #%d here:\n", i+1)
 		reader := bufio.NewReader(os.Stdin)
@@ -43,12 +53,31 @@ func ImportMnemonics(params *dagconfig.Params, numKeys uint32, cmdLinePassword s
 			return nil, nil, errors.Errorf("mnemonic is invalid")
 		}
 
-		mnemonics[i] = string(mnemonic)
+		mnemonics[i], err = newMnemonic(string(mnemonic), i, askPassphrases)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 	return encryptedMnemonicExtendedPublicKeyPairs(params, mnemonics, cmdLinePassword, isMultisig)
 }
 
-func encryptedMnemonicExtendedPublicKeyPairs(params *dagconfig.Params, mnemonics []string, cmdLinePassword string, isMultisig bool) (
+func newMnemonic(phrase string, index uint32, askPassphrase bool) (*libsedrawallet.Mnemonic, error) {
+	mnemonic := &libsedrawallet.Mnemonic{Phrase: phrase}
+	if !askPassphrase {
+		return mnemonic, nil
+	}
+
+	passphrase := []byte(GetPassword(fmt.Sprintf("Enter the bip-39 passphrase of mnemonic #%d (leave empty for none):", index+1)))
+	confirmPassphrase := []byte(GetPassword("Confirm passphrase:"))
+	if subtle.ConstantTimeCompare(passphrase, confirmPassphrase) != 1 {
+		return nil, errors.New("Passphrases are not identical")
+	}
+
+	mnemonic.Passphrase = string(passphrase)
+	return mnemonic, nil
+}
+
+func encryptedMnemonicExtendedPublicKeyPairs(params *dagconfig.Params, mnemonics []*libsedrawallet.Mnemonic, cmdLinePassword string, isMultisig bool) (
 	encryptedPrivateKeys []*EncryptedMnemonic, extendedPublicKeys []string, err error) {
 	password := []byte(cmdLinePassword)
 	if len(password) == 0 {
@@ -65,14 +94,14 @@ func encryptedMnemonicExtendedPublicKeyPairs(params *dagconfig.Params, mnemonics
 	extendedPublicKeys = make([]string, 0, len(mnemonics))
 
 	for _, mnemonic := range mnemonics {
-		extendedPublicKey, err := libsedrawallet.MasterPublicKeyFromMnemonic(params, mnemonic, isMultisig)
+		extendedPublicKey, err := libsedrawallet.AccountPublicKeyFromMnemonic(params, mnemonic, 0, isMultisig)
 		if err != nil {
 			return nil, nil, err
 		}
 
 		extendedPublicKeys = append(extendedPublicKeys, extendedPublicKey)
 
-		encryptedPrivateKey, err := encryptMnemonic(mnemonic, password)
+		encryptedPrivateKey, err := encryptMnemonic(mnemonic, password, DefaultKDFParams())
 		if err != nil {
 			return nil, nil, err
 		}
@@ -92,30 +121,48 @@ func generateSalt() ([]byte, error) {
 	return salt, nil
 }
 
-func encryptMnemonic(mnemonic string, password []byte) (*EncryptedMnemonic, error) {
-	mnemonicBytes := []byte(mnemonic)
+func encryptMnemonic(mnemonic *libsedrawallet.Mnemonic, password []byte, kdfParams *KDFParams) (
+	*EncryptedMnemonic, error) {
 
-	salt, err := generateSalt()
+	cipher, salt, err := encrypt([]byte(mnemonic.Phrase), password, kdfParams)
 	if err != nil {
 		return nil, err
 	}
 
-	aead, err := getAEAD(defaultNumThreads, password, salt)
+	encryptedMnemonic := &EncryptedMnemonic{
+		cipher: cipher,
+		salt:   salt,
+	}
+	if mnemonic.Passphrase != "" {
+		encryptedMnemonic.passphraseCipher, encryptedMnemonic.passphraseSalt, err =
+			encrypt([]byte(mnemonic.Passphrase), password, kdfParams)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return encryptedMnemonic, nil
+}
+
+func encrypt(data []byte, password []byte, kdfParams *KDFParams) (cipher []byte, salt []byte, err error) {
+	salt, err = generateSalt()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	aead, err := getAEAD(kdfParams, password, salt)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Select a random nonce, and leave capacity for the ciphertext.
-	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(mnemonicBytes)+aead.Overhead())
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(data)+aead.Overhead())
 	if _, err := rand.Read(nonce); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Encrypt the message and append the ciphertext to the nonce.
-	cipher := aead.Seal(nonce, nonce, []byte(mnemonicBytes), nil)
+	cipher = aead.Seal(nonce, nonce, data, nil)
 
-	return &EncryptedMnemonic{
-		cipher: cipher,
-		salt:   salt,
-	}, nil
+	return cipher, salt, nil
 }
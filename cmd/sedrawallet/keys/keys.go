@@ -2,6 +2,7 @@ package keys
 
 import (
 	"bufio"
+	"bytes"
 	"crypto/cipher"
 	"encoding/hex"
 	"encoding/json"
@@ -10,8 +11,10 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
 	"github.com/sedracoin/sedrad/cmd/sedrawallet/utils"
 
 	"github.com/sedracoin/sedrad/domain/dagconfig"
@@ -25,21 +28,43 @@ var (
 	defaultAppDir = util.AppDir("sedrawallet", false)
 )
 
-// LastVersion is the most up to date file format version
-const LastVersion = 1
+// LastVersion is the most up to date file format version. Version 2 records
+// the Argon2 parameters the mnemonics are encrypted with in the file.
+const LastVersion = 2
 
 func defaultKeysFile(netParams *dagconfig.Params) string {
 	return filepath.Join(defaultAppDir, netParams.Name, "keys.json")
 }
 
 type encryptedPrivateKeyJSON struct {
-	Cipher string `json:"cipher"`
-	Salt   string `json:"salt"`
+	Cipher           string `json:"cipher"`
+	Salt             string `json:"salt"`
+	PassphraseCipher string `json:"passphraseCipher,omitempty"`
+	PassphraseSalt   string `json:"passphraseSalt,omitempty"`
+}
+
+type kdfParamsJSON struct {
+	Time      uint32 `json:"time"`
+	MemoryKiB uint32 `json:"memoryKiB"`
+	Threads   uint8  `json:"threads"`
+}
+
+type accountJSON struct {
+	Index                 uint32   `json:"index"`
+	ExtendedPublicKeys    []string `json:"publicKeys"`
+	LastUsedExternalIndex uint32   `json:"lastUsedExternalIndex"`
+	LastUsedInternalIndex uint32   `json:"lastUsedInternalIndex"`
+}
+
+type contractJSON struct {
+	RedeemScript   string `json:"redeemScript"`
+	DerivationPath string `json:"derivationPath"`
 }
 
 type keysFileJSON struct {
 	Version               uint32                     `json:"version"`
-	NumThreads            uint8                      `json:"numThreads,omitempty"` // This field is ignored for versions different from 0. See more details at the function `numThreads`.
+	NumThreads            uint8                      `json:"numThreads,omitempty"` // This field is ignored for versions different from 0. See more details at the function `kdfParams`.
+	KDFParams             *kdfParamsJSON             `json:"kdf,omitempty"`
 	EncryptedPrivateKeys  []*encryptedPrivateKeyJSON `json:"encryptedMnemonics"`
 	ExtendedPublicKeys    []string                   `json:"publicKeys"`
 	MinimumSignatures     uint32                     `json:"minimumSignatures"`
@@ -47,18 +72,82 @@ type keysFileJSON struct {
 	LastUsedExternalIndex uint32                     `json:"lastUsedExternalIndex"`
 	LastUsedInternalIndex uint32                     `json:"lastUsedInternalIndex"`
 	ECDSA                 bool                       `json:"ecdsa"`
+	Contracts             []*contractJSON            `json:"contracts,omitempty"`
+	Accounts              []*accountJSON             `json:"accounts,omitempty"`
 }
 
-// EncryptedMnemonic represents an encrypted mnemonic
+// EncryptedMnemonic represents an encrypted mnemonic, along with its
+// encrypted bip-39 passphrase, if it has one
 type EncryptedMnemonic struct {
-	cipher []byte
-	salt   []byte
+	cipher           []byte
+	salt             []byte
+	passphraseCipher []byte
+	passphraseSalt   []byte
+}
+
+// KDFParams are the parameters of the Argon2id key derivation function that
+// derives the encryption keys of the mnemonics from the wallet password
+type KDFParams struct {
+	Time      uint32
+	MemoryKiB uint32
+	Threads   uint8
+}
+
+// DefaultKDFParams returns the Argon2 parameters that all key files up to
+// version 1 are encrypted with, and which new key files use by default.
+func DefaultKDFParams() *KDFParams {
+	return &KDFParams{
+		Time:      1,
+		MemoryKiB: 64 * 1024,
+		Threads:   defaultNumThreads,
+	}
+}
+
+// maxKDFMemoryKiB caps the memory of the key derivation function, so that a
+// corrupted keys file can't make the wallet run out of memory
+const maxKDFMemoryKiB = 4 * 1024 * 1024
+
+// Validate returns an error if params are weaker than the default ones, or
+// require more memory than the wallet allows.
+func (params *KDFParams) Validate() error {
+	defaultParams := DefaultKDFParams()
+	if params.Time < defaultParams.Time {
+		return errors.Errorf("the KDF time must be at least %d", defaultParams.Time)
+	}
+	if params.MemoryKiB < defaultParams.MemoryKiB {
+		return errors.Errorf("the KDF memory must be at least %d KiB", defaultParams.MemoryKiB)
+	}
+	if params.MemoryKiB > maxKDFMemoryKiB {
+		return errors.Errorf("the KDF memory must be at most %d KiB", maxKDFMemoryKiB)
+	}
+	if params.Threads == 0 {
+		return errors.New("the KDF threads must be positive")
+	}
+	return nil
+}
+
+// Account holds the extended public keys and the address indexes of an
+// additional account of the wallet. The first account of the wallet
+// (account 0) is kept in the File itself.
+type Account struct {
+	Index                 uint32
+	ExtendedPublicKeys    []string
+	lastUsedExternalIndex uint32
+	lastUsedInternalIndex uint32
+}
+
+// Contract is a pay-to-script-hash contract tracked by the wallet, along
+// with the derivation path of the wallet key that takes part in it
+type Contract struct {
+	RedeemScript   []byte
+	DerivationPath string
 }
 
 // File holds all the data related to the wallet keys
 type File struct {
 	Version               uint32
-	NumThreads            uint8 // This field is ignored for versions different than 0
+	NumThreads            uint8      // This field is ignored for versions different than 0
+	KDFParams             *KDFParams // This field is ignored for versions lower than 2
 	EncryptedMnemonics    []*EncryptedMnemonic
 	ExtendedPublicKeys    []string
 	MinimumSignatures     uint32
@@ -66,6 +155,8 @@ type File struct {
 	lastUsedExternalIndex uint32
 	lastUsedInternalIndex uint32
 	ECDSA                 bool
+	contracts             []*Contract
+	accounts              []*Account
 	path                  string
 }
 
@@ -73,14 +164,44 @@ func (d *File) toJSON() *keysFileJSON {
 	encryptedPrivateKeysJSON := make([]*encryptedPrivateKeyJSON, len(d.EncryptedMnemonics))
 	for i, encryptedPrivateKey := range d.EncryptedMnemonics {
 		encryptedPrivateKeysJSON[i] = &encryptedPrivateKeyJSON{
-			Cipher: hex.EncodeToString(encryptedPrivateKey.cipher),
-			Salt:   hex.EncodeToString(encryptedPrivateKey.salt),
+			Cipher:           hex.EncodeToString(encryptedPrivateKey.cipher),
+			Salt:             hex.EncodeToString(encryptedPrivateKey.salt),
+			PassphraseCipher: hex.EncodeToString(encryptedPrivateKey.passphraseCipher),
+			PassphraseSalt:   hex.EncodeToString(encryptedPrivateKey.passphraseSalt),
+		}
+	}
+
+	var contractsJSON []*contractJSON
+	for _, contract := range d.contracts {
+		contractsJSON = append(contractsJSON, &contractJSON{
+			RedeemScript:   hex.EncodeToString(contract.RedeemScript),
+			DerivationPath: contract.DerivationPath,
+		})
+	}
+
+	var accountsJSON []*accountJSON
+	for _, account := range d.accounts {
+		accountsJSON = append(accountsJSON, &accountJSON{
+			Index:                 account.Index,
+			ExtendedPublicKeys:    account.ExtendedPublicKeys,
+			LastUsedExternalIndex: account.lastUsedExternalIndex,
+			LastUsedInternalIndex: account.lastUsedInternalIndex,
+		})
+	}
+
+	var kdfParams *kdfParamsJSON
+	if d.KDFParams != nil {
+		kdfParams = &kdfParamsJSON{
+			Time:      d.KDFParams.Time,
+			MemoryKiB: d.KDFParams.MemoryKiB,
+			Threads:   d.KDFParams.Threads,
 		}
 	}
 
 	return &keysFileJSON{
 		Version:               d.Version,
 		NumThreads:            d.NumThreads,
+		KDFParams:             kdfParams,
 		EncryptedPrivateKeys:  encryptedPrivateKeysJSON,
 		ExtendedPublicKeys:    d.ExtendedPublicKeys,
 		MinimumSignatures:     d.MinimumSignatures,
@@ -88,19 +209,22 @@ func (d *File) toJSON() *keysFileJSON {
 		CosignerIndex:         d.CosignerIndex,
 		LastUsedExternalIndex: d.lastUsedExternalIndex,
 		LastUsedInternalIndex: d.lastUsedInternalIndex,
+		Contracts:             contractsJSON,
+		Accounts:              accountsJSON,
 	}
 }
 
 // NewFileFromMnemonic generates a new File from the given mnemonic string
 func NewFileFromMnemonic(params *dagconfig.Params, mnemonic string, password string) (*File, error) {
-	encryptedMnemonics, extendedPublicKeys, err :=
-		encryptedMnemonicExtendedPublicKeyPairs(params, []string{mnemonic}, password, false)
+	encryptedMnemonics, extendedPublicKeys, err := encryptedMnemonicExtendedPublicKeyPairs(params,
+		[]*libsedrawallet.Mnemonic{{Phrase: mnemonic}}, password, false)
 	if err != nil {
 		return nil, err
 	}
 	return &File{
 		Version:            LastVersion,
 		NumThreads:         defaultNumThreads,
+		KDFParams:          DefaultKDFParams(),
 		EncryptedMnemonics: encryptedMnemonics,
 		ExtendedPublicKeys: extendedPublicKeys,
 		MinimumSignatures:  1,
@@ -111,6 +235,19 @@ func NewFileFromMnemonic(params *dagconfig.Params, mnemonic string, password str
 func (d *File) fromJSON(fileJSON *keysFileJSON) error {
 	d.Version = fileJSON.Version
 	d.NumThreads = fileJSON.NumThreads
+	if fileJSON.KDFParams != nil {
+		d.KDFParams = &KDFParams{
+			Time:      fileJSON.KDFParams.Time,
+			MemoryKiB: fileJSON.KDFParams.MemoryKiB,
+			Threads:   fileJSON.KDFParams.Threads,
+		}
+		// Argon2 panics on zero time or threads, so the parameters are checked
+		// before any password is derived with them
+		err := d.KDFParams.Validate()
+		if err != nil {
+			return errors.Wrap(err, "invalid KDF parameters")
+		}
+	}
 	d.MinimumSignatures = fileJSON.MinimumSignatures
 	d.ECDSA = fileJSON.ECDSA
 	d.ExtendedPublicKeys = fileJSON.ExtendedPublicKeys
@@ -130,9 +267,44 @@ func (d *File) fromJSON(fileJSON *keysFileJSON) error {
 			return err
 		}
 
+		passphraseCipher, err := hex.DecodeString(encryptedPrivateKeyJSON.PassphraseCipher)
+		if err != nil {
+			return err
+		}
+
+		passphraseSalt, err := hex.DecodeString(encryptedPrivateKeyJSON.PassphraseSalt)
+		if err != nil {
+			return err
+		}
+
 		d.EncryptedMnemonics[i] = &EncryptedMnemonic{
-			cipher: cipher,
-			salt:   salt,
+			cipher:           cipher,
+			salt:             salt,
+			passphraseCipher: passphraseCipher,
+			passphraseSalt:   passphraseSalt,
+		}
+	}
+
+	d.contracts = make([]*Contract, len(fileJSON.Contracts))
+	for i, contractJSON := range fileJSON.Contracts {
+		redeemScript, err := hex.DecodeString(contractJSON.RedeemScript)
+		if err != nil {
+			return err
+		}
+
+		d.contracts[i] = &Contract{
+			RedeemScript:   redeemScript,
+			DerivationPath: contractJSON.DerivationPath,
+		}
+	}
+
+	d.accounts = make([]*Account, len(fileJSON.Accounts))
+	for i, accountJSON := range fileJSON.Accounts {
+		d.accounts[i] = &Account{
+			Index:                 accountJSON.Index,
+			ExtendedPublicKeys:    accountJSON.ExtendedPublicKeys,
+			lastUsedExternalIndex: accountJSON.LastUsedExternalIndex,
+			lastUsedInternalIndex: accountJSON.LastUsedInternalIndex,
 		}
 	}
 
@@ -174,58 +346,167 @@ func (d *File) Path() string {
 }
 
 // SetLastUsedExternalIndex sets the last used index in the external key
-// chain, and saves the file with the updated data.
-func (d *File) SetLastUsedExternalIndex(index uint32) error {
-	if d.lastUsedExternalIndex == index {
+// chain of the given account, and saves the file with the updated data.
+func (d *File) SetLastUsedExternalIndex(account uint32, index uint32) error {
+	lastUsedExternalIndex, _, err := d.lastUsedIndexes(account)
+	if err != nil {
+		return err
+	}
+	if *lastUsedExternalIndex == index {
 		return nil
 	}
 
-	d.lastUsedExternalIndex = index
+	*lastUsedExternalIndex = index
 	return d.Save()
 }
 
 // LastUsedExternalIndex returns the last used index in the external key
-// chain and saves the file with the updated data.
-func (d *File) LastUsedExternalIndex() uint32 {
-	return d.lastUsedExternalIndex
+// chain of the given account, or 0 if the account doesn't exist
+func (d *File) LastUsedExternalIndex(account uint32) uint32 {
+	lastUsedExternalIndex, _, err := d.lastUsedIndexes(account)
+	if err != nil {
+		return 0
+	}
+	return *lastUsedExternalIndex
 }
 
-// SetLastUsedInternalIndex sets the last used index in the internal key chain, and saves the file.
-func (d *File) SetLastUsedInternalIndex(index uint32) error {
-	if d.lastUsedInternalIndex == index {
+// SetLastUsedInternalIndex sets the last used index in the internal key chain of the given account, and saves the file.
+func (d *File) SetLastUsedInternalIndex(account uint32, index uint32) error {
+	_, lastUsedInternalIndex, err := d.lastUsedIndexes(account)
+	if err != nil {
+		return err
+	}
+	if *lastUsedInternalIndex == index {
 		return nil
 	}
 
-	d.lastUsedInternalIndex = index
+	*lastUsedInternalIndex = index
+	return d.Save()
+}
+
+// LastUsedInternalIndex returns the last used index in the internal key chain of the given account,
+// or 0 if the account doesn't exist
+func (d *File) LastUsedInternalIndex(account uint32) uint32 {
+	_, lastUsedInternalIndex, err := d.lastUsedIndexes(account)
+	if err != nil {
+		return 0
+	}
+	return *lastUsedInternalIndex
+}
+
+func (d *File) lastUsedIndexes(account uint32) (lastUsedExternalIndex *uint32, lastUsedInternalIndex *uint32, err error) {
+	if account == 0 {
+		return &d.lastUsedExternalIndex, &d.lastUsedInternalIndex, nil
+	}
+
+	for _, existingAccount := range d.accounts {
+		if existingAccount.Index == account {
+			return &existingAccount.lastUsedExternalIndex, &existingAccount.lastUsedInternalIndex, nil
+		}
+	}
+	return nil, nil, errors.Errorf("account %d doesn't exist in the keys file", account)
+}
+
+// Accounts returns the indexes of the accounts of the wallet in ascending
+// order. The first account (account 0) always exists.
+func (d *File) Accounts() []uint32 {
+	accounts := []uint32{0}
+	for _, account := range d.accounts {
+		accounts = append(accounts, account.Index)
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i] < accounts[j] })
+	return accounts
+}
+
+// AccountExtendedPublicKeys returns a copy of the extended public keys of the given
+// account, in the order of the cosigners of the first account. It's a copy since
+// libsedrawallet sorts the extended public keys it's given in place.
+func (d *File) AccountExtendedPublicKeys(account uint32) ([]string, error) {
+	if account == 0 {
+		return append([]string(nil), d.ExtendedPublicKeys...), nil
+	}
+
+	for _, existingAccount := range d.accounts {
+		if existingAccount.Index == account {
+			return append([]string(nil), existingAccount.ExtendedPublicKeys...), nil
+		}
+	}
+	return nil, errors.Errorf("account %d doesn't exist in the keys file", account)
+}
+
+// AddAccount adds an account with the given extended public keys to the
+// wallet, and saves the file with the updated data. The extended public keys
+// must belong to the same cosigners, and be in the same order, as the ones
+// of the first account.
+func (d *File) AddAccount(account uint32, extendedPublicKeys []string) error {
+	if _, err := d.AccountExtendedPublicKeys(account); err == nil {
+		return errors.Errorf("account %d already exists in the keys file", account)
+	}
+	if len(extendedPublicKeys) != len(d.ExtendedPublicKeys) {
+		return errors.Errorf("the wallet has %d public keys, but %d were given for account %d",
+			len(d.ExtendedPublicKeys), len(extendedPublicKeys), account)
+	}
+
+	d.accounts = append(d.accounts, &Account{
+		Index:              account,
+		ExtendedPublicKeys: append([]string(nil), extendedPublicKeys...),
+	})
 	return d.Save()
 }
 
-// LastUsedInternalIndex returns the last used index in the internal key chain
-func (d *File) LastUsedInternalIndex() uint32 {
-	return d.lastUsedInternalIndex
+// Contracts returns the contracts tracked by the wallet
+func (d *File) Contracts() []*Contract {
+	return d.contracts
+}
+
+// AddContract adds a contract to the ones tracked by the wallet, and
+// saves the file with the updated data. Adding a contract whose redeem
+// script is already tracked does nothing.
+func (d *File) AddContract(contract *Contract) error {
+	for _, existingContract := range d.contracts {
+		if bytes.Equal(existingContract.RedeemScript, contract.RedeemScript) {
+			return nil
+		}
+	}
+
+	d.contracts = append(d.contracts, contract)
+	return d.Save()
 }
 
 // DecryptMnemonics asks the user to enter the password for the private keys and
-// returns the decrypted private keys.
-func (d *File) DecryptMnemonics(password string) ([]string, error) {
+// returns the decrypted private keys, along with their bip-39 passphrases.
+func (d *File) DecryptMnemonics(password string) ([]*libsedrawallet.Mnemonic, error) {
 	passwordBytes := []byte(password)
 
-	var numThreads uint8
+	var kdfParams *KDFParams
 	if len(d.EncryptedMnemonics) > 0 {
 		var err error
-		numThreads, err = d.numThreads(passwordBytes)
+		kdfParams, err = d.kdfParams(passwordBytes)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	privateKeys := make([]string, len(d.EncryptedMnemonics))
+	privateKeys := make([]*libsedrawallet.Mnemonic, len(d.EncryptedMnemonics))
 	for i, encryptedPrivateKey := range d.EncryptedMnemonics {
-		var err error
-		privateKeys[i], err = decryptMnemonic(numThreads, encryptedPrivateKey, passwordBytes)
+		phrase, err := decryptMnemonic(kdfParams, encryptedPrivateKey, passwordBytes)
 		if err != nil {
 			return nil, err
 		}
+
+		passphrase := ""
+		if len(encryptedPrivateKey.passphraseCipher) > 0 {
+			passphrase, err = decrypt(kdfParams, encryptedPrivateKey.passphraseCipher,
+				encryptedPrivateKey.passphraseSalt, passwordBytes)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		privateKeys[i] = &libsedrawallet.Mnemonic{
+			Phrase:     phrase,
+			Passphrase: passphrase,
+		}
 	}
 
 	return privateKeys, nil
@@ -301,47 +582,49 @@ func (d *File) Save() error {
 		return err
 	}
 
-	file, err := os.OpenFile(d.path, os.O_WRONLY|os.O_CREATE, 0600)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	encoder := json.NewEncoder(file)
-	err = encoder.Encode(d.toJSON())
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return utils.WriteJSONFileAtomically(d.path, d.toJSON())
 }
 
 const defaultNumThreads = 8
 
-func (d *File) numThreads(password []byte) (uint8, error) {
+func (d *File) kdfParams(password []byte) (*KDFParams, error) {
 	// There's a bug in v0 wallets where the number of threads
 	// was determined by the number of logical CPUs at the machine,
 	// which made the authentication non-deterministic across platforms.
 	// In order to solve it we introduce v1 where the number of threads
 	// is constant, and brute force the number of threads in v0. After we
 	// find the right amount via brute force we save the result to the file.
+	// From v2 on all the parameters are recorded in the file.
+
+	if d.Version >= 2 {
+		if d.KDFParams == nil {
+			return nil, errors.Errorf("the KDF parameters are missing from the version %d keys file", d.Version)
+		}
+		return d.KDFParams, nil
+	}
 
 	if d.Version != 0 {
-		return defaultNumThreads, nil
+		return DefaultKDFParams(), nil
 	}
 
 	numThreads, err := d.detectNumThreads(password, d.EncryptedMnemonics[0])
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	d.NumThreads = numThreads
 	err = d.Save()
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	return numThreads, nil
+	return version0KDFParams(numThreads), nil
+}
+
+func version0KDFParams(numThreads uint8) *KDFParams {
+	kdfParams := DefaultKDFParams()
+	kdfParams.Threads = numThreads
+	return kdfParams
 }
 
 func (d *File) detectNumThreads(password []byte, encryptedMnemonic *EncryptedMnemonic) (uint8, error) {
@@ -349,7 +632,7 @@ func (d *File) detectNumThreads(password []byte, encryptedMnemonic *EncryptedMne
 	if d.NumThreads == 0 {
 		firstGuessNumThreads = uint8(runtime.NumCPU())
 	}
-	_, err := decryptMnemonic(firstGuessNumThreads, encryptedMnemonic, password)
+	_, err := decryptMnemonic(version0KDFParams(firstGuessNumThreads), encryptedMnemonic, password)
 	if err != nil {
 		if !strings.Contains(err.Error(), "message authentication failed") {
 			return 0, err
@@ -363,7 +646,7 @@ func (d *File) detectNumThreads(password []byte, encryptedMnemonic *EncryptedMne
 			continue
 		}
 
-		_, err := decryptMnemonic(numThreadsGuess, encryptedMnemonic, password)
+		_, err := decryptMnemonic(version0KDFParams(numThreadsGuess), encryptedMnemonic, password)
 		if err != nil {
 			const maxTries = 255
 			if numThreadsGuess == maxTries || !strings.Contains(err.Error(), "message authentication failed") {
@@ -375,23 +658,27 @@ func (d *File) detectNumThreads(password []byte, encryptedMnemonic *EncryptedMne
 	}
 }
 
-func getAEAD(threads uint8, password, salt []byte) (cipher.AEAD, error) {
-	key := argon2.IDKey(password, salt, 1, 64*1024, threads, 32)
+func getAEAD(kdfParams *KDFParams, password, salt []byte) (cipher.AEAD, error) {
+	key := argon2.IDKey(password, salt, kdfParams.Time, kdfParams.MemoryKiB, kdfParams.Threads, 32)
 	return chacha20poly1305.NewX(key)
 }
 
-func decryptMnemonic(numThreads uint8, encryptedPrivateKey *EncryptedMnemonic, password []byte) (string, error) {
-	aead, err := getAEAD(numThreads, password, encryptedPrivateKey.salt)
+func decryptMnemonic(kdfParams *KDFParams, encryptedPrivateKey *EncryptedMnemonic, password []byte) (string, error) {
+	return decrypt(kdfParams, encryptedPrivateKey.cipher, encryptedPrivateKey.salt, password)
+}
+
+func decrypt(kdfParams *KDFParams, encrypted []byte, salt []byte, password []byte) (string, error) {
+	aead, err := getAEAD(kdfParams, password, salt)
 	if err != nil {
 		return "", err
 	}
 
-	if len(encryptedPrivateKey.cipher) < aead.NonceSize() {
+	if len(encrypted) < aead.NonceSize() {
 		return "", errors.New("ciphertext too short")
 	}
 
 	// Split nonce and ciphertext.
-	nonce, ciphertext := encryptedPrivateKey.cipher[:aead.NonceSize()], encryptedPrivateKey.cipher[aead.NonceSize():]
+	nonce, ciphertext := encrypted[:aead.NonceSize()], encrypted[aead.NonceSize():]
 
 	// Decrypt the message and check it wasn't tampered with.
 	decrypted, err := aead.Open(nil, nonce, ciphertext, nil)
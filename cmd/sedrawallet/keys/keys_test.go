@@ -0,0 +1,142 @@
+package keys
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
+	"github.com/sedracoin/sedrad/domain/dagconfig"
+)
+
+func TestAccountExtendedPublicKeysOrder(t *testing.T) {
+	params := &dagconfig.MainnetParams
+	path := filepath.Join(t.TempDir(), "keys.json")
+
+	const cosignerCount = 3
+	mnemonics := make([]*libsedrawallet.Mnemonic, cosignerCount)
+	for i := range mnemonics {
+		phrase, err := libsedrawallet.CreateMnemonic()
+		if err != nil {
+			t.Fatalf("CreateMnemonic: %+v", err)
+		}
+		mnemonics[i] = &libsedrawallet.Mnemonic{Phrase: phrase}
+	}
+	accountPublicKeys := func(account uint32) []string {
+		extendedPublicKeys := make([]string, cosignerCount)
+		for i, mnemonic := range mnemonics {
+			extendedPublicKey, err := libsedrawallet.AccountPublicKeyFromMnemonic(params, mnemonic, account, true)
+			if err != nil {
+				t.Fatalf("AccountPublicKeyFromMnemonic: %+v", err)
+			}
+			extendedPublicKeys[i] = extendedPublicKey
+		}
+		return extendedPublicKeys
+	}
+
+	// The cosigners are ordered so that the keys of the first account aren't sorted
+	extendedPublicKeys := accountPublicKeys(0)
+	sort.Sort(sort.Reverse(sort.StringSlice(extendedPublicKeys)))
+	otherAccountExtendedPublicKeys := make([]string, cosignerCount)
+	for i, extendedPublicKey := range accountPublicKeys(0) {
+		for j := range extendedPublicKeys {
+			if extendedPublicKeys[j] == extendedPublicKey {
+				otherAccountExtendedPublicKeys[j] = accountPublicKeys(1)[i]
+			}
+		}
+	}
+	file := &File{
+		Version:            LastVersion,
+		ExtendedPublicKeys: append([]string(nil), extendedPublicKeys...),
+		MinimumSignatures:  2,
+	}
+	err := file.SetPath(params, path, true)
+	if err != nil {
+		t.Fatalf("SetPath: %+v", err)
+	}
+	err = file.AddAccount(1, append([]string(nil), otherAccountExtendedPublicKeys...))
+	if err != nil {
+		t.Fatalf("AddAccount: %+v", err)
+	}
+
+	// Deriving addresses sorts the given extended public keys in place
+	for account, expectedExtendedPublicKeys := range [][]string{extendedPublicKeys, otherAccountExtendedPublicKeys} {
+		accountExtendedPublicKeys, err := file.AccountExtendedPublicKeys(uint32(account))
+		if err != nil {
+			t.Fatalf("AccountExtendedPublicKeys: %+v", err)
+		}
+		_, err = libsedrawallet.Address(params, accountExtendedPublicKeys, file.MinimumSignatures, "m/0/0", false)
+		if err != nil {
+			t.Fatalf("Address: %+v", err)
+		}
+
+		accountExtendedPublicKeys, err = file.AccountExtendedPublicKeys(uint32(account))
+		if err != nil {
+			t.Fatalf("AccountExtendedPublicKeys: %+v", err)
+		}
+		for i, extendedPublicKey := range accountExtendedPublicKeys {
+			if extendedPublicKey != expectedExtendedPublicKeys[i] {
+				t.Fatalf("The extended public keys of account %d were reordered", account)
+			}
+		}
+	}
+}
+
+func TestReadKeysFileCorruptedKDFParams(t *testing.T) {
+	params := &dagconfig.MainnetParams
+	path := filepath.Join(t.TempDir(), "keys.json")
+
+	mnemonic, err := libsedrawallet.CreateMnemonic()
+	if err != nil {
+		t.Fatalf("CreateMnemonic: %+v", err)
+	}
+	file, err := NewFileFromMnemonic(params, mnemonic, "password")
+	if err != nil {
+		t.Fatalf("NewFileFromMnemonic: %+v", err)
+	}
+	err = file.SetPath(params, path, true)
+	if err != nil {
+		t.Fatalf("SetPath: %+v", err)
+	}
+	err = file.Save()
+	if err != nil {
+		t.Fatalf("Save: %+v", err)
+	}
+	_, err = ReadKeysFile(params, path)
+	if err != nil {
+		t.Fatalf("ReadKeysFile: %+v", err)
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %+v", err)
+	}
+	for _, kdfParams := range []*kdfParamsJSON{
+		{Time: 0, MemoryKiB: 64 * 1024, Threads: 8},
+		{Time: 1, MemoryKiB: 64 * 1024, Threads: 0},
+		{Time: 1, MemoryKiB: maxKDFMemoryKiB + 1, Threads: 8},
+	} {
+		fileJSON := &keysFileJSON{}
+		err = json.Unmarshal(contents, fileJSON)
+		if err != nil {
+			t.Fatalf("Unmarshal: %+v", err)
+		}
+		fileJSON.KDFParams = kdfParams
+		corruptedContents, err := json.Marshal(fileJSON)
+		if err != nil {
+			t.Fatalf("Marshal: %+v", err)
+		}
+		corruptedPath := filepath.Join(t.TempDir(), "corrupted.json")
+		err = os.WriteFile(corruptedPath, corruptedContents, 0600)
+		if err != nil {
+			t.Fatalf("WriteFile: %+v", err)
+		}
+
+		_, err = ReadKeysFile(params, corruptedPath)
+		if err == nil {
+			t.Fatalf("ReadKeysFile unexpectedly accepted the KDF parameters %+v", kdfParams)
+		}
+	}
+}
@@ -26,18 +26,42 @@ const (
 	CoinType = 111111
 )
 
-func defaultPath(isMultisig bool) string {
+// MaxAccount is the highest account index a wallet can have. Account indexes
+// are used as hardened derivation indexes, so they can't exceed 2^31-1.
+const MaxAccount = 1<<31 - 1
+
+// Mnemonic is a bip-39 mnemonic along with the optional passphrase that
+// extends it. The same mnemonic with different passphrases derives unrelated
+// keys.
+type Mnemonic struct {
+	Phrase     string
+	Passphrase string
+}
+
+func defaultPath(isMultisig bool, account uint32) string {
 	purpose := SingleSignerPurpose
 	if isMultisig {
 		purpose = MultiSigPurpose
 	}
 
-	return fmt.Sprintf("m/%d'/%d'/0'", purpose, CoinType)
+	return fmt.Sprintf("m/%d'/%d'/%d'", purpose, CoinType, account)
 }
 
 // MasterPublicKeyFromMnemonic returns the master public key with the correct derivation for the given mnemonic.
+// It uses no passphrase and the first account of the wallet.
 func MasterPublicKeyFromMnemonic(params *dagconfig.Params, mnemonic string, isMultisig bool) (string, error) {
-	path := defaultPath(isMultisig)
+	return AccountPublicKeyFromMnemonic(params, &Mnemonic{Phrase: mnemonic}, 0, isMultisig)
+}
+
+// AccountPublicKeyFromMnemonic returns the master public key of the given account for the given mnemonic.
+func AccountPublicKeyFromMnemonic(params *dagconfig.Params, mnemonic *Mnemonic, account uint32,
+	isMultisig bool) (string, error) {
+
+	if account > MaxAccount {
+		return "", errors.Errorf("account %d is higher than the maximum of %d", account, MaxAccount)
+	}
+
+	path := defaultPath(isMultisig, account)
 	extendedKey, err := extendedKeyFromMnemonicAndPath(mnemonic, path, params)
 	if err != nil {
 		return "", err
@@ -51,8 +75,8 @@ func MasterPublicKeyFromMnemonic(params *dagconfig.Params, mnemonic string, isMu
 	return extendedPublicKey.String(), nil
 }
 
-func extendedKeyFromMnemonicAndPath(mnemonic string, path string, params *dagconfig.Params) (*bip32.ExtendedKey, error) {
-	seed := bip39.NewSeed(mnemonic, "")
+func extendedKeyFromMnemonicAndPath(mnemonic *Mnemonic, path string, params *dagconfig.Params) (*bip32.ExtendedKey, error) {
+	seed := bip39.NewSeed(mnemonic.Phrase, mnemonic.Passphrase)
 	version, err := versionFromParams(params)
 	if err != nil {
 		return nil, err
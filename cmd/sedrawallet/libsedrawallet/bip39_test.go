@@ -0,0 +1,187 @@
+package libsedrawallet_test
+
+import (
+	"testing"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
+	"github.com/sedracoin/sedrad/domain/consensus"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/testutils"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/utxo"
+)
+
+func TestAccountPublicKeyFromMnemonic(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		params := &consensusConfig.Params
+		phrase, err := libsedrawallet.CreateMnemonic()
+		if err != nil {
+			t.Fatalf("CreateMnemonic: %+v", err)
+		}
+
+		masterPublicKey, err := libsedrawallet.MasterPublicKeyFromMnemonic(params, phrase, false)
+		if err != nil {
+			t.Fatalf("MasterPublicKeyFromMnemonic: %+v", err)
+		}
+		firstAccountPublicKey, err := libsedrawallet.AccountPublicKeyFromMnemonic(params,
+			&libsedrawallet.Mnemonic{Phrase: phrase}, 0, false)
+		if err != nil {
+			t.Fatalf("AccountPublicKeyFromMnemonic: %+v", err)
+		}
+		if firstAccountPublicKey != masterPublicKey {
+			t.Fatalf("The public key of the first account with no passphrase is not the master public key")
+		}
+
+		publicKeys := map[string]struct{}{masterPublicKey: {}}
+		for _, test := range []struct {
+			passphrase string
+			account    uint32
+			isMultisig bool
+		}{
+			{passphrase: "", account: 1, isMultisig: false},
+			{passphrase: "", account: 0, isMultisig: true},
+			{passphrase: "passphrase", account: 0, isMultisig: false},
+			{passphrase: "passphrase", account: 1, isMultisig: false},
+			{passphrase: "another passphrase", account: 0, isMultisig: false},
+		} {
+			publicKey, err := libsedrawallet.AccountPublicKeyFromMnemonic(params,
+				&libsedrawallet.Mnemonic{Phrase: phrase, Passphrase: test.passphrase}, test.account, test.isMultisig)
+			if err != nil {
+				t.Fatalf("AccountPublicKeyFromMnemonic: %+v", err)
+			}
+			if _, ok := publicKeys[publicKey]; ok {
+				t.Fatalf("Passphrase %q, account %d and isMultisig %t derived an already derived public key",
+					test.passphrase, test.account, test.isMultisig)
+			}
+			publicKeys[publicKey] = struct{}{}
+		}
+
+		_, err = libsedrawallet.AccountPublicKeyFromMnemonic(params, &libsedrawallet.Mnemonic{Phrase: phrase},
+			libsedrawallet.MaxAccount+1, false)
+		if err == nil {
+			t.Fatalf("AccountPublicKeyFromMnemonic unexpectedly succeeded for an account above the maximum")
+		}
+	})
+}
+
+func TestSignWithAccount(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		params := &consensusConfig.Params
+		forSchnorrAndECDSA(t, func(t *testing.T, ecdsa bool) {
+			consensusConfig.BlockCoinbaseMaturity = 0
+			tc, teardown, err := consensus.NewFactory().NewTestConsensus(consensusConfig, "TestSignWithAccount")
+			if err != nil {
+				t.Fatalf("Error setting up tc: %+v", err)
+			}
+			defer teardown(false)
+
+			phrase, err := libsedrawallet.CreateMnemonic()
+			if err != nil {
+				t.Fatalf("CreateMnemonic: %+v", err)
+			}
+			mnemonic := &libsedrawallet.Mnemonic{Phrase: phrase, Passphrase: "passphrase"}
+
+			const account = 2
+			publicKey, err := libsedrawallet.AccountPublicKeyFromMnemonic(params, mnemonic, account, false)
+			if err != nil {
+				t.Fatalf("AccountPublicKeyFromMnemonic: %+v", err)
+			}
+			publicKeys := []string{publicKey}
+
+			const path = "m/0/1"
+			address, err := libsedrawallet.Address(params, publicKeys, 1, path, ecdsa)
+			if err != nil {
+				t.Fatalf("Address: %+v", err)
+			}
+
+			scriptPublicKey, err := txscript.PayToAddrScript(address)
+			if err != nil {
+				t.Fatalf("PayToAddrScript: %+v", err)
+			}
+
+			coinbaseData := &externalapi.DomainCoinbaseData{
+				ScriptPublicKey: scriptPublicKey,
+				ExtraData:       nil,
+			}
+
+			fundingBlockHash, _, err := tc.AddBlock([]*externalapi.DomainHash{consensusConfig.GenesisHash}, coinbaseData, nil)
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+
+			block1Hash, _, err := tc.AddBlock([]*externalapi.DomainHash{fundingBlockHash}, nil, nil)
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+
+			block1, _, err := tc.GetBlock(block1Hash)
+			if err != nil {
+				t.Fatalf("GetBlock: %+v", err)
+			}
+
+			block1TxOut := block1.Transactions[0].Outputs[0]
+			selectedUTXOs := []*libsedrawallet.UTXO{
+				{
+					Outpoint: &externalapi.DomainOutpoint{
+						TransactionID: *consensushashing.TransactionID(block1.Transactions[0]),
+						Index:         0,
+					},
+					UTXOEntry:      utxo.NewUTXOEntry(block1TxOut.Value, block1TxOut.ScriptPublicKey, true, 0),
+					DerivationPath: path,
+				},
+			}
+
+			unsignedTransaction, err := libsedrawallet.CreateUnsignedTransaction(publicKeys, 1,
+				[]*libsedrawallet.Payment{{
+					Address: address,
+					Amount:  10,
+				}}, selectedUTXOs)
+			if err != nil {
+				t.Fatalf("CreateUnsignedTransactions: %+v", err)
+			}
+
+			_, err = libsedrawallet.Sign(params, []string{phrase}, unsignedTransaction, ecdsa)
+			if err == nil {
+				t.Fatalf("Sign unexpectedly succeeded without the passphrase and the account")
+			}
+
+			_, err = libsedrawallet.SignWithAccount(params, []*libsedrawallet.Mnemonic{mnemonic}, account-1,
+				unsignedTransaction, ecdsa)
+			if err == nil {
+				t.Fatalf("SignWithAccount unexpectedly succeeded with the wrong account")
+			}
+
+			wrongPassphraseMnemonic := &libsedrawallet.Mnemonic{Phrase: phrase, Passphrase: "wrong passphrase"}
+			_, err = libsedrawallet.SignWithAccount(params, []*libsedrawallet.Mnemonic{wrongPassphraseMnemonic},
+				account, unsignedTransaction, ecdsa)
+			if err == nil {
+				t.Fatalf("SignWithAccount unexpectedly succeeded with the wrong passphrase")
+			}
+
+			signedTx, err := libsedrawallet.SignWithAccount(params, []*libsedrawallet.Mnemonic{mnemonic}, account,
+				unsignedTransaction, ecdsa)
+			if err != nil {
+				t.Fatalf("SignWithAccount: %+v", err)
+			}
+
+			tx, err := libsedrawallet.ExtractTransaction(signedTx, ecdsa)
+			if err != nil {
+				t.Fatalf("ExtractTransaction: %+v", err)
+			}
+
+			_, virtualChangeSet, err := tc.AddBlock([]*externalapi.DomainHash{block1Hash}, nil, []*externalapi.DomainTransaction{tx})
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+
+			addedUTXO := &externalapi.DomainOutpoint{
+				TransactionID: *consensushashing.TransactionID(tx),
+				Index:         0,
+			}
+			if !virtualChangeSet.VirtualUTXODiff.ToAdd().Contains(addedUTXO) {
+				t.Fatalf("Transaction wasn't accepted in the DAG")
+			}
+		})
+	})
+}
@@ -0,0 +1,315 @@
+package libsedrawallet
+
+import (
+	"bytes"
+	"crypto/sha256"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet/bip32"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet/serialization"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/constants"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/subnetworks"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
+	"github.com/sedracoin/sedrad/domain/dagconfig"
+	"github.com/sedracoin/sedrad/util"
+	"github.com/pkg/errors"
+)
+
+// ContractBranch selects which branch of a contract's redeem script a spend unlocks
+type ContractBranch uint8
+
+const (
+	// ContractBranchImmediate is the branch that can be spent at any time: the
+	// recovery branch of a vault, or the claim branch of a hash-timelocked contract
+	ContractBranchImmediate ContractBranch = iota
+
+	// ContractBranchTimeLocked is the branch that can only be spent once the
+	// contract's lock has passed: the owner branch of a vault, or the refund
+	// branch of a hash-timelocked contract
+	ContractBranchTimeLocked
+)
+
+const (
+	// ContractTypeTimeLockedVault is the type of contracts created by NewTimeLockedVault
+	ContractTypeTimeLockedVault = "vault"
+
+	// ContractTypeHashTimeLockedContract is the type of contracts created by NewHashTimeLockedContract
+	ContractTypeHashTimeLockedContract = "htlc"
+)
+
+// Contract is a pay-to-script-hash redeem script of one of the contract
+// templates the wallet knows how to spend.
+type Contract struct {
+	RedeemScript           []byte
+	Vault                  *txscript.TimeLockedVaultDataPushes
+	HashTimeLockedContract *txscript.HashTimeLockedContractDataPushes
+}
+
+// ParseContract parses the given redeem script into a Contract. It returns an
+// error if the script is not of a known contract template.
+func ParseContract(redeemScript []byte) (*Contract, error) {
+	vault, err := txscript.ExtractTimeLockedVaultDataPushes(redeemScript)
+	if err != nil {
+		return nil, err
+	}
+	if vault != nil {
+		return &Contract{RedeemScript: redeemScript, Vault: vault}, nil
+	}
+
+	hashTimeLockedContract, err := txscript.ExtractHashTimeLockedContractDataPushes(redeemScript)
+	if err != nil {
+		return nil, err
+	}
+	if hashTimeLockedContract != nil {
+		return &Contract{RedeemScript: redeemScript, HashTimeLockedContract: hashTimeLockedContract}, nil
+	}
+
+	return nil, errors.Errorf("the script is neither a timelocked vault nor a hash-timelocked contract")
+}
+
+// NewTimeLockedVault creates a vault that can be spent by the key derived from
+// extendedPublicKey at the given path once timeLock has passed, or by the key of
+// recoveryAddress at any time.
+func NewTimeLockedVault(extendedPublicKey string, path string, recoveryAddress util.Address,
+	timeLock txscript.TimeLock, ecdsa bool) (*Contract, error) {
+
+	ownerPublicKey, err := derivedPublicKey(extendedPublicKey, path, ecdsa)
+	if err != nil {
+		return nil, err
+	}
+
+	recoveryPublicKey, err := publicKeyFromAddress(recoveryAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	redeemScript, err := txscript.TimeLockedVaultScript(ownerPublicKey, recoveryPublicKey, timeLock)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseContract(redeemScript)
+}
+
+// NewHashTimeLockedContract creates a contract that can be claimed by the key of
+// recipientAddress along with the secret whose SHA256 is secretHash, or refunded
+// to the key derived from extendedPublicKey at the given path once timeLock has
+// passed.
+func NewHashTimeLockedContract(extendedPublicKey string, path string, recipientAddress util.Address,
+	secretHash []byte, timeLock txscript.TimeLock, ecdsa bool) (*Contract, error) {
+
+	refundPublicKey, err := derivedPublicKey(extendedPublicKey, path, ecdsa)
+	if err != nil {
+		return nil, err
+	}
+
+	recipientPublicKey, err := publicKeyFromAddress(recipientAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	redeemScript, err := txscript.HashTimeLockedContractScript(recipientPublicKey, refundPublicKey, secretHash, timeLock)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseContract(redeemScript)
+}
+
+// Address returns the pay-to-script-hash address of the contract
+func (c *Contract) Address(params *dagconfig.Params) (util.Address, error) {
+	return util.NewAddressScriptHash(c.RedeemScript, params.Prefix)
+}
+
+// Type returns the name of the contract's template
+func (c *Contract) Type() string {
+	if c.Vault != nil {
+		return ContractTypeTimeLockedVault
+	}
+	return ContractTypeHashTimeLockedContract
+}
+
+// TimeLock returns the lock of the contract's timelocked branch
+func (c *Contract) TimeLock() txscript.TimeLock {
+	if c.Vault != nil {
+		return c.Vault.TimeLock
+	}
+	return c.HashTimeLockedContract.TimeLock
+}
+
+// BranchPublicKey returns the public key that has to sign spends of the given branch
+func (c *Contract) BranchPublicKey(branch ContractBranch) []byte {
+	if c.Vault != nil {
+		if branch == ContractBranchImmediate {
+			return c.Vault.RecoveryPublicKey
+		}
+		return c.Vault.OwnerPublicKey
+	}
+
+	if branch == ContractBranchImmediate {
+		return c.HashTimeLockedContract.RecipientPublicKey
+	}
+	return c.HashTimeLockedContract.RefundPublicKey
+}
+
+// IsKeyInBranch returns whether the key derived from extendedPublicKey at the given
+// path is the key that signs spends of the given branch of the contract
+func (c *Contract) IsKeyInBranch(extendedPublicKey string, path string, branch ContractBranch, ecdsa bool) (bool, error) {
+	publicKey, err := derivedPublicKey(extendedPublicKey, path, ecdsa)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(publicKey, c.BranchPublicKey(branch)), nil
+}
+
+// CreateUnsignedContractTransaction creates an unsigned transaction that spends
+// the given UTXOs of the contract through the given branch. The UTXOs are signed
+// by the key derived from extendedPublicKey at their DerivationPath, which has to
+// be the branch's key. Claiming a hash-timelocked contract requires its secret.
+func CreateUnsignedContractTransaction(
+	extendedPublicKey string,
+	contract *Contract,
+	branch ContractBranch,
+	secret []byte,
+	payments []*Payment,
+	selectedUTXOs []*UTXO) ([]byte, error) {
+
+	redeemScriptArguments, err := contractRedeemScriptArguments(contract, branch, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	timeLock := contract.TimeLock()
+	lockTime := uint64(0)
+	sequence := uint64(0)
+	if branch == ContractBranchTimeLocked {
+		if timeLock.IsRelative {
+			sequence = timeLock.Value
+		} else {
+			lockTime = timeLock.Value
+		}
+	}
+
+	inputs := make([]*externalapi.DomainTransactionInput, len(selectedUTXOs))
+	partiallySignedInputs := make([]*serialization.PartiallySignedInput, len(selectedUTXOs))
+	for i, utxo := range selectedUTXOs {
+		isKeyInBranch, err := contract.IsKeyInBranch(extendedPublicKey, utxo.DerivationPath, branch,
+			len(contract.BranchPublicKey(branch)) != 32)
+		if err != nil {
+			return nil, err
+		}
+		if !isKeyInBranch {
+			return nil, errors.Errorf("the key at path %s can't sign for this branch of the contract", utxo.DerivationPath)
+		}
+
+		extendedKey, err := bip32.DeserializeExtendedKey(extendedPublicKey)
+		if err != nil {
+			return nil, err
+		}
+
+		derivedKey, err := extendedKey.DeriveFromPath(utxo.DerivationPath)
+		if err != nil {
+			return nil, err
+		}
+
+		inputs[i] = &externalapi.DomainTransactionInput{
+			PreviousOutpoint: *utxo.Outpoint,
+			Sequence:         sequence,
+		}
+		partiallySignedInputs[i] = &serialization.PartiallySignedInput{
+			PrevOutput: &externalapi.DomainTransactionOutput{
+				Value:           utxo.UTXOEntry.Amount(),
+				ScriptPublicKey: utxo.UTXOEntry.ScriptPublicKey(),
+			},
+			MinimumSignatures:     1,
+			PubKeySignaturePairs:  []*serialization.PubKeySignaturePair{{ExtendedPublicKey: derivedKey.String()}},
+			DerivationPath:        utxo.DerivationPath,
+			RedeemScript:          contract.RedeemScript,
+			RedeemScriptArguments: redeemScriptArguments,
+
+			PrevOutputBlockDAAScore: utxo.UTXOEntry.BlockDAAScore(),
+			PrevOutputIsCoinbase:    utxo.UTXOEntry.IsCoinbase(),
+		}
+		inputs[i].SigOpCount = PartiallySignedInputSigOpCount(partiallySignedInputs[i])
+	}
+
+	outputs := make([]*externalapi.DomainTransactionOutput, len(payments))
+	for i, payment := range payments {
+		scriptPublicKey, err := txscript.PayToAddrScript(payment.Address)
+		if err != nil {
+			return nil, err
+		}
+
+		outputs[i] = &externalapi.DomainTransactionOutput{
+			Value:           payment.Amount,
+			ScriptPublicKey: scriptPublicKey,
+		}
+	}
+
+	domainTransaction := &externalapi.DomainTransaction{
+		Version:      constants.MaxTransactionVersion,
+		Inputs:       inputs,
+		Outputs:      outputs,
+		LockTime:     lockTime,
+		SubnetworkID: subnetworks.SubnetworkIDNative,
+		Gas:          0,
+		Payload:      nil,
+	}
+
+	return serialization.SerializePartiallySignedTransaction(&serialization.PartiallySignedTransaction{
+		Tx:                    domainTransaction,
+		PartiallySignedInputs: partiallySignedInputs,
+	})
+}
+
+// contractRedeemScriptArguments returns the data that has to be pushed between the
+// signature and the redeem script in order to unlock the given branch: the secret
+// for hash-timelocked contract claims, followed by the branch selector.
+func contractRedeemScriptArguments(contract *Contract, branch ContractBranch, secret []byte) ([][]byte, error) {
+	if branch == ContractBranchTimeLocked {
+		if secret != nil {
+			return nil, errors.Errorf("a secret is only required to claim a hash-timelocked contract")
+		}
+		return [][]byte{{}}, nil
+	}
+
+	if contract.Vault != nil {
+		if secret != nil {
+			return nil, errors.Errorf("vaults don't have a secret")
+		}
+		return [][]byte{{1}}, nil
+	}
+
+	if len(secret) != txscript.HashTimeLockedContractSecretSize {
+		return nil, errors.Errorf("the secret must be %d bytes long", txscript.HashTimeLockedContractSecretSize)
+	}
+	secretHash := sha256.Sum256(secret)
+	if secretHash != contract.HashTimeLockedContract.SecretHash {
+		return nil, errors.Errorf("the secret doesn't match the secret hash of the contract")
+	}
+	return [][]byte{secret, {1}}, nil
+}
+
+func contractSignatureScript(input *serialization.PartiallySignedInput) ([]byte, error) {
+	if len(input.PubKeySignaturePairs) != 1 {
+		return nil, errors.Errorf("contract inputs are expected to have a single public key")
+	}
+	if input.PubKeySignaturePairs[0].Signature == nil {
+		return nil, errors.Errorf("missing signature")
+	}
+
+	scriptBuilder := txscript.NewScriptBuilder().AddData(input.PubKeySignaturePairs[0].Signature)
+	for _, argument := range input.RedeemScriptArguments {
+		scriptBuilder.AddData(argument)
+	}
+	scriptBuilder.AddData(input.RedeemScript)
+	return scriptBuilder.Script()
+}
+
+func publicKeyFromAddress(address util.Address) ([]byte, error) {
+	switch address.(type) {
+	case *util.AddressPublicKey, *util.AddressPublicKeyECDSA:
+		return address.ScriptAddress(), nil
+	}
+	return nil, errors.Errorf("%s is not a public key address", address)
+}
@@ -0,0 +1,263 @@
+package libsedrawallet_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
+	"github.com/sedracoin/sedrad/domain/consensus"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/testutils"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/utxo"
+	"github.com/sedracoin/sedrad/util"
+)
+
+func TestContracts(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x5e}, txscript.HashTimeLockedContractSecretSize)
+	secretHash := sha256.Sum256(secret)
+
+	// In all the tests the contract is created by the first party, and the
+	// second party is the recovery key of vaults and the recipient of
+	// hash-timelocked contracts.
+	tests := []struct {
+		name            string
+		isVault         bool
+		timeLock        txscript.TimeLock
+		branch          libsedrawallet.ContractBranch
+		spender         int
+		secret          []byte
+		expectAccepting bool
+	}{
+		{
+			name:            "vault owner after absolute lock",
+			isVault:         true,
+			timeLock:        txscript.TimeLock{Value: 1},
+			branch:          libsedrawallet.ContractBranchTimeLocked,
+			spender:         0,
+			expectAccepting: true,
+		},
+		{
+			name:            "vault owner before absolute lock",
+			isVault:         true,
+			timeLock:        txscript.TimeLock{Value: 1_000_000},
+			branch:          libsedrawallet.ContractBranchTimeLocked,
+			spender:         0,
+			expectAccepting: false,
+		},
+		{
+			name:            "vault recovery before absolute lock",
+			isVault:         true,
+			timeLock:        txscript.TimeLock{Value: 1_000_000},
+			branch:          libsedrawallet.ContractBranchImmediate,
+			spender:         1,
+			expectAccepting: true,
+		},
+		{
+			name:            "htlc claim before relative lock",
+			timeLock:        txscript.TimeLock{Value: 1000, IsRelative: true},
+			branch:          libsedrawallet.ContractBranchImmediate,
+			spender:         1,
+			secret:          secret,
+			expectAccepting: true,
+		},
+		{
+			name:            "htlc refund after relative lock",
+			timeLock:        txscript.TimeLock{Value: 1, IsRelative: true},
+			branch:          libsedrawallet.ContractBranchTimeLocked,
+			spender:         0,
+			expectAccepting: true,
+		},
+		{
+			name:            "htlc refund before relative lock",
+			timeLock:        txscript.TimeLock{Value: 1000, IsRelative: true},
+			branch:          libsedrawallet.ContractBranchTimeLocked,
+			spender:         0,
+			expectAccepting: false,
+		},
+	}
+
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		params := &consensusConfig.Params
+		forSchnorrAndECDSA(t, func(t *testing.T, ecdsa bool) {
+			for _, test := range tests {
+				consensusConfig.BlockCoinbaseMaturity = 0
+				tc, teardown, err := consensus.NewFactory().NewTestConsensus(consensusConfig, "TestContracts")
+				if err != nil {
+					t.Fatalf("Error setting up tc: %+v", err)
+				}
+
+				const path = "m/0/1"
+				mnemonics := make([]string, 2)
+				publicKeys := make([]string, 2)
+				addresses := make([]util.Address, 2)
+				for i := range mnemonics {
+					mnemonics[i], err = libsedrawallet.CreateMnemonic()
+					if err != nil {
+						t.Fatalf("CreateMnemonic: %+v", err)
+					}
+
+					publicKeys[i], err = libsedrawallet.MasterPublicKeyFromMnemonic(params, mnemonics[i], false)
+					if err != nil {
+						t.Fatalf("MasterPublicKeyFromMnemonic: %+v", err)
+					}
+
+					addresses[i], err = libsedrawallet.Address(params, publicKeys[i:i+1], 1, path, ecdsa)
+					if err != nil {
+						t.Fatalf("Address: %+v", err)
+					}
+				}
+
+				var contract *libsedrawallet.Contract
+				if test.isVault {
+					contract, err = libsedrawallet.NewTimeLockedVault(publicKeys[0], path, addresses[1], test.timeLock, ecdsa)
+				} else {
+					contract, err = libsedrawallet.NewHashTimeLockedContract(publicKeys[0], path, addresses[1],
+						secretHash[:], test.timeLock, ecdsa)
+				}
+				if err != nil {
+					t.Fatalf("%s: creating the contract: %+v", test.name, err)
+				}
+
+				parsedContract, err := libsedrawallet.ParseContract(contract.RedeemScript)
+				if err != nil {
+					t.Fatalf("%s: ParseContract: %+v", test.name, err)
+				}
+				if parsedContract.Type() != contract.Type() || parsedContract.TimeLock() != test.timeLock {
+					t.Fatalf("%s: the parsed contract is different than the created one", test.name)
+				}
+
+				contractAddress, err := contract.Address(params)
+				if err != nil {
+					t.Fatalf("%s: Address: %+v", test.name, err)
+				}
+				scriptPublicKey, err := txscript.PayToAddrScript(contractAddress)
+				if err != nil {
+					t.Fatalf("%s: PayToAddrScript: %+v", test.name, err)
+				}
+
+				fundingBlockHash, _, err := tc.AddBlock([]*externalapi.DomainHash{consensusConfig.GenesisHash},
+					&externalapi.DomainCoinbaseData{ScriptPublicKey: scriptPublicKey}, nil)
+				if err != nil {
+					t.Fatalf("%s: AddBlock: %+v", test.name, err)
+				}
+
+				block1Hash, _, err := tc.AddBlock([]*externalapi.DomainHash{fundingBlockHash}, nil, nil)
+				if err != nil {
+					t.Fatalf("%s: AddBlock: %+v", test.name, err)
+				}
+
+				block1, _, err := tc.GetBlock(block1Hash)
+				if err != nil {
+					t.Fatalf("%s: GetBlock: %+v", test.name, err)
+				}
+
+				block1TxOut := block1.Transactions[0].Outputs[0]
+				selectedUTXOs := []*libsedrawallet.UTXO{
+					{
+						Outpoint: &externalapi.DomainOutpoint{
+							TransactionID: *consensushashing.TransactionID(block1.Transactions[0]),
+							Index:         0,
+						},
+						UTXOEntry:      utxo.NewUTXOEntry(block1TxOut.Value, block1TxOut.ScriptPublicKey, true, 0),
+						DerivationPath: path,
+					},
+				}
+				payments := []*libsedrawallet.Payment{{
+					Address: addresses[test.spender],
+					Amount:  block1TxOut.Value - 10_000,
+				}}
+
+				otherParty := 1 - test.spender
+				_, err = libsedrawallet.CreateUnsignedContractTransaction(publicKeys[otherParty], contract, test.branch,
+					test.secret, payments, selectedUTXOs)
+				if err == nil {
+					t.Fatalf("%s: a spend was created for a key that isn't in the branch", test.name)
+				}
+
+				unsignedTransaction, err := libsedrawallet.CreateUnsignedContractTransaction(publicKeys[test.spender],
+					contract, test.branch, test.secret, payments, selectedUTXOs)
+				if err != nil {
+					t.Fatalf("%s: CreateUnsignedContractTransaction: %+v", test.name, err)
+				}
+
+				signedTransaction, err := libsedrawallet.Sign(params, mnemonics[test.spender:test.spender+1],
+					unsignedTransaction, ecdsa)
+				if err != nil {
+					t.Fatalf("%s: Sign: %+v", test.name, err)
+				}
+
+				tx, err := libsedrawallet.ExtractTransaction(signedTransaction, ecdsa)
+				if err != nil {
+					t.Fatalf("%s: ExtractTransaction: %+v", test.name, err)
+				}
+
+				// The funding output is only added to the UTXO set once block1 is merged, so
+				// another block is added before the spend in order for relative locks to be
+				// counted from it
+				block2Hash, _, err := tc.AddBlock([]*externalapi.DomainHash{block1Hash}, nil, nil)
+				if err != nil {
+					t.Fatalf("%s: AddBlock: %+v", test.name, err)
+				}
+
+				_, virtualChangeSet, err := tc.AddBlock([]*externalapi.DomainHash{block2Hash}, nil,
+					[]*externalapi.DomainTransaction{tx})
+				addedUTXO := &externalapi.DomainOutpoint{
+					TransactionID: *consensushashing.TransactionID(tx),
+					Index:         0,
+				}
+				isAccepted := err == nil && virtualChangeSet.VirtualUTXODiff.ToAdd().Contains(addedUTXO)
+				if isAccepted != test.expectAccepting {
+					t.Fatalf("%s: expected the transaction to be accepted: %t, but it was accepted: %t (error: %v)",
+						test.name, test.expectAccepting, isAccepted, err)
+				}
+
+				teardown(false)
+			}
+		})
+	})
+}
+
+func TestCreateUnsignedContractTransactionSecret(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x5e}, txscript.HashTimeLockedContractSecretSize)
+	secretHash := sha256.Sum256(secret)
+
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		params := &consensusConfig.Params
+		mnemonic, err := libsedrawallet.CreateMnemonic()
+		if err != nil {
+			t.Fatalf("CreateMnemonic: %+v", err)
+		}
+		publicKey, err := libsedrawallet.MasterPublicKeyFromMnemonic(params, mnemonic, false)
+		if err != nil {
+			t.Fatalf("MasterPublicKeyFromMnemonic: %+v", err)
+		}
+		address, err := libsedrawallet.Address(params, []string{publicKey}, 1, "m/0/0", false)
+		if err != nil {
+			t.Fatalf("Address: %+v", err)
+		}
+
+		contract, err := libsedrawallet.NewHashTimeLockedContract(publicKey, "m/0/1", address, secretHash[:],
+			txscript.TimeLock{Value: 1}, false)
+		if err != nil {
+			t.Fatalf("NewHashTimeLockedContract: %+v", err)
+		}
+
+		wrongSecret := bytes.Repeat([]byte{0x01}, txscript.HashTimeLockedContractSecretSize)
+		for _, claimSecret := range [][]byte{nil, secret[:20], wrongSecret} {
+			_, err := libsedrawallet.CreateUnsignedContractTransaction(publicKey, contract,
+				libsedrawallet.ContractBranchImmediate, claimSecret, nil, nil)
+			if err == nil {
+				t.Fatalf("A claim was created with the invalid secret %x", claimSecret)
+			}
+		}
+
+		_, err = libsedrawallet.CreateUnsignedContractTransaction(publicKey, contract,
+			libsedrawallet.ContractBranchTimeLocked, secret, nil, nil)
+		if err == nil {
+			t.Fatalf("A refund was created with a secret")
+		}
+	})
+}
@@ -132,6 +132,45 @@ func p2pkAddress(params *dagconfig.Params, extendedPublicKey string, path string
 	return util.NewAddressPublicKey(serializedSchnorrPublicKey[:], params.Prefix)
 }
 
+// derivedPublicKey returns the serialized public key derived from extendedPublicKey
+// at the given path: an ECDSA public key if ecdsa is true, and a schnorr public key
+// otherwise.
+func derivedPublicKey(extendedPublicKey string, path string, ecdsa bool) ([]byte, error) {
+	extendedKey, err := bip32.DeserializeExtendedKey(extendedPublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	derivedKey, err := extendedKey.DeriveFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := derivedKey.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	if ecdsa {
+		serializedECDSAPublicKey, err := publicKey.Serialize()
+		if err != nil {
+			return nil, err
+		}
+		return serializedECDSAPublicKey[:], nil
+	}
+
+	schnorrPublicKey, err := publicKey.ToSchnorr()
+	if err != nil {
+		return nil, err
+	}
+
+	serializedSchnorrPublicKey, err := schnorrPublicKey.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	return serializedSchnorrPublicKey[:], nil
+}
+
 func sortPublicKeys(extendedPublicKeys []string) {
 	sort.Slice(extendedPublicKeys, func(i, j int) bool {
 		return strings.Compare(extendedPublicKeys[i], extendedPublicKeys[j]) < 0
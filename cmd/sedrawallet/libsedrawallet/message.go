@@ -0,0 +1,140 @@
+package libsedrawallet
+
+import (
+	"bytes"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet/bip32"
+	"github.com/sedracoin/sedrad/domain/dagconfig"
+	"github.com/sedracoin/sedrad/util/messagesigning"
+	"github.com/pkg/errors"
+)
+
+// SignMessage signs message with the private keys of the given mnemonics, on
+// behalf of the address at the given derivation path of the given account of
+// a wallet with the given extended public keys.
+//
+// For a single-key wallet the result is a complete signature. For a multisig
+// wallet it holds only the signatures of the given mnemonics, and the
+// signatures of the other cosigners have to be added with
+// CombineMessageSignatures until there are minimumSignatures of them.
+func SignMessage(params *dagconfig.Params, mnemonics []*Mnemonic, extendedPublicKeys []string, minimumSignatures uint32,
+	account uint32, path string, message []byte, ecdsa bool) ([]byte, error) {
+
+	isMultisig := len(extendedPublicKeys) > 1
+	if !isMultisig {
+		for _, mnemonic := range mnemonics {
+			signature, ok, err := signMessageWithMnemonic(params, mnemonic, extendedPublicKeys[0], account, path,
+				message, isMultisig, ecdsa)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				return signature, nil
+			}
+		}
+		return nil, errors.Errorf("none of the private keys belongs to the wallet")
+	}
+
+	sortedExtendedPublicKeys := append([]string(nil), extendedPublicKeys...)
+	sortPublicKeys(sortedExtendedPublicKeys)
+	redeemScript, err := multiSigRedeemScript(sortedExtendedPublicKeys, minimumSignatures, path, ecdsa)
+	if err != nil {
+		return nil, err
+	}
+
+	signatures := make([][]byte, len(sortedExtendedPublicKeys))
+	signed := false
+	for _, mnemonic := range mnemonics {
+		for i, extendedPublicKey := range sortedExtendedPublicKeys {
+			signature, ok, err := signMessageWithMnemonic(params, mnemonic, extendedPublicKey, account, path,
+				message, isMultisig, ecdsa)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				signatures[i] = signature
+				signed = true
+			}
+		}
+	}
+	if !signed {
+		return nil, errors.Errorf("none of the private keys belongs to the wallet")
+	}
+
+	return messagesigning.MultiSigSignature(redeemScript, signatures)
+}
+
+// signMessageWithMnemonic signs message with the key derived from mnemonic at
+// the given path of the given account, provided that it's the private key of
+// extendedPublicKey.
+func signMessageWithMnemonic(params *dagconfig.Params, mnemonic *Mnemonic, extendedPublicKey string, account uint32,
+	path string, message []byte, isMultisig bool, ecdsa bool) (signature []byte, ok bool, err error) {
+
+	extendedKey, err := extendedKeyFromMnemonicAndPath(mnemonic, defaultPath(isMultisig, account), params)
+	if err != nil {
+		return nil, false, err
+	}
+	derivedKey, err := extendedKey.DeriveFromPath(path)
+	if err != nil {
+		return nil, false, err
+	}
+	derivedPublicKey, err := derivedKey.Public()
+	if err != nil {
+		return nil, false, err
+	}
+
+	expectedKey, err := bip32.DeserializeExtendedKey(extendedPublicKey)
+	if err != nil {
+		return nil, false, err
+	}
+	expectedDerivedKey, err := expectedKey.DeriveFromPath(path)
+	if err != nil {
+		return nil, false, err
+	}
+	if derivedPublicKey.String() != expectedDerivedKey.String() {
+		return nil, false, nil
+	}
+
+	privateKey := derivedKey.PrivateKey()
+	if ecdsa {
+		signature, err = messagesigning.SignECDSA(privateKey, message)
+		return signature, err == nil, err
+	}
+
+	schnorrKeyPair, err := privateKey.ToSchnorr()
+	if err != nil {
+		return nil, false, err
+	}
+	signature, err = messagesigning.SignSchnorr(schnorrKeyPair, message)
+	return signature, err == nil, err
+}
+
+// CombineMessageSignatures combines the partial signatures that the cosigners
+// of a multisig address created with SignMessage into a single signature.
+func CombineMessageSignatures(signatures [][]byte) ([]byte, error) {
+	if len(signatures) == 0 {
+		return nil, errors.Errorf("no signatures to combine")
+	}
+
+	redeemScript, combinedSignatures, err := messagesigning.ParseMultiSigSignature(signatures[0])
+	if err != nil {
+		return nil, err
+	}
+	for _, signature := range signatures[1:] {
+		signatureRedeemScript, cosignerSignatures, err := messagesigning.ParseMultiSigSignature(signature)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(signatureRedeemScript, redeemScript) {
+			return nil, errors.Errorf("the signatures were made for different addresses")
+		}
+
+		for i, cosignerSignature := range cosignerSignatures {
+			if len(cosignerSignature) != 0 {
+				combinedSignatures[i] = cosignerSignature
+			}
+		}
+	}
+
+	return messagesigning.MultiSigSignature(redeemScript, combinedSignatures)
+}
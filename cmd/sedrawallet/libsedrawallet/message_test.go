@@ -0,0 +1,135 @@
+package libsedrawallet_test
+
+import (
+	"testing"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
+	"github.com/sedracoin/sedrad/domain/consensus"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/testutils"
+	"github.com/sedracoin/sedrad/util/messagesigning"
+)
+
+func TestSignMessage(t *testing.T) {
+	message := []byte("I control this address")
+
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		params := &consensusConfig.Params
+		forSchnorrAndECDSA(t, func(t *testing.T, ecdsa bool) {
+			mnemonic, err := libsedrawallet.CreateMnemonic()
+			if err != nil {
+				t.Fatalf("CreateMnemonic: %+v", err)
+			}
+			publicKey, err := libsedrawallet.MasterPublicKeyFromMnemonic(params, mnemonic, false)
+			if err != nil {
+				t.Fatalf("MasterPublicKeyFromMnemonic: %+v", err)
+			}
+
+			const path = "m/0/3"
+			address, err := libsedrawallet.Address(params, []string{publicKey}, 1, path, ecdsa)
+			if err != nil {
+				t.Fatalf("Address: %+v", err)
+			}
+			otherAddress, err := libsedrawallet.Address(params, []string{publicKey}, 1, "m/0/4", ecdsa)
+			if err != nil {
+				t.Fatalf("Address: %+v", err)
+			}
+
+			signature, err := libsedrawallet.SignMessage(params, []*libsedrawallet.Mnemonic{{Phrase: mnemonic}},
+				[]string{publicKey}, 1, 0, path, message, ecdsa)
+			if err != nil {
+				t.Fatalf("SignMessage: %+v", err)
+			}
+
+			err = messagesigning.Verify(address, message, signature)
+			if err != nil {
+				t.Fatalf("Verify: %+v", err)
+			}
+			err = messagesigning.Verify(address, []byte("I control another address"), signature)
+			if err == nil {
+				t.Fatalf("The signature is valid for a different message")
+			}
+			err = messagesigning.Verify(otherAddress, message, signature)
+			if err == nil {
+				t.Fatalf("The signature is valid for a different address")
+			}
+
+			otherMnemonic, err := libsedrawallet.CreateMnemonic()
+			if err != nil {
+				t.Fatalf("CreateMnemonic: %+v", err)
+			}
+			_, err = libsedrawallet.SignMessage(params, []*libsedrawallet.Mnemonic{{Phrase: otherMnemonic}},
+				[]string{publicKey}, 1, 0, path, message, ecdsa)
+			if err == nil {
+				t.Fatalf("A message was signed with a key that doesn't belong to the wallet")
+			}
+		})
+	})
+}
+
+func TestSignMessageMultisig(t *testing.T) {
+	message := []byte("We control this address")
+
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		params := &consensusConfig.Params
+		forSchnorrAndECDSA(t, func(t *testing.T, ecdsa bool) {
+			const numKeys = 3
+			const minimumSignatures = 2
+			mnemonics := make([]string, numKeys)
+			publicKeys := make([]string, numKeys)
+			for i := range mnemonics {
+				var err error
+				mnemonics[i], err = libsedrawallet.CreateMnemonic()
+				if err != nil {
+					t.Fatalf("CreateMnemonic: %+v", err)
+				}
+				publicKeys[i], err = libsedrawallet.MasterPublicKeyFromMnemonic(params, mnemonics[i], true)
+				if err != nil {
+					t.Fatalf("MasterPublicKeyFromMnemonic: %+v", err)
+				}
+			}
+
+			const path = "m/1/0/2"
+			address, err := libsedrawallet.Address(params, publicKeys, minimumSignatures, path, ecdsa)
+			if err != nil {
+				t.Fatalf("Address: %+v", err)
+			}
+
+			signatures := make([][]byte, numKeys)
+			for i, mnemonic := range mnemonics {
+				signatures[i], err = libsedrawallet.SignMessage(params, []*libsedrawallet.Mnemonic{{Phrase: mnemonic}},
+					publicKeys, minimumSignatures, 0, path, message, ecdsa)
+				if err != nil {
+					t.Fatalf("SignMessage: %+v", err)
+				}
+
+				err = messagesigning.Verify(address, message, signatures[i])
+				if err == nil {
+					t.Fatalf("A single cosigner signature is valid for a 2-of-3 address")
+				}
+			}
+
+			combinedSignature, err := libsedrawallet.CombineMessageSignatures([][]byte{signatures[0], signatures[2]})
+			if err != nil {
+				t.Fatalf("CombineMessageSignatures: %+v", err)
+			}
+			err = messagesigning.Verify(address, message, combinedSignature)
+			if err != nil {
+				t.Fatalf("Verify: %+v", err)
+			}
+			err = messagesigning.Verify(address, []byte("We control another address"), combinedSignature)
+			if err == nil {
+				t.Fatalf("The signature is valid for a different message")
+			}
+
+			otherPathSignature, err := libsedrawallet.SignMessage(params, []*libsedrawallet.Mnemonic{{Phrase: mnemonics[1]}},
+				publicKeys, minimumSignatures, 0, "m/1/0/3", message, ecdsa)
+			if err != nil {
+				t.Fatalf("SignMessage: %+v", err)
+			}
+			_, err = libsedrawallet.CombineMessageSignatures([][]byte{signatures[0], otherPathSignature})
+			if err == nil {
+				t.Fatalf("Signatures of different addresses were combined")
+			}
+		})
+	})
+}
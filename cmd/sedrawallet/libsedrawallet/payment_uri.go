@@ -0,0 +1,153 @@
+package libsedrawallet
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/utils"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/constants"
+	"github.com/sedracoin/sedrad/domain/dagconfig"
+	"github.com/sedracoin/sedrad/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	paymentURIAmountParameter  = "amount"
+	paymentURILabelParameter   = "label"
+	paymentURIMessageParameter = "message"
+	paymentURIExpiryParameter  = "expires"
+
+	// paymentURIRequiredParameterPrefix marks parameters that a payer must
+	// understand in order to pay the request.
+	paymentURIRequiredParameterPrefix = "req-"
+)
+
+// PaymentRequest is a request for a payment to an address, that a merchant
+// hands to the payer as a payment URI.
+//
+// The URI of a request is its address, whose prefix ("sedra" on mainnet)
+// serves as the URI scheme, followed by the optional query parameters
+// "amount" (in sedra), "label", "message" and "expires" (a UNIX timestamp in
+// seconds), e.g.:
+//
+//	sedra:<address payload>?amount=12.5&label=Shop&message=Order%2042
+//
+// The URI only contains characters that are allowed in URIs, so it can be
+// encoded as-is in a QR code.
+type PaymentRequest struct {
+	Address util.Address
+	// Amount is the requested amount in seep, or 0 if the payer chooses it
+	Amount  uint64
+	Label   string
+	Message string
+	// Expiry is the time after which the request shouldn't be paid, or the zero
+	// time if the request doesn't expire
+	Expiry time.Time
+}
+
+// URI serializes the payment request into a payment URI
+func (pr *PaymentRequest) URI() string {
+	var parameters []string
+	if pr.Amount != 0 {
+		parameters = append(parameters, paymentURIAmountParameter+"="+formatSedraAmount(pr.Amount))
+	}
+	if pr.Label != "" {
+		parameters = append(parameters, paymentURILabelParameter+"="+escapePaymentURIValue(pr.Label))
+	}
+	if pr.Message != "" {
+		parameters = append(parameters, paymentURIMessageParameter+"="+escapePaymentURIValue(pr.Message))
+	}
+	if !pr.Expiry.IsZero() {
+		parameters = append(parameters, paymentURIExpiryParameter+"="+strconv.FormatInt(pr.Expiry.Unix(), 10))
+	}
+
+	uri := pr.Address.String()
+	if len(parameters) > 0 {
+		uri += "?" + strings.Join(parameters, "&")
+	}
+	return uri
+}
+
+// IsExpired returns whether the payment request has expired at the given time
+func (pr *PaymentRequest) IsExpired(now time.Time) bool {
+	return !pr.Expiry.IsZero() && !now.Before(pr.Expiry)
+}
+
+// ParsePaymentURI parses a payment URI that was created by PaymentRequest.URI,
+// or by any other wallet that follows the same scheme. The address of the URI
+// must belong to the network of the given params.
+//
+// Unknown parameters are ignored, unless they start with "req-", in which case
+// the URI is rejected, since the payer is required to understand them.
+func ParsePaymentURI(uri string, params *dagconfig.Params) (*PaymentRequest, error) {
+	addressPart, query, _ := strings.Cut(strings.TrimSpace(uri), "?")
+	if !strings.Contains(addressPart, ":") {
+		return nil, errors.Errorf("payment URI %s has no scheme", uri)
+	}
+	address, err := util.DecodeAddress(addressPart, params.Prefix)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid address in payment URI %s", uri)
+	}
+
+	parameters, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid parameters in payment URI %s", uri)
+	}
+
+	paymentRequest := &PaymentRequest{Address: address}
+	for name, values := range parameters {
+		if len(values) > 1 {
+			return nil, errors.Errorf("parameter %s appears more than once in payment URI %s", name, uri)
+		}
+		value := values[0]
+
+		switch name {
+		case paymentURIAmountParameter:
+			paymentRequest.Amount, err = utils.SdrToSeep(value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid amount %s in payment URI %s", value, uri)
+			}
+			if paymentRequest.Amount == 0 {
+				return nil, errors.Errorf("payment URI %s requests an amount of 0", uri)
+			}
+		case paymentURILabelParameter:
+			paymentRequest.Label = value
+		case paymentURIMessageParameter:
+			paymentRequest.Message = value
+		case paymentURIExpiryParameter:
+			expiry, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || expiry <= 0 {
+				return nil, errors.Errorf("invalid expiry %s in payment URI %s", value, uri)
+			}
+			paymentRequest.Expiry = time.Unix(expiry, 0)
+		default:
+			if strings.HasPrefix(name, paymentURIRequiredParameterPrefix) {
+				return nil, errors.Errorf("payment URI %s requires the unsupported parameter %s", uri, name)
+			}
+		}
+	}
+
+	return paymentRequest, nil
+}
+
+// formatSedraAmount formats an amount of seep in sedra, without trailing
+// zeros, so that it's as short as possible
+func formatSedraAmount(amount uint64) string {
+	whole := amount / constants.SeepPerSedra
+	fraction := amount % constants.SeepPerSedra
+	if fraction == 0 {
+		return strconv.FormatUint(whole, 10)
+	}
+
+	fractionString := strings.TrimRight(fmt.Sprintf("%08d", fraction), "0")
+	return strconv.FormatUint(whole, 10) + "." + fractionString
+}
+
+// escapePaymentURIValue escapes a parameter value of a payment URI. Spaces
+// are escaped as %20 rather than +, which not all payers decode as a space.
+func escapePaymentURIValue(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
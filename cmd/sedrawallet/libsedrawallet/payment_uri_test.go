@@ -0,0 +1,93 @@
+package libsedrawallet_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
+	"github.com/sedracoin/sedrad/domain/consensus"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/testutils"
+)
+
+func TestPaymentURI(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		params := &consensusConfig.Params
+
+		mnemonic, err := libsedrawallet.CreateMnemonic()
+		if err != nil {
+			t.Fatalf("CreateMnemonic: %+v", err)
+		}
+		publicKey, err := libsedrawallet.MasterPublicKeyFromMnemonic(params, mnemonic, false)
+		if err != nil {
+			t.Fatalf("MasterPublicKeyFromMnemonic: %+v", err)
+		}
+		address, err := libsedrawallet.Address(params, []string{publicKey}, 1, "m/0/1", false)
+		if err != nil {
+			t.Fatalf("Address: %+v", err)
+		}
+
+		paymentRequest := &libsedrawallet.PaymentRequest{
+			Address: address,
+			Amount:  1_250_000_000,
+			Label:   "Coffee & Co.",
+			Message: "Order 42+1",
+			Expiry:  time.Unix(1700000000, 0),
+		}
+		uri := paymentRequest.URI()
+		expectedURI := address.String() + "?amount=12.5&label=Coffee%20%26%20Co.&message=Order%2042%2B1&expires=1700000000"
+		if uri != expectedURI {
+			t.Fatalf("Unexpected URI. Want: %s, got: %s", expectedURI, uri)
+		}
+
+		parsed, err := libsedrawallet.ParsePaymentURI(uri, params)
+		if err != nil {
+			t.Fatalf("ParsePaymentURI: %+v", err)
+		}
+		if parsed.Address.String() != address.String() || parsed.Amount != paymentRequest.Amount ||
+			parsed.Label != paymentRequest.Label || parsed.Message != paymentRequest.Message ||
+			!parsed.Expiry.Equal(paymentRequest.Expiry) {
+
+			t.Fatalf("The parsed payment request %+v is different than the original %+v", parsed, paymentRequest)
+		}
+
+		if !parsed.IsExpired(paymentRequest.Expiry) || parsed.IsExpired(paymentRequest.Expiry.Add(-time.Second)) {
+			t.Fatalf("IsExpired is wrong around the expiry time")
+		}
+
+		bareURI := (&libsedrawallet.PaymentRequest{Address: address}).URI()
+		if bareURI != address.String() {
+			t.Fatalf("A payment request without parameters should be just the address. Got: %s", bareURI)
+		}
+		bare, err := libsedrawallet.ParsePaymentURI(bareURI, params)
+		if err != nil {
+			t.Fatalf("ParsePaymentURI: %+v", err)
+		}
+		if bare.Amount != 0 || !bare.Expiry.IsZero() || bare.IsExpired(time.Now()) {
+			t.Fatalf("Unexpected parameters in a payment request without parameters: %+v", bare)
+		}
+
+		_, err = libsedrawallet.ParsePaymentURI(address.String()+"?amount=1&unknown=x", params)
+		if err != nil {
+			t.Fatalf("Unknown optional parameters should be ignored: %+v", err)
+		}
+
+		addressPayload := strings.SplitN(address.String(), ":", 2)[1]
+		invalidURIs := []string{
+			addressPayload,
+			address.String() + "?amount=0",
+			address.String() + "?amount=1.123456789",
+			address.String() + "?amount=abc",
+			address.String() + "?amount=1&amount=2",
+			address.String() + "?expires=yesterday",
+			address.String() + "?req-refund=1",
+			"sedra:invalid",
+		}
+		for _, invalidURI := range invalidURIs {
+			_, err := libsedrawallet.ParsePaymentURI(invalidURI, params)
+			if err == nil {
+				t.Fatalf("ParsePaymentURI unexpectedly succeeded for %s", invalidURI)
+			}
+		}
+	})
+}
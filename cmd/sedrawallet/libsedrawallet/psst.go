@@ -0,0 +1,214 @@
+package libsedrawallet
+
+import (
+	"bytes"
+
+	"github.com/sedracoin/go-secp256k1"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet/serialization"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/utxo"
+	"github.com/pkg/errors"
+)
+
+// CombinePartiallySignedTransactions merges the signatures of several copies
+// of the same partially signed transaction, each signed by other cosigners,
+// into a single partially signed transaction.
+func CombinePartiallySignedTransactions(partiallySignedTransactions []*serialization.PartiallySignedTransaction) (
+	*serialization.PartiallySignedTransaction, error) {
+
+	if len(partiallySignedTransactions) == 0 {
+		return nil, errors.New("no transactions to combine")
+	}
+
+	combined := partiallySignedTransactions[0].Clone()
+	combinedID := consensushashing.TransactionID(combined.Tx)
+	for i, partiallySignedTransaction := range partiallySignedTransactions[1:] {
+		transactionID := consensushashing.TransactionID(partiallySignedTransaction.Tx)
+		if !transactionID.Equal(combinedID) {
+			return nil, errors.Errorf("transaction #%d is %s while transaction #1 is %s",
+				i+2, transactionID, combinedID)
+		}
+		if len(partiallySignedTransaction.PartiallySignedInputs) != len(combined.PartiallySignedInputs) {
+			return nil, errors.Errorf("transaction #%d has %d partially signed inputs while transaction #1 has %d",
+				i+2, len(partiallySignedTransaction.PartiallySignedInputs), len(combined.PartiallySignedInputs))
+		}
+
+		for j, input := range partiallySignedTransaction.PartiallySignedInputs {
+			combinedInput := combined.PartiallySignedInputs[j]
+			if len(input.PubKeySignaturePairs) != len(combinedInput.PubKeySignaturePairs) ||
+				input.MinimumSignatures != combinedInput.MinimumSignatures ||
+				!bytes.Equal(input.RedeemScript, combinedInput.RedeemScript) {
+
+				return nil, errors.Errorf("input %d of transaction #%d is spent differently than in transaction #1",
+					j, i+2)
+			}
+
+			for k, pair := range input.PubKeySignaturePairs {
+				combinedPair := combinedInput.PubKeySignaturePairs[k]
+				if pair.ExtendedPublicKey != combinedPair.ExtendedPublicKey {
+					return nil, errors.Errorf("input %d of transaction #%d has different public keys than in "+
+						"transaction #1", j, i+2)
+				}
+				if combinedPair.Signature == nil && pair.Signature != nil {
+					combinedPair.Signature = append([]byte(nil), pair.Signature...)
+				}
+			}
+		}
+	}
+
+	return combined, nil
+}
+
+// VerifyPartialSignatures checks every signature of a partially signed
+// transaction against the public key it's attached to, so that a signature
+// received from another party can't block the real signature of that
+// cosigner.
+func VerifyPartialSignatures(partiallySignedTransaction *serialization.PartiallySignedTransaction, ecdsa bool) error {
+	clone := partiallySignedTransaction.Clone()
+	populateSigningFields(clone)
+
+	sighashReusedValues := &consensushashing.SighashReusedValues{}
+	for i, input := range clone.PartiallySignedInputs {
+		for _, pair := range input.PubKeySignaturePairs {
+			if pair.Signature == nil {
+				continue
+			}
+
+			valid, err := verifyPartialSignature(clone.Tx, i, pair, sighashReusedValues, ecdsa)
+			if err != nil {
+				return errors.Wrapf(err, "could not verify the signature of %s on input %d",
+					pair.ExtendedPublicKey, i)
+			}
+			if !valid {
+				return errors.Errorf("the signature of %s on input %d is invalid", pair.ExtendedPublicKey, i)
+			}
+		}
+	}
+	return nil
+}
+
+func verifyPartialSignature(tx *externalapi.DomainTransaction, inputIndex int, pair *serialization.PubKeySignaturePair,
+	sighashReusedValues *consensushashing.SighashReusedValues, ecdsa bool) (bool, error) {
+
+	if len(pair.Signature) < 2 {
+		return false, nil
+	}
+	hashType := serialization.SignatureSigHashType(pair.Signature)
+	if !hashType.IsStandardSigHashType() {
+		return false, errors.Errorf("invalid sighash type %d", hashType)
+	}
+	signature := pair.Signature[:len(pair.Signature)-1]
+
+	publicKey, err := derivedPublicKey(pair.ExtendedPublicKey, "m", ecdsa)
+	if err != nil {
+		return false, err
+	}
+
+	if ecdsa {
+		sigHash, err := consensushashing.CalculateSignatureHashECDSA(tx, inputIndex, hashType, sighashReusedValues)
+		if err != nil {
+			return false, err
+		}
+		ecdsaPublicKey, err := secp256k1.DeserializeECDSAPubKey(publicKey)
+		if err != nil {
+			return false, err
+		}
+		ecdsaSignature, err := secp256k1.DeserializeECDSASignatureFromSlice(signature)
+		if err != nil {
+			return false, nil
+		}
+		secpHash := secp256k1.Hash(*sigHash.ByteArray())
+		return ecdsaPublicKey.ECDSAVerify(&secpHash, ecdsaSignature), nil
+	}
+
+	sigHash, err := consensushashing.CalculateSignatureHashSchnorr(tx, inputIndex, hashType, sighashReusedValues)
+	if err != nil {
+		return false, err
+	}
+	schnorrPublicKey, err := secp256k1.DeserializeSchnorrPubKey(publicKey)
+	if err != nil {
+		return false, err
+	}
+	schnorrSignature, err := secp256k1.DeserializeSchnorrSignatureFromSlice(signature)
+	if err != nil {
+		return false, nil
+	}
+	secpHash := secp256k1.Hash(*sigHash.ByteArray())
+	return schnorrPublicKey.SchnorrVerify(&secpHash, schnorrSignature), nil
+}
+
+// FinalizeTransaction builds the signature scripts of a fully signed
+// transaction, verifies them against the UTXO entries the transaction spends,
+// and returns the transaction that is ready to be broadcast.
+//
+// Unlike ExtractTransaction, it doesn't need to know whether the wallet uses
+// ECDSA: it finds the multisig redeem script that matches the script public
+// key of the output the first multisig input spends.
+func FinalizeTransaction(partiallySignedTransaction *serialization.PartiallySignedTransaction) (
+	*externalapi.DomainTransaction, error) {
+
+	if !isTransactionFullySigned(partiallySignedTransaction) {
+		return nil, errors.New("the transaction is not fully signed")
+	}
+
+	ecdsa := false
+	for i, input := range partiallySignedTransaction.PartiallySignedInputs {
+		if input.RedeemScript == nil && len(input.PubKeySignaturePairs) > 1 {
+			var err error
+			ecdsa, err = isMultisigInputECDSA(input)
+			if err != nil {
+				return nil, errors.Wrapf(err, "input %d", i)
+			}
+			break
+		}
+	}
+
+	tx, err := ExtractTransactionDeserialized(partiallySignedTransaction.Clone(), ecdsa)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, input := range partiallySignedTransaction.PartiallySignedInputs {
+		tx.Inputs[i].UTXOEntry = utxo.NewUTXOEntry(input.PrevOutput.Value, input.PrevOutput.ScriptPublicKey,
+			input.PrevOutputIsCoinbase, input.PrevOutputBlockDAAScore)
+	}
+
+	sighashReusedValues := &consensushashing.SighashReusedValues{}
+	for i, input := range tx.Inputs {
+		engine, err := txscript.NewEngine(input.UTXOEntry.ScriptPublicKey(), tx, i, txscript.ScriptNoFlags, nil, nil,
+			sighashReusedValues)
+		if err != nil {
+			return nil, errors.Wrapf(err, "input %d", i)
+		}
+		err = engine.Execute()
+		if err != nil {
+			return nil, errors.Wrapf(err, "the signature script of input %d is invalid", i)
+		}
+	}
+
+	for _, input := range tx.Inputs {
+		input.UTXOEntry = nil
+	}
+	return tx, nil
+}
+
+// isMultisigInputECDSA returns whether the multisig redeem script of input,
+// as derived from its public keys, uses ECDSA.
+func isMultisigInputECDSA(input *serialization.PartiallySignedInput) (bool, error) {
+	for _, ecdsa := range []bool{false, true} {
+		redeemScript, err := partiallySignedInputMultisigRedeemScript(input, ecdsa)
+		if err != nil {
+			return false, err
+		}
+		scriptPublicKey, err := txscript.PayToScriptHashScript(redeemScript)
+		if err != nil {
+			return false, err
+		}
+		if bytes.Equal(scriptPublicKey, input.PrevOutput.ScriptPublicKey.Script) {
+			return ecdsa, nil
+		}
+	}
+	return false, errors.New("the public keys don't match the script public key of the spent output")
+}
@@ -0,0 +1,232 @@
+package libsedrawallet_test
+
+import (
+	"testing"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet/serialization"
+	"github.com/sedracoin/sedrad/domain/consensus"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/testutils"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/utxo"
+)
+
+func TestCombineAndFinalizePSST(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		params := &consensusConfig.Params
+		forSchnorrAndECDSA(t, func(t *testing.T, ecdsa bool) {
+			consensusConfig.BlockCoinbaseMaturity = 0
+			tc, teardown, err := consensus.NewFactory().NewTestConsensus(consensusConfig, "TestCombineAndFinalizePSST")
+			if err != nil {
+				t.Fatalf("Error setting up tc: %+v", err)
+			}
+			defer teardown(false)
+
+			const numKeys = 3
+			mnemonics := make([]string, numKeys)
+			publicKeys := make([]string, numKeys)
+			for i := 0; i < numKeys; i++ {
+				mnemonics[i], err = libsedrawallet.CreateMnemonic()
+				if err != nil {
+					t.Fatalf("CreateMnemonic: %+v", err)
+				}
+
+				publicKeys[i], err = libsedrawallet.MasterPublicKeyFromMnemonic(params, mnemonics[i], true)
+				if err != nil {
+					t.Fatalf("MasterPublicKeyFromMnemonic: %+v", err)
+				}
+			}
+
+			const minimumSignatures = 2
+			const path = "m/1/2/3"
+			address, err := libsedrawallet.Address(params, publicKeys, minimumSignatures, path, ecdsa)
+			if err != nil {
+				t.Fatalf("Address: %+v", err)
+			}
+
+			scriptPublicKey, err := txscript.PayToAddrScript(address)
+			if err != nil {
+				t.Fatalf("PayToAddrScript: %+v", err)
+			}
+
+			fundingBlockHash, _, err := tc.AddBlock([]*externalapi.DomainHash{consensusConfig.GenesisHash},
+				&externalapi.DomainCoinbaseData{ScriptPublicKey: scriptPublicKey}, nil)
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+
+			block1Hash, _, err := tc.AddBlock([]*externalapi.DomainHash{fundingBlockHash}, nil, nil)
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+
+			block1, _, err := tc.GetBlock(block1Hash)
+			if err != nil {
+				t.Fatalf("GetBlock: %+v", err)
+			}
+
+			block1TxOut := block1.Transactions[0].Outputs[0]
+			selectedUTXOs := []*libsedrawallet.UTXO{
+				{
+					Outpoint: &externalapi.DomainOutpoint{
+						TransactionID: *consensushashing.TransactionID(block1.Transactions[0]),
+						Index:         0,
+					},
+					UTXOEntry:      utxo.NewUTXOEntry(block1TxOut.Value, block1TxOut.ScriptPublicKey, true, 7),
+					DerivationPath: path,
+				},
+			}
+
+			unsignedTransaction, err := libsedrawallet.CreateUnsignedTransaction(publicKeys, minimumSignatures,
+				[]*libsedrawallet.Payment{{
+					Address: address,
+					Amount:  10,
+				}}, selectedUTXOs)
+			if err != nil {
+				t.Fatalf("CreateUnsignedTransactions: %+v", err)
+			}
+
+			// Each cosigner signs the unsigned transaction on their own, and passes it on as a PSST
+			var signedPSSTs []*serialization.PartiallySignedTransaction
+			for i := 0; i < minimumSignatures; i++ {
+				signedTransaction, err := libsedrawallet.Sign(params, mnemonics[i:i+1], unsignedTransaction, ecdsa)
+				if err != nil {
+					t.Fatalf("Sign: %+v", err)
+				}
+
+				partiallySignedTransaction, err := serialization.DeserializePartiallySignedTransaction(signedTransaction)
+				if err != nil {
+					t.Fatalf("DeserializePartiallySignedTransaction: %+v", err)
+				}
+
+				binaryPSST, err := serialization.SerializePSST(partiallySignedTransaction)
+				if err != nil {
+					t.Fatalf("SerializePSST: %+v", err)
+				}
+				if !serialization.IsPSST(binaryPSST) {
+					t.Fatalf("The binary PSST doesn't start with the PSST magic bytes")
+				}
+				partiallySignedTransaction, err = serialization.DeserializePSST(binaryPSST)
+				if err != nil {
+					t.Fatalf("DeserializePSST: %+v", err)
+				}
+
+				jsonPSST, err := serialization.PSSTToJSON(partiallySignedTransaction)
+				if err != nil {
+					t.Fatalf("PSSTToJSON: %+v", err)
+				}
+				partiallySignedTransaction, err = serialization.PSSTFromJSON(jsonPSST)
+				if err != nil {
+					t.Fatalf("PSSTFromJSON: %+v", err)
+				}
+
+				signedPSSTs = append(signedPSSTs, partiallySignedTransaction)
+			}
+
+			input := signedPSSTs[0].PartiallySignedInputs[0]
+			if input.PrevOutputBlockDAAScore != 7 || !input.PrevOutputIsCoinbase {
+				t.Fatalf("The UTXO entry of the input wasn't kept in the PSST")
+			}
+
+			_, err = libsedrawallet.FinalizeTransaction(signedPSSTs[0])
+			if err == nil {
+				t.Fatalf("FinalizeTransaction unexpectedly succeeded with a single signature")
+			}
+
+			combined, err := libsedrawallet.CombinePartiallySignedTransactions(signedPSSTs)
+			if err != nil {
+				t.Fatalf("CombinePartiallySignedTransactions: %+v", err)
+			}
+
+			err = libsedrawallet.VerifyPartialSignatures(combined, ecdsa)
+			if err != nil {
+				t.Fatalf("VerifyPartialSignatures: %+v", err)
+			}
+
+			tampered := combined.Clone()
+			for _, pair := range tampered.PartiallySignedInputs[0].PubKeySignaturePairs {
+				if pair.Signature != nil {
+					pair.Signature[0] ^= 1
+					break
+				}
+			}
+			err = libsedrawallet.VerifyPartialSignatures(tampered, ecdsa)
+			if err == nil {
+				t.Fatalf("VerifyPartialSignatures unexpectedly accepted a tampered signature")
+			}
+
+			for _, pair := range combined.PartiallySignedInputs[0].PubKeySignaturePairs {
+				if pair.Signature != nil &&
+					serialization.SignatureSigHashType(pair.Signature) != consensushashing.SigHashAll {
+					t.Fatalf("Unexpected sighash type %d", serialization.SignatureSigHashType(pair.Signature))
+				}
+			}
+
+			tx, err := libsedrawallet.FinalizeTransaction(combined)
+			if err != nil {
+				t.Fatalf("FinalizeTransaction: %+v", err)
+			}
+
+			_, virtualChangeSet, err := tc.AddBlock([]*externalapi.DomainHash{block1Hash}, nil,
+				[]*externalapi.DomainTransaction{tx})
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+
+			addedUTXO := &externalapi.DomainOutpoint{
+				TransactionID: *consensushashing.TransactionID(tx),
+				Index:         0,
+			}
+			if !virtualChangeSet.VirtualUTXODiff.ToAdd().Contains(addedUTXO) {
+				t.Fatalf("Transaction wasn't accepted in the DAG")
+			}
+
+			otherUnsignedTransaction, err := libsedrawallet.CreateUnsignedTransaction(publicKeys, minimumSignatures,
+				[]*libsedrawallet.Payment{{
+					Address: address,
+					Amount:  20,
+				}}, selectedUTXOs)
+			if err != nil {
+				t.Fatalf("CreateUnsignedTransactions: %+v", err)
+			}
+			otherPartiallySignedTransaction, err :=
+				serialization.DeserializePartiallySignedTransaction(otherUnsignedTransaction)
+			if err != nil {
+				t.Fatalf("DeserializePartiallySignedTransaction: %+v", err)
+			}
+			_, err = libsedrawallet.CombinePartiallySignedTransactions(
+				[]*serialization.PartiallySignedTransaction{signedPSSTs[0], otherPartiallySignedTransaction})
+			if err == nil {
+				t.Fatalf("CombinePartiallySignedTransactions unexpectedly combined different transactions")
+			}
+
+			// Partially signed transactions from other parties are validated in both encodings
+			mismatched := signedPSSTs[0].Clone()
+			mismatched.PartiallySignedInputs = append(mismatched.PartiallySignedInputs,
+				mismatched.PartiallySignedInputs[0].Clone())
+			serializedMismatched, err := serialization.SerializePartiallySignedTransaction(mismatched)
+			if err != nil {
+				t.Fatalf("SerializePartiallySignedTransaction: %+v", err)
+			}
+			_, err = serialization.DeserializePartiallySignedTransaction(serializedMismatched)
+			if err == nil {
+				t.Fatalf("DeserializePartiallySignedTransaction unexpectedly accepted mismatched inputs")
+			}
+			binaryMismatched, err := serialization.SerializePSST(mismatched)
+			if err != nil {
+				t.Fatalf("SerializePSST: %+v", err)
+			}
+			_, err = serialization.DeserializePSST(binaryMismatched)
+			if err == nil {
+				t.Fatalf("DeserializePSST unexpectedly accepted mismatched inputs")
+			}
+			_, err = libsedrawallet.CombinePartiallySignedTransactions(
+				[]*serialization.PartiallySignedTransaction{signedPSSTs[0], mismatched})
+			if err == nil {
+				t.Fatalf("CombinePartiallySignedTransactions unexpectedly combined mismatched inputs")
+			}
+		})
+	})
+}
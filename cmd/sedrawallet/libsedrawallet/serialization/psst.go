@@ -0,0 +1,84 @@
+package serialization
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+)
+
+// PSSTVersion is the version of the PSST format produced by SerializePSST and
+// PSSTToJSON. The format is documented in README.md.
+const PSSTVersion = 1
+
+// psstMagic prefixes every binary PSST, so it can't be confused with the bare
+// protobuf encoding of a partially signed transaction.
+var psstMagic = []byte{'p', 's', 's', 't', 0xff}
+
+// IsPSST returns whether data starts with the magic bytes of a binary PSST.
+func IsPSST(data []byte) bool {
+	return bytes.HasPrefix(data, psstMagic)
+}
+
+// SerializePSST serializes a PartiallySignedTransaction into a binary PSST:
+// the magic bytes, followed by the format version and the protobuf encoding
+// of the transaction.
+func SerializePSST(partiallySignedTransaction *PartiallySignedTransaction) ([]byte, error) {
+	serializedPartiallySignedTransaction, err := SerializePartiallySignedTransaction(partiallySignedTransaction)
+	if err != nil {
+		return nil, err
+	}
+
+	psst := make([]byte, 0, len(psstMagic)+1+len(serializedPartiallySignedTransaction))
+	psst = append(psst, psstMagic...)
+	psst = append(psst, PSSTVersion)
+	return append(psst, serializedPartiallySignedTransaction...), nil
+}
+
+// DeserializePSST deserializes a binary PSST into a PartiallySignedTransaction.
+func DeserializePSST(psst []byte) (*PartiallySignedTransaction, error) {
+	if !IsPSST(psst) {
+		return nil, errors.New("the data is not a PSST: the magic bytes are missing")
+	}
+	if len(psst) == len(psstMagic) {
+		return nil, errors.New("the PSST version is missing")
+	}
+
+	version := psst[len(psstMagic)]
+	if version != PSSTVersion {
+		return nil, errors.Errorf("unsupported PSST version %d", version)
+	}
+
+	return DeserializePartiallySignedTransaction(psst[len(psstMagic)+1:])
+}
+
+// validatePSST checks the consistency of a PSST that was received from
+// another party.
+func validatePSST(partiallySignedTransaction *PartiallySignedTransaction) error {
+	if partiallySignedTransaction.Tx == nil {
+		return errors.New("the PSST has no transaction")
+	}
+	if len(partiallySignedTransaction.PartiallySignedInputs) != len(partiallySignedTransaction.Tx.Inputs) {
+		return errors.Errorf("the PSST has %d partially signed inputs but its transaction has %d inputs",
+			len(partiallySignedTransaction.PartiallySignedInputs), len(partiallySignedTransaction.Tx.Inputs))
+	}
+
+	for i, input := range partiallySignedTransaction.PartiallySignedInputs {
+		if input.PrevOutput == nil || input.PrevOutput.ScriptPublicKey == nil {
+			return errors.Errorf("the UTXO entry of input %d is missing", i)
+		}
+		if len(input.PubKeySignaturePairs) == 0 {
+			return errors.Errorf("input %d has no public keys", i)
+		}
+		if input.MinimumSignatures == 0 || input.MinimumSignatures > uint32(len(input.PubKeySignaturePairs)) {
+			return errors.Errorf("input %d requires %d signatures out of %d public keys",
+				i, input.MinimumSignatures, len(input.PubKeySignaturePairs))
+		}
+		for _, pair := range input.PubKeySignaturePairs {
+			if pair.Signature != nil && len(pair.Signature) < 2 {
+				return errors.Errorf("input %d has a signature of %s that is too short", i, pair.ExtendedPublicKey)
+			}
+		}
+	}
+
+	return nil
+}
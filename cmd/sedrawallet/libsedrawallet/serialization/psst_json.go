@@ -0,0 +1,319 @@
+package serialization
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/subnetworks"
+	"github.com/pkg/errors"
+)
+
+type psstJSON struct {
+	Version     uint32                          `json:"version"`
+	Transaction *psstTransactionJSON            `json:"transaction"`
+	Inputs      []*psstPartiallySignedInputJSON `json:"inputs"`
+}
+
+type psstTransactionJSON struct {
+	Version      uint16                       `json:"version"`
+	Inputs       []*psstTransactionInputJSON  `json:"inputs"`
+	Outputs      []*psstTransactionOutputJSON `json:"outputs"`
+	LockTime     uint64                       `json:"lockTime"`
+	SubnetworkID string                       `json:"subnetworkId"`
+	Gas          uint64                       `json:"gas"`
+	Payload      string                       `json:"payload"`
+}
+
+type psstTransactionInputJSON struct {
+	TransactionID   string `json:"transactionId"`
+	Index           uint32 `json:"index"`
+	SignatureScript string `json:"signatureScript"`
+	Sequence        uint64 `json:"sequence"`
+	SigOpCount      byte   `json:"sigOpCount"`
+}
+
+type psstTransactionOutputJSON struct {
+	Amount          uint64                   `json:"amount"`
+	ScriptPublicKey *psstScriptPublicKeyJSON `json:"scriptPublicKey"`
+}
+
+type psstScriptPublicKeyJSON struct {
+	Version uint16 `json:"version"`
+	Script  string `json:"script"`
+}
+
+type psstUTXOEntryJSON struct {
+	Amount          uint64                   `json:"amount"`
+	ScriptPublicKey *psstScriptPublicKeyJSON `json:"scriptPublicKey"`
+	BlockDAAScore   uint64                   `json:"blockDaaScore"`
+	IsCoinbase      bool                     `json:"isCoinbase"`
+}
+
+type psstPartiallySignedInputJSON struct {
+	UTXOEntry             *psstUTXOEntryJSON   `json:"utxoEntry"`
+	MinimumSignatures     uint32               `json:"minimumSignatures"`
+	DerivationPath        string               `json:"derivationPath"`
+	RedeemScript          string               `json:"redeemScript,omitempty"`
+	RedeemScriptArguments []string             `json:"redeemScriptArguments,omitempty"`
+	Signatures            []*psstSignatureJSON `json:"signatures"`
+}
+
+type psstSignatureJSON struct {
+	ExtendedPublicKey string `json:"extendedPublicKey"`
+	Signature         string `json:"signature,omitempty"`
+	SigHashType       uint8  `json:"sighashType,omitempty"`
+}
+
+// PSSTToJSON encodes a PartiallySignedTransaction in the JSON PSST format.
+func PSSTToJSON(partiallySignedTransaction *PartiallySignedTransaction) ([]byte, error) {
+	tx := partiallySignedTransaction.Tx
+	transactionJSON := &psstTransactionJSON{
+		Version:      tx.Version,
+		Inputs:       make([]*psstTransactionInputJSON, len(tx.Inputs)),
+		Outputs:      make([]*psstTransactionOutputJSON, len(tx.Outputs)),
+		LockTime:     tx.LockTime,
+		SubnetworkID: hex.EncodeToString(tx.SubnetworkID[:]),
+		Gas:          tx.Gas,
+		Payload:      hex.EncodeToString(tx.Payload),
+	}
+	for i, input := range tx.Inputs {
+		transactionJSON.Inputs[i] = &psstTransactionInputJSON{
+			TransactionID:   input.PreviousOutpoint.TransactionID.String(),
+			Index:           input.PreviousOutpoint.Index,
+			SignatureScript: hex.EncodeToString(input.SignatureScript),
+			Sequence:        input.Sequence,
+			SigOpCount:      input.SigOpCount,
+		}
+	}
+	for i, output := range tx.Outputs {
+		transactionJSON.Outputs[i] = &psstTransactionOutputJSON{
+			Amount:          output.Value,
+			ScriptPublicKey: scriptPublicKeyToPSSTJSON(output.ScriptPublicKey),
+		}
+	}
+
+	inputsJSON := make([]*psstPartiallySignedInputJSON, len(partiallySignedTransaction.PartiallySignedInputs))
+	for i, input := range partiallySignedTransaction.PartiallySignedInputs {
+		inputJSON := &psstPartiallySignedInputJSON{
+			UTXOEntry: &psstUTXOEntryJSON{
+				Amount:          input.PrevOutput.Value,
+				ScriptPublicKey: scriptPublicKeyToPSSTJSON(input.PrevOutput.ScriptPublicKey),
+				BlockDAAScore:   input.PrevOutputBlockDAAScore,
+				IsCoinbase:      input.PrevOutputIsCoinbase,
+			},
+			MinimumSignatures: input.MinimumSignatures,
+			DerivationPath:    input.DerivationPath,
+			RedeemScript:      hex.EncodeToString(input.RedeemScript),
+			Signatures:        make([]*psstSignatureJSON, len(input.PubKeySignaturePairs)),
+		}
+		for _, argument := range input.RedeemScriptArguments {
+			inputJSON.RedeemScriptArguments = append(inputJSON.RedeemScriptArguments, hex.EncodeToString(argument))
+		}
+		for j, pair := range input.PubKeySignaturePairs {
+			signatureJSON := &psstSignatureJSON{ExtendedPublicKey: pair.ExtendedPublicKey}
+			if len(pair.Signature) > 0 {
+				signatureJSON.Signature = hex.EncodeToString(pair.Signature)
+				signatureJSON.SigHashType = uint8(SignatureSigHashType(pair.Signature))
+			}
+			inputJSON.Signatures[j] = signatureJSON
+		}
+		inputsJSON[i] = inputJSON
+	}
+
+	return json.MarshalIndent(&psstJSON{
+		Version:     PSSTVersion,
+		Transaction: transactionJSON,
+		Inputs:      inputsJSON,
+	}, "", "  ")
+}
+
+// PSSTFromJSON decodes a PartiallySignedTransaction from the JSON PSST format.
+func PSSTFromJSON(data []byte) (*PartiallySignedTransaction, error) {
+	decoded := &psstJSON{}
+	err := json.Unmarshal(data, decoded)
+	if err != nil {
+		return nil, err
+	}
+
+	if decoded.Version != PSSTVersion {
+		return nil, errors.Errorf("unsupported PSST version %d", decoded.Version)
+	}
+	if decoded.Transaction == nil {
+		return nil, errors.New("the PSST has no transaction")
+	}
+
+	tx, err := decoded.Transaction.toDomainTransaction()
+	if err != nil {
+		return nil, err
+	}
+
+	partiallySignedInputs := make([]*PartiallySignedInput, len(decoded.Inputs))
+	for i, inputJSON := range decoded.Inputs {
+		partiallySignedInputs[i], err = inputJSON.toPartiallySignedInput()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid input %d", i)
+		}
+	}
+
+	partiallySignedTransaction := &PartiallySignedTransaction{
+		Tx:                    tx,
+		PartiallySignedInputs: partiallySignedInputs,
+	}
+	err = validatePSST(partiallySignedTransaction)
+	if err != nil {
+		return nil, err
+	}
+
+	return partiallySignedTransaction, nil
+}
+
+// SignatureSigHashType returns the sighash type of a signature, which is
+// appended to it as its last byte.
+func SignatureSigHashType(signature []byte) consensushashing.SigHashType {
+	if len(signature) == 0 {
+		return 0
+	}
+	return consensushashing.SigHashType(signature[len(signature)-1])
+}
+
+func (transactionJSON *psstTransactionJSON) toDomainTransaction() (*externalapi.DomainTransaction, error) {
+	inputs := make([]*externalapi.DomainTransactionInput, len(transactionJSON.Inputs))
+	for i, inputJSON := range transactionJSON.Inputs {
+		transactionID, err := externalapi.NewDomainTransactionIDFromString(inputJSON.TransactionID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid transaction ID of transaction input %d", i)
+		}
+		signatureScript, err := hex.DecodeString(inputJSON.SignatureScript)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid signature script of transaction input %d", i)
+		}
+
+		inputs[i] = &externalapi.DomainTransactionInput{
+			PreviousOutpoint: externalapi.DomainOutpoint{
+				TransactionID: *transactionID,
+				Index:         inputJSON.Index,
+			},
+			SignatureScript: signatureScript,
+			Sequence:        inputJSON.Sequence,
+			SigOpCount:      inputJSON.SigOpCount,
+		}
+	}
+
+	outputs := make([]*externalapi.DomainTransactionOutput, len(transactionJSON.Outputs))
+	for i, outputJSON := range transactionJSON.Outputs {
+		scriptPublicKey, err := outputJSON.ScriptPublicKey.toScriptPublicKey()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid script public key of transaction output %d", i)
+		}
+
+		outputs[i] = &externalapi.DomainTransactionOutput{
+			Value:           outputJSON.Amount,
+			ScriptPublicKey: scriptPublicKey,
+		}
+	}
+
+	subnetworkIDBytes, err := hex.DecodeString(transactionJSON.SubnetworkID)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid subnetwork ID")
+	}
+	subnetworkID, err := subnetworks.FromBytes(subnetworkIDBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := hex.DecodeString(transactionJSON.Payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid payload")
+	}
+
+	return &externalapi.DomainTransaction{
+		Version:      transactionJSON.Version,
+		Inputs:       inputs,
+		Outputs:      outputs,
+		LockTime:     transactionJSON.LockTime,
+		SubnetworkID: *subnetworkID,
+		Gas:          transactionJSON.Gas,
+		Payload:      payload,
+	}, nil
+}
+
+func (inputJSON *psstPartiallySignedInputJSON) toPartiallySignedInput() (*PartiallySignedInput, error) {
+	if inputJSON.UTXOEntry == nil {
+		return nil, errors.New("the UTXO entry is missing")
+	}
+	scriptPublicKey, err := inputJSON.UTXOEntry.ScriptPublicKey.toScriptPublicKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid script public key of the UTXO entry")
+	}
+
+	redeemScript, err := hex.DecodeString(inputJSON.RedeemScript)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid redeem script")
+	}
+	if len(redeemScript) == 0 {
+		redeemScript = nil
+	}
+
+	var redeemScriptArguments [][]byte
+	for i, argumentHex := range inputJSON.RedeemScriptArguments {
+		argument, err := hex.DecodeString(argumentHex)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid redeem script argument %d", i)
+		}
+		redeemScriptArguments = append(redeemScriptArguments, argument)
+	}
+
+	pubKeySignaturePairs := make([]*PubKeySignaturePair, len(inputJSON.Signatures))
+	for i, signatureJSON := range inputJSON.Signatures {
+		pair := &PubKeySignaturePair{ExtendedPublicKey: signatureJSON.ExtendedPublicKey}
+		if signatureJSON.Signature != "" {
+			pair.Signature, err = hex.DecodeString(signatureJSON.Signature)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid signature of %s", signatureJSON.ExtendedPublicKey)
+			}
+			sigHashType := SignatureSigHashType(pair.Signature)
+			if signatureJSON.SigHashType != 0 && signatureJSON.SigHashType != uint8(sigHashType) {
+				return nil, errors.Errorf("the signature of %s has sighash type %d but is declared as %d",
+					signatureJSON.ExtendedPublicKey, sigHashType, signatureJSON.SigHashType)
+			}
+		}
+		pubKeySignaturePairs[i] = pair
+	}
+
+	return &PartiallySignedInput{
+		PrevOutput: &externalapi.DomainTransactionOutput{
+			Value:           inputJSON.UTXOEntry.Amount,
+			ScriptPublicKey: scriptPublicKey,
+		},
+		MinimumSignatures:       inputJSON.MinimumSignatures,
+		PubKeySignaturePairs:    pubKeySignaturePairs,
+		DerivationPath:          inputJSON.DerivationPath,
+		PrevOutputBlockDAAScore: inputJSON.UTXOEntry.BlockDAAScore,
+		PrevOutputIsCoinbase:    inputJSON.UTXOEntry.IsCoinbase,
+		RedeemScript:            redeemScript,
+		RedeemScriptArguments:   redeemScriptArguments,
+	}, nil
+}
+
+func scriptPublicKeyToPSSTJSON(scriptPublicKey *externalapi.ScriptPublicKey) *psstScriptPublicKeyJSON {
+	return &psstScriptPublicKeyJSON{
+		Version: scriptPublicKey.Version,
+		Script:  hex.EncodeToString(scriptPublicKey.Script),
+	}
+}
+
+func (scriptPublicKeyJSON *psstScriptPublicKeyJSON) toScriptPublicKey() (*externalapi.ScriptPublicKey, error) {
+	if scriptPublicKeyJSON == nil {
+		return nil, errors.New("the script public key is missing")
+	}
+	script, err := hex.DecodeString(scriptPublicKeyJSON.Script)
+	if err != nil {
+		return nil, err
+	}
+	return &externalapi.ScriptPublicKey{
+		Script:  script,
+		Version: scriptPublicKeyJSON.Version,
+	}, nil
+}
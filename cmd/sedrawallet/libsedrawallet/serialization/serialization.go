@@ -26,6 +26,17 @@ type PartiallySignedInput struct {
 	MinimumSignatures    uint32
 	PubKeySignaturePairs []*PubKeySignaturePair
 	DerivationPath       string
+
+	// PrevOutputBlockDAAScore and PrevOutputIsCoinbase complete PrevOutput
+	// to the UTXO entry the input spends. They aren't needed for signing.
+	PrevOutputBlockDAAScore uint64
+	PrevOutputIsCoinbase    bool
+
+	// RedeemScript and RedeemScriptArguments are set for inputs that spend a
+	// contract, such as a timelocked vault. The signature script of such inputs
+	// pushes the signature, then the arguments, then the redeem script.
+	RedeemScript          []byte
+	RedeemScriptArguments [][]byte
 }
 
 // PubKeySignaturePair is a pair of public key and (potentially) its associated signature
@@ -53,6 +64,20 @@ func (psi PartiallySignedInput) Clone() *PartiallySignedInput {
 		MinimumSignatures:    psi.MinimumSignatures,
 		PubKeySignaturePairs: make([]*PubKeySignaturePair, len(psi.PubKeySignaturePairs)),
 		DerivationPath:       psi.DerivationPath,
+
+		PrevOutputBlockDAAScore: psi.PrevOutputBlockDAAScore,
+		PrevOutputIsCoinbase:    psi.PrevOutputIsCoinbase,
+	}
+	if psi.RedeemScript != nil {
+		clone.RedeemScript = make([]byte, len(psi.RedeemScript))
+		copy(clone.RedeemScript, psi.RedeemScript)
+	}
+	if psi.RedeemScriptArguments != nil {
+		clone.RedeemScriptArguments = make([][]byte, len(psi.RedeemScriptArguments))
+		for i, argument := range psi.RedeemScriptArguments {
+			clone.RedeemScriptArguments[i] = make([]byte, len(argument))
+			copy(clone.RedeemScriptArguments[i], argument)
+		}
 	}
 	for i, pubKeySignaturePair := range psi.PubKeySignaturePairs {
 		clone.PubKeySignaturePairs[i] = pubKeySignaturePair.Clone()
@@ -73,7 +98,13 @@ func (psp PubKeySignaturePair) Clone() *PubKeySignaturePair {
 }
 
 // DeserializePartiallySignedTransaction deserializes a byte slice into PartiallySignedTransaction.
+// It accepts both a binary PSST and the bare protobuf encoding produced by
+// SerializePartiallySignedTransaction.
 func DeserializePartiallySignedTransaction(serializedPartiallySignedTransaction []byte) (*PartiallySignedTransaction, error) {
+	if IsPSST(serializedPartiallySignedTransaction) {
+		return DeserializePSST(serializedPartiallySignedTransaction)
+	}
+
 	protoPartiallySignedTransaction := &protoserialization.PartiallySignedTransaction{}
 	err := proto.Unmarshal(serializedPartiallySignedTransaction, protoPartiallySignedTransaction)
 	if err != nil {
@@ -105,6 +136,9 @@ func SerializeDomainTransaction(tx *externalapi.DomainTransaction) ([]byte, erro
 }
 
 func partiallySignedTransactionFromProto(protoPartiallySignedTransaction *protoserialization.PartiallySignedTransaction) (*PartiallySignedTransaction, error) {
+	if protoPartiallySignedTransaction.Tx == nil {
+		return nil, errors.Errorf("protoPartiallySignedTransaction.Tx is nil")
+	}
 	tx, err := transactionFromProto(protoPartiallySignedTransaction.Tx)
 	if err != nil {
 		return nil, err
@@ -118,10 +152,17 @@ func partiallySignedTransactionFromProto(protoPartiallySignedTransaction *protos
 		}
 	}
 
-	return &PartiallySignedTransaction{
+	partiallySignedTransaction := &PartiallySignedTransaction{
 		Tx:                    tx,
 		PartiallySignedInputs: inputs,
-	}, nil
+	}
+	// Both the binary PSST and the bare protobuf encodings are received from other parties
+	err = validatePSST(partiallySignedTransaction)
+	if err != nil {
+		return nil, err
+	}
+
+	return partiallySignedTransaction, nil
 }
 
 func partiallySignedTransactionToProto(partiallySignedTransaction *PartiallySignedTransaction) *protoserialization.PartiallySignedTransaction {
@@ -137,6 +178,9 @@ func partiallySignedTransactionToProto(partiallySignedTransaction *PartiallySign
 }
 
 func partiallySignedInputFromProto(protoPartiallySignedInput *protoserialization.PartiallySignedInput) (*PartiallySignedInput, error) {
+	if protoPartiallySignedInput.PrevOutput == nil {
+		return nil, errors.Errorf("protoPartiallySignedInput.PrevOutput is nil")
+	}
 	output, err := transactionOutputFromProto(protoPartiallySignedInput.PrevOutput)
 	if err != nil {
 		return nil, err
@@ -148,10 +192,15 @@ func partiallySignedInputFromProto(protoPartiallySignedInput *protoserialization
 	}
 
 	return &PartiallySignedInput{
-		PrevOutput:           output,
-		MinimumSignatures:    protoPartiallySignedInput.MinimumSignatures,
-		PubKeySignaturePairs: pubKeySignaturePairs,
-		DerivationPath:       protoPartiallySignedInput.DerivationPath,
+		PrevOutput:            output,
+		MinimumSignatures:     protoPartiallySignedInput.MinimumSignatures,
+		PubKeySignaturePairs:  pubKeySignaturePairs,
+		DerivationPath:        protoPartiallySignedInput.DerivationPath,
+		RedeemScript:          protoPartiallySignedInput.RedeemScript,
+		RedeemScriptArguments: protoPartiallySignedInput.RedeemScriptArguments,
+
+		PrevOutputBlockDAAScore: protoPartiallySignedInput.PrevOutputBlockDaaScore,
+		PrevOutputIsCoinbase:    protoPartiallySignedInput.PrevOutputIsCoinbase,
 	}, nil
 }
 
@@ -162,10 +211,15 @@ func partiallySignedInputToProto(partiallySignedInput *PartiallySignedInput) *pr
 	}
 
 	return &protoserialization.PartiallySignedInput{
-		PrevOutput:           transactionOutputToProto(partiallySignedInput.PrevOutput),
-		MinimumSignatures:    partiallySignedInput.MinimumSignatures,
-		PubKeySignaturePairs: protoPairs,
-		DerivationPath:       partiallySignedInput.DerivationPath,
+		PrevOutput:            transactionOutputToProto(partiallySignedInput.PrevOutput),
+		MinimumSignatures:     partiallySignedInput.MinimumSignatures,
+		PubKeySignaturePairs:  protoPairs,
+		DerivationPath:        partiallySignedInput.DerivationPath,
+		RedeemScript:          partiallySignedInput.RedeemScript,
+		RedeemScriptArguments: partiallySignedInput.RedeemScriptArguments,
+
+		PrevOutputBlockDaaScore: partiallySignedInput.PrevOutputBlockDAAScore,
+		PrevOutputIsCoinbase:    partiallySignedInput.PrevOutputIsCoinbase,
 	}
 }
 
@@ -206,6 +260,9 @@ func transactionFromProto(protoTransaction *protoserialization.TransactionMessag
 		}
 	}
 
+	if protoTransaction.SubnetworkId == nil {
+		return nil, errors.Errorf("protoTransaction.SubnetworkId is nil")
+	}
 	subnetworkID, err := subnetworks.FromBytes(protoTransaction.SubnetworkId.Bytes)
 	if err != nil {
 		return nil, err
@@ -272,6 +329,9 @@ func transactionInputToProto(input *externalapi.DomainTransactionInput) *protose
 }
 
 func outpointFromProto(protoOutpoint *protoserialization.Outpoint) (*externalapi.DomainOutpoint, error) {
+	if protoOutpoint == nil {
+		return nil, errors.Errorf("protoOutpoint is nil")
+	}
 	txID, err := transactionIDFromProto(protoOutpoint.TransactionId)
 	if err != nil {
 		return nil, err
@@ -317,6 +377,9 @@ func transactionOutputToProto(output *externalapi.DomainTransactionOutput) *prot
 }
 
 func scriptPublicKeyFromProto(protoScriptPublicKey *protoserialization.ScriptPublicKey) (*externalapi.ScriptPublicKey, error) {
+	if protoScriptPublicKey == nil {
+		return nil, errors.Errorf("protoScriptPublicKey is nil")
+	}
 	if protoScriptPublicKey.Version > math.MaxUint16 {
 		return nil, errors.Errorf("protoOutput.ScriptPublicKey.Version is %d and is too big to be a uint16", protoScriptPublicKey.Version)
 	}
@@ -27,15 +27,27 @@ func rawTxInSignature(extendedKey *bip32.ExtendedKey, tx *externalapi.DomainTran
 	return txscript.RawTxInSignature(tx, idx, hashType, schnorrKeyPair, sighashReusedValues)
 }
 
-// Sign signs the transaction with the given private keys
+// Sign signs the transaction with the given private keys.
+// It uses no passphrase and the first account of the wallet.
 func Sign(params *dagconfig.Params, mnemonics []string, serializedPSTx []byte, ecdsa bool) ([]byte, error) {
+	accountMnemonics := make([]*Mnemonic, len(mnemonics))
+	for i, mnemonic := range mnemonics {
+		accountMnemonics[i] = &Mnemonic{Phrase: mnemonic}
+	}
+	return SignWithAccount(params, accountMnemonics, 0, serializedPSTx, ecdsa)
+}
+
+// SignWithAccount signs the transaction with the private keys of the given account of the given mnemonics
+func SignWithAccount(params *dagconfig.Params, mnemonics []*Mnemonic, account uint32, serializedPSTx []byte,
+	ecdsa bool) ([]byte, error) {
+
 	partiallySignedTransaction, err := serialization.DeserializePartiallySignedTransaction(serializedPSTx)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, mnemonic := range mnemonics {
-		err = sign(params, mnemonic, partiallySignedTransaction, ecdsa)
+		err = sign(params, mnemonic, account, partiallySignedTransaction, ecdsa)
 		if err != nil {
 			return nil, err
 		}
@@ -43,27 +55,18 @@ func Sign(params *dagconfig.Params, mnemonics []string, serializedPSTx []byte, e
 	return serialization.SerializePartiallySignedTransaction(partiallySignedTransaction)
 }
 
-func sign(params *dagconfig.Params, mnemonic string, partiallySignedTransaction *serialization.PartiallySignedTransaction, ecdsa bool) error {
+func sign(params *dagconfig.Params, mnemonic *Mnemonic, account uint32, partiallySignedTransaction *serialization.PartiallySignedTransaction, ecdsa bool) error {
 	if isTransactionFullySigned(partiallySignedTransaction) {
 		return nil
 	}
 
 	sighashReusedValues := &consensushashing.SighashReusedValues{}
-	for i, partiallySignedInput := range partiallySignedTransaction.PartiallySignedInputs {
-		prevOut := partiallySignedInput.PrevOutput
-		partiallySignedTransaction.Tx.Inputs[i].UTXOEntry = utxo.NewUTXOEntry(
-			prevOut.Value,
-			prevOut.ScriptPublicKey,
-			false, // This is a fake value, because it's irrelevant for the signature
-			0,     // This is a fake value, because it's irrelevant for the signature
-		)
-		partiallySignedTransaction.Tx.Inputs[i].SigOpCount = byte(len(partiallySignedInput.PubKeySignaturePairs))
-	}
+	populateSigningFields(partiallySignedTransaction)
 
 	signed := false
 	for i, partiallySignedInput := range partiallySignedTransaction.PartiallySignedInputs {
 		isMultisig := len(partiallySignedInput.PubKeySignaturePairs) > 1
-		path := defaultPath(isMultisig)
+		path := defaultPath(isMultisig, account)
 		extendedKey, err := extendedKeyFromMnemonicAndPath(mnemonic, path, params)
 		if err != nil {
 			return err
@@ -97,3 +100,19 @@ func sign(params *dagconfig.Params, mnemonic string, partiallySignedTransaction
 
 	return nil
 }
+
+// populateSigningFields sets the fields of the inputs of the transaction that
+// its signature hashes commit to: the spent UTXO entries and the signature
+// operation counts.
+func populateSigningFields(partiallySignedTransaction *serialization.PartiallySignedTransaction) {
+	for i, partiallySignedInput := range partiallySignedTransaction.PartiallySignedInputs {
+		prevOut := partiallySignedInput.PrevOutput
+		partiallySignedTransaction.Tx.Inputs[i].UTXOEntry = utxo.NewUTXOEntry(
+			prevOut.Value,
+			prevOut.ScriptPublicKey,
+			partiallySignedInput.PrevOutputIsCoinbase,
+			partiallySignedInput.PrevOutputBlockDAAScore,
+		)
+		partiallySignedTransaction.Tx.Inputs[i].SigOpCount = PartiallySignedInputSigOpCount(partiallySignedInput)
+	}
+}
@@ -46,41 +46,11 @@ func multiSigRedeemScript(extendedPublicKeys []string, minimumSignatures uint32,
 	scriptBuilder := txscript.NewScriptBuilder()
 	scriptBuilder.AddInt64(int64(minimumSignatures))
 	for _, key := range extendedPublicKeys {
-		extendedKey, err := bip32.DeserializeExtendedKey(key)
+		serializedPublicKey, err := derivedPublicKey(key, path, ecdsa)
 		if err != nil {
 			return nil, err
 		}
 
-		derivedKey, err := extendedKey.DeriveFromPath(path)
-		if err != nil {
-			return nil, err
-		}
-
-		publicKey, err := derivedKey.PublicKey()
-		if err != nil {
-			return nil, err
-		}
-
-		var serializedPublicKey []byte
-		if ecdsa {
-			serializedECDSAPublicKey, err := publicKey.Serialize()
-			if err != nil {
-				return nil, err
-			}
-			serializedPublicKey = serializedECDSAPublicKey[:]
-		} else {
-			schnorrPublicKey, err := publicKey.ToSchnorr()
-			if err != nil {
-				return nil, err
-			}
-
-			serializedSchnorrPublicKey, err := schnorrPublicKey.Serialize()
-			if err != nil {
-				return nil, err
-			}
-			serializedPublicKey = serializedSchnorrPublicKey[:]
-		}
-
 		scriptBuilder.AddData(serializedPublicKey)
 	}
 	scriptBuilder.AddInt64(int64(len(extendedPublicKeys)))
@@ -129,6 +99,9 @@ func createUnsignedTransaction(
 			MinimumSignatures:    minimumSignatures,
 			PubKeySignaturePairs: emptyPubKeySignaturePairs,
 			DerivationPath:       utxo.DerivationPath,
+
+			PrevOutputBlockDAAScore: utxo.UTXOEntry.BlockDAAScore(),
+			PrevOutputIsCoinbase:    utxo.UTXOEntry.IsCoinbase(),
 		}
 	}
 
@@ -172,6 +145,12 @@ func IsTransactionFullySigned(partiallySignedTransactionBytes []byte) (bool, err
 	return isTransactionFullySigned(partiallySignedTransaction), nil
 }
 
+// IsPartiallySignedTransactionFullySigned is the same as IsTransactionFullySigned
+// for a deserialized partially signed transaction.
+func IsPartiallySignedTransactionFullySigned(partiallySignedTransaction *serialization.PartiallySignedTransaction) bool {
+	return isTransactionFullySigned(partiallySignedTransaction)
+}
+
 func isTransactionFullySigned(partiallySignedTransaction *serialization.PartiallySignedTransaction) bool {
 	for _, input := range partiallySignedTransaction.PartiallySignedInputs {
 		numSignatures := 0
@@ -204,6 +183,15 @@ func ExtractTransactionDeserialized(partiallySignedTransaction *serialization.Pa
 	*externalapi.DomainTransaction, error) {
 
 	for i, input := range partiallySignedTransaction.PartiallySignedInputs {
+		if input.RedeemScript != nil {
+			sigScript, err := contractSignatureScript(input)
+			if err != nil {
+				return nil, err
+			}
+			partiallySignedTransaction.Tx.Inputs[i].SignatureScript = sigScript
+			continue
+		}
+
 		isMultisig := len(input.PubKeySignaturePairs) > 1
 		scriptBuilder := txscript.NewScriptBuilder()
 		if isMultisig {
@@ -251,6 +239,15 @@ func ExtractTransactionDeserialized(partiallySignedTransaction *serialization.Pa
 	return partiallySignedTransaction.Tx, nil
 }
 
+// PartiallySignedInputSigOpCount returns the number of signature operations the
+// signature script of the given input is expected to have.
+func PartiallySignedInputSigOpCount(input *serialization.PartiallySignedInput) byte {
+	if input.RedeemScript != nil {
+		return byte(txscript.GetSigOpCount(input.RedeemScript))
+	}
+	return byte(len(input.PubKeySignaturePairs))
+}
+
 func partiallySignedInputMultisigRedeemScript(input *serialization.PartiallySignedInput, ecdsa bool) ([]byte, error) {
 	extendedPublicKeys := make([]string, len(input.PubKeySignaturePairs))
 	for i, pair := range input.PubKeySignaturePairs {
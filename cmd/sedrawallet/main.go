@@ -31,6 +31,44 @@ func main() {
 		err = startDaemon(config.(*startDaemonConfig))
 	case sweepSubCmd:
 		err = sweep(config.(*sweepConfig))
+	case newVaultSubCmd:
+		err = newVault(config.(*newVaultConfig))
+	case newHTLCSubCmd:
+		err = newHTLC(config.(*newHTLCConfig))
+	case importContractSubCmd:
+		err = importContract(config.(*importContractConfig))
+	case showContractsSubCmd:
+		err = showContracts(config.(*showContractsConfig))
+	case spendContractSubCmd:
+		err = spendContract(config.(*spendContractConfig))
+	case signMessageSubCmd:
+		err = signMessage(config.(*signMessageConfig))
+	case verifyMessageSubCmd:
+		err = verifyMessage(config.(*verifyMessageConfig))
+	case addAccountSubCmd:
+		err = addAccount(config.(*addAccountConfig))
+	case changePasswordSubCmd:
+		err = changePassword(config.(*changePasswordConfig))
+	case combineSubCmd:
+		err = combine(config.(*combineConfig))
+	case finalizeSubCmd:
+		err = finalize(config.(*finalizeConfig))
+	case inspectSubCmd:
+		err = inspect(config.(*inspectConfig))
+	case rescanSubCmd:
+		err = rescan(config.(*rescanConfig))
+	case proposeSubCmd:
+		err = propose(config.(*proposeConfig))
+	case showProposalsSubCmd:
+		err = showProposals(config.(*showProposalsConfig))
+	case signProposalSubCmd:
+		err = signProposal(config.(*signProposalConfig))
+	case cancelProposalSubCmd:
+		err = cancelProposal(config.(*cancelProposalConfig))
+	case createInvoiceSubCmd:
+		err = createInvoice(config.(*createInvoiceConfig))
+	case showInvoicesSubCmd:
+		err = showInvoices(config.(*showInvoicesConfig))
 	default:
 		err = errors.Errorf("Unknown sub-command '%s'\n", subCmd)
 	}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/client"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/pb"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/keys"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
+	"github.com/pkg/errors"
+)
+
+func propose(conf *proposeConfig) error {
+	input, err := readTransactionsInput(conf.Transaction, conf.TransactionFile)
+	if err != nil {
+		return err
+	}
+
+	transactions, err := decodePartiallySignedTransactions(input)
+	if err != nil {
+		return err
+	}
+
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	response, err := daemonClient.CreateMultisigProposal(ctx, &pb.CreateMultisigProposalRequest{
+		Transactions: transactions,
+		Description:  conf.Description,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created proposal %s. The other cosigners can sign it with `sedrawallet %s --id %s`\n\n",
+		response.Proposal.Id, signProposalSubCmd, response.Proposal.Id)
+	printMultisigProposal(response.Proposal)
+	return nil
+}
+
+func showProposals(conf *showProposalsConfig) error {
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	response, err := daemonClient.GetMultisigProposals(ctx, &pb.GetMultisigProposalsRequest{Id: conf.ID})
+	if err != nil {
+		return err
+	}
+
+	if len(response.Proposals) == 0 {
+		fmt.Println("There are no proposals")
+		return nil
+	}
+	for _, proposal := range response.Proposals {
+		printMultisigProposal(proposal)
+	}
+	return nil
+}
+
+func signProposal(conf *signProposalConfig) error {
+	keysFile, err := keys.ReadKeysFile(conf.NetParams(), conf.KeysFile)
+	if err != nil {
+		return err
+	}
+
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	getResponse, err := daemonClient.GetMultisigProposals(ctx, &pb.GetMultisigProposalsRequest{Id: conf.ID})
+	if err != nil {
+		return err
+	}
+	proposal := getResponse.Proposals[0]
+	if proposal.Status != "pending" {
+		return errors.Errorf("Proposal %s is already %s", proposal.Id, proposal.Status)
+	}
+
+	if len(conf.Password) == 0 {
+		conf.Password = keys.GetPassword("Password:")
+	}
+	mnemonics, err := keysFile.DecryptMnemonics(conf.Password)
+	if err != nil {
+		return err
+	}
+
+	// The transactions are signed locally, so that the private keys never
+	// reach the daemon, which may belong to another cosigner
+	signedTransactions := make([][]byte, len(proposal.Transactions))
+	for i, transaction := range proposal.Transactions {
+		signedTransactions[i], err = libsedrawallet.SignWithAccount(conf.NetParams(), mnemonics, conf.Account,
+			transaction, keysFile.ECDSA)
+		if err != nil {
+			return err
+		}
+	}
+
+	signResponse, err := daemonClient.SignMultisigProposal(ctx, &pb.SignMultisigProposalRequest{
+		Id:                 conf.ID,
+		SignedTransactions: signedTransactions,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Signed proposal %s\n\n", conf.ID)
+	printMultisigProposal(signResponse.Proposal)
+	return nil
+}
+
+func cancelProposal(conf *cancelProposalConfig) error {
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	_, err = daemonClient.CancelMultisigProposal(ctx, &pb.CancelMultisigProposalRequest{Id: conf.ID})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Cancelled proposal %s\n", conf.ID)
+	return nil
+}
+
+func printMultisigProposal(proposal *pb.MultisigProposal) {
+	fmt.Printf("Proposal %s\n", proposal.Id)
+	if proposal.Description != "" {
+		fmt.Printf("\tDescription: %s\n", proposal.Description)
+	}
+	fmt.Printf("\tCreated at: %s\n", time.UnixMilli(proposal.CreatedAt).Format(time.RFC3339))
+	fmt.Printf("\tTransactions: %d\n", len(proposal.Transactions))
+	fmt.Printf("\tStatus: %s\n", proposal.Status)
+
+	numSigned := uint32(0)
+	for _, cosigner := range proposal.Cosigners {
+		if cosigner.TotalInputs > 0 && cosigner.SignedInputs == cosigner.TotalInputs {
+			numSigned++
+		}
+		fmt.Printf("\tCosigner %d: signed %d of %d inputs\n",
+			cosigner.CosignerIndex, cosigner.SignedInputs, cosigner.TotalInputs)
+	}
+	if proposal.Status == "pending" {
+		fmt.Printf("\t%d of the %d required cosigners signed\n", numSigned, proposal.MinimumSignatures)
+	}
+
+	for _, txID := range proposal.TxIDs {
+		fmt.Printf("\tBroadcast transaction: %s\n", txID)
+	}
+	if proposal.BroadcastError != "" {
+		fmt.Printf("\tBroadcast failed: %s. It's attempted again when the proposal is signed\n",
+			proposal.BroadcastError)
+	}
+	fmt.Println()
+}
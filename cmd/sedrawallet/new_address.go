@@ -17,7 +17,7 @@ func newAddress(conf *newAddressConfig) error {
 	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
 	defer cancel()
 
-	response, err := daemonClient.NewAddress(ctx, &pb.NewAddressRequest{})
+	response, err := daemonClient.NewAddress(ctx, &pb.NewAddressRequest{Account: conf.Account})
 	if err != nil {
 		return err
 	}
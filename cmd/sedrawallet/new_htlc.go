@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/client"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/pb"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
+)
+
+func newHTLC(conf *newHTLCConfig) error {
+	var secret []byte
+	secretHash := conf.SecretHash
+	if secretHash == "" {
+		secret = make([]byte, txscript.HashTimeLockedContractSecretSize)
+		_, err := rand.Read(secret)
+		if err != nil {
+			return err
+		}
+		hash := sha256.Sum256(secret)
+		secretHash = hex.EncodeToString(hash[:])
+	}
+
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	response, err := daemonClient.NewHashTimeLockedContract(ctx, &pb.NewHashTimeLockedContractRequest{
+		RecipientAddress:   conf.RecipientAddress,
+		SecretHash:         secretHash,
+		LockTime:           conf.LockTime,
+		IsRelativeLockTime: conf.IsRelativeLockTime,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Contract address:\n%s\n", response.Address)
+	fmt.Printf("Redeem script (needed by the recipient in order to import the contract):\n%s\n", response.RedeemScript)
+	fmt.Printf("Secret hash:\n%s\n", secretHash)
+	if secret != nil {
+		fmt.Printf("Secret (keep it private until you claim the counterparty's contract):\n%x\n", secret)
+	}
+	return nil
+}
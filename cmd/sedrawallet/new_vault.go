@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/client"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/pb"
+)
+
+func newVault(conf *newVaultConfig) error {
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	response, err := daemonClient.NewTimeLockedVault(ctx, &pb.NewTimeLockedVaultRequest{
+		RecoveryAddress:    conf.RecoveryAddress,
+		LockTime:           conf.LockTime,
+		IsRelativeLockTime: conf.IsRelativeLockTime,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Vault address:\n%s\n", response.Address)
+	fmt.Printf("Redeem script (needed by the owner of the recovery key in order to import the vault):\n%s\n",
+		response.RedeemScript)
+	return nil
+}
@@ -29,7 +29,7 @@ func parse(conf *parseConfig) error {
 		transactionHex = strings.TrimSpace(string(transactionHexBytes))
 	}
 
-	transactions, err := decodeTransactionsFromHex(transactionHex)
+	transactions, err := decodePartiallySignedTransactions(transactionHex)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet/serialization"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/constants"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
+	"github.com/sedracoin/sedrad/domain/dagconfig"
+	"github.com/pkg/errors"
+)
+
+func combine(conf *combineConfig) error {
+	inputs := append([]string(nil), conf.Transactions...)
+	for _, transactionFile := range conf.TransactionFiles {
+		transactionBytes, err := ioutil.ReadFile(transactionFile)
+		if err != nil {
+			return errors.Wrapf(err, "Could not read the transactions from %s", transactionFile)
+		}
+		inputs = append(inputs, string(transactionBytes))
+	}
+	if len(inputs) < 2 {
+		return errors.Errorf("At least two transactions are required, given with --transaction or --transaction-file")
+	}
+
+	// Each input may hold several transactions, in which case the transactions
+	// at the same position in every input are combined
+	var inputsTransactions [][]*serialization.PartiallySignedTransaction
+	for i, input := range inputs {
+		transactions, err := deserializePartiallySignedTransactions(input)
+		if err != nil {
+			return errors.Wrapf(err, "invalid transaction #%d", i+1)
+		}
+		if len(inputsTransactions) > 0 && len(transactions) != len(inputsTransactions[0]) {
+			return errors.Errorf("transaction #%d holds %d transactions while transaction #1 holds %d",
+				i+1, len(transactions), len(inputsTransactions[0]))
+		}
+		inputsTransactions = append(inputsTransactions, transactions)
+	}
+
+	combinedTransactions := make([]*serialization.PartiallySignedTransaction, len(inputsTransactions[0]))
+	for i := range combinedTransactions {
+		transactionsToCombine := make([]*serialization.PartiallySignedTransaction, len(inputsTransactions))
+		for j, transactions := range inputsTransactions {
+			transactionsToCombine[j] = transactions[i]
+		}
+
+		var err error
+		combinedTransactions[i], err = libsedrawallet.CombinePartiallySignedTransactions(transactionsToCombine)
+		if err != nil {
+			return err
+		}
+	}
+
+	encoded, err := encodePartiallySignedTransactions(combinedTransactions, conf.JSON)
+	if err != nil {
+		return err
+	}
+	fmt.Println(encoded)
+	return nil
+}
+
+func finalize(conf *finalizeConfig) error {
+	input, err := readTransactionsInput(conf.Transaction, conf.TransactionFile)
+	if err != nil {
+		return err
+	}
+
+	partiallySignedTransactions, err := deserializePartiallySignedTransactions(input)
+	if err != nil {
+		return err
+	}
+
+	finalizedTransactions := make([][]byte, len(partiallySignedTransactions))
+	for i, partiallySignedTransaction := range partiallySignedTransactions {
+		tx, err := libsedrawallet.FinalizeTransaction(partiallySignedTransaction)
+		if err != nil {
+			return errors.Wrapf(err, "Could not finalize transaction #%d", i+1)
+		}
+
+		finalizedTransactions[i], err = serialization.SerializeDomainTransaction(tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "The transactions are finalized. Broadcast them with `sedrawallet broadcast --finalized`")
+	fmt.Println(encodeTransactionsToHex(finalizedTransactions))
+	return nil
+}
+
+func inspect(conf *inspectConfig) error {
+	input, err := readTransactionsInput(conf.Transaction, conf.TransactionFile)
+	if err != nil {
+		return err
+	}
+
+	partiallySignedTransactions, err := deserializePartiallySignedTransactions(input)
+	if err != nil {
+		return err
+	}
+
+	if conf.JSON {
+		encoded, err := encodePartiallySignedTransactions(partiallySignedTransactions, true)
+		if err != nil {
+			return err
+		}
+		fmt.Println(encoded)
+		return nil
+	}
+
+	for i, partiallySignedTransaction := range partiallySignedTransactions {
+		err := printPartiallySignedTransaction(conf.NetParams(), i, partiallySignedTransaction)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printPartiallySignedTransaction(params *dagconfig.Params, index int,
+	partiallySignedTransaction *serialization.PartiallySignedTransaction) error {
+
+	fmt.Printf("Transaction #%d ID: \t%s\n\n", index+1, consensushashing.TransactionID(partiallySignedTransaction.Tx))
+
+	isFullySigned := true
+	totalInputAmount := uint64(0)
+	for i, input := range partiallySignedTransaction.Tx.Inputs {
+		partiallySignedInput := partiallySignedTransaction.PartiallySignedInputs[i]
+		totalInputAmount += partiallySignedInput.PrevOutput.Value
+
+		numSignatures := uint32(0)
+		for _, pair := range partiallySignedInput.PubKeySignaturePairs {
+			if pair.Signature != nil {
+				numSignatures++
+			}
+		}
+		if numSignatures < partiallySignedInput.MinimumSignatures {
+			isFullySigned = false
+		}
+
+		fmt.Printf("Input %d: \tOutpoint: %s:%d \tAmount: %.2f sedra \tSignatures: %d of %d\n", i,
+			input.PreviousOutpoint.TransactionID, input.PreviousOutpoint.Index,
+			float64(partiallySignedInput.PrevOutput.Value)/float64(constants.SeepPerSedra),
+			numSignatures, partiallySignedInput.MinimumSignatures)
+		if partiallySignedInput.RedeemScript != nil {
+			fmt.Printf("\tRedeem script: %s\n", hex.EncodeToString(partiallySignedInput.RedeemScript))
+		}
+		for _, pair := range partiallySignedInput.PubKeySignaturePairs {
+			status := "not signed"
+			if pair.Signature != nil {
+				status = fmt.Sprintf("signed (%s)", sigHashTypeString(serialization.SignatureSigHashType(pair.Signature)))
+			}
+			fmt.Printf("\t%s: %s\n", pair.ExtendedPublicKey, status)
+		}
+	}
+	fmt.Println()
+
+	totalOutputAmount := uint64(0)
+	for i, output := range partiallySignedTransaction.Tx.Outputs {
+		totalOutputAmount += output.Value
+
+		recipient := fmt.Sprintf("<Non-standard transaction script public key: %s>",
+			hex.EncodeToString(output.ScriptPublicKey.Script))
+		scriptPublicKeyType, address, err := txscript.ExtractScriptPubKeyAddress(output.ScriptPublicKey, params)
+		if err == nil && scriptPublicKeyType != txscript.NonStandardTy {
+			recipient = address.EncodeAddress()
+		}
+
+		fmt.Printf("Output %d: \tRecipient: %s \tAmount: %.2f sedra\n",
+			i, recipient, float64(output.Value)/float64(constants.SeepPerSedra))
+	}
+	fmt.Println()
+
+	if totalInputAmount >= totalOutputAmount {
+		fmt.Printf("Fee:\t%d Seep\n", totalInputAmount-totalOutputAmount)
+	} else {
+		fmt.Printf("The outputs spend %d Seep more than the inputs\n", totalOutputAmount-totalInputAmount)
+	}
+	if isFullySigned {
+		fmt.Printf("The transaction is fully signed\n\n")
+	} else {
+		fmt.Printf("The transaction is missing signatures\n\n")
+	}
+	return nil
+}
+
+func sigHashTypeString(sigHashType consensushashing.SigHashType) string {
+	var name string
+	switch sigHashType &^ consensushashing.SigHashAnyOneCanPay {
+	case consensushashing.SigHashAll:
+		name = "SIGHASH_ALL"
+	case consensushashing.SigHashNone:
+		name = "SIGHASH_NONE"
+	case consensushashing.SigHashSingle:
+		name = "SIGHASH_SINGLE"
+	default:
+		return fmt.Sprintf("unknown sighash type %d", sigHashType)
+	}
+	if sigHashType&consensushashing.SigHashAnyOneCanPay != 0 {
+		name += "|SIGHASH_ANYONECANPAY"
+	}
+	return name
+}
+
+func readTransactionsInput(transaction, transactionFile string) (string, error) {
+	if transaction == "" && transactionFile == "" {
+		return "", errors.Errorf("Either --transaction or --transaction-file is required")
+	}
+	if transaction != "" && transactionFile != "" {
+		return "", errors.Errorf("Both --transaction and --transaction-file cannot be passed at the same time")
+	}
+
+	if transactionFile == "" {
+		return transaction, nil
+	}
+	transactionBytes, err := ioutil.ReadFile(transactionFile)
+	if err != nil {
+		return "", errors.Wrapf(err, "Could not read the transactions from %s", transactionFile)
+	}
+	return strings.TrimSpace(string(transactionBytes)), nil
+}
+
+func deserializePartiallySignedTransactions(input string) ([]*serialization.PartiallySignedTransaction, error) {
+	serializedTransactions, err := decodePartiallySignedTransactions(input)
+	if err != nil {
+		return nil, err
+	}
+
+	partiallySignedTransactions := make([]*serialization.PartiallySignedTransaction, len(serializedTransactions))
+	for i, serializedTransaction := range serializedTransactions {
+		partiallySignedTransactions[i], err = serialization.DeserializePartiallySignedTransaction(serializedTransaction)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return partiallySignedTransactions, nil
+}
+
+// encodePartiallySignedTransactions encodes the given transactions as binary
+// PSSTs in hex, or, if asJSON is true, as JSON PSSTs.
+func encodePartiallySignedTransactions(partiallySignedTransactions []*serialization.PartiallySignedTransaction,
+	asJSON bool) (string, error) {
+
+	if !asJSON {
+		serializedTransactions := make([][]byte, len(partiallySignedTransactions))
+		for i, partiallySignedTransaction := range partiallySignedTransactions {
+			var err error
+			serializedTransactions[i], err = serialization.SerializePSST(partiallySignedTransaction)
+			if err != nil {
+				return "", err
+			}
+		}
+		return encodeTransactionsToHex(serializedTransactions), nil
+	}
+
+	psstsJSON := make([]json.RawMessage, len(partiallySignedTransactions))
+	for i, partiallySignedTransaction := range partiallySignedTransactions {
+		psstJSON, err := serialization.PSSTToJSON(partiallySignedTransaction)
+		if err != nil {
+			return "", err
+		}
+		psstsJSON[i] = psstJSON
+	}
+	if len(psstsJSON) == 1 {
+		return string(psstsJSON[0]), nil
+	}
+
+	encoded, err := json.MarshalIndent(psstsJSON, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
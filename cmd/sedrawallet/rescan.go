@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/client"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/pb"
+)
+
+func rescan(conf *rescanConfig) error {
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	_, err = daemonClient.Rescan(ctx, &pb.RescanRequest{FromDAAScore: conf.FromDAAScore})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Rescan started. The wallet daemon log shows its progress")
+	return nil
+}
@@ -49,6 +49,7 @@ func send(conf *sendConfig) error {
 			Amount:                   sendAmountSeep,
 			IsSendAll:                conf.IsSendAll,
 			UseExistingChangeAddress: conf.UseExistingChangeAddress,
+			Account:                  conf.Account,
 		})
 	if err != nil {
 		return err
@@ -68,7 +69,8 @@ func send(conf *sendConfig) error {
 
 	signedTransactions := make([][]byte, len(createUnsignedTransactionsResponse.UnsignedTransactions))
 	for i, unsignedTransaction := range createUnsignedTransactionsResponse.UnsignedTransactions {
-		signedTransaction, err := libsedrawallet.Sign(conf.NetParams(), mnemonics, unsignedTransaction, keysFile.ECDSA)
+		signedTransaction, err := libsedrawallet.SignWithAccount(conf.NetParams(), mnemonics, conf.Account,
+			unsignedTransaction, keysFile.ECDSA)
 		if err != nil {
 			return err
 		}
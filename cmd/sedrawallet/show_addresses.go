@@ -17,7 +17,7 @@ func showAddresses(conf *showAddressesConfig) error {
 	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
 	defer cancel()
 
-	response, err := daemonClient.ShowAddresses(ctx, &pb.ShowAddressesRequest{})
+	response, err := daemonClient.ShowAddresses(ctx, &pb.ShowAddressesRequest{Account: conf.Account})
 	if err != nil {
 		return err
 	}
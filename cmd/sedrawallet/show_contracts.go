@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/client"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/pb"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/utils"
+)
+
+func showContracts(conf *showContractsConfig) error {
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	response, err := daemonClient.ShowContracts(ctx, &pb.ShowContractsRequest{})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Contracts (%d):\n", len(response.Contracts))
+	for _, contract := range response.Contracts {
+		fmt.Printf("\n%s (%s)\n", contract.Address, contract.Type)
+		fmt.Printf("\tBalance: %s SDR\n", utils.FormatSdr(contract.Balance))
+		if contract.IsRelativeLockTime {
+			fmt.Printf("\tLocked for %d DAA scores after funding\n", contract.LockTime)
+		} else {
+			fmt.Printf("\tLocked until %d\n", contract.LockTime)
+		}
+		if contract.SecretHash != "" {
+			fmt.Printf("\tSecret hash: %s\n", contract.SecretHash)
+		}
+		fmt.Printf("\tCan spend immediately: %t\n", contract.CanSpendImmediately)
+		fmt.Printf("\tCan spend after lock time: %t\n", contract.CanSpendAfterLockTime)
+		if conf.Verbose {
+			fmt.Printf("\tRedeem script: %s\n", contract.RedeemScript)
+		}
+	}
+	return nil
+}
@@ -40,7 +40,7 @@ func sign(conf *signConfig) error {
 		}
 		transactionsHex = strings.TrimSpace(string(transactionHexBytes))
 	}
-	partiallySignedTransactions, err := decodeTransactionsFromHex(transactionsHex)
+	partiallySignedTransactions, err := decodePartiallySignedTransactions(transactionsHex)
 	if err != nil {
 		return err
 	}
@@ -48,7 +48,8 @@ func sign(conf *signConfig) error {
 	updatedPartiallySignedTransactions := make([][]byte, len(partiallySignedTransactions))
 	for i, partiallySignedTransaction := range partiallySignedTransactions {
 		updatedPartiallySignedTransactions[i], err =
-			libsedrawallet.Sign(conf.NetParams(), privateKeys, partiallySignedTransaction, keysFile.ECDSA)
+			libsedrawallet.SignWithAccount(conf.NetParams(), privateKeys, conf.Account, partiallySignedTransaction,
+				keysFile.ECDSA)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/keys"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
+	"github.com/sedracoin/sedrad/util"
+	"github.com/sedracoin/sedrad/util/messagesigning"
+	"github.com/pkg/errors"
+)
+
+// addressSearchGap is the number of indexes after the last used one that are
+// searched for the address of a message
+const addressSearchGap = 100
+
+func signMessage(conf *signMessageConfig) error {
+	keysFile, err := keys.ReadKeysFile(conf.NetParams(), conf.KeysFile)
+	if err != nil {
+		return err
+	}
+
+	address, err := util.DecodeAddress(conf.Address, conf.NetParams().Prefix)
+	if err != nil {
+		return err
+	}
+
+	extendedPublicKeys, err := keysFile.AccountExtendedPublicKeys(conf.Account)
+	if err != nil {
+		return err
+	}
+
+	path := conf.DerivationPath
+	if path == "" {
+		path, err = addressPath(conf, keysFile, extendedPublicKeys, address)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(conf.Password) == 0 {
+		conf.Password = keys.GetPassword("Password:")
+	}
+	mnemonics, err := keysFile.DecryptMnemonics(conf.Password)
+	if err != nil {
+		if strings.Contains(err.Error(), "message authentication failed") {
+			fmt.Fprintf(os.Stderr, "Password decryption failed. Sometimes this is a result of not "+
+				"specifying the same keys file used by the wallet daemon process.\n")
+		}
+		return err
+	}
+
+	signature, err := libsedrawallet.SignMessage(conf.NetParams(), mnemonics, extendedPublicKeys,
+		keysFile.MinimumSignatures, conf.Account, path, []byte(conf.Message), keysFile.ECDSA)
+	if err != nil {
+		return err
+	}
+
+	isMultisig := len(keysFile.ExtendedPublicKeys) > 1
+	if !isMultisig {
+		fmt.Printf("Signature:\n%x\n", signature)
+		return nil
+	}
+
+	signatures := [][]byte{signature}
+	for _, cosignerSignatureHex := range conf.CosignerSignatures {
+		cosignerSignature, err := hex.DecodeString(cosignerSignatureHex)
+		if err != nil {
+			return errors.Wrap(err, "invalid cosigner signature")
+		}
+		signatures = append(signatures, cosignerSignature)
+	}
+	signature, err = libsedrawallet.CombineMessageSignatures(signatures)
+	if err != nil {
+		return err
+	}
+
+	err = messagesigning.Verify(address, []byte(conf.Message), signature)
+	if err != nil {
+		fmt.Printf("Partial signature (%s):\n%x\n", err, signature)
+		fmt.Println("Pass it to the other cosigners with --cosigner-signature until there are enough signatures")
+		return nil
+	}
+	fmt.Printf("Signature:\n%x\n", signature)
+	return nil
+}
+
+// addressPath returns the derivation path of the given address of the wallet
+// account with the given extended public keys. Addresses that are more than
+// addressSearchGap indexes after the last used index aren't found, and their
+// path has to be given explicitly.
+func addressPath(conf *signMessageConfig, keysFile *keys.File, extendedPublicKeys []string, address util.Address) (
+	string, error) {
+
+	isMultisig := len(extendedPublicKeys) > 1
+	numCosigners := uint32(1)
+	if isMultisig {
+		numCosigners = uint32(len(extendedPublicKeys))
+	}
+	lastUsedIndexes := map[uint8]uint32{
+		libsedrawallet.ExternalKeychain: keysFile.LastUsedExternalIndex(conf.Account),
+		libsedrawallet.InternalKeychain: keysFile.LastUsedInternalIndex(conf.Account),
+	}
+
+	for _, keyChain := range []uint8{libsedrawallet.ExternalKeychain, libsedrawallet.InternalKeychain} {
+		for cosignerIndex := uint32(0); cosignerIndex < numCosigners; cosignerIndex++ {
+			for index := uint32(0); index <= lastUsedIndexes[keyChain]+addressSearchGap; index++ {
+				path := fmt.Sprintf("m/%d/%d", keyChain, index)
+				if isMultisig {
+					path = fmt.Sprintf("m/%d/%d/%d", cosignerIndex, keyChain, index)
+				}
+
+				candidate, err := libsedrawallet.Address(conf.NetParams(), extendedPublicKeys,
+					keysFile.MinimumSignatures, path, keysFile.ECDSA)
+				if err != nil {
+					return "", err
+				}
+				if candidate.String() == address.String() {
+					return path, nil
+				}
+			}
+		}
+	}
+
+	return "", errors.Errorf("%s was not found in the wallet. If it's an address of the wallet with a "+
+		"high index, specify its path with --derivation-path", address)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/client"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/daemon/pb"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/keys"
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet"
+	"github.com/pkg/errors"
+)
+
+func spendContract(conf *spendContractConfig) error {
+	keysFile, err := keys.ReadKeysFile(conf.NetParams(), conf.KeysFile)
+	if err != nil {
+		return err
+	}
+
+	if len(keysFile.ExtendedPublicKeys) > 1 {
+		return errors.Errorf("Contracts are only supported by single-signer wallets")
+	}
+
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	response, err := daemonClient.CreateUnsignedContractSpend(ctx, &pb.CreateUnsignedContractSpendRequest{
+		ContractAddress: conf.ContractAddress,
+		ToAddress:       conf.ToAddress,
+		Secret:          conf.Secret,
+		Recover:         conf.Recover,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(conf.Password) == 0 {
+		conf.Password = keys.GetPassword("Password:")
+	}
+	mnemonics, err := keysFile.DecryptMnemonics(conf.Password)
+	if err != nil {
+		if strings.Contains(err.Error(), "message authentication failed") {
+			fmt.Fprintf(os.Stderr, "Password decryption failed. Sometimes this is a result of not "+
+				"specifying the same keys file used by the wallet daemon process.\n")
+		}
+		return err
+	}
+
+	signedTransactions := make([][]byte, len(response.UnsignedTransactions))
+	for i, unsignedTransaction := range response.UnsignedTransactions {
+		signedTransactions[i], err = libsedrawallet.SignWithAccount(conf.NetParams(), mnemonics, 0, unsignedTransaction,
+			keysFile.ECDSA)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Since we waited for user input when getting the password, which could take unbound amount of time -
+	// create a new context for broadcast, to reset the timeout.
+	broadcastCtx, broadcastCancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer broadcastCancel()
+
+	broadcastResponse, err := daemonClient.Broadcast(broadcastCtx, &pb.BroadcastRequest{Transactions: signedTransactions})
+	if err != nil {
+		return err
+	}
+	fmt.Println("Transactions were sent successfully")
+	fmt.Println("Transaction ID(s): ")
+	for _, txID := range broadcastResponse.TxIDs {
+		fmt.Printf("\t%s\n", txID)
+	}
+
+	return nil
+}
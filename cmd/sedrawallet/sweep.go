@@ -79,7 +79,7 @@ func sweep(conf *sweepConfig) error {
 		paymentAmount = paymentAmount + UTXO.UTXOEntry.Amount()
 	}
 
-	newAddressResponse, err := daemonClient.NewAddress(ctx, &pb.NewAddressRequest{})
+	newAddressResponse, err := daemonClient.NewAddress(ctx, &pb.NewAddressRequest{Account: conf.Account})
 	if err != nil {
 		return err
 	}
@@ -2,7 +2,11 @@ package main
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"strings"
+
+	"github.com/sedracoin/sedrad/cmd/sedrawallet/libsedrawallet/serialization"
+	"github.com/pkg/errors"
 )
 
 // hexTransactionsSeparator is used to mark the end of one transaction and the beginning of the next one.
@@ -31,3 +35,37 @@ func decodeTransactionsFromHex(transactionsHex string) ([][]byte, error) {
 
 	return transactions, nil
 }
+
+// decodePartiallySignedTransactions decodes partially signed transactions that
+// are given either as a JSON PSST, or a JSON array of them, or in hex, as
+// binary PSSTs or in the encoding used by sedrawallet, separated by
+// hexTransactionsSeparator. The transactions are returned serialized.
+func decodePartiallySignedTransactions(transactions string) ([][]byte, error) {
+	transactions = strings.TrimSpace(transactions)
+	if !strings.HasPrefix(transactions, "{") && !strings.HasPrefix(transactions, "[") {
+		return decodeTransactionsFromHex(transactions)
+	}
+
+	var psstsJSON []json.RawMessage
+	if strings.HasPrefix(transactions, "[") {
+		err := json.Unmarshal([]byte(transactions), &psstsJSON)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		psstsJSON = []json.RawMessage{json.RawMessage(transactions)}
+	}
+
+	serializedTransactions := make([][]byte, len(psstsJSON))
+	for i, psstJSON := range psstsJSON {
+		partiallySignedTransaction, err := serialization.PSSTFromJSON(psstJSON)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid PSST #%d", i+1)
+		}
+		serializedTransactions[i], err = serialization.SerializePSST(partiallySignedTransaction)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return serializedTransactions, nil
+}
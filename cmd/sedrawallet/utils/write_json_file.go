@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// WriteJSONFileAtomically writes value as JSON to the file at path. The JSON
+// is written to a temporary file that then replaces the existing one, so a
+// failure in the middle never leaves a partially written file behind.
+func WriteJSONFileAtomically(path string, value interface{}) error {
+	temporaryPath := path + ".tmp"
+	file, err := os.OpenFile(temporaryPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	err = json.NewEncoder(file).Encode(value)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	err = file.Sync()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(temporaryPath, path)
+}
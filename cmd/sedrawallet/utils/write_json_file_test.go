@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteJSONFileAtomically(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.json")
+	for _, value := range []map[string]int{{"a": 1, "b": 2}, {"c": 3}} {
+		err := WriteJSONFileAtomically(path, value)
+		if err != nil {
+			t.Fatalf("WriteJSONFileAtomically: %+v", err)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile: %+v", err)
+		}
+		var readValue map[string]int
+		err = json.Unmarshal(content, &readValue)
+		if err != nil {
+			t.Fatalf("Unmarshal: %+v", err)
+		}
+		if len(readValue) != len(value) {
+			t.Fatalf("Expected %v to be written but got %v", value, readValue)
+		}
+		for key, number := range value {
+			if readValue[key] != number {
+				t.Fatalf("Expected %v to be written but got %v", value, readValue)
+			}
+		}
+
+		if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+			t.Fatalf("The temporary file was left behind")
+		}
+	}
+
+	// A value that can't be encoded leaves the existing file as it is
+	err := WriteJSONFileAtomically(path, func() {})
+	if err == nil {
+		t.Fatalf("WriteJSONFileAtomically unexpectedly succeeded with a value that can't be encoded")
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %+v", err)
+	}
+	var readValue map[string]int
+	err = json.Unmarshal(content, &readValue)
+	if err != nil || readValue["c"] != 3 {
+		t.Fatalf("The existing file was changed by a failed write: %s", content)
+	}
+}
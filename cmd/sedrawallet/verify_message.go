@@ -0,0 +1,30 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/sedracoin/sedrad/util"
+	"github.com/sedracoin/sedrad/util/messagesigning"
+	"github.com/pkg/errors"
+)
+
+func verifyMessage(conf *verifyMessageConfig) error {
+	address, err := util.DecodeAddress(conf.Address, conf.NetParams().Prefix)
+	if err != nil {
+		return err
+	}
+
+	signature, err := hex.DecodeString(conf.Signature)
+	if err != nil {
+		return errors.Wrap(err, "invalid signature")
+	}
+
+	err = messagesigning.Verify(address, []byte(conf.Message), signature)
+	if err != nil {
+		return errors.Wrap(err, "the signature is invalid")
+	}
+
+	fmt.Printf("The message was signed by %s\n", address)
+	return nil
+}
@@ -0,0 +1,178 @@
+package benchmarks
+
+import (
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/sedracoin/sedrad/domain/consensus/model"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/blockheader"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/constants"
+)
+
+// newDAG builds a DAG of the given shape for a benchmark that adds blocks to it
+func newDAG(b *testing.B, shape *DAGShape) *DAG {
+	dag, teardown, err := NewDAG(shape)
+	if err != nil {
+		b.Fatalf("NewDAG: %+v", err)
+	}
+	b.Cleanup(teardown)
+	return dag
+}
+
+// sharedDAGs are DAGs that are shared by benchmarks that don't add blocks to them,
+// so that they're built only once
+var sharedDAGs = make(map[string]*DAG)
+
+func sharedDAG(b *testing.B, shape *DAGShape) *DAG {
+	dag, ok := sharedDAGs[shape.Name]
+	if ok {
+		return dag
+	}
+	dag, teardown, err := NewDAG(shape)
+	if err != nil {
+		b.Fatalf("NewDAG: %+v", err)
+	}
+	sharedDAGsTeardowns = append(sharedDAGsTeardowns, teardown)
+	sharedDAGs[shape.Name] = dag
+	return dag
+}
+
+var sharedDAGsTeardowns []func()
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	for _, teardown := range sharedDAGsTeardowns {
+		teardown()
+	}
+	os.Exit(code)
+}
+
+func BenchmarkValidateAndInsertBlock(b *testing.B) {
+	for _, shape := range DAGShapes {
+		b.Run(shape.Name, func(b *testing.B) {
+			dag := newDAG(b, shape)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				block, err := dag.BuildNextBlock()
+				if err != nil {
+					b.Fatalf("BuildNextBlock: %+v", err)
+				}
+				b.StartTimer()
+
+				err = dag.TestConsensus.ValidateAndInsertBlock(block, true)
+				if err != nil {
+					b.Fatalf("ValidateAndInsertBlock: %+v", err)
+				}
+				dag.Added(consensushashing.BlockHash(block))
+			}
+		})
+	}
+}
+
+// BenchmarkGHOSTDAG benchmarks calculating the GHOSTDAG data of the virtual,
+// which is recalculated whenever the virtual changes
+func BenchmarkGHOSTDAG(b *testing.B) {
+	for _, shape := range DAGShapes {
+		b.Run(shape.Name, func(b *testing.B) {
+			dag := sharedDAG(b, shape)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				err := dag.TestConsensus.GHOSTDAGManager().GHOSTDAG(model.NewStagingArea(), model.VirtualBlockHash)
+				if err != nil {
+					b.Fatalf("GHOSTDAG: %+v", err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkCalculatePastUTXOAndAcceptanceData benchmarks calculating the UTXO set
+// and acceptance data of the virtual, which is done whenever the virtual changes
+func BenchmarkCalculatePastUTXOAndAcceptanceData(b *testing.B) {
+	for _, shape := range DAGShapes {
+		b.Run(shape.Name, func(b *testing.B) {
+			dag := sharedDAG(b, shape)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, _, _, err := dag.TestConsensus.ConsensusStateManager().CalculatePastUTXOAndAcceptanceData(
+					model.NewStagingArea(), model.VirtualBlockHash)
+				if err != nil {
+					b.Fatalf("CalculatePastUTXOAndAcceptanceData: %+v", err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkReachabilityAddBlock benchmarks adding blocks to the reachability tree, including
+// the reindexing that they cause. The blocks are only staged, so that nothing but the
+// reachability data has to be calculated for them.
+func BenchmarkReachabilityAddBlock(b *testing.B) {
+	for _, shape := range DAGShapes {
+		b.Run(shape.Name, func(b *testing.B) {
+			dag := newDAG(b, shape)
+			tc := dag.TestConsensus
+			stagingArea := model.NewStagingArea()
+			genesis := tc.DAGParams().GenesisBlock
+			selectedTip, err := tc.GetVirtualSelectedParent()
+			if err != nil {
+				b.Fatalf("GetVirtualSelectedParent: %+v", err)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				parents := dag.NextParents()
+				header := blockheader.NewImmutableBlockHeader(
+					constants.BlockVersion,
+					[]externalapi.BlockLevelParents{parents},
+					&externalapi.DomainHash{},
+					&externalapi.DomainHash{},
+					&externalapi.DomainHash{},
+					genesis.Header.TimeInMilliseconds(),
+					genesis.Header.Bits(),
+					uint64(i),
+					0,
+					0,
+					big.NewInt(0),
+					tc.DAGParams().GenesisHash,
+				)
+				blockHash := consensushashing.HeaderHash(header)
+				tc.BlockHeaderStore().Stage(stagingArea, blockHash, header)
+				err := tc.DAGTopologyManager().SetParents(stagingArea, blockHash, parents)
+				if err != nil {
+					b.Fatalf("SetParents: %+v", err)
+				}
+				err = tc.GHOSTDAGManager().GHOSTDAG(stagingArea, blockHash)
+				if err != nil {
+					b.Fatalf("GHOSTDAG: %+v", err)
+				}
+				selectedTip, err = tc.GHOSTDAGManager().ChooseSelectedParent(stagingArea, selectedTip, blockHash)
+				if err != nil {
+					b.Fatalf("ChooseSelectedParent: %+v", err)
+				}
+				b.StartTimer()
+
+				err = tc.ReachabilityManager().AddBlock(stagingArea, blockHash)
+				if err != nil {
+					b.Fatalf("AddBlock: %+v", err)
+				}
+				if selectedTip.Equal(blockHash) {
+					err = tc.ReachabilityManager().UpdateReindexRoot(stagingArea, selectedTip)
+					if err != nil {
+						b.Fatalf("UpdateReindexRoot: %+v", err)
+					}
+				}
+				dag.Added(blockHash)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package benchmarks
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sedracoin/sedrad/domain/consensus"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/model/testapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/dagconfig"
+)
+
+// DAGShape describes the DAG a benchmark runs on. A DAG of a shape starts with a chain of
+// ChainLength blocks, continues with Rounds rounds of Width blocks each, where every block
+// points at all the blocks of the previous round, and ends with Tips blocks that all point
+// at the last block before them. Blocks that are added to the DAG after it's built continue
+// its last part.
+type DAGShape struct {
+	Name        string
+	ChainLength int
+	Width       int
+	Rounds      int
+	Tips        int
+}
+
+// DAGShapes are the shapes benchmarks run on
+var DAGShapes = []*DAGShape{
+	// Deep is a long selected parent chain, like the DAG of a network with little concurrency
+	{Name: "Deep", ChainLength: 1000},
+
+	// Wide is a DAG where every block merges the blocks of the previous round,
+	// like the DAG of a network with a high block rate
+	{Name: "Wide", ChainLength: 10, Width: 8, Rounds: 120},
+
+	// ManyTips is a DAG with many tips, like the one the many-tips stability test builds
+	{Name: "ManyTips", ChainLength: 100, Tips: 500},
+}
+
+// DAG is a DAG of some shape in a TestConsensus
+type DAG struct {
+	TestConsensus testapi.TestConsensus
+	shape         *DAGShape
+
+	// previousRound are the blocks the next block points at
+	previousRound []*externalapi.DomainHash
+	currentRound  []*externalapi.DomainHash
+	blockCount    int
+}
+
+// NewConsensusConfig returns the config of the consensus benchmarks run on. Proof of
+// work is skipped, so that building DAGs doesn't depend on mining.
+func NewConsensusConfig() *consensus.Config {
+	consensusConfig := &consensus.Config{Params: dagconfig.DevnetParams}
+	consensusConfig.SkipProofOfWork = true
+	return consensusConfig
+}
+
+// NewDAG builds a DAG of the given shape in a new TestConsensus. The returned teardown
+// function must be called once the DAG is no longer used.
+func NewDAG(shape *DAGShape) (dag *DAG, teardown func(), err error) {
+	tc, tcTeardown, err := consensus.NewFactory().NewTestConsensus(NewConsensusConfig(), "Benchmark"+shape.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+	teardown = func() { tcTeardown(false) }
+
+	dag = &DAG{
+		TestConsensus: tc,
+		shape:         shape,
+		previousRound: []*externalapi.DomainHash{tc.DAGParams().GenesisHash},
+	}
+	size := shape.ChainLength + shape.Width*shape.Rounds + shape.Tips
+	for i := 0; i < size; i++ {
+		block, err := dag.BuildNextBlock()
+		if err != nil {
+			teardown()
+			return nil, nil, err
+		}
+		err = tc.ValidateAndInsertBlock(block, true)
+		if err != nil {
+			teardown()
+			return nil, nil, errors.Wrapf(err, "couldn't insert block #%d of %s", i, shape.Name)
+		}
+		dag.Added(consensushashing.BlockHash(block))
+	}
+	return dag, teardown, nil
+}
+
+// NextParents returns the parents of the next block of the DAG
+func (dag *DAG) NextParents() []*externalapi.DomainHash {
+	return dag.previousRound
+}
+
+// BuildNextBlock builds the next block of the DAG, without adding it to the DAG
+func (dag *DAG) BuildNextBlock() (*externalapi.DomainBlock, error) {
+	block, _, err := dag.TestConsensus.BuildBlockWithParents(dag.NextParents(), nil, nil)
+	return block, err
+}
+
+// Added updates the DAG after the next block, with the given hash, was added to it
+func (dag *DAG) Added(blockHash *externalapi.DomainHash) {
+	dag.blockCount++
+
+	switch {
+	case dag.blockCount <= dag.shape.ChainLength:
+		dag.previousRound = []*externalapi.DomainHash{blockHash}
+	case dag.blockCount <= dag.shape.ChainLength+dag.shape.Width*dag.shape.Rounds || dag.shape.Tips == 0:
+		if dag.shape.Width == 0 {
+			dag.previousRound = []*externalapi.DomainHash{blockHash}
+			return
+		}
+		dag.currentRound = append(dag.currentRound, blockHash)
+		if len(dag.currentRound) == dag.shape.Width {
+			dag.previousRound = dag.currentRound
+			dag.currentRound = nil
+		}
+	default:
+		// All the tips point at the block before them, which is already the previous round
+	}
+}
@@ -0,0 +1,12 @@
+/*
+Package benchmarks benchmarks the hot paths of consensus on realistic DAGs.
+
+The DAGs are described by DAGShapes, and every benchmark that depends on the DAG runs on all of
+them as sub-benchmarks. Run the benchmarks with:
+
+	go test -run ^$ -bench . -benchmem -count 5 ./domain/consensus/benchmarks
+
+Results are tracked in the results directory, and two results files are compared with
+cmd/benchcompare, which flags regressions. See results/README.md.
+*/
+package benchmarks
@@ -0,0 +1,31 @@
+package benchmarks
+
+import (
+	"testing"
+
+	"github.com/sedracoin/sedrad/domain/consensus/utils/pow"
+	"github.com/sedracoin/sedrad/domain/dagconfig"
+)
+
+// BenchmarkCheckProofOfWork benchmarks checking the proof of work of a block header,
+// which generates the heavyhash matrix of the header and hashes it once
+func BenchmarkCheckProofOfWork(b *testing.B) {
+	header := dagconfig.MainnetParams.GenesisBlock.Header.ToMutable()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		header.SetNonce(uint64(i))
+		pow.CheckProofOfWorkByBits(header)
+	}
+}
+
+// BenchmarkHeavyHash benchmarks hashing a header with different nonces, like a miner does
+func BenchmarkHeavyHash(b *testing.B) {
+	state := pow.NewState(dagconfig.MainnetParams.GenesisBlock.Header.ToMutable())
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		state.IncrementNonce()
+		state.CalculateProofOfWorkValue()
+	}
+}
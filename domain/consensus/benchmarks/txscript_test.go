@@ -0,0 +1,137 @@
+package benchmarks
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sedracoin/go-secp256k1"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/constants"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/subnetworks"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/utxo"
+	"github.com/sedracoin/sedrad/util"
+)
+
+// BenchmarkVerifySignatures benchmarks verifying the signatures of a transaction whose inputs
+// spend pay-to-pubkey outputs. Signatures aren't cached, like for a transaction that wasn't in
+// the mempool before it was included in a block.
+func BenchmarkVerifySignatures(b *testing.B) {
+	privateKey := make([]byte, 32)
+	privateKey[31] = 1
+
+	for _, ecdsa := range []bool{false, true} {
+		for _, inputCount := range []int{1, 10, 100} {
+			name := fmt.Sprintf("Schnorr/%d-inputs", inputCount)
+			if ecdsa {
+				name = fmt.Sprintf("ECDSA/%d-inputs", inputCount)
+			}
+			b.Run(name, func(b *testing.B) {
+				tx, scriptPublicKey := signedTransaction(b, privateKey, ecdsa, inputCount)
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					sighashReusedValues := &consensushashing.SighashReusedValues{}
+					for inputIndex := range tx.Inputs {
+						vm, err := txscript.NewEngine(scriptPublicKey, tx, inputIndex, txscript.ScriptNoFlags,
+							nil, nil, sighashReusedValues)
+						if err != nil {
+							b.Fatalf("NewEngine: %+v", err)
+						}
+						err = vm.Execute()
+						if err != nil {
+							b.Fatalf("Execute: %+v", err)
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+// signedTransaction returns a transaction with the given number of inputs, which spend
+// pay-to-pubkey outputs of the given private key, along with the script of these outputs
+func signedTransaction(b *testing.B, privateKey []byte, ecdsa bool, inputCount int) (
+	*externalapi.DomainTransaction, *externalapi.ScriptPublicKey) {
+
+	var address util.Address
+	var schnorrKeyPair *secp256k1.SchnorrKeyPair
+	var ecdsaPrivateKey *secp256k1.ECDSAPrivateKey
+	var err error
+	if ecdsa {
+		ecdsaPrivateKey, err = secp256k1.DeserializeECDSAPrivateKeyFromSlice(privateKey)
+		if err != nil {
+			b.Fatalf("DeserializeECDSAPrivateKeyFromSlice: %+v", err)
+		}
+		publicKey, err := ecdsaPrivateKey.ECDSAPublicKey()
+		if err != nil {
+			b.Fatalf("ECDSAPublicKey: %+v", err)
+		}
+		serializedPublicKey, err := publicKey.Serialize()
+		if err != nil {
+			b.Fatalf("Serialize: %+v", err)
+		}
+		address, err = util.NewAddressPublicKeyECDSA(serializedPublicKey[:], util.Bech32PrefixSedraDev)
+		if err != nil {
+			b.Fatalf("NewAddressPublicKeyECDSA: %+v", err)
+		}
+	} else {
+		schnorrKeyPair, err = secp256k1.DeserializeSchnorrPrivateKeyFromSlice(privateKey)
+		if err != nil {
+			b.Fatalf("DeserializeSchnorrPrivateKeyFromSlice: %+v", err)
+		}
+		publicKey, err := schnorrKeyPair.SchnorrPublicKey()
+		if err != nil {
+			b.Fatalf("SchnorrPublicKey: %+v", err)
+		}
+		serializedPublicKey, err := publicKey.Serialize()
+		if err != nil {
+			b.Fatalf("Serialize: %+v", err)
+		}
+		address, err = util.NewAddressPublicKey(serializedPublicKey[:], util.Bech32PrefixSedraDev)
+		if err != nil {
+			b.Fatalf("NewAddressPublicKey: %+v", err)
+		}
+	}
+	scriptPublicKey, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		b.Fatalf("PayToAddrScript: %+v", err)
+	}
+
+	const inputValue = 100_000_000
+	inputs := make([]*externalapi.DomainTransactionInput, inputCount)
+	for i := range inputs {
+		inputs[i] = &externalapi.DomainTransactionInput{
+			PreviousOutpoint: externalapi.DomainOutpoint{Index: uint32(i)},
+			Sequence:         constants.MaxTxInSequenceNum,
+			SigOpCount:       1,
+			UTXOEntry:        utxo.NewUTXOEntry(inputValue, scriptPublicKey, false, 0),
+		}
+	}
+	tx := &externalapi.DomainTransaction{
+		Version: constants.MaxTransactionVersion,
+		Inputs:  inputs,
+		Outputs: []*externalapi.DomainTransactionOutput{{
+			Value:           inputValue*uint64(inputCount) - 10_000,
+			ScriptPublicKey: scriptPublicKey,
+		}},
+		SubnetworkID: subnetworks.SubnetworkIDNative,
+		Payload:      []byte{},
+	}
+
+	sighashReusedValues := &consensushashing.SighashReusedValues{}
+	for i, input := range tx.Inputs {
+		if ecdsa {
+			input.SignatureScript, err = txscript.SignatureScriptECDSA(tx, i, consensushashing.SigHashAll,
+				ecdsaPrivateKey, sighashReusedValues)
+		} else {
+			input.SignatureScript, err = txscript.SignatureScript(tx, i, consensushashing.SigHashAll,
+				schnorrKeyPair, sighashReusedValues)
+		}
+		if err != nil {
+			b.Fatalf("SignatureScript: %+v", err)
+		}
+	}
+	return tx, scriptPublicKey
+}
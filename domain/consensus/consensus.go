@@ -614,6 +614,155 @@ func (s *consensus) GetVirtualUTXOs(expectedVirtualParents []*externalapi.Domain
 	return virtualUTXOs, nil
 }
 
+// GetChainBlockUTXODiff returns the UTXO diff that transforms the virtual UTXO set into the UTXO
+// set of the given chain block, i.e. the UTXO set right after the block had been accepted.
+// expectedVirtualParents is used to make sure that the caller and the consensus agree on the
+// virtual UTXO set the diff is applied to.
+func (s *consensus) GetChainBlockUTXODiff(expectedVirtualParents []*externalapi.DomainHash,
+	blockHash *externalapi.DomainHash) (externalapi.UTXODiff, error) {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	stagingArea := model.NewStagingArea()
+
+	virtualParents, err := s.dagTopologyManagers[0].Parents(stagingArea, model.VirtualBlockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if !externalapi.HashesEqual(expectedVirtualParents, virtualParents) {
+		return nil, errors.Wrapf(ruleerrors.ErrGetVirtualUTXOsWrongVirtualParents, "expected virtual parents %s but got %s",
+			expectedVirtualParents,
+			virtualParents)
+	}
+
+	err = s.validateBlockHashExists(stagingArea, blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	isChainBlock, err := s.dagTopologyManagers[0].IsInSelectedParentChainOf(stagingArea, blockHash, model.VirtualBlockHash)
+	if err != nil {
+		return nil, err
+	}
+	if !isChainBlock {
+		return nil, errors.Errorf("block %s is not in the virtual selected parent chain", blockHash)
+	}
+
+	pruningPoint, err := s.pruningStore.PruningPoint(s.databaseContext, stagingArea)
+	if err != nil {
+		return nil, err
+	}
+	isInPruningPointSelectedChain, err := s.dagTopologyManagers[0].IsInSelectedParentChainOf(stagingArea, blockHash, pruningPoint)
+	if err != nil {
+		return nil, err
+	}
+	if isInPruningPointSelectedChain && !blockHash.Equal(pruningPoint) {
+		// Non-archival nodes delete the UTXO diffs of blocks below the pruning point
+		hasUTXODiffChild, err := s.utxoDiffStore.HasUTXODiffChild(s.databaseContext, stagingArea, blockHash)
+		if err != nil {
+			return nil, err
+		}
+		if !hasUTXODiffChild {
+			return nil, errors.Wrapf(ruleerrors.ErrPrunedBlockUTXOSet, "block %s is below the pruning point %s "+
+				"and its UTXO data had already been pruned", blockHash, pruningPoint)
+		}
+	}
+
+	return s.consensusStateManager.RestoreDiffFromVirtual(stagingArea, blockHash)
+}
+
+// GetChainBlockByDAAScore returns the highest block in the virtual selected parent chain
+// with a DAA score that is not greater than the given one
+func (s *consensus) GetChainBlockByDAAScore(daaScore uint64) (*externalapi.DomainHash, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	stagingArea := model.NewStagingArea()
+
+	return s.findHighestChainBlock(stagingArea, func(blockHash *externalapi.DomainHash) (bool, error) {
+		blockHeader, err := s.blockHeaderStore.BlockHeader(s.databaseContext, stagingArea, blockHash)
+		if err != nil {
+			return false, err
+		}
+		return blockHeader.DAAScore() <= daaScore, nil
+	})
+}
+
+// GetChainBlockByTimestamp returns the highest block in the virtual selected parent chain
+// with a timestamp that is not greater than the given one.
+// Note that block timestamps are not strictly monotonic along the chain, so the result is
+// accurate up to the timestamp deviation tolerance.
+func (s *consensus) GetChainBlockByTimestamp(timeInMilliseconds int64) (*externalapi.DomainHash, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	stagingArea := model.NewStagingArea()
+
+	return s.findHighestChainBlock(stagingArea, func(blockHash *externalapi.DomainHash) (bool, error) {
+		blockHeader, err := s.blockHeaderStore.BlockHeader(s.databaseContext, stagingArea, blockHash)
+		if err != nil {
+			return false, err
+		}
+		return blockHeader.TimeInMilliseconds() <= timeInMilliseconds, nil
+	})
+}
+
+// findHighestChainBlock binary-searches the headers selected chain, up to the virtual
+// selected parent, for the highest block for which isNotAfter returns true.
+// isNotAfter is expected to be monotonic along the chain.
+func (s *consensus) findHighestChainBlock(stagingArea *model.StagingArea,
+	isNotAfter func(blockHash *externalapi.DomainHash) (bool, error)) (*externalapi.DomainHash, error) {
+
+	virtualGHOSTDAGData, err := s.ghostdagDataStores[0].Get(s.databaseContext, stagingArea, model.VirtualBlockHash, false)
+	if err != nil {
+		return nil, err
+	}
+	highIndex, err := s.headersSelectedChainStore.GetIndexByHash(s.databaseContext, stagingArea,
+		virtualGHOSTDAGData.SelectedParent())
+	if database.IsNotFoundError(err) {
+		return nil, errors.Errorf("the virtual selected parent %s is not in the headers selected chain",
+			virtualGHOSTDAGData.SelectedParent())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lowIndex := uint64(0)
+	lowHash, err := s.headersSelectedChainStore.GetHashByIndex(s.databaseContext, stagingArea, lowIndex)
+	if err != nil {
+		return nil, err
+	}
+	isLowNotAfter, err := isNotAfter(lowHash)
+	if err != nil {
+		return nil, err
+	}
+	if !isLowNotAfter {
+		return nil, errors.Errorf("the requested point precedes the lowest known chain block %s", lowHash)
+	}
+
+	// Invariant: the block at lowIndex satisfies isNotAfter
+	for lowIndex < highIndex {
+		middleIndex := lowIndex + (highIndex-lowIndex+1)/2
+		middleHash, err := s.headersSelectedChainStore.GetHashByIndex(s.databaseContext, stagingArea, middleIndex)
+		if err != nil {
+			return nil, err
+		}
+		isMiddleNotAfter, err := isNotAfter(middleHash)
+		if err != nil {
+			return nil, err
+		}
+		if isMiddleNotAfter {
+			lowIndex = middleIndex
+		} else {
+			highIndex = middleIndex - 1
+		}
+	}
+
+	return s.headersSelectedChainStore.GetHashByIndex(s.databaseContext, stagingArea, lowIndex)
+}
+
 func (s *consensus) PruningPoint() (*externalapi.DomainHash, error) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
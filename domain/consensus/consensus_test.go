@@ -1,9 +1,11 @@
 package consensus_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/sedracoin/sedrad/domain/consensus"
+	"github.com/sedracoin/sedrad/domain/consensus/model"
 	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
 	"github.com/sedracoin/sedrad/domain/consensus/ruleerrors"
 	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
@@ -74,3 +76,210 @@ func TestConsensus_GetBlockInfo(t *testing.T) {
 
 	})
 }
+
+func TestConsensus_GetChainBlockUTXODiff(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestConsensus_GetChainBlockUTXODiff")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		chain := []*externalapi.DomainHash{consensusConfig.GenesisHash}
+		for i := 0; i < 10; i++ {
+			blockHash, _, err := tc.AddBlock([]*externalapi.DomainHash{chain[len(chain)-1]}, nil, nil)
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+			chain = append(chain, blockHash)
+		}
+
+		virtualInfo, err := tc.GetVirtualInfo()
+		if err != nil {
+			t.Fatalf("GetVirtualInfo: %+v", err)
+		}
+		virtualUTXOs, err := tc.GetVirtualUTXOs(virtualInfo.ParentHashes, nil, 1000)
+		if err != nil {
+			t.Fatalf("GetVirtualUTXOs: %+v", err)
+		}
+
+		for _, blockHash := range chain[1:] {
+			diffFromVirtual, err := tc.GetChainBlockUTXODiff(virtualInfo.ParentHashes, blockHash)
+			if err != nil {
+				t.Fatalf("GetChainBlockUTXODiff: %+v", err)
+			}
+			restoredUTXOs := make(map[externalapi.DomainOutpoint]externalapi.UTXOEntry)
+			for _, pair := range virtualUTXOs {
+				restoredUTXOs[*pair.Outpoint] = pair.UTXOEntry
+			}
+			iterator := diffFromVirtual.ToRemove().Iterator()
+			for ok := iterator.First(); ok; ok = iterator.Next() {
+				outpoint, _, err := iterator.Get()
+				if err != nil {
+					t.Fatalf("Get: %+v", err)
+				}
+				delete(restoredUTXOs, *outpoint)
+			}
+			iterator.Close()
+			iterator = diffFromVirtual.ToAdd().Iterator()
+			for ok := iterator.First(); ok; ok = iterator.Next() {
+				outpoint, entry, err := iterator.Get()
+				if err != nil {
+					t.Fatalf("Get: %+v", err)
+				}
+				restoredUTXOs[*outpoint] = entry
+			}
+			iterator.Close()
+
+			expectedUTXOSetIterator, err := tc.ConsensusStateManager().RestorePastUTXOSetIterator(model.NewStagingArea(), blockHash)
+			if err != nil {
+				t.Fatalf("RestorePastUTXOSetIterator: %+v", err)
+			}
+			expectedUTXOs := make(map[externalapi.DomainOutpoint]externalapi.UTXOEntry)
+			for ok := expectedUTXOSetIterator.First(); ok; ok = expectedUTXOSetIterator.Next() {
+				outpoint, entry, err := expectedUTXOSetIterator.Get()
+				if err != nil {
+					t.Fatalf("Get: %+v", err)
+				}
+				expectedUTXOs[*outpoint] = entry
+			}
+			expectedUTXOSetIterator.Close()
+
+			if !reflect.DeepEqual(restoredUTXOs, expectedUTXOs) {
+				t.Fatalf("Unexpected UTXO set for block %s: got %d UTXOs, expected %d",
+					blockHash, len(restoredUTXOs), len(expectedUTXOs))
+			}
+
+			header, err := tc.GetBlockHeader(blockHash)
+			if err != nil {
+				t.Fatalf("GetBlockHeader: %+v", err)
+			}
+			blockHashByDAAScore, err := tc.GetChainBlockByDAAScore(header.DAAScore())
+			if err != nil {
+				t.Fatalf("GetChainBlockByDAAScore: %+v", err)
+			}
+			if !blockHashByDAAScore.Equal(blockHash) {
+				t.Fatalf("Expected GetChainBlockByDAAScore(%d) to return %s but got %s",
+					header.DAAScore(), blockHash, blockHashByDAAScore)
+			}
+		}
+
+		_, err = tc.GetChainBlockUTXODiff([]*externalapi.DomainHash{chain[1]}, chain[1])
+		if !errors.Is(err, ruleerrors.ErrGetVirtualUTXOsWrongVirtualParents) {
+			t.Fatalf("Expected ErrGetVirtualUTXOsWrongVirtualParents, but got: %v", err)
+		}
+	})
+}
+
+func TestConsensus_GetChainBlockUTXODiffPruned(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		// This is done to reduce the pruning depth to 6 blocks
+		consensusConfig.FinalityDuration = 2 * consensusConfig.TargetTimePerBlock
+		consensusConfig.K = 0
+
+		// Setting this value to zero forces all DAA windows to be empty, and as such,
+		// no blocks are kept below the pruning point
+		consensusConfig.DifficultyAdjustmentWindowSize = 0
+
+		for _, isArchival := range []bool{false, true} {
+			consensusConfig.IsArchival = isArchival
+
+			factory := consensus.NewFactory()
+			tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestConsensus_GetChainBlockUTXODiffPruned")
+			if err != nil {
+				t.Fatalf("Error setting up consensus: %+v", err)
+			}
+
+			// Add blocks until the pruning point has passed the first block above the genesis
+			chain := []*externalapi.DomainHash{consensusConfig.GenesisHash}
+			for {
+				blockHash, _, err := tc.AddBlock([]*externalapi.DomainHash{chain[len(chain)-1]}, nil, nil)
+				if err != nil {
+					t.Fatalf("AddBlock: %+v", err)
+				}
+				chain = append(chain, blockHash)
+
+				pruningPoint, err := tc.PruningPoint()
+				if err != nil {
+					t.Fatalf("PruningPoint: %+v", err)
+				}
+				if !pruningPoint.Equal(consensusConfig.GenesisHash) && !pruningPoint.Equal(chain[1]) {
+					break
+				}
+			}
+
+			virtualInfo, err := tc.GetVirtualInfo()
+			if err != nil {
+				t.Fatalf("GetVirtualInfo: %+v", err)
+			}
+			_, err = tc.GetChainBlockUTXODiff(virtualInfo.ParentHashes, chain[1])
+			if isArchival {
+				if err != nil {
+					t.Fatalf("GetChainBlockUTXODiff: %+v", err)
+				}
+			} else if !errors.Is(err, ruleerrors.ErrPrunedBlockUTXOSet) {
+				t.Fatalf("Expected ErrPrunedBlockUTXOSet, but got: %v", err)
+			}
+
+			teardown(false)
+		}
+	})
+}
+
+func TestConsensus_GetChainBlockGenesis(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestConsensus_GetChainBlockGenesis")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		chain := []*externalapi.DomainHash{consensusConfig.GenesisHash}
+		for i := 0; i < 3; i++ {
+			blockHash, _, err := tc.AddBlock([]*externalapi.DomainHash{chain[len(chain)-1]}, nil, nil)
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+			chain = append(chain, blockHash)
+		}
+
+		// A DAA score of 0 selects the highest chain block whose DAA score is 0
+		blockHash, err := tc.GetChainBlockByDAAScore(0)
+		if err != nil {
+			t.Fatalf("GetChainBlockByDAAScore: %+v", err)
+		}
+		expectedBlockHash := consensusConfig.GenesisHash
+		for _, chainBlockHash := range chain[1:] {
+			header, err := tc.GetBlockHeader(chainBlockHash)
+			if err != nil {
+				t.Fatalf("GetBlockHeader: %+v", err)
+			}
+			if header.DAAScore() != 0 {
+				break
+			}
+			expectedBlockHash = chainBlockHash
+		}
+		if !blockHash.Equal(expectedBlockHash) {
+			t.Fatalf("Expected GetChainBlockByDAAScore(0) to return %s but got %s", expectedBlockHash, blockHash)
+		}
+
+		genesisHeader, err := tc.GetBlockHeader(consensusConfig.GenesisHash)
+		if err != nil {
+			t.Fatalf("GetBlockHeader: %+v", err)
+		}
+		blockHash, err = tc.GetChainBlockByTimestamp(genesisHeader.TimeInMilliseconds())
+		if err != nil {
+			t.Fatalf("GetChainBlockByTimestamp: %+v", err)
+		}
+		if !blockHash.Equal(consensusConfig.GenesisHash) {
+			t.Fatalf("Expected the genesis timestamp to select the genesis %s but got %s",
+				consensusConfig.GenesisHash, blockHash)
+		}
+		_, err = tc.GetChainBlockByTimestamp(genesisHeader.TimeInMilliseconds() - 1)
+		if err == nil {
+			t.Fatalf("Expected a timestamp before the genesis not to select any block")
+		}
+	})
+}
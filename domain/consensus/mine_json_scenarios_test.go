@@ -0,0 +1,77 @@
+package consensus_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sedracoin/sedrad/domain/consensus"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/model/testapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/testutils"
+	"github.com/sedracoin/sedrad/domain/dagconfig"
+)
+
+// mineJSONScenario is a scenario in testdata/scenarios. See testdata/scenarios/README.md
+type mineJSONScenario struct {
+	Description string `json:"description"`
+	Params      struct {
+		K                     *externalapi.KType `json:"k"`
+		BlockCoinbaseMaturity *uint64            `json:"blockCoinbaseMaturity"`
+		CheckProofOfWork      bool               `json:"checkProofOfWork"`
+	} `json:"params"`
+	Steps json.RawMessage `json:"steps"`
+}
+
+func TestMineJSONScenarios(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "scenarios", "*.json"))
+	if err != nil {
+		t.Fatalf("Glob: %+v", err)
+	}
+	if len(paths) == 0 {
+		t.Fatalf("No scenarios found")
+	}
+
+	for _, path := range paths {
+		scenarioJSON, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile: %+v", err)
+		}
+		scenario := &mineJSONScenario{}
+		err = json.Unmarshal(scenarioJSON, scenario)
+		if err != nil {
+			t.Fatalf("Couldn't parse scenario %s: %+v", path, err)
+		}
+
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		t.Run(name, func(t *testing.T) {
+			testutils.ForAllNets(t, !scenario.Params.CheckProofOfWork, func(t *testing.T, consensusConfig *consensus.Config) {
+				// Difficulty is too high on mainnet to actually mine.
+				if scenario.Params.CheckProofOfWork && consensusConfig.Name == dagconfig.MainnetParams.Name {
+					t.Skip("Blocks can't be mined on mainnet")
+				}
+				if scenario.Params.K != nil {
+					consensusConfig.K = *scenario.Params.K
+				}
+				if scenario.Params.BlockCoinbaseMaturity != nil {
+					consensusConfig.BlockCoinbaseMaturity = *scenario.Params.BlockCoinbaseMaturity
+				}
+
+				factory := consensus.NewFactory()
+				tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestMineJSONScenarios")
+				if err != nil {
+					t.Fatalf("Error setting up consensus: %+v", err)
+				}
+				defer teardown(false)
+
+				_, err = tc.MineJSON(bytes.NewReader(scenario.Steps), testapi.MineJSONBlockTypeUTXOValidBlock)
+				if err != nil {
+					t.Fatalf("Scenario %q failed: %+v", scenario.Description, err)
+				}
+			})
+		})
+	}
+}
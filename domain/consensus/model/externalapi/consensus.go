@@ -26,6 +26,9 @@ type Consensus interface {
 	GetMissingBlockBodyHashes(highHash *DomainHash) ([]*DomainHash, error)
 	GetPruningPointUTXOs(expectedPruningPointHash *DomainHash, fromOutpoint *DomainOutpoint, limit int) ([]*OutpointAndUTXOEntryPair, error)
 	GetVirtualUTXOs(expectedVirtualParents []*DomainHash, fromOutpoint *DomainOutpoint, limit int) ([]*OutpointAndUTXOEntryPair, error)
+	GetChainBlockUTXODiff(expectedVirtualParents []*DomainHash, blockHash *DomainHash) (UTXODiff, error)
+	GetChainBlockByDAAScore(daaScore uint64) (*DomainHash, error)
+	GetChainBlockByTimestamp(timeInMilliseconds int64) (*DomainHash, error)
 	PruningPoint() (*DomainHash, error)
 	PruningPointHeaders() ([]BlockHeader, error)
 	PruningPointAndItsAnticone() ([]*DomainHash, error)
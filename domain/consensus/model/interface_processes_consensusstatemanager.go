@@ -9,6 +9,7 @@ type ConsensusStateManager interface {
 	ImportPruningPointUTXOSet(stagingArea *StagingArea, newPruningPoint *externalapi.DomainHash) error
 	ImportPruningPoints(stagingArea *StagingArea, pruningPoints []externalapi.BlockHeader) error
 	RestorePastUTXOSetIterator(stagingArea *StagingArea, blockHash *externalapi.DomainHash) (externalapi.ReadOnlyUTXOSetIterator, error)
+	RestoreDiffFromVirtual(stagingArea *StagingArea, blockHash *externalapi.DomainHash) (externalapi.UTXODiff, error)
 	CalculatePastUTXOAndAcceptanceData(stagingArea *StagingArea, blockHash *externalapi.DomainHash) (externalapi.UTXODiff, externalapi.AcceptanceData, Multiset, error)
 	GetVirtualSelectedParentChainFromBlock(stagingArea *StagingArea, blockHash *externalapi.DomainHash) (*externalapi.SelectedChainPath, error)
 	RecoverUTXOIfRequired() error
@@ -266,6 +266,18 @@ func (csm *consensusStateManager) maybeAcceptTransaction(stagingArea *model.Stag
 	return true, accumulatedMassAfter, nil
 }
 
+// RestoreDiffFromVirtual returns the UTXO diff that transforms the virtual UTXO set into the UTXO set of
+// the given block. The block is expected to be in the virtual selected parent chain and to still have its
+// UTXO diff in the database.
+func (csm *consensusStateManager) RestoreDiffFromVirtual(stagingArea *model.StagingArea,
+	blockHash *externalapi.DomainHash) (externalapi.UTXODiff, error) {
+
+	onEnd := logger.LogAndMeasureExecutionTime(log, "RestoreDiffFromVirtual")
+	defer onEnd()
+
+	return csm.restorePastUTXO(stagingArea, blockHash)
+}
+
 // RestorePastUTXOSetIterator restores the given block's UTXOSet iterator, and returns it as a externalapi.ReadOnlyUTXOSetIterator
 func (csm *consensusStateManager) RestorePastUTXOSetIterator(stagingArea *model.StagingArea, blockHash *externalapi.DomainHash) (
 	externalapi.ReadOnlyUTXOSetIterator, error) {
@@ -68,30 +68,40 @@ func (csm *consensusStateManager) validateBlockTransactionsAgainstPastUTXO(stagi
 	}
 	log.Tracef("The past median time of %s is %d", blockHash, selectedParentMedianTime)
 
+	// The transactions are populated first and validated together, so that their scripts
+	// are validated in parallel. A transaction can't spend the outputs of another transaction
+	// in the same block, so populating it doesn't depend on the validation of the ones before it.
+	transactions := make([]*externalapi.DomainTransaction, 0, len(block.Transactions))
+	var populateErr error
 	for i, transaction := range block.Transactions {
 		transactionID := consensushashing.TransactionID(transaction)
-		log.Tracef("Validating transaction %s in block %s against "+
-			"the block's past UTXO", transactionID, blockHash)
 		if i == transactionhelper.CoinbaseTransactionIndex {
 			log.Tracef("Skipping transaction %s because it is the coinbase", transactionID)
 			continue
 		}
 
 		log.Tracef("Populating transaction %s with UTXO entries", transactionID)
-		err = csm.populateTransactionWithUTXOEntriesFromVirtualOrDiff(stagingArea, transaction, pastUTXODiff)
-		if err != nil {
-			return err
+		populateErr = csm.populateTransactionWithUTXOEntriesFromVirtualOrDiff(stagingArea, transaction, pastUTXODiff)
+		if populateErr != nil {
+			break
 		}
+		transactions = append(transactions, transaction)
+	}
 
-		log.Tracef("Validating transaction %s and populating it with fee", transactionID)
-		err = csm.transactionValidator.ValidateTransactionInContextAndPopulateFee(
-			stagingArea, transaction, blockHash)
-		if err != nil {
-			return err
-		}
-		log.Tracef("Validation against the block's past UTXO "+
-			"passed for transaction %s in block %s", transactionID, blockHash)
+	// An invalid transaction that precedes the one that couldn't be populated
+	// takes precedence, like it would have if they were validated one by one
+	log.Tracef("Validating %d transactions in block %s against the block's past UTXO "+
+		"and populating them with fees", len(transactions), blockHash)
+	err = csm.transactionValidator.ValidateTransactionsInContextAndPopulateFees(
+		stagingArea, transactions, blockHash)
+	if err != nil {
+		return err
+	}
+	if populateErr != nil {
+		return populateErr
 	}
+	log.Tracef("Validation against the block's past UTXO "+
+		"passed for all transactions in block %s", blockHash)
 	return nil
 }
 
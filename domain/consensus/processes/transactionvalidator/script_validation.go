@@ -0,0 +1,138 @@
+package transactionvalidator
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/ruleerrors"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
+)
+
+// parallelScriptValidationMinInputs is the number of inputs from which scripts are
+// validated by several workers. The scripts of fewer inputs are validated sequentially,
+// since starting the workers would cost more than it saves.
+const parallelScriptValidationMinInputs = 4
+
+// maxScriptValidationWorkers is the maximum number of workers that validate scripts at once
+var maxScriptValidationWorkers = runtime.NumCPU()
+
+// inputScript is an input whose script is to be validated
+type inputScript struct {
+	tx         *externalapi.DomainTransaction
+	inputIndex int
+}
+
+func (v *transactionValidator) validateTransactionScripts(tx *externalapi.DomainTransaction) error {
+	var missingOutpoints []*externalapi.DomainOutpoint
+	inputScripts := make([]inputScript, 0, len(tx.Inputs))
+	for i, input := range tx.Inputs {
+		if input.UTXOEntry == nil {
+			missingOutpoints = append(missingOutpoints, &input.PreviousOutpoint)
+			continue
+		}
+		inputScripts = append(inputScripts, inputScript{tx: tx, inputIndex: i})
+	}
+
+	err := v.validateInputScripts(inputScripts)
+	if err != nil {
+		return err
+	}
+	if len(missingOutpoints) > 0 {
+		return ruleerrors.NewErrMissingTxOut(missingOutpoints)
+	}
+	return nil
+}
+
+// validateInputScripts validates the scripts of the given inputs, whose UTXO entries must
+// be populated. If several inputs are invalid, the error of the first of them is returned,
+// regardless of the order in which the scripts happened to be validated.
+func (v *transactionValidator) validateInputScripts(inputScripts []inputScript) error {
+	// Workers take the inputs in order, and stop taking inputs once any of them fails.
+	// Therefore all the inputs before the first failing one are validated, and the error
+	// of the first failing input is the one with the lowest index.
+	errs := make([]error, len(inputScripts))
+	var nextIndex int64
+	var failed int32
+	worker := func() {
+		// SighashReusedValues isn't safe for concurrent access, and its values are only
+		// reusable for inputs of the same transaction
+		var previousTransaction *externalapi.DomainTransaction
+		var sighashReusedValues *consensushashing.SighashReusedValues
+		for atomic.LoadInt32(&failed) == 0 {
+			index := int(atomic.AddInt64(&nextIndex, 1) - 1)
+			if index >= len(inputScripts) {
+				return
+			}
+			inputScript := inputScripts[index]
+			if inputScript.tx != previousTransaction {
+				previousTransaction = inputScript.tx
+				sighashReusedValues = &consensushashing.SighashReusedValues{}
+			}
+			errs[index] = v.validateInputScript(inputScript, sighashReusedValues)
+			if errs[index] != nil {
+				atomic.StoreInt32(&failed, 1)
+			}
+		}
+	}
+
+	workerCount := maxScriptValidationWorkers
+	if workerCount > len(inputScripts) {
+		workerCount = len(inputScripts)
+	}
+	if len(inputScripts) < parallelScriptValidationMinInputs || workerCount < 2 {
+		worker()
+	} else {
+		var wg sync.WaitGroup
+		wg.Add(workerCount)
+		for i := 0; i < workerCount; i++ {
+			go func() {
+				defer wg.Done()
+				worker()
+			}()
+		}
+		wg.Wait()
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *transactionValidator) validateInputScript(inputScript inputScript,
+	sighashReusedValues *consensushashing.SighashReusedValues) error {
+
+	tx := inputScript.tx
+	i := inputScript.inputIndex
+	input := tx.Inputs[i]
+
+	// Create a new script engine for the script pair.
+	sigScript := input.SignatureScript
+	scriptPubKey := input.UTXOEntry.ScriptPublicKey()
+	vm, err := txscript.NewEngine(scriptPubKey, tx, i, txscript.ScriptNoFlags, v.sigCache, v.sigCacheECDSA, sighashReusedValues)
+	if err != nil {
+		return errors.Wrapf(ruleerrors.ErrScriptMalformed, "failed to parse input "+
+			"%d which references output %s - "+
+			"%s (input script bytes %x, prev "+
+			"output script bytes %x)",
+			i,
+			input.PreviousOutpoint, err, sigScript, scriptPubKey)
+	}
+
+	// Execute the script pair.
+	if err := vm.Execute(); err != nil {
+		return errors.Wrapf(ruleerrors.ErrScriptValidation, "failed to validate input "+
+			"%d which references output %s - "+
+			"%s (input script bytes %x, prev output "+
+			"script bytes %x)",
+			i,
+			input.PreviousOutpoint, err, sigScript, scriptPubKey)
+	}
+	return nil
+}
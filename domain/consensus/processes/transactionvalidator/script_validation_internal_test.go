@@ -0,0 +1,132 @@
+package transactionvalidator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/sedracoin/go-secp256k1"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/ruleerrors"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/constants"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/subnetworks"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/utxo"
+	"github.com/sedracoin/sedrad/util"
+)
+
+// TestValidateInputScriptsWorkers validates scripts with several workers regardless of the
+// number of CPUs, to make sure the error of the first failing input is always returned
+func TestValidateInputScriptsWorkers(t *testing.T) {
+	originalMaxScriptValidationWorkers := maxScriptValidationWorkers
+	maxScriptValidationWorkers = 4
+	defer func() { maxScriptValidationWorkers = originalMaxScriptValidationWorkers }()
+
+	privateKey, err := secp256k1.DeserializeSchnorrPrivateKeyFromSlice([]byte{31: 1})
+	if err != nil {
+		t.Fatalf("DeserializeSchnorrPrivateKeyFromSlice: %+v", err)
+	}
+	publicKey, err := privateKey.SchnorrPublicKey()
+	if err != nil {
+		t.Fatalf("SchnorrPublicKey: %+v", err)
+	}
+	publicKeySerialized, err := publicKey.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize: %+v", err)
+	}
+	addr, err := util.NewAddressPublicKey(publicKeySerialized[:], util.Bech32PrefixSedraSim)
+	if err != nil {
+		t.Fatalf("NewAddressPublicKey: %+v", err)
+	}
+	scriptPublicKey, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("PayToAddrScript: %+v", err)
+	}
+
+	const inputCount = 8
+	newInputScripts := func(txIndex byte, invalidInputs ...int) []inputScript {
+		tx := &externalapi.DomainTransaction{
+			Version: constants.MaxTransactionVersion,
+			Outputs: []*externalapi.DomainTransactionOutput{{
+				Value:           100_000_000,
+				ScriptPublicKey: scriptPublicKey,
+			}},
+			SubnetworkID: subnetworks.SubnetworkIDNative,
+			Payload:      []byte{},
+		}
+		for i := 0; i < inputCount; i++ {
+			tx.Inputs = append(tx.Inputs, &externalapi.DomainTransactionInput{
+				PreviousOutpoint: externalapi.DomainOutpoint{
+					TransactionID: *externalapi.NewDomainTransactionIDFromByteArray(&[32]byte{txIndex}),
+					Index:         uint32(i),
+				},
+				Sequence:   constants.MaxTxInSequenceNum,
+				SigOpCount: 1,
+				UTXOEntry:  utxo.NewUTXOEntry(100_000_000, scriptPublicKey, false, 0),
+			})
+		}
+		inputScripts := make([]inputScript, inputCount)
+		for i, input := range tx.Inputs {
+			input.SignatureScript, err = txscript.SignatureScript(tx, i, consensushashing.SigHashAll, privateKey,
+				&consensushashing.SighashReusedValues{})
+			if err != nil {
+				t.Fatalf("SignatureScript: %+v", err)
+			}
+			inputScripts[i] = inputScript{tx: tx, inputIndex: i}
+		}
+		for _, i := range invalidInputs {
+			tx.Inputs[i].SignatureScript = tx.Inputs[(i+1)%inputCount].SignatureScript
+		}
+		return inputScripts
+	}
+
+	tests := []struct {
+		name string
+		// invalidInputs are the invalid inputs of each of the transactions
+		invalidInputs        [][]int
+		isValid              bool
+		expectedFailingTx    byte
+		expectedFailingInput int
+	}{
+		{name: "valid", invalidInputs: [][]int{{}, {}, {}}, isValid: true},
+		{name: "invalid inputs in one transaction", invalidInputs: [][]int{{}, {2, 3, 7}, {}},
+			expectedFailingTx: 1, expectedFailingInput: 2},
+		{name: "invalid inputs in several transactions", invalidInputs: [][]int{{}, {6}, {0, 1}},
+			expectedFailingTx: 1, expectedFailingInput: 6},
+	}
+	for _, test := range tests {
+		for i := 0; i < 20; i++ {
+			// A new validator with an empty signature cache, so that all signatures are verified
+			validator := &transactionValidator{
+				sigCache:      txscript.NewSigCache(sigCacheSize),
+				sigCacheECDSA: txscript.NewSigCacheECDSA(sigCacheSize),
+			}
+			var inputScripts []inputScript
+			for txIndex, invalidInputs := range test.invalidInputs {
+				inputScripts = append(inputScripts, newInputScripts(byte(txIndex), invalidInputs...)...)
+			}
+
+			err := validator.validateInputScripts(inputScripts)
+			if test.isValid {
+				if err != nil {
+					t.Fatalf("%s: validateInputScripts: %+v", test.name, err)
+				}
+				continue
+			}
+			if !errors.Is(err, ruleerrors.ErrScriptValidation) {
+				t.Fatalf("%s: Expected ErrScriptValidation, but got: %+v", test.name, err)
+			}
+			expectedOutpoint := externalapi.DomainOutpoint{
+				TransactionID: *externalapi.NewDomainTransactionIDFromByteArray(&[32]byte{test.expectedFailingTx}),
+				Index:         uint32(test.expectedFailingInput),
+			}
+			expectedMessage := fmt.Sprintf("failed to validate input %d which references output %s",
+				test.expectedFailingInput, expectedOutpoint)
+			if !strings.Contains(err.Error(), expectedMessage) {
+				t.Fatalf("%s: Expected the error of input %s, but got: %s", test.name, expectedOutpoint, err)
+			}
+		}
+	}
+}
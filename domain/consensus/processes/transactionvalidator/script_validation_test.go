@@ -0,0 +1,143 @@
+package transactionvalidator_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/sedracoin/go-secp256k1"
+	"github.com/sedracoin/sedrad/domain/consensus"
+	"github.com/sedracoin/sedrad/domain/consensus/model"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/ruleerrors"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/constants"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/subnetworks"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/testutils"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/utxo"
+	"github.com/sedracoin/sedrad/util"
+)
+
+// TestValidateScriptsReportsFirstFailingInput makes sure that the scripts of transactions with
+// many inputs, which are validated in parallel, fail with the error of the first failing input
+func TestValidateScriptsReportsFirstFailingInput(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		factory := consensus.NewFactory()
+		tc, tearDown, err := factory.NewTestConsensus(consensusConfig,
+			"TestValidateScriptsReportsFirstFailingInput")
+		if err != nil {
+			t.Fatalf("Failed create a NewTestConsensus: %s", err)
+		}
+		defer tearDown(false)
+
+		privateKey, err := secp256k1.GenerateSchnorrKeyPair()
+		if err != nil {
+			t.Fatalf("Failed to generate a private key: %v", err)
+		}
+		publicKey, err := privateKey.SchnorrPublicKey()
+		if err != nil {
+			t.Fatalf("Failed to generate a public key: %v", err)
+		}
+		publicKeySerialized, err := publicKey.Serialize()
+		if err != nil {
+			t.Fatalf("Failed to serialize public key: %v", err)
+		}
+		addr, err := util.NewAddressPublicKey(publicKeySerialized[:], consensusConfig.Prefix)
+		if err != nil {
+			t.Fatalf("Failed to generate p2pk address: %v", err)
+		}
+		scriptPublicKey, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			t.Fatalf("PayToAddrScript: unexpected error: %v", err)
+		}
+
+		const inputCount = 16
+		const utxoDAAScore = 5
+		newTransaction := func(txIndex byte, invalidInputs ...int) *externalapi.DomainTransaction {
+			inputs := make([]*externalapi.DomainTransactionInput, inputCount)
+			for i := range inputs {
+				inputs[i] = &externalapi.DomainTransactionInput{
+					PreviousOutpoint: externalapi.DomainOutpoint{
+						TransactionID: *externalapi.NewDomainTransactionIDFromByteArray(&[32]byte{txIndex}),
+						Index:         uint32(i),
+					},
+					Sequence:   constants.MaxTxInSequenceNum,
+					SigOpCount: 1,
+					UTXOEntry:  utxo.NewUTXOEntry(100_000_000, scriptPublicKey, false, utxoDAAScore),
+				}
+			}
+			tx := &externalapi.DomainTransaction{
+				Version: constants.MaxTransactionVersion,
+				Inputs:  inputs,
+				Outputs: []*externalapi.DomainTransactionOutput{{
+					Value:           100_000_000,
+					ScriptPublicKey: scriptPublicKey,
+				}},
+				SubnetworkID: subnetworks.SubnetworkIDNative,
+				Payload:      []byte{},
+			}
+			for i, input := range tx.Inputs {
+				signatureScript, err := txscript.SignatureScript(tx, i, consensushashing.SigHashAll, privateKey,
+					&consensushashing.SighashReusedValues{})
+				if err != nil {
+					t.Fatalf("Failed to create a sigScript: %v", err)
+				}
+				input.SignatureScript = signatureScript
+			}
+			// The signature of another input is a valid signature over the wrong sighash
+			for _, i := range invalidInputs {
+				tx.Inputs[i].SignatureScript = tx.Inputs[(i+1)%inputCount].SignatureScript
+			}
+			return tx
+		}
+
+		stagingArea := model.NewStagingArea()
+		povBlockHash := externalapi.NewDomainHashFromByteArray(&[32]byte{0x01})
+		tc.DAABlocksStore().StageDAAScore(stagingArea, povBlockHash, consensusConfig.BlockCoinbaseMaturity+utxoDAAScore)
+
+		// Signatures that were validated once are cached, so every case uses new transactions
+		validTransaction := newTransaction(0)
+		err = tc.TransactionValidator().ValidateTransactionInContextAndPopulateFee(stagingArea, validTransaction, povBlockHash)
+		if err != nil {
+			t.Fatalf("ValidateTransactionInContextAndPopulateFee: %+v", err)
+		}
+
+		for i := 0; i < 10; i++ {
+			tx := newTransaction(byte(1+i), 5, 6, 12)
+			err = tc.TransactionValidator().ValidateTransactionInContextAndPopulateFee(stagingArea, tx, povBlockHash)
+			assertScriptValidationError(t, err, 5)
+		}
+
+		// The script errors of transactions that precede a transaction that fails other checks take precedence
+		txWithInvalidScripts := newTransaction(11, 3, 9)
+		txWithSpendTooHigh := newTransaction(12, 1)
+		txWithSpendTooHigh.Outputs[0].Value = 100_000_000*inputCount + 1
+		err = tc.TransactionValidator().ValidateTransactionsInContextAndPopulateFees(stagingArea,
+			[]*externalapi.DomainTransaction{newTransaction(13), txWithInvalidScripts, txWithSpendTooHigh}, povBlockHash)
+		assertScriptValidationError(t, err, 3)
+
+		err = tc.TransactionValidator().ValidateTransactionsInContextAndPopulateFees(stagingArea,
+			[]*externalapi.DomainTransaction{newTransaction(14), txWithSpendTooHigh, newTransaction(15, 0)}, povBlockHash)
+		if !errors.Is(err, ruleerrors.ErrSpendTooHigh) {
+			t.Fatalf("Expected ErrSpendTooHigh, but got: %+v", err)
+		}
+
+		err = tc.TransactionValidator().ValidateTransactionsInContextAndPopulateFees(stagingArea,
+			[]*externalapi.DomainTransaction{newTransaction(16), newTransaction(17)}, povBlockHash)
+		if err != nil {
+			t.Fatalf("ValidateTransactionsInContextAndPopulateFees: %+v", err)
+		}
+	})
+}
+
+func assertScriptValidationError(t *testing.T, err error, expectedInputIndex int) {
+	if !errors.Is(err, ruleerrors.ErrScriptValidation) {
+		t.Fatalf("Expected ErrScriptValidation, but got: %+v", err)
+	}
+	expectedMessage := fmt.Sprintf("failed to validate input %d ", expectedInputIndex)
+	if !strings.Contains(err.Error(), expectedMessage) {
+		t.Fatalf("Expected the error of input %d, but got: %s", expectedInputIndex, err)
+	}
+}
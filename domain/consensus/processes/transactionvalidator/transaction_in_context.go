@@ -6,7 +6,6 @@ import (
 	"github.com/sedracoin/sedrad/domain/consensus/model"
 	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
 	"github.com/sedracoin/sedrad/domain/consensus/ruleerrors"
-	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
 	"github.com/sedracoin/sedrad/domain/consensus/utils/constants"
 	"github.com/sedracoin/sedrad/domain/consensus/utils/transactionhelper"
 	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
@@ -68,39 +67,77 @@ func (v *transactionValidator) ValidateTransactionInContextIgnoringUTXO(stagingA
 func (v *transactionValidator) ValidateTransactionInContextAndPopulateFee(stagingArea *model.StagingArea,
 	tx *externalapi.DomainTransaction, povBlockHash *externalapi.DomainHash) error {
 
-	err := v.checkTransactionCoinbaseMaturity(stagingArea, povBlockHash, tx)
+	err := v.validateTransactionInContextIgnoringScriptsAndPopulateFee(stagingArea, tx, povBlockHash)
 	if err != nil {
 		return err
 	}
 
-	totalSeepIn, err := v.checkTransactionInputAmounts(tx)
+	return v.validateTransactionScripts(tx)
+}
+
+// ValidateTransactionsInContextAndPopulateFees validates the given transactions against their
+// referenced UTXOs, and populates their fee fields. The scripts of all the transactions are
+// validated together, so that transactions with few inputs are validated in parallel as well.
+// The returned error is the one ValidateTransactionInContextAndPopulateFee would return for
+// the first invalid transaction.
+//
+// Note: if the function fails, there's no guarantee that the transactions fee fields will remain unaffected.
+func (v *transactionValidator) ValidateTransactionsInContextAndPopulateFees(stagingArea *model.StagingArea,
+	txs []*externalapi.DomainTransaction, povBlockHash *externalapi.DomainHash) error {
+
+	// The scripts of a transaction are validated after the rest of its checks, so the
+	// scripts of the transaction that fails these checks don't matter
+	var firstFailure error
+	txsBeforeFailure := txs
+	for i, tx := range txs {
+		err := v.validateTransactionInContextIgnoringScriptsAndPopulateFee(stagingArea, tx, povBlockHash)
+		if err != nil {
+			firstFailure = err
+			txsBeforeFailure = txs[:i]
+			break
+		}
+	}
+
+	var inputScripts []inputScript
+	for _, tx := range txsBeforeFailure {
+		for i := range tx.Inputs {
+			inputScripts = append(inputScripts, inputScript{tx: tx, inputIndex: i})
+		}
+	}
+	err := v.validateInputScripts(inputScripts)
 	if err != nil {
 		return err
 	}
 
-	totalSeepOut, err := v.checkTransactionOutputAmounts(tx, totalSeepIn)
+	return firstFailure
+}
+
+func (v *transactionValidator) validateTransactionInContextIgnoringScriptsAndPopulateFee(stagingArea *model.StagingArea,
+	tx *externalapi.DomainTransaction, povBlockHash *externalapi.DomainHash) error {
+
+	err := v.checkTransactionCoinbaseMaturity(stagingArea, povBlockHash, tx)
 	if err != nil {
 		return err
 	}
 
-	tx.Fee = totalSeepIn - totalSeepOut
-
-	err = v.checkTransactionSequenceLock(stagingArea, povBlockHash, tx)
+	totalSeepIn, err := v.checkTransactionInputAmounts(tx)
 	if err != nil {
 		return err
 	}
 
-	err = v.validateTransactionSigOpCounts(tx)
+	totalSeepOut, err := v.checkTransactionOutputAmounts(tx, totalSeepIn)
 	if err != nil {
 		return err
 	}
 
-	err = v.validateTransactionScripts(tx)
+	tx.Fee = totalSeepIn - totalSeepOut
+
+	err = v.checkTransactionSequenceLock(stagingArea, povBlockHash, tx)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return v.validateTransactionSigOpCounts(tx)
 }
 
 func (v *transactionValidator) checkTransactionCoinbaseMaturity(stagingArea *model.StagingArea,
@@ -225,46 +262,6 @@ func (v *transactionValidator) checkTransactionSequenceLock(stagingArea *model.S
 	return nil
 }
 
-func (v *transactionValidator) validateTransactionScripts(tx *externalapi.DomainTransaction) error {
-	var missingOutpoints []*externalapi.DomainOutpoint
-	sighashReusedValues := &consensushashing.SighashReusedValues{}
-
-	for i, input := range tx.Inputs {
-		// Create a new script engine for the script pair.
-		sigScript := input.SignatureScript
-		utxoEntry := input.UTXOEntry
-		if utxoEntry == nil {
-			missingOutpoints = append(missingOutpoints, &input.PreviousOutpoint)
-			continue
-		}
-
-		scriptPubKey := utxoEntry.ScriptPublicKey()
-		vm, err := txscript.NewEngine(scriptPubKey, tx, i, txscript.ScriptNoFlags, v.sigCache, v.sigCacheECDSA, sighashReusedValues)
-		if err != nil {
-			return errors.Wrapf(ruleerrors.ErrScriptMalformed, "failed to parse input "+
-				"%d which references output %s - "+
-				"%s (input script bytes %x, prev "+
-				"output script bytes %x)",
-				i,
-				input.PreviousOutpoint, err, sigScript, scriptPubKey)
-		}
-
-		// Execute the script pair.
-		if err := vm.Execute(); err != nil {
-			return errors.Wrapf(ruleerrors.ErrScriptValidation, "failed to validate input "+
-				"%d which references output %s - "+
-				"%s (input script bytes %x, prev output "+
-				"script bytes %x)",
-				i,
-				input.PreviousOutpoint, err, sigScript, scriptPubKey)
-		}
-	}
-	if len(missingOutpoints) > 0 {
-		return ruleerrors.NewErrMissingTxOut(missingOutpoints)
-	}
-	return nil
-}
-
 func (v *transactionValidator) calcTxSequenceLockFromReferencedUTXOEntries(stagingArea *model.StagingArea,
 	povBlockHash *externalapi.DomainHash, tx *externalapi.DomainTransaction) (*sequenceLock, error) {
 
@@ -220,6 +220,10 @@ var (
 
 	ErrGetVirtualUTXOsWrongVirtualParents = newRuleError("ErrGetVirtualUTXOsWrongVirtualParents")
 
+	// ErrPrunedBlockUTXOSet indicates that the UTXO set of a block was requested after
+	// the data required to restore it had already been pruned.
+	ErrPrunedBlockUTXOSet = newRuleError("ErrPrunedBlockUTXOSet")
+
 	ErrVirtualGenesisParent = newRuleError("ErrVirtualGenesisParent")
 
 	ErrGenesisOnInitializedConsensus = newRuleError("ErrGenesisOnInitializedConsensus")
@@ -13,7 +13,6 @@ import (
 	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
 	"github.com/sedracoin/sedrad/domain/dagconfig"
 	"github.com/sedracoin/sedrad/infrastructure/db/database"
-	"github.com/pkg/errors"
 )
 
 type testConsensus struct {
@@ -130,80 +129,6 @@ func (tc *testConsensus) ResolveVirtualWithMaxParam(maxBlocksToResolve uint64) (
 	return tc.resolveVirtualChunkNoLock(maxBlocksToResolve)
 }
 
-// jsonBlock is a json representation of a block in mine format
-type jsonBlock struct {
-	ID      string   `json:"id"`
-	Parents []string `json:"parents"`
-}
-
-func (tc *testConsensus) MineJSON(r io.Reader, blockType testapi.MineJSONBlockType) (tips []*externalapi.DomainHash, err error) {
-	tipSet := map[externalapi.DomainHash]*externalapi.DomainHash{}
-	tipSet[*tc.dagParams.GenesisHash] = tc.dagParams.GenesisHash
-
-	parentsMap := make(map[string]*externalapi.DomainHash)
-	parentsMap["0"] = tc.dagParams.GenesisHash
-
-	decoder := json.NewDecoder(r)
-	// read open bracket
-	_, err = decoder.Token()
-	if err != nil {
-		return nil, err
-	}
-	// while the array contains values
-	for decoder.More() {
-		var block jsonBlock
-		// decode an array value (Message)
-		err := decoder.Decode(&block)
-		if err != nil {
-			return nil, err
-		}
-		if block.ID == "0" {
-			continue
-		}
-		parentHashes := make([]*externalapi.DomainHash, len(block.Parents))
-		var ok bool
-		for i, parentID := range block.Parents {
-			parentHashes[i], ok = parentsMap[parentID]
-			if !ok {
-				return nil, errors.Errorf("Couldn't find blockID: %s", parentID)
-			}
-			delete(tipSet, *parentHashes[i])
-		}
-
-		var blockHash *externalapi.DomainHash
-		switch blockType {
-		case testapi.MineJSONBlockTypeUTXOValidBlock:
-			blockHash, _, err = tc.AddBlock(parentHashes, nil, nil)
-			if err != nil {
-				return nil, err
-			}
-		case testapi.MineJSONBlockTypeUTXOInvalidBlock:
-			blockHash, _, err = tc.AddUTXOInvalidBlock(parentHashes)
-			if err != nil {
-				return nil, err
-			}
-		case testapi.MineJSONBlockTypeUTXOInvalidHeader:
-			blockHash, _, err = tc.AddUTXOInvalidHeader(parentHashes)
-			if err != nil {
-				return nil, err
-			}
-		default:
-			return nil, errors.Errorf("unknwon block type %v", blockType)
-		}
-
-		parentsMap[block.ID] = blockHash
-		tipSet[*blockHash] = blockHash
-	}
-
-	tips = make([]*externalapi.DomainHash, len(tipSet))
-	i := 0
-	for _, v := range tipSet {
-		tips[i] = v
-		i++
-	}
-	return tips, nil
-}
-
 func (tc *testConsensus) ToJSON(w io.Writer) error {
 	hashToID := make(map[externalapi.DomainHash]string)
 	lastID := 0
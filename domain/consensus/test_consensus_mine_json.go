@@ -0,0 +1,550 @@
+package consensus
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sedracoin/sedrad/domain/consensus/model"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/model/testapi"
+	"github.com/sedracoin/sedrad/domain/consensus/ruleerrors"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/constants"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/mining"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/pow"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/transactionhelper"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
+	"github.com/pkg/errors"
+)
+
+// jsonBlock is a json representation of an entry in mine format. An entry is either
+// a block, the definition of a named transaction, or assertions on the state of the DAG.
+type jsonBlock struct {
+	ID      string   `json:"id"`
+	Parents []string `json:"parents"`
+
+	// Transactions are the names of the transactions the block includes, in addition to its coinbase
+	Transactions []string `json:"transactions,omitempty"`
+
+	// Invalid makes the block invalid in the given way. See the jsonBlockInvalid... constants.
+	Invalid string `json:"invalid,omitempty"`
+
+	// ExpectedError is the name of the rule error the block is expected to be rejected with
+	ExpectedError string `json:"expectedError,omitempty"`
+
+	Transaction *jsonTransaction `json:"transaction,omitempty"`
+	Assert      *jsonAssertion   `json:"assert,omitempty"`
+}
+
+// jsonTransaction is a json representation of a named transaction. Its outputs pay to an
+// anyone-can-spend script, and can be spent by later transactions as "<name>:<index>".
+// The coinbase transaction of a block is named by the ID of the block.
+type jsonTransaction struct {
+	ID     string   `json:"id"`
+	Inputs []string `json:"inputs"`
+
+	// Outputs are the values of the outputs of the transaction. If there are none, the
+	// transaction has a single output with the value of its inputs minus Fee.
+	Outputs []uint64 `json:"outputs,omitempty"`
+	Fee     uint64   `json:"fee,omitempty"`
+}
+
+// jsonAssertion is a json representation of assertions on the state of the DAG
+type jsonAssertion struct {
+	VirtualParents        []string          `json:"virtualParents,omitempty"`
+	VirtualSelectedParent string            `json:"virtualSelectedParent,omitempty"`
+	BlockStatus           map[string]string `json:"blockStatus,omitempty"`
+	BlueScore             map[string]uint64 `json:"blueScore,omitempty"`
+
+	// AcceptedTransactions are the names of the non-coinbase transactions accepted by each block
+	AcceptedTransactions map[string][]string `json:"acceptedTransactions,omitempty"`
+
+	// UTXOs maps outputs, in the form "<name>:<index>", to whether they're in the virtual UTXO set
+	UTXOs map[string]bool `json:"utxos,omitempty"`
+}
+
+const (
+	jsonBlockInvalidBadProofOfWork          = "badProofOfWork"
+	jsonBlockInvalidUTXOInvalid             = "utxoInvalid"
+	jsonBlockInvalidUTXOInvalidHeader       = "utxoInvalidHeader"
+	jsonBlockInvalidTimestampTooOld         = "timestampTooOld"
+	jsonBlockInvalidTimestampTooFarInFuture = "timestampTooFarInFuture"
+)
+
+// mineJSONState is the state of the DAG that MineJSON builds
+type mineJSONState struct {
+	tc        *testConsensus
+	blockType testapi.MineJSONBlockType
+	random    *rand.Rand
+
+	tipSet       map[externalapi.DomainHash]*externalapi.DomainHash
+	blockHashes  map[string]*externalapi.DomainHash
+	blockIDs     map[externalapi.DomainHash]string
+	transactions map[string]*externalapi.DomainTransaction
+	names        map[externalapi.DomainTransactionID]string
+}
+
+func (tc *testConsensus) MineJSON(r io.Reader, blockType testapi.MineJSONBlockType) (tips []*externalapi.DomainHash, err error) {
+	state := &mineJSONState{
+		tc:           tc,
+		blockType:    blockType,
+		random:       rand.New(rand.NewSource(0)),
+		tipSet:       map[externalapi.DomainHash]*externalapi.DomainHash{},
+		blockHashes:  make(map[string]*externalapi.DomainHash),
+		blockIDs:     make(map[externalapi.DomainHash]string),
+		transactions: make(map[string]*externalapi.DomainTransaction),
+		names:        make(map[externalapi.DomainTransactionID]string),
+	}
+	state.addBlockID("0", tc.dagParams.GenesisHash, tc.dagParams.GenesisBlock)
+	state.tipSet[*tc.dagParams.GenesisHash] = tc.dagParams.GenesisHash
+
+	decoder := json.NewDecoder(r)
+	// read open bracket
+	_, err = decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	// while the array contains values
+	for i := 0; decoder.More(); i++ {
+		var entry jsonBlock
+		// decode an array value (Message)
+		err := decoder.Decode(&entry)
+		if err != nil {
+			return nil, err
+		}
+		err = state.processEntry(&entry)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error in entry #%d", i)
+		}
+	}
+
+	tips = make([]*externalapi.DomainHash, len(state.tipSet))
+	i := 0
+	for _, v := range state.tipSet {
+		tips[i] = v
+		i++
+	}
+	return tips, nil
+}
+
+func (s *mineJSONState) processEntry(entry *jsonBlock) error {
+	switch {
+	case entry.Transaction != nil:
+		return s.addTransaction(entry.Transaction)
+	case entry.Assert != nil:
+		return s.assert(entry.Assert)
+	case entry.ID == "0":
+		return nil
+	default:
+		return s.addBlock(entry)
+	}
+}
+
+func (s *mineJSONState) addBlockID(id string, blockHash *externalapi.DomainHash, block *externalapi.DomainBlock) {
+	s.blockHashes[id] = blockHash
+	s.blockIDs[*blockHash] = id
+	if len(block.Transactions) > transactionhelper.CoinbaseTransactionIndex {
+		coinbase := block.Transactions[transactionhelper.CoinbaseTransactionIndex]
+		s.transactions[id] = coinbase
+		s.names[*consensushashing.TransactionID(coinbase)] = id
+	}
+}
+
+func (s *mineJSONState) addBlock(entry *jsonBlock) error {
+	if _, ok := s.blockHashes[entry.ID]; ok {
+		return errors.Errorf("block %s is already defined", entry.ID)
+	}
+	parentHashes, err := s.blockHashesByIDs(entry.Parents)
+	if err != nil {
+		return err
+	}
+	transactions := make([]*externalapi.DomainTransaction, len(entry.Transactions))
+	for i, name := range entry.Transactions {
+		transaction, ok := s.transactions[name]
+		if !ok {
+			return errors.Errorf("couldn't find transaction %s", name)
+		}
+		transactions[i] = transaction.Clone()
+	}
+
+	invalid := entry.Invalid
+	if invalid == "" {
+		switch s.blockType {
+		case testapi.MineJSONBlockTypeUTXOValidBlock:
+		case testapi.MineJSONBlockTypeUTXOInvalidBlock:
+			invalid = jsonBlockInvalidUTXOInvalid
+		case testapi.MineJSONBlockTypeUTXOInvalidHeader:
+			invalid = jsonBlockInvalidUTXOInvalidHeader
+		default:
+			return errors.Errorf("unknwon block type %v", s.blockType)
+		}
+	}
+
+	block, err := s.buildBlock(parentHashes, transactions, invalid)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't build block %s", entry.ID)
+	}
+	blockHash := consensushashing.BlockHash(block)
+
+	err = s.tc.ValidateAndInsertBlock(block, true)
+	if entry.ExpectedError != "" {
+		if err == nil {
+			return errors.Errorf("block %s was expected to be rejected with %s but it was accepted",
+				entry.ID, entry.ExpectedError)
+		}
+		if !isRuleError(err, entry.ExpectedError) {
+			return errors.Wrapf(err, "block %s was expected to be rejected with %s, but got another error",
+				entry.ID, entry.ExpectedError)
+		}
+		// The block can still be referred to by assertions
+		s.addBlockID(entry.ID, blockHash, block)
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "block %s was rejected", entry.ID)
+	}
+
+	s.addBlockID(entry.ID, blockHash, block)
+	for _, parentHash := range parentHashes {
+		delete(s.tipSet, *parentHash)
+	}
+	s.tipSet[*blockHash] = blockHash
+	return nil
+}
+
+func (s *mineJSONState) buildBlock(parentHashes []*externalapi.DomainHash,
+	transactions []*externalapi.DomainTransaction, invalid string) (*externalapi.DomainBlock, error) {
+
+	var block *externalapi.DomainBlock
+	var err error
+	switch invalid {
+	case "", jsonBlockInvalidBadProofOfWork, jsonBlockInvalidTimestampTooOld, jsonBlockInvalidTimestampTooFarInFuture:
+		block, _, err = s.tc.BuildBlockWithParents(parentHashes, nil, transactions)
+	case jsonBlockInvalidUTXOInvalid:
+		if len(transactions) > 0 {
+			return nil, errors.New("UTXO invalid blocks can't include transactions")
+		}
+		block, err = s.tc.BuildUTXOInvalidBlock(parentHashes)
+	case jsonBlockInvalidUTXOInvalidHeader:
+		if len(transactions) > 0 {
+			return nil, errors.New("UTXO invalid headers can't include transactions")
+		}
+		var header externalapi.BlockHeader
+		header, err = s.tc.BuildHeaderWithParents(parentHashes)
+		block = &externalapi.DomainBlock{Header: header}
+	default:
+		return nil, errors.Errorf("unknown kind of invalid block %s", invalid)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch invalid {
+	case jsonBlockInvalidTimestampTooOld:
+		header := block.Header.ToMutable()
+		header.SetTimeInMilliseconds(0)
+		block.Header = header.ToImmutable()
+	case jsonBlockInvalidTimestampTooFarInFuture:
+		maxTimestamp := time.Now().UnixMilli() +
+			int64(s.tc.dagParams.TimestampDeviationTolerance)*s.tc.dagParams.TargetTimePerBlock.Milliseconds()
+		header := block.Header.ToMutable()
+		header.SetTimeInMilliseconds(maxTimestamp + time.Minute.Milliseconds())
+		block.Header = header.ToImmutable()
+	}
+
+	if invalid == jsonBlockInvalidBadProofOfWork {
+		solveBlockWithWrongPoW(block)
+	} else if !s.tc.dagParams.SkipProofOfWork {
+		mining.SolveBlock(block, s.random)
+	}
+	return block, nil
+}
+
+// solveBlockWithWrongPoW sets the nonce of the block to the first nonce that doesn't satisfy its target
+func solveBlockWithWrongPoW(block *externalapi.DomainBlock) {
+	header := block.Header.ToMutable()
+	state := pow.NewState(header)
+	for state.Nonce = 0; state.Nonce < math.MaxUint64; state.Nonce++ {
+		if !state.CheckProofOfWork() {
+			header.SetNonce(state.Nonce)
+			block.Header = header.ToImmutable()
+			return
+		}
+	}
+
+	panic(errors.New("went over all the nonce space and couldn't find a single one that gives an invalid block"))
+}
+
+// isRuleError returns whether err is the rule error with the given name, or is caused by
+// a transaction that's invalid because of it
+func isRuleError(err error, name string) bool {
+	var ruleError ruleerrors.RuleError
+	if !errors.As(err, &ruleError) {
+		return false
+	}
+	if ruleErrorName(ruleError) == name {
+		return true
+	}
+
+	var invalidTransactionsError ruleerrors.ErrInvalidTransactionsInNewBlock
+	if errors.As(err, &invalidTransactionsError) {
+		for _, invalidTransaction := range invalidTransactionsError.InvalidTransactions {
+			if invalidTransaction.Error != nil && ruleErrorName(*invalidTransaction.Error) == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ruleErrorName returns the name of a rule error, which is the beginning of its message
+func ruleErrorName(ruleError ruleerrors.RuleError) string {
+	return strings.SplitN(ruleError.Error(), ":", 2)[0]
+}
+
+func (s *mineJSONState) addTransaction(jsonTransaction *jsonTransaction) error {
+	if _, ok := s.transactions[jsonTransaction.ID]; ok {
+		return errors.Errorf("transaction %s is already defined", jsonTransaction.ID)
+	}
+
+	redeemScript := []byte{txscript.OpTrue}
+	scriptPublicKeyScript, err := txscript.PayToScriptHashScript(redeemScript)
+	if err != nil {
+		return err
+	}
+	scriptPublicKey := &externalapi.ScriptPublicKey{Script: scriptPublicKeyScript, Version: constants.MaxScriptPublicKeyVersion}
+	signatureScript, err := txscript.PayToScriptHashSignatureScript(redeemScript, nil)
+	if err != nil {
+		return err
+	}
+
+	inputs := make([]*externalapi.DomainTransactionInput, len(jsonTransaction.Inputs))
+	inputsValue := uint64(0)
+	for i, reference := range jsonTransaction.Inputs {
+		outpoint, output, err := s.output(reference)
+		if err != nil {
+			return err
+		}
+		inputsValue += output.Value
+		inputs[i] = &externalapi.DomainTransactionInput{
+			PreviousOutpoint: *outpoint,
+			SignatureScript:  signatureScript,
+			Sequence:         constants.MaxTxInSequenceNum,
+		}
+	}
+
+	outputValues := jsonTransaction.Outputs
+	if len(outputValues) == 0 {
+		if jsonTransaction.Fee > inputsValue {
+			return errors.Errorf("the fee of transaction %s is higher than the value of its inputs",
+				jsonTransaction.ID)
+		}
+		outputValues = []uint64{inputsValue - jsonTransaction.Fee}
+	}
+	outputs := make([]*externalapi.DomainTransactionOutput, len(outputValues))
+	for i, value := range outputValues {
+		outputs[i] = &externalapi.DomainTransactionOutput{
+			ScriptPublicKey: scriptPublicKey,
+			Value:           value,
+		}
+	}
+
+	transaction := &externalapi.DomainTransaction{
+		Version: constants.MaxTransactionVersion,
+		Inputs:  inputs,
+		Outputs: outputs,
+		Payload: []byte{},
+	}
+	s.transactions[jsonTransaction.ID] = transaction
+	s.names[*consensushashing.TransactionID(transaction)] = jsonTransaction.ID
+	return nil
+}
+
+// output returns the outpoint and the output referred to by "<name>:<index>"
+func (s *mineJSONState) output(reference string) (*externalapi.DomainOutpoint, *externalapi.DomainTransactionOutput, error) {
+	separatorIndex := strings.LastIndex(reference, ":")
+	if separatorIndex == -1 {
+		return nil, nil, errors.Errorf("output %s is not in the form <transaction>:<index>", reference)
+	}
+	name := reference[:separatorIndex]
+	index, err := strconv.ParseUint(reference[separatorIndex+1:], 10, 32)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "output %s has an invalid index", reference)
+	}
+
+	transaction, ok := s.transactions[name]
+	if !ok {
+		return nil, nil, errors.Errorf("couldn't find transaction %s", name)
+	}
+	if index >= uint64(len(transaction.Outputs)) {
+		return nil, nil, errors.Errorf("transaction %s has no output %d", name, index)
+	}
+	outpoint := &externalapi.DomainOutpoint{
+		TransactionID: *consensushashing.TransactionID(transaction),
+		Index:         uint32(index),
+	}
+	return outpoint, transaction.Outputs[index], nil
+}
+
+func (s *mineJSONState) blockHashesByIDs(ids []string) ([]*externalapi.DomainHash, error) {
+	blockHashes := make([]*externalapi.DomainHash, len(ids))
+	for i, id := range ids {
+		blockHash, ok := s.blockHashes[id]
+		if !ok {
+			return nil, errors.Errorf("Couldn't find blockID: %s", id)
+		}
+		blockHashes[i] = blockHash
+	}
+	return blockHashes, nil
+}
+
+func (s *mineJSONState) blockID(blockHash *externalapi.DomainHash) string {
+	id, ok := s.blockIDs[*blockHash]
+	if !ok {
+		return blockHash.String()
+	}
+	return id
+}
+
+func (s *mineJSONState) assert(assertion *jsonAssertion) error {
+	if assertion.VirtualParents != nil {
+		virtualInfo, err := s.tc.GetVirtualInfo()
+		if err != nil {
+			return err
+		}
+		virtualParents := make([]string, len(virtualInfo.ParentHashes))
+		for i, parentHash := range virtualInfo.ParentHashes {
+			virtualParents[i] = s.blockID(parentHash)
+		}
+		if !sameStrings(virtualParents, assertion.VirtualParents) {
+			return errors.Errorf("expected the virtual parents to be %v but got %v",
+				assertion.VirtualParents, virtualParents)
+		}
+	}
+
+	if assertion.VirtualSelectedParent != "" {
+		virtualSelectedParent, err := s.tc.GetVirtualSelectedParent()
+		if err != nil {
+			return err
+		}
+		if s.blockID(virtualSelectedParent) != assertion.VirtualSelectedParent {
+			return errors.Errorf("expected the virtual selected parent to be %s but got %s",
+				assertion.VirtualSelectedParent, s.blockID(virtualSelectedParent))
+		}
+	}
+
+	for id, expectedStatus := range assertion.BlockStatus {
+		blockInfo, err := s.blockInfo(id)
+		if err != nil {
+			return err
+		}
+		if blockInfo.BlockStatus.String() != expectedStatus {
+			return errors.Errorf("expected the status of block %s to be %s but got %s",
+				id, expectedStatus, blockInfo.BlockStatus)
+		}
+	}
+
+	for id, expectedBlueScore := range assertion.BlueScore {
+		blockInfo, err := s.blockInfo(id)
+		if err != nil {
+			return err
+		}
+		if blockInfo.BlueScore != expectedBlueScore {
+			return errors.Errorf("expected the blue score of block %s to be %d but got %d",
+				id, expectedBlueScore, blockInfo.BlueScore)
+		}
+	}
+
+	for id, expectedAcceptedTransactions := range assertion.AcceptedTransactions {
+		acceptedTransactions, err := s.acceptedTransactions(id)
+		if err != nil {
+			return err
+		}
+		if !sameStrings(acceptedTransactions, expectedAcceptedTransactions) {
+			return errors.Errorf("expected block %s to accept the transactions %v but it accepted %v",
+				id, expectedAcceptedTransactions, acceptedTransactions)
+		}
+	}
+
+	for reference, expectedHasUTXO := range assertion.UTXOs {
+		outpoint, _, err := s.output(reference)
+		if err != nil {
+			return err
+		}
+		hasUTXO, err := s.tc.consensusStateStore.HasUTXOByOutpoint(s.tc.databaseContext, model.NewStagingArea(), outpoint)
+		if err != nil {
+			return err
+		}
+		if hasUTXO != expectedHasUTXO {
+			return errors.Errorf("expected the presence of output %s in the virtual UTXO set to be %t but got %t",
+				reference, expectedHasUTXO, hasUTXO)
+		}
+	}
+
+	return nil
+}
+
+func (s *mineJSONState) blockInfo(id string) (*externalapi.BlockInfo, error) {
+	blockHash, ok := s.blockHashes[id]
+	if !ok {
+		return nil, errors.Errorf("Couldn't find blockID: %s", id)
+	}
+	blockInfo, err := s.tc.GetBlockInfo(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if !blockInfo.Exists {
+		return nil, errors.Errorf("block %s doesn't exist", id)
+	}
+	return blockInfo, nil
+}
+
+func (s *mineJSONState) acceptedTransactions(id string) ([]string, error) {
+	blockHash, ok := s.blockHashes[id]
+	if !ok {
+		return nil, errors.Errorf("Couldn't find blockID: %s", id)
+	}
+	acceptanceData, err := s.tc.GetBlockAcceptanceData(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	acceptedTransactions := []string{}
+	for _, blockAcceptanceData := range acceptanceData {
+		for _, transactionAcceptanceData := range blockAcceptanceData.TransactionAcceptanceData {
+			if !transactionAcceptanceData.IsAccepted ||
+				transactionhelper.IsCoinBase(transactionAcceptanceData.Transaction) {
+				continue
+			}
+			transactionID := consensushashing.TransactionID(transactionAcceptanceData.Transaction)
+			name, ok := s.names[*transactionID]
+			if !ok {
+				name = transactionID.String()
+			}
+			acceptedTransactions = append(acceptedTransactions, name)
+		}
+	}
+	return acceptedTransactions, nil
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sortedA := append([]string{}, a...)
+	sortedB := append([]string{}, b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+	for i := range sortedA {
+		if sortedA[i] != sortedB[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -16,6 +16,7 @@ const (
 	proofOfWorkDomain             = "ProofOfWorkHash"
 	heavyHashDomain               = "HeavyHash"
 	merkleBranchDomain            = "MerkleBranchHash"
+	personalMessageSigningDomain  = "PersonalMessageSigningHash"
 )
 
 // transactionSigningECDSADomainHash is a hashed version of transcationSigningECDSADomain that is used
@@ -86,3 +87,12 @@ func NewMerkleBranchHashWriter() HashWriter {
 	}
 	return HashWriter{blake}
 }
+
+// NewPersonalMessageSigningHashWriter Returns a new HashWriter used for signing on an arbitrary message
+func NewPersonalMessageSigningHashWriter() HashWriter {
+	blake, err := blake2b.New256([]byte(personalMessageSigningDomain))
+	if err != nil {
+		panic(errors.Wrapf(err, "this should never happen. %s is less than 64 bytes", personalMessageSigningDomain))
+	}
+	return HashWriter{blake}
+}
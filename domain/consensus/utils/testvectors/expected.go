@@ -0,0 +1,156 @@
+package testvectors
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/domain/consensus"
+	"github.com/sedracoin/sedrad/domain/consensus/model"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/model/testapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/hashes"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/multiset"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/transactionhelper"
+	"github.com/sedracoin/sedrad/domain/dagconfig"
+)
+
+// newConsensusConfig returns the config of the consensus fixtures are generated and replayed
+// with. Fixtures are about the consensus rules rather than about proof of work, so it's skipped.
+func newConsensusConfig() *consensus.Config {
+	consensusConfig := &consensus.Config{Params: dagconfig.DevnetParams}
+	consensusConfig.SkipProofOfWork = true
+	return consensusConfig
+}
+
+func paramsOf(consensusConfig *consensus.Config) *Params {
+	return &Params{
+		Network:                        consensusConfig.Name,
+		GenesisHash:                    consensusConfig.GenesisHash.String(),
+		K:                              consensusConfig.K,
+		BlockCoinbaseMaturity:          consensusConfig.BlockCoinbaseMaturity,
+		DifficultyAdjustmentWindowSize: consensusConfig.DifficultyAdjustmentWindowSize,
+		TargetTimePerBlockMilliseconds: consensusConfig.TargetTimePerBlock.Milliseconds(),
+		MergeSetSizeLimit:              consensusConfig.MergeSetSizeLimit,
+	}
+}
+
+func blockData(tc testapi.TestConsensus, blockHash *externalapi.DomainHash) (*BlockData, error) {
+	stagingArea := model.NewStagingArea()
+
+	blockInfo, err := tc.GetBlockInfo(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if !blockInfo.Exists {
+		return nil, errors.Errorf("block %s doesn't exist", blockHash)
+	}
+
+	ghostdagData, err := tc.GHOSTDAGDataStore().Get(tc.DatabaseContext(), stagingArea, blockHash, false)
+	if err != nil {
+		return nil, err
+	}
+	bluesAnticoneSizes := make(map[string]externalapi.KType, len(ghostdagData.BluesAnticoneSizes()))
+	for blueHash, anticoneSize := range ghostdagData.BluesAnticoneSizes() {
+		bluesAnticoneSizes[blueHash.String()] = anticoneSize
+	}
+
+	daaScore, err := tc.DAABlocksStore().DAAScore(tc.DatabaseContext(), stagingArea, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	bits, err := tc.DifficultyManager().RequiredDifficulty(stagingArea, blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &BlockData{
+		Status: blockInfo.BlockStatus.String(),
+		GHOSTDAG: &GHOSTDAGData{
+			BlueScore:          ghostdagData.BlueScore(),
+			BlueWork:           ghostdagData.BlueWork().Text(16),
+			SelectedParent:     ghostdagData.SelectedParent().String(),
+			MergeSetBlues:      hashes.ToStrings(ghostdagData.MergeSetBlues()),
+			MergeSetReds:       hashes.ToStrings(ghostdagData.MergeSetReds()),
+			BluesAnticoneSizes: bluesAnticoneSizes,
+		},
+		DAAScore: daaScore,
+		Bits:     bits,
+	}
+	if blockInfo.BlockStatus != externalapi.StatusUTXOValid {
+		return data, nil
+	}
+
+	block, found, err := tc.GetBlock(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.Errorf("block %s wasn't found", blockHash)
+	}
+	_, coinbaseData, _, err := tc.CoinbaseManager().ExtractCoinbaseDataBlueScoreAndSubsidy(
+		block.Transactions[transactionhelper.CoinbaseTransactionIndex])
+	if err != nil {
+		return nil, err
+	}
+	coinbaseTransaction, _, err := tc.CoinbaseManager().ExpectedCoinbaseTransaction(stagingArea, blockHash, coinbaseData)
+	if err != nil {
+		return nil, err
+	}
+	data.CoinbaseTransaction = appmessage.DomainTransactionToRPCTransaction(coinbaseTransaction)
+
+	utxoMultiset, err := tc.MultisetStore().Get(tc.DatabaseContext(), stagingArea, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	data.UTXOCommitment = utxoMultiset.Hash().String()
+
+	return data, nil
+}
+
+func virtualData(tc testapi.TestConsensus) (*VirtualData, error) {
+	virtualInfo, err := tc.GetVirtualInfo()
+	if err != nil {
+		return nil, err
+	}
+	virtualSelectedParent, err := tc.GetVirtualSelectedParent()
+	if err != nil {
+		return nil, err
+	}
+
+	utxoMultiset := multiset.New()
+	var fromOutpoint *externalapi.DomainOutpoint
+	for {
+		const step = 1000
+		virtualUTXOs, err := tc.GetVirtualUTXOs(virtualInfo.ParentHashes, fromOutpoint, step)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, outpointAndUTXOEntryPair := range virtualUTXOs {
+			// Depending on the database, a page may start with the last outpoint of the previous one
+			if fromOutpoint != nil && outpointAndUTXOEntryPair.Outpoint.Equal(fromOutpoint) {
+				continue
+			}
+			err := tc.ConsensusStateManager().AddUTXOToMultiset(utxoMultiset,
+				outpointAndUTXOEntryPair.UTXOEntry, outpointAndUTXOEntryPair.Outpoint)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if len(virtualUTXOs) < step {
+			break
+		}
+
+		fromOutpoint = virtualUTXOs[len(virtualUTXOs)-1].Outpoint
+	}
+
+	return &VirtualData{
+		Parents:        hashes.ToStrings(virtualInfo.ParentHashes),
+		SelectedParent: virtualSelectedParent.String(),
+		BlueScore:      virtualInfo.BlueScore,
+		DAAScore:       virtualInfo.DAAScore,
+		Bits:           virtualInfo.Bits,
+		PastMedianTime: virtualInfo.PastMedianTime,
+		UTXOCommitment: utxoMultiset.Hash().String(),
+	}, nil
+}
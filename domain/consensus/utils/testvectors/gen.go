@@ -0,0 +1,52 @@
+//go:build ignore
+
+// This program regenerates the fixtures in domain/consensus/testdata/vectors from their sources.
+// Run it with `go generate` after intentionally changing the consensus rules, and review the
+// fixture diffs.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sedracoin/sedrad/domain/consensus/utils/testvectors"
+)
+
+func main() {
+	err := generate()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%+v\n", err)
+		os.Exit(1)
+	}
+}
+
+func generate() error {
+	sourcePaths, err := filepath.Glob(filepath.Join(testvectors.SourcesDirectory, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, sourcePath := range sourcePaths {
+		source, err := testvectors.ReadSource(sourcePath)
+		if err != nil {
+			return err
+		}
+		fixture, err := testvectors.Generate(source)
+		if err != nil {
+			return err
+		}
+		fixtureJSON, err := testvectors.Marshal(fixture)
+		if err != nil {
+			return err
+		}
+
+		fixturePath := testvectors.FixturePath(sourcePath)
+		err = os.WriteFile(fixturePath, fixtureJSON, 0644)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Generated %s with %d blocks\n", fixturePath, len(fixture.Blocks))
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package testvectors
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+	"github.com/sedracoin/sedrad/domain/consensus"
+	"github.com/sedracoin/sedrad/domain/consensus/model"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/model/testapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/hashset"
+)
+
+// Generate mines the DAG of the given source, and returns it as a fixture along with
+// the data consensus calculates for it
+func Generate(source *Source) (*Fixture, error) {
+	consensusConfig := newConsensusConfig()
+	if source.Params.K != nil {
+		consensusConfig.K = *source.Params.K
+	}
+	if source.Params.BlockCoinbaseMaturity != nil {
+		consensusConfig.BlockCoinbaseMaturity = *source.Params.BlockCoinbaseMaturity
+	}
+	if source.Params.DifficultyAdjustmentWindowSize != nil {
+		consensusConfig.DifficultyAdjustmentWindowSize = *source.Params.DifficultyAdjustmentWindowSize
+	}
+
+	tc, teardown, err := consensus.NewFactory().NewTestConsensus(consensusConfig, "GenerateTestVectors")
+	if err != nil {
+		return nil, err
+	}
+	defer teardown(false)
+
+	_, err = tc.MineJSON(bytes.NewReader(source.Steps), testapi.MineJSONBlockTypeUTXOValidBlock)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't mine the DAG of %q", source.Description)
+	}
+
+	blocks, err := blocksInInsertionOrder(tc)
+	if err != nil {
+		return nil, err
+	}
+	return fixtureOf(consensusConfig, source.Description, blocks)
+}
+
+// blocksInInsertionOrder returns all the blocks in the DAG except for the genesis,
+// ordered so that every block comes after its parents
+func blocksInInsertionOrder(tc testapi.TestConsensus) ([]*externalapi.DomainBlock, error) {
+	stagingArea := model.NewStagingArea()
+	genesisHash := tc.DAGParams().GenesisHash
+
+	visited := hashset.New()
+	queue := tc.DAGTraversalManager().NewUpHeap(stagingArea)
+	err := queue.Push(genesisHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks []*externalapi.DomainBlock
+	for queue.Len() > 0 {
+		current := queue.Pop()
+		if visited.Contains(current) || current.Equal(model.VirtualBlockHash) {
+			continue
+		}
+		visited.Add(current)
+		if !current.Equal(genesisHash) {
+			block, found, err := tc.GetBlock(current)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				return nil, errors.Errorf("block %s wasn't found", current)
+			}
+			blocks = append(blocks, block)
+		}
+
+		children, err := tc.DAGTopologyManager().Children(stagingArea, current)
+		if err != nil {
+			return nil, err
+		}
+		err = queue.PushSlice(children)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return blocks, nil
+}
@@ -0,0 +1,111 @@
+package testvectors
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/domain/consensus"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+)
+
+// Replay adds the blocks of the given fixture to a new consensus, and returns an error
+// if consensus rejects any of them or calculates different data than the fixture expects
+func Replay(fixture *Fixture) error {
+	consensusConfig := newConsensusConfig()
+	consensusConfig.K = fixture.Params.K
+	consensusConfig.BlockCoinbaseMaturity = fixture.Params.BlockCoinbaseMaturity
+	consensusConfig.DifficultyAdjustmentWindowSize = fixture.Params.DifficultyAdjustmentWindowSize
+	err := compare("params", fixture.Params, paramsOf(consensusConfig))
+	if err != nil {
+		return err
+	}
+
+	blocks := make([]*externalapi.DomainBlock, len(fixture.Blocks))
+	for i, fixtureBlock := range fixture.Blocks {
+		blocks[i], err = appmessage.RPCBlockToDomainBlock(fixtureBlock.Block)
+		if err != nil {
+			return errors.Wrapf(err, "couldn't parse block #%d", i)
+		}
+	}
+
+	actual, err := fixtureOf(consensusConfig, fixture.Description, blocks)
+	if err != nil {
+		return err
+	}
+	for i, fixtureBlock := range fixture.Blocks {
+		if actual.Blocks[i].Hash != fixtureBlock.Hash {
+			return errors.Errorf("block #%d is expected to have the hash %s, but has the hash %s",
+				i, fixtureBlock.Hash, actual.Blocks[i].Hash)
+		}
+		err = compare("data of block "+fixtureBlock.Hash, fixtureBlock.Expected, actual.Blocks[i].Expected)
+		if err != nil {
+			return err
+		}
+	}
+	return compare("data of the virtual", fixture.Virtual, actual.Virtual)
+}
+
+// fixtureOf adds the given blocks to a new consensus in their order, and returns them as a fixture
+// along with the data consensus calculated for them. Since the status of a block depends on the blocks
+// that are added after it, the data of the blocks is collected once all of them are added.
+func fixtureOf(consensusConfig *consensus.Config, description string,
+	blocks []*externalapi.DomainBlock) (*Fixture, error) {
+
+	tc, teardown, err := consensus.NewFactory().NewTestConsensus(consensusConfig, "TestVectors")
+	if err != nil {
+		return nil, err
+	}
+	defer teardown(false)
+
+	for _, block := range blocks {
+		err := tc.ValidateAndInsertBlock(block, true)
+		if err != nil {
+			return nil, errors.Wrapf(err, "block %s was rejected", consensushashing.BlockHash(block))
+		}
+	}
+
+	fixtureBlocks := make([]*Block, len(blocks))
+	for i, block := range blocks {
+		blockHash := consensushashing.BlockHash(block)
+		expected, err := blockData(tc, blockHash)
+		if err != nil {
+			return nil, err
+		}
+		fixtureBlocks[i] = &Block{
+			Hash:     blockHash.String(),
+			Block:    appmessage.DomainBlockToRPCBlock(block),
+			Expected: expected,
+		}
+	}
+
+	virtual, err := virtualData(tc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Fixture{
+		Description: description,
+		Params:      paramsOf(consensusConfig),
+		Blocks:      fixtureBlocks,
+		Virtual:     virtual,
+	}, nil
+}
+
+// compare compares expected and actual by their JSON, which is what fixtures keep
+func compare(name string, expected interface{}, actual interface{}) error {
+	expectedJSON, err := json.MarshalIndent(expected, "", "  ")
+	if err != nil {
+		return err
+	}
+	actualJSON, err := json.MarshalIndent(actual, "", "  ")
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(expectedJSON, actualJSON) {
+		return nil
+	}
+	return errors.Errorf("unexpected %s. Expected:\n%s\nGot:\n%s", name, expectedJSON, actualJSON)
+}
@@ -0,0 +1,142 @@
+// Package testvectors generates consensus test vectors, which are JSON fixtures of DAGs along
+// with the data that consensus is expected to calculate for them, and replays them against
+// consensus.
+//
+// The fixtures are meant to be shared with other implementations of the consensus rules,
+// so they only use JSON types and the RPC representation of blocks and transactions.
+// See domain/consensus/testdata/vectors/README.md for their format.
+//
+//go:generate go run gen.go
+package testvectors
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+)
+
+const (
+	// FixturesDirectory is the directory of the fixtures, relative to this package
+	FixturesDirectory = "../../testdata/vectors"
+
+	// SourcesDirectory is the directory of the sources of the fixtures, relative to this package
+	SourcesDirectory = FixturesDirectory + "/sources"
+)
+
+// FixturePath returns the path of the fixture generated from the source in the given path
+func FixturePath(sourcePath string) string {
+	return filepath.Join(FixturesDirectory, filepath.Base(sourcePath))
+}
+
+// Source is a DAG to generate a fixture from. Steps are in the format that
+// TestConsensus.MineJSON reads.
+type Source struct {
+	Description string `json:"description"`
+	Params      struct {
+		K                              *externalapi.KType `json:"k"`
+		BlockCoinbaseMaturity          *uint64            `json:"blockCoinbaseMaturity"`
+		DifficultyAdjustmentWindowSize *int               `json:"difficultyAdjustmentWindowSize"`
+	} `json:"params"`
+	Steps json.RawMessage `json:"steps"`
+}
+
+// Fixture is a DAG along with the data that consensus is expected to calculate for it
+type Fixture struct {
+	Description string       `json:"description"`
+	Params      *Params      `json:"params"`
+	Blocks      []*Block     `json:"blocks"`
+	Virtual     *VirtualData `json:"virtual"`
+}
+
+// Params are the consensus parameters a fixture was generated with
+type Params struct {
+	Network                        string            `json:"network"`
+	GenesisHash                    string            `json:"genesisHash"`
+	K                              externalapi.KType `json:"k"`
+	BlockCoinbaseMaturity          uint64            `json:"blockCoinbaseMaturity"`
+	DifficultyAdjustmentWindowSize int               `json:"difficultyAdjustmentWindowSize"`
+	TargetTimePerBlockMilliseconds int64             `json:"targetTimePerBlockMilliseconds"`
+	MergeSetSizeLimit              uint64            `json:"mergeSetSizeLimit"`
+}
+
+// Block is a block of a fixture, in the order it's added to the DAG
+type Block struct {
+	Hash     string               `json:"hash"`
+	Block    *appmessage.RPCBlock `json:"block"`
+	Expected *BlockData           `json:"expected"`
+}
+
+// BlockData is the data consensus calculates for a block once it's added to the DAG.
+// CoinbaseTransaction and UTXOCommitment are only set for blocks whose UTXO set was
+// verified, which are the blocks with the status Valid.
+type BlockData struct {
+	Status              string                     `json:"status"`
+	GHOSTDAG            *GHOSTDAGData              `json:"ghostdag"`
+	DAAScore            uint64                     `json:"daaScore"`
+	Bits                uint32                     `json:"bits"`
+	CoinbaseTransaction *appmessage.RPCTransaction `json:"coinbaseTransaction,omitempty"`
+	UTXOCommitment      string                     `json:"utxoCommitment,omitempty"`
+}
+
+// GHOSTDAGData is the GHOSTDAG data of a block. BlueWork is in hex.
+type GHOSTDAGData struct {
+	BlueScore          uint64                       `json:"blueScore"`
+	BlueWork           string                       `json:"blueWork"`
+	SelectedParent     string                       `json:"selectedParent"`
+	MergeSetBlues      []string                     `json:"mergeSetBlues"`
+	MergeSetReds       []string                     `json:"mergeSetReds"`
+	BluesAnticoneSizes map[string]externalapi.KType `json:"bluesAnticoneSizes"`
+}
+
+// VirtualData is the data consensus calculates for the virtual block once all the blocks
+// of a fixture are added to the DAG. UTXOCommitment is the commitment to the virtual UTXO set.
+type VirtualData struct {
+	Parents        []string `json:"parents"`
+	SelectedParent string   `json:"selectedParent"`
+	BlueScore      uint64   `json:"blueScore"`
+	DAAScore       uint64   `json:"daaScore"`
+	Bits           uint32   `json:"bits"`
+	PastMedianTime int64    `json:"pastMedianTime"`
+	UTXOCommitment string   `json:"utxoCommitment"`
+}
+
+// ReadSource reads the source in the given path
+func ReadSource(path string) (*Source, error) {
+	sourceJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	source := &Source{}
+	err = json.Unmarshal(sourceJSON, source)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't parse source %s", path)
+	}
+	return source, nil
+}
+
+// ReadFixture reads the fixture in the given path
+func ReadFixture(path string) (*Fixture, error) {
+	fixtureJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fixture := &Fixture{}
+	err = json.Unmarshal(fixtureJSON, fixture)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't parse fixture %s", path)
+	}
+	return fixture, nil
+}
+
+// Marshal serializes a fixture in the format it's kept in
+func Marshal(fixture *Fixture) ([]byte, error) {
+	fixtureJSON, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(fixtureJSON, '\n'), nil
+}
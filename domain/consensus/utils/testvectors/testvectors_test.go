@@ -0,0 +1,69 @@
+package testvectors
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReplay(t *testing.T) {
+	fixturePaths, err := filepath.Glob(filepath.Join(FixturesDirectory, "*.json"))
+	if err != nil {
+		t.Fatalf("Glob: %+v", err)
+	}
+	if len(fixturePaths) == 0 {
+		t.Fatalf("No fixtures found")
+	}
+
+	for _, fixturePath := range fixturePaths {
+		fixture, err := ReadFixture(fixturePath)
+		if err != nil {
+			t.Fatalf("ReadFixture: %+v", err)
+		}
+		t.Run(filepath.Base(fixturePath), func(t *testing.T) {
+			err := Replay(fixture)
+			if err != nil {
+				t.Fatalf("Replay of %q failed: %+v", fixture.Description, err)
+			}
+		})
+	}
+}
+
+// TestFixturesAreUpToDate checks that regenerating the fixtures doesn't change them, so that a change in
+// the consensus rules is caught as a fixture diff that has to be reviewed and committed with `go generate`
+func TestFixturesAreUpToDate(t *testing.T) {
+	sourcePaths, err := filepath.Glob(filepath.Join(SourcesDirectory, "*.json"))
+	if err != nil {
+		t.Fatalf("Glob: %+v", err)
+	}
+	if len(sourcePaths) == 0 {
+		t.Fatalf("No sources found")
+	}
+
+	for _, sourcePath := range sourcePaths {
+		source, err := ReadSource(sourcePath)
+		if err != nil {
+			t.Fatalf("ReadSource: %+v", err)
+		}
+		t.Run(filepath.Base(sourcePath), func(t *testing.T) {
+			fixture, err := Generate(source)
+			if err != nil {
+				t.Fatalf("Generate: %+v", err)
+			}
+			fixtureJSON, err := Marshal(fixture)
+			if err != nil {
+				t.Fatalf("Marshal: %+v", err)
+			}
+
+			fixturePath := FixturePath(sourcePath)
+			existingFixtureJSON, err := os.ReadFile(fixturePath)
+			if err != nil {
+				t.Fatalf("ReadFile: %+v", err)
+			}
+			if !bytes.Equal(fixtureJSON, existingFixtureJSON) {
+				t.Fatalf("%s is out of date. Run `go generate` in this package and review the diff", fixturePath)
+			}
+		})
+	}
+}
@@ -49,6 +49,7 @@ type Engine struct {
 	sigHashReusedValues *consensushashing.SighashReusedValues
 	isP2SH              bool     // treat execution as pay-to-script-hash
 	savedFirstStack     [][]byte // stack from first script for ps2h scripts
+	tracer              Tracer
 }
 
 // hasFlag returns whether the script engine instance has the passed flag set.
@@ -239,12 +240,25 @@ func (vm *Engine) Step() (done bool, err error) {
 		return true, err
 	}
 	opcode := &vm.scripts[vm.scriptIdx][vm.scriptOff]
+	var step *TraceStep
+	if vm.tracer != nil {
+		step = vm.newTraceStep(opcode)
+		defer func() {
+			step.Err = err
+			vm.tracer.TraceStep(step)
+		}()
+	}
 	vm.scriptOff++
 
 	// Execute the opcode while taking into account several things such as
 	// disabled opcodes, illegal opcodes, maximum allowed operations per
 	// script, maximum script element sizes, and conditionals.
 	err = vm.executeOpcode(opcode)
+	if step != nil {
+		// The stacks are recorded before they're modified when moving
+		// on to the redeem script of a pay-to-script-hash spend
+		vm.completeTraceStep(step)
+	}
 	if err != nil {
 		return true, err
 	}
@@ -5,6 +5,8 @@
 package txscript
 
 import (
+	"sync"
+
 	"github.com/sedracoin/go-secp256k1"
 )
 
@@ -30,6 +32,7 @@ type sigCacheEntry struct {
 // optimization which speeds up the validation of transactions within a block,
 // if they've already been seen and verified within the mempool.
 type SigCache struct {
+	sync.RWMutex
 	validSigs  map[secp256k1.Hash]sigCacheEntry
 	maxEntries uint
 }
@@ -52,7 +55,9 @@ func NewSigCache(maxEntries uint) *SigCache {
 // NOTE: This function is safe for concurrent access. Readers won't be blocked
 // unless there exists a writer, adding an entry to the SigCache.
 func (s *SigCache) Exists(sigHash secp256k1.Hash, sig *secp256k1.SchnorrSignature, pubKey *secp256k1.SchnorrPublicKey) bool {
+	s.RLock()
 	entry, ok := s.validSigs[sigHash]
+	s.RUnlock()
 
 	return ok && entry.pubKey.IsEqual(pubKey) && entry.sig.IsEqual(sig)
 }
@@ -69,6 +74,9 @@ func (s *SigCache) Add(sigHash secp256k1.Hash, sig *secp256k1.SchnorrSignature,
 		return
 	}
 
+	s.Lock()
+	defer s.Unlock()
+
 	// If adding this new entry will put us over the max number of allowed
 	// entries, then evict an entry.
 	if uint(len(s.validSigs)+1) > s.maxEntries {
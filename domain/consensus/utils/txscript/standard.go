@@ -5,6 +5,8 @@
 package txscript
 
 import (
+	"crypto/sha256"
+	"encoding/binary"
 	"fmt"
 
 	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
@@ -441,3 +443,337 @@ func ExtractAtomicSwapDataPushes(version uint16, scriptPubKey []byte) (*AtomicSw
 	}
 	return pushes, nil
 }
+
+// TimeLock is the lock used by the timelocked script templates. An absolute lock
+// is enforced by OP_CHECKLOCKTIMEVERIFY against the lock time of the spending
+// transaction, and holds either a DAA score or a timestamp in milliseconds,
+// depending on whether it is below constants.LockTimeThreshold. A relative lock
+// is enforced by OP_CHECKSEQUENCEVERIFY against the sequence of the spending
+// input, and holds a number of DAA scores since the spent output was accepted.
+type TimeLock struct {
+	Value      uint64
+	IsRelative bool
+}
+
+func (timeLock TimeLock) validate() error {
+	if timeLock.Value == 0 {
+		return errors.New("lock value must be greater than zero")
+	}
+	if timeLock.IsRelative && timeLock.Value > constants.SequenceLockTimeMask {
+		return errors.Errorf("relative lock value %d is greater than the maximum of %d",
+			timeLock.Value, constants.SequenceLockTimeMask)
+	}
+	return nil
+}
+
+func (timeLock TimeLock) opcode() byte {
+	if timeLock.IsRelative {
+		return OpCheckSequenceVerify
+	}
+	return OpCheckLockTimeVerify
+}
+
+// HashTimeLockedContractSecretSize is the size of the secrets of the contracts
+// created by HashTimeLockedContractScript. The size is fixed so that a secret
+// which is acceptable on one chain is acceptable on the other chains taking part
+// in an atomic swap.
+const HashTimeLockedContractSecretSize = 32
+
+// checkSigOpcodeForPublicKey returns the signature check opcode that matches the
+// given public key: OP_CHECKSIG for 32-byte schnorr keys and OP_CHECKSIGECDSA
+// for 33-byte ECDSA keys.
+func checkSigOpcodeForPublicKey(publicKey []byte) (byte, error) {
+	switch len(publicKey) {
+	case 32:
+		return OpCheckSig, nil
+	case 33:
+		return OpCheckSigECDSA, nil
+	}
+	return 0, errors.Errorf("public key of length %d is neither a schnorr nor an ECDSA public key", len(publicKey))
+}
+
+// TimeLockedVaultScript creates a redeem script that can be spent at any time by
+// the recovery key, or by the owner key once the given lock has passed:
+//
+//	OP_IF
+//	  <recovery pubkey> OP_CHECKSIG
+//	OP_ELSE
+//	  <lock> OP_CHECKLOCKTIMEVERIFY/OP_CHECKSEQUENCEVERIFY <owner pubkey> OP_CHECKSIG
+//	OP_ENDIF
+//
+// The script is expected to be used with pay-to-script-hash.
+func TimeLockedVaultScript(ownerPublicKey, recoveryPublicKey []byte, timeLock TimeLock) ([]byte, error) {
+	err := timeLock.validate()
+	if err != nil {
+		return nil, err
+	}
+	ownerCheckSigOpcode, err := checkSigOpcodeForPublicKey(ownerPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	recoveryCheckSigOpcode, err := checkSigOpcodeForPublicKey(recoveryPublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewScriptBuilder().
+		AddOp(OpIf).
+		AddData(recoveryPublicKey).AddOp(recoveryCheckSigOpcode).
+		AddOp(OpElse).
+		AddLockTimeNumber(timeLock.Value).AddOp(timeLock.opcode()).
+		AddData(ownerPublicKey).AddOp(ownerCheckSigOpcode).
+		AddOp(OpEndIf).
+		Script()
+}
+
+// HashTimeLockedContractScript creates a redeem script that can be spent at any
+// time by the recipient key along with a secret whose SHA256 is secretHash, or
+// by the refund key once the given lock has passed:
+//
+//	OP_IF
+//	  OP_SIZE 32 OP_EQUALVERIFY OP_SHA256 <secret hash> OP_EQUALVERIFY <recipient pubkey> OP_CHECKSIG
+//	OP_ELSE
+//	  <lock> OP_CHECKLOCKTIMEVERIFY/OP_CHECKSEQUENCEVERIFY <refund pubkey> OP_CHECKSIG
+//	OP_ENDIF
+//
+// SHA256 is used rather than BLAKE2b so that the same secret hash can be used in
+// contracts on other chains. The script is expected to be used with
+// pay-to-script-hash.
+func HashTimeLockedContractScript(recipientPublicKey, refundPublicKey, secretHash []byte, timeLock TimeLock) ([]byte, error) {
+	err := timeLock.validate()
+	if err != nil {
+		return nil, err
+	}
+	if len(secretHash) != sha256.Size {
+		return nil, errors.Errorf("secret hash is of length %d while a length of %d is expected",
+			len(secretHash), sha256.Size)
+	}
+	recipientCheckSigOpcode, err := checkSigOpcodeForPublicKey(recipientPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	refundCheckSigOpcode, err := checkSigOpcodeForPublicKey(refundPublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewScriptBuilder().
+		AddOp(OpIf).
+		AddOp(OpSize).AddInt64(HashTimeLockedContractSecretSize).AddOp(OpEqualVerify).
+		AddOp(OpSHA256).AddData(secretHash).AddOp(OpEqualVerify).
+		AddData(recipientPublicKey).AddOp(recipientCheckSigOpcode).
+		AddOp(OpElse).
+		AddLockTimeNumber(timeLock.Value).AddOp(timeLock.opcode()).
+		AddData(refundPublicKey).AddOp(refundCheckSigOpcode).
+		AddOp(OpEndIf).
+		Script()
+}
+
+// TimeLockedVaultDataPushes houses the data pushes found in timelocked vault scripts.
+type TimeLockedVaultDataPushes struct {
+	OwnerPublicKey    []byte
+	RecoveryPublicKey []byte
+	TimeLock          TimeLock
+}
+
+// ExtractTimeLockedVaultDataPushes returns the data pushes from a script created
+// by TimeLockedVaultScript. If the script is not a timelocked vault,
+// ExtractTimeLockedVaultDataPushes returns (nil, nil). Non-nil errors are returned
+// for unparsable scripts.
+func ExtractTimeLockedVaultDataPushes(script []byte) (*TimeLockedVaultDataPushes, error) {
+	pops, err := parseScript(script)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pops) != 9 {
+		return nil, nil
+	}
+	isVault := pops[0].opcode.value == OpIf &&
+		isPublicKeyCheckSig(pops[1], pops[2]) &&
+		pops[3].opcode.value == OpElse &&
+		isTimeLockVerify(pops[5]) &&
+		isPublicKeyCheckSig(pops[6], pops[7]) &&
+		pops[8].opcode.value == OpEndIf
+	if !isVault {
+		return nil, nil
+	}
+
+	timeLock, ok := extractTimeLock(pops[4], pops[5])
+	if !ok {
+		return nil, nil
+	}
+
+	return &TimeLockedVaultDataPushes{
+		OwnerPublicKey:    pops[6].data,
+		RecoveryPublicKey: pops[1].data,
+		TimeLock:          timeLock,
+	}, nil
+}
+
+// HashTimeLockedContractDataPushes houses the data pushes found in hash-timelocked
+// contract scripts.
+type HashTimeLockedContractDataPushes struct {
+	RecipientPublicKey []byte
+	RefundPublicKey    []byte
+	SecretHash         [sha256.Size]byte
+	TimeLock           TimeLock
+}
+
+// ExtractHashTimeLockedContractDataPushes returns the data pushes from a script
+// created by HashTimeLockedContractScript. If the script is not a hash-timelocked
+// contract, ExtractHashTimeLockedContractDataPushes returns (nil, nil). Non-nil
+// errors are returned for unparsable scripts.
+func ExtractHashTimeLockedContractDataPushes(script []byte) (*HashTimeLockedContractDataPushes, error) {
+	pops, err := parseScript(script)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pops) != 15 {
+		return nil, nil
+	}
+	isHashTimeLockedContract := pops[0].opcode.value == OpIf &&
+		pops[1].opcode.value == OpSize &&
+		pops[2].opcode.value == OpData1 &&
+		pops[2].data[0] == HashTimeLockedContractSecretSize &&
+		pops[3].opcode.value == OpEqualVerify &&
+		pops[4].opcode.value == OpSHA256 &&
+		pops[5].opcode.value == OpData32 &&
+		pops[6].opcode.value == OpEqualVerify &&
+		isPublicKeyCheckSig(pops[7], pops[8]) &&
+		pops[9].opcode.value == OpElse &&
+		isTimeLockVerify(pops[11]) &&
+		isPublicKeyCheckSig(pops[12], pops[13]) &&
+		pops[14].opcode.value == OpEndIf
+	if !isHashTimeLockedContract {
+		return nil, nil
+	}
+
+	timeLock, ok := extractTimeLock(pops[10], pops[11])
+	if !ok {
+		return nil, nil
+	}
+
+	pushes := &HashTimeLockedContractDataPushes{
+		RecipientPublicKey: pops[7].data,
+		RefundPublicKey:    pops[12].data,
+		TimeLock:           timeLock,
+	}
+	copy(pushes.SecretHash[:], pops[5].data)
+	return pushes, nil
+}
+
+// isPublicKeyCheckSig returns whether the given opcodes push a public key and
+// then check a signature against it with the matching signature check opcode.
+func isPublicKeyCheckSig(publicKeyPop, checkSigPop parsedOpcode) bool {
+	return (publicKeyPop.opcode.value == OpData32 && checkSigPop.opcode.value == OpCheckSig) ||
+		(publicKeyPop.opcode.value == OpData33 && checkSigPop.opcode.value == OpCheckSigECDSA)
+}
+
+func isTimeLockVerify(pop parsedOpcode) bool {
+	return pop.opcode.value == OpCheckLockTimeVerify || pop.opcode.value == OpCheckSequenceVerify
+}
+
+// extractTimeLock decodes the lock pushed by lockPop the same way the lock time
+// and sequence verification opcodes do.
+func extractTimeLock(lockPop, verifyPop parsedOpcode) (TimeLock, bool) {
+	var value uint64
+	switch {
+	case lockPop.opcode.value == Op0:
+		return TimeLock{}, false
+	case isSmallInt(lockPop.opcode):
+		value = uint64(asSmallInt(lockPop.opcode))
+	case lockPop.data != nil && len(lockPop.data) <= 8 && canonicalPush(lockPop):
+		valueBytes := make([]byte, 8)
+		copy(valueBytes, lockPop.data)
+		value = binary.LittleEndian.Uint64(valueBytes)
+	default:
+		return TimeLock{}, false
+	}
+
+	timeLock := TimeLock{
+		Value:      value,
+		IsRelative: verifyPop.opcode.value == OpCheckSequenceVerify,
+	}
+	if timeLock.validate() != nil {
+		return TimeLock{}, false
+	}
+	return timeLock, true
+}
+
+// MultiSigDataPushes houses the data pushes found in multisig redeem scripts.
+type MultiSigDataPushes struct {
+	MinimumSignatures int
+	PublicKeys        [][]byte
+	IsECDSA           bool
+}
+
+// ExtractMultiSigDataPushes returns the data pushes of a multisig script of
+// the form:
+//
+//	<minimum signatures> <public key>... <number of public keys> CHECKMULTISIG
+//
+// where CHECKMULTISIGECDSA is used instead of CHECKMULTISIG for ECDSA public
+// keys.
+//
+// It returns nil without an error if the script isn't a multisig script.
+func ExtractMultiSigDataPushes(script []byte) (*MultiSigDataPushes, error) {
+	pops, err := parseScript(script)
+	if err != nil {
+		return nil, err
+	}
+	if len(pops) < 4 {
+		return nil, nil
+	}
+
+	checkMultiSigPop := pops[len(pops)-1]
+	isECDSA := checkMultiSigPop.opcode.value == OpCheckMultiSigECDSA
+	if checkMultiSigPop.opcode.value != OpCheckMultiSig && !isECDSA {
+		return nil, nil
+	}
+	publicKeyPushOpcode := byte(OpData32)
+	if isECDSA {
+		publicKeyPushOpcode = OpData33
+	}
+
+	publicKeyPops := pops[1 : len(pops)-2]
+	numPublicKeys, ok := extractMultiSigNumber(pops[len(pops)-2])
+	if !ok || numPublicKeys != len(publicKeyPops) || numPublicKeys > MaxPubKeysPerMultiSig {
+		return nil, nil
+	}
+	minimumSignatures, ok := extractMultiSigNumber(pops[0])
+	if !ok || minimumSignatures < 1 || minimumSignatures > numPublicKeys {
+		return nil, nil
+	}
+
+	publicKeys := make([][]byte, len(publicKeyPops))
+	for i, pop := range publicKeyPops {
+		if pop.opcode.value != publicKeyPushOpcode {
+			return nil, nil
+		}
+		publicKeys[i] = pop.data
+	}
+
+	return &MultiSigDataPushes{
+		MinimumSignatures: minimumSignatures,
+		PublicKeys:        publicKeys,
+		IsECDSA:           isECDSA,
+	}, nil
+}
+
+// extractMultiSigNumber decodes a number pushed the way ScriptBuilder.AddInt64
+// pushes it.
+func extractMultiSigNumber(pop parsedOpcode) (int, bool) {
+	if isSmallInt(pop.opcode) {
+		return asSmallInt(pop.opcode), true
+	}
+	if pop.data == nil || !canonicalPush(pop) {
+		return 0, false
+	}
+	number, err := makeScriptNum(pop.data, defaultScriptNumLen)
+	if err != nil {
+		return 0, false
+	}
+	return int(number.Int32()), true
+}
@@ -6,7 +6,9 @@ package txscript
 
 import (
 	"bytes"
+	"encoding/hex"
 	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/constants"
 	"reflect"
 	"testing"
 
@@ -566,3 +568,173 @@ func TestStringifyClass(t *testing.T) {
 		}
 	}
 }
+
+// TestTimeLockedScriptTemplates ensures the timelocked vault and hash-timelocked
+// contract scripts can be parsed back into the data they were created from.
+func TestTimeLockedScriptTemplates(t *testing.T) {
+	t.Parallel()
+
+	schnorrPublicKey := bytes.Repeat([]byte{0x01}, 32)
+	ecdsaPublicKey := append([]byte{0x02}, bytes.Repeat([]byte{0x03}, 32)...)
+	secretHash := bytes.Repeat([]byte{0x04}, 32)
+
+	timeLocks := []TimeLock{
+		{Value: 1},
+		{Value: 16},
+		{Value: 17},
+		{Value: 256},
+		{Value: 1_600_000_000_000},
+		{Value: 1, IsRelative: true},
+		{Value: 86_400, IsRelative: true},
+	}
+
+	for _, timeLock := range timeLocks {
+		vaultScript, err := TimeLockedVaultScript(schnorrPublicKey, ecdsaPublicKey, timeLock)
+		if err != nil {
+			t.Fatalf("TimeLockedVaultScript(%+v): %s", timeLock, err)
+		}
+		vaultPushes, err := ExtractTimeLockedVaultDataPushes(vaultScript)
+		if err != nil {
+			t.Fatalf("ExtractTimeLockedVaultDataPushes(%+v): %s", timeLock, err)
+		}
+		expectedVaultPushes := &TimeLockedVaultDataPushes{
+			OwnerPublicKey:    schnorrPublicKey,
+			RecoveryPublicKey: ecdsaPublicKey,
+			TimeLock:          timeLock,
+		}
+		if !reflect.DeepEqual(vaultPushes, expectedVaultPushes) {
+			t.Fatalf("ExtractTimeLockedVaultDataPushes(%+v): got %+v, want %+v",
+				timeLock, vaultPushes, expectedVaultPushes)
+		}
+
+		htlcScript, err := HashTimeLockedContractScript(ecdsaPublicKey, schnorrPublicKey, secretHash, timeLock)
+		if err != nil {
+			t.Fatalf("HashTimeLockedContractScript(%+v): %s", timeLock, err)
+		}
+		htlcPushes, err := ExtractHashTimeLockedContractDataPushes(htlcScript)
+		if err != nil {
+			t.Fatalf("ExtractHashTimeLockedContractDataPushes(%+v): %s", timeLock, err)
+		}
+		expectedHTLCPushes := &HashTimeLockedContractDataPushes{
+			RecipientPublicKey: ecdsaPublicKey,
+			RefundPublicKey:    schnorrPublicKey,
+			TimeLock:           timeLock,
+		}
+		copy(expectedHTLCPushes.SecretHash[:], secretHash)
+		if !reflect.DeepEqual(htlcPushes, expectedHTLCPushes) {
+			t.Fatalf("ExtractHashTimeLockedContractDataPushes(%+v): got %+v, want %+v",
+				timeLock, htlcPushes, expectedHTLCPushes)
+		}
+
+		// Each template must not be mistaken for the other
+		otherPushes, err := ExtractHashTimeLockedContractDataPushes(vaultScript)
+		if err != nil || otherPushes != nil {
+			t.Fatalf("A vault script was parsed as a hash-timelocked contract")
+		}
+		otherVaultPushes, err := ExtractTimeLockedVaultDataPushes(htlcScript)
+		if err != nil || otherVaultPushes != nil {
+			t.Fatalf("A hash-timelocked contract script was parsed as a vault")
+		}
+	}
+
+	invalidTimeLocks := []TimeLock{
+		{Value: 0},
+		{Value: 0, IsRelative: true},
+		{Value: constants.SequenceLockTimeMask + 1, IsRelative: true},
+	}
+	for _, timeLock := range invalidTimeLocks {
+		_, err := TimeLockedVaultScript(schnorrPublicKey, schnorrPublicKey, timeLock)
+		if err == nil {
+			t.Fatalf("TimeLockedVaultScript(%+v): expected an error", timeLock)
+		}
+	}
+
+	_, err := HashTimeLockedContractScript(schnorrPublicKey, schnorrPublicKey, secretHash[:20], TimeLock{Value: 1})
+	if err == nil {
+		t.Fatalf("HashTimeLockedContractScript: expected an error for a short secret hash")
+	}
+	_, err = TimeLockedVaultScript(schnorrPublicKey[:20], schnorrPublicKey, TimeLock{Value: 1})
+	if err == nil {
+		t.Fatalf("TimeLockedVaultScript: expected an error for an invalid public key")
+	}
+}
+
+func TestExtractMultiSigDataPushes(t *testing.T) {
+	schnorrPublicKey := bytes.Repeat([]byte{0x02}, 32)
+	ecdsaPublicKey := append([]byte{0x02}, bytes.Repeat([]byte{0x03}, 32)...)
+
+	multiSigScript := func(minimumSignatures int64, publicKeys [][]byte, numPublicKeys int64, opcode byte) []byte {
+		builder := NewScriptBuilder().AddInt64(minimumSignatures)
+		for _, publicKey := range publicKeys {
+			builder.AddData(publicKey)
+		}
+		script, err := builder.AddInt64(numPublicKeys).AddOp(opcode).Script()
+		if err != nil {
+			t.Fatalf("Script: %v", err)
+		}
+		return script
+	}
+
+	manySchnorrPublicKeys := make([][]byte, 17)
+	for i := range manySchnorrPublicKeys {
+		manySchnorrPublicKeys[i] = schnorrPublicKey
+	}
+
+	tests := []struct {
+		name     string
+		script   []byte
+		expected *MultiSigDataPushes
+	}{
+		{
+			name:   "schnorr 2-of-3",
+			script: multiSigScript(2, [][]byte{schnorrPublicKey, schnorrPublicKey, schnorrPublicKey}, 3, OpCheckMultiSig),
+			expected: &MultiSigDataPushes{
+				MinimumSignatures: 2,
+				PublicKeys:        [][]byte{schnorrPublicKey, schnorrPublicKey, schnorrPublicKey},
+			},
+		},
+		{
+			name:   "ecdsa 1-of-2",
+			script: multiSigScript(1, [][]byte{ecdsaPublicKey, ecdsaPublicKey}, 2, OpCheckMultiSigECDSA),
+			expected: &MultiSigDataPushes{
+				MinimumSignatures: 1,
+				PublicKeys:        [][]byte{ecdsaPublicKey, ecdsaPublicKey},
+				IsECDSA:           true,
+			},
+		},
+		{
+			name:   "schnorr 17-of-17",
+			script: multiSigScript(17, manySchnorrPublicKeys, 17, OpCheckMultiSig),
+			expected: &MultiSigDataPushes{
+				MinimumSignatures: 17,
+				PublicKeys:        manySchnorrPublicKeys,
+			},
+		},
+		{
+			name:   "wrong number of public keys",
+			script: multiSigScript(1, [][]byte{schnorrPublicKey, schnorrPublicKey}, 3, OpCheckMultiSig),
+		},
+		{
+			name:   "more signatures than public keys",
+			script: multiSigScript(3, [][]byte{schnorrPublicKey, schnorrPublicKey}, 2, OpCheckMultiSig),
+		},
+		{
+			name:   "ecdsa public keys with schnorr opcode",
+			script: multiSigScript(1, [][]byte{ecdsaPublicKey, ecdsaPublicKey}, 2, OpCheckMultiSig),
+		},
+		{
+			name:   "not a multisig script",
+			script: mustParseShortForm("DATA_32 0x"+hex.EncodeToString(schnorrPublicKey)+" CHECKSIG", 0),
+		},
+	}
+
+	for _, test := range tests {
+		multiSig, err := ExtractMultiSigDataPushes(test.script)
+		if err != nil {
+			t.Fatalf("%s: ExtractMultiSigDataPushes: %v", test.name, err)
+		}
+		if !reflect.DeepEqual(multiSig, test.expected) {
+			t.Fatalf("%s: expected %+v, but got %+v", test.name, test.expected, multiSig)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package txscript
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/pkg/errors"
+)
+
+// TraceStep describes the execution of a single opcode by an Engine.
+type TraceStep struct {
+	// ScriptIndex is the index of the script the opcode belongs to: 0 for the
+	// signature script, 1 for the script public key and 2 for the redeem
+	// script of a pay-to-script-hash spend.
+	ScriptIndex int
+
+	// ScriptOffset is the index of the opcode within its script.
+	ScriptOffset int
+
+	// Opcode is the disassembly of the opcode, including its data, if any.
+	Opcode string
+
+	// IsBranchExecuting is false when the opcode is inside a conditional
+	// branch that isn't executed.
+	IsBranchExecuting bool
+
+	DataStackBefore [][]byte
+	AltStackBefore  [][]byte
+	CondStackBefore []int
+	DataStackAfter  [][]byte
+	AltStackAfter   [][]byte
+	CondStackAfter  []int
+
+	// Err is the error the step failed with, if any.
+	Err error
+}
+
+// ScriptName returns a human readable name of the script the step belongs to.
+func (step *TraceStep) ScriptName() string {
+	switch step.ScriptIndex {
+	case 0:
+		return "signature script"
+	case 1:
+		return "script public key"
+	case 2:
+		return "redeem script"
+	}
+	return fmt.Sprintf("script %d", step.ScriptIndex)
+}
+
+// String returns a multi-line description of the step, including the stacks
+// before and after it.
+func (step *TraceStep) String() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%02x:%04x: %s (%s)", step.ScriptIndex, step.ScriptOffset, step.Opcode, step.ScriptName())
+	if !step.IsBranchExecuting {
+		builder.WriteString(" [not executed]")
+	}
+	builder.WriteString("\n")
+	writeTraceStack(&builder, "stack before", step.DataStackBefore)
+	writeTraceStack(&builder, "alt stack before", step.AltStackBefore)
+	writeTraceCondStack(&builder, "conditions before", step.CondStackBefore)
+	writeTraceStack(&builder, "stack after", step.DataStackAfter)
+	writeTraceStack(&builder, "alt stack after", step.AltStackAfter)
+	writeTraceCondStack(&builder, "conditions after", step.CondStackAfter)
+	if step.Err != nil {
+		fmt.Fprintf(&builder, "\terror: %s\n", step.Err)
+	}
+	return builder.String()
+}
+
+func writeTraceStack(builder *strings.Builder, name string, stack [][]byte) {
+	if len(stack) == 0 {
+		return
+	}
+	fmt.Fprintf(builder, "\t%s (top last):\n", name)
+	for _, item := range stack {
+		if len(item) == 0 {
+			builder.WriteString("\t\t<empty>\n")
+			continue
+		}
+		fmt.Fprintf(builder, "\t\t%x\n", item)
+	}
+}
+
+func writeTraceCondStack(builder *strings.Builder, name string, condStack []int) {
+	if len(condStack) == 0 {
+		return
+	}
+	conditions := make([]string, len(condStack))
+	for i, condition := range condStack {
+		switch condition {
+		case OpCondTrue:
+			conditions[i] = "true"
+		case OpCondFalse:
+			conditions[i] = "false"
+		case OpCondSkip:
+			conditions[i] = "skip"
+		}
+	}
+	fmt.Fprintf(builder, "\t%s: %s\n", name, strings.Join(conditions, " "))
+}
+
+// Tracer is notified by an Engine of every step it executes.
+type Tracer interface {
+	TraceStep(step *TraceStep)
+}
+
+// TraceRecorder is a Tracer that keeps all the steps it is notified of.
+type TraceRecorder struct {
+	Steps []*TraceStep
+}
+
+// TraceStep implements the Tracer interface.
+func (recorder *TraceRecorder) TraceStep(step *TraceStep) {
+	recorder.Steps = append(recorder.Steps, step)
+}
+
+// FailingStep returns the step that failed the execution, or nil if no step
+// failed. Note that execution may still fail after the last step, e.g. when
+// the stack is left with a false value.
+func (recorder *TraceRecorder) FailingStep() *TraceStep {
+	for _, step := range recorder.Steps {
+		if step.Err != nil {
+			return step
+		}
+	}
+	return nil
+}
+
+// String returns the descriptions of all the recorded steps.
+func (recorder *TraceRecorder) String() string {
+	var builder strings.Builder
+	for _, step := range recorder.Steps {
+		builder.WriteString(step.String())
+	}
+	return builder.String()
+}
+
+// SetTracer sets a tracer that will be notified of every step executed by the
+// engine. Tracing copies the stacks on every step, so it should only be used
+// for debugging.
+func (vm *Engine) SetTracer(tracer Tracer) {
+	vm.tracer = tracer
+}
+
+// newTraceStep creates a trace step for the given opcode, which is about to be
+// executed at the current program counter.
+func (vm *Engine) newTraceStep(pop *parsedOpcode) *TraceStep {
+	return &TraceStep{
+		ScriptIndex:       vm.scriptIdx,
+		ScriptOffset:      vm.scriptOff,
+		Opcode:            pop.print(false),
+		IsBranchExecuting: vm.isBranchExecuting(),
+		DataStackBefore:   vm.GetStack(),
+		AltStackBefore:    vm.GetAltStack(),
+		CondStackBefore:   append([]int(nil), vm.condStack...),
+	}
+}
+
+// completeTraceStep records the state of the engine after step was executed.
+func (vm *Engine) completeTraceStep(step *TraceStep) {
+	step.DataStackAfter = vm.GetStack()
+	step.AltStackAfter = vm.GetAltStack()
+	step.CondStackAfter = append([]int(nil), vm.condStack...)
+}
+
+// TraceTransactionInput executes the scripts of the input at txIdx of tx, whose
+// inputs must all have their UTXO entries populated, and returns the recorded
+// steps along with the execution error, if any.
+func TraceTransactionInput(tx *externalapi.DomainTransaction, txIdx int) (*TraceRecorder, error) {
+	recorder := &TraceRecorder{}
+	if txIdx < 0 || txIdx >= len(tx.Inputs) {
+		return recorder, scriptError(ErrInvalidIndex,
+			fmt.Sprintf("transaction input index %d is negative or >= %d", txIdx, len(tx.Inputs)))
+	}
+	for i, input := range tx.Inputs {
+		if input.UTXOEntry == nil {
+			return recorder, errors.Errorf("the UTXO entry of input %d is missing", i)
+		}
+	}
+
+	vm, err := NewEngine(tx.Inputs[txIdx].UTXOEntry.ScriptPublicKey(), tx, txIdx, ScriptNoFlags, nil, nil,
+		&consensushashing.SighashReusedValues{})
+	if err != nil {
+		return recorder, err
+	}
+	vm.SetTracer(recorder)
+	return recorder, vm.Execute()
+}
@@ -0,0 +1,144 @@
+package txscript
+
+import (
+	"testing"
+
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/utxo"
+)
+
+func TestTraceTransactionInput(t *testing.T) {
+	redeemScript := mustParseShortForm("2 EQUAL", 0)
+	p2shScript, err := PayToScriptHashScript(redeemScript)
+	if err != nil {
+		t.Fatalf("PayToScriptHashScript: %v", err)
+	}
+
+	tests := []struct {
+		name                string
+		scriptPublicKey     []byte
+		signatureScript     []byte
+		expectedSteps       int
+		expectedFailingStep *TraceStep
+		expectErr           bool
+	}{
+		{
+			name:            "successful pay-to-script-hash",
+			scriptPublicKey: p2shScript,
+			signatureScript: mustParseShortForm("2 DATA_2 0x52 0x87", 0),
+			// 2 pushes, 3 opcodes of the script public key and 2 of the redeem script
+			expectedSteps: 7,
+		},
+		{
+			name:            "failing redeem script",
+			scriptPublicKey: p2shScript,
+			signatureScript: mustParseShortForm("3 DATA_2 0x52 0x87", 0),
+			expectedSteps:   7,
+			expectErr:       true,
+		},
+		{
+			name:            "failing verify",
+			scriptPublicKey: mustParseShortForm("IF 2 EQUALVERIFY ENDIF 1", 0),
+			signatureScript: mustParseShortForm("3 1", 0),
+			expectedSteps:   5,
+			expectedFailingStep: &TraceStep{
+				ScriptIndex:       1,
+				ScriptOffset:      2,
+				Opcode:            "OP_EQUALVERIFY",
+				IsBranchExecuting: true,
+				DataStackBefore:   [][]byte{{3}, {2}},
+				CondStackBefore:   []int{OpCondTrue},
+				CondStackAfter:    []int{OpCondTrue},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		tx := &externalapi.DomainTransaction{
+			Version: 0,
+			Inputs: []*externalapi.DomainTransactionInput{{
+				SignatureScript: test.signatureScript,
+				UTXOEntry: utxo.NewUTXOEntry(1, &externalapi.ScriptPublicKey{Script: test.scriptPublicKey},
+					false, 0),
+			}},
+		}
+
+		recorder, err := TraceTransactionInput(tx, 0)
+		if (err != nil) != test.expectErr {
+			t.Fatalf("%s: expected error: %t, but got: %v", test.name, test.expectErr, err)
+		}
+		if len(recorder.Steps) != test.expectedSteps {
+			t.Fatalf("%s: expected %d steps, but got %d:\n%s", test.name, test.expectedSteps,
+				len(recorder.Steps), recorder)
+		}
+		failingStep := recorder.FailingStep()
+		if test.expectedFailingStep == nil {
+			if failingStep != nil {
+				t.Fatalf("%s: unexpected failing step:\n%s", test.name, failingStep)
+			}
+			continue
+		}
+		if failingStep == nil {
+			t.Fatalf("%s: expected a failing step", test.name)
+		}
+		expected := test.expectedFailingStep
+		if failingStep.ScriptIndex != expected.ScriptIndex || failingStep.ScriptOffset != expected.ScriptOffset ||
+			failingStep.Opcode != expected.Opcode || failingStep.IsBranchExecuting != expected.IsBranchExecuting ||
+			!equalStacks(failingStep.DataStackBefore, expected.DataStackBefore) ||
+			len(failingStep.CondStackBefore) != len(expected.CondStackBefore) ||
+			len(failingStep.CondStackAfter) != len(expected.CondStackAfter) {
+
+			t.Fatalf("%s: expected failing step:\n%s\nbut got:\n%s", test.name, expected, failingStep)
+		}
+	}
+}
+
+func TestTraceRedeemScriptSteps(t *testing.T) {
+	redeemScript := mustParseShortForm("2 EQUAL", 0)
+	p2shScript, err := PayToScriptHashScript(redeemScript)
+	if err != nil {
+		t.Fatalf("PayToScriptHashScript: %v", err)
+	}
+	tx := &externalapi.DomainTransaction{
+		Inputs: []*externalapi.DomainTransactionInput{{
+			SignatureScript: mustParseShortForm("2 DATA_2 0x52 0x87", 0),
+			UTXOEntry:       utxo.NewUTXOEntry(1, &externalapi.ScriptPublicKey{Script: p2shScript}, false, 0),
+		}},
+	}
+
+	recorder, err := TraceTransactionInput(tx, 0)
+	if err != nil {
+		t.Fatalf("TraceTransactionInput: %v", err)
+	}
+
+	expectedScriptIndexes := []int{0, 0, 1, 1, 1, 2, 2}
+	for i, step := range recorder.Steps {
+		if step.ScriptIndex != expectedScriptIndexes[i] {
+			t.Fatalf("expected step %d to be in script %d, but it's in script %d",
+				i, expectedScriptIndexes[i], step.ScriptIndex)
+		}
+	}
+
+	// The last step of the script public key should show its own result rather
+	// than the stack the redeem script starts with
+	lastScriptPublicKeyStep := recorder.Steps[4]
+	if !equalStacks(lastScriptPublicKeyStep.DataStackAfter, [][]byte{{2}, {1}}) {
+		t.Fatalf("unexpected stack after the last step of the script public key:\n%s", lastScriptPublicKeyStep)
+	}
+	if !equalStacks(recorder.Steps[5].DataStackBefore, [][]byte{{2}}) {
+		t.Fatalf("unexpected stack before the first step of the redeem script:\n%s", recorder.Steps[5])
+	}
+}
+
+func equalStacks(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if string(a[i]) != string(b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -3,6 +3,7 @@ package mempool
 import (
 	"fmt"
 
+	"github.com/sedracoin/sedrad/util/txfee"
 	"github.com/sedracoin/sedrad/util/txmass"
 
 	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
@@ -187,20 +188,6 @@ func (mp *mempool) checkTransactionStandardInContext(transaction *externalapi.Do
 // minimumRequiredTransactionRelayFee returns the minimum transaction fee required for a
 // transaction with the passed mass to be accepted into the mampool and relayed.
 func (mp *mempool) minimumRequiredTransactionRelayFee(mass uint64) uint64 {
-	// Calculate the minimum fee for a transaction to be allowed into the
-	// mempool and relayed by scaling the base fee. MinimumRelayTransactionFee is in
-	// seep/kg so multiply by mass (which is in grams) and divide by 1000 to get minimum seeps.
-	minimumFee := (mass * uint64(mp.config.MinimumRelayTransactionFee)) / 1000
-
-	if minimumFee == 0 && mp.config.MinimumRelayTransactionFee > 0 {
-		minimumFee = uint64(mp.config.MinimumRelayTransactionFee)
-	}
-
-	// Set the minimum fee to the maximum possible value if the calculated
-	// fee is not in the valid range for monetary amounts.
-	if minimumFee > constants.MaxSeep {
-		minimumFee = constants.MaxSeep
-	}
-
-	return minimumFee
+	// MinimumRelayTransactionFee is in seep/kg, and mass is in grams
+	return txfee.MinimumRelayFee(mass, uint64(mp.config.MinimumRelayTransactionFee))
 }
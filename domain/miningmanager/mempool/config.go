@@ -6,6 +6,7 @@ import (
 	"github.com/sedracoin/sedrad/domain/consensus/utils/constants"
 
 	"github.com/sedracoin/sedrad/util"
+	"github.com/sedracoin/sedrad/util/txfee"
 
 	"github.com/sedracoin/sedrad/domain/dagconfig"
 )
@@ -25,7 +26,7 @@ const (
 
 	// defaultMinimumRelayTransactionFee specifies the minimum transaction fee for a transaction to be accepted to
 	// the mempool and relayed. It is specified in seep per 1kg (or 1000 grams) of transaction mass.
-	defaultMinimumRelayTransactionFee = util.Amount(1000)
+	defaultMinimumRelayTransactionFee = util.Amount(txfee.DefaultMinimumRelayFeeRate)
 
 	// Standard transaction version range might be different from what consensus accepts, therefore
 	// we define separate values in mempool.
@@ -1,11 +1,15 @@
 package utxoindex
 
 import (
+	"sync"
+	"time"
+
 	"github.com/sedracoin/sedrad/domain"
 	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/ruleerrors"
 	"github.com/sedracoin/sedrad/infrastructure/db/database"
 	"github.com/sedracoin/sedrad/infrastructure/logger"
-	"sync"
+	"github.com/pkg/errors"
 )
 
 // UTXOIndex maintains an index between transaction scriptPublicKeys
@@ -199,6 +203,93 @@ func (ui *UTXOIndex) UTXOs(scriptPublicKey *externalapi.ScriptPublicKey) (UTXOOu
 	return ui.store.getUTXOOutpointEntryPairs(scriptPublicKey)
 }
 
+// The UTXO index is updated asynchronously to the consensus virtual, so a historical query
+// may find the two out of sync. In that case we give the index some time to catch up.
+const (
+	maxHistoricalUTXOsAttempts      = 10
+	historicalUTXOsAttemptsInterval = 100 * time.Millisecond
+)
+
+// UTXOsAtChainBlock returns all the UTXOs for each of the given scriptPublicKeys as they were
+// right after the given chain block had been accepted
+func (ui *UTXOIndex) UTXOsAtChainBlock(blockHash *externalapi.DomainHash,
+	scriptPublicKeys []*externalapi.ScriptPublicKey) ([]UTXOOutpointEntryPairs, error) {
+
+	onEnd := logger.LogAndMeasureExecutionTime(log, "UTXOIndex.UTXOsAtChainBlock")
+	defer onEnd()
+
+	for attempt := 1; ; attempt++ {
+		utxoOutpointEntryPairs, err := ui.utxosAtChainBlock(blockHash, scriptPublicKeys)
+		if err == nil {
+			return utxoOutpointEntryPairs, nil
+		}
+		if !errors.Is(err, ruleerrors.ErrGetVirtualUTXOsWrongVirtualParents) || attempt == maxHistoricalUTXOsAttempts {
+			return nil, err
+		}
+		log.Debugf("The UTXO index is not in sync with the virtual (attempt %d): %s", attempt, err)
+		time.Sleep(historicalUTXOsAttemptsInterval)
+	}
+}
+
+func (ui *UTXOIndex) utxosAtChainBlock(blockHash *externalapi.DomainHash,
+	scriptPublicKeys []*externalapi.ScriptPublicKey) ([]UTXOOutpointEntryPairs, error) {
+
+	ui.mutex.Lock()
+	defer ui.mutex.Unlock()
+
+	virtualParents, err := ui.store.getVirtualParents()
+	if err != nil {
+		return nil, err
+	}
+	diffFromVirtual, err := ui.domain.Consensus().GetChainBlockUTXODiff(virtualParents, blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]UTXOOutpointEntryPairs, len(scriptPublicKeys))
+	for i, scriptPublicKey := range scriptPublicKeys {
+		utxoOutpointEntryPairs, err := ui.store.getUTXOOutpointEntryPairs(scriptPublicKey)
+		if err != nil {
+			return nil, err
+		}
+		err = applyDiffForScriptPublicKey(utxoOutpointEntryPairs, diffFromVirtual, scriptPublicKey)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = utxoOutpointEntryPairs
+	}
+	return result, nil
+}
+
+func applyDiffForScriptPublicKey(utxoOutpointEntryPairs UTXOOutpointEntryPairs, diff externalapi.UTXODiff,
+	scriptPublicKey *externalapi.ScriptPublicKey) error {
+
+	toRemoveIterator := diff.ToRemove().Iterator()
+	defer toRemoveIterator.Close()
+	for ok := toRemoveIterator.First(); ok; ok = toRemoveIterator.Next() {
+		outpoint, entry, err := toRemoveIterator.Get()
+		if err != nil {
+			return err
+		}
+		if entry.ScriptPublicKey().Equal(scriptPublicKey) {
+			delete(utxoOutpointEntryPairs, *outpoint)
+		}
+	}
+
+	toAddIterator := diff.ToAdd().Iterator()
+	defer toAddIterator.Close()
+	for ok := toAddIterator.First(); ok; ok = toAddIterator.Next() {
+		outpoint, entry, err := toAddIterator.Get()
+		if err != nil {
+			return err
+		}
+		if entry.ScriptPublicKey().Equal(scriptPublicKey) {
+			utxoOutpointEntryPairs[*outpoint] = entry
+		}
+	}
+	return nil
+}
+
 // GetCirculatingSeepSupply returns the current circulating supply of seeps in the network
 func (ui *UTXOIndex) GetCirculatingSeepSupply() (uint64, error) {
 
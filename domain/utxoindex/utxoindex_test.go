@@ -0,0 +1,134 @@
+package utxoindex
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sedracoin/sedrad/domain"
+	"github.com/sedracoin/sedrad/domain/consensus"
+	"github.com/sedracoin/sedrad/domain/consensus/model"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/model/testapi"
+	"github.com/sedracoin/sedrad/domain/consensus/ruleerrors"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/testutils"
+	"github.com/sedracoin/sedrad/infrastructure/db/database/ldb"
+	"github.com/pkg/errors"
+)
+
+type testDomain struct {
+	domain.Domain
+	consensus externalapi.Consensus
+}
+
+func (d *testDomain) Consensus() externalapi.Consensus {
+	return d.consensus
+}
+
+func TestUTXOsAtChainBlock(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		consensusConfig.BlockCoinbaseMaturity = 0
+
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestUTXOsAtChainBlock")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		db, err := ldb.NewLevelDB(t.TempDir(), 8)
+		if err != nil {
+			t.Fatalf("NewLevelDB: %+v", err)
+		}
+		defer db.Close()
+
+		utxoIndex, err := New(&testDomain{consensus: tc}, db)
+		if err != nil {
+			t.Fatalf("New: %+v", err)
+		}
+
+		scriptPublicKey, _ := testutils.OpTrueScript()
+		coinbaseData := &externalapi.DomainCoinbaseData{ScriptPublicKey: scriptPublicKey, ExtraData: []byte{}}
+		addBlock := func(parentHash *externalapi.DomainHash) (*externalapi.DomainHash, *externalapi.VirtualChangeSet) {
+			blockHash, virtualChangeSet, err := tc.AddBlock([]*externalapi.DomainHash{parentHash}, coinbaseData, nil)
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+			return blockHash, virtualChangeSet
+		}
+
+		chain := []*externalapi.DomainHash{consensusConfig.GenesisHash}
+		for i := 0; i < 10; i++ {
+			blockHash, virtualChangeSet := addBlock(chain[len(chain)-1])
+			_, err = utxoIndex.Update(virtualChangeSet)
+			if err != nil {
+				t.Fatalf("Update: %+v", err)
+			}
+			chain = append(chain, blockHash)
+		}
+
+		for _, blockHash := range chain {
+			utxoOutpointEntryPairs, err := utxoIndex.UTXOsAtChainBlock(blockHash,
+				[]*externalapi.ScriptPublicKey{scriptPublicKey})
+			if err != nil {
+				t.Fatalf("UTXOsAtChainBlock: %+v", err)
+			}
+			expectedUTXOOutpointEntryPairs := pastUTXOsOfScriptPublicKey(t, tc, blockHash, scriptPublicKey)
+			if !reflect.DeepEqual(utxoOutpointEntryPairs[0], expectedUTXOOutpointEntryPairs) {
+				t.Fatalf("Unexpected UTXOs for block %s: got %d UTXOs, expected %d",
+					blockHash, len(utxoOutpointEntryPairs[0]), len(expectedUTXOOutpointEntryPairs))
+			}
+		}
+
+		// The UTXO index lags behind the virtual until the virtual change set is applied
+		blockHash, virtualChangeSet := addBlock(chain[len(chain)-1])
+		chain = append(chain, blockHash)
+		updateErrors := make(chan error, 1)
+		go func() {
+			time.Sleep(historicalUTXOsAttemptsInterval * 3 / 2)
+			_, err := utxoIndex.Update(virtualChangeSet)
+			updateErrors <- err
+		}()
+		utxoOutpointEntryPairs, err := utxoIndex.UTXOsAtChainBlock(chain[1], []*externalapi.ScriptPublicKey{scriptPublicKey})
+		if err != nil {
+			t.Fatalf("UTXOsAtChainBlock: %+v", err)
+		}
+		err = <-updateErrors
+		if err != nil {
+			t.Fatalf("Update: %+v", err)
+		}
+		expectedUTXOOutpointEntryPairs := pastUTXOsOfScriptPublicKey(t, tc, chain[1], scriptPublicKey)
+		if !reflect.DeepEqual(utxoOutpointEntryPairs[0], expectedUTXOOutpointEntryPairs) {
+			t.Fatalf("Unexpected UTXOs for block %s after the UTXO index had caught up", chain[1])
+		}
+
+		// A UTXO index that never catches up gives up after maxHistoricalUTXOsAttempts
+		addBlock(chain[len(chain)-1])
+		_, err = utxoIndex.UTXOsAtChainBlock(chain[1], []*externalapi.ScriptPublicKey{scriptPublicKey})
+		if !errors.Is(err, ruleerrors.ErrGetVirtualUTXOsWrongVirtualParents) {
+			t.Fatalf("Expected ErrGetVirtualUTXOsWrongVirtualParents, but got: %v", err)
+		}
+	})
+}
+
+func pastUTXOsOfScriptPublicKey(t *testing.T, tc testapi.TestConsensus, blockHash *externalapi.DomainHash,
+	scriptPublicKey *externalapi.ScriptPublicKey) UTXOOutpointEntryPairs {
+
+	iterator, err := tc.ConsensusStateManager().RestorePastUTXOSetIterator(model.NewStagingArea(), blockHash)
+	if err != nil {
+		t.Fatalf("RestorePastUTXOSetIterator: %+v", err)
+	}
+	defer iterator.Close()
+
+	utxoOutpointEntryPairs := make(UTXOOutpointEntryPairs)
+	for ok := iterator.First(); ok; ok = iterator.Next() {
+		outpoint, entry, err := iterator.Get()
+		if err != nil {
+			t.Fatalf("Get: %+v", err)
+		}
+		if entry.ScriptPublicKey().Equal(scriptPublicKey) {
+			utxoOutpointEntryPairs[*outpoint] = entry
+		}
+	}
+	return utxoOutpointEntryPairs
+}
@@ -53,7 +53,7 @@ const (
 	defaultSigCacheMaxSize  = 100_000
 	sampleConfigFilename    = "sample-sedrad.conf"
 	defaultMaxUTXOCacheSize = 5_000_000_000
-	defaultProtocolVersion  = 5
+	defaultProtocolVersion  = 6
 )
 
 var (
@@ -99,6 +99,7 @@ type Flags struct {
 	RPCMaxConcurrentReqs            int           `long:"rpcmaxconcurrentreqs" description:"Max number of concurrent RPC requests that may be processed concurrently"`
 	DisableRPC                      bool          `long:"norpc" description:"Disable built-in RPC server"`
 	SafeRPC                         bool          `long:"saferpc" description:"Disable RPC commands which affect the state of the node"`
+	DAGViewerListen                 string        `long:"dagviewerlisten" description:"Serve a web page that renders the recent DAG live on the given interface/port (e.g. 127.0.0.1:22112) -- NOTE the page is unauthenticated"`
 	DisableDNSSeed                  bool          `long:"nodnsseed" description:"Disable DNS seeding for peers"`
 	DNSSeed                         string        `long:"dnsseed" description:"Override DNS seeds with specified hostname (Only 1 hostname allowed)"`
 	GRPCSeed                        string        `long:"grpcseed" description:"Hostname of gRPC server for seeding peers"`
@@ -119,6 +120,10 @@ type Flags struct {
 	BlocksOnly                      bool          `long:"blocksonly" description:"Do not accept transactions from remote peers."`
 	RelayNonStd                     bool          `long:"relaynonstd" description:"Relay non-standard transactions regardless of the default settings for the active network."`
 	RejectNonStd                    bool          `long:"rejectnonstd" description:"Reject non-standard transactions regardless of the default settings for the active network."`
+	Dandelion                       bool          `long:"dandelion" description:"Relay transactions through a randomly selected stem peer before broadcasting them to the network (Dandelion++), to make it harder to link them to this node"`
+	MaxUploadRate                   uint64        `long:"maxuploadrate" description:"Max total upload rate to all P2P peers in KB/s (0 means unlimited)"`
+	MaxPeerUploadRate               uint64        `long:"maxpeeruploadrate" description:"Max upload rate to a single P2P peer in KB/s (0 means unlimited)"`
+	MaxIBDUploadRate                uint64        `long:"maxibduploadrate" description:"Max total upload rate of blocks served to syncing (IBD) peers in KB/s (0 means unlimited)"`
 	ResetDatabase                   bool          `long:"reset-db" description:"Reset database before starting node. It's needed when switching between subnetworks."`
 	MaxUTXOCacheSize                uint64        `long:"maxutxocachesize" description:"Max size of loaded UTXO into ram from the disk in bytes"`
 	UTXOIndex                       bool          `long:"utxoindex" description:"Enable the UTXO index"`
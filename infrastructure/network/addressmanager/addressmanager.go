@@ -89,6 +89,16 @@ func (am *AddressManager) addAddressNoLock(netAddress *appmessage.NetAddress) er
 	}
 
 	key := netAddressKey(netAddress)
+	if existingAddress, ok := am.store.getNotBanned(key); ok {
+		// Gossiped services are only claimed by whoever relayed the address,
+		// so they never override known services. Those are only updated by
+		// UpdateServices, with the services the peer itself has advertised.
+		if existingAddress.netAddress.Services != 0 {
+			return nil
+		}
+		return am.updateServicesNoLock(key, existingAddress, netAddress.Services)
+	}
+
 	// We mark `connectionFailedCount` as 0 only after first success
 	address := &address{netAddress: netAddress, connectionFailedCount: 1}
 	err := am.store.add(key, address)
@@ -116,6 +126,20 @@ func (am *AddressManager) addAddressNoLock(netAddress *appmessage.NetAddress) er
 	return nil
 }
 
+// updateServicesNoLock sets the services of the given stored address, unless
+// they are unknown
+func (am *AddressManager) updateServicesNoLock(key addressKey, address *address,
+	services appmessage.ServiceFlag) error {
+
+	if services == 0 || address.netAddress.Services == services {
+		return nil
+	}
+	updatedNetAddress := *address.netAddress
+	updatedNetAddress.Services = services
+	address.netAddress = &updatedNetAddress
+	return am.store.updateNotBanned(key, address)
+}
+
 func (am *AddressManager) removeAddressNoLock(address *appmessage.NetAddress) error {
 	key := netAddressKey(address)
 	return am.store.remove(key)
@@ -129,7 +153,8 @@ func (am *AddressManager) AddAddress(address *appmessage.NetAddress) error {
 	return am.addAddressNoLock(address)
 }
 
-// AddAddresses adds addresses to the address manager
+// AddAddresses adds addresses to the address manager. The services of
+// addresses that are already known are only set if they're still unknown.
 func (am *AddressManager) AddAddresses(addresses ...*appmessage.NetAddress) error {
 	am.mutex.Lock()
 	defer am.mutex.Unlock()
@@ -187,6 +212,34 @@ func (am *AddressManager) MarkConnectionSuccess(address *appmessage.NetAddress)
 	return am.store.updateNotBanned(key, entry)
 }
 
+// UpdateServices sets the services of the given address to the ones its peer
+// has advertised. Addresses that are not registered with the address manager
+// are ignored.
+func (am *AddressManager) UpdateServices(netAddress *appmessage.NetAddress, services appmessage.ServiceFlag) error {
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+
+	key := netAddressKey(netAddress)
+	address, ok := am.store.getNotBanned(key)
+	if !ok {
+		return nil
+	}
+	return am.updateServicesNoLock(key, address, services)
+}
+
+// Services returns the services the peer at the given address is known to
+// support. It returns 0 if they're unknown.
+func (am *AddressManager) Services(netAddress *appmessage.NetAddress) appmessage.ServiceFlag {
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+
+	address, ok := am.store.getNotBanned(netAddressKey(netAddress))
+	if !ok {
+		return 0
+	}
+	return address.netAddress.Services
+}
+
 // Addresses returns all addresses
 func (am *AddressManager) Addresses() []*appmessage.NetAddress {
 	am.mutex.Lock()
@@ -217,6 +270,21 @@ func (am *AddressManager) RandomAddresses(count int, exceptions []*appmessage.Ne
 	return am.random.RandomAddresses(validAddresses, count)
 }
 
+// RandomAddressesWithServices returns count addresses at random that aren't banned,
+// aren't in exceptions, and are known to support all the given services
+func (am *AddressManager) RandomAddressesWithServices(count int, services appmessage.ServiceFlag,
+	exceptions []*appmessage.NetAddress) []*appmessage.NetAddress {
+
+	validAddresses := am.notBannedAddressesWithException(exceptions)
+	addressesWithServices := make([]*address, 0, len(validAddresses))
+	for _, address := range validAddresses {
+		if address.netAddress.HasServices(services) {
+			addressesWithServices = append(addressesWithServices, address)
+		}
+	}
+	return am.random.RandomAddresses(addressesWithServices, count)
+}
+
 // BestLocalAddress returns the most appropriate local address to use
 // for the given remote address.
 func (am *AddressManager) BestLocalAddress(remoteAddress *appmessage.NetAddress) *appmessage.NetAddress {
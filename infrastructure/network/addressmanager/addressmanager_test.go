@@ -372,3 +372,54 @@ func TestOverfillAddressManager(t *testing.T) {
 		}
 	}
 }
+
+func TestAddressServices(t *testing.T) {
+	addressManager, teardown := newAddressManagerForTest(t, "TestAddressServices")
+	defer teardown()
+
+	newTestAddress := func(services appmessage.ServiceFlag) *appmessage.NetAddress {
+		return &appmessage.NetAddress{IP: net.ParseIP("1.2.3.4"), Port: 16111, Timestamp: mstime.Now(), Services: services}
+	}
+
+	// Gossip fills in services that are unknown
+	err := addressManager.AddAddresses(newTestAddress(0))
+	if err != nil {
+		t.Fatalf("AddAddresses() failed: %s", err)
+	}
+	if services := addressManager.Services(newTestAddress(0)); services != 0 {
+		t.Fatalf("Expected the services to be unknown, but got %s", services)
+	}
+	err = addressManager.AddAddresses(newTestAddress(appmessage.SFNodeNetwork))
+	if err != nil {
+		t.Fatalf("AddAddresses() failed: %s", err)
+	}
+	if services := addressManager.Services(newTestAddress(0)); services != appmessage.SFNodeNetwork {
+		t.Fatalf("Expected gossip to fill in the unknown services, but got %s", services)
+	}
+
+	// Gossip doesn't override known services
+	err = addressManager.AddAddresses(newTestAddress(appmessage.SFNodeNetwork | appmessage.SFNodeArchival))
+	if err != nil {
+		t.Fatalf("AddAddresses() failed: %s", err)
+	}
+	if services := addressManager.Services(newTestAddress(0)); services != appmessage.SFNodeNetwork {
+		t.Fatalf("Expected gossip not to override the known services, but got %s", services)
+	}
+
+	// The services the peer itself advertises override the known ones,
+	// unless they're unknown
+	err = addressManager.UpdateServices(newTestAddress(0), appmessage.SFNodeArchival)
+	if err != nil {
+		t.Fatalf("UpdateServices() failed: %s", err)
+	}
+	if services := addressManager.Services(newTestAddress(0)); services != appmessage.SFNodeArchival {
+		t.Fatalf("Expected the advertised services to override the known ones, but got %s", services)
+	}
+	err = addressManager.UpdateServices(newTestAddress(0), 0)
+	if err != nil {
+		t.Fatalf("UpdateServices() failed: %s", err)
+	}
+	if services := addressManager.Services(newTestAddress(0)); services != appmessage.SFNodeArchival {
+		t.Fatalf("Expected unknown services not to override the known ones, but got %s", services)
+	}
+}
@@ -239,14 +239,19 @@ func (as *addressStore) deserializeAddressKey(serializedKey []byte) addressKey {
 	}
 }
 
+// serializedAddressSizeWithoutServices is the size of addresses that were
+// serialized before services were stored
+const serializedAddressSizeWithoutServices = 16 + 2 + 8 + 8 // ipv6 + port + timestamp + connectionFailedCount
+
 func (as *addressStore) serializeAddress(address *address) []byte {
-	serializedSize := 16 + 2 + 8 + 8 // ipv6 + port + timestamp + connectionFailedCount
+	serializedSize := serializedAddressSizeWithoutServices + 8 // + services
 	serializedNetAddress := make([]byte, serializedSize)
 
 	copy(serializedNetAddress[:], address.netAddress.IP.To16()[:])
 	binary.LittleEndian.PutUint16(serializedNetAddress[16:], address.netAddress.Port)
 	binary.LittleEndian.PutUint64(serializedNetAddress[18:], uint64(address.netAddress.Timestamp.UnixMilliseconds()))
 	binary.LittleEndian.PutUint64(serializedNetAddress[26:], uint64(address.connectionFailedCount))
+	binary.LittleEndian.PutUint64(serializedNetAddress[34:], uint64(address.netAddress.Services))
 
 	return serializedNetAddress
 }
@@ -259,11 +264,18 @@ func (as *addressStore) deserializeAddress(serializedAddress []byte) *address {
 	timestamp := mstime.UnixMilliseconds(int64(binary.LittleEndian.Uint64(serializedAddress[18:])))
 	connectionFailedCount := binary.LittleEndian.Uint64(serializedAddress[26:])
 
+	// Addresses that were stored before services were stored have unknown services
+	services := appmessage.ServiceFlag(0)
+	if len(serializedAddress) > serializedAddressSizeWithoutServices {
+		services = appmessage.ServiceFlag(binary.LittleEndian.Uint64(serializedAddress[34:]))
+	}
+
 	return &address{
 		netAddress: &appmessage.NetAddress{
 			IP:        ip,
 			Port:      port,
 			Timestamp: timestamp,
+			Services:  services,
 		},
 		connectionFailedCount: connectionFailedCount,
 	}
@@ -34,6 +34,7 @@ func TestAddressSerialization(t *testing.T) {
 			IP:        net.ParseIP("2602:100:abcd::102"),
 			Port:      12345,
 			Timestamp: mstime.Now(),
+			Services:  appmessage.SFNodeNetwork | appmessage.SFNodeArchival,
 		},
 		connectionFailedCount: 98465,
 	}
@@ -44,4 +45,16 @@ func TestAddressSerialization(t *testing.T) {
 		t.Fatalf("testAddress and deserializedTestAddress are not equal\n"+
 			"testAddress:%+v\ndeserializedTestAddress:%+v", testAddress, deserializedTestAddress)
 	}
+
+	// Addresses that were serialized before services were stored have unknown services
+	legacySerializedTestAddress := serializedTestAddress[:serializedAddressSizeWithoutServices]
+	deserializedLegacyTestAddress := addressStore.deserializeAddress(legacySerializedTestAddress)
+	if deserializedLegacyTestAddress.netAddress.Services != 0 {
+		t.Fatalf("expected the services of a legacy address to be unknown, but got %s",
+			deserializedLegacyTestAddress.netAddress.Services)
+	}
+	if deserializedLegacyTestAddress.connectionFailedCount != testAddress.connectionFailedCount {
+		t.Fatalf("expected connectionFailedCount %d but got %d",
+			testAddress.connectionFailedCount, deserializedLegacyTestAddress.connectionFailedCount)
+	}
 }
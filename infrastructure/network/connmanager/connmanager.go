@@ -34,7 +34,7 @@ type ConnectionManager struct {
 
 	activeRequested  map[string]*connectionRequest
 	pendingRequested map[string]*connectionRequest
-	activeOutgoing   map[string]struct{}
+	activeOutgoing   map[string]*appmessage.NetAddress
 	targetOutgoing   int
 	activeIncoming   map[string]struct{}
 	maxIncoming      int
@@ -54,7 +54,7 @@ func New(cfg *config.Config, netAdapter *netadapter.NetAdapter, addressManager *
 		addressManager:   addressManager,
 		activeRequested:  map[string]*connectionRequest{},
 		pendingRequested: map[string]*connectionRequest{},
-		activeOutgoing:   map[string]struct{}{},
+		activeOutgoing:   map[string]*appmessage.NetAddress{},
 		activeIncoming:   map[string]struct{}{},
 		resetLoopChan:    make(chan struct{}),
 		loopTicker:       time.NewTicker(connectionsLoopInterval),
@@ -2,6 +2,11 @@ package connmanager
 
 import "github.com/sedracoin/sedrad/app/appmessage"
 
+// minArchivalOutgoing is the amount of outgoing connections that are
+// deliberately made to archival peers, so that data below the pruning
+// point remains reachable
+const minArchivalOutgoing = 1
+
 // checkOutgoingConnections goes over all activeOutgoing and makes sure they are still active.
 // Then it opens connections so that we have targetOutgoing active connections
 func (c *ConnectionManager) checkOutgoingConnections(connSet connectionSet) {
@@ -31,7 +36,7 @@ func (c *ConnectionManager) checkOutgoingConnections(connSet connectionSet) {
 		liveConnections, c.targetOutgoing, c.targetOutgoing-liveConnections)
 
 	connectionsNeededCount := c.targetOutgoing - len(c.activeOutgoing)
-	netAddresses := c.addressManager.RandomAddresses(connectionsNeededCount, connectedAddresses)
+	netAddresses := c.outgoingCandidateAddresses(connectionsNeededCount, connectedAddresses)
 
 	for _, netAddress := range netAddresses {
 		addressString := netAddress.TCPAddress().String()
@@ -47,7 +52,7 @@ func (c *ConnectionManager) checkOutgoingConnections(connSet connectionSet) {
 		}
 		c.addressManager.MarkConnectionSuccess(netAddress)
 
-		c.activeOutgoing[addressString] = struct{}{}
+		c.activeOutgoing[addressString] = netAddress
 	}
 
 	if len(netAddresses) < connectionsNeededCount {
@@ -59,3 +64,36 @@ func (c *ConnectionManager) checkOutgoingConnections(connSet connectionSet) {
 		c.seedFromDNS()
 	}
 }
+
+// outgoingCandidateAddresses returns count random addresses to connect to,
+// preferring archival peers if there are not enough outgoing connections to them
+func (c *ConnectionManager) outgoingCandidateAddresses(count int,
+	connectedAddresses []*appmessage.NetAddress) []*appmessage.NetAddress {
+
+	archivalNeededCount := minArchivalOutgoing - c.archivalOutgoingCount()
+	if archivalNeededCount > count {
+		archivalNeededCount = count
+	}
+	if archivalNeededCount <= 0 {
+		return c.addressManager.RandomAddresses(count, connectedAddresses)
+	}
+
+	archivalAddresses := c.addressManager.RandomAddressesWithServices(
+		archivalNeededCount, appmessage.SFNodeArchival, connectedAddresses)
+	if len(archivalAddresses) > 0 {
+		log.Debugf("Connecting to %d archival peers", len(archivalAddresses))
+	}
+	otherAddresses := c.addressManager.RandomAddresses(count-len(archivalAddresses),
+		append(connectedAddresses, archivalAddresses...))
+	return append(archivalAddresses, otherAddresses...)
+}
+
+func (c *ConnectionManager) archivalOutgoingCount() int {
+	count := 0
+	for _, netAddress := range c.activeOutgoing {
+		if c.addressManager.Services(netAddress)&appmessage.SFNodeArchival != 0 {
+			count++
+		}
+	}
+	return count
+}
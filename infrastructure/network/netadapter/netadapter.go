@@ -3,6 +3,7 @@ package netadapter
 import (
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/sedracoin/sedrad/app/appmessage"
 	"github.com/sedracoin/sedrad/infrastructure/config"
@@ -10,7 +11,9 @@ import (
 	routerpkg "github.com/sedracoin/sedrad/infrastructure/network/netadapter/router"
 	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/server"
 	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/server/grpcserver"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/server/grpcserver/protowire"
 	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
 )
 
 // RouterInitializer is a function that initializes a new
@@ -33,16 +36,31 @@ type NetAdapter struct {
 
 	p2pConnections     map[*NetConnection]struct{}
 	p2pConnectionsLock sync.RWMutex
+
+	startTime        time.Time
+	p2pTraffic       *trafficCounter
+	uploadLimiter    *RateLimiter
+	ibdUploadLimiter *RateLimiter
 }
 
+// bytesPerKilobyte is the unit of the upload rate limits in the config
+const bytesPerKilobyte = 1000
+
 // NewNetAdapter creates and starts a new NetAdapter on the
 // given listeningPort
 func NewNetAdapter(cfg *config.Config) (*NetAdapter, error) {
-	netAdapterID, err := id.GenerateID()
+	p2pServer, err := grpcserver.NewP2PServer(cfg.Listeners)
 	if err != nil {
 		return nil, err
 	}
-	p2pServer, err := grpcserver.NewP2PServer(cfg.Listeners)
+	return NewNetAdapterWithP2PServer(cfg, p2pServer)
+}
+
+// NewNetAdapterWithP2PServer creates a new NetAdapter that uses the given
+// p2p server instead of listening on cfg.Listeners. It's meant for running
+// nodes over a transport other than gRPC, such as a simulated network.
+func NewNetAdapterWithP2PServer(cfg *config.Config, p2pServer server.P2PServer) (*NetAdapter, error) {
+	netAdapterID, err := id.GenerateID()
 	if err != nil {
 		return nil, err
 	}
@@ -57,6 +75,11 @@ func NewNetAdapter(cfg *config.Config) (*NetAdapter, error) {
 		rpcServer: rpcServer,
 
 		p2pConnections: make(map[*NetConnection]struct{}),
+
+		startTime:        time.Now(),
+		p2pTraffic:       newTrafficCounter(),
+		uploadLimiter:    NewRateLimiter(cfg.MaxUploadRate * bytesPerKilobyte),
+		ibdUploadLimiter: NewRateLimiter(cfg.MaxIBDUploadRate * bytesPerKilobyte),
 	}
 
 	adapter.p2pServer.SetOnConnectedHandler(adapter.onP2PConnectedHandler)
@@ -128,7 +151,22 @@ func (na *NetAdapter) P2PConnectionCount() int {
 }
 
 func (na *NetAdapter) onP2PConnectedHandler(connection server.Connection) error {
-	netConnection := newNetConnection(connection, na.p2pRouterInitializer, "on P2P connected")
+	// The outgoing route is throttled before the flows are started by the
+	// router initializer, so that they can't enqueue anything unthrottled
+	netConnection := newNetConnection(connection, func(router *routerpkg.Router, netConnection *NetConnection) {
+		netConnection.uploadLimiter = NewRateLimiter(na.cfg.MaxPeerUploadRate * bytesPerKilobyte)
+		router.OutgoingRoute().SetOnEnqueueHandler(func(message appmessage.Message) {
+			na.onP2PMessageEnqueueing(netConnection, message)
+		})
+		na.p2pRouterInitializer(router, netConnection)
+	}, "on P2P connected")
+	connection.SetOnMessageSendingHandler(func(command appmessage.MessageCommand, byteCount int) {
+		na.onP2PMessageSending(netConnection, command, byteCount)
+	})
+	connection.SetOnMessageReceivedHandler(func(command appmessage.MessageCommand, byteCount int) {
+		netConnection.traffic.addReceived(command, byteCount)
+		na.p2pTraffic.addReceived(command, byteCount)
+	})
 
 	na.p2pConnectionsLock.Lock()
 	defer na.p2pConnectionsLock.Unlock()
@@ -147,6 +185,66 @@ func (na *NetAdapter) onP2PConnectedHandler(connection server.Connection) error
 	return nil
 }
 
+// throttledCommands are the commands of the block payloads whose sending is
+// delayed by the upload limits. Other messages, such as pings and requests,
+// are small and time sensitive: delaying them would make peers seem slow to
+// respond, so they're accounted for by the limits but never wait for them.
+var throttledCommands = map[appmessage.MessageCommand]struct{}{
+	appmessage.CmdBlock:                    {},
+	appmessage.CmdIBDBlock:                 {},
+	appmessage.CmdBlockHeaders:             {},
+	appmessage.CmdBlockWithTrustedData:     {},
+	appmessage.CmdBlockWithTrustedDataV4:   {},
+	appmessage.CmdTrustedData:              {},
+	appmessage.CmdPruningPointProof:        {},
+	appmessage.CmdPruningPointUTXOSetChunk: {},
+	appmessage.CmdBlockTransactions:        {},
+	appmessage.CmdHistoricalBlocks:         {},
+}
+
+// onP2PMessageEnqueueing blocks until sending the given outgoing message is
+// allowed by the upload limits if it's a block payload. Block payloads wait
+// before they're enqueued rather than when they're sent, since all the
+// messages to a peer are sent from a single queue: a block that waits there
+// would delay every message that's queued after it.
+func (na *NetAdapter) onP2PMessageEnqueueing(netConnection *NetConnection, message appmessage.Message) {
+	if _, ok := throttledCommands[message.Command()]; !ok {
+		return
+	}
+	if netConnection.uploadLimiter == nil && na.uploadLimiter == nil {
+		return
+	}
+
+	messageProto, err := protowire.FromAppMessage(message)
+	if err != nil {
+		// The message fails to be converted again once it's sent,
+		// which disconnects the peer
+		return
+	}
+	byteCount := proto.Size(messageProto)
+	netConnection.uploadLimiter.Wait(byteCount)
+	na.uploadLimiter.Wait(byteCount)
+}
+
+// onP2PMessageSending accounts for the given outgoing message. Block payloads
+// had already been charged to the upload limits before they were enqueued, and
+// other messages are charged now, once they're sent.
+func (na *NetAdapter) onP2PMessageSending(netConnection *NetConnection,
+	command appmessage.MessageCommand, byteCount int) {
+
+	netConnection.traffic.addSent(command, byteCount)
+	na.p2pTraffic.addSent(command, byteCount)
+
+	// The IBD upload limit is charged by the flow that serves IBD blocks
+	// before it sends them, so that it doesn't read blocks faster than it's
+	// allowed to send them
+	if _, ok := throttledCommands[command]; ok {
+		return
+	}
+	netConnection.uploadLimiter.Charge(byteCount)
+	na.uploadLimiter.Charge(byteCount)
+}
+
 func (na *NetAdapter) onRPCConnectedHandler(connection server.Connection) error {
 	netConnection := newNetConnection(connection, na.rpcRouterInitializer, "on RPC connected")
 	netConnection.setOnDisconnectedHandler(func() {})
@@ -190,3 +288,32 @@ func (na *NetAdapter) P2PBroadcast(netConnections []*NetConnection, message appm
 	}
 	return nil
 }
+
+// P2PTrafficStats returns the traffic of all the p2p connections since the
+// NetAdapter was created, both in total and per message type
+func (na *NetAdapter) P2PTrafficStats() (total TrafficStats, byCommand map[appmessage.MessageCommand]TrafficStats) {
+	return na.p2pTraffic.stats()
+}
+
+// StartTime returns the time in which the NetAdapter was created
+func (na *NetAdapter) StartTime() time.Time {
+	return na.startTime
+}
+
+// IBDUploadLimiter returns the RateLimiter of the blocks that are sent
+// to peers that sync from this node. It returns nil if the rate is unlimited.
+// The limiter isn't charged when blocks are sent, so it's up to the flow that
+// sends them to charge it beforehand.
+func (na *NetAdapter) IBDUploadLimiter() *RateLimiter {
+	return na.ibdUploadLimiter
+}
+
+// P2PMessageSize returns the number of bytes that the given message takes
+// when it's sent to a peer
+func P2PMessageSize(message appmessage.Message) (int, error) {
+	messageProto, err := protowire.FromAppMessage(message)
+	if err != nil {
+		return 0, err
+	}
+	return proto.Size(messageProto), nil
+}
@@ -2,14 +2,18 @@ package netadapter
 
 import (
 	"fmt"
+	"math/big"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/subnetworks"
 
 	"github.com/sedracoin/sedrad/infrastructure/config"
 	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/router"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/server/simulatedserver"
 )
 
 // routerInitializerForTest returns new RouterInitializer which simply sets
@@ -153,6 +157,16 @@ func TestNetAdapter(t *testing.T) {
 		t.Fatalf("TestNetAdapter: expected '%d' message number but got %d", nonce, number)
 	}
 
+	// Ensure the broadcasted messages were accounted for by both sides
+	totalA, byCommandA := adapterA.P2PTrafficStats()
+	if totalA.MessagesSent != 2 || byCommandA[appmessage.CmdPing].MessagesSent != 2 || totalA.BytesSent == 0 {
+		t.Fatalf("TestNetAdapter: expected 2 sent ping messages to be accounted for, but got %+v", totalA)
+	}
+	totalB, byCommandB := adapterB.P2PTrafficStats()
+	if totalB.MessagesReceived != 1 || byCommandB[appmessage.CmdPing].BytesReceived != totalA.BytesSent/2 {
+		t.Fatalf("TestNetAdapter: expected 1 received ping message to be accounted for, but got %+v", totalB)
+	}
+
 	err = adapterA.Stop()
 	if err != nil {
 		t.Fatalf("TestNetAdapter: stopping adapter failed: %+v", err)
@@ -164,3 +178,152 @@ func TestNetAdapter(t *testing.T) {
 		t.Fatalf("TestNetAdapter: error expected at attempt to stop adapter second time, but got nothing")
 	}
 }
+
+// TestUploadLimitsThrottleOnlyBlocks makes sure that the upload limits delay
+// only block payloads, and that other messages are only accounted for
+func TestUploadLimitsThrottleOnlyBlocks(t *testing.T) {
+	const bytesPerSecond = 10_000
+	adapter := &NetAdapter{
+		p2pTraffic:    newTrafficCounter(),
+		uploadLimiter: NewRateLimiter(bytesPerSecond),
+	}
+	netConnection := &NetConnection{
+		traffic:       newTrafficCounter(),
+		uploadLimiter: NewRateLimiter(bytesPerSecond),
+	}
+
+	// Put both limiters in debt of about a second, as if another connection
+	// sent a block that exceeded the limits
+	adapter.uploadLimiter.Charge(2 * bytesPerSecond)
+	netConnection.uploadLimiter.Charge(2 * bytesPerSecond)
+
+	start := time.Now()
+	for _, message := range []appmessage.Message{appmessage.NewMsgPing(0), appmessage.NewMsgPong(0),
+		appmessage.NewMsgRequestIBDBlocks(nil), appmessage.NewMsgInvBlock(&externalapi.DomainHash{})} {
+
+		adapter.onP2PMessageEnqueueing(netConnection, message)
+		adapter.onP2PMessageSending(netConnection, message.Command(), 100)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Fatalf("TestUploadLimitsThrottleOnlyBlocks: expected control messages not to be delayed, "+
+			"but they were delayed for %s", elapsed)
+	}
+	total, _ := adapter.P2PTrafficStats()
+	if total.MessagesSent != 4 || total.BytesSent != 400 {
+		t.Fatalf("TestUploadLimitsThrottleOnlyBlocks: expected the control messages to be accounted for, "+
+			"but got %+v", total)
+	}
+	if timeUntilQuota := adapter.uploadLimiter.timeUntilQuota(); timeUntilQuota < 950*time.Millisecond {
+		t.Fatalf("TestUploadLimitsThrottleOnlyBlocks: expected the control messages to be charged, "+
+			"but the limiter allows sending in %s", timeUntilQuota)
+	}
+
+	start = time.Now()
+	adapter.onP2PMessageEnqueueing(netConnection, newTestBlock(100))
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Fatalf("TestUploadLimitsThrottleOnlyBlocks: expected a block to wait for the upload limit, "+
+			"but it waited for %s", elapsed)
+	}
+}
+
+// TestThrottledBlockDoesNotDelayPong makes sure that a pong that is enqueued
+// after a block that waits for the upload limit is sent without waiting for it
+func TestThrottledBlockDoesNotDelayPong(t *testing.T) {
+	const addressA, addressB = "10.0.0.1:16111", "10.0.0.2:16111"
+
+	network, err := simulatedserver.NewNetwork(simulatedserver.LinkConfig{}, 0)
+	if err != nil {
+		t.Fatalf("TestThrottledBlockDoesNotDelayPong: NewNetwork failed: %+v", err)
+	}
+
+	cfgA, cfgB := config.DefaultConfig(), config.DefaultConfig()
+	cfgA.MaxPeerUploadRate = 10
+
+	p2pServerB, err := network.NewP2PServer(addressB)
+	if err != nil {
+		t.Fatalf("TestThrottledBlockDoesNotDelayPong: NewP2PServer failed: %+v", err)
+	}
+	adapterB, err := NewNetAdapterWithP2PServer(cfgB, p2pServerB)
+	if err != nil {
+		t.Fatalf("TestThrottledBlockDoesNotDelayPong: NetAdapter instantiation failed: %+v", err)
+	}
+	incomingRoutes := make(chan *router.Route, 1)
+	adapterB.SetP2PRouterInitializer(func(router *router.Router, connection *NetConnection) {
+		route, err := router.AddIncomingRoute("B", []appmessage.MessageCommand{appmessage.CmdBlock, appmessage.CmdPong})
+		if err != nil {
+			t.Errorf("TestThrottledBlockDoesNotDelayPong: AddIncomingRoute failed: %+v", err)
+		}
+		incomingRoutes <- route
+	})
+	err = p2pServerB.Start()
+	if err != nil {
+		t.Fatalf("TestThrottledBlockDoesNotDelayPong: Start() failed: %+v", err)
+	}
+
+	p2pServerA, err := network.NewP2PServer(addressA)
+	if err != nil {
+		t.Fatalf("TestThrottledBlockDoesNotDelayPong: NewP2PServer failed: %+v", err)
+	}
+	adapterA, err := NewNetAdapterWithP2PServer(cfgA, p2pServerA)
+	if err != nil {
+		t.Fatalf("TestThrottledBlockDoesNotDelayPong: NetAdapter instantiation failed: %+v", err)
+	}
+	outgoingRoutes := make(chan *router.Route, 1)
+	adapterA.SetP2PRouterInitializer(func(router *router.Router, connection *NetConnection) {
+		outgoingRoutes <- router.OutgoingRoute()
+	})
+	err = p2pServerA.Start()
+	if err != nil {
+		t.Fatalf("TestThrottledBlockDoesNotDelayPong: Start() failed: %+v", err)
+	}
+	err = adapterA.P2PConnect(addressB)
+	if err != nil {
+		t.Fatalf("TestThrottledBlockDoesNotDelayPong: connection to %s failed: %+v", addressB, err)
+	}
+	outgoingRoute := <-outgoingRoutes
+	incomingRoute := <-incomingRoutes
+
+	// The block is worth about three seconds of the peer's upload limit, so
+	// it's sent only after about two seconds
+	blockEnqueued := make(chan error, 1)
+	go func() {
+		blockEnqueued <- outgoingRoute.Enqueue(newTestBlock(3 * int(cfgA.MaxPeerUploadRate) * bytesPerKilobyte))
+	}()
+	time.Sleep(100 * time.Millisecond)
+	err = outgoingRoute.Enqueue(appmessage.NewMsgPong(1))
+	if err != nil {
+		t.Fatalf("TestThrottledBlockDoesNotDelayPong: Enqueue failed: %+v", err)
+	}
+
+	message, err := incomingRoute.DequeueWithTimeout(time.Second)
+	if err != nil {
+		t.Fatalf("TestThrottledBlockDoesNotDelayPong: expected the pong not to wait for the block: %+v", err)
+	}
+	if message.Command() != appmessage.CmdPong {
+		t.Fatalf("TestThrottledBlockDoesNotDelayPong: expected the pong to arrive first, but got %s",
+			message.Command())
+	}
+
+	err = <-blockEnqueued
+	if err != nil {
+		t.Fatalf("TestThrottledBlockDoesNotDelayPong: Enqueue failed: %+v", err)
+	}
+	message, err = incomingRoute.DequeueWithTimeout(5 * time.Second)
+	if err != nil {
+		t.Fatalf("TestThrottledBlockDoesNotDelayPong: expected the block to arrive: %+v", err)
+	}
+	if message.Command() != appmessage.CmdBlock {
+		t.Fatalf("TestThrottledBlockDoesNotDelayPong: expected a block, but got %s", message.Command())
+	}
+}
+
+// newTestBlock returns a block message whose single transaction has
+// a payload of the given size
+func newTestBlock(payloadSize int) *appmessage.MsgBlock {
+	header := appmessage.NewBlockHeader(0, nil, &externalapi.DomainHash{}, &externalapi.DomainHash{},
+		&externalapi.DomainHash{}, 0, 0, 0, 0, big.NewInt(0), &externalapi.DomainHash{})
+	block := appmessage.NewMsgBlock(header)
+	block.AddTransaction(appmessage.NewSubnetworkMsgTx(0, nil, nil, &subnetworks.SubnetworkIDCoinbase, 0,
+		make([]byte, payloadSize)))
+	return block
+}
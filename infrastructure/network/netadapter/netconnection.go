@@ -18,6 +18,9 @@ type NetConnection struct {
 	router                *routerpkg.Router
 	onDisconnectedHandler server.OnDisconnectedHandler
 	isRouterClosed        uint32
+
+	traffic       *trafficCounter
+	uploadLimiter *RateLimiter
 }
 
 func newNetConnection(connection server.Connection, routerInitializer RouterInitializer, name string) *NetConnection {
@@ -26,6 +29,7 @@ func newNetConnection(connection server.Connection, routerInitializer RouterInit
 	netConnection := &NetConnection{
 		connection: connection,
 		router:     router,
+		traffic:    newTrafficCounter(),
 	}
 
 	netConnection.connection.SetOnDisconnectedHandler(func() {
@@ -91,6 +95,11 @@ func (c *NetConnection) Disconnect() {
 	}
 }
 
+// TrafficStats returns the traffic of this connection, both in total and per message type
+func (c *NetConnection) TrafficStats() (total TrafficStats, byCommand map[appmessage.MessageCommand]TrafficStats) {
+	return c.traffic.stats()
+}
+
 // SetOnInvalidMessageHandler sets the invalid message handler for this connection
 func (c *NetConnection) SetOnInvalidMessageHandler(onInvalidMessageHandler server.OnInvalidMessageHandler) {
 	c.connection.SetOnInvalidMessageHandler(onInvalidMessageHandler)
@@ -0,0 +1,85 @@
+package netadapter
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter limits the rate in which bytes are sent. Bytes that are sent
+// beyond the allowed rate are accounted as debt, which has to be repaid by
+// waiting before sending any more bytes.
+//
+// A nil RateLimiter does not limit anything.
+type RateLimiter struct {
+	bytesPerSecond float64
+	burst          float64
+
+	lock           sync.Mutex
+	balance        float64
+	lastRefillTime time.Time
+}
+
+// NewRateLimiter returns a RateLimiter that allows sending bytesPerSecond bytes
+// per second on average. It returns nil if bytesPerSecond is 0, which means that
+// the rate is unlimited.
+func NewRateLimiter(bytesPerSecond uint64) *RateLimiter {
+	if bytesPerSecond == 0 {
+		return nil
+	}
+	return &RateLimiter{
+		bytesPerSecond: float64(bytesPerSecond),
+		burst:          float64(bytesPerSecond),
+		balance:        float64(bytesPerSecond),
+		lastRefillTime: time.Now(),
+	}
+}
+
+// Wait charges the limiter with the given amount of bytes, and blocks until
+// they are allowed to be sent
+func (rl *RateLimiter) Wait(byteCount int) {
+	rl.Charge(byteCount)
+	rl.WaitForQuota()
+}
+
+// Charge charges the limiter with the given amount of bytes without blocking.
+// It's used to account for bytes that had already been sent.
+func (rl *RateLimiter) Charge(byteCount int) {
+	if rl == nil {
+		return
+	}
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+
+	rl.refill()
+	rl.balance -= float64(byteCount)
+}
+
+// WaitForQuota blocks until all the bytes the limiter was charged with
+// are allowed to have been sent
+func (rl *RateLimiter) WaitForQuota() {
+	if rl == nil {
+		return
+	}
+	time.Sleep(rl.timeUntilQuota())
+}
+
+func (rl *RateLimiter) timeUntilQuota() time.Duration {
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+
+	rl.refill()
+	if rl.balance >= 0 {
+		return 0
+	}
+	return time.Duration(-rl.balance / rl.bytesPerSecond * float64(time.Second))
+}
+
+// refill must be called with the lock held
+func (rl *RateLimiter) refill() {
+	now := time.Now()
+	rl.balance += now.Sub(rl.lastRefillTime).Seconds() * rl.bytesPerSecond
+	if rl.balance > rl.burst {
+		rl.balance = rl.burst
+	}
+	rl.lastRefillTime = now
+}
@@ -0,0 +1,41 @@
+package netadapter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	if NewRateLimiter(0) != nil {
+		t.Fatalf("expected a zero rate to be unlimited")
+	}
+	// A nil limiter must never block
+	var unlimited *RateLimiter
+	unlimited.Wait(1_000_000_000)
+
+	const bytesPerSecond = 10_000
+	limiter := NewRateLimiter(bytesPerSecond)
+
+	// The first second's worth of bytes is allowed immediately
+	start := time.Now()
+	limiter.Wait(bytesPerSecond)
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Fatalf("expected the initial burst not to block, but it blocked for %s", elapsed)
+	}
+
+	// Going over the rate puts the limiter in debt, which must be repaid by waiting
+	limiter.Charge(bytesPerSecond / 5)
+	if timeUntilQuota := limiter.timeUntilQuota(); timeUntilQuota < 150*time.Millisecond ||
+		timeUntilQuota > 200*time.Millisecond {
+
+		t.Fatalf("expected to wait about 200ms, but got %s", timeUntilQuota)
+	}
+	start = time.Now()
+	limiter.WaitForQuota()
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Fatalf("expected WaitForQuota to block for about 200ms, but it blocked for %s", elapsed)
+	}
+	if timeUntilQuota := limiter.timeUntilQuota(); timeUntilQuota != 0 {
+		t.Fatalf("expected the debt to be repaid, but still have to wait %s", timeUntilQuota)
+	}
+}
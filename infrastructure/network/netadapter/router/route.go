@@ -26,6 +26,11 @@ var (
 	ErrRouteCapacityReached = protocolerrors.New(false, "route capacity has been reached")
 )
 
+// OnEnqueueHandler is a function that is to be called right before
+// a message is enqueued to a route. It may block in order to throttle
+// the route, in which case only the caller of Enqueue is delayed.
+type OnEnqueueHandler func(message appmessage.Message)
+
 // Route represents an incoming or outgoing Router route
 type Route struct {
 	name    string
@@ -35,6 +40,8 @@ type Route struct {
 	closed    bool
 	closeLock sync.Mutex
 	capacity  int
+
+	onEnqueueHandler OnEnqueueHandler
 }
 
 // NewRoute create a new Route
@@ -51,8 +58,20 @@ func newRouteWithCapacity(name string, capacity int) *Route {
 	}
 }
 
+// SetOnEnqueueHandler sets the handler that is called before every message
+// is enqueued. It must be set before the route is used.
+func (r *Route) SetOnEnqueueHandler(onEnqueueHandler OnEnqueueHandler) {
+	r.onEnqueueHandler = onEnqueueHandler
+}
+
 // Enqueue enqueues a message to the Route
 func (r *Route) Enqueue(message appmessage.Message) error {
+	// The handler is called without holding closeLock, so that a blocking
+	// handler doesn't block closing the route
+	if r.onEnqueueHandler != nil {
+		r.onEnqueueHandler(message)
+	}
+
 	r.closeLock.Lock()
 	defer r.closeLock.Unlock()
 
@@ -12,6 +12,7 @@ import (
 
 	routerpkg "github.com/sedracoin/sedrad/infrastructure/network/netadapter/router"
 	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/server/grpcserver/protowire"
 )
@@ -67,6 +68,10 @@ func (c *gRPCConnection) sendLoop() error {
 			return err
 		}
 
+		if c.onMessageSendingHandler != nil {
+			c.onMessageSendingHandler(message.Command(), proto.Size(messageProto))
+		}
+
 		err = c.send(messageProto)
 		if err != nil {
 			return err
@@ -93,6 +98,10 @@ func (c *gRPCConnection) receiveLoop() error {
 			return err
 		}
 
+		if c.onMessageReceivedHandler != nil {
+			c.onMessageReceivedHandler(message.Command(), proto.Size(protoMessage))
+		}
+
 		messageNumber++
 		message.SetMessageNumber(messageNumber)
 		message.SetReceivedAt(time.Now())
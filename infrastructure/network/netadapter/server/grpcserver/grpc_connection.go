@@ -33,6 +33,9 @@ type gRPCConnection struct {
 	onDisconnectedHandler   server.OnDisconnectedHandler
 	onInvalidMessageHandler server.OnInvalidMessageHandler
 
+	onMessageSendingHandler  server.OnMessageSendingHandler
+	onMessageReceivedHandler server.OnMessageReceivedHandler
+
 	isConnected uint32
 }
 
@@ -96,6 +99,14 @@ func (c *gRPCConnection) SetOnInvalidMessageHandler(onInvalidMessageHandler serv
 	c.onInvalidMessageHandler = onInvalidMessageHandler
 }
 
+func (c *gRPCConnection) SetOnMessageSendingHandler(onMessageSendingHandler server.OnMessageSendingHandler) {
+	c.onMessageSendingHandler = onMessageSendingHandler
+}
+
+func (c *gRPCConnection) SetOnMessageReceivedHandler(onMessageReceivedHandler server.OnMessageReceivedHandler) {
+	c.onMessageReceivedHandler = onMessageReceivedHandler
+}
+
 func (c *gRPCConnection) IsOutbound() bool {
 	return c.lowLevelClientConnection != nil
 }
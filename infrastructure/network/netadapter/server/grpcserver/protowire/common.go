@@ -105,6 +105,7 @@ func (x *NetAddress) toAppMessage() (*appmessage.NetAddress, error) {
 	}
 	return &appmessage.NetAddress{
 		Timestamp: mstime.UnixMilliseconds(x.Timestamp),
+		Services:  appmessage.ServiceFlag(x.Services),
 		IP:        x.Ip,
 		Port:      uint16(x.Port),
 	}, nil
@@ -113,6 +114,7 @@ func (x *NetAddress) toAppMessage() (*appmessage.NetAddress, error) {
 func appMessageNetAddressToProto(address *appmessage.NetAddress) *NetAddress {
 	return &NetAddress{
 		Timestamp: address.Timestamp.UnixMilliseconds(),
+		Services:  uint64(address.Services),
 		Ip:        address.IP,
 		Port:      uint32(address.Port),
 	}
@@ -148,6 +150,16 @@ func (x *UtxoEntry) toAppMessage() (*appmessage.UTXOEntry, error) {
 	}, nil
 }
 
+func (x *UtxoEntry) fromAppMessage(utxoEntry *appmessage.UTXOEntry) {
+	x.Amount = utxoEntry.Amount
+	x.ScriptPublicKey = &ScriptPublicKey{
+		Script:  utxoEntry.ScriptPublicKey.Script,
+		Version: uint32(utxoEntry.ScriptPublicKey.Version),
+	}
+	x.BlockDaaScore = utxoEntry.BlockDAAScore
+	x.IsCoinbase = utxoEntry.IsCoinbase
+}
+
 func (x *ScriptPublicKey) toAppMessage() (*externalapi.ScriptPublicKey, error) {
 	if x == nil {
 		return nil, errors.Wrapf(errorNil, "ScriptPublicKey is nil")
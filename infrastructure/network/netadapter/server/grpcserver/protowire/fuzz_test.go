@@ -0,0 +1,105 @@
+package protowire
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/dagconfig"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/id"
+	"google.golang.org/protobuf/proto"
+)
+
+// FuzzToAppMessage checks that converting any SedradMessage that a peer may send
+// into an appmessage never panics, and that every message that converts successfully
+// can be converted back to a SedradMessage that converts to the same appmessage.
+func FuzzToAppMessage(f *testing.F) {
+	for _, message := range seedMessages(f) {
+		protoMessage, err := FromAppMessage(message)
+		if err != nil {
+			f.Fatalf("FromAppMessage: %+v", err)
+		}
+		data, err := proto.Marshal(protoMessage)
+		if err != nil {
+			f.Fatalf("Marshal: %+v", err)
+		}
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		protoMessage := &SedradMessage{}
+		err := proto.Unmarshal(data, protoMessage)
+		if err != nil {
+			return
+		}
+		message, err := protoMessage.ToAppMessage()
+		if err != nil {
+			return
+		}
+
+		convertedData := marshalAppMessage(t, message)
+		convertedProtoMessage := &SedradMessage{}
+		err = proto.Unmarshal(convertedData, convertedProtoMessage)
+		if err != nil {
+			t.Fatalf("Unmarshal of the converted %s: %+v", message.Command(), err)
+		}
+		convertedMessage, err := convertedProtoMessage.ToAppMessage()
+		if err != nil {
+			t.Fatalf("ToAppMessage of the converted %s: %+v", message.Command(), err)
+		}
+		if !bytes.Equal(marshalAppMessage(t, convertedMessage), convertedData) {
+			t.Fatalf("Converting %s back and forth changed it", message.Command())
+		}
+	})
+}
+
+func marshalAppMessage(t *testing.T, message appmessage.Message) []byte {
+	protoMessage, err := FromAppMessage(message)
+	if err != nil {
+		t.Fatalf("FromAppMessage of %s: %+v", message.Command(), err)
+	}
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(protoMessage)
+	if err != nil {
+		t.Fatalf("Marshal of %s: %+v", message.Command(), err)
+	}
+	return data
+}
+
+// seedMessages returns valid messages of the kinds peers send each other
+func seedMessages(f *testing.F) []appmessage.Message {
+	genesis := dagconfig.SimnetParams.GenesisBlock
+	genesisHash := dagconfig.SimnetParams.GenesisHash
+	msgBlock := appmessage.DomainBlockToMsgBlock(genesis)
+	msgTx := appmessage.DomainTransactionToMsgTx(genesis.Transactions[0])
+	transactionID := &externalapi.DomainTransactionID{}
+
+	peerID, err := id.GenerateID()
+	if err != nil {
+		f.Fatalf("GenerateID: %+v", err)
+	}
+	netAddress := appmessage.NewNetAddressIPPort([]byte{10, 0, 0, 1}, 16111)
+
+	return []appmessage.Message{
+		appmessage.NewMsgVersion(netAddress, peerID, dagconfig.SimnetParams.Name, nil, 5),
+		appmessage.NewMsgVerAck(),
+		appmessage.NewMsgReady(),
+		appmessage.NewMsgRequestAddresses(false, nil),
+		appmessage.NewMsgAddresses([]*appmessage.NetAddress{netAddress}),
+		appmessage.NewMsgPing(1),
+		appmessage.NewMsgPong(1),
+		appmessage.NewMsgReject("reason"),
+		appmessage.NewMsgInvBlock(genesisHash),
+		msgBlock,
+		appmessage.NewMsgIBDBlock(msgBlock),
+		appmessage.NewMsgBlockLocator([]*externalapi.DomainHash{genesisHash}),
+		appmessage.NewMsgRequstHeaders(genesisHash, genesisHash),
+		appmessage.NewBlockHeadersMessage([]*appmessage.MsgBlockHeader{&msgBlock.Header}),
+		appmessage.NewMsgPruningPointProof([][]*appmessage.MsgBlockHeader{{&msgBlock.Header}}),
+		appmessage.NewMsgInvTransaction([]*externalapi.DomainTransactionID{transactionID}),
+		appmessage.NewMsgRequestTransactions([]*externalapi.DomainTransactionID{transactionID}),
+		appmessage.NewMsgTransactionNotFound(transactionID),
+		msgTx,
+		appmessage.NewMsgStemTransaction(msgTx),
+	}
+}
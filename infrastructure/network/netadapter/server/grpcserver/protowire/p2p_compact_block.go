@@ -0,0 +1,74 @@
+package protowire
+
+import (
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *SedradMessage_CompactBlock) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SedradMessage_CompactBlock is nil")
+	}
+	return x.CompactBlock.toAppMessage()
+}
+
+func (x *CompactBlockMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "CompactBlockMessage is nil")
+	}
+	if len(x.ShortIds) > appmessage.MaxShortIDsPerCompactBlock {
+		return nil, errors.Errorf("too many short IDs for message "+
+			"[count %d, max %d]", len(x.ShortIds), appmessage.MaxShortIDsPerCompactBlock)
+	}
+	header, err := x.Header.toAppMessage()
+	if err != nil {
+		return nil, err
+	}
+
+	prefilledTransactions := make([]*appmessage.PrefilledTransaction, len(x.PrefilledTransactions))
+	for i, prefilledTransaction := range x.PrefilledTransactions {
+		if prefilledTransaction == nil {
+			return nil, errors.Wrapf(errorNil, "PrefilledTransaction is nil")
+		}
+		msgTx, err := prefilledTransaction.Transaction.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+		prefilledTransactions[i] = &appmessage.PrefilledTransaction{
+			Index:       prefilledTransaction.Index,
+			Transaction: msgTx.(*appmessage.MsgTx),
+		}
+	}
+
+	return appmessage.NewMsgCompactBlock(header, x.ShortIdNonce, x.ShortIds, prefilledTransactions), nil
+}
+
+func (x *SedradMessage_CompactBlock) fromAppMessage(msgCompactBlock *appmessage.MsgCompactBlock) error {
+	if len(msgCompactBlock.ShortIDs) > appmessage.MaxShortIDsPerCompactBlock {
+		return errors.Errorf("too many short IDs for message "+
+			"[count %d, max %d]", len(msgCompactBlock.ShortIDs), appmessage.MaxShortIDsPerCompactBlock)
+	}
+	protoHeader := new(BlockHeader)
+	err := protoHeader.fromAppMessage(&msgCompactBlock.Header)
+	if err != nil {
+		return err
+	}
+
+	protoPrefilledTransactions := make([]*PrefilledTransaction, len(msgCompactBlock.PrefilledTransactions))
+	for i, prefilledTransaction := range msgCompactBlock.PrefilledTransactions {
+		protoTx := new(TransactionMessage)
+		protoTx.fromAppMessage(prefilledTransaction.Transaction)
+		protoPrefilledTransactions[i] = &PrefilledTransaction{
+			Index:       prefilledTransaction.Index,
+			Transaction: protoTx,
+		}
+	}
+
+	x.CompactBlock = &CompactBlockMessage{
+		Header:                protoHeader,
+		ShortIdNonce:          msgCompactBlock.ShortIDNonce,
+		ShortIds:              msgCompactBlock.ShortIDs,
+		PrefilledTransactions: protoPrefilledTransactions,
+	}
+	return nil
+}
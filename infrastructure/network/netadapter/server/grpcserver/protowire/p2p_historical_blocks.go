@@ -0,0 +1,206 @@
+package protowire
+
+import (
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *SedradMessage_RequestHistoricalBlocks) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SedradMessage_RequestHistoricalBlocks is nil")
+	}
+	return x.RequestHistoricalBlocks.toAppMessage()
+}
+
+func (x *RequestHistoricalBlocksMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "RequestHistoricalBlocksMessage is nil")
+	}
+	if len(x.Hashes) > appmessage.MaxRequestHistoricalBlocksHashes {
+		return nil, errors.Errorf("too many hashes for message "+
+			"[count %d, max %d]", len(x.Hashes), appmessage.MaxRequestHistoricalBlocksHashes)
+	}
+	hashes, err := protoHashesToDomain(x.Hashes)
+	if err != nil {
+		return nil, err
+	}
+	return appmessage.NewMsgRequestHistoricalBlocks(hashes, x.IncludeAcceptanceData), nil
+}
+
+func (x *SedradMessage_RequestHistoricalBlocks) fromAppMessage(
+	msgRequestHistoricalBlocks *appmessage.MsgRequestHistoricalBlocks) error {
+
+	if len(msgRequestHistoricalBlocks.Hashes) > appmessage.MaxRequestHistoricalBlocksHashes {
+		return errors.Errorf("too many hashes for message "+
+			"[count %d, max %d]", len(msgRequestHistoricalBlocks.Hashes), appmessage.MaxRequestHistoricalBlocksHashes)
+	}
+	x.RequestHistoricalBlocks = &RequestHistoricalBlocksMessage{
+		Hashes:                domainHashesToProto(msgRequestHistoricalBlocks.Hashes),
+		IncludeAcceptanceData: msgRequestHistoricalBlocks.IncludeAcceptanceData,
+	}
+	return nil
+}
+
+func (x *SedradMessage_HistoricalBlocks) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SedradMessage_HistoricalBlocks is nil")
+	}
+	return x.HistoricalBlocks.toAppMessage()
+}
+
+func (x *HistoricalBlocksMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "HistoricalBlocksMessage is nil")
+	}
+	if len(x.Blocks) > appmessage.MaxRequestHistoricalBlocksHashes {
+		return nil, errors.Errorf("too many blocks for message "+
+			"[count %d, max %d]", len(x.Blocks), appmessage.MaxRequestHistoricalBlocksHashes)
+	}
+	blocks := make([]*appmessage.HistoricalBlock, len(x.Blocks))
+	for i, block := range x.Blocks {
+		appBlock, err := block.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+		blocks[i] = appBlock
+	}
+	return appmessage.NewMsgHistoricalBlocks(blocks), nil
+}
+
+func (x *HistoricalBlock) toAppMessage() (*appmessage.HistoricalBlock, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "HistoricalBlock is nil")
+	}
+	hash, err := x.Hash.toDomain()
+	if err != nil {
+		return nil, err
+	}
+
+	var msgBlock *appmessage.MsgBlock
+	if x.Block != nil {
+		msgBlock, err = x.Block.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	acceptanceData := make([]*appmessage.BlockAcceptanceData, len(x.AcceptanceData))
+	for i, blockAcceptanceData := range x.AcceptanceData {
+		acceptanceData[i], err = blockAcceptanceData.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &appmessage.HistoricalBlock{
+		Hash:           hash,
+		Block:          msgBlock,
+		AcceptanceData: acceptanceData,
+	}, nil
+}
+
+func (x *BlockAcceptanceData) toAppMessage() (*appmessage.BlockAcceptanceData, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "BlockAcceptanceData is nil")
+	}
+	blockHash, err := x.BlockHash.toDomain()
+	if err != nil {
+		return nil, err
+	}
+
+	transactionAcceptanceData := make([]*appmessage.TransactionAcceptanceData, len(x.TransactionAcceptanceData))
+	for i, singleTransactionAcceptanceData := range x.TransactionAcceptanceData {
+		transactionAcceptanceData[i], err = singleTransactionAcceptanceData.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &appmessage.BlockAcceptanceData{
+		BlockHash:                 blockHash,
+		TransactionAcceptanceData: transactionAcceptanceData,
+	}, nil
+}
+
+func (x *TransactionAcceptanceData) toAppMessage() (*appmessage.TransactionAcceptanceData, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "TransactionAcceptanceData is nil")
+	}
+	msgTx, err := x.Transaction.toAppMessage()
+	if err != nil {
+		return nil, err
+	}
+
+	utxoEntries := make([]*appmessage.UTXOEntry, len(x.TransactionInputUtxoEntries))
+	for i, utxoEntry := range x.TransactionInputUtxoEntries {
+		utxoEntries[i], err = utxoEntry.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &appmessage.TransactionAcceptanceData{
+		Transaction:                 msgTx.(*appmessage.MsgTx),
+		Fee:                         x.Fee,
+		IsAccepted:                  x.IsAccepted,
+		TransactionInputUTXOEntries: utxoEntries,
+	}, nil
+}
+
+func (x *SedradMessage_HistoricalBlocks) fromAppMessage(msgHistoricalBlocks *appmessage.MsgHistoricalBlocks) error {
+	if len(msgHistoricalBlocks.Blocks) > appmessage.MaxRequestHistoricalBlocksHashes {
+		return errors.Errorf("too many blocks for message "+
+			"[count %d, max %d]", len(msgHistoricalBlocks.Blocks), appmessage.MaxRequestHistoricalBlocksHashes)
+	}
+	blocks := make([]*HistoricalBlock, len(msgHistoricalBlocks.Blocks))
+	for i, block := range msgHistoricalBlocks.Blocks {
+		blocks[i] = &HistoricalBlock{}
+		err := blocks[i].fromAppMessage(block)
+		if err != nil {
+			return err
+		}
+	}
+	x.HistoricalBlocks = &HistoricalBlocksMessage{
+		Blocks: blocks,
+	}
+	return nil
+}
+
+func (x *HistoricalBlock) fromAppMessage(historicalBlock *appmessage.HistoricalBlock) error {
+	x.Hash = domainHashToProto(historicalBlock.Hash)
+	if historicalBlock.Block != nil {
+		x.Block = &BlockMessage{}
+		err := x.Block.fromAppMessage(historicalBlock.Block)
+		if err != nil {
+			return err
+		}
+	}
+
+	x.AcceptanceData = make([]*BlockAcceptanceData, len(historicalBlock.AcceptanceData))
+	for i, blockAcceptanceData := range historicalBlock.AcceptanceData {
+		transactionAcceptanceData := make([]*TransactionAcceptanceData,
+			len(blockAcceptanceData.TransactionAcceptanceData))
+		for j, singleTransactionAcceptanceData := range blockAcceptanceData.TransactionAcceptanceData {
+			protoTx := new(TransactionMessage)
+			protoTx.fromAppMessage(singleTransactionAcceptanceData.Transaction)
+
+			utxoEntries := make([]*UtxoEntry, len(singleTransactionAcceptanceData.TransactionInputUTXOEntries))
+			for k, utxoEntry := range singleTransactionAcceptanceData.TransactionInputUTXOEntries {
+				utxoEntries[k] = &UtxoEntry{}
+				utxoEntries[k].fromAppMessage(utxoEntry)
+			}
+
+			transactionAcceptanceData[j] = &TransactionAcceptanceData{
+				Transaction:                 protoTx,
+				Fee:                         singleTransactionAcceptanceData.Fee,
+				IsAccepted:                  singleTransactionAcceptanceData.IsAccepted,
+				TransactionInputUtxoEntries: utxoEntries,
+			}
+		}
+		x.AcceptanceData[i] = &BlockAcceptanceData{
+			BlockHash:                 domainHashToProto(blockAcceptanceData.BlockHash),
+			TransactionAcceptanceData: transactionAcceptanceData,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package protowire
+
+import (
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *SedradMessage_RequestBlockTransactions) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SedradMessage_RequestBlockTransactions is nil")
+	}
+	return x.RequestBlockTransactions.toAppMessage()
+}
+
+func (x *RequestBlockTransactionsMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "RequestBlockTransactionsMessage is nil")
+	}
+	if len(x.Indexes) > appmessage.MaxShortIDsPerCompactBlock {
+		return nil, errors.Errorf("too many indexes for message "+
+			"[count %d, max %d]", len(x.Indexes), appmessage.MaxShortIDsPerCompactBlock)
+	}
+	blockHash, err := x.BlockHash.toDomain()
+	if err != nil {
+		return nil, err
+	}
+
+	return appmessage.NewMsgRequestBlockTransactions(blockHash, x.Indexes), nil
+}
+
+func (x *SedradMessage_RequestBlockTransactions) fromAppMessage(
+	msgRequestBlockTransactions *appmessage.MsgRequestBlockTransactions) error {
+
+	x.RequestBlockTransactions = &RequestBlockTransactionsMessage{
+		BlockHash: domainHashToProto(msgRequestBlockTransactions.BlockHash),
+		Indexes:   msgRequestBlockTransactions.Indexes,
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package protowire
+
+import (
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *SedradMessage_StemTransaction) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SedradMessage_StemTransaction is nil")
+	}
+	return x.StemTransaction.toAppMessage()
+}
+
+func (x *StemTransactionMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "StemTransactionMessage is nil")
+	}
+	msgTx, err := x.Transaction.toAppMessage()
+	if err != nil {
+		return nil, err
+	}
+	return appmessage.NewMsgStemTransaction(msgTx.(*appmessage.MsgTx)), nil
+}
+
+func (x *SedradMessage_StemTransaction) fromAppMessage(msgStemTransaction *appmessage.MsgStemTransaction) error {
+	protoTx := new(TransactionMessage)
+	protoTx.fromAppMessage(msgStemTransaction.Transaction)
+	x.StemTransaction = &StemTransactionMessage{
+		Transaction: protoTx,
+	}
+	return nil
+}
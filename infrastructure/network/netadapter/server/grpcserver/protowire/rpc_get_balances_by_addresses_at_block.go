@@ -0,0 +1,92 @@
+package protowire
+
+import (
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *SedradMessage_GetBalancesByAddressesAtBlockRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SedradMessage_GetBalancesByAddressesAtBlockRequest is nil")
+	}
+	return x.GetBalancesByAddressesAtBlockRequest.toAppMessage()
+}
+
+func (x *SedradMessage_GetBalancesByAddressesAtBlockRequest) fromAppMessage(message *appmessage.GetBalancesByAddressesAtBlockRequestMessage) error {
+	x.GetBalancesByAddressesAtBlockRequest = &GetBalancesByAddressesAtBlockRequestMessage{
+		Addresses: message.Addresses,
+		BlockHash: message.BlockHash,
+		DaaScore:  message.DAAScore,
+		Timestamp: message.Timestamp,
+	}
+	return nil
+}
+
+func (x *GetBalancesByAddressesAtBlockRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetBalancesByAddressesAtBlockRequestMessage is nil")
+	}
+	return &appmessage.GetBalancesByAddressesAtBlockRequestMessage{
+		Addresses: x.Addresses,
+		BlockHash: x.BlockHash,
+		DAAScore:  x.DaaScore,
+		Timestamp: x.Timestamp,
+	}, nil
+}
+
+func (x *SedradMessage_GetBalancesByAddressesAtBlockResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SedradMessage_GetBalancesByAddressesAtBlockResponse is nil")
+	}
+	return x.GetBalancesByAddressesAtBlockResponse.toAppMessage()
+}
+
+func (x *SedradMessage_GetBalancesByAddressesAtBlockResponse) fromAppMessage(message *appmessage.GetBalancesByAddressesAtBlockResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	entries := make([]*BalancesByAddressEntry, len(message.Entries))
+	for i, entry := range message.Entries {
+		entries[i] = &BalancesByAddressEntry{}
+		entries[i].fromAppMessage(entry)
+	}
+	x.GetBalancesByAddressesAtBlockResponse = &GetBalancesByAddressesAtBlockResponseMessage{
+		BlockHash: message.BlockHash,
+		DaaScore:  message.DAAScore,
+		Entries:   entries,
+		Error:     err,
+	}
+	return nil
+}
+
+func (x *GetBalancesByAddressesAtBlockResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetBalancesByAddressesAtBlockResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+
+	if rpcErr != nil && len(x.Entries) != 0 {
+		return nil, errors.New("GetBalancesByAddressesAtBlockResponseMessage contains both an error and a response")
+	}
+
+	entries := make([]*appmessage.BalancesByAddressesEntry, len(x.Entries))
+	for i, entry := range x.Entries {
+		entryAsAppMessage, err := entry.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = entryAsAppMessage
+	}
+
+	return &appmessage.GetBalancesByAddressesAtBlockResponseMessage{
+		BlockHash: x.BlockHash,
+		DAAScore:  x.DaaScore,
+		Entries:   entries,
+		Error:     rpcErr,
+	}, nil
+}
@@ -17,15 +17,19 @@ func (x *GetBlockRequestMessage) toAppMessage() (appmessage.Message, error) {
 		return nil, errors.Wrapf(errorNil, "GetBlockRequestMessage is nil")
 	}
 	return &appmessage.GetBlockRequestMessage{
-		Hash:                x.Hash,
-		IncludeTransactions: x.IncludeTransactions,
+		Hash:                          x.Hash,
+		IncludeTransactions:           x.IncludeTransactions,
+		FetchFromArchivalPeers:        x.FetchFromArchivalPeers,
+		IncludeAcceptedTransactionIDs: x.IncludeAcceptedTransactionIds,
 	}, nil
 }
 
 func (x *SedradMessage_GetBlockRequest) fromAppMessage(message *appmessage.GetBlockRequestMessage) error {
 	x.GetBlockRequest = &GetBlockRequestMessage{
-		Hash:                message.Hash,
-		IncludeTransactions: message.IncludeTransactions,
+		Hash:                          message.Hash,
+		IncludeTransactions:           message.IncludeTransactions,
+		FetchFromArchivalPeers:        message.FetchFromArchivalPeers,
+		IncludeAcceptedTransactionIds: message.IncludeAcceptedTransactionIDs,
 	}
 	return nil
 }
@@ -58,8 +62,9 @@ func (x *GetBlockResponseMessage) toAppMessage() (appmessage.Message, error) {
 		}
 	}
 	return &appmessage.GetBlockResponseMessage{
-		Block: block,
-		Error: rpcErr,
+		Block:                  block,
+		AcceptedTransactionIDs: x.AcceptedTransactionIds,
+		Error:                  rpcErr,
 	}, nil
 }
 
@@ -78,8 +83,9 @@ func (x *SedradMessage_GetBlockResponse) fromAppMessage(message *appmessage.GetB
 		block = protoBlock
 	}
 	x.GetBlockResponse = &GetBlockResponseMessage{
-		Block: block,
-		Error: err,
+		Block:                  block,
+		AcceptedTransactionIds: message.AcceptedTransactionIDs,
+		Error:                  err,
 	}
 	return nil
 }
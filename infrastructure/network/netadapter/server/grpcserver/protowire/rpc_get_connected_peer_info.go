@@ -27,6 +27,14 @@ func (x *SedradMessage_GetConnectedPeerInfoResponse) fromAppMessage(message *app
 	}
 	infos := make([]*GetConnectedPeerInfoMessage, len(message.Infos))
 	for i, info := range message.Infos {
+		recentViolations := make([]*PeerViolationMessage, len(info.RecentViolations))
+		for j, violation := range info.RecentViolations {
+			recentViolations[j] = &PeerViolationMessage{
+				Misbehavior: violation.Misbehavior,
+				Reason:      violation.Reason,
+				Timestamp:   violation.Timestamp,
+			}
+		}
 		infos[i] = &GetConnectedPeerInfoMessage{
 			Id:                        info.ID,
 			Address:                   info.Address,
@@ -37,6 +45,10 @@ func (x *SedradMessage_GetConnectedPeerInfoResponse) fromAppMessage(message *app
 			AdvertisedProtocolVersion: info.AdvertisedProtocolVersion,
 			TimeConnected:             info.TimeConnected,
 			IsIbdPeer:                 info.IsIBDPeer,
+			MisbehaviorScore:          info.MisbehaviorScore,
+			RecentViolations:          recentViolations,
+			BytesSent:                 info.BytesSent,
+			BytesReceived:             info.BytesReceived,
 		}
 	}
 	x.GetConnectedPeerInfoResponse = &GetConnectedPeerInfoResponseMessage{
@@ -78,6 +90,17 @@ func (x *GetConnectedPeerInfoMessage) toAppMessage() (*appmessage.GetConnectedPe
 	if x == nil {
 		return nil, errors.Wrapf(errorNil, "GetConnectedPeerInfoMessage is nil")
 	}
+	recentViolations := make([]*appmessage.PeerViolation, len(x.RecentViolations))
+	for i, violation := range x.RecentViolations {
+		if violation == nil {
+			return nil, errors.Wrapf(errorNil, "PeerViolationMessage is nil")
+		}
+		recentViolations[i] = &appmessage.PeerViolation{
+			Misbehavior: violation.Misbehavior,
+			Reason:      violation.Reason,
+			Timestamp:   violation.Timestamp,
+		}
+	}
 	return &appmessage.GetConnectedPeerInfoMessage{
 		ID:                        x.Id,
 		Address:                   x.Address,
@@ -88,5 +111,9 @@ func (x *GetConnectedPeerInfoMessage) toAppMessage() (*appmessage.GetConnectedPe
 		AdvertisedProtocolVersion: x.AdvertisedProtocolVersion,
 		TimeConnected:             x.TimeOffset,
 		IsIBDPeer:                 x.IsIbdPeer,
+		MisbehaviorScore:          x.MisbehaviorScore,
+		RecentViolations:          recentViolations,
+		BytesSent:                 x.BytesSent,
+		BytesReceived:             x.BytesReceived,
 	}, nil
 }
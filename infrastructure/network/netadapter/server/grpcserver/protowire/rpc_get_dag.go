@@ -0,0 +1,119 @@
+package protowire
+
+import (
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *SedradMessage_GetDagRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SedradMessage_GetDagRequest is nil")
+	}
+	return x.GetDagRequest.toAppMessage()
+}
+
+func (x *SedradMessage_GetDagRequest) fromAppMessage(message *appmessage.GetDAGRequestMessage) error {
+	x.GetDagRequest = &GetDagRequestMessage{
+		LowHash:           message.LowHash,
+		HighHash:          message.HighHash,
+		LastDaaBlockCount: message.LastDAABlockCount,
+		IncludeDot:        message.IncludeDOT,
+	}
+	return nil
+}
+
+func (x *GetDagRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetDagRequestMessage is nil")
+	}
+	return &appmessage.GetDAGRequestMessage{
+		LowHash:           x.LowHash,
+		HighHash:          x.HighHash,
+		LastDAABlockCount: x.LastDaaBlockCount,
+		IncludeDOT:        x.IncludeDot,
+	}, nil
+}
+
+func (x *SedradMessage_GetDagResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SedradMessage_GetDagResponse is nil")
+	}
+	return x.GetDagResponse.toAppMessage()
+}
+
+func (x *SedradMessage_GetDagResponse) fromAppMessage(message *appmessage.GetDAGResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	blocks := make([]*RpcDagBlock, len(message.Blocks))
+	for i, block := range message.Blocks {
+		blocks[i] = &RpcDagBlock{}
+		blocks[i].fromAppMessage(block)
+	}
+	x.GetDagResponse = &GetDagResponseMessage{
+		Blocks: blocks,
+		Dot:    message.DOT,
+		Error:  err,
+	}
+	return nil
+}
+
+func (x *GetDagResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetDagResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	if rpcErr != nil && len(x.Blocks) != 0 {
+		return nil, errors.New("GetDagResponseMessage contains both an error and a response")
+	}
+	blocks := make([]*appmessage.RPCDAGBlock, len(x.Blocks))
+	for i, block := range x.Blocks {
+		blocks[i], err = block.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &appmessage.GetDAGResponseMessage{
+		Blocks: blocks,
+		DOT:    x.Dot,
+		Error:  rpcErr,
+	}, nil
+}
+
+func (x *RpcDagBlock) toAppMessage() (*appmessage.RPCDAGBlock, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "RpcDagBlock is nil")
+	}
+	return &appmessage.RPCDAGBlock{
+		Hash:                x.Hash,
+		ParentHashes:        x.ParentHashes,
+		SelectedParentHash:  x.SelectedParentHash,
+		BlueScore:           x.BlueScore,
+		DAAScore:            x.DaaScore,
+		Timestamp:           x.Timestamp,
+		IsChainBlock:        x.IsChainBlock,
+		Color:               x.Color,
+		MergeSetBluesHashes: x.MergeSetBluesHashes,
+		MergeSetRedsHashes:  x.MergeSetRedsHashes,
+	}, nil
+}
+
+func (x *RpcDagBlock) fromAppMessage(message *appmessage.RPCDAGBlock) {
+	*x = RpcDagBlock{
+		Hash:                message.Hash,
+		ParentHashes:        message.ParentHashes,
+		SelectedParentHash:  message.SelectedParentHash,
+		BlueScore:           message.BlueScore,
+		DaaScore:            message.DAAScore,
+		Timestamp:           message.Timestamp,
+		IsChainBlock:        message.IsChainBlock,
+		Color:               message.Color,
+		MergeSetBluesHashes: message.MergeSetBluesHashes,
+		MergeSetRedsHashes:  message.MergeSetRedsHashes,
+	}
+}
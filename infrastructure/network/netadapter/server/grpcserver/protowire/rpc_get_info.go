@@ -32,7 +32,9 @@ func (x *SedradMessage_GetInfoResponse) fromAppMessage(message *appmessage.GetIn
 		MempoolSize:   message.MempoolSize,
 		IsUtxoIndexed: message.IsUtxoIndexed,
 		IsSynced:      message.IsSynced,
-		Error:         err,
+
+		MinimumRelayTransactionFee: message.MinimumRelayTransactionFee,
+		Error:                      err,
 	}
 	return nil
 }
@@ -58,6 +60,8 @@ func (x *GetInfoResponseMessage) toAppMessage() (appmessage.Message, error) {
 		IsUtxoIndexed: x.IsUtxoIndexed,
 		IsSynced:      x.IsSynced,
 
+		MinimumRelayTransactionFee: x.MinimumRelayTransactionFee,
+
 		Error: rpcErr,
 	}, nil
 }
@@ -0,0 +1,81 @@
+package protowire
+
+import (
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *SedradMessage_GetNetTotalsRequest) toAppMessage() (appmessage.Message, error) {
+	return &appmessage.GetNetTotalsRequestMessage{}, nil
+}
+
+func (x *SedradMessage_GetNetTotalsRequest) fromAppMessage(_ *appmessage.GetNetTotalsRequestMessage) error {
+	x.GetNetTotalsRequest = &GetNetTotalsRequestMessage{}
+	return nil
+}
+
+func (x *SedradMessage_GetNetTotalsResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SedradMessage_GetNetTotalsResponse is nil")
+	}
+	return x.GetNetTotalsResponse.toAppMessage()
+}
+
+func (x *SedradMessage_GetNetTotalsResponse) fromAppMessage(message *appmessage.GetNetTotalsResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	messageTypeTotals := make([]*NetMessageTypeTotals, len(message.MessageTypeTotals))
+	for i, totals := range message.MessageTypeTotals {
+		messageTypeTotals[i] = &NetMessageTypeTotals{
+			MessageType:      totals.MessageType,
+			BytesSent:        totals.BytesSent,
+			BytesReceived:    totals.BytesReceived,
+			MessagesSent:     totals.MessagesSent,
+			MessagesReceived: totals.MessagesReceived,
+		}
+	}
+	x.GetNetTotalsResponse = &GetNetTotalsResponseMessage{
+		TotalBytesSent:     message.TotalBytesSent,
+		TotalBytesReceived: message.TotalBytesReceived,
+		TimeStarted:        message.TimeStarted,
+		MessageTypeTotals:  messageTypeTotals,
+		Error:              err,
+	}
+	return nil
+}
+
+func (x *GetNetTotalsResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetNetTotalsResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	if rpcErr != nil && len(x.MessageTypeTotals) != 0 {
+		return nil, errors.New("GetNetTotalsResponseMessage contains both an error and a response")
+	}
+	messageTypeTotals := make([]*appmessage.NetMessageTypeTotals, len(x.MessageTypeTotals))
+	for i, totals := range x.MessageTypeTotals {
+		if totals == nil {
+			return nil, errors.Wrapf(errorNil, "NetMessageTypeTotals is nil")
+		}
+		messageTypeTotals[i] = &appmessage.NetMessageTypeTotals{
+			MessageType:      totals.MessageType,
+			BytesSent:        totals.BytesSent,
+			BytesReceived:    totals.BytesReceived,
+			MessagesSent:     totals.MessagesSent,
+			MessagesReceived: totals.MessagesReceived,
+		}
+	}
+	return &appmessage.GetNetTotalsResponseMessage{
+		TotalBytesSent:     x.TotalBytesSent,
+		TotalBytesReceived: x.TotalBytesReceived,
+		TimeStarted:        x.TimeStarted,
+		MessageTypeTotals:  messageTypeTotals,
+		Error:              rpcErr,
+	}, nil
+}
@@ -0,0 +1,92 @@
+package protowire
+
+import (
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *SedradMessage_GetUtxosByAddressesAtBlockRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SedradMessage_GetUtxosByAddressesAtBlockRequest is nil")
+	}
+	return x.GetUtxosByAddressesAtBlockRequest.toAppMessage()
+}
+
+func (x *SedradMessage_GetUtxosByAddressesAtBlockRequest) fromAppMessage(message *appmessage.GetUTXOsByAddressesAtBlockRequestMessage) error {
+	x.GetUtxosByAddressesAtBlockRequest = &GetUtxosByAddressesAtBlockRequestMessage{
+		Addresses: message.Addresses,
+		BlockHash: message.BlockHash,
+		DaaScore:  message.DAAScore,
+		Timestamp: message.Timestamp,
+	}
+	return nil
+}
+
+func (x *GetUtxosByAddressesAtBlockRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetUtxosByAddressesAtBlockRequestMessage is nil")
+	}
+	return &appmessage.GetUTXOsByAddressesAtBlockRequestMessage{
+		Addresses: x.Addresses,
+		BlockHash: x.BlockHash,
+		DAAScore:  x.DaaScore,
+		Timestamp: x.Timestamp,
+	}, nil
+}
+
+func (x *SedradMessage_GetUtxosByAddressesAtBlockResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SedradMessage_GetUtxosByAddressesAtBlockResponse is nil")
+	}
+	return x.GetUtxosByAddressesAtBlockResponse.toAppMessage()
+}
+
+func (x *SedradMessage_GetUtxosByAddressesAtBlockResponse) fromAppMessage(message *appmessage.GetUTXOsByAddressesAtBlockResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	entries := make([]*UtxosByAddressesEntry, len(message.Entries))
+	for i, entry := range message.Entries {
+		entries[i] = &UtxosByAddressesEntry{}
+		entries[i].fromAppMessage(entry)
+	}
+	x.GetUtxosByAddressesAtBlockResponse = &GetUtxosByAddressesAtBlockResponseMessage{
+		BlockHash: message.BlockHash,
+		DaaScore:  message.DAAScore,
+		Entries:   entries,
+		Error:     err,
+	}
+	return nil
+}
+
+func (x *GetUtxosByAddressesAtBlockResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetUtxosByAddressesAtBlockResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+
+	if rpcErr != nil && len(x.Entries) != 0 {
+		return nil, errors.New("GetUtxosByAddressesAtBlockResponseMessage contains both an error and a response")
+	}
+
+	entries := make([]*appmessage.UTXOsByAddressesEntry, len(x.Entries))
+	for i, entry := range x.Entries {
+		entryAsAppMessage, err := entry.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = entryAsAppMessage
+	}
+
+	return &appmessage.GetUTXOsByAddressesAtBlockResponseMessage{
+		BlockHash: x.BlockHash,
+		DAAScore:  x.DaaScore,
+		Entries:   entries,
+		Error:     rpcErr,
+	}, nil
+}
@@ -0,0 +1,72 @@
+package protowire
+
+import (
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *SedradMessage_VerifyMessageRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SedradMessage_VerifyMessageRequest is nil")
+	}
+	return x.VerifyMessageRequest.toAppMessage()
+}
+
+func (x *SedradMessage_VerifyMessageRequest) fromAppMessage(message *appmessage.VerifyMessageRequestMessage) error {
+	x.VerifyMessageRequest = &VerifyMessageRequestMessage{
+		Address:   message.Address,
+		Message:   message.Message,
+		Signature: message.Signature,
+	}
+	return nil
+}
+
+func (x *VerifyMessageRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "VerifyMessageRequestMessage is nil")
+	}
+	return &appmessage.VerifyMessageRequestMessage{
+		Address:   x.Address,
+		Message:   x.Message,
+		Signature: x.Signature,
+	}, nil
+}
+
+func (x *SedradMessage_VerifyMessageResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SedradMessage_VerifyMessageResponse is nil")
+	}
+	return x.VerifyMessageResponse.toAppMessage()
+}
+
+func (x *SedradMessage_VerifyMessageResponse) fromAppMessage(message *appmessage.VerifyMessageResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	x.VerifyMessageResponse = &VerifyMessageResponseMessage{
+		IsValid:       message.IsValid,
+		InvalidReason: message.InvalidReason,
+		Error:         err,
+	}
+	return nil
+}
+
+func (x *VerifyMessageResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "VerifyMessageResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	if rpcErr != nil && x.IsValid {
+		return nil, errors.New("VerifyMessageResponseMessage contains both an error and a response")
+	}
+	return &appmessage.VerifyMessageResponseMessage{
+		IsValid:       x.IsValid,
+		InvalidReason: x.InvalidReason,
+		Error:         rpcErr,
+	}, nil
+}
@@ -359,6 +359,48 @@ func toP2PPayload(message appmessage.Message) (isSedradMessage_Payload, error) {
 			return nil, err
 		}
 		return payload, nil
+	case *appmessage.MsgCompactBlock:
+		payload := new(SedradMessage_CompactBlock)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.MsgRequestBlockTransactions:
+		payload := new(SedradMessage_RequestBlockTransactions)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.MsgBlockTransactions:
+		payload := new(SedradMessage_BlockTransactions)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.MsgStemTransaction:
+		payload := new(SedradMessage_StemTransaction)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.MsgRequestHistoricalBlocks:
+		payload := new(SedradMessage_RequestHistoricalBlocks)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.MsgHistoricalBlocks:
+		payload := new(SedradMessage_HistoricalBlocks)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
 	default:
 		return nil, nil
 	}
@@ -968,6 +1010,76 @@ func toRPCPayload(message appmessage.Message) (isSedradMessage_Payload, error) {
 			return nil, err
 		}
 		return payload, nil
+	case *appmessage.GetUTXOsByAddressesAtBlockRequestMessage:
+		payload := new(SedradMessage_GetUtxosByAddressesAtBlockRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetUTXOsByAddressesAtBlockResponseMessage:
+		payload := new(SedradMessage_GetUtxosByAddressesAtBlockResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetBalancesByAddressesAtBlockRequestMessage:
+		payload := new(SedradMessage_GetBalancesByAddressesAtBlockRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetBalancesByAddressesAtBlockResponseMessage:
+		payload := new(SedradMessage_GetBalancesByAddressesAtBlockResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetNetTotalsRequestMessage:
+		payload := new(SedradMessage_GetNetTotalsRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetNetTotalsResponseMessage:
+		payload := new(SedradMessage_GetNetTotalsResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.VerifyMessageRequestMessage:
+		payload := new(SedradMessage_VerifyMessageRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.VerifyMessageResponseMessage:
+		payload := new(SedradMessage_VerifyMessageResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetDAGRequestMessage:
+		payload := new(SedradMessage_GetDagRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetDAGResponseMessage:
+		payload := new(SedradMessage_GetDagResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
 	default:
 		return nil, nil
 	}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 
+	"github.com/sedracoin/sedrad/app/appmessage"
 	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/router"
 )
 
@@ -20,6 +21,14 @@ type OnDisconnectedHandler func()
 // was received from a connection.
 type OnInvalidMessageHandler func(err error)
 
+// OnMessageSendingHandler is a function that is to be called
+// right before a message of the given size is sent.
+type OnMessageSendingHandler func(command appmessage.MessageCommand, byteCount int)
+
+// OnMessageReceivedHandler is a function that is to be called
+// once a message of the given size is received.
+type OnMessageReceivedHandler func(command appmessage.MessageCommand, byteCount int)
+
 // Server represents a server.
 type Server interface {
 	Start() error
@@ -42,5 +51,7 @@ type Connection interface {
 	IsOutbound() bool
 	SetOnDisconnectedHandler(onDisconnectedHandler OnDisconnectedHandler)
 	SetOnInvalidMessageHandler(onInvalidMessageHandler OnInvalidMessageHandler)
+	SetOnMessageSendingHandler(onMessageSendingHandler OnMessageSendingHandler)
+	SetOnMessageReceivedHandler(onMessageReceivedHandler OnMessageReceivedHandler)
 	Address() *net.TCPAddr
 }
@@ -0,0 +1,299 @@
+package simulatedserver
+
+import (
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/sedracoin/sedrad/infrastructure/logger"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/router"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/server"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+// inFlightMessagesCapacity is the number of messages that can be on their
+// way over one direction of a link before sending more messages blocks
+const inFlightMessagesCapacity = 1000
+
+type inFlightMessage struct {
+	serializedMessage []byte
+	arrivalTime       time.Time
+}
+
+// connection is one side of a simulated connection. Every message sent over
+// it is serialized, delayed according to the link the connection is over, and
+// then handed to the other side of the connection.
+type connection struct {
+	network       *Network
+	localAddress  string
+	remoteAddress string
+	address       *net.TCPAddr
+	isOutbound    bool
+	peer          *connection
+	router        *router.Router
+
+	inFlightMessages chan *inFlightMessage
+	incomingMessages chan []byte
+	lastArrivalTime  time.Time
+
+	stopChan                 chan struct{}
+	onDisconnectedHandler    server.OnDisconnectedHandler
+	onInvalidMessageHandler  server.OnInvalidMessageHandler
+	onMessageSendingHandler  server.OnMessageSendingHandler
+	onMessageReceivedHandler server.OnMessageReceivedHandler
+
+	isConnected uint32
+}
+
+// newConnectionPair creates both sides of a connection from localServer
+// to remoteServer
+func newConnectionPair(network *Network, localServer, remoteServer *p2pServer) (outbound, inbound *connection) {
+	outbound = newConnection(network, localServer.address, remoteServer.address, remoteServer.tcpAddress, true)
+	inbound = newConnection(network, remoteServer.address, localServer.address,
+		network.nextEphemeralAddress(localServer.tcpAddress), false)
+	outbound.peer = inbound
+	inbound.peer = outbound
+	return outbound, inbound
+}
+
+func newConnection(network *Network, localAddress, remoteAddress string, address *net.TCPAddr,
+	isOutbound bool) *connection {
+
+	return &connection{
+		network:          network,
+		localAddress:     localAddress,
+		remoteAddress:    remoteAddress,
+		address:          address,
+		isOutbound:       isOutbound,
+		inFlightMessages: make(chan *inFlightMessage, inFlightMessagesCapacity),
+		incomingMessages: make(chan []byte),
+		stopChan:         make(chan struct{}),
+		isConnected:      1,
+	}
+}
+
+func (c *connection) Start(router *router.Router) {
+	if c.onDisconnectedHandler == nil {
+		panic(errors.New("onDisconnectedHandler is nil"))
+	}
+
+	c.router = router
+
+	spawn("simulatedserver.connection.Start-connectionLoops", func() {
+		err := c.connectionLoops()
+		if err != nil {
+			log.Errorf("error from connectionLoops for %s: %s", c, err)
+		}
+	})
+}
+
+func (c *connection) String() string {
+	return c.Address().String()
+}
+
+func (c *connection) IsConnected() bool {
+	return atomic.LoadUint32(&c.isConnected) != 0
+}
+
+func (c *connection) IsOutbound() bool {
+	return c.isOutbound
+}
+
+func (c *connection) SetOnDisconnectedHandler(onDisconnectedHandler server.OnDisconnectedHandler) {
+	c.onDisconnectedHandler = onDisconnectedHandler
+}
+
+func (c *connection) SetOnInvalidMessageHandler(onInvalidMessageHandler server.OnInvalidMessageHandler) {
+	c.onInvalidMessageHandler = onInvalidMessageHandler
+}
+
+func (c *connection) SetOnMessageSendingHandler(onMessageSendingHandler server.OnMessageSendingHandler) {
+	c.onMessageSendingHandler = onMessageSendingHandler
+}
+
+func (c *connection) SetOnMessageReceivedHandler(onMessageReceivedHandler server.OnMessageReceivedHandler) {
+	c.onMessageReceivedHandler = onMessageReceivedHandler
+}
+
+// Disconnect disconnects both sides of the connection. Messages that are
+// still on their way are lost.
+// Calling this function a second time doesn't do anything
+//
+// This is part of the Connection interface
+func (c *connection) Disconnect() {
+	if !atomic.CompareAndSwapUint32(&c.isConnected, 1, 0) {
+		return
+	}
+
+	close(c.stopChan)
+
+	log.Debugf("Disconnecting from %s", c)
+	if c.onDisconnectedHandler != nil {
+		c.onDisconnectedHandler()
+	}
+
+	spawn("simulatedserver.connection.Disconnect-peer", c.peer.Disconnect)
+}
+
+func (c *connection) Address() *net.TCPAddr {
+	return c.address
+}
+
+func (c *connection) connectionLoops() error {
+	errChan := make(chan error, 3) // buffered channel because the loops might try write after disconnect
+
+	spawn("simulatedserver.connection.sendLoop", func() { errChan <- c.sendLoop() })
+	spawn("simulatedserver.connection.deliveryLoop", func() { errChan <- c.deliveryLoop() })
+	spawn("simulatedserver.connection.receiveLoop", func() { errChan <- c.receiveLoop() })
+
+	err := <-errChan
+
+	c.Disconnect()
+
+	return err
+}
+
+// sendLoop serializes the outgoing messages and puts them on the link,
+// which takes as long as the bandwidth of the link requires
+func (c *connection) sendLoop() error {
+	outgoingRoute := c.router.OutgoingRoute()
+	for c.IsConnected() {
+		message, err := outgoingRoute.Dequeue()
+		if err != nil {
+			if errors.Is(err, router.ErrRouteClosed) {
+				return nil
+			}
+			return err
+		}
+
+		log.Debugf("outgoing '%s' message to %s", message.Command(), c)
+		log.Tracef("outgoing '%s' message to %s: %s", message.Command(), c, logger.NewLogClosure(func() string {
+			return spew.Sdump(message)
+		}))
+
+		messageProto, err := protowire.FromAppMessage(message)
+		if err != nil {
+			return err
+		}
+
+		if c.onMessageSendingHandler != nil {
+			c.onMessageSendingHandler(message.Command(), proto.Size(messageProto))
+		}
+
+		serializedMessage, err := proto.Marshal(messageProto)
+		if err != nil {
+			return err
+		}
+
+		link := c.network.Link(c.localAddress, c.remoteAddress)
+		if link.Bandwidth > 0 {
+			transmissionTime := time.Duration(float64(len(serializedMessage)) / float64(link.Bandwidth) * float64(time.Second))
+			if !sleep(transmissionTime, c.stopChan) {
+				return nil
+			}
+		}
+
+		// Messages arrive in the order they were sent in, like over TCP
+		arrivalTime := time.Now().Add(c.network.transmissionDelay(&link, len(serializedMessage)))
+		if arrivalTime.Before(c.lastArrivalTime) {
+			arrivalTime = c.lastArrivalTime
+		}
+		c.lastArrivalTime = arrivalTime
+
+		select {
+		case c.inFlightMessages <- &inFlightMessage{serializedMessage: serializedMessage, arrivalTime: arrivalTime}:
+		case <-c.stopChan:
+			return nil
+		}
+	}
+	return nil
+}
+
+// deliveryLoop hands the messages that were put on the link to the other
+// side of the connection once they arrive. Messages that are sent during a
+// partition are held until it's healed.
+func (c *connection) deliveryLoop() error {
+	for {
+		var message *inFlightMessage
+		select {
+		case message = <-c.inFlightMessages:
+		case <-c.stopChan:
+			return nil
+		}
+
+		if !sleep(time.Until(message.arrivalTime), c.stopChan) {
+			return nil
+		}
+		if !c.network.waitUntilReachable(c.localAddress, c.remoteAddress, c.stopChan) {
+			return nil
+		}
+
+		select {
+		case c.peer.incomingMessages <- message.serializedMessage:
+		case <-c.stopChan:
+			return nil
+		}
+	}
+}
+
+func (c *connection) receiveLoop() error {
+	messageNumber := uint64(0)
+	for c.IsConnected() {
+		var serializedMessage []byte
+		select {
+		case serializedMessage = <-c.incomingMessages:
+		case <-c.stopChan:
+			return nil
+		}
+
+		protoMessage := &protowire.SedradMessage{}
+		err := proto.Unmarshal(serializedMessage, protoMessage)
+		if err != nil {
+			return errors.Wrapf(err, "error deserializing a message from %s", c)
+		}
+		message, err := protoMessage.ToAppMessage()
+		if err != nil {
+			if c.onInvalidMessageHandler != nil {
+				c.onInvalidMessageHandler(err)
+			}
+			return err
+		}
+
+		if c.onMessageReceivedHandler != nil {
+			c.onMessageReceivedHandler(message.Command(), proto.Size(protoMessage))
+		}
+
+		messageNumber++
+		message.SetMessageNumber(messageNumber)
+		message.SetReceivedAt(time.Now())
+
+		log.Debugf("incoming '%s' message from %s (message number %d)", message.Command(), c,
+			message.MessageNumber())
+
+		log.Tracef("incoming '%s' message from %s  (message number %d): %s", message.Command(),
+			c, message.MessageNumber(), logger.NewLogClosure(func() string {
+				return spew.Sdump(message)
+			}))
+
+		err = c.router.EnqueueIncomingMessage(message)
+		if err != nil {
+			if errors.Is(err, router.ErrRouteClosed) {
+				return nil
+			}
+
+			// ErrRouteCapacityReached isn't an invalid message error, so
+			// we return it in order to log it later on.
+			if errors.Is(err, router.ErrRouteCapacityReached) {
+				return err
+			}
+			if c.onInvalidMessageHandler != nil {
+				c.onInvalidMessageHandler(err)
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+package simulatedserver
+
+import (
+	"github.com/sedracoin/sedrad/infrastructure/logger"
+	"github.com/sedracoin/sedrad/util/panics"
+)
+
+var log = logger.RegisterSubSystem("NTAR")
+var spawn = panics.GoroutineWrapperFunc(log)
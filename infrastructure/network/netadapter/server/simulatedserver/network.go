@@ -0,0 +1,310 @@
+package simulatedserver
+
+import (
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxSegmentSize is the size of the packets messages are split into when
+// packet loss is simulated
+const maxSegmentSize = 1460
+
+// minRetransmissionTimeout is the minimal time it takes to notice that a
+// packet was lost and retransmit it
+const minRetransmissionTimeout = 200 * time.Millisecond
+
+// firstEphemeralPort is the port of the first outbound connection of
+// every server, as seen by the servers it connects to
+const firstEphemeralPort = 49152
+
+// LinkConfig describes the conditions of the link between two simulated
+// servers. The same conditions apply to both directions of the link.
+type LinkConfig struct {
+	// Latency is the time it takes a message to get to the other side of
+	// the link, once it's sent
+	Latency time.Duration
+
+	// Jitter is the maximal random delay that's added to Latency
+	Jitter time.Duration
+
+	// Bandwidth is the number of bytes per second that can be sent in each
+	// direction of the link. 0 means that the bandwidth is unlimited.
+	Bandwidth uint64
+
+	// PacketLoss is the probability that a packet is lost. Since the P2P
+	// protocol runs over TCP, lost packets are retransmitted, so packet
+	// loss delays messages rather than dropping them.
+	PacketLoss float64
+}
+
+func (lc *LinkConfig) validate() error {
+	if lc.Latency < 0 || lc.Jitter < 0 {
+		return errors.Errorf("the latency and jitter of a link can't be negative")
+	}
+	if lc.PacketLoss < 0 || lc.PacketLoss >= 1 {
+		return errors.Errorf("the packet loss of a link must be in [0, 1), but it's %f", lc.PacketLoss)
+	}
+	return nil
+}
+
+func (lc *LinkConfig) retransmissionTimeout() time.Duration {
+	retransmissionTimeout := 2 * lc.Latency
+	if retransmissionTimeout < minRetransmissionTimeout {
+		return minRetransmissionTimeout
+	}
+	return retransmissionTimeout
+}
+
+// linkKey identifies the link between two servers regardless of its direction
+type linkKey struct {
+	addressA, addressB string
+}
+
+func newLinkKey(addressA, addressB string) linkKey {
+	if addressA > addressB {
+		addressA, addressB = addressB, addressA
+	}
+	return linkKey{addressA: addressA, addressB: addressB}
+}
+
+// Network is a simulated network of P2P servers that run in the same
+// process. Messages are serialized just like the gRPC server serializes them,
+// and are delivered according to the LinkConfig of the link they're sent over.
+// Servers are identified by their listening addresses.
+type Network struct {
+	lock        sync.RWMutex
+	servers     map[string]*p2pServer
+	defaultLink LinkConfig
+	links       map[linkKey]LinkConfig
+	nextPorts   map[string]int
+
+	// partitions maps the address of every partitioned server to the
+	// index of its partition. A nil map means that there's no partition.
+	partitions map[string]int
+	// partitionsChanged is closed and replaced whenever the partitions change
+	partitionsChanged chan struct{}
+
+	random     *rand.Rand
+	randomLock sync.Mutex
+}
+
+// NewNetwork creates a new simulated network in which all the links have
+// the conditions of defaultLink, until they're set otherwise. The seed seeds
+// the jitter and packet loss of all the links.
+func NewNetwork(defaultLink LinkConfig, seed int64) (*Network, error) {
+	err := defaultLink.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Network{
+		servers:           make(map[string]*p2pServer),
+		defaultLink:       defaultLink,
+		links:             make(map[linkKey]LinkConfig),
+		nextPorts:         make(map[string]int),
+		partitionsChanged: make(chan struct{}),
+		random:            rand.New(rand.NewSource(seed)),
+	}, nil
+}
+
+// SetLink sets the conditions of the link between the servers with the given
+// addresses, in both directions. The new conditions apply to messages that are
+// sent from now on.
+func (n *Network) SetLink(addressA, addressB string, link LinkConfig) error {
+	err := link.validate()
+	if err != nil {
+		return err
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.links[newLinkKey(addressA, addressB)] = link
+	return nil
+}
+
+// Link returns the conditions of the link between the servers with the given
+// addresses
+func (n *Network) Link(addressA, addressB string) LinkConfig {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	link, ok := n.links[newLinkKey(addressA, addressB)]
+	if !ok {
+		return n.defaultLink
+	}
+	return link
+}
+
+// Partition splits the network so that servers in different groups can't
+// reach each other. All the servers that aren't in any of the groups form one
+// more group. Like over TCP, messages that were sent across the partition are
+// held until it's healed, and new connections across it fail.
+func (n *Network) Partition(groups ...[]string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.partitions = make(map[string]int)
+	for i, group := range groups {
+		for _, address := range group {
+			n.partitions[address] = i + 1
+		}
+	}
+	n.notifyPartitionsChanged()
+}
+
+// Heal removes the partition of the network, if there is one
+func (n *Network) Heal() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.partitions = nil
+	n.notifyPartitionsChanged()
+}
+
+// notifyPartitionsChanged must be called with the lock held
+func (n *Network) notifyPartitionsChanged() {
+	close(n.partitionsChanged)
+	n.partitionsChanged = make(chan struct{})
+}
+
+// IsPartitioned returns whether the servers with the given addresses are
+// in different partitions
+func (n *Network) IsPartitioned(addressA, addressB string) bool {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	return n.isPartitioned(addressA, addressB)
+}
+
+// isPartitioned must be called with the lock held
+func (n *Network) isPartitioned(addressA, addressB string) bool {
+	if n.partitions == nil {
+		return false
+	}
+	return n.partitions[addressA] != n.partitions[addressB]
+}
+
+// waitUntilReachable blocks until the servers with the given addresses
+// aren't partitioned. It returns false if stopChan was closed before that.
+func (n *Network) waitUntilReachable(addressA, addressB string, stopChan <-chan struct{}) bool {
+	for {
+		n.lock.RLock()
+		isPartitioned := n.isPartitioned(addressA, addressB)
+		partitionsChanged := n.partitionsChanged
+		n.lock.RUnlock()
+
+		if !isPartitioned {
+			return true
+		}
+
+		select {
+		case <-partitionsChanged:
+		case <-stopChan:
+			return false
+		}
+	}
+}
+
+func (n *Network) addServer(server *p2pServer) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if _, ok := n.servers[server.address]; ok {
+		return errors.Errorf("a simulated server with the address %s already exists", server.address)
+	}
+	n.servers[server.address] = server
+	return nil
+}
+
+func (n *Network) server(address string) (*p2pServer, bool) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	server, ok := n.servers[address]
+	return server, ok
+}
+
+// nextEphemeralAddress returns the address the next outbound connection of
+// the server with the given address is seen from
+func (n *Network) nextEphemeralAddress(address *net.TCPAddr) *net.TCPAddr {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	port, ok := n.nextPorts[address.String()]
+	if !ok {
+		port = firstEphemeralPort
+	}
+	n.nextPorts[address.String()] = port + 1
+
+	return &net.TCPAddr{IP: address.IP, Port: port}
+}
+
+// transmissionDelay returns how long it takes a message of the given size
+// to get to the other side of the given link, once it's been put on the link
+func (n *Network) transmissionDelay(link *LinkConfig, byteCount int) time.Duration {
+	n.randomLock.Lock()
+	defer n.randomLock.Unlock()
+
+	delay := link.Latency
+	if link.Jitter > 0 {
+		delay += time.Duration(n.random.Int63n(int64(link.Jitter) + 1))
+	}
+
+	if link.PacketLoss > 0 {
+		// The message waits for the packet that was retransmitted the most
+		// times, and the retransmission timeout doubles on every retry
+		packetCount := (byteCount + maxSegmentSize - 1) / maxSegmentSize
+		maxRetransmissions := 0
+		for i := 0; i < packetCount; i++ {
+			retransmissions := 0
+			for n.random.Float64() < link.PacketLoss {
+				retransmissions++
+			}
+			if retransmissions > maxRetransmissions {
+				maxRetransmissions = retransmissions
+			}
+		}
+		delay += link.retransmissionTimeout() * time.Duration(1<<maxRetransmissions-1)
+	}
+
+	return delay
+}
+
+func parseAddress(address string) (*net.TCPAddr, error) {
+	host, portString, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid address %s", address)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, errors.Errorf("the host of a simulated server must be an IP, but it's %s", host)
+	}
+	port, err := strconv.Atoi(portString)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid port in address %s", address)
+	}
+	return &net.TCPAddr{IP: ip, Port: port}, nil
+}
+
+// sleep sleeps for the given duration, and returns false if stopChan was
+// closed before it's over
+func sleep(duration time.Duration, stopChan <-chan struct{}) bool {
+	if duration <= 0 {
+		return true
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-stopChan:
+		return false
+	}
+}
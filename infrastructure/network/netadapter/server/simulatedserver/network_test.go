@@ -0,0 +1,146 @@
+package simulatedserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/router"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/server"
+)
+
+type testPeer struct {
+	connection server.Connection
+	router     *router.Router
+	pingRoute  *router.Route
+}
+
+// startTestServer starts a simulated server whose connections are sent to
+// the returned channel once they're started
+func startTestServer(t *testing.T, network *Network, address string) (server.P2PServer, <-chan *testPeer) {
+	p2pServer, err := network.NewP2PServer(address)
+	if err != nil {
+		t.Fatalf("NewP2PServer: %+v", err)
+	}
+
+	peers := make(chan *testPeer, 10)
+	p2pServer.SetOnConnectedHandler(func(connection server.Connection) error {
+		connectionRouter := router.NewRouter(address)
+		pingRoute, err := connectionRouter.AddIncomingRoute("ping", []appmessage.MessageCommand{appmessage.CmdPing})
+		if err != nil {
+			return err
+		}
+		connection.SetOnDisconnectedHandler(connectionRouter.Close)
+		connection.Start(connectionRouter)
+
+		peers <- &testPeer{connection: connection, router: connectionRouter, pingRoute: pingRoute}
+		return nil
+	})
+
+	err = p2pServer.Start()
+	if err != nil {
+		t.Fatalf("Start: %+v", err)
+	}
+	return p2pServer, peers
+}
+
+func TestNetwork(t *testing.T) {
+	const (
+		latency  = 100 * time.Millisecond
+		addressA = "10.0.0.1:16111"
+		addressB = "10.0.0.2:16111"
+		addressC = "10.0.0.3:16111"
+	)
+
+	network, err := NewNetwork(LinkConfig{Latency: latency}, 0)
+	if err != nil {
+		t.Fatalf("NewNetwork: %+v", err)
+	}
+
+	serverA, peersOfA := startTestServer(t, network, addressA)
+	_, peersOfB := startTestServer(t, network, addressB)
+
+	_, err = network.NewP2PServer(addressA)
+	if err == nil {
+		t.Fatalf("NewP2PServer unexpectedly succeeded for an address that's in use")
+	}
+	_, err = serverA.Connect(addressC)
+	if err == nil {
+		t.Fatalf("Connect unexpectedly succeeded for an address no server listens on")
+	}
+
+	connectStart := time.Now()
+	_, err = serverA.Connect(addressB)
+	if err != nil {
+		t.Fatalf("Connect: %+v", err)
+	}
+	if time.Since(connectStart) < 2*latency {
+		t.Fatalf("Connecting took less than a round trip")
+	}
+	peerAtA := <-peersOfA
+	peerAtB := <-peersOfB
+	if !peerAtA.connection.IsOutbound() || peerAtB.connection.IsOutbound() {
+		t.Fatalf("Unexpected connection directions")
+	}
+	if peerAtA.connection.Address().String() != addressB {
+		t.Fatalf("Expected the outbound connection address to be %s, but it's %s",
+			addressB, peerAtA.connection.Address())
+	}
+	if peerAtB.connection.Address().IP.String() != "10.0.0.1" || peerAtB.connection.Address().Port != firstEphemeralPort {
+		t.Fatalf("Unexpected inbound connection address %s", peerAtB.connection.Address())
+	}
+
+	sendPing := func(nonce uint64) time.Time {
+		err := peerAtA.router.OutgoingRoute().Enqueue(appmessage.NewMsgPing(nonce))
+		if err != nil {
+			t.Fatalf("Enqueue: %+v", err)
+		}
+		return time.Now()
+	}
+	receivePing := func(expectedNonce uint64, timeout time.Duration) {
+		message, err := peerAtB.pingRoute.DequeueWithTimeout(timeout)
+		if err != nil {
+			t.Fatalf("DequeueWithTimeout: %+v", err)
+		}
+		if message.(*appmessage.MsgPing).Nonce != expectedNonce {
+			t.Fatalf("Expected nonce %d but got %d", expectedNonce, message.(*appmessage.MsgPing).Nonce)
+		}
+	}
+
+	sentAt := sendPing(1)
+	receivePing(1, time.Second)
+	if time.Since(sentAt) < latency {
+		t.Fatalf("A message arrived after %s, before the link latency", time.Since(sentAt))
+	}
+
+	network.Partition([]string{addressA})
+	if !network.IsPartitioned(addressA, addressB) {
+		t.Fatalf("Expected %s and %s to be partitioned", addressA, addressB)
+	}
+	sendPing(2)
+	_, err = peerAtB.pingRoute.DequeueWithTimeout(3 * latency)
+	if err == nil {
+		t.Fatalf("A message was delivered across a partition")
+	}
+	_, err = serverA.Connect(addressB)
+	if err == nil {
+		t.Fatalf("Connect unexpectedly succeeded across a partition")
+	}
+
+	network.Heal()
+	receivePing(2, time.Second)
+
+	err = network.SetLink(addressA, addressB, LinkConfig{PacketLoss: 1})
+	if err == nil {
+		t.Fatalf("SetLink unexpectedly succeeded for a link that loses all packets")
+	}
+
+	peerAtB.connection.Disconnect()
+	deadline := time.Now().Add(time.Second)
+	for peerAtA.connection.IsConnected() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Disconnecting one side of a connection didn't disconnect the other")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,99 @@
+package simulatedserver
+
+import (
+	"net"
+	"sync/atomic"
+
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/server"
+	"github.com/pkg/errors"
+)
+
+type p2pServer struct {
+	network            *Network
+	address            string
+	tcpAddress         *net.TCPAddr
+	onConnectedHandler server.OnConnectedHandler
+	isListening        uint32
+}
+
+// NewP2PServer creates a new P2PServer that listens on the given address of
+// the simulated network. The address must be of the form ip:port.
+func (n *Network) NewP2PServer(address string) (server.P2PServer, error) {
+	tcpAddress, err := parseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	p2pServer := &p2pServer{
+		network:    n,
+		address:    tcpAddress.String(),
+		tcpAddress: tcpAddress,
+	}
+	err = n.addServer(p2pServer)
+	if err != nil {
+		return nil, err
+	}
+	return p2pServer, nil
+}
+
+func (p *p2pServer) Start() error {
+	if p.onConnectedHandler == nil {
+		return errors.New("onConnectedHandler is nil")
+	}
+
+	atomic.StoreUint32(&p.isListening, 1)
+	log.Infof("Simulated P2P Server listening on %s", p.address)
+	return nil
+}
+
+func (p *p2pServer) Stop() error {
+	atomic.StoreUint32(&p.isListening, 0)
+	return nil
+}
+
+func (p *p2pServer) SetOnConnectedHandler(onConnectedHandler server.OnConnectedHandler) {
+	p.onConnectedHandler = onConnectedHandler
+}
+
+func (p *p2pServer) IsListening() bool {
+	return atomic.LoadUint32(&p.isListening) != 0
+}
+
+// Connect connects to the simulated server with the given address. Like
+// establishing a TCP connection, it takes a round trip over the link.
+// This is part of the P2PServer interface
+func (p *p2pServer) Connect(address string) (server.Connection, error) {
+	log.Debugf("Simulated P2P Dialing to %s", address)
+
+	tcpAddress, err := parseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	remoteServer, ok := p.network.server(tcpAddress.String())
+	if !ok || !remoteServer.IsListening() {
+		return nil, errors.Errorf("error connecting to %s: no simulated server is listening on it", address)
+	}
+	if p.network.IsPartitioned(p.address, remoteServer.address) {
+		return nil, errors.Errorf("error connecting to %s: it's unreachable due to a network partition", address)
+	}
+
+	link := p.network.Link(p.address, remoteServer.address)
+	sleep(2*link.Latency, nil)
+
+	outboundConnection, inboundConnection := newConnectionPair(p.network, p, remoteServer)
+
+	err = remoteServer.onConnectedHandler(inboundConnection)
+	if err != nil {
+		inboundConnection.Disconnect()
+		return nil, err
+	}
+	err = p.onConnectedHandler(outboundConnection)
+	if err != nil {
+		outboundConnection.Disconnect()
+		return nil, err
+	}
+
+	log.Infof("Simulated P2P Connected to %s", address)
+
+	return outboundConnection, nil
+}
@@ -0,0 +1,74 @@
+package netadapter
+
+import (
+	"sync"
+
+	"github.com/sedracoin/sedrad/app/appmessage"
+)
+
+// TrafficStats holds the amount of traffic that was sent and received.
+// Byte counts are of serialized messages, before compression.
+type TrafficStats struct {
+	BytesSent        uint64
+	BytesReceived    uint64
+	MessagesSent     uint64
+	MessagesReceived uint64
+}
+
+// trafficCounter counts the traffic of one or more connections, both in
+// total and per message type
+type trafficCounter struct {
+	lock      sync.Mutex
+	total     TrafficStats
+	byCommand map[appmessage.MessageCommand]*TrafficStats
+}
+
+func newTrafficCounter() *trafficCounter {
+	return &trafficCounter{
+		byCommand: make(map[appmessage.MessageCommand]*TrafficStats),
+	}
+}
+
+func (tc *trafficCounter) addSent(command appmessage.MessageCommand, byteCount int) {
+	tc.lock.Lock()
+	defer tc.lock.Unlock()
+
+	commandStats := tc.commandStats(command)
+	commandStats.BytesSent += uint64(byteCount)
+	commandStats.MessagesSent++
+	tc.total.BytesSent += uint64(byteCount)
+	tc.total.MessagesSent++
+}
+
+func (tc *trafficCounter) addReceived(command appmessage.MessageCommand, byteCount int) {
+	tc.lock.Lock()
+	defer tc.lock.Unlock()
+
+	commandStats := tc.commandStats(command)
+	commandStats.BytesReceived += uint64(byteCount)
+	commandStats.MessagesReceived++
+	tc.total.BytesReceived += uint64(byteCount)
+	tc.total.MessagesReceived++
+}
+
+// commandStats must be called with the lock held
+func (tc *trafficCounter) commandStats(command appmessage.MessageCommand) *TrafficStats {
+	commandStats, ok := tc.byCommand[command]
+	if !ok {
+		commandStats = &TrafficStats{}
+		tc.byCommand[command] = commandStats
+	}
+	return commandStats
+}
+
+// stats returns copies of the total traffic and of the traffic per message type
+func (tc *trafficCounter) stats() (total TrafficStats, byCommand map[appmessage.MessageCommand]TrafficStats) {
+	tc.lock.Lock()
+	defer tc.lock.Unlock()
+
+	byCommand = make(map[appmessage.MessageCommand]TrafficStats, len(tc.byCommand))
+	for command, commandStats := range tc.byCommand {
+		byCommand[command] = *commandStats
+	}
+	return tc.total, byCommand
+}
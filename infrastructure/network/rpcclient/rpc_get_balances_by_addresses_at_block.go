@@ -0,0 +1,23 @@
+package rpcclient
+
+import "github.com/sedracoin/sedrad/app/appmessage"
+
+// GetBalancesByAddressesAtBlock sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) GetBalancesByAddressesAtBlock(addresses []string, blockHash string, daaScore *uint64,
+	timestamp *int64) (*appmessage.GetBalancesByAddressesAtBlockResponseMessage, error) {
+
+	err := c.rpcRouter.outgoingRoute().Enqueue(
+		appmessage.NewGetBalancesByAddressesAtBlockRequest(addresses, blockHash, daaScore, timestamp))
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdGetBalancesByAddressesAtBlockResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	getBalancesByAddressesAtBlockResponse := response.(*appmessage.GetBalancesByAddressesAtBlockResponseMessage)
+	if getBalancesByAddressesAtBlockResponse.Error != nil {
+		return nil, c.convertRPCError(getBalancesByAddressesAtBlockResponse.Error)
+	}
+	return getBalancesByAddressesAtBlockResponse, nil
+}
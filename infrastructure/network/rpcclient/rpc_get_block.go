@@ -21,3 +21,26 @@ func (c *RPCClient) GetBlock(hash string, includeTransactions bool) (
 	}
 	return GetBlockResponse, nil
 }
+
+// GetBlockFromArchivalPeers sends an RPC request respective to the function's name and returns the RPC server's response.
+// Unlike GetBlock, blocks that the RPC server had already pruned are fetched from its archival peers.
+func (c *RPCClient) GetBlockFromArchivalPeers(hash string, includeTransactions bool,
+	includeAcceptedTransactionIDs bool) (*appmessage.GetBlockResponseMessage, error) {
+
+	request := appmessage.NewGetBlockRequestMessage(hash, includeTransactions)
+	request.FetchFromArchivalPeers = true
+	request.IncludeAcceptedTransactionIDs = includeAcceptedTransactionIDs
+	err := c.rpcRouter.outgoingRoute().Enqueue(request)
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdGetBlockResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	getBlockResponse := response.(*appmessage.GetBlockResponseMessage)
+	if getBlockResponse.Error != nil {
+		return nil, c.convertRPCError(getBlockResponse.Error)
+	}
+	return getBlockResponse, nil
+}
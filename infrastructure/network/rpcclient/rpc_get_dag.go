@@ -0,0 +1,23 @@
+package rpcclient
+
+import "github.com/sedracoin/sedrad/app/appmessage"
+
+// GetDAG sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) GetDAG(lowHash string, highHash string, lastDAABlockCount uint64,
+	includeDOT bool) (*appmessage.GetDAGResponseMessage, error) {
+
+	err := c.rpcRouter.outgoingRoute().Enqueue(
+		appmessage.NewGetDAGRequestMessage(lowHash, highHash, lastDAABlockCount, includeDOT))
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdGetDAGResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	getDAGResponse := response.(*appmessage.GetDAGResponseMessage)
+	if getDAGResponse.Error != nil {
+		return nil, c.convertRPCError(getDAGResponse.Error)
+	}
+	return getDAGResponse, nil
+}
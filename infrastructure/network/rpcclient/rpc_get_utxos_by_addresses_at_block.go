@@ -0,0 +1,23 @@
+package rpcclient
+
+import "github.com/sedracoin/sedrad/app/appmessage"
+
+// GetUTXOsByAddressesAtBlock sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) GetUTXOsByAddressesAtBlock(addresses []string, blockHash string, daaScore *uint64,
+	timestamp *int64) (*appmessage.GetUTXOsByAddressesAtBlockResponseMessage, error) {
+
+	err := c.rpcRouter.outgoingRoute().Enqueue(
+		appmessage.NewGetUTXOsByAddressesAtBlockRequestMessage(addresses, blockHash, daaScore, timestamp))
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdGetUTXOsByAddressesAtBlockResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	getUTXOsByAddressesAtBlockResponse := response.(*appmessage.GetUTXOsByAddressesAtBlockResponseMessage)
+	if getUTXOsByAddressesAtBlockResponse.Error != nil {
+		return nil, c.convertRPCError(getUTXOsByAddressesAtBlockResponse.Error)
+	}
+	return getUTXOsByAddressesAtBlockResponse, nil
+}
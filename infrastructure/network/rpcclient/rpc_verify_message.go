@@ -0,0 +1,20 @@
+package rpcclient
+
+import "github.com/sedracoin/sedrad/app/appmessage"
+
+// VerifyMessage sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) VerifyMessage(address string, message string, signature string) (*appmessage.VerifyMessageResponseMessage, error) {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewVerifyMessageRequestMessage(address, message, signature))
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdVerifyMessageResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	verifyMessageResponse := response.(*appmessage.VerifyMessageResponseMessage)
+	if verifyMessageResponse.Error != nil {
+		return nil, c.convertRPCError(verifyMessageResponse.Error)
+	}
+	return verifyMessageResponse, nil
+}
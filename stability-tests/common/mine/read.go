@@ -4,12 +4,27 @@ import (
 	"compress/gzip"
 	"encoding/json"
 	"os"
+
+	"github.com/pkg/errors"
 )
 
 // JSONBlock is a json representation of a block in mine format
 type JSONBlock struct {
 	ID      string   `json:"id"`
 	Parents []string `json:"parents"`
+
+	// The following fields are used by the scenarios of TestConsensus.MineJSON, and
+	// aren't supported when mining to a node
+	Transactions  []string        `json:"transactions,omitempty"`
+	Invalid       string          `json:"invalid,omitempty"`
+	ExpectedError string          `json:"expectedError,omitempty"`
+	Transaction   json.RawMessage `json:"transaction,omitempty"`
+	Assert        json.RawMessage `json:"assert,omitempty"`
+}
+
+func (block *JSONBlock) isScenarioEntry() bool {
+	return len(block.Transactions) > 0 || block.Invalid != "" || block.ExpectedError != "" ||
+		block.Transaction != nil || block.Assert != nil
 }
 
 func readBlocks(jsonFile string) (<-chan JSONBlock, error) {
@@ -42,6 +57,10 @@ func readBlocks(jsonFile string) (<-chan JSONBlock, error) {
 			if err != nil {
 				panic(err)
 			}
+			if block.isScenarioEntry() {
+				panic(errors.Errorf("entry %s has transactions, invalid blocks or assertions, which "+
+					"are supported only by TestConsensus.MineJSON", block.ID))
+			}
 
 			blockChan <- block
 		}
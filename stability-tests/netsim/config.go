@@ -0,0 +1,60 @@
+package main
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/sedracoin/sedrad/stability-tests/common"
+)
+
+const (
+	defaultLogFilename    = "netsim.log"
+	defaultErrLogFilename = "netsim_err.log"
+)
+
+var (
+	// Default configuration options
+	defaultLogFile    = filepath.Join(common.DefaultAppDir, defaultLogFilename)
+	defaultErrLogFile = filepath.Join(common.DefaultAppDir, defaultErrLogFilename)
+)
+
+type configFlags struct {
+	LogLevel          string        `short:"d" long:"loglevel" description:"Set log level {trace, debug, info, warn, error, critical}"`
+	Profile           string        `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
+	NodeCount         int           `short:"n" long:"nodes" description:"Number of nodes"`
+	K                 uint8         `short:"k" long:"k" description:"GHOSTDAG K (default: the simnet K)"`
+	BlockRate         float64       `short:"r" long:"block-rate" description:"Expected number of blocks per second"`
+	HashrateShares    []float64     `long:"hashrate-share" description:"Hashrate share of the miner of every node, by the order of the nodes. Nodes without a share don't mine. May be repeated (default: all nodes mine with the same hashrate)"`
+	Latency           time.Duration `long:"latency" description:"One-way latency of every link"`
+	Jitter            time.Duration `long:"jitter" description:"Maximal random delay that's added to the latency"`
+	Bandwidth         uint64        `long:"bandwidth" description:"Bytes per second every link can carry in each direction (default: unlimited)"`
+	PacketLoss        float64       `long:"packet-loss" description:"Probability of a packet to be lost and retransmitted"`
+	Duration          time.Duration `long:"duration" description:"How long to mine for"`
+	PartitionDuration time.Duration `long:"partition-duration" description:"If set, the first half of the nodes is partitioned from the rest for this long, in the middle of the run"`
+	Seed              int64         `long:"seed" description:"Seed of the miners and the network"`
+}
+
+var cfg *configFlags
+
+func activeConfig() *configFlags {
+	return cfg
+}
+
+func parseConfig() error {
+	cfg = &configFlags{
+		NodeCount: 5,
+		BlockRate: 1,
+		Latency:   100 * time.Millisecond,
+		Duration:  time.Minute,
+	}
+	parser := flags.NewParser(cfg, flags.PrintErrors|flags.HelpFlag)
+	_, err := parser.Parse()
+	if err != nil {
+		return err
+	}
+
+	initLog(defaultLogFile, defaultErrLogFile)
+
+	return nil
+}
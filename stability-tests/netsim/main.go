@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/server/simulatedserver"
+	"github.com/sedracoin/sedrad/stability-tests/common"
+	"github.com/sedracoin/sedrad/testing/netsim"
+	"github.com/sedracoin/sedrad/util/panics"
+	"github.com/sedracoin/sedrad/util/profiling"
+	"github.com/pkg/errors"
+)
+
+func main() {
+	err := realMain()
+	if err != nil {
+		log.Criticalf("An error occurred: %+v", err)
+		backendLog.Close()
+		os.Exit(1)
+	}
+	backendLog.Close()
+}
+
+func realMain() error {
+	defer panics.HandlePanic(log, "netsim-main", nil)
+	err := parseConfig()
+	if err != nil {
+		return errors.Wrap(err, "Error in parseConfig")
+	}
+	common.UseLogger(backendLog, log.Level())
+	cfg := activeConfig()
+	if cfg.Profile != "" {
+		profiling.Start(cfg.Profile, log)
+	}
+
+	simulationConfig := &netsim.Config{
+		NodeCount: cfg.NodeCount,
+		DefaultLink: simulatedserver.LinkConfig{
+			Latency:    cfg.Latency,
+			Jitter:     cfg.Jitter,
+			Bandwidth:  cfg.Bandwidth,
+			PacketLoss: cfg.PacketLoss,
+		},
+		K:         externalapi.KType(cfg.K),
+		BlockRate: cfg.BlockRate,
+		Seed:      cfg.Seed,
+	}
+	for i, hashrateShare := range cfg.HashrateShares {
+		simulationConfig.Miners = append(simulationConfig.Miners, netsim.MinerConfig{
+			Node:          i,
+			HashrateShare: hashrateShare,
+		})
+	}
+
+	simulation, err := netsim.New(simulationConfig)
+	if err != nil {
+		return errors.Wrap(err, "Error creating the simulation")
+	}
+	err = simulation.Start()
+	if err != nil {
+		return errors.Wrap(err, "Error starting the simulation")
+	}
+	defer func() {
+		err := simulation.Stop()
+		if err != nil {
+			log.Errorf("Error stopping the simulation: %+v", err)
+		}
+	}()
+
+	err = run(simulation)
+	if err != nil {
+		return err
+	}
+
+	results, err := simulation.Results()
+	if err != nil {
+		return errors.Wrap(err, "Error collecting the results")
+	}
+	fmt.Println(results)
+
+	if !results.IsConverged {
+		return errors.New("the nodes didn't converge")
+	}
+	return nil
+}
+
+func run(simulation *netsim.Simulation) error {
+	cfg := activeConfig()
+	if cfg.PartitionDuration == 0 {
+		log.Infof("Mining for %s", cfg.Duration)
+		return simulation.Mine(cfg.Duration)
+	}
+
+	log.Infof("Mining for %s before the partition", cfg.Duration/2)
+	err := simulation.Mine(cfg.Duration / 2)
+	if err != nil {
+		return err
+	}
+
+	partitionedNodes := make([]int, cfg.NodeCount/2)
+	for i := range partitionedNodes {
+		partitionedNodes[i] = i
+	}
+	log.Infof("Partitioning nodes %v from the rest for %s", partitionedNodes, cfg.PartitionDuration)
+	simulation.Partition(partitionedNodes)
+	err = simulation.Mine(cfg.PartitionDuration)
+	if err != nil {
+		return err
+	}
+	simulation.Heal()
+
+	log.Infof("Mining for %s after the partition", cfg.Duration/2)
+	return simulation.Mine(cfg.Duration / 2)
+}
@@ -0,0 +1,13 @@
+#!/bin/bash
+set -e
+netsim --loglevel=warn --nodes=5 --block-rate=2 --latency=200ms --duration=30s --partition-duration=10s --seed=1
+TEST_EXIT_CODE=$?
+
+echo "Exit code: $TEST_EXIT_CODE"
+
+if [ $TEST_EXIT_CODE -eq 0 ]; then
+  echo "netsim test: PASSED"
+  exit 0
+fi
+echo "netsim test: FAILED"
+exit 1
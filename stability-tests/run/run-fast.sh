@@ -42,6 +42,10 @@ echo "Running many-tips"
 cd "${PROJECT_ROOT}/many-tips/run" && ./run.sh || failedTests+=("many-tips")
 echo "Done running many-tips"
 
+echo "Running netsim"
+cd "${PROJECT_ROOT}/netsim/run" && ./run.sh || failedTests+=("netsim")
+echo "Done running netsim"
+
 echo "Running netsync - fast"
 cd "${PROJECT_ROOT}/netsync/run" && ./run-fast.sh || failedTests+=("netsync")
 echo "Done running netsync - fast"
@@ -0,0 +1,161 @@
+package netsim
+
+import (
+	"time"
+
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/dagconfig"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/server/simulatedserver"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultConnectionTimeout  = 30 * time.Second
+	defaultConvergenceTimeout = 30 * time.Second
+	defaultReorgPollInterval  = 10 * time.Millisecond
+)
+
+// Config describes a simulation
+type Config struct {
+	// NodeCount is the number of full nodes in the simulation
+	NodeCount int
+
+	// Peers lists the nodes every node connects to, by their index. If it's
+	// nil, every node connects to all the nodes before it, so that the nodes
+	// form a full mesh.
+	Peers [][]int
+
+	// DefaultLink is the link between every two nodes, until it's set
+	// otherwise with SetLink
+	DefaultLink simulatedserver.LinkConfig
+
+	// Params are the DAG params of the nodes. Defaults to the simnet params
+	// with proof of work disabled.
+	Params *dagconfig.Params
+
+	// K overrides the GHOSTDAG K of Params, if it's not 0. The merge set
+	// size limit is scaled along with it.
+	K externalapi.KType
+
+	// BlockRate is the expected number of blocks per second that all the
+	// miners mine together. Blocks are mined in a Poisson process.
+	BlockRate float64
+
+	// Miners are the miners of the simulation. If there are none, every node
+	// has a miner, and they all have the same hashrate.
+	Miners []MinerConfig
+
+	// ReferenceNode is the index of the node whose DAG the results are
+	// computed from
+	ReferenceNode int
+
+	// Seed seeds the randomness of both the miners and the network
+	Seed int64
+
+	// DataDir is the directory the databases of the nodes are kept in.
+	// If it's empty, a temporary directory is used and removed on Stop.
+	DataDir string
+
+	// ConnectionTimeout is how long Start waits for all the nodes to connect
+	ConnectionTimeout time.Duration
+
+	// ConvergenceTimeout is how long Results waits for all the nodes to
+	// have the same tips
+	ConvergenceTimeout time.Duration
+
+	// ReorgPollInterval is how often the selected tips of the nodes are
+	// checked for reorgs. Reorgs that are shorter than that might be
+	// measured as part of a longer reorg, or not at all.
+	ReorgPollInterval time.Duration
+}
+
+// MinerConfig describes a simulated miner
+type MinerConfig struct {
+	// Node is the index of the node the miner mines on
+	Node int
+
+	// HashrateShare is the share of the total hashrate of the miner.
+	// Shares don't have to add up to 1, since they're relative to each other.
+	HashrateShare float64
+}
+
+func (c *Config) applyDefaults() {
+	if c.Params == nil {
+		params := dagconfig.SimnetParams
+		params.SkipProofOfWork = true
+		c.Params = &params
+	} else {
+		// Copy so that changes don't leak to the caller
+		params := *c.Params
+		c.Params = &params
+	}
+	if c.K != 0 {
+		c.Params.MergeSetSizeLimit = c.Params.MergeSetSizeLimit * uint64(c.K) / uint64(c.Params.K)
+		c.Params.K = c.K
+	}
+
+	if c.Peers == nil {
+		c.Peers = make([][]int, c.NodeCount)
+		for i := range c.Peers {
+			for j := 0; j < i; j++ {
+				c.Peers[i] = append(c.Peers[i], j)
+			}
+		}
+	}
+
+	if len(c.Miners) == 0 {
+		c.Miners = make([]MinerConfig, c.NodeCount)
+		for i := range c.Miners {
+			c.Miners[i] = MinerConfig{Node: i, HashrateShare: 1}
+		}
+	}
+
+	if c.ConnectionTimeout == 0 {
+		c.ConnectionTimeout = defaultConnectionTimeout
+	}
+	if c.ConvergenceTimeout == 0 {
+		c.ConvergenceTimeout = defaultConvergenceTimeout
+	}
+	if c.ReorgPollInterval == 0 {
+		c.ReorgPollInterval = defaultReorgPollInterval
+	}
+}
+
+func (c *Config) validate() error {
+	if c.NodeCount <= 0 {
+		return errors.Errorf("a simulation must have at least one node")
+	}
+	if c.BlockRate <= 0 {
+		return errors.Errorf("the block rate must be positive, but it's %f", c.BlockRate)
+	}
+	if c.ReferenceNode < 0 || c.ReferenceNode >= c.NodeCount {
+		return errors.Errorf("the reference node %d doesn't exist", c.ReferenceNode)
+	}
+
+	if len(c.Peers) != c.NodeCount {
+		return errors.Errorf("the peers of %d nodes are given, but there are %d nodes", len(c.Peers), c.NodeCount)
+	}
+	for i, peers := range c.Peers {
+		for _, peer := range peers {
+			if peer < 0 || peer >= c.NodeCount || peer == i {
+				return errors.Errorf("node %d can't connect to node %d", i, peer)
+			}
+		}
+	}
+
+	totalHashrateShare := 0.0
+	for i, miner := range c.Miners {
+		if miner.Node < 0 || miner.Node >= c.NodeCount {
+			return errors.Errorf("miner %d mines on node %d, which doesn't exist", i, miner.Node)
+		}
+		if miner.HashrateShare < 0 {
+			return errors.Errorf("the hashrate share of miner %d is negative", i)
+		}
+		totalHashrateShare += miner.HashrateShare
+	}
+	if totalHashrateShare == 0 {
+		return errors.Errorf("the miners don't have any hashrate")
+	}
+
+	return nil
+}
@@ -0,0 +1,9 @@
+package netsim
+
+import (
+	"github.com/sedracoin/sedrad/infrastructure/logger"
+	"github.com/sedracoin/sedrad/util/panics"
+)
+
+var log = logger.RegisterSubSystem("NSIM")
+var spawn = panics.GoroutineWrapperFunc(log)
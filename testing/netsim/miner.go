@@ -0,0 +1,55 @@
+package netsim
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/consensushashing"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/mining"
+)
+
+// miner mines blocks on top of the DAG of its node, whenever the
+// simulation decides it found a block
+type miner struct {
+	index         int
+	node          *Node
+	hashrateShare float64
+	coinbaseData  *externalapi.DomainCoinbaseData
+}
+
+func newMiner(index int, node *Node, hashrateShare float64) *miner {
+	return &miner{
+		index:         index,
+		node:          node,
+		hashrateShare: hashrateShare,
+		coinbaseData: &externalapi.DomainCoinbaseData{
+			ScriptPublicKey: &externalapi.ScriptPublicKey{},
+			// The extra data makes sure that miners that mine on the same
+			// node at the same time don't mine the same block
+			ExtraData: []byte(fmt.Sprintf("netsim miner %d", index)),
+		},
+	}
+}
+
+// mineBlock mines a block on top of the current block template of the
+// miner's node, adds it to the node's DAG and relays it
+func (m *miner) mineBlock(random *rand.Rand) (*externalapi.DomainHash, error) {
+	protocolManager := m.node.protocolManager()
+	block, _, err := protocolManager.Context().Domain().MiningManager().GetBlockTemplate(m.coinbaseData)
+	if err != nil {
+		return nil, err
+	}
+
+	if !m.node.config.ActiveNetParams.SkipProofOfWork {
+		// The template might be cached, so it's cloned before it's modified
+		block = block.Clone()
+		mining.SolveBlock(block, random)
+	}
+
+	err = protocolManager.AddBlock(block)
+	if err != nil {
+		return nil, err
+	}
+	return consensushashing.BlockHash(block), nil
+}
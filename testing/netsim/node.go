@@ -0,0 +1,113 @@
+package netsim
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/sedracoin/sedrad/app"
+	"github.com/sedracoin/sedrad/app/protocol"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/dagconfig"
+	"github.com/sedracoin/sedrad/infrastructure/config"
+	"github.com/sedracoin/sedrad/infrastructure/db/database"
+	"github.com/sedracoin/sedrad/infrastructure/db/database/ldb"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/server/simulatedserver"
+)
+
+// Node is a full node in a simulation
+type Node struct {
+	index            int
+	address          string
+	config           *config.Config
+	database         database.Database
+	componentManager *app.ComponentManager
+	isStarted        bool
+}
+
+// nodeAddress returns the address the node with the given index listens on.
+// Every node has its own IP, so that the nodes don't share their peer scores.
+func nodeAddress(index int, params *dagconfig.Params) string {
+	return fmt.Sprintf("10.0.%d.%d:%s", (index+1)/256, (index+1)%256, params.DefaultPort)
+}
+
+func newNode(index int, network *simulatedserver.Network, params *dagconfig.Params, peerAddresses []string,
+	dataDir string) (*Node, error) {
+
+	node := &Node{
+		index:   index,
+		address: nodeAddress(index, params),
+	}
+
+	node.config = config.DefaultConfig()
+	node.config.ActiveNetParams = params
+	node.config.AppDir = filepath.Join(dataDir, fmt.Sprintf("node-%d", index))
+	node.config.Listeners = nil
+	node.config.RPCListeners = nil
+	node.config.ConnectPeers = peerAddresses
+	node.config.TargetOutboundPeers = 0
+	node.config.DisableDNSSeed = true
+	node.config.AllowSubmitBlockWhenNotSynced = true
+
+	var err error
+	node.database, err = ldb.NewLevelDB(filepath.Join(node.config.AppDir, "db"), 8)
+	if err != nil {
+		return nil, err
+	}
+
+	node.componentManager, err = newComponentManager(node, network)
+	if err != nil {
+		node.database.Close()
+		return nil, err
+	}
+
+	return node, nil
+}
+
+func newComponentManager(node *Node, network *simulatedserver.Network) (*app.ComponentManager, error) {
+	p2pServer, err := network.NewP2PServer(node.address)
+	if err != nil {
+		return nil, err
+	}
+	netAdapter, err := netadapter.NewNetAdapterWithP2PServer(node.config, p2pServer)
+	if err != nil {
+		return nil, err
+	}
+	return app.NewComponentManagerWithNetAdapter(node.config, node.database, netAdapter, make(chan struct{}))
+}
+
+// Index returns the index of the node in the simulation
+func (n *Node) Index() int {
+	return n.index
+}
+
+// Address returns the address of the node in the simulated network
+func (n *Node) Address() string {
+	return n.address
+}
+
+// Consensus returns the consensus of the node
+func (n *Node) Consensus() externalapi.Consensus {
+	return n.protocolManager().Context().Domain().Consensus()
+}
+
+// PeerCount returns the number of peers the node completed a handshake with
+func (n *Node) PeerCount() int {
+	return len(n.protocolManager().Peers())
+}
+
+func (n *Node) protocolManager() *protocol.Manager {
+	return n.componentManager.ProtocolManager()
+}
+
+func (n *Node) start() {
+	n.componentManager.Start()
+	n.isStarted = true
+}
+
+func (n *Node) stop() error {
+	if n.isStarted {
+		n.componentManager.Stop()
+	}
+	return n.database.Close()
+}
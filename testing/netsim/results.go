@@ -0,0 +1,229 @@
+package netsim
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+)
+
+// Results are the measurements of a simulation. Blocks are classified by
+// the DAG of the reference node.
+type Results struct {
+	// MinedBlocks is the number of blocks that were mined by the miners
+	MinedBlocks int
+
+	// BlueBlocks is the number of mined blocks that were merged as blue
+	// by the selected chain
+	BlueBlocks int
+
+	// RedBlocks is the number of mined blocks that were merged as red
+	// by the selected chain
+	RedBlocks int
+
+	// OrphanBlocks is the number of mined blocks that weren't merged by the
+	// selected chain, either because they never got to the reference node or
+	// because they're too far from the selected chain to be merged
+	OrphanBlocks int
+
+	// Reorgs is the number of times a node lost chain blocks from its
+	// selected chain, summed over all the nodes
+	Reorgs int
+
+	// ReorgDepths maps the number of chain blocks lost in a reorg to the
+	// number of reorgs of that depth
+	ReorgDepths map[int]int
+
+	// MaxReorgDepth is the largest number of chain blocks that a node lost in
+	// a single reorg
+	MaxReorgDepth int
+
+	// Miners are the results of every miner, in the order of Config.Miners
+	Miners []*MinerResults
+
+	// IsConverged is whether all the nodes had the same tips when the
+	// results were collected
+	IsConverged bool
+}
+
+// MinerResults are the measurements of a single miner
+type MinerResults struct {
+	MinedBlocks  int
+	BlueBlocks   int
+	RedBlocks    int
+	OrphanBlocks int
+}
+
+// RedBlockRate returns the fraction of mined blocks that are red
+func (r *Results) RedBlockRate() float64 {
+	if r.MinedBlocks == 0 {
+		return 0
+	}
+	return float64(r.RedBlocks) / float64(r.MinedBlocks)
+}
+
+// OrphanRate returns the fraction of mined blocks that are orphans
+func (r *Results) OrphanRate() float64 {
+	if r.MinedBlocks == 0 {
+		return 0
+	}
+	return float64(r.OrphanBlocks) / float64(r.MinedBlocks)
+}
+
+func (r *Results) String() string {
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "Mined blocks: %d (blue: %d, red: %d, orphan: %d)\n",
+		r.MinedBlocks, r.BlueBlocks, r.RedBlocks, r.OrphanBlocks)
+	fmt.Fprintf(builder, "Red block rate: %.4f, orphan rate: %.4f\n", r.RedBlockRate(), r.OrphanRate())
+
+	depths := make([]int, 0, len(r.ReorgDepths))
+	for depth := range r.ReorgDepths {
+		depths = append(depths, depth)
+	}
+	sort.Ints(depths)
+	depthCounts := make([]string, len(depths))
+	for i, depth := range depths {
+		depthCounts[i] = fmt.Sprintf("%d: %d", depth, r.ReorgDepths[depth])
+	}
+	fmt.Fprintf(builder, "Reorgs: %d, max depth: %d, by depth: {%s}\n",
+		r.Reorgs, r.MaxReorgDepth, strings.Join(depthCounts, ", "))
+
+	for i, minerResults := range r.Miners {
+		fmt.Fprintf(builder, "Miner %d: mined %d (blue: %d, red: %d, orphan: %d)\n", i,
+			minerResults.MinedBlocks, minerResults.BlueBlocks, minerResults.RedBlocks, minerResults.OrphanBlocks)
+	}
+	fmt.Fprintf(builder, "Converged: %t", r.IsConverged)
+
+	return builder.String()
+}
+
+// reorgTracker polls the selected tips of the nodes and records how many
+// chain blocks every change of a selected tip removed from the selected chain
+type reorgTracker struct {
+	lock        sync.Mutex
+	reorgDepths map[int]int
+
+	stopChan  chan struct{}
+	waitGroup sync.WaitGroup
+}
+
+func newReorgTracker() *reorgTracker {
+	return &reorgTracker{
+		reorgDepths: make(map[int]int),
+		stopChan:    make(chan struct{}),
+	}
+}
+
+func (rt *reorgTracker) start(nodes []*Node, pollInterval time.Duration) {
+	for _, node := range nodes {
+		node := node
+		rt.waitGroup.Add(1)
+		spawn("reorgTracker.track", func() {
+			defer rt.waitGroup.Done()
+			err := rt.track(node, pollInterval)
+			if err != nil {
+				log.Errorf("Error tracking the reorgs of node %d: %+v", node.Index(), err)
+			}
+		})
+	}
+}
+
+func (rt *reorgTracker) stop() {
+	close(rt.stopChan)
+	rt.waitGroup.Wait()
+}
+
+func (rt *reorgTracker) track(node *Node, pollInterval time.Duration) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	lastSelectedTip, err := node.Consensus().GetVirtualSelectedParent()
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-rt.stopChan:
+			return nil
+		case <-ticker.C:
+		}
+
+		consensus := node.Consensus()
+		selectedTip, err := consensus.GetVirtualSelectedParent()
+		if err != nil {
+			return err
+		}
+		if selectedTip.Equal(lastSelectedTip) {
+			continue
+		}
+
+		chainChanges, err := consensus.GetVirtualSelectedParentChainFromBlock(lastSelectedTip)
+		if err != nil {
+			return err
+		}
+		if len(chainChanges.Removed) > 0 {
+			rt.addReorg(len(chainChanges.Removed))
+		}
+		lastSelectedTip = selectedTip
+	}
+}
+
+func (rt *reorgTracker) addReorg(depth int) {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+
+	rt.reorgDepths[depth]++
+}
+
+func (rt *reorgTracker) addTo(results *Results) {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+
+	results.ReorgDepths = make(map[int]int, len(rt.reorgDepths))
+	for depth, count := range rt.reorgDepths {
+		results.ReorgDepths[depth] = count
+		results.Reorgs += count
+		if depth > results.MaxReorgDepth {
+			results.MaxReorgDepth = depth
+		}
+	}
+}
+
+// mergedBlocks returns the blocks the selected chain of the given consensus
+// merged as blue and as red
+func mergedBlocks(consensus externalapi.Consensus, genesisHash *externalapi.DomainHash) (
+	blues, reds map[externalapi.DomainHash]struct{}, err error) {
+
+	chain, err := consensus.GetVirtualSelectedParentChainFromBlock(genesisHash)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	blues = make(map[externalapi.DomainHash]struct{})
+	reds = make(map[externalapi.DomainHash]struct{})
+	for _, chainBlock := range chain.Added {
+		blockInfo, err := consensus.GetBlockInfo(chainBlock)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, blue := range blockInfo.MergeSetBlues {
+			blues[*blue] = struct{}{}
+		}
+		for _, red := range blockInfo.MergeSetReds {
+			reds[*red] = struct{}{}
+		}
+	}
+
+	// The selected tip is merged only by the virtual, and it's always blue
+	selectedTip, err := consensus.GetVirtualSelectedParent()
+	if err != nil {
+		return nil, nil, err
+	}
+	blues[*selectedTip] = struct{}{}
+
+	return blues, reds, nil
+}
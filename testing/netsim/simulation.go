@@ -0,0 +1,344 @@
+// Package netsim runs a network of full nodes in a single process, over a
+// simulated network with controllable latency, bandwidth, packet loss and
+// partitions, and mines on it with simulated miners. It measures the red
+// block rate, the orphan rate and the depth of reorgs, so that GHOSTDAG
+// parameters can be tested against different network conditions.
+package netsim
+
+import (
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/server/simulatedserver"
+	"github.com/pkg/errors"
+)
+
+// maxSealingBlocks is the maximal number of blocks Results mines in order
+// to merge all the tips of the reference node
+const maxSealingBlocks = 10
+
+// Simulation is a network of full nodes and miners that run in the
+// same process
+type Simulation struct {
+	config  *Config
+	network *simulatedserver.Network
+	nodes   []*Node
+	miners  []*miner
+	random  *rand.Rand
+
+	totalHashrateShare float64
+	minedBlocks        map[externalapi.DomainHash]*miner
+	reorgTracker       *reorgTracker
+
+	isDataDirTemporary bool
+}
+
+// New creates a new simulation according to the given config. Use Start()
+// to start its nodes.
+func New(config *Config) (*Simulation, error) {
+	configCopy := *config
+	config = &configCopy
+	config.applyDefaults()
+	err := config.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	network, err := simulatedserver.NewNetwork(config.DefaultLink, config.Seed)
+	if err != nil {
+		return nil, err
+	}
+
+	simulation := &Simulation{
+		config:       config,
+		network:      network,
+		random:       rand.New(rand.NewSource(config.Seed)),
+		minedBlocks:  make(map[externalapi.DomainHash]*miner),
+		reorgTracker: newReorgTracker(),
+	}
+
+	if config.DataDir == "" {
+		config.DataDir, err = os.MkdirTemp("", "netsim")
+		if err != nil {
+			return nil, err
+		}
+		simulation.isDataDirTemporary = true
+	}
+
+	for i := 0; i < config.NodeCount; i++ {
+		peerAddresses := make([]string, len(config.Peers[i]))
+		for j, peer := range config.Peers[i] {
+			peerAddresses[j] = nodeAddress(peer, config.Params)
+		}
+
+		node, err := newNode(i, network, config.Params, peerAddresses, config.DataDir)
+		if err != nil {
+			// The error of the cleanup is ignored in favor of the original error
+			_ = simulation.Stop()
+			return nil, err
+		}
+		simulation.nodes = append(simulation.nodes, node)
+	}
+
+	for i, minerConfig := range config.Miners {
+		simulation.miners = append(simulation.miners,
+			newMiner(i, simulation.nodes[minerConfig.Node], minerConfig.HashrateShare))
+		simulation.totalHashrateShare += minerConfig.HashrateShare
+	}
+
+	return simulation, nil
+}
+
+// Start starts all the nodes, and waits until they're connected according
+// to Config.Peers
+func (s *Simulation) Start() error {
+	for _, node := range s.nodes {
+		node.start()
+	}
+
+	expectedPeerCounts := make([]int, len(s.nodes))
+	for i, peers := range s.config.Peers {
+		for _, peer := range peers {
+			expectedPeerCounts[i]++
+			expectedPeerCounts[peer]++
+		}
+	}
+
+	deadline := time.Now().Add(s.config.ConnectionTimeout)
+	for i, node := range s.nodes {
+		for node.PeerCount() < expectedPeerCounts[i] {
+			if time.Now().After(deadline) {
+				return errors.Errorf("node %d connected to %d peers instead of %d within %s",
+					i, node.PeerCount(), expectedPeerCounts[i], s.config.ConnectionTimeout)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	log.Infof("All %d nodes are connected", len(s.nodes))
+
+	s.reorgTracker.start(s.nodes, s.config.ReorgPollInterval)
+	return nil
+}
+
+// Stop stops all the nodes, and removes their data if it was kept in a
+// temporary directory
+func (s *Simulation) Stop() error {
+	s.reorgTracker.stop()
+
+	err := s.closeNodes()
+	if err != nil {
+		return err
+	}
+
+	if s.isDataDirTemporary {
+		return os.RemoveAll(s.config.DataDir)
+	}
+	return nil
+}
+
+func (s *Simulation) closeNodes() error {
+	var firstErr error
+	for _, node := range s.nodes {
+		err := node.stop()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Nodes returns the nodes of the simulation
+func (s *Simulation) Nodes() []*Node {
+	return s.nodes
+}
+
+// Network returns the simulated network the nodes are connected by
+func (s *Simulation) Network() *simulatedserver.Network {
+	return s.network
+}
+
+// SetLink sets the conditions of the link between the nodes with the
+// given indexes
+func (s *Simulation) SetLink(nodeA, nodeB int, link simulatedserver.LinkConfig) error {
+	return s.network.SetLink(s.nodes[nodeA].Address(), s.nodes[nodeB].Address(), link)
+}
+
+// Partition splits the nodes into groups that can't reach each other,
+// by their indexes. See simulatedserver.Network.Partition.
+func (s *Simulation) Partition(groups ...[]int) {
+	addressGroups := make([][]string, len(groups))
+	for i, group := range groups {
+		for _, node := range group {
+			addressGroups[i] = append(addressGroups[i], s.nodes[node].Address())
+		}
+	}
+	s.network.Partition(addressGroups...)
+}
+
+// Heal removes the partition of the nodes, if there is one
+func (s *Simulation) Heal() {
+	s.network.Heal()
+}
+
+// Mine mines blocks for the given duration. The time between blocks is
+// exponentially distributed so that Config.BlockRate blocks are mined every
+// second on average, and every block is mined by a miner that's chosen
+// according to the hashrate shares.
+func (s *Simulation) Mine(duration time.Duration) error {
+	deadline := time.Now().Add(duration)
+	nextBlockTime := time.Now()
+	for {
+		interval := time.Duration(s.random.ExpFloat64() / s.config.BlockRate * float64(time.Second))
+		nextBlockTime = nextBlockTime.Add(interval)
+		if nextBlockTime.After(deadline) {
+			time.Sleep(time.Until(deadline))
+			return nil
+		}
+		time.Sleep(time.Until(nextBlockTime))
+
+		miner := s.chooseMiner()
+		blockHash, err := miner.mineBlock(s.random)
+		if err != nil {
+			return errors.Wrapf(err, "miner %d failed to mine a block on node %d", miner.index, miner.node.Index())
+		}
+		s.minedBlocks[*blockHash] = miner
+		log.Debugf("Miner %d mined block %s on node %d", miner.index, blockHash, miner.node.Index())
+	}
+}
+
+func (s *Simulation) chooseMiner() *miner {
+	target := s.random.Float64() * s.totalHashrateShare
+	for _, miner := range s.miners {
+		if target < miner.hashrateShare {
+			return miner
+		}
+		target -= miner.hashrateShare
+	}
+	return s.miners[len(s.miners)-1]
+}
+
+// Results waits for the nodes to converge, and measures the blocks that
+// were mined so far. It stops tracking reorgs, and mines blocks that merge
+// all the tips of the reference node, so it should be called once, after
+// mining is done.
+func (s *Simulation) Results() (*Results, error) {
+	isConverged, err := s.waitForConvergence()
+	if err != nil {
+		return nil, err
+	}
+	// The tracker is replaced with one that isn't started, so that Stop
+	// doesn't stop it twice
+	stoppedReorgTracker := s.reorgTracker
+	stoppedReorgTracker.stop()
+	s.reorgTracker = newReorgTracker()
+
+	referenceNode := s.nodes[s.config.ReferenceNode]
+	err = s.sealTips(referenceNode)
+	if err != nil {
+		return nil, err
+	}
+
+	blues, reds, err := mergedBlocks(referenceNode.Consensus(), s.config.Params.GenesisHash)
+	if err != nil {
+		return nil, err
+	}
+
+	results := &Results{
+		Miners:      make([]*MinerResults, len(s.miners)),
+		IsConverged: isConverged,
+	}
+	for i := range results.Miners {
+		results.Miners[i] = &MinerResults{}
+	}
+	for blockHash, miner := range s.minedBlocks {
+		minerResults := results.Miners[miner.index]
+		results.MinedBlocks++
+		minerResults.MinedBlocks++
+
+		if _, ok := blues[blockHash]; ok {
+			results.BlueBlocks++
+			minerResults.BlueBlocks++
+		} else if _, ok := reds[blockHash]; ok {
+			results.RedBlocks++
+			minerResults.RedBlocks++
+		} else {
+			results.OrphanBlocks++
+			minerResults.OrphanBlocks++
+		}
+	}
+	stoppedReorgTracker.addTo(results)
+
+	return results, nil
+}
+
+// waitForConvergence waits until all the nodes have the same tips, or until
+// Config.ConvergenceTimeout passes
+func (s *Simulation) waitForConvergence() (bool, error) {
+	deadline := time.Now().Add(s.config.ConvergenceTimeout)
+	for {
+		isConverged, err := s.isConverged()
+		if err != nil {
+			return false, err
+		}
+		if isConverged {
+			return true, nil
+		}
+		if time.Now().After(deadline) {
+			log.Warnf("The nodes didn't converge within %s", s.config.ConvergenceTimeout)
+			return false, nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func (s *Simulation) isConverged() (bool, error) {
+	var referenceTips map[externalapi.DomainHash]struct{}
+	for _, node := range s.nodes {
+		tips, err := node.Consensus().Tips()
+		if err != nil {
+			return false, err
+		}
+
+		if referenceTips == nil {
+			referenceTips = make(map[externalapi.DomainHash]struct{}, len(tips))
+			for _, tip := range tips {
+				referenceTips[*tip] = struct{}{}
+			}
+			continue
+		}
+
+		if len(tips) != len(referenceTips) {
+			return false, nil
+		}
+		for _, tip := range tips {
+			if _, ok := referenceTips[*tip]; !ok {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// sealTips mines blocks on the given node until it has a single tip, so
+// that all the blocks it knows are merged by its selected chain. The
+// sealing blocks aren't counted as mined blocks.
+func (s *Simulation) sealTips(node *Node) error {
+	sealer := newMiner(len(s.miners), node, 0)
+	for i := 0; i < maxSealingBlocks; i++ {
+		tips, err := node.Consensus().Tips()
+		if err != nil {
+			return err
+		}
+		if len(tips) == 1 {
+			return nil
+		}
+
+		_, err = sealer.mineBlock(s.random)
+		if err != nil {
+			return errors.Wrapf(err, "failed to mine a sealing block on node %d", node.Index())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package netsim
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sedracoin/sedrad/infrastructure/network/netadapter/server/simulatedserver"
+)
+
+func TestSimulation(t *testing.T) {
+	simulation, err := New(&Config{
+		NodeCount:   3,
+		DefaultLink: simulatedserver.LinkConfig{Latency: 50 * time.Millisecond, Bandwidth: 1_000_000},
+		K:           4,
+		BlockRate:   10,
+		Miners: []MinerConfig{
+			{Node: 0, HashrateShare: 2},
+			{Node: 2, HashrateShare: 1},
+		},
+		Seed: 1,
+	})
+	if err != nil {
+		t.Fatalf("New: %+v", err)
+	}
+	err = simulation.Start()
+	if err != nil {
+		t.Fatalf("Start: %+v", err)
+	}
+	defer func() {
+		err := simulation.Stop()
+		if err != nil {
+			t.Fatalf("Stop: %+v", err)
+		}
+	}()
+
+	err = simulation.Mine(time.Second)
+	if err != nil {
+		t.Fatalf("Mine: %+v", err)
+	}
+	simulation.Partition([]int{0})
+	err = simulation.Mine(time.Second)
+	if err != nil {
+		t.Fatalf("Mine: %+v", err)
+	}
+	simulation.Heal()
+	err = simulation.Mine(500 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("Mine: %+v", err)
+	}
+
+	results, err := simulation.Results()
+	if err != nil {
+		t.Fatalf("Results: %+v", err)
+	}
+	t.Logf("Results:\n%s", results)
+
+	if !results.IsConverged {
+		t.Fatalf("The nodes didn't converge after the partition was healed")
+	}
+	if results.MinedBlocks == 0 {
+		t.Fatalf("No blocks were mined")
+	}
+	if results.BlueBlocks+results.RedBlocks+results.OrphanBlocks != results.MinedBlocks {
+		t.Fatalf("The %d mined blocks weren't classified correctly: %d blue, %d red and %d orphans",
+			results.MinedBlocks, results.BlueBlocks, results.RedBlocks, results.OrphanBlocks)
+	}
+	if len(results.Miners) != 2 || results.Miners[0].MinedBlocks+results.Miners[1].MinedBlocks != results.MinedBlocks {
+		t.Fatalf("The results of the miners don't add up to the total")
+	}
+
+	for _, node := range simulation.Nodes() {
+		if node.PeerCount() != 2 {
+			t.Fatalf("Node %d has %d peers instead of 2", node.Index(), node.PeerCount())
+		}
+	}
+}
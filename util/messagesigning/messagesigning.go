@@ -0,0 +1,179 @@
+// Package messagesigning implements signing arbitrary messages with the keys
+// behind sedra addresses, which allows proving the control of an address
+// without moving its funds.
+//
+// A message is signed by signing its PersonalMessageSigningHash, so a message
+// signature can never be used as a transaction signature. The signature of a
+// public key address is a plain 64-byte Schnorr or ECDSA signature, and the
+// signature of a multisig pay-to-script-hash address is a script that pushes
+// one signature (or OP_0 for a missing one) for every public key of the
+// multisig redeem script, in the order of the keys in the script, followed
+// by the redeem script itself.
+package messagesigning
+
+import (
+	"bytes"
+
+	"github.com/sedracoin/go-secp256k1"
+	"github.com/sedracoin/sedrad/domain/consensus/model/externalapi"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/hashes"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
+	"github.com/sedracoin/sedrad/util"
+	"github.com/pkg/errors"
+)
+
+// Hash returns the hash that is signed in order to sign message
+func Hash(message []byte) *externalapi.DomainHash {
+	hashWriter := hashes.NewPersonalMessageSigningHashWriter()
+	hashWriter.InfallibleWrite(message)
+	return hashWriter.Finalize()
+}
+
+// SignSchnorr signs message with the given Schnorr key pair
+func SignSchnorr(keyPair *secp256k1.SchnorrKeyPair, message []byte) ([]byte, error) {
+	secpHash := secp256k1.Hash(*Hash(message).ByteArray())
+	signature, err := keyPair.SchnorrSign(&secpHash)
+	if err != nil {
+		return nil, errors.Errorf("cannot sign message: %s", err)
+	}
+	return signature.Serialize()[:], nil
+}
+
+// SignECDSA signs message with the given ECDSA private key
+func SignECDSA(privateKey *secp256k1.ECDSAPrivateKey, message []byte) ([]byte, error) {
+	secpHash := secp256k1.Hash(*Hash(message).ByteArray())
+	signature, err := privateKey.ECDSASign(&secpHash)
+	if err != nil {
+		return nil, errors.Errorf("cannot sign message: %s", err)
+	}
+	return signature.Serialize()[:], nil
+}
+
+// MultiSigSignature encodes the signatures of the cosigners of a multisig
+// address. signatures[i] is the signature of the i-th public key in the
+// redeem script, or nil if that cosigner didn't sign.
+func MultiSigSignature(redeemScript []byte, signatures [][]byte) ([]byte, error) {
+	scriptBuilder := txscript.NewScriptBuilder()
+	for _, signature := range signatures {
+		scriptBuilder.AddData(signature)
+	}
+	scriptBuilder.AddData(redeemScript)
+	return scriptBuilder.Script()
+}
+
+// ParseMultiSigSignature decodes a signature encoded by MultiSigSignature into
+// the multisig redeem script and the signatures of its public keys.
+func ParseMultiSigSignature(signature []byte) (redeemScript []byte, signatures [][]byte, err error) {
+	pushes, err := txscript.PushedData(signature)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "malformed multisig signature")
+	}
+	if len(pushes) == 0 {
+		return nil, nil, errors.New("malformed multisig signature: missing the redeem script")
+	}
+
+	redeemScript = pushes[len(pushes)-1]
+	multiSig, err := extractMultiSig(redeemScript)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	signatures = pushes[:len(pushes)-1]
+	if len(signatures) != len(multiSig.PublicKeys) {
+		return nil, nil, errors.Errorf("expected %d signatures (some of them may be empty), but got %d",
+			len(multiSig.PublicKeys), len(signatures))
+	}
+	return redeemScript, signatures, nil
+}
+
+func extractMultiSig(redeemScript []byte) (*txscript.MultiSigDataPushes, error) {
+	multiSig, err := txscript.ExtractMultiSigDataPushes(redeemScript)
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed redeem script")
+	}
+	if multiSig == nil {
+		return nil, errors.New("the redeem script is not a multisig script")
+	}
+	return multiSig, nil
+}
+
+// Verify returns an error if signature is not a valid signature of message by
+// the key, or enough of the multisig keys, behind address.
+func Verify(address util.Address, message []byte, signature []byte) error {
+	hash := Hash(message)
+	switch address := address.(type) {
+	case *util.AddressPublicKey:
+		return verifySignature(address.ScriptAddress(), signature, hash, false)
+	case *util.AddressPublicKeyECDSA:
+		return verifySignature(address.ScriptAddress(), signature, hash, true)
+	case *util.AddressScriptHash:
+		return verifyMultiSig(address, signature, hash)
+	}
+	return errors.Errorf("messages can't be signed by addresses of type %T", address)
+}
+
+func verifyMultiSig(address *util.AddressScriptHash, signature []byte, hash *externalapi.DomainHash) error {
+	redeemScript, signatures, err := ParseMultiSigSignature(signature)
+	if err != nil {
+		return err
+	}
+	multiSig, err := extractMultiSig(redeemScript)
+	if err != nil {
+		return err
+	}
+
+	redeemScriptAddress, err := util.NewAddressScriptHash(redeemScript, address.Prefix())
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(redeemScriptAddress.ScriptAddress(), address.ScriptAddress()) {
+		return errors.Errorf("the redeem script in the signature doesn't belong to %s", address)
+	}
+
+	validSignatures := 0
+	for i, publicKeySignature := range signatures {
+		if len(publicKeySignature) == 0 {
+			continue
+		}
+		err := verifySignature(multiSig.PublicKeys[i], publicKeySignature, hash, multiSig.IsECDSA)
+		if err != nil {
+			return errors.Wrapf(err, "invalid signature of public key %d", i)
+		}
+		validSignatures++
+	}
+	if validSignatures < multiSig.MinimumSignatures {
+		return errors.Errorf("got %d signatures, but %d are required", validSignatures, multiSig.MinimumSignatures)
+	}
+	return nil
+}
+
+func verifySignature(publicKey []byte, signature []byte, hash *externalapi.DomainHash, ecdsa bool) error {
+	secpHash := secp256k1.Hash(*hash.ByteArray())
+	if ecdsa {
+		parsedPublicKey, err := secp256k1.DeserializeECDSAPubKey(publicKey)
+		if err != nil {
+			return err
+		}
+		parsedSignature, err := secp256k1.DeserializeECDSASignatureFromSlice(signature)
+		if err != nil {
+			return err
+		}
+		if !parsedPublicKey.ECDSAVerify(&secpHash, parsedSignature) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+
+	parsedPublicKey, err := secp256k1.DeserializeSchnorrPubKey(publicKey)
+	if err != nil {
+		return err
+	}
+	parsedSignature, err := secp256k1.DeserializeSchnorrSignatureFromSlice(signature)
+	if err != nil {
+		return err
+	}
+	if !parsedPublicKey.SchnorrVerify(&secpHash, parsedSignature) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
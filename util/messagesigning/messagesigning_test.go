@@ -0,0 +1,102 @@
+package messagesigning
+
+import (
+	"testing"
+
+	"github.com/sedracoin/go-secp256k1"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/hashes"
+	"github.com/sedracoin/sedrad/domain/consensus/utils/txscript"
+	"github.com/sedracoin/sedrad/util"
+)
+
+func TestHashIsDomainSeparated(t *testing.T) {
+	message := []byte("message")
+	transactionSigningHashWriter := hashes.NewTransactionSigningHashWriter()
+	transactionSigningHashWriter.InfallibleWrite(message)
+	if Hash(message).Equal(transactionSigningHashWriter.Finalize()) {
+		t.Fatalf("The message hash equals the transaction signing hash of the same data")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	message := []byte("message")
+
+	schnorrKeyPair, err := secp256k1.GenerateSchnorrKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateSchnorrKeyPair: %s", err)
+	}
+	schnorrPublicKey, err := schnorrKeyPair.SchnorrPublicKey()
+	if err != nil {
+		t.Fatalf("SchnorrPublicKey: %s", err)
+	}
+	serializedSchnorrPublicKey, err := schnorrPublicKey.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize: %s", err)
+	}
+	schnorrAddress, err := util.NewAddressPublicKey(serializedSchnorrPublicKey[:], util.Bech32PrefixSedra)
+	if err != nil {
+		t.Fatalf("NewAddressPublicKey: %s", err)
+	}
+	schnorrSignature, err := SignSchnorr(schnorrKeyPair, message)
+	if err != nil {
+		t.Fatalf("SignSchnorr: %s", err)
+	}
+
+	ecdsaPrivateKey, err := secp256k1.GenerateECDSAPrivateKey()
+	if err != nil {
+		t.Fatalf("GenerateECDSAPrivateKey: %s", err)
+	}
+	ecdsaPublicKey, err := ecdsaPrivateKey.ECDSAPublicKey()
+	if err != nil {
+		t.Fatalf("ECDSAPublicKey: %s", err)
+	}
+	serializedECDSAPublicKey, err := ecdsaPublicKey.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize: %s", err)
+	}
+	ecdsaAddress, err := util.NewAddressPublicKeyECDSA(serializedECDSAPublicKey[:], util.Bech32PrefixSedra)
+	if err != nil {
+		t.Fatalf("NewAddressPublicKeyECDSA: %s", err)
+	}
+	ecdsaSignature, err := SignECDSA(ecdsaPrivateKey, message)
+	if err != nil {
+		t.Fatalf("SignECDSA: %s", err)
+	}
+
+	// A pay-to-script-hash address of a script that isn't a multisig script
+	redeemScript, err := txscript.NewScriptBuilder().AddData(serializedSchnorrPublicKey[:]).
+		AddOp(txscript.OpCheckSig).Script()
+	if err != nil {
+		t.Fatalf("Script: %s", err)
+	}
+	nonMultiSigAddress, err := util.NewAddressScriptHash(redeemScript, util.Bech32PrefixSedra)
+	if err != nil {
+		t.Fatalf("NewAddressScriptHash: %s", err)
+	}
+	nonMultiSigSignature, err := txscript.NewScriptBuilder().AddData(schnorrSignature).AddData(redeemScript).Script()
+	if err != nil {
+		t.Fatalf("Script: %s", err)
+	}
+
+	tests := []struct {
+		name        string
+		address     util.Address
+		message     []byte
+		signature   []byte
+		expectValid bool
+	}{
+		{"schnorr", schnorrAddress, message, schnorrSignature, true},
+		{"ecdsa", ecdsaAddress, message, ecdsaSignature, true},
+		{"schnorr other message", schnorrAddress, []byte("other"), schnorrSignature, false},
+		{"ecdsa other message", ecdsaAddress, []byte("other"), ecdsaSignature, false},
+		{"schnorr signature for ecdsa address", ecdsaAddress, message, schnorrSignature, false},
+		{"truncated signature", schnorrAddress, message, schnorrSignature[:63], false},
+		{"non multisig redeem script", nonMultiSigAddress, message, nonMultiSigSignature, false},
+	}
+	for _, test := range tests {
+		err := Verify(test.address, test.message, test.signature)
+		if (err == nil) != test.expectValid {
+			t.Errorf("%s: expected valid: %t, but got error: %v", test.name, test.expectValid, err)
+		}
+	}
+}
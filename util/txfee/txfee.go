@@ -0,0 +1,26 @@
+package txfee
+
+import (
+	"github.com/sedracoin/sedrad/domain/consensus/utils/constants"
+)
+
+// DefaultMinimumRelayFeeRate is the default minimum relay fee rate of the
+// mempool, in seep per 1000 grams of transaction mass
+const DefaultMinimumRelayFeeRate = 1000
+
+// MinimumRelayFee returns the minimum fee a transaction with the given mass has
+// to pay in order to be accepted to the mempool and relayed, given the minimum
+// relay fee rate in seep per 1000 grams of mass
+func MinimumRelayFee(mass uint64, feeRate uint64) uint64 {
+	fee := mass * feeRate / 1000
+	if fee == 0 {
+		fee = feeRate
+	}
+
+	// Set the minimum fee to the maximum possible value if the calculated
+	// fee is not in the valid range for monetary amounts.
+	if fee > constants.MaxSeep {
+		fee = constants.MaxSeep
+	}
+	return fee
+}
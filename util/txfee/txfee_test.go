@@ -0,0 +1,27 @@
+package txfee
+
+import (
+	"testing"
+)
+
+func TestMinimumRelayFee(t *testing.T) {
+	tests := []struct {
+		mass        uint64
+		feeRate     uint64
+		expectedFee uint64
+	}{
+		{mass: 2036, feeRate: 1000, expectedFee: 2036},
+		{mass: 2036, feeRate: 2500, expectedFee: 5090},
+		{mass: 1, feeRate: 500, expectedFee: 500},
+		{mass: 0, feeRate: 1000, expectedFee: 1000},
+		{mass: 2036, feeRate: 0, expectedFee: 0},
+	}
+
+	for _, test := range tests {
+		fee := MinimumRelayFee(test.mass, test.feeRate)
+		if fee != test.expectedFee {
+			t.Errorf("MinimumRelayFee(%d, %d): expected %d but got %d",
+				test.mass, test.feeRate, test.expectedFee, fee)
+		}
+	}
+}